@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,16 +13,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/streamspace/streamspace/api/internal/activity"
 	"github.com/streamspace/streamspace/api/internal/api"
 	"github.com/streamspace/streamspace/api/internal/auth"
 	"github.com/streamspace/streamspace/api/internal/cache"
+	"github.com/streamspace/streamspace/api/internal/catalog"
 	"github.com/streamspace/streamspace/api/internal/db"
+	apierrors "github.com/streamspace/streamspace/api/internal/errors"
 	"github.com/streamspace/streamspace/api/internal/events"
 	"github.com/streamspace/streamspace/api/internal/handlers"
 	"github.com/streamspace/streamspace/api/internal/k8s"
+	"github.com/streamspace/streamspace/api/internal/metrics"
 	"github.com/streamspace/streamspace/api/internal/middleware"
+	"github.com/streamspace/streamspace/api/internal/plugins"
 	"github.com/streamspace/streamspace/api/internal/quota"
 	"github.com/streamspace/streamspace/api/internal/sync"
 	"github.com/streamspace/streamspace/api/internal/tracker"
@@ -39,17 +43,24 @@ func main() {
 	dbSSLMode := getEnv("DB_SSL_MODE", "disable") // SECURITY: Should be "require" in production
 	pluginDir := getEnv("PLUGIN_DIR", "./plugins")
 
+	// Structured JSON logging. slog.SetDefault also routes the standard
+	// log package through this handler, so existing log.Printf calls are
+	// emitted as JSON records too.
+	appLogger := middleware.NewSlogLogger(os.Stdout, middleware.ParseLogLevel(os.Getenv("LOG_LEVEL")))
+	slog.SetDefault(appLogger)
+
 	log.Println("Starting StreamSpace API Server...")
 
 	// Initialize database
 	log.Println("Connecting to database...")
 	database, err := db.NewDatabase(db.Config{
-		Host:     dbHost,
-		Port:     dbPort,
-		User:     dbUser,
-		Password: dbPassword,
-		DBName:   dbName,
-		SSLMode:  dbSSLMode,
+		Host:       dbHost,
+		Port:       dbPort,
+		User:       dbUser,
+		Password:   dbPassword,
+		DBName:     dbName,
+		SSLMode:    dbSSLMode,
+		PoolConfig: db.PoolConfigFromEnv(),
 	})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -116,6 +127,17 @@ func main() {
 		platform = events.PlatformKubernetes // Default platform
 	}
 
+	// Initialize plugin runtime (loads enabled plugins and delivers platform events to them)
+	log.Println("Starting plugin runtime...")
+	pluginRuntime := plugins.NewRuntimeV2(database, pluginDir)
+	registerPluginEventSchemas(pluginRuntime.GetEventBus())
+	pluginRuntime.SetSecretStore(k8sClient)
+	pluginStartCtx, cancelPluginStart := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := pluginRuntime.Start(pluginStartCtx); err != nil {
+		log.Printf("Warning: Failed to start plugin runtime: %v", err)
+	}
+	cancelPluginStart()
+
 	// Initialize NATS event subscriber for receiving status updates from controllers
 	log.Println("Initializing NATS event subscriber...")
 	eventSubscriber, err := events.NewSubscriber(events.Config{
@@ -127,6 +149,8 @@ func main() {
 		log.Printf("Warning: Failed to initialize NATS subscriber: %v", err)
 		log.Println("Status feedback from controllers will be disabled")
 	}
+	eventSubscriber.SetEventEmitter(pluginRuntime)
+	k8sClient.SetEventEmitter(pluginRuntime)
 	defer eventSubscriber.Close()
 
 	// Start subscriber in background to receive controller status events
@@ -144,6 +168,14 @@ func main() {
 	go connTracker.Start()
 	defer connTracker.Stop()
 
+	// Drains requests and tracks background goroutines on shutdown
+	drainer := middleware.NewDrainer()
+
+	// Initialize session resource collector (requires metrics-server)
+	log.Println("Starting session resource collector...")
+	resourceCollector := metrics.NewResourceCollector(database, k8sClient, k8sClient.GetNamespace())
+	drainer.Go("resource-collector", resourceCollector.Start)
+
 	// Initialize sync service
 	log.Println("Initializing repository sync service...")
 	syncService, err := sync.NewSyncService(database)
@@ -151,6 +183,10 @@ func main() {
 		log.Fatalf("Failed to initialize sync service: %v", err)
 	}
 
+	// Catalog listings are cached until their TTL or their repository's next sync
+	templateCache := catalog.NewTemplateCacheFromEnv()
+	syncService.SetCatalogCache(templateCache)
+
 	// Start scheduled sync (every 1 hour by default)
 	syncInterval := getEnv("SYNC_INTERVAL", "1h")
 	interval, err := time.ParseDuration(syncInterval)
@@ -159,10 +195,10 @@ func main() {
 		interval = 1 * time.Hour
 	}
 
-	ctx, cancelSync := context.WithCancel(context.Background())
+	syncCtx, cancelSync := context.WithCancel(context.Background())
 	defer cancelSync()
 
-	go syncService.StartScheduledSync(ctx, interval)
+	drainer.Go("repository-sync", func() { syncService.StartScheduledSync(syncCtx, interval) })
 
 	// Initialize WebSocket manager
 	log.Println("Initializing WebSocket manager...")
@@ -192,14 +228,44 @@ func main() {
 	}
 	router := gin.New()
 
+	// SECURITY: Only honor X-Forwarded-For from known reverse proxies, so
+	// clients cannot spoof the IP seen by c.ClientIP() (IP filters, rate
+	// limits, audit logs).
+	// TRUSTED_PROXIES: comma-separated CIDRs or IPs (default: none)
+	if err := router.SetTrustedProxies(middleware.CIDRsFromEnv("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Add request ID middleware for distributed tracing
 	router.Use(middleware.RequestID())
 
-	// Add recovery middleware (must be early in chain)
-	router.Use(gin.Recovery())
+	// Reject new requests with 503 once graceful shutdown starts
+	router.Use(drainer.Middleware())
+
+	// Add recovery middleware (must be early in chain; logs panics with stack traces)
+	router.Use(apierrors.Recovery())
+
+	// Record Prometheus request metrics (scraped via GET /metrics)
+	router.Use(middleware.PrometheusMetrics())
+	middleware.RegisterStreamSpaceGauges(
+		func() float64 {
+			var count int
+			database.DB().QueryRow(`SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'running'`).Scan(&count)
+			return float64(count)
+		},
+		func() float64 {
+			return float64(wsManager.ClientCount())
+		},
+		func() float64 {
+			var total int64
+			database.DB().QueryRow(`SELECT COALESCE(SUM(size_bytes), 0) FROM session_snapshots WHERE status = 'available'`).Scan(&total)
+			return float64(total)
+		},
+	)
 
 	// Add structured logging with request IDs
 	loggerConfig := middleware.DefaultStructuredLoggerConfig()
+	loggerConfig.Logger = appLogger
 	router.Use(middleware.StructuredLoggerWithConfigFunc(loggerConfig))
 
 	// SECURITY: Add request timeout to prevent slow loris attacks
@@ -227,13 +293,21 @@ func main() {
 	auditLogger := middleware.NewAuditLogger(database, false) // Don't log request bodies by default
 	router.Use(auditLogger.Middleware())
 
+	// SECURITY: Restrict admin endpoints by client IP (open unless configured)
+	// ADMIN_BLOCK_CIDRS / ADMIN_ALLOW_CIDRS: comma-separated CIDRs or IPs
+	router.Use(middleware.OnPathPrefix("/api/v1/admin/",
+		middleware.IPBlockList(middleware.CIDRsFromEnv("ADMIN_BLOCK_CIDRS")),
+		middleware.IPAllowList(middleware.CIDRsFromEnv("ADMIN_ALLOW_CIDRS")),
+	))
+
 	// Add gzip compression (exclude WebSocket, auth, and metrics endpoints)
 	router.Use(middleware.GzipWithExclusions(
 		middleware.BestSpeed, // Use best speed for balance of compression vs CPU
 		[]string{
-			"/api/v1/ws/",      // Exclude WebSocket paths
-			"/api/v1/auth/",    // Exclude auth endpoints (setup, login, etc.)
-			"/api/v1/metrics",  // Exclude metrics (browser handles decompression inconsistently)
+			"/api/v1/ws/",     // Exclude WebSocket paths
+			"/api/v1/auth/",   // Exclude auth endpoints (setup, login, etc.)
+			"/api/v1/metrics", // Exclude metrics (browser handles decompression inconsistently)
+			"/metrics",        // Exclude Prometheus scrape endpoint (promhttp compresses itself)
 		},
 	))
 
@@ -295,15 +369,63 @@ func main() {
 		samlAuth = nil
 	}
 
+	// Initialize OIDC authentication (optional)
+	// Declared as the interface type so a disabled provider is a true nil
+	var oidcAuth auth.OIDCService
+	if oidcConfig := auth.OIDCConfigFromEnv(); oidcConfig != nil {
+		authenticator, err := auth.NewOIDCAuthenticator(oidcConfig)
+		if err != nil {
+			log.Printf("WARNING: OIDC is enabled but initialization failed: %v. OIDC endpoints will return 503.", err)
+		} else {
+			log.Println("OIDC authentication is enabled")
+			oidcAuth = authenticator
+		}
+	} else {
+		log.Println("OIDC authentication is disabled (set OIDC_ENABLED=true to enable)")
+	}
+
+	// Initialize LDAP authentication (optional)
+	var ldapAuth auth.LDAPService
+	if ldapConfig := auth.LDAPConfigFromEnv(); ldapConfig != nil {
+		authenticator, err := auth.NewLDAPAuthenticator(ldapConfig)
+		if err != nil {
+			log.Printf("WARNING: LDAP is enabled but initialization failed: %v. LDAP login will return 503.", err)
+		} else {
+			log.Println("LDAP authentication is enabled")
+			ldapAuth = authenticator
+		}
+	} else {
+		log.Println("LDAP authentication is disabled (set LDAP_URL to enable)")
+	}
+
 	// Initialize API handlers
 	apiHandler := api.NewHandler(database, k8sClient, eventPublisher, connTracker, syncService, wsManager, quotaEnforcer, platform)
+	apiHandler.SetJWTManager(jwtManager, userDB)
+	apiHandler.SetEventEmitter(pluginRuntime)
 	userHandler := handlers.NewUserHandler(userDB, groupDB)
 	groupHandler := handlers.NewGroupHandler(groupDB, userDB)
-	authHandler := auth.NewAuthHandler(userDB, jwtManager, samlAuth)
+	authHandler := auth.NewAuthHandler(userDB, jwtManager, samlAuth, oidcAuth)
+	authHandler.SetLDAPAuthenticator(ldapAuth)
+	totpKey := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if totpKey == "" {
+		totpKey = jwtSecret
+	}
+	authHandler.SetTOTPStore(db.NewTOTPDB(database.DB()), totpKey)
+	authHandler.SetRefreshTokenStore(db.NewRefreshTokenDB(database.DB()))
 	activityHandler := handlers.NewActivityHandler(k8sClient, activityTracker)
 	catalogHandler := handlers.NewCatalogHandler(database)
+	catalogHandler.SetClusterClient(k8sClient)
+	catalogHandler.SetTemplateCache(templateCache)
 	sharingHandler := handlers.NewSharingHandler(database)
+	sharingHandler.SetEventEmitter(pluginRuntime)
 	pluginHandler := handlers.NewPluginHandler(database, pluginDir)
+	pluginHandler.SetPluginLifecycle(pluginRuntime)
+	pluginHandler.SetSecretStore(k8sClient)
+	pluginHandler.SetAPIRegistry(pluginRuntime.GetAPIRegistry())
+	pluginHandler.SetHealthSource(pluginRuntime)
+	pluginHandler.SetTaskSource(pluginRuntime.GetTaskRegistry())
+	pluginHandler.SetEventPermissionSource(pluginRuntime)
+	pluginHandler.SetEventEmitter(pluginRuntime)
 	dashboardHandler := handlers.NewDashboardHandler(database, k8sClient)
 	sessionActivityHandler := handlers.NewSessionActivityHandler(database)
 	apiKeyHandler := handlers.NewAPIKeyHandler(database)
@@ -328,17 +450,24 @@ func main() {
 	templateVersioningHandler := handlers.NewTemplateVersioningHandler(database)
 	setupHandler := handlers.NewSetupHandler(database)
 	applicationHandler := handlers.NewApplicationHandler(database, eventPublisher, k8sClient, platform)
+	auditLogHandler := handlers.NewAuditLogHandler(database)
+	pluginEventsHandler := handlers.NewPluginEventsHandler(pluginRuntime.GetEventBus())
 	// NOTE: Billing is now handled by the streamspace-billing plugin
 
-	// SECURITY: Initialize webhook authentication
-	webhookSecret := os.Getenv("WEBHOOK_SECRET")
-	if webhookSecret == "" {
-		log.Println("WARNING: WEBHOOK_SECRET not set. Webhook authentication will be disabled.")
-		log.Println("         Generate a secret with: openssl rand -hex 32")
-	}
-
 	// Setup routes
-	setupRoutes(router, apiHandler, userHandler, groupHandler, authHandler, activityHandler, catalogHandler, sharingHandler, pluginHandler, dashboardHandler, sessionActivityHandler, apiKeyHandler, teamHandler, preferencesHandler, notificationsHandler, searchHandler, sessionTemplatesHandler, batchHandler, monitoringHandler, quotasHandler, nodeHandler, wsManager, consoleHandler, collaborationHandler, integrationsHandler, loadBalancingHandler, schedulingHandler, securityHandler, templateVersioningHandler, setupHandler, applicationHandler, jwtManager, userDB, redisCache, webhookSecret)
+	setupRoutes(router, apiHandler, userHandler, groupHandler, authHandler, activityHandler, catalogHandler, sharingHandler, pluginHandler, dashboardHandler, sessionActivityHandler, apiKeyHandler, teamHandler, preferencesHandler, notificationsHandler, searchHandler, sessionTemplatesHandler, batchHandler, monitoringHandler, quotasHandler, nodeHandler, wsManager, consoleHandler, collaborationHandler, integrationsHandler, loadBalancingHandler, schedulingHandler, securityHandler, templateVersioningHandler, setupHandler, applicationHandler, auditLogHandler, pluginEventsHandler, jwtManager, userDB, redisCache, jwtSecret)
+
+	// Plugin HTTP endpoints (/api/plugins/{name}/...) registered while the
+	// runtime started. Non-public endpoints get the same authentication, rate
+	// limiting and CSRF protection as /api/v1; plugins loaded later are
+	// mounted on the next restart.
+	pluginAPI := pluginRuntime.GetAPIRegistry()
+	pluginAPI.SetAuthMiddleware(
+		auth.Middleware(jwtManager, userDB),
+		middleware.GetRateLimiter().UserMiddleware(),
+		middleware.CSRFProtection(jwtSecret),
+	)
+	pluginAPI.AttachToRouter(router.Group(""))
 
 	// Create HTTP server with security timeouts
 	srv := &http.Server{
@@ -382,7 +511,27 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	// Shutdown HTTP server (stops accepting new connections)
+	// New requests get 503 from here on
+	drainer.StartDraining()
+
+	// Give plugins a chance to flush state while their dependencies are up
+	log.Println("Notifying plugins of shutdown...")
+	notifyCtx, cancelNotify := context.WithTimeout(ctx, 5*time.Second)
+	for _, err := range pluginRuntime.GetEventBus().EmitSyncCtx(notifyCtx, events.PluginEventPlatformShutdownInitiated, events.PlatformShutdownInitiated{
+		Signal:       sig.String(),
+		DrainTimeout: shutdownTimeout.String(),
+		Timestamp:    time.Now(),
+	}) {
+		log.Printf("Plugin shutdown handler failed: %v", err)
+	}
+	cancelNotify()
+
+	// Stop background work so it can finish while requests drain
+	log.Println("Stopping background workers...")
+	cancelSync()
+	resourceCollector.Stop()
+
+	// Shutdown HTTP server (stops accepting new connections, waits for in-flight requests)
 	log.Println("Shutting down HTTP server...")
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("HTTP server forced to shutdown: %v", err)
@@ -390,12 +539,22 @@ func main() {
 		log.Println("HTTP server stopped gracefully")
 	}
 
+	if running := drainer.Wait(ctx); len(running) > 0 {
+		log.Fatalf("Background goroutines still running after %s, forcing exit: %s", shutdownTimeout, strings.Join(running, ", "))
+	}
+
 	// Close WebSocket connections
 	log.Println("Closing WebSocket connections...")
 	if wsManager != nil {
 		wsManager.CloseAll()
 	}
 
+	// Unload plugins before their database goes away
+	log.Println("Stopping plugin runtime...")
+	if err := pluginRuntime.Stop(ctx); err != nil {
+		log.Printf("Error stopping plugin runtime: %v", err)
+	}
+
 	// Close database connections
 	log.Println("Closing database connections...")
 	if database != nil {
@@ -419,32 +578,21 @@ func main() {
 	log.Println("Graceful shutdown completed")
 }
 
-func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserHandler, groupHandler *handlers.GroupHandler, authHandler *auth.AuthHandler, activityHandler *handlers.ActivityHandler, catalogHandler *handlers.CatalogHandler, sharingHandler *handlers.SharingHandler, pluginHandler *handlers.PluginHandler, dashboardHandler *handlers.DashboardHandler, sessionActivityHandler *handlers.SessionActivityHandler, apiKeyHandler *handlers.APIKeyHandler, teamHandler *handlers.TeamHandler, preferencesHandler *handlers.PreferencesHandler, notificationsHandler *handlers.NotificationsHandler, searchHandler *handlers.SearchHandler, sessionTemplatesHandler *handlers.SessionTemplatesHandler, batchHandler *handlers.BatchHandler, monitoringHandler *handlers.MonitoringHandler, quotasHandler *handlers.QuotasHandler, nodeHandler *handlers.NodeHandler, wsManager *internalWebsocket.Manager, consoleHandler *handlers.ConsoleHandler, collaborationHandler *handlers.CollaborationHandler, integrationsHandler *handlers.IntegrationsHandler, loadBalancingHandler *handlers.LoadBalancingHandler, schedulingHandler *handlers.SchedulingHandler, securityHandler *handlers.SecurityHandler, templateVersioningHandler *handlers.TemplateVersioningHandler, setupHandler *handlers.SetupHandler, applicationHandler *handlers.ApplicationHandler, jwtManager *auth.JWTManager, userDB *db.UserDB, redisCache *cache.Cache, webhookSecret string) {
+func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserHandler, groupHandler *handlers.GroupHandler, authHandler *auth.AuthHandler, activityHandler *handlers.ActivityHandler, catalogHandler *handlers.CatalogHandler, sharingHandler *handlers.SharingHandler, pluginHandler *handlers.PluginHandler, dashboardHandler *handlers.DashboardHandler, sessionActivityHandler *handlers.SessionActivityHandler, apiKeyHandler *handlers.APIKeyHandler, teamHandler *handlers.TeamHandler, preferencesHandler *handlers.PreferencesHandler, notificationsHandler *handlers.NotificationsHandler, searchHandler *handlers.SearchHandler, sessionTemplatesHandler *handlers.SessionTemplatesHandler, batchHandler *handlers.BatchHandler, monitoringHandler *handlers.MonitoringHandler, quotasHandler *handlers.QuotasHandler, nodeHandler *handlers.NodeHandler, wsManager *internalWebsocket.Manager, consoleHandler *handlers.ConsoleHandler, collaborationHandler *handlers.CollaborationHandler, integrationsHandler *handlers.IntegrationsHandler, loadBalancingHandler *handlers.LoadBalancingHandler, schedulingHandler *handlers.SchedulingHandler, securityHandler *handlers.SecurityHandler, templateVersioningHandler *handlers.TemplateVersioningHandler, setupHandler *handlers.SetupHandler, applicationHandler *handlers.ApplicationHandler, auditLogHandler *handlers.AuditLogHandler, pluginEventsHandler *handlers.PluginEventsHandler, jwtManager *auth.JWTManager, userDB *db.UserDB, redisCache *cache.Cache, csrfSecret string) {
 	// SECURITY: Create authentication middleware
 	authMiddleware := auth.Middleware(jwtManager, userDB)
 	adminMiddleware := auth.RequireRole("admin")
 	operatorMiddleware := auth.RequireAnyRole("admin", "operator")
 
-	// SECURITY: Create webhook authentication middleware
-	var webhookAuth *middleware.WebhookAuth
-	if webhookSecret != "" {
-		webhookAuth = middleware.NewWebhookAuth(webhookSecret)
-	}
-
-	// WebSocket upgrader for real-time connections
-	var upgrader = websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			// Allow all origins for development (should be restricted in production)
-			return true
-		},
-	}
-
 	// Health check (public - no auth required)
 	router.GET("/health", h.Health)
 	router.GET("/version", h.Version)
 
+	// Prometheus scrape endpoint and DB pool stats (loopback or METRICS_ALLOWED_CIDRS only)
+	metricsCIDRs := middleware.MetricsAllowedCIDRsFromEnv()
+	router.GET("/metrics", middleware.PrometheusHandler(metricsCIDRs))
+	router.GET("/metrics/db", middleware.MetricsClientsOnly(metricsCIDRs), h.DatabaseStats)
+
 	// API v1
 	v1 := router.Group("/api/v1")
 	{
@@ -455,17 +603,30 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 			setupHandler.RegisterRoutes(authGroup)
 		}
 
+		// Kubernetes API circuit breaker state (public, like /health)
+		v1.GET("/health/kubernetes", h.KubernetesHealth)
+
 		// PROTECTED ROUTES - Require authentication
 		protected := v1.Group("")
 		protected.Use(authMiddleware)
-		protected.Use(middleware.CSRFProtection()) // SECURITY: CSRF protection for all state-changing operations
+		// Per-user rate limiting (falls back to client IP when unauthenticated)
+		protected.Use(middleware.GetRateLimiter().UserMiddleware())
+		protected.Use(middleware.CSRFProtection(csrfSecret)) // SECURITY: CSRF protection for all state-changing operations
 		{
+			// TOTP enrollment for local accounts (login enforcement is in AuthHandler.Login)
+			totpGroup := protected.Group("/auth/totp")
+			{
+				totpGroup.POST("/setup", authHandler.TOTPSetup)
+				totpGroup.POST("/verify", authHandler.TOTPVerify)
+			}
+
 			// Sessions (authenticated users only)
 			sessions := protected.Group("/sessions")
 			{
 				// Cache session lists for 30 seconds (frequently changing)
 				sessions.GET("", cache.CacheMiddleware(redisCache, 30*time.Second), h.ListSessions)
 				sessions.POST("", cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.CreateSession)
+				sessions.POST("/bulk", cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.BulkSessionOperation)
 				sessions.GET("/by-tags", cache.CacheMiddleware(redisCache, 30*time.Second), h.ListSessionsByTags)
 				sessions.GET("/:id", cache.CacheMiddleware(redisCache, 30*time.Second), h.GetSession)
 				sessions.PATCH("/:id", cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.UpdateSession)
@@ -473,6 +634,11 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 				sessions.PATCH("/:id/tags", cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.UpdateSessionTags)
 				sessions.GET("/:id/connect", h.ConnectSession)
 				sessions.POST("/:id/disconnect", h.DisconnectSession)
+				sessions.GET("/:id/metrics", h.GetSessionMetrics)
+				sessions.POST("/:id/pin-template", h.PinSessionTemplate)
+				sessions.GET("/:id/template-drift", h.GetSessionTemplateDrift)
+				sessions.POST("/:id/restore", adminMiddleware, cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.RestoreSession)
+				sessions.POST("/:id/force-kill", adminMiddleware, cache.InvalidateCacheMiddleware(redisCache, cache.SessionPattern()), h.ForceKillSession)
 
 				// NOTE: Session heartbeat is registered by ActivityHandler.RegisterRoutes()
 				// NOTE: Session recording is now handled by the streamspace-recording plugin
@@ -707,6 +873,14 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 				}
 			}
 
+			// Repository sync (operators/admins) and webhook secrets (admins only)
+			repositories := protected.Group("/repositories")
+			{
+				repositories.POST("/:id/sync", operatorMiddleware, h.SyncRepository)
+				repositories.GET("/:id/sync/stream", operatorMiddleware, h.StreamRepositorySync)
+				repositories.PUT("/:id/webhook-secret", adminMiddleware, h.RotateRepositoryWebhookSecret)
+			}
+
 			// Cluster management (operators/admins only)
 			cluster := protected.Group("/cluster")
 			cluster.Use(operatorMiddleware)
@@ -750,6 +924,9 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 			// Plugin system - using dedicated handler
 			pluginHandler.RegisterRoutes(protected)
 
+			// Plugin event catalog (event types and payload shapes for plugin authors)
+			protected.GET("/plugins/events/catalog", pluginEventsHandler.EventCatalog)
+
 			// Installed applications management - using dedicated handler (admin only for management)
 			applicationHandler.RegisterRoutes(protected)
 
@@ -827,6 +1004,14 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 				apiKeys.GET("/:id/usage", cache.CacheMiddleware(redisCache, 30*time.Second), apiKeyHandler.GetAPIKeyUsage)
 			}
 
+			// Same API key management under the current user (used by the CLI and service accounts)
+			myAPIKeys := protected.Group("/users/me/api-keys")
+			{
+				myAPIKeys.POST("", apiKeyHandler.CreateAPIKey)
+				myAPIKeys.GET("", apiKeyHandler.ListAPIKeys)
+				myAPIKeys.DELETE("/:id", apiKeyHandler.DeleteAPIKey)
+			}
+
 			// User preferences and settings - using dedicated handler (all authenticated users)
 			preferencesHandler.RegisterRoutes(protected)
 
@@ -865,6 +1050,25 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 				admin.POST("/nodes/:name/cordon", nodeHandler.CordonNode)
 				admin.POST("/nodes/:name/uncordon", nodeHandler.UncordonNode)
 				admin.POST("/nodes/:name/drain", nodeHandler.DrainNode)
+
+				// Plugin event bus metrics (handler latency and failures per plugin)
+				admin.GET("/plugins/events/metrics", pluginEventsHandler.EventMetrics)
+
+				// Plugin event replay (for debugging plugin handlers)
+				admin.POST("/plugins/events/replay", pluginEventsHandler.ReplayEvents)
+				admin.POST("/plugins/events/:id/replay", pluginEventsHandler.ReplayEvent)
+
+				// Response cache (drop cached catalog responses before their TTL)
+				admin.POST("/cache/invalidate", middleware.CacheInvalidateHandler())
+			}
+
+			// Audit log (admins query/export; only superadmins may purge)
+			auditLog := protected.Group("/admin/audit-log")
+			auditLog.Use(auth.RequireAnyRole("admin", "superadmin"))
+			{
+				auditLog.GET("", auditLogHandler.ListAuditLog)
+				auditLog.GET("/export", auditLogHandler.ExportAuditLog)
+				auditLog.POST("/purge", auth.RequireRole("superadmin"), auditLogHandler.PurgeAuditLog)
 			}
 
 			// NOTE: Billing is now handled by the streamspace-billing plugin
@@ -876,63 +1080,22 @@ func setupRoutes(router *gin.Engine, h *api.Handler, userHandler *handlers.UserH
 	}
 
 	// WebSocket endpoints (require authentication)
+	// Sessions, cluster and logs authenticate the upgrade request themselves
+	// (?token= or "jwt.<token>" sub-protocol) and close with code 4001 on failure.
 	ws := router.Group("/api/v1/ws")
-	ws.Use(authMiddleware)
 	{
-		// Session updates WebSocket - connects to wsManager for real-time session broadcasts
-		ws.GET("/sessions", func(c *gin.Context) {
-			// Get user ID from auth middleware
-			userID, exists := c.Get("userID")
-			if !exists {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-				return
-			}
-
-			userIDStr, ok := userID.(string)
-			if !ok {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
-				return
-			}
-
-			// Upgrade HTTP connection to WebSocket
-			conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-			if err != nil {
-				log.Printf("Failed to upgrade WebSocket connection: %v", err)
-				return
-			}
-
-			// Delegate to wsManager which broadcasts sessions every 3 seconds
-			wsManager.HandleSessionsWebSocket(conn, userIDStr, "")
-		})
-
-		// Metrics WebSocket - connects to wsManager for real-time metrics broadcasts
-		ws.GET("/cluster", operatorMiddleware, func(c *gin.Context) {
-			// Upgrade HTTP connection to WebSocket
-			conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-			if err != nil {
-				log.Printf("Failed to upgrade WebSocket connection: %v", err)
-				return
-			}
-
-			// Delegate to wsManager which broadcasts metrics every 5 seconds
-			wsManager.HandleMetricsWebSocket(conn)
-		})
-
-		ws.GET("/logs/:namespace/:pod", operatorMiddleware, h.LogsWebSocket)
-		ws.GET("/enterprise", handlers.HandleEnterpriseWebSocket) // Real-time enterprise features
+		ws.GET("/sessions", h.SessionsWebSocket)
+		ws.GET("/cluster", h.ClusterWebSocket)
+		ws.GET("/logs/:namespace/:pod", h.LogsWebSocket)
+		ws.GET("/enterprise", authMiddleware, handlers.HandleEnterpriseWebSocket) // Real-time enterprise features
 	}
 
-	// Webhook endpoints (HMAC signature validation required)
+	// Webhook endpoints
+	// SECURITY: Each repository has its own webhook secret; the handler verifies
+	// the X-Hub-Signature-256 header against it and rejects unsigned requests.
 	webhooks := router.Group("/webhooks")
 	{
-		if webhookAuth != nil {
-			// SECURITY: Require webhook signature validation
-			webhooks.POST("/repository/sync", webhookAuth.Middleware(), h.WebhookRepositorySync)
-		} else {
-			// WARNING: Running without webhook authentication
-			log.Println("WARNING: Webhook endpoints running without authentication")
-			webhooks.POST("/repository/sync", h.WebhookRepositorySync)
-		}
+		webhooks.POST("/repository/sync", h.WebhookRepositorySync)
 	}
 }
 
@@ -1010,6 +1173,23 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// registerPluginEventSchemas declares the payload types of the platform
+// events delivered to plugins, so mismatched payloads are rejected at Emit
+// and plugin authors can discover them in the event catalog.
+func registerPluginEventSchemas(bus *plugins.EventBus) {
+	bus.RegisterEventSchema(events.PluginEventSessionHibernated, "A session entered the hibernated state", events.SessionStateChange{})
+	bus.RegisterEventSchema(events.PluginEventSessionWoken, "A hibernated session is running again", events.SessionStateChange{})
+	bus.RegisterEventSchema(handlers.EventSessionCollaboratorAdded, "A user was invited to collaborate on a session", handlers.CollaboratorAddedEvent{})
+	bus.RegisterEventSchema(handlers.EventPluginUpgraded, "An installed plugin was upgraded to its catalog version", handlers.PluginUpgradedEvent{})
+	bus.RegisterEventSchema(handlers.EventSessionCollaboratorRemoved, "A collaborator was removed from a session", handlers.CollaboratorRemovedEvent{})
+	bus.RegisterEventSchema(k8s.EventCircuitOpened, "The Kubernetes API circuit breaker opened", k8s.CircuitOpenedEvent{})
+	bus.RegisterEventSchema(plugins.EventPluginCrashed, "A plugin's HTTP endpoints were disabled after repeated panics", plugins.PluginCrashedEvent{})
+	bus.RegisterEventSchema(plugins.EventPluginViolatedTimeout, "A plugin was disabled after its event handlers repeatedly exceeded their time limit", plugins.PluginViolationEvent{})
+	bus.RegisterEventSchema(plugins.EventPluginUnhealthy, "A loaded plugin became degraded, e.g. after repeated handler timeouts", plugins.PluginUnhealthyEvent{})
+	bus.RegisterEventSchema(events.PluginEventPlatformShutdownInitiated, "The API started a graceful shutdown", events.PlatformShutdownInitiated{})
+	bus.RegisterEventSchema(events.PluginEventSessionForceKilled, "An admin force-killed a session's pod", events.SessionForceKilled{})
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
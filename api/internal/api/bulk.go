@@ -0,0 +1,207 @@
+// Package api provides the core REST API handlers for StreamSpace.
+//
+// This file implements bulk session operations.
+//
+// BULK OPERATIONS:
+//
+// POST /api/v1/sessions/bulk applies one action to many sessions in a single
+// synchronous request:
+//
+//	{"action": "stop|hibernate|delete", "sessionIds": ["s1", "s2", ...]}
+//
+// Actions map to the same controller events as the single-session endpoints:
+//   - stop: SessionDeleteEvent, as PATCH /sessions/:id with state=terminated
+//   - hibernate: SessionHibernateEvent, as PATCH /sessions/:id with state=hibernated
+//   - delete: SessionDeleteEvent, as DELETE /sessions/:id
+//
+// EXECUTION:
+//
+//   - Each session is processed by a bounded worker pool; parallelism is set
+//     by BULK_OP_CONCURRENCY (default 5)
+//   - Partial failures never abort the batch; every session is attempted
+//   - Response: {"succeeded": [...], "failed": [{"id": ..., "error": ...}]}
+//
+// AUTHORIZATION:
+//
+//   - Admins may operate on any session (ownership check skipped)
+//   - Other users may only operate on sessions they own; sessions owned by
+//     someone else are reported as failed, not silently skipped
+//
+// Unlike the asynchronous /batch/sessions/* jobs, bulk requests return the
+// per-session outcome directly, which suits admin tooling such as cleaning
+// up every session of a deactivated user.
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/events"
+)
+
+const (
+	// defaultBulkConcurrency is the worker pool size when BULK_OP_CONCURRENCY is unset
+	defaultBulkConcurrency = 5
+
+	// maxBulkSessions caps the number of sessions in a single bulk request
+	maxBulkSessions = 500
+)
+
+// BulkSessionRequest is the request body for POST /sessions/bulk
+type BulkSessionRequest struct {
+	Action     string   `json:"action" binding:"required"`
+	SessionIDs []string `json:"sessionIds" binding:"required"`
+}
+
+// BulkSessionFailure describes a session that could not be processed
+type BulkSessionFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// BulkSessionOperation applies stop, hibernate, or delete to many sessions.
+func (h *Handler) BulkSessionOperation(c *gin.Context) {
+	var req BulkSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Action != "stop" && req.Action != "hibernate" && req.Action != "delete" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action. Must be: stop, hibernate, or delete"})
+		return
+	}
+
+	sessionIDs := dedupeSessionIDs(req.SessionIDs)
+	if len(sessionIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sessionIds must not be empty"})
+		return
+	}
+	if len(sessionIDs) > maxBulkSessions {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Too many sessions (max %d per request)", maxBulkSessions),
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	isAdmin := c.GetString("userRole") == "admin"
+
+	// Detach from request cancellation so a client disconnect does not
+	// leave the batch half-applied
+	ctx := context.WithoutCancel(c.Request.Context())
+
+	succeeded, failed := runBulk(sessionIDs, bulkConcurrency(), func(sessionID string) error {
+		return h.applySessionAction(ctx, sessionID, req.Action, userID, isAdmin)
+	})
+
+	log.Printf("[Bulk] %s on %d sessions by %s: %d succeeded, %d failed",
+		req.Action, len(sessionIDs), userID, len(succeeded), len(failed))
+
+	c.JSON(http.StatusOK, gin.H{
+		"action":    req.Action,
+		"succeeded": succeeded,
+		"failed":    failed,
+	})
+}
+
+// applySessionAction performs a single bulk action on one session.
+func (h *Handler) applySessionAction(ctx context.Context, sessionID, action, userID string, isAdmin bool) error {
+	session, err := h.k8sClient.GetSession(ctx, h.namespace, sessionID)
+	if err != nil {
+		return fmt.Errorf("session not found")
+	}
+
+	if !isAdmin && session.User != userID {
+		return fmt.Errorf("session not owned by user")
+	}
+
+	switch action {
+	case "hibernate":
+		return h.publisher.PublishSessionHibernate(ctx, &events.SessionHibernateEvent{
+			SessionID: sessionID,
+			UserID:    session.User,
+			Platform:  h.platform,
+		})
+	case "stop", "delete":
+		return h.publisher.PublishSessionDelete(ctx, &events.SessionDeleteEvent{
+			SessionID: sessionID,
+			UserID:    session.User,
+			Platform:  h.platform,
+		})
+	default:
+		return fmt.Errorf("unknown action: %s", action)
+	}
+}
+
+// runBulk calls op for every ID using at most concurrency goroutines.
+//
+// Results preserve the input order of IDs. A failing op never stops the
+// remaining IDs from being processed.
+func runBulk(ids []string, concurrency int, op func(id string) error) ([]string, []BulkSessionFailure) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(ids))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = op(ids[i])
+			}
+		}()
+	}
+
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	succeeded := []string{}
+	failed := []BulkSessionFailure{}
+	for i, id := range ids {
+		if errs[i] != nil {
+			failed = append(failed, BulkSessionFailure{ID: id, Error: errs[i].Error()})
+		} else {
+			succeeded = append(succeeded, id)
+		}
+	}
+	return succeeded, failed
+}
+
+// bulkConcurrency returns the worker pool size from BULK_OP_CONCURRENCY.
+func bulkConcurrency() int {
+	if value := os.Getenv("BULK_OP_CONCURRENCY"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("[Bulk] Invalid BULK_OP_CONCURRENCY %q, using default %d", value, defaultBulkConcurrency)
+	}
+	return defaultBulkConcurrency
+}
+
+// dedupeSessionIDs removes empty and duplicate IDs, preserving order.
+func dedupeSessionIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
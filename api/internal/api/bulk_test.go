@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBulk_PartialFailure(t *testing.T) {
+	ids := []string{"s1", "s2", "s3", "s4"}
+
+	succeeded, failed := runBulk(ids, 2, func(id string) error {
+		if id == "s2" {
+			return errors.New("session not found")
+		}
+		return nil
+	})
+
+	assert.Equal(t, []string{"s1", "s3", "s4"}, succeeded)
+	assert.Equal(t, []BulkSessionFailure{{ID: "s2", Error: "session not found"}}, failed)
+}
+
+func TestRunBulk_RespectsConcurrency(t *testing.T) {
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = string(rune('a' + i))
+	}
+
+	var active, peak int32
+	runBulk(ids, 3, func(id string) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	})
+
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestBulkConcurrency(t *testing.T) {
+	t.Setenv("BULK_OP_CONCURRENCY", "")
+	assert.Equal(t, defaultBulkConcurrency, bulkConcurrency())
+
+	t.Setenv("BULK_OP_CONCURRENCY", "12")
+	assert.Equal(t, 12, bulkConcurrency())
+
+	t.Setenv("BULK_OP_CONCURRENCY", "zero")
+	assert.Equal(t, defaultBulkConcurrency, bulkConcurrency())
+}
+
+func TestDedupeSessionIDs(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, dedupeSessionIDs([]string{"a", "", "b", "a"}))
+}
@@ -95,6 +95,8 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -105,6 +107,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/streamspace/streamspace/api/internal/auth"
 	"github.com/streamspace/streamspace/api/internal/db"
 	"github.com/streamspace/streamspace/api/internal/events"
 	"github.com/streamspace/streamspace/api/internal/k8s"
@@ -164,6 +167,10 @@ type Handler struct {
 	quotaEnforcer  *quota.Enforcer              // Resource quota enforcement
 	namespace      string                       // Kubernetes namespace for resources
 	platform       string                       // Target platform (kubernetes, docker, etc.)
+	jwtManager     *auth.JWTManager             // JWT validation for WebSocket connections
+	wsUsers        WebSocketUserStore           // Active-user checks for WebSocket connections
+	pods           SessionPodKiller             // Force-deletes stuck session pods
+	emitter        EventEmitter                 // Plugin event delivery (optional)
 }
 
 // NewHandler creates a new API handler with injected dependencies.
@@ -199,7 +206,7 @@ func NewHandler(database *db.Database, k8sClient *k8s.Client, publisher *events.
 	if platform == "" {
 		platform = events.PlatformKubernetes // Default platform
 	}
-	return &Handler{
+	h := &Handler{
 		db:            database,
 		sessionDB:     db.NewSessionDB(database.DB()),
 		k8sClient:     k8sClient,
@@ -211,6 +218,10 @@ func NewHandler(database *db.Database, k8sClient *k8s.Client, publisher *events.
 		namespace:     namespace,
 		platform:      platform,
 	}
+	if k8sClient != nil {
+		h.pods = k8sClient
+	}
+	return h
 }
 
 // ============================================================================
@@ -229,6 +240,8 @@ func NewHandler(database *db.Database, k8sClient *k8s.Client, publisher *events.
 // - user (optional): Filter sessions by user ID
 //   - If provided: Returns sessions for that specific user
 //   - If omitted: Returns all sessions (requires admin role)
+//   - Ignored for non-admins, who always get their own sessions and the
+//     sessions they collaborate on
 //
 // REQUEST EXAMPLE:
 //
@@ -263,6 +276,14 @@ func (h *Handler) ListSessions(c *gin.Context) {
 	// SECURITY FIX: Use request context for proper cancellation and timeout handling
 	ctx := c.Request.Context()
 	userID := c.Query("user")
+	if c.GetString("userRole") != "admin" {
+		userID = c.GetString("userID")
+	}
+
+	if c.Query("archived") == "true" {
+		h.listArchivedSessions(c)
+		return
+	}
 
 	// Use database as source of truth for multi-platform support
 	var dbSessions []*db.Session
@@ -298,12 +319,74 @@ func (h *Handler) ListSessions(c *gin.Context) {
 	// Convert database sessions to API response format
 	sessions := h.convertDBSessionsToResponse(dbSessions)
 
+	// Include sessions the user collaborates on, flagged as shared
+	if userID != "" {
+		shared, err := h.sessionDB.ListCollaboratingSessions(ctx, userID)
+		if err != nil {
+			log.Printf("Failed to list collaborating sessions for user %s: %v", userID, err)
+		}
+		for _, session := range h.convertDBSessionsToResponse(shared) {
+			session["sharedWith"] = true
+			sessions = append(sessions, session)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"sessions": sessions,
 		"total":    len(sessions),
 	})
 }
 
+// listArchivedSessions handles GET /sessions?archived=true.
+//
+// Only admins may list archived (soft-deleted) sessions. Archived sessions
+// are never read from Kubernetes since their resources are gone.
+func (h *Handler) listArchivedSessions(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	dbSessions, err := h.sessionDB.ListArchivedSessions(c.Request.Context())
+	if err != nil {
+		log.Printf("Failed to list archived sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list archived sessions"})
+		return
+	}
+
+	sessions := h.convertDBSessionsToResponse(dbSessions)
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"total":    len(sessions),
+	})
+}
+
+// RestoreSession handles POST /sessions/:id/restore (admin only).
+//
+// Clears archived_at on a soft-deleted session together with its
+// connections and metrics. Snapshots deleted during archival are not
+// restored. The Kubernetes resources are not recreated; the restored
+// session keeps its last known state.
+func (h *Handler) RestoreSession(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	err := h.sessionDB.RestoreSession(c.Request.Context(), sessionID)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Archived session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to restore session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":    sessionID,
+		"message": "Session restored",
+	})
+}
+
 // GetSession returns a single session by ID
 func (h *Handler) GetSession(c *gin.Context) {
 	// SECURITY FIX: Use request context for proper cancellation and timeout handling
@@ -722,6 +805,9 @@ func (h *Handler) CreateSession(c *gin.Context) {
 	}
 	if err := h.sessionDB.CreateSession(ctx, dbSession); err != nil {
 		log.Printf("Failed to cache session %s in database (non-fatal): %v", sessionName, err)
+	} else if _, err := h.sessionDB.PinTemplateVersion(ctx, sessionName); err != nil {
+		// Pin to the catalog template version so restarts can detect drift
+		log.Printf("Failed to pin template version for session %s (non-fatal): %v", sessionName, err)
 	}
 
 	// Return the session info immediately
@@ -812,11 +898,17 @@ func (h *Handler) UpdateSession(c *gin.Context) {
 	}
 
 	log.Printf("Published session %s event for %s (controller will update resources)", req.State, sessionID)
-	c.JSON(http.StatusAccepted, gin.H{
+	response := gin.H{
 		"name":    sessionID,
 		"state":   req.State,
 		"message": "State change requested, waiting for controller",
-	})
+	}
+	if req.State == "running" {
+		if warning := h.templateDriftWarning(ctx, sessionID); warning != "" {
+			response["warning"] = warning
+		}
+	}
+	c.JSON(http.StatusAccepted, response)
 }
 
 // DeleteSession deletes a session
@@ -1879,7 +1971,7 @@ func (h *Handler) AddRepository(c *gin.Context) {
 	// Trigger repository sync in background
 	go func() {
 		syncCtx := context.Background()
-		if err := h.syncService.SyncRepository(syncCtx, int(id)); err != nil {
+		if err := h.syncService.SyncRepository(syncCtx, int(id), nil); err != nil {
 			log.Printf("Background sync failed for repository %d: %v", id, err)
 		} else {
 			log.Printf("Background sync completed for repository %d", id)
@@ -1887,7 +1979,11 @@ func (h *Handler) AddRepository(c *gin.Context) {
 	}()
 }
 
-// SyncRepository triggers a sync for a repository
+// SyncRepository triggers a sync for a repository.
+//
+// With ?dryRun=true the sync runs synchronously without writing anything
+// and the response is the diff (added/removed/modified templates and
+// plugins) the real sync would apply.
 func (h *Handler) SyncRepository(c *gin.Context) {
 	repoIDStr := c.Param("id")
 
@@ -1898,11 +1994,31 @@ func (h *Handler) SyncRepository(c *gin.Context) {
 		return
 	}
 
+	if c.Query("dryRun") == "true" {
+		diff, err := h.syncService.SyncRepositoryDryRun(c.Request.Context(), repoID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Repository not found"})
+				return
+			}
+			log.Printf("Dry-run sync failed for repository %d: %v", repoID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Dry-run sync failed", "message": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"repositoryId": repoID,
+			"dryRun":       true,
+			"diff":         diff,
+		})
+		return
+	}
+
 	// Trigger sync in background
 	// BUG FIX: Use context.Background() for goroutine - request context will be cancelled when HTTP request completes
 	go func() {
 		syncCtx := context.Background()
-		if err := h.syncService.SyncRepository(syncCtx, repoID); err != nil {
+		if err := h.syncService.SyncRepository(syncCtx, repoID, nil); err != nil {
 			log.Printf("Repository sync failed for ID %d: %v", repoID, err)
 		}
 	}()
@@ -1913,6 +2029,73 @@ func (h *Handler) SyncRepository(c *gin.Context) {
 	})
 }
 
+// syncStreamHeartbeat is how often StreamRepositorySync writes a keep-alive
+// ping so proxies do not close an idle connection.
+const syncStreamHeartbeat = 15 * time.Second
+
+// StreamRepositorySync triggers a sync for a repository and streams its
+// progress as Server-Sent Events.
+//
+// Each stage is written as a JSON data line, e.g.
+//
+//	data: {"stage":"parsing","templatesFound":5}
+//
+// ending with a "done" or "error" stage. "data: ping" is written every 15
+// seconds while the sync runs. The sync continues in the background if the
+// client disconnects.
+func (h *Handler) StreamRepositorySync(c *gin.Context) {
+	var repoID int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &repoID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repository ID"})
+		return
+	}
+
+	progress := sync.NewSyncProgress()
+	go func() {
+		// Use context.Background() - the sync must not stop when the client disconnects
+		if err := h.syncService.SyncRepository(context.Background(), repoID, progress); err != nil {
+			log.Printf("Repository sync failed for ID %d: %v", repoID, err)
+		}
+	}()
+
+	writeSyncProgressStream(c, progress, syncStreamHeartbeat)
+}
+
+// writeSyncProgressStream writes progress updates as SSE until progress is
+// closed or the client goes away.
+func writeSyncProgressStream(c *gin.Context, progress <-chan sync.SyncProgress, heartbeat time.Duration) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case update, ok := <-progress:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(update)
+			if err != nil {
+				log.Printf("Failed to encode sync progress: %v", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+			c.Writer.Flush()
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, "data: ping\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
 // DeleteRepository deletes a template repository
 func (h *Handler) DeleteRepository(c *gin.Context) {
 	// SECURITY FIX: Use request context for proper cancellation and timeout handling
@@ -2080,6 +2263,10 @@ func (h *Handler) convertDBSessionToResponse(session *db.Session) map[string]int
 		result["status"].(map[string]interface{})["lastActivity"] = session.LastActivity
 	}
 
+	if session.ArchivedAt != nil {
+		result["archivedAt"] = session.ArchivedAt
+	}
+
 	return result
 }
 
@@ -2169,37 +2356,34 @@ func (h *Handler) updateSessionInDB(ctx context.Context, session *k8s.Session) e
 	return err
 }
 
-// deleteSessionFromDB removes a session from the database cache.
+// deleteSessionFromDB archives a session in the database cache.
 //
 // DATABASE TRANSACTION BOUNDARY:
 //
-// - Single DELETE query
-// - No explicit transaction needed
-// - Idempotent: Safe to call even if session doesn't exist
+// - Single transaction (see db.SessionDB.ArchiveSession)
+// - Idempotent: Safe to call even if session doesn't exist or is
+//   already archived
 //
 // CLEANUP STRATEGY:
 //
-// When a session is deleted from Kubernetes, we also remove it from
-// the database cache to prevent stale data.
+// When a session is deleted from Kubernetes, the row is soft-deleted
+// (archived_at is set) so it disappears from all queries but can still be
+// restored by an admin via POST /sessions/:id/restore.
 //
 // CASCADE BEHAVIOR:
 //
-// Database schema may have CASCADE DELETE for related tables:
-// - session_connections (active connections)
-// - session_snapshots (saved states)
-// - audit_logs (may be preserved)
-//
-// Check database schema for exact CASCADE behavior.
+// - connections: archived_at set
+// - session_snapshots: status set to 'deleted'
+// - session_resource_metrics: archived_at set
 //
 // ERROR HANDLING:
 //
 // - Returns error on database failure
 // - Callers typically log and ignore (best-effort cleanup)
-// - Stale cache entries cleaned up by periodic garbage collection
 func (h *Handler) deleteSessionFromDB(ctx context.Context, sessionID string) error {
-	_, err := h.db.DB().ExecContext(ctx, `
-		DELETE FROM sessions WHERE id = $1
-	`, sessionID)
-
+	err := h.sessionDB.ArchiveSession(ctx, sessionID)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		return nil
+	}
 	return err
 }
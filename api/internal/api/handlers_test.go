@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockK8sClient is a mock implementation of the Kubernetes client
@@ -49,6 +55,23 @@ func TestHealth(t *testing.T) {
 	assert.Equal(t, "streamspace-api", response["service"])
 }
 
+func TestHealth_DatabaseUnavailable(t *testing.T) {
+	handler, mock, w, c := newHandlerTest(t, http.MethodGet, "/health", "")
+
+	mock.ExpectQuery(`SELECT 1`).
+		WillReturnError(errors.New(`dial tcp 10.0.0.5:5432: connect: connection refused (user "streamspace")`))
+
+	handler.Health(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "degraded", response["status"])
+	assert.Equal(t, "database unavailable", response["database"])
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestVersion(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -127,6 +150,26 @@ func createTestContext() (*gin.Context, *httptest.ResponseRecorder) {
 	return c, w
 }
 
+// newHandlerTest returns a Handler whose databases are backed by sqlmock and
+// a gin context for a request to target. The mock is closed when the test
+// finishes.
+func newHandlerTest(t *testing.T, method, target, body string) (*Handler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	handler := &Handler{db: db.NewDatabaseFromDB(mockDB), sessionDB: db.NewSessionDB(mockDB)}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+
+	return handler, mock, w, c
+}
+
 func TestListPods_Success(t *testing.T) {
 	// This test would require a more complete mock setup
 	// Placeholder for future implementation
@@ -160,6 +203,60 @@ func TestGetPodLogs_MissingPodName(t *testing.T) {
 }
 
 // Benchmark tests
+// sessionRows returns sessions rows as scanned by SessionDB, one per owner.
+func sessionRows(owners ...string) *sqlmock.Rows {
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"id", "user_id", "team_id", "template_name", "state", "app_type",
+		"active_connections", "url", "namespace", "platform", "pod_name",
+		"memory", "cpu", "persistent_home", "idle_timeout", "max_session_duration",
+		"created_at", "updated_at", "last_connection", "last_disconnect", "last_activity",
+	})
+	for _, owner := range owners {
+		rows.AddRow(owner+"-firefox", owner, "", "firefox", "running", "desktop",
+			0, "https://"+owner+".example.com", "streamspace", "kubernetes", owner+"-pod",
+			"", "", false, "", "",
+			now, now, nil, nil, nil)
+	}
+	return rows
+}
+
+func TestListSessions_UserFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		wantUser string
+	}{
+		{name: "admin may list another user", role: "admin", wantUser: "alice"},
+		{name: "user only lists own sessions", role: "user", wantUser: "bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mock, w, c := newHandlerTest(t, http.MethodGet, "/api/sessions?user=alice", "")
+			c.Set("userID", "bob")
+			c.Set("userRole", tt.role)
+
+			mock.ExpectQuery(`FROM sessions\s+WHERE user_id = \$1`).
+				WithArgs(tt.wantUser).
+				WillReturnRows(sessionRows(tt.wantUser))
+			mock.ExpectQuery(`JOIN session_collaborators`).
+				WithArgs(tt.wantUser).
+				WillReturnRows(sessionRows("carol"))
+
+			handler.ListSessions(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var resp struct {
+				Total int `json:"total"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, 2, resp.Total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func BenchmarkHealth(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 	handler := &Handler{}
@@ -0,0 +1,153 @@
+// Package api provides the core REST API handlers for StreamSpace.
+//
+// This file implements force-killing stuck sessions.
+//
+// FORCE KILL:
+//
+// A session pod can get stuck terminating (hung process, unresponsive
+// node), so that the normal stop/delete flow through the controller never
+// completes. An admin can then delete the pod with a zero grace period:
+//
+//   - POST /api/v1/sessions/:id/force-kill - Force-terminate the session's pod (admin only)
+//
+// Request Body (optional):
+//
+//	{"reason": "pod stuck in Terminating after node failure"}
+//
+// The Session resource is set to terminated first, so the controller removes
+// the Deployment instead of recreating the pod. The session is then marked
+// terminated and archived, the kill is recorded in the audit log with the
+// reason, and plugins receive session.force_killed.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/events"
+	"github.com/streamspace/streamspace/api/internal/k8s"
+	"github.com/streamspace/streamspace/api/internal/middleware"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// SessionPodKiller terminates a session's Session resource and force-deletes
+// its pods.
+//
+// *k8s.Client implements this interface; it is declared here so the handler
+// can be tested without a cluster.
+type SessionPodKiller interface {
+	UpdateSessionState(ctx context.Context, namespace, name, state string) (*k8s.Session, error)
+	ForceDeleteSessionPods(ctx context.Context, namespace, sessionID, podName string) ([]string, error)
+}
+
+// EventEmitter delivers events to plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// api package does not depend on the plugin runtime.
+type EventEmitter interface {
+	EmitEventWithContext(ctx context.Context, eventType string, data interface{})
+}
+
+// ForceKillRequest is the optional body of POST /sessions/:id/force-kill.
+type ForceKillRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SetEventEmitter sets where session events raised by the API are emitted
+// for plugins.
+func (h *Handler) SetEventEmitter(emitter EventEmitter) {
+	h.emitter = emitter
+}
+
+// ForceKillSession handles POST /sessions/:id/force-kill (admin only).
+//
+// Sets the Session resource to terminated so its Deployment does not
+// recreate the pod, deletes the session's pods with a zero grace period,
+// then marks the session terminated and archives it. Unlike DeleteSession,
+// this does not wait for the controller. A Session resource that is already
+// gone is not an error.
+func (h *Handler) ForceKillSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	var req ForceKillRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "message": err.Error()})
+		return
+	}
+
+	if h.pods == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not configured"})
+		return
+	}
+
+	session, err := h.sessionDB.GetSession(ctx, sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	namespace := session.Namespace
+	if namespace == "" {
+		namespace = h.namespace
+	}
+
+	if _, err := h.pods.UpdateSessionState(ctx, namespace, sessionID, "terminated"); err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("Failed to terminate session resource %s before force kill: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to terminate session", "message": err.Error()})
+		return
+	}
+
+	pods, err := h.pods.ForceDeleteSessionPods(ctx, namespace, sessionID, session.PodName)
+	if err != nil {
+		log.Printf("Failed to force kill session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force kill session pod", "message": err.Error()})
+		return
+	}
+
+	if err := h.sessionDB.TerminateSession(ctx, sessionID); err != nil && !errors.Is(err, db.ErrSessionNotFound) {
+		log.Printf("Force killed pods %v of session %s but failed to mark it terminated: %v", pods, sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark session terminated", "message": err.Error()})
+		return
+	}
+
+	adminID := c.GetString("userID")
+	h.recordForceKill(ctx, c, sessionID, req.Reason, pods)
+	if h.emitter != nil {
+		h.emitter.EmitEventWithContext(middleware.TraceContext(c), events.PluginEventSessionForceKilled, events.SessionForceKilled{
+			SessionID: sessionID,
+			AdminID:   adminID,
+			Reason:    req.Reason,
+		})
+	}
+
+	log.Printf("Admin %s force killed session %s (pods %v): %s", adminID, sessionID, pods, req.Reason)
+	c.JSON(http.StatusOK, gin.H{
+		"name":        sessionID,
+		"state":       "terminated",
+		"deletedPods": pods,
+		"message":     "Session pod force killed",
+	})
+}
+
+// recordForceKill writes the force kill to the audit log. Failures are
+// logged, since the pod is already gone.
+func (h *Handler) recordForceKill(ctx context.Context, c *gin.Context, sessionID, reason string, pods []string) {
+	changes, _ := json.Marshal(map[string]interface{}{
+		"reason": reason,
+		"pods":   pods,
+	})
+
+	if _, err := h.db.DB().ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, action, resource_type, resource_id, changes, timestamp, ip_address)
+		VALUES ($1, 'session.force_kill', 'session', $2, $3, CURRENT_TIMESTAMP, $4)
+	`, c.GetString("userID"), sessionID, changes, c.ClientIP()); err != nil {
+		log.Printf("Failed to record force kill of session %s in audit log: %v", sessionID, err)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/events"
+	"github.com/streamspace/streamspace/api/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePodKiller struct {
+	namespace, sessionID, podName string
+	state                         string
+	stateErr                      error
+	calls                         []string
+}
+
+func (f *fakePodKiller) UpdateSessionState(ctx context.Context, namespace, name, state string) (*k8s.Session, error) {
+	f.calls = append(f.calls, "UpdateSessionState")
+	if f.stateErr != nil {
+		return nil, f.stateErr
+	}
+	f.state = state
+	return &k8s.Session{Name: name, Namespace: namespace, State: state}, nil
+}
+
+func (f *fakePodKiller) ForceDeleteSessionPods(ctx context.Context, namespace, sessionID, podName string) ([]string, error) {
+	f.calls = append(f.calls, "ForceDeleteSessionPods")
+	f.namespace, f.sessionID, f.podName = namespace, sessionID, podName
+	return []string{podName}, nil
+}
+
+type recordingEmitter struct {
+	eventType string
+	data      interface{}
+}
+
+func (r *recordingEmitter) EmitEventWithContext(ctx context.Context, eventType string, data interface{}) {
+	r.eventType, r.data = eventType, data
+}
+
+// expectForceKillSession expects sess-1 to be looked up.
+func expectForceKillSession(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	mock.ExpectQuery("SELECT(.|\n)*FROM sessions").
+		WithArgs("sess-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "team_id", "template_name", "state", "app_type",
+			"active_connections", "url", "namespace", "platform", "pod_name",
+			"memory", "cpu", "persistent_home", "idle_timeout", "max_session_duration",
+			"created_at", "updated_at", "last_connection", "last_disconnect", "last_activity",
+		}).AddRow("sess-1", "alice", "", "firefox", "running", "desktop",
+			0, "", "tenant-a", "kubernetes", "sess-1-pod",
+			"", "", false, "", "",
+			now, now, nil, nil, nil))
+}
+
+func TestForceKillSession_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pods := &fakePodKiller{}
+	emitter := &recordingEmitter{}
+	handler := &Handler{db: db.NewDatabaseFromDB(mockDB), sessionDB: db.NewSessionDB(mockDB), pods: pods, emitter: emitter}
+
+	expectForceKillSession(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions").WithArgs("sess-1", "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sessions SET state").WithArgs("terminated", "sess-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE connections SET archived_at").WithArgs("sess-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_snapshots").WithArgs("sess-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_resource_metrics").WithArgs("sess-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs("admin-1", "sess-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/sessions/sess-1/force-kill", strings.NewReader(`{"reason":"stuck terminating"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "sess-1"}}
+	c.Set("userID", "admin-1")
+	c.Set("userRole", "admin")
+
+	handler.ForceKillSession(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "terminated", resp["state"])
+	assert.Equal(t, []interface{}{"sess-1-pod"}, resp["deletedPods"])
+
+	assert.Equal(t, "tenant-a", pods.namespace)
+	assert.Equal(t, "sess-1-pod", pods.podName)
+	assert.Equal(t, "terminated", pods.state)
+	assert.Equal(t, []string{"UpdateSessionState", "ForceDeleteSessionPods"}, pods.calls,
+		"the Session is terminated before its pods are deleted")
+	assert.Equal(t, events.PluginEventSessionForceKilled, emitter.eventType)
+	assert.Equal(t, events.SessionForceKilled{SessionID: "sess-1", AdminID: "admin-1", Reason: "stuck terminating"}, emitter.data)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceKillSession_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pods := &fakePodKiller{}
+	handler := &Handler{db: db.NewDatabaseFromDB(mockDB), sessionDB: db.NewSessionDB(mockDB), pods: pods}
+
+	mock.ExpectQuery("SELECT(.|\n)*FROM sessions").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/sessions/missing/force-kill", nil)
+	c.Params = gin.Params{{Key: "id", Value: "missing"}}
+	c.Set("userID", "admin-1")
+
+	handler.ForceKillSession(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, pods.sessionID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceKillSession_SessionUpdateFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pods := &fakePodKiller{stateErr: errors.New("failed to update session state: conflict")}
+	handler := &Handler{db: db.NewDatabaseFromDB(mockDB), sessionDB: db.NewSessionDB(mockDB), pods: pods}
+
+	expectForceKillSession(mock)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/sessions/sess-1/force-kill", nil)
+	c.Params = gin.Params{{Key: "id", Value: "sess-1"}}
+	c.Set("userID", "admin-1")
+
+	handler.ForceKillSession(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []string{"UpdateSessionState"}, pods.calls, "pods are not deleted while the Deployment would recreate them")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+// Package api provides the core REST API handlers for StreamSpace.
+//
+// This file implements the session resource metrics endpoint.
+//
+// SESSION METRICS:
+//
+// GET /api/v1/sessions/:id/metrics?from=&to=&resolution=1m returns the CPU
+// and memory usage of a session as a time series. Samples are written by
+// the background ResourceCollector (internal/metrics) into the
+// session_resource_metrics table.
+//
+// QUERY PARAMETERS:
+//
+//   - from: RFC3339 start of the range (default: one hour before to)
+//   - to: RFC3339 end of the range (default: now)
+//   - resolution: bucket size, one of 1m, 1h, 1d (default: 1m)
+//
+// Samples are averaged per bucket with date_trunc, so a 1h resolution over
+// a day returns at most 24 points regardless of the collection interval.
+// Buckets without samples are omitted rather than zero-filled.
+//
+// AUTHORIZATION:
+//
+//   - Admins and operators may read metrics of any session
+//   - Other users may only read metrics of sessions they own
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultMetricsWindow is the range returned when from is omitted
+	defaultMetricsWindow = time.Hour
+
+	// maxMetricsPoints caps the number of buckets a single query may return
+	maxMetricsPoints = 10000
+)
+
+// metricsResolutions maps the resolution parameter to a date_trunc field
+var metricsResolutions = map[string]struct {
+	field  string
+	bucket time.Duration
+}{
+	"1m": {"minute", time.Minute},
+	"1h": {"hour", time.Hour},
+	"1d": {"day", 24 * time.Hour},
+}
+
+// SessionMetricsPoint is one aggregated bucket of session resource usage
+type SessionMetricsPoint struct {
+	Timestamp     time.Time `json:"timestamp"`
+	CPUMillicores int64     `json:"cpuMillicores"`
+	MemoryBytes   int64     `json:"memoryBytes"`
+}
+
+// GetSessionMetrics returns the resource usage time series of a session.
+func (h *Handler) GetSessionMetrics(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	from, to, err := parseMetricsRange(c.Query("from"), c.Query("to"), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resolution := c.DefaultQuery("resolution", "1m")
+	res, ok := metricsResolutions[resolution]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resolution. Must be: 1m, 1h, or 1d"})
+		return
+	}
+
+	if to.Sub(from)/res.bucket > maxMetricsPoints {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Range too large for resolution %s (max %d points)", resolution, maxMetricsPoints),
+		})
+		return
+	}
+
+	role := c.GetString("userRole")
+	if role != "admin" && role != "operator" {
+		session, err := h.sessionDB.GetSession(ctx, sessionID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		if session.UserID != c.GetString("userID") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+	}
+
+	rows, err := h.db.DB().QueryContext(ctx, `
+		SELECT date_trunc($1, collected_at) AS bucket,
+		       AVG(cpu_millicores)::BIGINT,
+		       AVG(memory_bytes)::BIGINT
+		FROM session_resource_metrics
+		WHERE session_id = $2 AND collected_at >= $3 AND collected_at < $4 AND archived_at IS NULL
+		GROUP BY bucket
+		ORDER BY bucket
+	`, res.field, sessionID, from, to)
+	if err != nil {
+		log.Printf("Failed to query metrics for session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query session metrics"})
+		return
+	}
+	defer rows.Close()
+
+	points := []SessionMetricsPoint{}
+	for rows.Next() {
+		var p SessionMetricsPoint
+		var cpu, memory sql.NullInt64
+		if err := rows.Scan(&p.Timestamp, &cpu, &memory); err != nil {
+			log.Printf("Failed to scan metrics for session %s: %v", sessionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query session metrics"})
+			return
+		}
+		p.CPUMillicores = cpu.Int64
+		p.MemoryBytes = memory.Int64
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to read metrics for session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query session metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessionId":  sessionID,
+		"resolution": resolution,
+		"from":       from,
+		"to":         to,
+		"points":     points,
+	})
+}
+
+// parseMetricsRange parses the from/to query parameters.
+//
+// An empty to defaults to now and an empty from to defaultMetricsWindow
+// before to. from must be strictly before to.
+func parseMetricsRange(fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if toParam != "" {
+		parsed, err := time.Parse(time.RFC3339, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid 'to' timestamp, expected RFC3339")
+		}
+		to = parsed.UTC()
+	}
+
+	from := to.Add(-defaultMetricsWindow)
+	if fromParam != "" {
+		parsed, err := time.Parse(time.RFC3339, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid 'from' timestamp, expected RFC3339")
+		}
+		from = parsed.UTC()
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	return from, to, nil
+}
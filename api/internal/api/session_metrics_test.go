@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricsRange(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	from, to, err := parseMetricsRange("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, now, to)
+	assert.Equal(t, now.Add(-time.Hour), from)
+
+	from, to, err = parseMetricsRange("2025-01-15T00:00:00Z", "2025-01-15T06:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, to.Sub(from))
+
+	_, _, err = parseMetricsRange("yesterday", "", now)
+	assert.Error(t, err)
+
+	_, _, err = parseMetricsRange("2025-01-15T06:00:00Z", "2025-01-15T00:00:00Z", now)
+	assert.Error(t, err)
+}
+
+func setupSessionMetricsTest(t *testing.T, query string) (*Handler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	handler, mock, w, c := newHandlerTest(t, http.MethodGet, "/sessions/sess-1/metrics?"+query, "")
+	c.Params = gin.Params{{Key: "id", Value: "sess-1"}}
+	c.Set("userID", "admin")
+	c.Set("userRole", "admin")
+	return handler, mock, w, c
+}
+
+func TestGetSessionMetrics_AggregatesByResolution(t *testing.T) {
+	handler, mock, w, c := setupSessionMetricsTest(t,
+		"from=2025-01-15T00:00:00Z&to=2025-01-15T02:00:00Z&resolution=1h")
+
+	bucket := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT date_trunc\\(\\$1, collected_at\\)").
+		WithArgs("hour", "sess-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "cpu", "memory"}).
+			AddRow(bucket, 250, 536870912).
+			AddRow(bucket.Add(time.Hour), 500, 1073741824))
+
+	handler.GetSessionMetrics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		SessionID  string                `json:"sessionId"`
+		Resolution string                `json:"resolution"`
+		Points     []SessionMetricsPoint `json:"points"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "sess-1", resp.SessionID)
+	assert.Equal(t, "1h", resp.Resolution)
+	require.Len(t, resp.Points, 2)
+	assert.Equal(t, int64(250), resp.Points[0].CPUMillicores)
+	assert.Equal(t, int64(1073741824), resp.Points[1].MemoryBytes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSessionMetrics_InvalidResolution(t *testing.T) {
+	handler, mock, w, c := setupSessionMetricsTest(t, "resolution=5s")
+
+	handler.GetSessionMetrics(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSessionMetrics_RangeTooLarge(t *testing.T) {
+	handler, mock, w, c := setupSessionMetricsTest(t,
+		"from=2024-01-01T00:00:00Z&to=2025-01-01T00:00:00Z&resolution=1m")
+
+	handler.GetSessionMetrics(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
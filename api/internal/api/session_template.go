@@ -0,0 +1,122 @@
+// Package api provides the core REST API handlers for StreamSpace.
+//
+// This file implements session template pinning.
+//
+// TEMPLATE PINNING:
+//
+// A repository sync can change a catalog template (new base image, different
+// port) under a running session. Each session records the version_hash of
+// its catalog template when it is created; restarting a session whose
+// template has since changed still works, but the response carries a
+// warning.
+//
+//   - POST /api/v1/sessions/:id/pin-template - Pin the session to the current template version
+//   - GET /api/v1/sessions/:id/template-drift - Compare the pinned and current template versions
+//
+// AUTHORIZATION:
+//
+//   - Admins and operators may pin and inspect any session
+//   - Other users only sessions they own
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+// PinSessionTemplate pins a session to the current version of its catalog
+// template, accepting the drift reported by GetSessionTemplateDrift.
+func (h *Handler) PinSessionTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	if !h.authorizeSessionAccess(c, sessionID) {
+		return
+	}
+
+	hash, err := h.sessionDB.PinTemplateVersion(ctx, sessionID)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to pin template for session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pin session template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessionId":  sessionID,
+		"pinnedHash": hash,
+	})
+}
+
+// GetSessionTemplateDrift reports whether a session's catalog template
+// changed since the session was pinned.
+func (h *Handler) GetSessionTemplateDrift(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	if !h.authorizeSessionAccess(c, sessionID) {
+		return
+	}
+
+	drift, err := h.sessionDB.GetTemplateDrift(ctx, sessionID)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to check template drift for session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check template drift"})
+		return
+	}
+
+	c.JSON(http.StatusOK, drift)
+}
+
+// templateDriftWarning returns a warning for the response of a session
+// restart when the session's template changed since it was pinned, or ""
+// when it did not (or drift cannot be determined).
+func (h *Handler) templateDriftWarning(ctx context.Context, sessionID string) string {
+	if h.sessionDB == nil {
+		return ""
+	}
+
+	drift, err := h.sessionDB.GetTemplateDrift(ctx, sessionID)
+	if err != nil {
+		log.Printf("Failed to check template drift for session %s (non-fatal): %v", sessionID, err)
+		return ""
+	}
+	if !drift.Drifted {
+		return ""
+	}
+
+	log.Printf("Session %s restarted with drifted template (pinned %s, current %s)", sessionID, drift.PinnedHash, drift.CurrentHash)
+	return "The session's template changed since it was created; the session may not start as before. Pin the new template version to dismiss this warning."
+}
+
+// authorizeSessionAccess writes an error response and returns false unless
+// the caller is an admin or operator or owns the session.
+func (h *Handler) authorizeSessionAccess(c *gin.Context, sessionID string) bool {
+	role := c.GetString("userRole")
+	if role == "admin" || role == "operator" {
+		return true
+	}
+
+	session, err := h.sessionDB.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return false
+	}
+	if session.UserID != c.GetString("userID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionTemplateTest(t *testing.T, userID, role string) (*Handler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	handler, mock, w, c := newHandlerTest(t, http.MethodGet, "/sessions/sess-1/template-drift", "")
+	c.Params = gin.Params{{Key: "id", Value: "sess-1"}}
+	c.Set("userID", userID)
+	c.Set("userRole", role)
+	return handler, mock, w, c
+}
+
+func TestGetSessionTemplateDrift_Drifted(t *testing.T) {
+	handler, mock, w, c := setupSessionTemplateTest(t, "admin", "admin")
+
+	mock.ExpectQuery("SELECT s.template_version_hash").
+		WithArgs("sess-1").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned", "current"}).AddRow("aaaa", "bbbb"))
+
+	handler.GetSessionTemplateDrift(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var drift db.TemplateDrift
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &drift))
+	assert.Equal(t, db.TemplateDrift{Drifted: true, PinnedHash: "aaaa", CurrentHash: "bbbb"}, drift)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPinSessionTemplate_OtherUsersSession(t *testing.T) {
+	handler, mock, w, c := setupSessionTemplateTest(t, "bob", "user")
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT(.|\n)*FROM sessions").
+		WithArgs("sess-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "team_id", "template_name", "state", "app_type",
+			"active_connections", "url", "namespace", "platform", "pod_name",
+			"memory", "cpu", "persistent_home", "idle_timeout", "max_session_duration",
+			"created_at", "updated_at", "last_connection", "last_disconnect", "last_activity",
+		}).AddRow("sess-1", "alice", "", "firefox", "running", "desktop",
+			0, "", "streamspace", "kubernetes", "",
+			"", "", false, "", "",
+			now, now, nil, nil, nil))
+
+	handler.PinSessionTemplate(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPinSessionTemplate_Success(t *testing.T) {
+	handler, mock, w, c := setupSessionTemplateTest(t, "admin", "admin")
+
+	mock.ExpectQuery("UPDATE sessions s SET template_version_hash").
+		WithArgs("sess-1").
+		WillReturnRows(sqlmock.NewRows([]string{"template_version_hash"}).AddRow("bbbb"))
+
+	handler.PinSessionTemplate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"sessionId":"sess-1","pinnedHash":"bbbb"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
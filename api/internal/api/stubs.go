@@ -43,15 +43,27 @@ package api
 import (
 	"bufio"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/streamspace/streamspace/api/internal/auth"
+	"github.com/streamspace/streamspace/api/internal/models"
+	internalWebsocket "github.com/streamspace/streamspace/api/internal/websocket"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -114,14 +126,74 @@ var upgrader = websocket.Upgrader{
 // Health & Version Endpoints
 // ============================================================================
 
-// Health returns health status
+// Health returns health status.
+//
+// When a database is configured it is probed with a bounded SELECT 1; if the
+// probe fails the endpoint returns 503 with status "degraded" so load
+// balancers and Kubernetes readiness probes stop routing traffic here. The
+// endpoint is unauthenticated, so the error itself is only logged.
 func (h *Handler) Health(c *gin.Context) {
+	if h.db != nil {
+		if err := h.db.HealthCheck(c.Request.Context()); err != nil {
+			log.Printf("Health check failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":   "degraded",
+				"service":  "streamspace-api",
+				"database": "database unavailable",
+			})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
 		"service": "streamspace-api",
 	})
 }
 
+// KubernetesHealth reports the state of the Kubernetes API circuit breaker.
+//
+// Returns 503 while the breaker is open so probes can tell that session
+// operations are currently failing fast.
+func (h *Handler) KubernetesHealth(c *gin.Context) {
+	if h.k8sClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not configured"})
+		return
+	}
+
+	state, failures := h.k8sClient.CircuitBreakerState()
+	status := http.StatusOK
+	if state == "open" {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"state":    state,
+		"failures": failures,
+	})
+}
+
+// DatabaseStats returns connection pool statistics from sql.DB.Stats().
+func (h *Handler) DatabaseStats(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database not configured"})
+		return
+	}
+
+	stats := h.db.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"maxOpenConnections": stats.MaxOpenConnections,
+		"openConnections":    stats.OpenConnections,
+		"inUse":              stats.InUse,
+		"idle":               stats.Idle,
+		"waitCount":          stats.WaitCount,
+		"waitDurationMs":     stats.WaitDuration.Milliseconds(),
+		"maxIdleClosed":      stats.MaxIdleClosed,
+		"maxIdleTimeClosed":  stats.MaxIdleTimeClosed,
+		"maxLifetimeClosed":  stats.MaxLifetimeClosed,
+	})
+}
+
 // Version returns API version
 func (h *Handler) Version(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -742,6 +814,7 @@ func (h *Handler) GetMetrics(c *gin.Context) {
 			COUNT(*) FILTER (WHERE state = 'hibernated') as hibernated,
 			COUNT(*) FILTER (WHERE state = 'terminated') as terminated
 		FROM sessions
+		WHERE archived_at IS NULL
 	`).Scan(&sessionCounts.Total, &sessionCounts.Running, &sessionCounts.Hibernated, &sessionCounts.Terminated)
 
 	if err != nil {
@@ -834,32 +907,148 @@ func (h *Handler) GetMetrics(c *gin.Context) {
 // WebSocket Endpoints
 // ============================================================================
 
+// wsCloseUnauthorized is the close code sent when a WebSocket connection
+// fails authentication. Codes 4000-4999 are reserved for application use.
+const wsCloseUnauthorized = 4001
+
+// wsTokenProtocolPrefix marks a Sec-WebSocket-Protocol value carrying a JWT.
+const wsTokenProtocolPrefix = "jwt."
+
+// WebSocketUserStore looks up the users WebSocket tokens were issued to.
+//
+// *db.UserDB implements this interface; it is declared here so the handler
+// can be tested without a database.
+type WebSocketUserStore interface {
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+}
+
+// SetJWTManager sets the JWT manager and user store used to authenticate
+// WebSocket connections, including reconnect messages on open connections.
+func (h *Handler) SetJWTManager(jwtManager *auth.JWTManager, users WebSocketUserStore) {
+	h.jwtManager = jwtManager
+	h.wsUsers = users
+	if h.wsManager != nil {
+		h.wsManager.SetTokenValidator(h.webSocketIdentity)
+	}
+}
+
+// webSocketToken extracts the JWT from a WebSocket upgrade request.
+//
+// Browsers cannot set custom headers on WebSocket connections, so the token is
+// accepted either as a ?token= query parameter or as a "jwt.<token>" value in
+// the Sec-WebSocket-Protocol header. When the sub-protocol is used, it is
+// returned so the upgrade response can echo it back as the client requires.
+func webSocketToken(r *http.Request) (token, subprotocol string) {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, ""
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		if strings.HasPrefix(protocol, wsTokenProtocolPrefix) {
+			return strings.TrimPrefix(protocol, wsTokenProtocolPrefix), protocol
+		}
+	}
+	return "", ""
+}
+
+// authenticateWebSocket validates the JWT on a WebSocket upgrade request.
+//
+// Returns the authenticated identity, the sub-protocol to echo in the upgrade
+// response (if any), and whether authentication succeeded.
+func (h *Handler) authenticateWebSocket(c *gin.Context) (internalWebsocket.Identity, string, bool) {
+	token, subprotocol := webSocketToken(c.Request)
+	if token == "" {
+		return internalWebsocket.Identity{}, subprotocol, false
+	}
+
+	identity, err := h.webSocketIdentity(token)
+	if err != nil {
+		return internalWebsocket.Identity{}, subprotocol, false
+	}
+	return identity, subprotocol, true
+}
+
+// webSocketIdentity validates a WebSocket JWT and returns its user.
+//
+// Like the auth middleware, it rejects tokens whose session was invalidated
+// (logout) and tokens of missing or disabled users.
+func (h *Handler) webSocketIdentity(token string) (internalWebsocket.Identity, error) {
+	if h.jwtManager == nil || h.wsUsers == nil {
+		return internalWebsocket.Identity{}, fmt.Errorf("JWT authentication is not configured")
+	}
+
+	claims, err := h.jwtManager.ValidateToken(token)
+	if err != nil {
+		return internalWebsocket.Identity{}, err
+	}
+
+	ctx := context.Background()
+	if claims.ID != "" {
+		valid, err := h.jwtManager.ValidateSession(ctx, claims.ID)
+		if err != nil || !valid {
+			return internalWebsocket.Identity{}, fmt.Errorf("session expired or invalidated")
+		}
+	}
+
+	user, err := h.wsUsers.GetUser(ctx, claims.UserID)
+	if err != nil {
+		return internalWebsocket.Identity{}, fmt.Errorf("user not found")
+	}
+	if !user.Active {
+		return internalWebsocket.Identity{}, fmt.Errorf("user account is disabled")
+	}
+	return internalWebsocket.Identity{UserID: claims.UserID, UserRole: claims.Role}, nil
+}
+
+// upgradeWebSocket upgrades the connection, echoing the selected sub-protocol.
+func upgradeWebSocket(c *gin.Context, subprotocol string) (*websocket.Conn, error) {
+	var responseHeader http.Header
+	if subprotocol != "" {
+		responseHeader = http.Header{"Sec-WebSocket-Protocol": {subprotocol}}
+	}
+	return upgrader.Upgrade(c.Writer, c.Request, responseHeader)
+}
+
+// rejectWebSocket upgrades the connection only to send an unauthorized close
+// frame, so browser clients can tell an auth failure from a network error.
+func rejectWebSocket(c *gin.Context, subprotocol string) {
+	conn, err := upgradeWebSocket(c, subprotocol)
+	if err != nil {
+		log.Printf("Failed to upgrade WebSocket connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(wsCloseUnauthorized, "unauthorized"),
+		time.Now().Add(time.Second))
+}
+
 // SessionsWebSocket handles WebSocket for real-time session updates
 // Supports query parameters:
+// - ?token=<jwt> - Authentication token (or "jwt.<token>" sub-protocol)
 // - ?user_id=<userID> - Subscribe to events for a specific user (defaults to authenticated user)
 // - ?session_id=<sessionID> - Subscribe to events for a specific session
 func (h *Handler) SessionsWebSocket(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	identity, subprotocol, ok := h.authenticateWebSocket(c)
+	if !ok {
+		rejectWebSocket(c, subprotocol)
+		return
+	}
+
+	conn, err := upgradeWebSocket(c, subprotocol)
 	if err != nil {
 		log.Printf("Failed to upgrade WebSocket connection: %v", err)
 		return
 	}
 
-	// Get user ID from context (authenticated user)
-	authenticatedUserID, _ := c.Get("userID")
-	userIDStr := ""
-	if authenticatedUserID != nil {
-		if id, ok := authenticatedUserID.(string); ok {
-			userIDStr = id
-		}
-	}
+	userIDStr := identity.UserID
 
 	// Allow overriding user_id from query param (for admins/operators)
 	// But for security, regular users can only subscribe to their own events
 	queryUserID := c.Query("user_id")
 	if queryUserID != "" && queryUserID != userIDStr {
 		// Check if user has admin or operator role
-		role := c.GetString("role")
+		role := identity.UserRole
 		if role != "admin" && role != "operator" {
 			// Regular users can only subscribe to their own events
 			log.Printf("Unauthorized attempt to subscribe to user %s by user %s (role: %s)", queryUserID, userIDStr, role)
@@ -876,40 +1065,48 @@ func (h *Handler) SessionsWebSocket(c *gin.Context) {
 	// Get session ID from query params (optional)
 	sessionID := c.Query("session_id")
 
-	h.wsManager.HandleSessionsWebSocket(conn, userIDStr, sessionID)
+	h.wsManager.HandleSessionsWebSocket(conn, identity, userIDStr, sessionID)
 }
 
 // ClusterWebSocket handles WebSocket for real-time cluster updates
 // Only admins and operators can view cluster-wide metrics
 func (h *Handler) ClusterWebSocket(c *gin.Context) {
+	identity, subprotocol, ok := h.authenticateWebSocket(c)
+	if !ok {
+		rejectWebSocket(c, subprotocol)
+		return
+	}
+
 	// Check if user has admin or operator role
-	role := c.GetString("role")
-	if role != "admin" && role != "operator" {
-		userID := c.GetString("user_id")
-		log.Printf("Unauthorized attempt to access cluster metrics by user %s (role: %s)", userID, role)
+	if identity.UserRole != "admin" && identity.UserRole != "operator" {
+		log.Printf("Unauthorized attempt to access cluster metrics by user %s (role: %s)", identity.UserID, identity.UserRole)
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Unauthorized: Only admins and operators can view cluster metrics",
 		})
 		return
 	}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := upgradeWebSocket(c, subprotocol)
 	if err != nil {
 		log.Printf("Failed to upgrade WebSocket connection: %v", err)
 		return
 	}
 
-	h.wsManager.HandleMetricsWebSocket(conn)
+	h.wsManager.HandleMetricsWebSocket(conn, identity)
 }
 
 // LogsWebSocket handles WebSocket for streaming pod logs
 // Only admins and operators can view pod logs
 func (h *Handler) LogsWebSocket(c *gin.Context) {
+	identity, subprotocol, ok := h.authenticateWebSocket(c)
+	if !ok {
+		rejectWebSocket(c, subprotocol)
+		return
+	}
+
 	// Check if user has admin or operator role
-	role := c.GetString("role")
-	if role != "admin" && role != "operator" {
-		userID := c.GetString("user_id")
-		log.Printf("Unauthorized attempt to access pod logs by user %s (role: %s)", userID, role)
+	if identity.UserRole != "admin" && identity.UserRole != "operator" {
+		log.Printf("Unauthorized attempt to access pod logs by user %s (role: %s)", identity.UserID, identity.UserRole)
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Unauthorized: Only admins and operators can view pod logs",
 		})
@@ -919,7 +1116,7 @@ func (h *Handler) LogsWebSocket(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("pod")
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := upgradeWebSocket(c, subprotocol)
 	if err != nil {
 		log.Printf("Failed to upgrade WebSocket connection: %v", err)
 		return
@@ -972,15 +1169,44 @@ func (h *Handler) RemoveRepository(c *gin.Context) {
 // Webhook Endpoint for Repository Auto-Sync
 // ============================================================================
 
-// WebhookRepositorySync handles webhooks from Git providers for auto-sync
+// maxWebhookBodySize caps repository webhook payloads. Push payloads are a
+// few KB; GitHub itself stops at 25 MB.
+const maxWebhookBodySize = 5 << 20
+
+// WebhookRepositorySync handles webhooks from Git providers for auto-sync.
+//
+// SECURITY:
+//
+// Requests must carry a GitHub-style X-Hub-Signature-256 header
+// ("sha256=<hex hmac>") computed over the raw body with the repository's own
+// webhook secret (see RotateRepositoryWebhookSecret):
+//   - Repository has no webhook secret: 400 Missing webhook secret
+//   - Signature missing or does not match: 403
+//
+// Signatures are compared with crypto/subtle.ConstantTimeCompare so response
+// timing does not leak how much of a forged signature was correct.
+//
+// The endpoint is unauthenticated and the body is read before the signature
+// can be checked, so bodies over maxWebhookBodySize are rejected with 413.
 func (h *Handler) WebhookRepositorySync(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
 	var webhook struct {
 		RepositoryURL string `json:"repository_url"`
 		Branch        string `json:"branch"`
 		Ref           string `json:"ref"`
 	}
 
-	if err := c.ShouldBindJSON(&webhook); err != nil {
+	if err := json.Unmarshal(body, &webhook); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -988,18 +1214,31 @@ func (h *Handler) WebhookRepositorySync(c *gin.Context) {
 	// Find repository by URL
 	ctx := c.Request.Context()
 	var repoID int
-	err := h.db.DB().QueryRowContext(ctx, `
-		SELECT id FROM repositories WHERE url = $1
-	`, webhook.RepositoryURL).Scan(&repoID)
+	var webhookSecret sql.NullString
+	err = h.db.DB().QueryRowContext(ctx, `
+		SELECT id, webhook_secret FROM repositories WHERE url = $1
+	`, webhook.RepositoryURL).Scan(&repoID, &webhookSecret)
 
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Repository not found"})
 		return
 	}
 
+	if !webhookSecret.Valid || webhookSecret.String == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing webhook secret"})
+		return
+	}
+
+	if !validHubSignature(webhookSecret.String, body, c.GetHeader("X-Hub-Signature-256")) {
+		log.Printf("Rejected webhook for repository %d: invalid signature", repoID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+
 	// Trigger sync in background
+	// Use context.Background() - the request context is cancelled once we respond
 	go func() {
-		if err := h.syncService.SyncRepository(ctx, repoID); err != nil {
+		if err := h.syncService.SyncRepository(context.Background(), repoID, nil); err != nil {
 			log.Printf("Webhook-triggered sync failed for repository %d: %v", repoID, err)
 		} else {
 			log.Printf("Webhook-triggered sync completed for repository %d", repoID)
@@ -1013,6 +1252,59 @@ func (h *Handler) WebhookRepositorySync(c *gin.Context) {
 	})
 }
 
+// RotateRepositoryWebhookSecret generates a new webhook secret for a repository.
+//
+// The secret is 32 random bytes, hex-encoded. It is returned only in this
+// response; there is no endpoint to read it back, so callers must copy it
+// into the Git provider's webhook settings immediately. Calling this again
+// replaces the previous secret.
+func (h *Handler) RotateRepositoryWebhookSecret(c *gin.Context) {
+	ctx := c.Request.Context()
+	repoID := c.Param("id")
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+		return
+	}
+	secret := hex.EncodeToString(secretBytes)
+
+	result, err := h.db.DB().ExecContext(ctx, `
+		UPDATE repositories SET webhook_secret = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, secret, repoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store webhook secret"})
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Repository not found"})
+		return
+	}
+
+	log.Printf("Webhook secret rotated for repository %s by %s", repoID, c.GetString("userID"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"repositoryID":  repoID,
+		"webhookSecret": secret,
+		"message":       "Store this secret now; it will not be shown again",
+	})
+}
+
+// validHubSignature checks a GitHub-style "sha256=<hex>" signature of body.
+func validHubSignature(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok || signature == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(expected)) == 1
+}
+
 // ====================================================================================
 // COMPLIANCE STUBS
 // ====================================================================================
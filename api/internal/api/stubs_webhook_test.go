@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWebhookBody = `{"repository_url":"https://github.com/example/templates","ref":"refs/heads/main"}`
+
+func hubSignature(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func setupWebhookTest(t *testing.T, signature string) (*Handler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	handler, mock, w, c := newHandlerTest(t, http.MethodPost, "/webhooks/repository/sync", testWebhookBody)
+	c.Request.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		c.Request.Header.Set("X-Hub-Signature-256", signature)
+	}
+	return handler, mock, w, c
+}
+
+func TestValidHubSignature(t *testing.T) {
+	body := []byte(testWebhookBody)
+
+	assert.True(t, validHubSignature("s3cret", body, hubSignature("s3cret", testWebhookBody)))
+	assert.False(t, validHubSignature("s3cret", body, hubSignature("other", testWebhookBody)))
+	assert.False(t, validHubSignature("s3cret", body, ""))
+	assert.False(t, validHubSignature("s3cret", body, "sha1=abcdef"))
+}
+
+func TestWebhookRepositorySync_MissingSecret(t *testing.T) {
+	handler, mock, w, c := setupWebhookTest(t, hubSignature("s3cret", testWebhookBody))
+
+	mock.ExpectQuery("SELECT id, webhook_secret FROM repositories").
+		WithArgs("https://github.com/example/templates").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_secret"}).AddRow(1, nil))
+
+	handler.WebhookRepositorySync(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Missing webhook secret")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepositorySync_InvalidSignature(t *testing.T) {
+	handler, mock, w, c := setupWebhookTest(t, hubSignature("wrong", testWebhookBody))
+
+	mock.ExpectQuery("SELECT id, webhook_secret FROM repositories").
+		WithArgs("https://github.com/example/templates").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_secret"}).AddRow(1, "s3cret"))
+
+	handler.WebhookRepositorySync(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepositorySync_BodyTooLarge(t *testing.T) {
+	handler, mock, w, c := setupWebhookTest(t, "")
+	c.Request = httptest.NewRequest("POST", "/webhooks/repository/sync", bytes.NewReader(make([]byte, maxWebhookBodySize+1)))
+
+	handler.WebhookRepositorySync(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateRepositoryWebhookSecret_NotFound(t *testing.T) {
+	handler, mock, w, c := setupWebhookTest(t, "")
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	mock.ExpectExec("UPDATE repositories SET webhook_secret").
+		WithArgs(sqlmock.AnyArg(), "42").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	handler.RotateRepositoryWebhookSecret(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
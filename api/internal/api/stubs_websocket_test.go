@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/streamspace/streamspace/api/internal/auth"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebSocketUsers is a WebSocketUserStore backed by a map
+type fakeWebSocketUsers map[string]*models.User
+
+func (f fakeWebSocketUsers) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if user, ok := f[userID]; ok {
+		return user, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func TestWebSocketToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ws/sessions?token=query-token", nil)
+	token, subprotocol := webSocketToken(req)
+	assert.Equal(t, "query-token", token)
+	assert.Empty(t, subprotocol)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/ws/sessions", nil)
+	req.Header.Set("Sec-WebSocket-Protocol", "chat, jwt.header-token")
+	token, subprotocol = webSocketToken(req)
+	assert.Equal(t, "header-token", token)
+	assert.Equal(t, "jwt.header-token", subprotocol)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/ws/sessions", nil)
+	token, subprotocol = webSocketToken(req)
+	assert.Empty(t, token)
+	assert.Empty(t, subprotocol)
+}
+
+// dialClusterWebSocket serves ClusterWebSocket and dials it with the given
+// sub-protocols, returning the connection and the upgrade response.
+func dialClusterWebSocket(t *testing.T, h *Handler, query string, subprotocols []string) (*websocket.Conn, *http.Response) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/ws/cluster", h.ClusterWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/cluster" + query
+	conn, resp, err := dialer.Dial(url, http.Header{"Origin": {"http://localhost:3000"}})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, resp
+}
+
+func TestClusterWebSocket_InvalidTokenClosesUnauthorized(t *testing.T) {
+	h := &Handler{jwtManager: auth.NewJWTManager(&auth.JWTConfig{SecretKey: "test-secret-key-for-websocket-auth"})}
+
+	conn, _ := dialClusterWebSocket(t, h, "?token=not-a-jwt", nil)
+
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err)
+	assert.True(t, websocket.IsCloseError(err, wsCloseUnauthorized), "unexpected error: %v", err)
+}
+
+func TestClusterWebSocket_SubprotocolTokenEchoed(t *testing.T) {
+	h := &Handler{jwtManager: auth.NewJWTManager(&auth.JWTConfig{SecretKey: "test-secret-key-for-websocket-auth"})}
+
+	conn, resp := dialClusterWebSocket(t, h, "", []string{"jwt.not-a-jwt"})
+	assert.Equal(t, "jwt.not-a-jwt", resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, wsCloseUnauthorized), "unexpected error: %v", err)
+}
+
+func TestClusterWebSocket_ForbiddenRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := auth.NewJWTManager(&auth.JWTConfig{SecretKey: "test-secret-key-for-websocket-auth"})
+	token, err := jwtManager.GenerateToken("user-1", "alice", "alice@example.com", "user", nil)
+	require.NoError(t, err)
+
+	h := &Handler{jwtManager: jwtManager, wsUsers: fakeWebSocketUsers{"user-1": {ID: "user-1", Active: true}}}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/ws/cluster?token="+token, nil)
+
+	h.ClusterWebSocket(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestWebSocketIdentity_RejectsInactiveUsers(t *testing.T) {
+	jwtManager := auth.NewJWTManager(&auth.JWTConfig{SecretKey: "test-secret-key-for-websocket-auth"})
+	h := &Handler{jwtManager: jwtManager, wsUsers: fakeWebSocketUsers{
+		"user-1": {ID: "user-1", Active: true},
+		"user-2": {ID: "user-2", Active: false},
+	}}
+
+	tests := []struct {
+		name    string
+		userID  string
+		wantErr bool
+	}{
+		{name: "active user", userID: "user-1"},
+		{name: "disabled user", userID: "user-2", wantErr: true},
+		{name: "deleted user", userID: "user-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtManager.GenerateToken(tt.userID, "alice", "alice@example.com", "admin", nil)
+			require.NoError(t, err)
+
+			identity, err := h.webSocketIdentity(token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.userID, identity.UserID)
+		})
+	}
+}
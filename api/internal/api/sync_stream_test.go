@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/sync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSyncProgressStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	progress := sync.NewSyncProgress()
+	progress <- sync.SyncProgress{Stage: sync.SyncStageCloning}
+	progress <- sync.SyncProgress{Stage: sync.SyncStageParsing, TemplatesFound: 5}
+	progress <- sync.SyncProgress{Stage: sync.SyncStageDone, Elapsed: "3.2s"}
+	close(progress)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/repositories/1/sync/stream", nil)
+
+	writeSyncProgressStream(c, progress, time.Hour)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		`data: {"stage":"cloning"}`,
+		`data: {"stage":"parsing","templatesFound":5}`,
+		`data: {"stage":"done","elapsed":"3.2s"}`,
+		"",
+	}, "\n\n"), w.Body.String())
+}
+
+func TestWriteSyncProgressStream_Heartbeat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	progress := make(chan sync.SyncProgress)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(progress)
+	}()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/repositories/1/sync/stream", nil)
+
+	writeSyncProgressStream(c, progress, 10*time.Millisecond)
+
+	assert.Contains(t, w.Body.String(), "data: ping\n\n")
+}
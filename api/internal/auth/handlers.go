@@ -1,10 +1,12 @@
 // Package auth provides authentication and authorization mechanisms for StreamSpace.
 // This file implements HTTP handlers for authentication endpoints including local,
-// SAML, and password management operations.
+// SAML, OIDC, LDAP, and password management operations.
 //
 // AUTHENTICATION HANDLERS:
 // - Local authentication (username/password)
 // - SAML SSO authentication (enterprise identity providers)
+// - OIDC SSO authentication (Okta, Google Workspace, Azure AD, Keycloak)
+// - LDAP authentication (Active Directory, OpenLDAP)
 // - Token refresh (JWT token renewal)
 // - Password change (local users only)
 // - Logout (session termination)
@@ -16,6 +18,7 @@
 //   - System verifies credentials against database
 //   - Returns JWT token for subsequent requests
 //   - Supports account status validation (active/disabled)
+//   - Users with TOTP enabled must also send totpCode (see totp.go)
 //
 // 2. SAML SSO Authentication (GET /auth/saml/login):
 //   - User initiates SSO flow
@@ -25,15 +28,21 @@
 //   - Returns JWT token for API access
 //
 // 3. Token Refresh (POST /auth/refresh):
-//   - Client submits existing JWT token
-//   - System validates token is within refresh window
-//   - Issues new token with extended expiration
-//   - Prevents indefinite token refresh (7-day window)
+//   - Client submits an unexpired JWT (Bearer header) or refresh token cookie
+//   - Tokens with more than 5 minutes left are returned unchanged
+//   - Otherwise a new token with a fresh expiry is issued
+//   - Logout revokes the refresh token (see refresh.go)
 //
 // 4. Password Change (POST /auth/password):
 //   - Local users can change their password
 //   - Requires current password verification
-//   - Not available for SSO users (SAML/OIDC)
+//   - Not available for SSO and directory users (SAML/OIDC/LDAP)
+//
+// 5. LDAP Authentication (POST /auth/ldap/login):
+//   - User submits directory username and password
+//   - System verifies them against the LDAP directory (see ldap.go)
+//   - Provisions the user and syncs groups like SSO logins
+//   - Returns JWT token like local authentication
 //
 // SECURITY FEATURES:
 //
@@ -68,7 +77,7 @@
 // 3. Token Security:
 //   - JWT tokens include user ID, role, and groups
 //   - Tokens expire after configured duration (default: 24 hours)
-//   - Refresh tokens only valid within 7-day window
+//   - Refresh tokens are stored hashed and revoked on logout
 //   - See jwt.go for detailed token security
 //
 // 4. SAML Security:
@@ -80,7 +89,7 @@
 // EXAMPLE USAGE:
 //
 //	// Initialize handler with dependencies
-//	handler := NewAuthHandler(userDB, jwtManager, samlAuth)
+//	handler := NewAuthHandler(userDB, jwtManager, samlAuth, oidcAuth)
 //
 //	// Register routes
 //	router := gin.Default()
@@ -93,6 +102,9 @@
 //	// - GET  /api/v1/auth/saml/login (initiate SAML SSO)
 //	// - POST /api/v1/auth/saml/acs (SAML callback)
 //	// - GET  /api/v1/auth/saml/metadata (SAML SP metadata)
+//	// - GET  /api/v1/auth/oidc/login (initiate OIDC authorization code flow)
+//	// - GET  /api/v1/auth/oidc/callback (OIDC redirect URI)
+//	// - POST /api/v1/auth/ldap/login (LDAP authentication)
 //
 // THREAD SAFETY:
 //
@@ -104,6 +116,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -173,6 +186,7 @@ type UserStore interface {
 	UpdateUser(ctx context.Context, userID string, req *models.UpdateUserRequest) error
 	UpdatePassword(ctx context.Context, userID, password string) error
 	AddUserToGroup(ctx context.Context, userID, groupName string) error
+	RemoveUserFromGroup(ctx context.Context, userID, groupName string) error
 	DB() *sql.DB // Kept for backward compatibility if needed, but ideally should be removed
 }
 
@@ -181,6 +195,7 @@ type TokenManager interface {
 	GenerateTokenWithContext(ctx context.Context, userID, username, email, role string, groups []string, ipAddress, userAgent string) (string, error)
 	RefreshToken(token string) (string, error)
 	ValidateToken(token string) (*Claims, error)
+	ValidateSession(ctx context.Context, sessionID string) (bool, error)
 	InvalidateSession(ctx context.Context, sessionID string) error
 	GetTokenDuration() time.Duration
 }
@@ -192,22 +207,49 @@ type SAMLService interface {
 	ExtractUserFromAssertion(assertion *saml.Assertion) (*UserInfo, error)
 }
 
+// OIDCService defines the interface for OIDC operations
+type OIDCService interface {
+	GetAuthorizationURL(state string) string
+	HandleCallback(ctx context.Context, code string) (*OIDCUserInfo, error)
+}
+
+// LDAPService defines the interface for LDAP operations
+type LDAPService interface {
+	Authenticate(username, password string) (*LDAPUser, error)
+}
+
 // AuthHandler handles authentication requests
 type AuthHandler struct {
 	userDB     UserStore
 	jwtManager TokenManager
 	samlAuth   SAMLService
+	oidcAuth   OIDCService
+	ldapAuth   LDAPService
+	totpStore  TOTPStore
+	totpKey    []byte
+
+	refreshStore RefreshTokenStore
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(userDB UserStore, jwtManager TokenManager, samlAuth SAMLService) *AuthHandler {
+// NewAuthHandler creates a new auth handler.
+//
+// samlAuth and oidcAuth are optional; pass nil to disable the corresponding
+// SSO endpoints (they will return 503 Service Unavailable).
+func NewAuthHandler(userDB UserStore, jwtManager TokenManager, samlAuth SAMLService, oidcAuth OIDCService) *AuthHandler {
 	return &AuthHandler{
 		userDB:     userDB,
 		jwtManager: jwtManager,
 		samlAuth:   samlAuth,
+		oidcAuth:   oidcAuth,
 	}
 }
 
+// SetLDAPAuthenticator enables LDAP login. Without it, /auth/ldap/login
+// returns 503 Service Unavailable.
+func (h *AuthHandler) SetLDAPAuthenticator(ldapAuth LDAPService) {
+	h.ldapAuth = ldapAuth
+}
+
 // RegisterRoutes registers authentication routes
 func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
 	// Note: router is already /api/v1/auth from main.go
@@ -217,6 +259,9 @@ func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("/saml/login", h.SAMLLogin)
 	router.POST("/saml/acs", h.SAMLCallback)
 	router.GET("/saml/metadata", h.SAMLMetadata)
+	router.GET("/oidc/login", h.OIDCLogin)
+	router.GET("/oidc/callback", h.OIDCCallback)
+	router.POST("/ldap/login", h.LDAPLogin)
 }
 
 // LoginRequest represents a login request
@@ -253,10 +298,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Require a second factor if the user has confirmed TOTP enrollment
+	if !h.checkLoginTOTP(c, user.ID, req.TOTPCode) {
+		return
+	}
+
 	// Get user groups
-	groupIDs, err := h.userDB.GetUserGroups(c.Request.Context(), user.ID)
+	groupNames, err := h.userDB.GetUserGroups(c.Request.Context(), user.ID)
 	if err != nil {
-		groupIDs = []string{} // Continue without groups if error
+		groupNames = []string{} // Continue without groups if error
 	}
 
 	// Capture client info for session tracking
@@ -264,7 +314,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 
 	// Generate JWT token with session tracking
-	token, err := h.jwtManager.GenerateTokenWithContext(c.Request.Context(), user.ID, user.Username, user.Email, user.Role, groupIDs, ipAddress, userAgent)
+	token, err := h.jwtManager.GenerateTokenWithContext(c.Request.Context(), user.ID, user.Username, user.Email, user.Role, groupNames, ipAddress, userAgent)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate token",
@@ -276,6 +326,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Calculate expiration
 	expiresAt := time.Now().Add(h.jwtManager.GetTokenDuration())
 
+	// Issue a refresh token cookie (see refresh.go)
+	h.issueRefreshToken(c, user.ID)
+
 	// Remove sensitive data
 	user.PasswordHash = ""
 
@@ -286,61 +339,6 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
-// RefreshTokenRequest represents a token refresh request
-type RefreshTokenRequest struct {
-	Token string `json:"token" binding:"required"`
-}
-
-// RefreshToken handles token refresh
-func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	var req RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
-		return
-	}
-
-	// Validate and refresh token
-	newToken, err := h.jwtManager.RefreshToken(req.Token)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Invalid or expired token",
-			"message": err.Error(),
-		})
-		return
-	}
-
-	// Get user info from token
-	claims, err := h.jwtManager.ValidateToken(newToken)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to validate new token",
-		})
-		return
-	}
-
-	// Get full user data
-	user, err := h.userDB.GetUser(c.Request.Context(), claims.UserID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get user data",
-		})
-		return
-	}
-
-	user.PasswordHash = ""
-
-	expiresAt := time.Now().Add(h.jwtManager.GetTokenDuration())
-
-	c.JSON(http.StatusOK, LoginResponse{
-		Token:     newToken,
-		ExpiresAt: expiresAt,
-		User:      user,
-	})
-}
-
 // Logout handles logout and invalidates the session in Redis
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get session ID from context (set by auth middleware)
@@ -356,6 +354,8 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		}
 	}
 
+	h.revokeRefreshToken(c)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Logged out successfully",
 	})
@@ -494,9 +494,9 @@ func (h *AuthHandler) SAMLCallback(c *gin.Context) {
 	}
 
 	// Get user groups for JWT
-	groupIDs, err := h.userDB.GetUserGroups(ctx, user.ID)
+	groupNames, err := h.userDB.GetUserGroups(ctx, user.ID)
 	if err != nil {
-		groupIDs = []string{} // Continue without groups if error
+		groupNames = []string{} // Continue without groups if error
 	}
 
 	// Capture client info for session tracking
@@ -504,7 +504,7 @@ func (h *AuthHandler) SAMLCallback(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 
 	// Generate JWT token with session tracking
-	token, err := h.jwtManager.GenerateTokenWithContext(ctx, user.ID, user.Username, user.Email, user.Role, groupIDs, ipAddress, userAgent)
+	token, err := h.jwtManager.GenerateTokenWithContext(ctx, user.ID, user.Username, user.Email, user.Role, groupNames, ipAddress, userAgent)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate token",
@@ -573,6 +573,348 @@ func (h *AuthHandler) SAMLMetadata(c *gin.Context) {
 	c.String(http.StatusOK, string(metadataBytes))
 }
 
+// OIDCLogin initiates the OIDC authorization code flow.
+//
+// A random state value is stored in a short-lived HttpOnly cookie and the
+// user is redirected to the provider's authorization endpoint. The optional
+// return_url query parameter is validated and stored for use after login.
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	// Check if OIDC is configured
+	if h.oidcAuth == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "OIDC authentication is not configured",
+		})
+		return
+	}
+
+	state, err := generateRandomState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate OIDC state",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	secure := c.Request.TLS != nil
+	c.SetCookie("oidc_state", state, 600, "/", "", secure, true)
+
+	if returnURL := c.Query("return_url"); returnURL != "" {
+		c.SetCookie("oidc_return_url", validateReturnURL(returnURL), 600, "/", "", secure, true)
+	}
+
+	c.Redirect(http.StatusFound, h.oidcAuth.GetAuthorizationURL(state))
+}
+
+// OIDCCallback handles the OIDC redirect after the user authenticates.
+//
+// The callback validates the state cookie, exchanges the authorization code
+// for tokens (claims are read from the ID token and the userinfo endpoint),
+// creates or updates the local user, syncs groups, and issues a JWT exactly
+// like the local and SAML login flows.
+//
+// Users are matched by email, so the provider must report the email as
+// verified, and an existing account is only used if it was created by OIDC.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	// Check if OIDC is configured
+	if h.oidcAuth == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "OIDC authentication is not configured",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	secure := c.Request.TLS != nil
+
+	// Validate state parameter (CSRF protection)
+	storedState, err := c.Cookie("oidc_state")
+	if err != nil || storedState == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing OIDC state cookie"})
+		return
+	}
+	c.SetCookie("oidc_state", "", -1, "/", "", secure, true)
+
+	if c.Query("state") != storedState {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid state parameter"})
+		return
+	}
+
+	// Check for error from OIDC provider
+	if errMsg := c.Query("error"); errMsg != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "OIDC provider returned an error",
+			"message": errMsg + ": " + c.Query("error_description"),
+		})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing authorization code"})
+		return
+	}
+
+	userInfo, err := h.oidcAuth.HandleCallback(ctx, code)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "OIDC authentication failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if userInfo.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "OIDC claims missing required email",
+		})
+		return
+	}
+
+	// Accounts are matched by email, so an unverified address could take
+	// over the account that owns it
+	if !userInfo.EmailVerified {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "OIDC email address is not verified",
+		})
+		return
+	}
+
+	fullName := userInfo.FullName
+	if fullName == "" {
+		fullName = strings.TrimSpace(userInfo.FirstName + " " + userInfo.LastName)
+	}
+
+	// Get or create user in database
+	user, err := h.userDB.GetUserByEmail(ctx, userInfo.Email)
+	if err != nil {
+		username := userInfo.Username
+		if username == "" {
+			username = userInfo.Email
+		}
+		if fullName == "" {
+			fullName = userInfo.Email // Fallback to email if no name
+		}
+		createReq := &models.CreateUserRequest{
+			Username: username,
+			Email:    userInfo.Email,
+			FullName: fullName,
+			Provider: "oidc",
+			Role:     "user", // Default role
+		}
+
+		user, err = h.userDB.CreateUser(ctx, createReq)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create OIDC user",
+				"message": err.Error(),
+			})
+			return
+		}
+	} else if user.Provider != "oidc" {
+		// Never sign in to a local, SAML or LDAP account through OIDC
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "An account with this email uses a different sign-in method",
+		})
+		return
+	} else if fullName != "" {
+		// User exists, update attributes from OIDC claims
+		updateReq := &models.UpdateUserRequest{
+			FullName: &fullName,
+		}
+		if err := h.userDB.UpdateUser(ctx, user.ID, updateReq); err != nil {
+			// Log error but continue (non-critical)
+			log.Printf("Warning: Failed to update user %s from OIDC: %v", user.ID, err)
+		}
+	}
+
+	// Check if user is active
+	if !user.Active {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Account is disabled",
+		})
+		return
+	}
+
+	// Sync user groups from OIDC claims
+	if len(userInfo.Groups) > 0 {
+		if err := h.syncSAMLGroups(ctx, user.ID, userInfo.Groups); err != nil {
+			log.Printf("Warning: Failed to sync OIDC groups for user %s: %v", user.ID, err)
+		}
+	}
+
+	// Get user groups for JWT
+	groupNames, err := h.userDB.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		groupNames = []string{} // Continue without groups if error
+	}
+
+	// Generate JWT token with session tracking
+	token, err := h.jwtManager.GenerateTokenWithContext(ctx, user.ID, user.Username, user.Email, user.Role, groupNames, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate token",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.jwtManager.GetTokenDuration())
+
+	// Issue a refresh token cookie (see refresh.go)
+	h.issueRefreshToken(c, user.ID)
+
+	// Remove sensitive data
+	user.PasswordHash = ""
+
+	returnURL, err := c.Cookie("oidc_return_url")
+	if err != nil || returnURL == "" {
+		returnURL = "/"
+	}
+	c.SetCookie("oidc_return_url", "", -1, "/", "", secure, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":     token,
+		"expiresAt": expiresAt,
+		"user":      user,
+		"returnUrl": returnURL,
+	})
+}
+
+// LDAPLogin authenticates a username and password against the LDAP
+// directory.
+//
+// On first login the user is provisioned with provider "ldap"; afterwards
+// the full name is refreshed from the directory. Existing accounts with the
+// same email are only used if they were created by LDAP. The user is added to the
+// StreamSpace groups named like their directory groups. The request and
+// response are those of Login, including the TOTP code and refresh token.
+func (h *AuthHandler) LDAPLogin(c *gin.Context) {
+	if h.ldapAuth == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "LDAP authentication is not configured",
+		})
+		return
+	}
+
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	ldapUser, err := h.ldapAuth.Authenticate(req.Username, req.Password)
+	if errors.Is(err, ErrLDAPInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+	if err != nil {
+		log.Printf("LDAP authentication of %s failed: %v", req.Username, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "LDAP directory is unavailable",
+		})
+		return
+	}
+
+	if ldapUser.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "LDAP entry missing required email",
+		})
+		return
+	}
+
+	// Get or create user in database
+	user, err := h.userDB.GetUserByEmail(ctx, ldapUser.Email)
+	if err != nil {
+		fullName := ldapUser.FullName
+		if fullName == "" {
+			fullName = ldapUser.Username // Fallback to username if no name
+		}
+		createReq := &models.CreateUserRequest{
+			Username: ldapUser.Username,
+			Email:    ldapUser.Email,
+			FullName: fullName,
+			Provider: "ldap",
+			Role:     "user", // Default role
+		}
+
+		user, err = h.userDB.CreateUser(ctx, createReq)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create LDAP user",
+				"message": err.Error(),
+			})
+			return
+		}
+	} else if user.Provider != "ldap" {
+		// Never sign in to a local, SAML or OIDC account through LDAP
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "An account with this email uses a different sign-in method",
+		})
+		return
+	} else if ldapUser.FullName != "" {
+		// User exists, update attributes from the directory
+		updateReq := &models.UpdateUserRequest{
+			FullName: &ldapUser.FullName,
+		}
+		if err := h.userDB.UpdateUser(ctx, user.ID, updateReq); err != nil {
+			// Log error but continue (non-critical)
+			log.Printf("Warning: Failed to update user %s from LDAP: %v", user.ID, err)
+		}
+	}
+
+	// Check if user is active
+	if !user.Active {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Account is disabled",
+		})
+		return
+	}
+
+	// Require a second factor if the user has confirmed TOTP enrollment
+	if !h.checkLoginTOTP(c, user.ID, req.TOTPCode) {
+		return
+	}
+
+	// Sync user groups from the directory, which is authoritative for
+	// LDAP users
+	if err := h.syncLDAPGroups(ctx, user.ID, ldapUser.Groups); err != nil {
+		log.Printf("Warning: Failed to sync LDAP groups for user %s: %v", user.ID, err)
+	}
+
+	// Get user groups for JWT
+	groupNames, err := h.userDB.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		groupNames = []string{} // Continue without groups if error
+	}
+
+	// Generate JWT token with session tracking
+	token, err := h.jwtManager.GenerateTokenWithContext(ctx, user.ID, user.Username, user.Email, user.Role, groupNames, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate token",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.jwtManager.GetTokenDuration())
+
+	// Issue a refresh token cookie (see refresh.go)
+	h.issueRefreshToken(c, user.ID)
+
+	// Remove sensitive data
+	user.PasswordHash = ""
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      user,
+	})
+}
+
 // PasswordChangeRequest represents a password change request
 type PasswordChangeRequest struct {
 	OldPassword string `json:"oldPassword" binding:"required"`
@@ -641,7 +983,9 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
-// syncSAMLGroups synchronizes user's group memberships based on SAML assertion
+// syncSAMLGroups synchronizes user's group memberships based on SSO groups.
+//
+// Used for SAML assertions, OIDC group claims and LDAP groups.
 func (h *AuthHandler) syncSAMLGroups(ctx context.Context, userID string, samlGroups []string) error {
 	// For each SAML group, find matching local group and ensure membership
 	for _, samlGroupName := range samlGroups {
@@ -657,3 +1001,35 @@ func (h *AuthHandler) syncSAMLGroups(ctx context.Context, userID string, samlGro
 
 	return nil
 }
+
+// syncLDAPGroups makes an LDAP user's group memberships match the
+// directory: the user is added to the directory's groups and removed from
+// any other group, so access revoked in the directory is revoked here at
+// the next login.
+func (h *AuthHandler) syncLDAPGroups(ctx context.Context, userID string, ldapGroups []string) error {
+	if err := h.syncSAMLGroups(ctx, userID, ldapGroups); err != nil {
+		return err
+	}
+
+	current, err := h.userDB.GetUserGroups(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	inDirectory := make(map[string]bool, len(ldapGroups))
+	for _, name := range ldapGroups {
+		inDirectory[name] = true
+	}
+	for _, name := range current {
+		if inDirectory[name] {
+			continue
+		}
+		if err := h.userDB.RemoveUserFromGroup(ctx, userID, name); err != nil {
+			log.Printf("Warning: Failed to remove user %s from group %s: %v", userID, name, err)
+		} else {
+			log.Printf("Removed user %s from group %s (not in LDAP)", userID, name)
+		}
+	}
+
+	return nil
+}
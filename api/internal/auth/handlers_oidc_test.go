@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOIDCAuthenticator mocks the OIDC authenticator
+type MockOIDCAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockOIDCAuthenticator) GetAuthorizationURL(state string) string {
+	args := m.Called(state)
+	return args.String(0)
+}
+
+func (m *MockOIDCAuthenticator) HandleCallback(ctx context.Context, code string) (*OIDCUserInfo, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCUserInfo), args.Error(1)
+}
+
+func TestOIDCLogin_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/login", nil)
+
+	handler.OIDCLogin(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestOIDCLogin_RedirectsWithState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOIDC := new(MockOIDCAuthenticator)
+	mockOIDC.On("GetAuthorizationURL", mock.AnythingOfType("string")).Return("https://idp.example.com/authorize")
+
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/login", nil)
+
+	handler.OIDCLogin(c)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://idp.example.com/authorize", w.Header().Get("Location"))
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "oidc_state=")
+	mockOIDC.AssertExpectations(t)
+}
+
+func TestOIDCCallback_StateMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOIDC := new(MockOIDCAuthenticator)
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=other&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "expected"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockOIDC.AssertNotCalled(t, "HandleCallback", mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_ExchangeFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOIDC := new(MockOIDCAuthenticator)
+	mockOIDC.On("HandleCallback", mock.Anything, "abc").Return(nil, errors.New("invalid code"))
+
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=s1&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "s1"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestOIDCCallback_NewUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockOIDC := new(MockOIDCAuthenticator)
+
+	userInfo := &OIDCUserInfo{
+		Subject:       "sub-123",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Username:      "jane",
+		FullName:      "Jane Doe",
+		Groups:        []string{"engineering"},
+	}
+	mockOIDC.On("HandleCallback", mock.Anything, "abc").Return(userInfo, nil)
+
+	createdUser := &models.User{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Role:     "user",
+		Active:   true,
+	}
+	mockUserDB.On("GetUserByEmail", mock.Anything, "jane@example.com").Return(nil, errors.New("not found"))
+	mockUserDB.On("CreateUser", mock.Anything, mock.MatchedBy(func(req *models.CreateUserRequest) bool {
+		return req.Provider == "oidc" && req.Username == "jane" && req.FullName == "Jane Doe"
+	})).Return(createdUser, nil)
+	mockUserDB.On("AddUserToGroup", mock.Anything, "user-1", "engineering").Return(nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{"engineering"}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "jane", "jane@example.com", "user",
+		[]string{"engineering"}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=s1&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "s1"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "jwt-token", response["token"])
+	assert.Equal(t, "/", response["returnUrl"])
+	mockUserDB.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestOIDCCallback_RejectsUnverifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockOIDC := new(MockOIDCAuthenticator)
+	mockOIDC.On("HandleCallback", mock.Anything, "abc").Return(&OIDCUserInfo{
+		Subject:  "sub-123",
+		Email:    "admin@example.com",
+		Username: "admin",
+	}, nil)
+
+	handler := NewAuthHandler(mockUserDB, new(MockJWTManager), nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=s1&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "s1"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUserDB.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_RefusesToLinkOtherProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockOIDC := new(MockOIDCAuthenticator)
+	mockOIDC.On("HandleCallback", mock.Anything, "abc").Return(&OIDCUserInfo{
+		Subject:       "sub-123",
+		Email:         "admin@example.com",
+		EmailVerified: true,
+		Username:      "admin",
+	}, nil)
+	mockUserDB.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(&models.User{
+		ID:       "user-admin",
+		Username: "admin",
+		Email:    "admin@example.com",
+		Role:     "admin",
+		Provider: "local",
+		Active:   true,
+	}, nil)
+
+	handler := NewAuthHandler(mockUserDB, new(MockJWTManager), nil, mockOIDC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=s1&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "s1"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUserDB.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_IssuesRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockOIDC := new(MockOIDCAuthenticator)
+	refreshStore := newMemoryRefreshStore()
+
+	mockOIDC.On("HandleCallback", mock.Anything, "abc").Return(&OIDCUserInfo{
+		Subject:       "sub-123",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Username:      "jane",
+	}, nil)
+	mockUserDB.On("GetUserByEmail", mock.Anything, "jane@example.com").Return(&models.User{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Role:     "user",
+		Provider: "oidc",
+		Active:   true,
+	}, nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "jane", "jane@example.com", "user",
+		[]string{}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, mockOIDC)
+	handler.SetRefreshTokenStore(refreshStore)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/auth/oidc/callback?state=s1&code=abc", nil)
+	c.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "s1"})
+
+	handler.OIDCCallback(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var refreshCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == refreshTokenCookie {
+			refreshCookie = cookie
+		}
+	}
+	require.NotNil(t, refreshCookie)
+	assert.Len(t, refreshStore.users, 1)
+}
@@ -107,6 +107,11 @@ func (m *MockUserDB) AddUserToGroup(ctx context.Context, userID, groupName strin
 	return args.Error(0)
 }
 
+func (m *MockUserDB) RemoveUserFromGroup(ctx context.Context, userID, groupName string) error {
+	args := m.Called(ctx, userID, groupName)
+	return args.Error(0)
+}
+
 func (m *MockUserDB) DB() *sql.DB {
 	return nil
 }
@@ -134,6 +139,11 @@ func (m *MockJWTManager) ValidateToken(token string) (*Claims, error) {
 	return args.Get(0).(*Claims), args.Error(1)
 }
 
+func (m *MockJWTManager) ValidateSession(ctx context.Context, sessionID string) (bool, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockJWTManager) InvalidateSession(ctx context.Context, sessionID string) error {
 	args := m.Called(ctx, sessionID)
 	return args.Error(0)
@@ -150,7 +160,7 @@ func TestSAMLLogin_NotConfigured(t *testing.T) {
 	mockJWT := new(MockJWTManager)
 
 	// Create handler without SAML (nil)
-	handler := NewAuthHandler(mockUserDB, mockJWT, nil)
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
 
 	// Create test context
 	w := httptest.NewRecorder()
@@ -178,7 +188,7 @@ func TestSAMLLogin_WithConfiguration(t *testing.T) {
 	mockMiddleware := &samlsp.Middleware{}
 	mockSAML.On("GetMiddleware").Return(mockMiddleware)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -197,7 +207,7 @@ func TestSAMLCallback_NotConfigured(t *testing.T) {
 	mockUserDB := new(MockUserDB)
 	mockJWT := new(MockJWTManager)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, nil)
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -218,7 +228,7 @@ func TestSAMLCallback_NoAssertion(t *testing.T) {
 	mockJWT := new(MockJWTManager)
 	mockSAML := new(MockSAMLAuthenticator)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -248,7 +258,7 @@ func TestSAMLCallback_MissingEmail(t *testing.T) {
 		Groups:    []string{},
 	}, nil)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -304,7 +314,7 @@ func TestSAMLCallback_CreateNewUser(t *testing.T) {
 	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user123", "test@example.com", "test@example.com", "user", []string{"group1"}, mock.Anything, mock.Anything).Return("jwt-token-123", nil)
 	mockJWT.On("GetTokenDuration").Return(24 * time.Hour)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -362,7 +372,7 @@ func TestSAMLCallback_UpdateExistingUser(t *testing.T) {
 	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user456", "existing@example.com", "existing@example.com", "user", []string{}, mock.Anything, mock.Anything).Return("jwt-token-456", nil)
 	mockJWT.On("GetTokenDuration").Return(24 * time.Hour)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -408,7 +418,7 @@ func TestSAMLCallback_InactiveUser(t *testing.T) {
 	mockUserDB.On("GetUserByEmail", mock.Anything, "inactive@example.com").Return(inactiveUser, nil)
 	mockUserDB.On("UpdateUser", mock.Anything, "user789", mock.AnythingOfType("*models.UpdateUserRequest")).Return(nil)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -429,7 +439,7 @@ func TestSAMLMetadata_NotConfigured(t *testing.T) {
 	mockUserDB := new(MockUserDB)
 	mockJWT := new(MockJWTManager)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, nil)
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -453,7 +463,7 @@ func TestSAMLMetadata_NilServiceProvider(t *testing.T) {
 	// SP is nil
 	mockSAML.On("GetServiceProvider").Return(nil)
 
-	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML)
+	handler := NewAuthHandler(mockUserDB, mockJWT, mockSAML, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
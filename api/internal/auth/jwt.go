@@ -177,8 +177,9 @@ type Claims struct {
 	// - user: Standard access (own sessions only)
 	Role string `json:"role"`
 
-	// Groups lists the teams/groups the user belongs to.
-	// Used for team-based resource sharing and quotas.
+	// Groups lists the names of the teams/groups the user belongs to.
+	// Used for team-based resource sharing and quotas, and by
+	// GroupMiddleware without a database lookup.
 	// Omitted from token if user has no group memberships.
 	Groups []string `json:"groups,omitempty"`
 
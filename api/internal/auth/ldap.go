@@ -0,0 +1,311 @@
+// Package auth provides authentication and authorization mechanisms for StreamSpace.
+// This file implements LDAP authentication against enterprise directories
+// such as Active Directory and OpenLDAP.
+//
+// LDAP AUTHENTICATION FLOW (POST /auth/ldap/login):
+//
+//  1. Bind with the service account (LDAP_BIND_DN), or anonymously
+//  2. Search LDAP_USER_BASE_DN for exactly one entry matching LDAP_USER_FILTER
+//  3. Bind as that entry with the user's password to verify it
+//  4. Rebind with the service account and look up the user's groups
+//  5. The handler provisions the user (provider "ldap") on first login,
+//     syncs group memberships and returns a JWT like POST /auth/login
+//
+// GROUPS:
+//
+// When LDAP_GROUP_BASE_DN is set, the groups are the cn of the entries
+// below it matching LDAP_GROUP_FILTER, where %s is the user's DN (default:
+// "(member=%s)"). Otherwise they are taken from the user's memberOf
+// attribute. Groups are matched to StreamSpace groups by name, and an LDAP
+// user is removed from groups the directory no longer lists at each login.
+//
+// TRANSPORT SECURITY:
+//
+// Passwords are never sent in clear text. LDAP_URL must be an ldaps:// URL,
+// or an ldap:// URL with LDAP_START_TLS=true, which upgrades the connection
+// with StartTLS before binding.
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// defaultLDAPUserFilter finds Active Directory users by login name
+	defaultLDAPUserFilter = "(sAMAccountName=%s)"
+
+	// defaultLDAPGroupFilter finds the groups listing a user DN as member
+	defaultLDAPGroupFilter = "(member=%s)"
+
+	// ldapTimeout bounds dialing and each LDAP request
+	ldapTimeout = 10 * time.Second
+)
+
+// ErrLDAPInvalidCredentials is returned when the user does not exist in the
+// directory, is ambiguous, or the password is wrong.
+var ErrLDAPInvalidCredentials = errors.New("invalid LDAP credentials")
+
+// LDAPConfig holds LDAP authentication configuration
+type LDAPConfig struct {
+	URL                string `json:"url"`                  // ldaps://host:636, or ldap://host:389 with StartTLS
+	BindDN             string `json:"bind_dn"`              // Service account DN (empty for anonymous search)
+	BindPassword       string `json:"-"`                    // Service account password
+	UserBaseDN         string `json:"user_base_dn"`         // Base DN of the user search
+	UserFilter         string `json:"user_filter"`          // User search filter, %s is the username (default: (sAMAccountName=%s))
+	GroupBaseDN        string `json:"group_base_dn"`        // Base DN of the group search (empty to use memberOf)
+	GroupFilter        string `json:"group_filter"`         // Group search filter, %s is the user DN (default: (member=%s))
+	StartTLS           bool   `json:"start_tls"`            // Upgrade ldap:// connections with StartTLS
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // Skip TLS verification (dev only)
+}
+
+// LDAPUser holds the attributes of an authenticated directory user
+type LDAPUser struct {
+	DN       string   `json:"dn"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	FullName string   `json:"fullName"`
+	Groups   []string `json:"groups"`
+}
+
+// LDAPAuthenticator authenticates users against an LDAP directory
+type LDAPAuthenticator struct {
+	config    *LDAPConfig
+	tlsConfig *tls.Config
+}
+
+// NewLDAPAuthenticator creates a new LDAP authenticator.
+//
+// Returns an error if required settings are missing or the configuration
+// would send passwords without TLS.
+func NewLDAPAuthenticator(config *LDAPConfig) (*LDAPAuthenticator, error) {
+	if config == nil || config.URL == "" {
+		return nil, fmt.Errorf("LDAP URL is required")
+	}
+	if config.UserBaseDN == "" {
+		return nil, fmt.Errorf("LDAP user base DN is required")
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ldaps":
+		if config.StartTLS {
+			return nil, fmt.Errorf("LDAP StartTLS cannot be used with an ldaps:// URL")
+		}
+	case "ldap":
+		if !config.StartTLS {
+			return nil, fmt.Errorf("LDAP URL %s is not encrypted: use ldaps:// or enable StartTLS", config.URL)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported LDAP URL scheme %q: use ldaps:// or ldap:// with StartTLS", u.Scheme)
+	}
+
+	if config.UserFilter == "" {
+		config.UserFilter = defaultLDAPUserFilter
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = defaultLDAPGroupFilter
+	}
+
+	if config.InsecureSkipVerify {
+		log.Printf("[WARNING] LDAP: TLS verification disabled (insecure, use only in development)")
+	}
+
+	return &LDAPAuthenticator{
+		config: config,
+		tlsConfig: &tls.Config{
+			ServerName:         u.Hostname(),
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		},
+	}, nil
+}
+
+// Authenticate verifies username and password against the directory and
+// returns the user's attributes and groups.
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*LDAPUser, error) {
+	// An empty password would be an unauthenticated bind, which most
+	// servers accept without checking anything
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := a.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.config.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(a.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"dn", "mail", "displayName", "cn", "memberOf"},
+		nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("LDAP user search failed: %w", err)
+	}
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP user bind failed: %w", err)
+	}
+
+	user := &LDAPUser{
+		DN:       entry.DN,
+		Username: username,
+		Email:    entry.GetAttributeValue("mail"),
+		FullName: entry.GetAttributeValue("displayName"),
+	}
+	if user.FullName == "" {
+		user.FullName = entry.GetAttributeValue("cn")
+	}
+
+	// The user may not be allowed to read groups, so search them as the
+	// service account again
+	if err := a.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	user.Groups, err = a.userGroups(conn, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] LDAP: Successfully authenticated user: %s (dn: %s, groups: %v)", user.Username, user.DN, user.Groups)
+
+	return user, nil
+}
+
+// dial connects to the directory, upgrading ldap:// connections with StartTLS
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(a.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if a.config.StartTLS {
+		if err := conn.StartTLS(a.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// bindServiceAccount binds as LDAP_BIND_DN, or anonymously when none is set
+func (a *LDAPAuthenticator) bindServiceAccount(conn *ldap.Conn) error {
+	var err error
+	if a.config.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(a.config.BindDN, a.config.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("LDAP service account bind failed: %w", err)
+	}
+	return nil
+}
+
+// userGroups returns the names of the groups the user entry belongs to
+func (a *LDAPAuthenticator) userGroups(conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	if a.config.GroupBaseDN == "" {
+		var groups []string
+		for _, dn := range entry.GetAttributeValues("memberOf") {
+			if name := groupNameFromDN(dn); name != "" {
+				groups = append(groups, name)
+			}
+		}
+		return groups, nil
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.config.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(a.config.GroupFilter, ldap.EscapeFilter(entry.DN)),
+		[]string{"cn"},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("LDAP group search failed: %w", err)
+	}
+
+	var groups []string
+	for _, group := range result.Entries {
+		if name := group.GetAttributeValue("cn"); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// groupNameFromDN returns the cn of a group DN such as
+// "CN=Engineering,OU=Groups,DC=example,DC=com", or "" if it has none.
+func groupNameFromDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// LDAPConfigFromEnv builds an LDAPConfig from environment variables.
+//
+// Environment variables:
+//   - LDAP_URL: Directory URL, ldaps://host:636 or ldap://host:389 with StartTLS (enables LDAP)
+//   - LDAP_BIND_DN, LDAP_BIND_PASSWORD: Service account used for searches (default: anonymous)
+//   - LDAP_USER_BASE_DN: Base DN of the user search (required)
+//   - LDAP_USER_FILTER: User search filter, %s is the username (default: (sAMAccountName=%s))
+//   - LDAP_GROUP_BASE_DN: Base DN of the group search (default: use the memberOf attribute)
+//   - LDAP_GROUP_FILTER: Group search filter, %s is the user DN (default: (member=%s))
+//   - LDAP_START_TLS: "true" to upgrade ldap:// connections with StartTLS
+//   - LDAP_INSECURE_SKIP_VERIFY: "true" to skip TLS certificate verification (dev only)
+//
+// Returns nil when LDAP_URL is not set. Missing required values and
+// unencrypted URLs are reported by NewLDAPAuthenticator.
+func LDAPConfigFromEnv() *LDAPConfig {
+	ldapURL := os.Getenv("LDAP_URL")
+	if ldapURL == "" {
+		return nil
+	}
+
+	return &LDAPConfig{
+		URL:                ldapURL,
+		BindDN:             os.Getenv("LDAP_BIND_DN"),
+		BindPassword:       os.Getenv("LDAP_BIND_PASSWORD"),
+		UserBaseDN:         os.Getenv("LDAP_USER_BASE_DN"),
+		UserFilter:         os.Getenv("LDAP_USER_FILTER"),
+		GroupBaseDN:        os.Getenv("LDAP_GROUP_BASE_DN"),
+		GroupFilter:        os.Getenv("LDAP_GROUP_FILTER"),
+		StartTLS:           os.Getenv("LDAP_START_TLS") == "true",
+		InsecureSkipVerify: os.Getenv("LDAP_INSECURE_SKIP_VERIFY") == "true",
+	}
+}
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockLDAPAuthenticator mocks the LDAP authenticator
+type MockLDAPAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockLDAPAuthenticator) Authenticate(username, password string) (*LDAPUser, error) {
+	args := m.Called(username, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LDAPUser), args.Error(1)
+}
+
+func TestNewLDAPAuthenticator_EnforcesTLS(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		startTLS bool
+		wantErr  string
+	}{
+		{name: "ldaps", url: "ldaps://dc.example.com:636"},
+		{name: "ldap with StartTLS", url: "ldap://dc.example.com:389", startTLS: true},
+		{name: "plain ldap", url: "ldap://dc.example.com:389", wantErr: "not encrypted"},
+		{name: "ldaps with StartTLS", url: "ldaps://dc.example.com:636", startTLS: true, wantErr: "StartTLS cannot be used"},
+		{name: "unsupported scheme", url: "http://dc.example.com", wantErr: "unsupported LDAP URL scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, err := NewLDAPAuthenticator(&LDAPConfig{
+				URL:        tt.url,
+				UserBaseDN: "OU=Users,DC=example,DC=com",
+				StartTLS:   tt.startTLS,
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "dc.example.com", authenticator.tlsConfig.ServerName)
+		})
+	}
+}
+
+func TestLDAPConfigFromEnv(t *testing.T) {
+	t.Setenv("LDAP_URL", "")
+	assert.Nil(t, LDAPConfigFromEnv())
+
+	t.Setenv("LDAP_URL", "ldaps://dc.example.com")
+	t.Setenv("LDAP_USER_BASE_DN", "OU=Users,DC=example,DC=com")
+	t.Setenv("LDAP_START_TLS", "")
+	config := LDAPConfigFromEnv()
+	require.NotNil(t, config)
+
+	authenticator, err := NewLDAPAuthenticator(config)
+	require.NoError(t, err)
+	assert.Equal(t, "(sAMAccountName=%s)", authenticator.config.UserFilter)
+	assert.Equal(t, "(member=%s)", authenticator.config.GroupFilter)
+}
+
+func TestLDAPAuthenticate_RejectsEmptyPassword(t *testing.T) {
+	authenticator, err := NewLDAPAuthenticator(&LDAPConfig{
+		URL:        "ldaps://dc.example.com",
+		UserBaseDN: "OU=Users,DC=example,DC=com",
+	})
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate("jane", "")
+	assert.ErrorIs(t, err, ErrLDAPInvalidCredentials)
+}
+
+func TestGroupNameFromDN(t *testing.T) {
+	assert.Equal(t, "Engineering", groupNameFromDN("CN=Engineering,OU=Groups,DC=example,DC=com"))
+	assert.Equal(t, "R&D, Europe", groupNameFromDN(`cn=R&D\, Europe,ou=groups,dc=example,dc=com`))
+	assert.Empty(t, groupNameFromDN("OU=Groups,DC=example,DC=com"))
+	assert.Empty(t, groupNameFromDN("not a dn"))
+}
+
+func TestLDAPLogin_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/ldap/login", strings.NewReader(`{"username":"jane","password":"secret"}`))
+
+	handler.LDAPLogin(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestLDAPLogin_InvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockLDAP := new(MockLDAPAuthenticator)
+	mockLDAP.On("Authenticate", "jane", "wrong").Return(nil, ErrLDAPInvalidCredentials)
+
+	handler := NewAuthHandler(new(MockUserDB), new(MockJWTManager), nil, nil)
+	handler.SetLDAPAuthenticator(mockLDAP)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/ldap/login", strings.NewReader(`{"username":"jane","password":"wrong"}`))
+
+	handler.LDAPLogin(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLDAPLogin_NewUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockLDAP := new(MockLDAPAuthenticator)
+
+	mockLDAP.On("Authenticate", "jane", "secret").Return(&LDAPUser{
+		DN:       "CN=Jane Doe,OU=Users,DC=example,DC=com",
+		Username: "jane",
+		Email:    "jane@example.com",
+		FullName: "Jane Doe",
+		Groups:   []string{"engineering"},
+	}, nil)
+
+	createdUser := &models.User{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Role:     "user",
+		Active:   true,
+	}
+	mockUserDB.On("GetUserByEmail", mock.Anything, "jane@example.com").Return(nil, errors.New("not found"))
+	mockUserDB.On("CreateUser", mock.Anything, mock.MatchedBy(func(req *models.CreateUserRequest) bool {
+		return req.Provider == "ldap" && req.Username == "jane" && req.FullName == "Jane Doe"
+	})).Return(createdUser, nil)
+	mockUserDB.On("AddUserToGroup", mock.Anything, "user-1", "engineering").Return(nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{"engineering"}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "jane", "jane@example.com", "user",
+		[]string{"engineering"}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	handler.SetLDAPAuthenticator(mockLDAP)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/ldap/login", strings.NewReader(`{"username":"jane","password":"secret"}`))
+
+	handler.LDAPLogin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "jwt-token", response.Token)
+	assert.Equal(t, "user-1", response.User.ID)
+	mockUserDB.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestLDAPLogin_RemovesStaleGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockLDAP := new(MockLDAPAuthenticator)
+
+	mockLDAP.On("Authenticate", "jane", "secret").Return(&LDAPUser{
+		DN:       "CN=Jane Doe,OU=Users,DC=example,DC=com",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Groups:   []string{"engineering"},
+	}, nil)
+	mockUserDB.On("GetUserByEmail", mock.Anything, "jane@example.com").Return(&models.User{
+		ID:       "user-1",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Role:     "user",
+		Provider: "ldap",
+		Active:   true,
+	}, nil)
+	mockUserDB.On("AddUserToGroup", mock.Anything, "user-1", "engineering").Return(nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{"engineering", "finance"}, nil).Once()
+	mockUserDB.On("RemoveUserFromGroup", mock.Anything, "user-1", "finance").Return(nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{"engineering"}, nil).Once()
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "jane", "jane@example.com", "user",
+		[]string{"engineering"}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	handler.SetLDAPAuthenticator(mockLDAP)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/ldap/login", strings.NewReader(`{"username":"jane","password":"secret"}`))
+
+	handler.LDAPLogin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUserDB.AssertExpectations(t)
+	mockUserDB.AssertNotCalled(t, "RemoveUserFromGroup", mock.Anything, "user-1", "engineering")
+	mockJWT.AssertExpectations(t)
+}
+
+func TestLDAPLogin_RefusesToLinkOtherProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockLDAP := new(MockLDAPAuthenticator)
+	mockLDAP.On("Authenticate", "admin", "secret").Return(&LDAPUser{
+		DN:       "CN=admin,OU=Users,DC=example,DC=com",
+		Username: "admin",
+		Email:    "admin@example.com",
+	}, nil)
+	mockUserDB.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(&models.User{
+		ID:       "user-admin",
+		Username: "admin",
+		Email:    "admin@example.com",
+		Role:     "admin",
+		Provider: "local",
+		Active:   true,
+	}, nil)
+
+	handler := NewAuthHandler(mockUserDB, new(MockJWTManager), nil, nil)
+	handler.SetLDAPAuthenticator(mockLDAP)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/ldap/login", strings.NewReader(`{"username":"admin","password":"secret"}`))
+
+	handler.LDAPLogin(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
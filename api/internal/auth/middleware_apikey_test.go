@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAPIKeyAuthTest(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	router := gin.New()
+	router.Use(Middleware(nil, db.NewUserDB(mockDB)))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"userID":     c.GetString("userID"),
+			"userRole":   c.GetString("userRole"),
+			"authMethod": c.GetString("authMethod"),
+		})
+	})
+
+	return router, mock
+}
+
+func TestMiddleware_APIKey_Success(t *testing.T) {
+	router, mock := setupAPIKeyAuthTest(t)
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs(hashAPIKey("sk_test")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, "user123"))
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "full_name", "role", "provider", "active", "created_at", "updated_at", "last_login"}).
+			AddRow("user123", "ci-bot", "ci@example.com", "CI Bot", "operator", "local", true,
+				time.Now(), time.Now(), sql.NullTime{}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "ApiKey sk_test")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"userID":"user123","userRole":"operator","authMethod":"apikey"}`, w.Body.String())
+}
+
+func TestMiddleware_APIKey_Invalid(t *testing.T) {
+	router, mock := setupAPIKeyAuthTest(t)
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs(hashAPIKey("sk_revoked")).
+		WillReturnError(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "ApiKey sk_revoked")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMiddleware_APIKey_DisabledUser(t *testing.T) {
+	router, mock := setupAPIKeyAuthTest(t)
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs(hashAPIKey("sk_test")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, "user123"))
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "full_name", "role", "provider", "active", "created_at", "updated_at", "last_login"}).
+			AddRow("user123", "ci-bot", "ci@example.com", "CI Bot", "user", "local", false,
+				time.Now(), time.Now(), sql.NullTime{}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "ApiKey sk_test")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		groups     []string
+		setGroups  bool
+		wantStatus int
+	}{
+		{name: "member", groups: []string{"engineering", "finance"}, setGroups: true, wantStatus: http.StatusOK},
+		{name: "not a member", groups: []string{"engineering"}, setGroups: true, wantStatus: http.StatusForbidden},
+		{name: "no groups", groups: nil, setGroups: true, wantStatus: http.StatusForbidden},
+		{name: "not authenticated", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.setGroups {
+					c.Set("userGroups", tt.groups)
+				}
+			})
+			router.GET("/billing", GroupMiddleware("finance"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/billing", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
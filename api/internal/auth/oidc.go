@@ -185,13 +185,15 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strings"
-	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
@@ -474,7 +476,11 @@ func extractArrayClaim(claims map[string]interface{}, claimName string) []string
 // OIDCLoginHandler initiates OIDC authentication flow
 func (a *OIDCAuthenticator) OIDCLoginHandler(c *gin.Context) {
 	// Generate state parameter for CSRF protection
-	state := generateRandomState()
+	state, err := generateRandomState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state"})
+		return
+	}
 
 	// Store state in session/cookie (for CSRF validation)
 	c.SetCookie("oidc_state", state, 600, "/", "", false, true)
@@ -571,19 +577,52 @@ type User struct {
 }
 
 // generateRandomState generates a random state string for CSRF protection
-func generateRandomState() string {
-	// Use timestamp + random component for state
-	return fmt.Sprintf("%d-%s", time.Now().Unix(), randomString(32))
+func generateRandomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-// randomString generates a random string of given length
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+// OIDCConfigFromEnv builds an OIDCConfig from environment variables.
+//
+// Environment variables:
+//   - OIDC_ENABLED: "true" to enable OIDC authentication
+//   - OIDC_ISSUER_URL: Provider issuer / discovery URL (required)
+//   - OIDC_CLIENT_ID: OAuth2 client ID (required)
+//   - OIDC_CLIENT_SECRET: OAuth2 client secret (required)
+//   - OIDC_REDIRECT_URI: Callback URL, e.g. https://streamspace.example.com/api/v1/auth/oidc/callback (required)
+//   - OIDC_SCOPES: Comma-separated scopes (default: openid,profile,email)
+//   - OIDC_USERNAME_CLAIM, OIDC_EMAIL_CLAIM, OIDC_GROUPS_CLAIM: Claim name overrides
+//
+// Returns nil when OIDC_ENABLED is not "true". Missing required values are
+// reported by NewOIDCAuthenticator.
+func OIDCConfigFromEnv() *OIDCConfig {
+	if os.Getenv("OIDC_ENABLED") != "true" {
+		return nil
+	}
+
+	config := &OIDCConfig{
+		Enabled:       true,
+		ProviderURL:   os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURI:   os.Getenv("OIDC_REDIRECT_URI"),
+		UsernameClaim: os.Getenv("OIDC_USERNAME_CLAIM"),
+		EmailClaim:    os.Getenv("OIDC_EMAIL_CLAIM"),
+		GroupsClaim:   os.Getenv("OIDC_GROUPS_CLAIM"),
+	}
+
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				config.Scopes = append(config.Scopes, scope)
+			}
+		}
 	}
-	return string(b)
+
+	return config
 }
 
 // GetDiscoveryDocument returns the OIDC discovery document
@@ -0,0 +1,266 @@
+// Package auth provides authentication and authorization mechanisms for StreamSpace.
+// This file implements sliding JWT renewal and refresh tokens.
+//
+// TOKEN REFRESH (POST /auth/refresh):
+//
+// The endpoint accepts, in order of preference:
+//  1. An unexpired JWT in the "Authorization: Bearer" header (or, for older
+//     clients, in a JSON body {"token": "..."})
+//  2. A refresh token in the HTTP-only streamspace_refresh cookie
+//
+// A JWT with more than 5 minutes left is returned unchanged, so clients may
+// call the endpoint as often as they like. A JWT closer to expiry, or a
+// valid refresh token, yields a newly issued JWT with a fresh expiry. New
+// tokens are built from the current user record, so role changes and
+// disabled accounts take effect on renewal.
+//
+// REFRESH TOKENS:
+//
+// When a RefreshTokenStore is configured, login issues a random refresh
+// token in an HTTP-only cookie scoped to /api/v1/auth. Only its SHA-256
+// digest is stored (refresh_tokens table). Refresh tokens are single use:
+// redeeming one revokes it and sets a new one in the cookie. Logout revokes
+// it.
+//
+// JWTs are only renewed while their server-side session is valid, so a
+// token invalidated by logout cannot be renewed.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+)
+
+const (
+	// refreshTokenCookie is the HTTP-only cookie carrying the refresh token
+	refreshTokenCookie = "streamspace_refresh"
+
+	// refreshTokenCookiePath limits the cookie to the auth endpoints
+	refreshTokenCookiePath = "/api/v1/auth"
+
+	// refreshTokenDuration is how long a refresh token remains usable
+	refreshTokenDuration = 30 * 24 * time.Hour
+
+	// tokenReissueWindow is how close to expiry a JWT must be before
+	// /auth/refresh issues a new one instead of returning it unchanged
+	tokenReissueWindow = 5 * time.Minute
+)
+
+// RefreshTokenStore defines the interface for refresh token persistence (see db.RefreshTokenDB)
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (string, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+}
+
+// SetRefreshTokenStore enables refresh tokens. Without a store, login issues
+// no refresh token and /auth/refresh only renews JWTs.
+func (h *AuthHandler) SetRefreshTokenStore(store RefreshTokenStore) {
+	h.refreshStore = store
+}
+
+// RefreshTokenRequest represents a token refresh request from clients that
+// send the JWT in the body rather than the Authorization header
+type RefreshTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// RefreshToken handles token refresh
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	if token := bearerToken(c); token != "" {
+		h.renewToken(c, token)
+		return
+	}
+
+	var req RefreshTokenRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	if req.Token != "" {
+		h.renewToken(c, req.Token)
+		return
+	}
+
+	if refreshToken, err := c.Cookie(refreshTokenCookie); err == nil && refreshToken != "" && h.refreshStore != nil {
+		h.redeemRefreshToken(c, refreshToken)
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "No token provided"})
+}
+
+// renewToken returns token unchanged if it is far from expiry, otherwise a
+// newly issued token for the same user. Like the auth middleware, it
+// rejects tokens whose session was invalidated.
+func (h *AuthHandler) renewToken(c *gin.Context, token string) {
+	claims, err := h.jwtManager.ValidateToken(token)
+	if err != nil || claims.ExpiresAt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	if claims.ID != "" {
+		valid, err := h.jwtManager.ValidateSession(c.Request.Context(), claims.ID)
+		if err != nil || !valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or invalidated"})
+			return
+		}
+	}
+
+	user, ok := h.activeUser(c, claims.UserID)
+	if !ok {
+		return
+	}
+
+	if time.Until(claims.ExpiresAt.Time) > tokenReissueWindow {
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:     token,
+			ExpiresAt: claims.ExpiresAt.Time,
+			User:      user,
+		})
+		return
+	}
+
+	h.respondWithNewToken(c, user)
+}
+
+// redeemRefreshToken issues a new JWT for the owner of a refresh token. The
+// refresh token is revoked and replaced by a new one.
+func (h *AuthHandler) redeemRefreshToken(c *gin.Context, refreshToken string) {
+	userID, err := h.refreshStore.ConsumeRefreshToken(c.Request.Context(), hashRefreshToken(refreshToken))
+	if err == sql.ErrNoRows {
+		clearRefreshTokenCookie(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate refresh token"})
+		return
+	}
+
+	if user, ok := h.activeUser(c, userID); ok {
+		h.issueRefreshToken(c, user.ID)
+		h.respondWithNewToken(c, user)
+	}
+}
+
+// activeUser loads the user a token was issued to, writing an error
+// response and returning false if the user is missing or disabled.
+func (h *AuthHandler) activeUser(c *gin.Context, userID string) (*models.User, bool) {
+	user, err := h.userDB.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return nil, false
+	}
+	if !user.Active {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return nil, false
+	}
+
+	user.PasswordHash = ""
+	return user, true
+}
+
+// respondWithNewToken issues a JWT built from the current user record.
+func (h *AuthHandler) respondWithNewToken(c *gin.Context, user *models.User) {
+	ctx := c.Request.Context()
+
+	groupNames, err := h.userDB.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		groupNames = []string{} // Continue without groups if error
+	}
+
+	token, err := h.jwtManager.GenerateTokenWithContext(ctx, user.ID, user.Username, user.Email, user.Role, groupNames, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate token",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:     token,
+		ExpiresAt: time.Now().Add(h.jwtManager.GetTokenDuration()),
+		User:      user,
+	})
+}
+
+// issueRefreshToken stores a new refresh token for userID and sets it as an
+// HTTP-only cookie. Failures are logged; login still succeeds without one.
+func (h *AuthHandler) issueRefreshToken(c *gin.Context, userID string) {
+	if h.refreshStore == nil {
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Warning: Failed to generate refresh token: %v", err)
+		return
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(b)
+
+	expiresAt := time.Now().Add(refreshTokenDuration)
+	if err := h.refreshStore.CreateRefreshToken(c.Request.Context(), userID, hashRefreshToken(refreshToken), expiresAt); err != nil {
+		log.Printf("Warning: Failed to store refresh token for user %s: %v", userID, err)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, refreshToken, int(refreshTokenDuration.Seconds()), refreshTokenCookiePath, "", c.Request.TLS != nil, true)
+}
+
+// revokeRefreshToken revokes the refresh token in the request cookie, if
+// any, and clears the cookie.
+func (h *AuthHandler) revokeRefreshToken(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshTokenCookie)
+	if err != nil || refreshToken == "" {
+		return
+	}
+
+	if h.refreshStore != nil {
+		if err := h.refreshStore.RevokeRefreshToken(c.Request.Context(), hashRefreshToken(refreshToken)); err != nil {
+			// Log error but don't fail logout
+			log.Printf("Warning: Failed to revoke refresh token: %v", err)
+		}
+	}
+	clearRefreshTokenCookie(c)
+}
+
+// clearRefreshTokenCookie expires the refresh token cookie.
+func clearRefreshTokenCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, "", -1, refreshTokenCookiePath, "", c.Request.TLS != nil, true)
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// hashRefreshToken returns the SHA-256 hex digest stored for a refresh token.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
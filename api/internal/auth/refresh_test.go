@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRefreshStore is an in-memory RefreshTokenStore
+type memoryRefreshStore struct {
+	users   map[string]string
+	revoked map[string]bool
+}
+
+func newMemoryRefreshStore() *memoryRefreshStore {
+	return &memoryRefreshStore{users: map[string]string{}, revoked: map[string]bool{}}
+}
+
+func (s *memoryRefreshStore) CreateRefreshToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	s.users[tokenHash] = userID
+	return nil
+}
+
+func (s *memoryRefreshStore) ConsumeRefreshToken(ctx context.Context, tokenHash string) (string, error) {
+	userID, ok := s.users[tokenHash]
+	if !ok || s.revoked[tokenHash] {
+		return "", sql.ErrNoRows
+	}
+	s.revoked[tokenHash] = true
+	return userID, nil
+}
+
+func (s *memoryRefreshStore) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	s.revoked[tokenHash] = true
+	return nil
+}
+
+func claimsExpiringIn(d time.Duration) *Claims {
+	return &Claims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(d)),
+		},
+	}
+}
+
+func performRefresh(h *AuthHandler, configure func(req *http.Request)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/api/v1/auth/refresh", h.RefreshToken)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	configure(req)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRefreshToken_ReturnsSameTokenWhenFarFromExpiry(t *testing.T) {
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockJWT.On("ValidateToken", "current-token").Return(claimsExpiringIn(time.Hour), nil)
+	mockJWT.On("ValidateSession", mock.Anything, "session-1").Return(true, nil)
+	mockUserDB.On("GetUser", mock.Anything, "user-1").Return(&models.User{ID: "user-1", Active: true}, nil)
+
+	h := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	w := performRefresh(h, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer current-token")
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "current-token", resp.Token)
+	mockJWT.AssertNotCalled(t, "GenerateTokenWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshToken_ReissuesNearExpiry(t *testing.T) {
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockJWT.On("ValidateToken", "current-token").Return(claimsExpiringIn(2*time.Minute), nil)
+	mockJWT.On("ValidateSession", mock.Anything, "session-1").Return(true, nil)
+	mockUserDB.On("GetUser", mock.Anything, "user-1").Return(&models.User{ID: "user-1", Username: "alice", Role: "user", Active: true}, nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{"team-a"}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "alice", "", "user", []string{"team-a"}, mock.Anything, mock.Anything).
+		Return("new-token", nil)
+
+	h := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	w := performRefresh(h, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer current-token")
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "new-token", resp.Token)
+}
+
+func TestRefreshToken_RejectsInvalidToken(t *testing.T) {
+	mockJWT := new(MockJWTManager)
+	mockJWT.On("ValidateToken", "expired-token").Return(nil, jwt.ErrTokenExpired)
+
+	h := NewAuthHandler(new(MockUserDB), mockJWT, nil, nil)
+	w := performRefresh(h, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer expired-token")
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRefreshToken_RejectsInvalidatedSession(t *testing.T) {
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockJWT.On("ValidateToken", "logged-out-token").Return(claimsExpiringIn(2*time.Minute), nil)
+	mockJWT.On("ValidateSession", mock.Anything, "session-1").Return(false, nil)
+
+	h := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	w := performRefresh(h, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer logged-out-token")
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUserDB.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+	mockJWT.AssertNotCalled(t, "GenerateTokenWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// refreshCookie returns the refresh token cookie set by a response, if any.
+func refreshCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == refreshTokenCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestRefreshToken_RefreshCookieRevokedOnLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockUserDB.On("GetUser", mock.Anything, "user-1").Return(&models.User{ID: "user-1", Active: true}, nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("cookie-token", nil)
+
+	store := newMemoryRefreshStore()
+	h := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	h.SetRefreshTokenStore(store)
+
+	// Issue a refresh token as login would
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+	h.issueRefreshToken(c, "user-1")
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	assert.Equal(t, refreshTokenCookie, cookie.Name)
+	assert.True(t, cookie.HttpOnly)
+	assert.NotContains(t, store.users, cookie.Value, "only the hash is stored")
+
+	w = performRefresh(h, func(req *http.Request) { req.AddCookie(cookie) })
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "cookie-token")
+
+	// Redeeming rotates the refresh token: the old one is revoked
+	rotated := refreshCookie(w)
+	require.NotNil(t, rotated)
+	assert.NotEqual(t, cookie.Value, rotated.Value)
+
+	w = performRefresh(h, func(req *http.Request) { req.AddCookie(cookie) })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	cookie = rotated
+
+	// Logout revokes the refresh token
+	router := gin.New()
+	router.POST("/api/v1/auth/logout", h.Logout)
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRefresh(h, func(req *http.Request) { req.AddCookie(cookie) })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
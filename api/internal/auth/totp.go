@@ -0,0 +1,417 @@
+// Package auth provides authentication and authorization mechanisms for StreamSpace.
+// This file implements TOTP-based multi-factor authentication for local accounts.
+//
+// TOTP ENROLLMENT FLOW:
+//
+// 1. Setup (POST /auth/totp/setup, authenticated):
+//   - If TOTP is already enabled, the request must include a current TOTP
+//     or backup code ({"code": "..."}), otherwise it fails with 409
+//   - Generates a new TOTP secret (RFC 6238, 30s period, 6 digits)
+//   - Encrypts the secret with AES-256-GCM and stores it (unconfirmed)
+//   - Generates 8 single-use backup codes, stored as SHA-256 hashes
+//   - Returns the otpauth:// URL, a PNG QR code data URL, and the backup codes
+//
+// 2. Verify (POST /auth/totp/verify, authenticated):
+//   - User submits a 6-digit code from their authenticator app
+//   - On success the enrollment is marked confirmed, replacing any
+//     previously confirmed secret and backup codes
+//
+// 3. Login (POST /auth/login):
+//   - After a successful password check, users with confirmed TOTP must
+//     supply totpCode (a current TOTP code or an unused backup code)
+//   - Failed attempts are written to audit_log with action "totp.failed"
+//
+// SECURITY:
+//
+//   - Plaintext secrets are never stored; the encryption key comes from
+//     TOTP_ENCRYPTION_KEY (falling back to JWT_SECRET) and is stretched with SHA-256
+//   - Backup codes are only shown once, at setup time
+//   - Re-running setup keeps the old secret and backup codes active until the
+//     new secret is verified, so a stolen access token cannot disable TOTP
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpBackupCodeCount is the number of backup codes generated per enrollment
+const totpBackupCodeCount = 8
+
+// TOTPStore defines the interface for TOTP persistence (see db.TOTPDB)
+type TOTPStore interface {
+	GetTOTPSecret(ctx context.Context, userID string) (encryptedSecret string, confirmed bool, err error)
+	GetPendingTOTPSecret(ctx context.Context, userID string) (encryptedSecret string, err error)
+	SaveTOTPSecret(ctx context.Context, userID, encryptedSecret string, backupCodeHashes []string) error
+	ConfirmTOTP(ctx context.Context, userID string) error
+	ConsumeBackupCode(ctx context.Context, userID, codeHash string) (bool, error)
+	LogTOTPFailure(ctx context.Context, userID, ipAddress string) error
+}
+
+// SetTOTPStore enables TOTP multi-factor authentication for local accounts.
+//
+// encryptionKey is any non-empty secret; it is hashed with SHA-256 to derive
+// the AES-256 key used to encrypt TOTP secrets at rest. When no store is
+// configured, TOTP endpoints return 503 and login does not require a code.
+func (h *AuthHandler) SetTOTPStore(store TOTPStore, encryptionKey string) {
+	key := sha256.Sum256([]byte(encryptionKey))
+	h.totpStore = store
+	h.totpKey = key[:]
+}
+
+// TOTPSetupRequest represents a TOTP setup request. Code is only required
+// when replacing a confirmed enrollment.
+type TOTPSetupRequest struct {
+	Code string `json:"code"`
+}
+
+// TOTPVerifyRequest represents a TOTP enrollment confirmation request
+type TOTPVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPSetup generates a new TOTP secret and backup codes for the current user.
+func (h *AuthHandler) TOTPSetup(c *gin.Context) {
+	if h.totpStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "TOTP authentication is not configured"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userDB.GetUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.Provider != "" && user.Provider != "local" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "TOTP is only available for local accounts; use your identity provider's MFA",
+		})
+		return
+	}
+
+	var req TOTPSetupRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	// Replacing a confirmed enrollment requires the current second factor
+	encrypted, confirmed, err := h.totpStore.GetTOTPSecret(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load TOTP configuration",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err == nil && confirmed {
+		if strings.TrimSpace(req.Code) == "" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "TOTP is already enabled; supply a current TOTP or backup code to replace it",
+			})
+			return
+		}
+		valid, err := h.validateTOTPCode(ctx, userID, encrypted, req.Code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to decrypt TOTP secret",
+				"message": err.Error(),
+			})
+			return
+		}
+		if !valid {
+			if err := h.totpStore.LogTOTPFailure(ctx, userID, c.ClientIP()); err != nil {
+				log.Printf("Warning: Failed to record TOTP failure for user %s: %v", userID, err)
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "TOTP is already enabled and the supplied code is invalid",
+			})
+			return
+		}
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "StreamSpace",
+		AccountName: user.Username,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate TOTP secret",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	encrypted, err = encryptTOTPSecret(h.totpKey, key.Secret())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to encrypt TOTP secret",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	backupCodes := make([]string, 0, totpBackupCodeCount)
+	backupHashes := make([]string, 0, totpBackupCodeCount)
+	for i := 0; i < totpBackupCodeCount; i++ {
+		code, err := generateBackupCode()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate backup codes",
+				"message": err.Error(),
+			})
+			return
+		}
+		backupCodes = append(backupCodes, code)
+		backupHashes = append(backupHashes, hashBackupCode(code))
+	}
+
+	if err := h.totpStore.SaveTOTPSecret(ctx, userID, encrypted, backupHashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to save TOTP secret",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Render QR code as PNG data URL for the frontend
+	qrCode := ""
+	if img, err := key.Image(200, 200); err == nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err == nil {
+			qrCode = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      key.Secret(),
+		"otpauthUrl":  key.URL(),
+		"qrCode":      qrCode,
+		"backupCodes": backupCodes,
+		"message":     "Scan the QR code and verify a code to enable TOTP. Store the backup codes securely; they will not be shown again.",
+	})
+}
+
+// TOTPVerify confirms TOTP enrollment with a code from the authenticator app.
+func (h *AuthHandler) TOTPVerify(c *gin.Context) {
+	if h.totpStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "TOTP authentication is not configured"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var req TOTPVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	encrypted, err := h.totpStore.GetPendingTOTPSecret(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TOTP setup has not been started"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load TOTP secret",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	secret, err := decryptTOTPSecret(h.totpKey, encrypted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to decrypt TOTP secret",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !totp.Validate(strings.TrimSpace(req.Code), secret) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TOTP code"})
+		return
+	}
+
+	if err := h.totpStore.ConfirmTOTP(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to confirm TOTP",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "TOTP enabled successfully"})
+}
+
+// checkLoginTOTP enforces the second factor during local login.
+//
+// Returns true if the login may proceed. When it returns false, an error
+// response has already been written to the client.
+func (h *AuthHandler) checkLoginTOTP(c *gin.Context, userID, code string) bool {
+	if h.totpStore == nil {
+		return true
+	}
+
+	ctx := c.Request.Context()
+	encrypted, confirmed, err := h.totpStore.GetTOTPSecret(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !confirmed) {
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load TOTP configuration",
+			"message": err.Error(),
+		})
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":        "TOTP code required",
+			"totpRequired": true,
+		})
+		return false
+	}
+
+	valid, err := h.validateTOTPCode(ctx, userID, encrypted, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to decrypt TOTP secret",
+			"message": err.Error(),
+		})
+		return false
+	}
+	if valid {
+		return true
+	}
+
+	if err := h.totpStore.LogTOTPFailure(ctx, userID, c.ClientIP()); err != nil {
+		log.Printf("Warning: Failed to record TOTP failure for user %s: %v", userID, err)
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":        "Invalid TOTP code",
+		"totpRequired": true,
+	})
+	return false
+}
+
+// validateTOTPCode checks code against the user's confirmed secret, falling
+// back to (and consuming) a single-use backup code.
+func (h *AuthHandler) validateTOTPCode(ctx context.Context, userID, encrypted, code string) (bool, error) {
+	secret, err := decryptTOTPSecret(h.totpKey, encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if totp.Validate(code, secret) {
+		return true, nil
+	}
+
+	if used, err := h.totpStore.ConsumeBackupCode(ctx, userID, hashBackupCode(code)); err == nil && used {
+		log.Printf("[Auth] User %s used a TOTP backup code", userID)
+		return true, nil
+	}
+	return false, nil
+}
+
+// encryptTOTPSecret encrypts a TOTP secret with AES-256-GCM.
+// The output is base64(nonce || ciphertext).
+func encryptTOTPSecret(key []byte, secret string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptTOTPSecret reverses encryptTOTPSecret.
+func decryptTOTPSecret(key []byte, encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted secret: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted secret: too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// generateBackupCode returns a random backup code formatted as xxxx-xxxx.
+func generateBackupCode() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+	return code[:4] + "-" + code[4:], nil
+}
+
+// hashBackupCode returns the SHA-256 hex digest of a normalized backup code.
+func hashBackupCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTOTPStore mocks the TOTP store
+type MockTOTPStore struct {
+	mock.Mock
+}
+
+func (m *MockTOTPStore) GetTOTPSecret(ctx context.Context, userID string) (string, bool, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockTOTPStore) GetPendingTOTPSecret(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTOTPStore) SaveTOTPSecret(ctx context.Context, userID, encryptedSecret string, backupCodeHashes []string) error {
+	args := m.Called(ctx, userID, encryptedSecret, backupCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTOTPStore) ConfirmTOTP(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTOTPStore) ConsumeBackupCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTOTPStore) LogTOTPFailure(ctx context.Context, userID, ipAddress string) error {
+	args := m.Called(ctx, userID, ipAddress)
+	return args.Error(0)
+}
+
+func testTOTPKey() []byte {
+	key := sha256.Sum256([]byte("test-encryption-key"))
+	return key[:]
+}
+
+func TestEncryptDecryptTOTPSecret(t *testing.T) {
+	key := testTOTPKey()
+
+	encrypted, err := encryptTOTPSecret(key, "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "JBSWY3DPEHPK3PXP")
+
+	decrypted, err := decryptTOTPSecret(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", decrypted)
+
+	otherKey := sha256.Sum256([]byte("other-key"))
+	_, err = decryptTOTPSecret(otherKey[:], encrypted)
+	assert.Error(t, err)
+}
+
+func TestHashBackupCode_Normalizes(t *testing.T) {
+	assert.Equal(t, hashBackupCode("ab12-cd34"), hashBackupCode(" AB12CD34 "))
+	assert.NotEqual(t, hashBackupCode("ab12-cd34"), hashBackupCode("ab12-cd35"))
+}
+
+func newTOTPLoginTest(t *testing.T, body string) (*AuthHandler, *MockUserDB, *MockJWTManager, *MockTOTPStore, *httptest.ResponseRecorder, *gin.Context) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockJWT := new(MockJWTManager)
+	mockTOTP := new(MockTOTPStore)
+
+	handler := NewAuthHandler(mockUserDB, mockJWT, nil, nil)
+	handler.SetTOTPStore(mockTOTP, "test-encryption-key")
+
+	user := &models.User{ID: "user-1", Username: "alice", Email: "alice@example.com", Role: "user", Active: true}
+	mockUserDB.On("VerifyPassword", mock.Anything, "alice", "secret123").Return(user, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	return handler, mockUserDB, mockJWT, mockTOTP, w, c
+}
+
+func TestLogin_TOTPNotEnrolled(t *testing.T) {
+	handler, mockUserDB, mockJWT, mockTOTP, w, c := newTOTPLoginTest(t, `{"username":"alice","password":"secret123"}`)
+
+	mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return("", false, sql.ErrNoRows)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "alice", "alice@example.com", "user",
+		[]string{}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLogin_TOTPRequired(t *testing.T) {
+	handler, _, mockJWT, mockTOTP, w, c := newTOTPLoginTest(t, `{"username":"alice","password":"secret123"}`)
+
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return(encrypted, true, nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "totpRequired")
+	mockJWT.AssertNotCalled(t, "GenerateTokenWithContext")
+}
+
+func TestLogin_TOTPValidCode(t *testing.T) {
+	code, err := totp.GenerateCode("JBSWY3DPEHPK3PXP", time.Now())
+	require.NoError(t, err)
+
+	handler, mockUserDB, mockJWT, mockTOTP, w, c := newTOTPLoginTest(t,
+		`{"username":"alice","password":"secret123","totpCode":"`+code+`"}`)
+
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return(encrypted, true, nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "alice", "alice@example.com", "user",
+		[]string{}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLogin_TOTPInvalidCodeIsAudited(t *testing.T) {
+	handler, _, mockJWT, mockTOTP, w, c := newTOTPLoginTest(t,
+		`{"username":"alice","password":"secret123","totpCode":"000000"}`)
+
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return(encrypted, true, nil)
+	mockTOTP.On("ConsumeBackupCode", mock.Anything, "user-1", hashBackupCode("000000")).Return(false, nil)
+	mockTOTP.On("LogTOTPFailure", mock.Anything, "user-1", mock.Anything).Return(nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockTOTP.AssertCalled(t, "LogTOTPFailure", mock.Anything, "user-1", mock.Anything)
+	mockJWT.AssertNotCalled(t, "GenerateTokenWithContext")
+}
+
+func TestLogin_TOTPBackupCode(t *testing.T) {
+	handler, mockUserDB, mockJWT, mockTOTP, w, c := newTOTPLoginTest(t,
+		`{"username":"alice","password":"secret123","totpCode":"ab12-cd34"}`)
+
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return(encrypted, true, nil)
+	mockTOTP.On("ConsumeBackupCode", mock.Anything, "user-1", hashBackupCode("ab12-cd34")).Return(true, nil)
+	mockUserDB.On("GetUserGroups", mock.Anything, "user-1").Return([]string{}, nil)
+	mockJWT.On("GenerateTokenWithContext", mock.Anything, "user-1", "alice", "alice@example.com", "user",
+		[]string{}, mock.Anything, mock.Anything).Return("jwt-token", nil)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func newTOTPSetupTest(t *testing.T, body string) (*AuthHandler, *MockTOTPStore, *httptest.ResponseRecorder, *gin.Context) {
+	gin.SetMode(gin.TestMode)
+
+	mockUserDB := new(MockUserDB)
+	mockTOTP := new(MockTOTPStore)
+
+	handler := NewAuthHandler(mockUserDB, new(MockJWTManager), nil, nil)
+	handler.SetTOTPStore(mockTOTP, "test-encryption-key")
+
+	mockUserDB.On("GetUser", mock.Anything, "user-1").Return(&models.User{
+		ID: "user-1", Username: "alice", Provider: "local", Active: true,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/totp/setup", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", "user-1")
+
+	return handler, mockTOTP, w, c
+}
+
+func TestTOTPSetup_ConfirmedEnrollment(t *testing.T) {
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	code, err := totp.GenerateCode("JBSWY3DPEHPK3PXP", time.Now())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		backupCode bool
+		wantStatus int
+	}{
+		{name: "no code", body: "", wantStatus: http.StatusConflict},
+		{name: "invalid code", body: `{"code":"000000"}`, wantStatus: http.StatusConflict},
+		{name: "current TOTP code", body: `{"code":"` + code + `"}`, wantStatus: http.StatusOK},
+		{name: "backup code", body: `{"code":"ab12-cd34"}`, backupCode: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockTOTP, w, c := newTOTPSetupTest(t, tt.body)
+			mockTOTP.On("GetTOTPSecret", mock.Anything, "user-1").Return(encrypted, true, nil)
+			mockTOTP.On("ConsumeBackupCode", mock.Anything, "user-1", mock.Anything).Return(tt.backupCode, nil)
+			mockTOTP.On("LogTOTPFailure", mock.Anything, "user-1", mock.Anything).Return(nil)
+			mockTOTP.On("SaveTOTPSecret", mock.Anything, "user-1", mock.Anything, mock.Anything).Return(nil)
+
+			handler.TOTPSetup(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				mockTOTP.AssertCalled(t, "SaveTOTPSecret", mock.Anything, "user-1", mock.Anything, mock.Anything)
+			} else {
+				mockTOTP.AssertNotCalled(t, "SaveTOTPSecret", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTOTPVerify_UsesPendingSecret(t *testing.T) {
+	encrypted, err := encryptTOTPSecret(testTOTPKey(), "KRSXG5CTMVRXEZLU")
+	require.NoError(t, err)
+	code, err := totp.GenerateCode("KRSXG5CTMVRXEZLU", time.Now())
+	require.NoError(t, err)
+
+	handler, mockTOTP, w, c := newTOTPSetupTest(t, `{"code":"`+code+`"}`)
+	c.Request.URL.Path = "/auth/totp/verify"
+	mockTOTP.On("GetPendingTOTPSecret", mock.Anything, "user-1").Return(encrypted, nil)
+	mockTOTP.On("ConfirmTOTP", mock.Anything, "user-1").Return(nil)
+
+	handler.TOTPVerify(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockTOTP.AssertCalled(t, "ConfirmTOTP", mock.Anything, "user-1")
+}
@@ -0,0 +1,187 @@
+// Package catalog caches template catalog listings.
+//
+// GET /api/v1/catalog/templates runs a filtered, sorted query plus a count
+// query against catalog_templates on every request, although the catalog
+// only changes when a repository is synced. TemplateCache keeps recent
+// listings in a size-bounded LRU keyed on a hash of the query parameters.
+//
+// Invalidation:
+//   - Entries expire after the cache TTL
+//   - When a repository sync completes, every entry listing one of that
+//     repository's templates is removed (see InvalidateRepository);
+//     templates a sync adds to listings that did not already contain the
+//     repository appear once those listings expire
+//   - Changing a template category removes every entry (see Purge)
+//
+// Configuration:
+//   - CATALOG_CACHE_TTL: how long a listing is served from cache (default 60s)
+//   - CATALOG_CACHE_SIZE: maximum number of cached listings (default 500)
+//
+// Hits and misses are recorded in the default Prometheus registry, scraped
+// via GET /metrics:
+//
+//   - streamspace_catalog_cache_hits_total (counter)
+//   - streamspace_catalog_cache_misses_total (counter)
+//
+// Example usage:
+//
+//	templateCache := catalog.NewTemplateCacheFromEnv()
+//	catalogHandler.SetTemplateCache(templateCache)
+//	syncService.SetCatalogCache(templateCache)
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultCacheTTL is how long a listing is served from cache
+	DefaultCacheTTL = 60 * time.Second
+
+	// DefaultCacheSize is the maximum number of cached listings
+	DefaultCacheSize = 500
+)
+
+var (
+	cacheHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "streamspace_catalog_cache_hits_total",
+		Help: "Total number of catalog template listings served from cache.",
+	})
+
+	cacheMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "streamspace_catalog_cache_misses_total",
+		Help: "Total number of catalog template listings not found in cache.",
+	})
+
+	registerCacheMetricsOnce sync.Once
+)
+
+// TemplateQuery holds the parameters of a catalog template listing.
+type TemplateQuery struct {
+	Search   string
+	Category string
+	Tag      string
+	AppType  string
+	Featured bool
+	Sort     string
+	Page     int
+	Limit    int
+}
+
+// Key returns the cache key of the query.
+func (q TemplateQuery) Key() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%q|%q|%q|%q|%t|%q|%d|%d",
+		q.Search, q.Category, q.Tag, q.AppType, q.Featured, q.Sort, q.Page, q.Limit)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheEntry is one cached listing.
+type cacheEntry struct {
+	value interface{}
+
+	// repositories holds the IDs of the repositories whose templates the
+	// listing contains
+	repositories map[int]struct{}
+}
+
+// TemplateCache is a size-bounded LRU of catalog template listings.
+// It is safe for concurrent use.
+type TemplateCache struct {
+	entries *expirable.LRU[string, *cacheEntry]
+}
+
+// NewTemplateCache creates a cache holding up to size listings for ttl.
+func NewTemplateCache(size int, ttl time.Duration) *TemplateCache {
+	registerCacheMetricsOnce.Do(func() {
+		prometheus.MustRegister(cacheHitsTotal, cacheMissesTotal)
+	})
+
+	return &TemplateCache{
+		entries: expirable.NewLRU[string, *cacheEntry](size, nil, ttl),
+	}
+}
+
+// NewTemplateCacheFromEnv creates a cache configured by CATALOG_CACHE_TTL
+// and CATALOG_CACHE_SIZE, falling back to the defaults for unset or invalid
+// values.
+func NewTemplateCacheFromEnv() *TemplateCache {
+	ttl := DefaultCacheTTL
+	if raw := os.Getenv("CATALOG_CACHE_TTL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			log.Printf("Invalid CATALOG_CACHE_TTL %q, using default %s", raw, DefaultCacheTTL)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	size := DefaultCacheSize
+	if raw := os.Getenv("CATALOG_CACHE_SIZE"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			log.Printf("Invalid CATALOG_CACHE_SIZE %q, using default %d", raw, DefaultCacheSize)
+		} else {
+			size = parsed
+		}
+	}
+
+	return NewTemplateCache(size, ttl)
+}
+
+// Get returns the cached listing for key and records a hit or miss.
+func (c *TemplateCache) Get(key string) (interface{}, bool) {
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		cacheMissesTotal.Inc()
+		return nil, false
+	}
+	cacheHitsTotal.Inc()
+	return entry.value, true
+}
+
+// Add caches a listing containing templates of repositoryIDs. The value is
+// shared between requests and must not be modified afterwards.
+func (c *TemplateCache) Add(key string, value interface{}, repositoryIDs []int) {
+	repositories := make(map[int]struct{}, len(repositoryIDs))
+	for _, id := range repositoryIDs {
+		repositories[id] = struct{}{}
+	}
+	c.entries.Add(key, &cacheEntry{value: value, repositories: repositories})
+}
+
+// InvalidateRepository removes every listing containing templates of the
+// repository and returns how many were removed.
+func (c *TemplateCache) InvalidateRepository(repoID int) int {
+	removed := 0
+	for _, key := range c.entries.Keys() {
+		entry, ok := c.entries.Peek(key)
+		if !ok {
+			continue
+		}
+		if _, ok := entry.repositories[repoID]; ok {
+			c.entries.Remove(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Purge removes every cached listing.
+func (c *TemplateCache) Purge() {
+	c.entries.Purge()
+}
+
+// Len returns the number of cached listings.
+func (c *TemplateCache) Len() int {
+	return c.entries.Len()
+}
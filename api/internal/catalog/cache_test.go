@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateQueryKey(t *testing.T) {
+	base := TemplateQuery{Category: "Browsers", Sort: "popular", Page: 1, Limit: 20}
+
+	assert.Equal(t, base.Key(), base.Key())
+
+	other := base
+	other.Page = 2
+	assert.NotEqual(t, base.Key(), other.Key())
+
+	// Field boundaries are part of the key
+	a := TemplateQuery{Search: "a|b"}
+	b := TemplateQuery{Search: "a", Category: "b"}
+	assert.NotEqual(t, a.Key(), b.Key())
+}
+
+func TestTemplateCache_GetAdd(t *testing.T) {
+	cache := NewTemplateCache(10, time.Minute)
+
+	_, ok := cache.Get("k")
+	assert.False(t, ok)
+
+	cache.Add("k", "listing", []int{1})
+	value, ok := cache.Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "listing", value)
+}
+
+func TestTemplateCache_SizeBounded(t *testing.T) {
+	cache := NewTemplateCache(2, time.Minute)
+
+	cache.Add("a", 1, nil)
+	cache.Add("b", 2, nil)
+	cache.Get("a")
+	cache.Add("c", 3, nil)
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok := cache.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+}
+
+func TestTemplateCache_Expires(t *testing.T) {
+	cache := NewTemplateCache(10, 20*time.Millisecond)
+
+	cache.Add("k", "listing", nil)
+	time.Sleep(50 * time.Millisecond)
+
+	_, ok := cache.Get("k")
+	assert.False(t, ok)
+}
+
+func TestTemplateCache_InvalidateRepository(t *testing.T) {
+	cache := NewTemplateCache(10, time.Minute)
+
+	cache.Add("repo1", "a", []int{1})
+	cache.Add("mixed", "b", []int{1, 2})
+	cache.Add("repo2", "c", []int{2})
+
+	assert.Equal(t, 2, cache.InvalidateRepository(1))
+	assert.Equal(t, 1, cache.Len())
+	_, ok := cache.Get("repo2")
+	assert.True(t, ok)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfig_WithDefaults(t *testing.T) {
+	pool := PoolConfig{}.withDefaults()
+	assert.Equal(t, DefaultMaxOpenConns, pool.MaxOpenConns)
+	assert.Equal(t, DefaultMaxIdleConns, pool.MaxIdleConns)
+	assert.Equal(t, DefaultConnMaxLifetime, pool.ConnMaxLifetime)
+	assert.Equal(t, DefaultConnMaxIdleTime, pool.ConnMaxIdleTime)
+
+	pool = PoolConfig{MaxOpenConns: 3, MaxIdleConns: 10}.withDefaults()
+	assert.Equal(t, 3, pool.MaxOpenConns)
+	assert.Equal(t, 3, pool.MaxIdleConns, "idle connections are capped at MaxOpenConns")
+}
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN_CONNS", "50")
+	t.Setenv("DB_MAX_IDLE_CONNS", "10")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "10m")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME", "bogus")
+
+	pool := PoolConfigFromEnv()
+	assert.Equal(t, 50, pool.MaxOpenConns)
+	assert.Equal(t, 10, pool.MaxIdleConns)
+	assert.Equal(t, 10*time.Minute, pool.ConnMaxLifetime)
+	assert.Equal(t, time.Duration(0), pool.ConnMaxIdleTime)
+}
+
+func TestHealthCheck(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	database := NewDatabaseFromDB(mockDB)
+
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	assert.NoError(t, database.HealthCheck(context.Background()))
+
+	mock.ExpectQuery("SELECT 1").WillReturnError(errors.New("connection refused"))
+	err = database.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database health check failed")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
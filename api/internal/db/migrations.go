@@ -0,0 +1,275 @@
+// Package db provides PostgreSQL database access and management for StreamSpace.
+//
+// This file implements the versioned schema migration system.
+//
+// Purpose:
+//   - Apply numbered SQL migrations exactly once, in order
+//   - Record applied migrations so startup only runs pending ones
+//   - Roll back the most recent migrations with MigrateDown
+//   - Detect migration files edited after they were applied
+//
+// Migration Files:
+//
+// Migrations live in the migrations/ directory and are embedded in the
+// binary. Each migration is a pair of files sharing a four-digit version:
+//
+//	migrations/0002_totp.sql       (up: applied by Migrate)
+//	migrations/0002_totp.down.sql  (down: applied by MigrateDown)
+//
+// To change the schema, add a new pair with the next version number. Never
+// edit a migration that has been released: Migrate refuses to start if the
+// checksum of an applied migration no longer matches its file.
+//
+// Database Schema:
+//
+//   - schema_migrations table: One row per applied migration
+//
+//   - version (int): Migration version (the file's numeric prefix)
+//
+//   - applied_at (timestamp): When the migration was applied
+//
+//   - checksum (text): SHA-256 hex digest of the up file when applied
+//
+// Each migration runs in its own transaction together with its
+// schema_migrations row, so a failed migration leaves no partial record.
+package db
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFS embeds the numbered schema migrations.
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationFileRegex matches migration file names such as 0001_initial.sql
+// and 0001_initial.down.sql.
+var migrationFileRegex = regexp.MustCompile(`^(\d{4})_([a-z0-9_]+?)(\.down)?\.sql$`)
+
+// migration is one versioned schema change.
+type migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// loadMigrations reads the migration pairs in dir, sorted by version.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "" {
+			sum := sha256.Sum256(content)
+			m.Up = string(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateDown rolls back the most recently applied steps migrations, newest
+// first, using their down files.
+func (d *Database) MigrateDown(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	migrations, err := loadMigrations(migrationFS, "migrations")
+	if err != nil {
+		return err
+	}
+	return d.rollbackMigrations(migrations, steps)
+}
+
+// applyMigrations runs every migration that has not been applied yet.
+func (d *Database) applyMigrations(migrations []migration) error {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := verifyMigrations(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		tx, err := d.db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(m.Up); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)`, m.Version, m.Checksum); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+
+	return nil
+}
+
+// rollbackMigrations runs the down files of the newest steps applied
+// migrations.
+func (d *Database) rollbackMigrations(migrations []migration, steps int) error {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := verifyMigrations(migrations, applied); err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		steps--
+
+		tx, err := d.db.Begin()
+		if err != nil {
+			return fmt.Errorf("rollback of migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(m.Down); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("rollback of migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to unrecord migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("rollback of migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+
+		log.Printf("Rolled back migration %04d_%s", m.Version, m.Name)
+	}
+
+	return nil
+}
+
+// appliedMigrations returns the checksum of every applied migration, keyed
+// by version, creating schema_migrations if needed.
+func (d *Database) appliedMigrations() (map[int]string, error) {
+	if _, err := d.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		checksum TEXT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := d.db.Query(`SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+		}
+		applied[version] = checksum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// verifyMigrations fails if an applied migration is missing from this build
+// or its up file changed since it was applied.
+func verifyMigrations(migrations []migration, applied map[int]string) error {
+	known := make(map[int]migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		m, ok := known[version]
+		if !ok {
+			return fmt.Errorf("applied migration %04d is not known to this build", version)
+		}
+		if applied[version] != m.Checksum {
+			return fmt.Errorf("checksum mismatch for applied migration %04d_%s: file was modified after it was applied", m.Version, m.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+-- Revert the initial schema
+
+-- Tables are dropped in reverse creation order
+DROP TABLE IF EXISTS event_log CASCADE;
+DROP TABLE IF EXISTS platform_controllers CASCADE;
+DROP TABLE IF EXISTS compliance_reports CASCADE;
+DROP TABLE IF EXISTS compliance_violations CASCADE;
+DROP TABLE IF EXISTS compliance_policies CASCADE;
+DROP TABLE IF EXISTS compliance_frameworks CASCADE;
+DROP TABLE IF EXISTS scaling_events CASCADE;
+DROP TABLE IF EXISTS autoscaling_policies CASCADE;
+DROP TABLE IF EXISTS node_status CASCADE;
+DROP TABLE IF EXISTS load_balancing_policies CASCADE;
+DROP TABLE IF EXISTS calendar_events CASCADE;
+DROP TABLE IF EXISTS calendar_integrations CASCADE;
+DROP TABLE IF EXISTS scheduled_sessions CASCADE;
+DROP TABLE IF EXISTS security_alerts CASCADE;
+DROP TABLE IF EXISTS device_posture_checks CASCADE;
+DROP TABLE IF EXISTS session_verifications CASCADE;
+DROP TABLE IF EXISTS ip_whitelist CASCADE;
+DROP TABLE IF EXISTS trusted_devices CASCADE;
+DROP TABLE IF EXISTS backup_codes CASCADE;
+DROP TABLE IF EXISTS mfa_methods CASCADE;
+DROP TABLE IF EXISTS integrations CASCADE;
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
+DROP TABLE IF EXISTS collaboration_annotations CASCADE;
+DROP TABLE IF EXISTS collaboration_chat CASCADE;
+DROP TABLE IF EXISTS collaboration_participants CASCADE;
+DROP TABLE IF EXISTS collaboration_sessions CASCADE;
+DROP TABLE IF EXISTS monitor_configurations CASCADE;
+DROP TABLE IF EXISTS console_file_operations CASCADE;
+DROP TABLE IF EXISTS console_sessions CASCADE;
+DROP TABLE IF EXISTS workflow_executions CASCADE;
+DROP TABLE IF EXISTS workflows CASCADE;
+DROP TABLE IF EXISTS template_tests CASCADE;
+DROP TABLE IF EXISTS template_versions CASCADE;
+DROP TABLE IF EXISTS dlp_violations CASCADE;
+DROP TABLE IF EXISTS dlp_policies CASCADE;
+DROP TABLE IF EXISTS recording_access_log CASCADE;
+DROP TABLE IF EXISTS recording_policies CASCADE;
+DROP TABLE IF EXISTS payment_methods CASCADE;
+DROP TABLE IF EXISTS invoices CASCADE;
+DROP TABLE IF EXISTS quota_policies CASCADE;
+DROP TABLE IF EXISTS resource_quotas CASCADE;
+DROP TABLE IF EXISTS monitoring_alerts CASCADE;
+DROP TABLE IF EXISTS batch_operations CASCADE;
+DROP TABLE IF EXISTS user_session_template_versions CASCADE;
+DROP TABLE IF EXISTS user_session_templates CASCADE;
+DROP TABLE IF EXISTS snapshot_restore_jobs CASCADE;
+DROP TABLE IF EXISTS session_snapshots CASCADE;
+DROP TABLE IF EXISTS search_history CASCADE;
+DROP TABLE IF EXISTS saved_searches CASCADE;
+DROP TABLE IF EXISTS notification_delivery_log CASCADE;
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS user_favorite_templates CASCADE;
+DROP TABLE IF EXISTS user_preferences CASCADE;
+DROP TABLE IF EXISTS api_key_usage_log CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS plugin_stats CASCADE;
+DROP TABLE IF EXISTS plugin_ratings CASCADE;
+DROP TABLE IF EXISTS plugin_versions CASCADE;
+DROP TABLE IF EXISTS installed_plugins CASCADE;
+DROP TABLE IF EXISTS catalog_plugins CASCADE;
+DROP TABLE IF EXISTS session_recordings CASCADE;
+DROP TABLE IF EXISTS session_activity_log CASCADE;
+DROP TABLE IF EXISTS template_shares CASCADE;
+DROP TABLE IF EXISTS session_collaborators CASCADE;
+DROP TABLE IF EXISTS session_share_invitations CASCADE;
+DROP TABLE IF EXISTS session_shares CASCADE;
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP TABLE IF EXISTS configuration CASCADE;
+DROP TABLE IF EXISTS application_group_access CASCADE;
+DROP TABLE IF EXISTS installed_applications CASCADE;
+DROP TABLE IF EXISTS featured_templates CASCADE;
+DROP TABLE IF EXISTS user_template_favorites CASCADE;
+DROP TABLE IF EXISTS template_stats CASCADE;
+DROP TABLE IF EXISTS template_ratings CASCADE;
+DROP TABLE IF EXISTS catalog_template_versions CASCADE;
+DROP TABLE IF EXISTS catalog_templates CASCADE;
+DROP TABLE IF EXISTS repositories CASCADE;
+DROP TABLE IF EXISTS connections CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS team_role_permissions CASCADE;
+DROP TABLE IF EXISTS group_memberships CASCADE;
+DROP TABLE IF EXISTS group_quotas CASCADE;
+DROP TABLE IF EXISTS groups CASCADE;
+DROP TABLE IF EXISTS user_quotas CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_totp_backup_codes;
+DROP TABLE IF EXISTS user_totp_secrets;
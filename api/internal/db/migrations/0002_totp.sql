@@ -0,0 +1,20 @@
+-- TOTP second factor for local accounts (secret is AES-GCM encrypted)
+CREATE TABLE IF NOT EXISTS user_totp_secrets (
+	user_id VARCHAR(255) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	secret_encrypted TEXT NOT NULL,
+	confirmed BOOLEAN DEFAULT false,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	confirmed_at TIMESTAMP
+);
+
+-- Single-use TOTP backup codes (SHA-256 hashed)
+CREATE TABLE IF NOT EXISTS user_totp_backup_codes (
+	id SERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash VARCHAR(64) NOT NULL,
+	used BOOLEAN DEFAULT false,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_totp_backup_codes_user_id ON user_totp_backup_codes(user_id) WHERE used = false;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_catalog_template_versions_template_synced;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS tags;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS icon_url;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS app_type;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS category;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS description;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS display_name;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS synced_at;
+ALTER TABLE catalog_template_versions DROP COLUMN IF EXISTS version_hash;
+ALTER TABLE catalog_templates DROP COLUMN IF EXISTS version_hash;
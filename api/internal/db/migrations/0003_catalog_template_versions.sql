@@ -0,0 +1,20 @@
+-- Catalog template version history (appended on each repository sync)
+ALTER TABLE catalog_templates ADD COLUMN IF NOT EXISTS version_hash VARCHAR(64);
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS version_hash VARCHAR(64);
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS display_name VARCHAR(255);
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS description TEXT;
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS app_type VARCHAR(50);
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS icon_url TEXT;
+
+ALTER TABLE catalog_template_versions ADD COLUMN IF NOT EXISTS tags TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_catalog_template_versions_template_synced ON catalog_template_versions(template_id, synced_at DESC);
@@ -0,0 +1,2 @@
+-- Per-repository webhook secret for X-Hub-Signature-256 verification
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(64);
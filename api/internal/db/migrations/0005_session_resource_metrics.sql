@@ -0,0 +1,11 @@
+-- Per-session resource usage samples (written by the metrics collector)
+CREATE TABLE IF NOT EXISTS session_resource_metrics (
+	id BIGSERIAL PRIMARY KEY,
+	session_id VARCHAR(255) NOT NULL,
+	collected_at TIMESTAMP NOT NULL,
+	cpu_millicores BIGINT NOT NULL DEFAULT 0,
+	memory_bytes BIGINT NOT NULL DEFAULT 0,
+	UNIQUE(session_id, collected_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_resource_metrics_collected_at ON session_resource_metrics(collected_at);
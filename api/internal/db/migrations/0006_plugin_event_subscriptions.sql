@@ -0,0 +1,10 @@
+-- Plugin event subscription audit log
+CREATE TABLE IF NOT EXISTS plugin_event_subscriptions (
+	id SERIAL PRIMARY KEY,
+	plugin_name VARCHAR(255) NOT NULL,
+	event_type VARCHAR(255) NOT NULL,
+	action VARCHAR(20) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_plugin_event_subscriptions_plugin_created ON plugin_event_subscriptions(plugin_name, created_at DESC);
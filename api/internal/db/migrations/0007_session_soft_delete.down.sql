@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sessions_archived_at;
+ALTER TABLE session_resource_metrics DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE connections DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,8 @@
+-- Soft-delete for sessions, cascaded to connections and metrics
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+ALTER TABLE connections ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+ALTER TABLE session_resource_metrics ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_archived_at ON sessions(archived_at) WHERE archived_at IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE session_collaborators DROP COLUMN IF EXISTS invited_at;
+ALTER TABLE session_collaborators DROP COLUMN IF EXISTS invited_by;
+ALTER TABLE session_collaborators DROP COLUMN IF EXISTS role;
@@ -0,0 +1,6 @@
+-- Invited session collaborators (viewer/editor) alongside share-derived ones
+ALTER TABLE session_collaborators ADD COLUMN IF NOT EXISTS role VARCHAR(20);
+
+ALTER TABLE session_collaborators ADD COLUMN IF NOT EXISTS invited_by VARCHAR(255) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE session_collaborators ADD COLUMN IF NOT EXISTS invited_at TIMESTAMP;
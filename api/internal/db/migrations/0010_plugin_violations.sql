@@ -0,0 +1,11 @@
+-- Plugin sandbox violations (event handlers exceeding maxHandlerDuration)
+CREATE TABLE IF NOT EXISTS plugin_violations (
+	id SERIAL PRIMARY KEY,
+	plugin_name VARCHAR(255) NOT NULL,
+	violation_type VARCHAR(50) NOT NULL,
+	event_type VARCHAR(255),
+	limit_ms BIGINT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_plugin_violations_plugin_created ON plugin_violations(plugin_name, created_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_plugin_storage_expires;
+ALTER TABLE plugin_storage DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,15 @@
+-- Plugin key-value storage (see plugins.PluginStorage), with optional expiry
+CREATE TABLE IF NOT EXISTS plugin_storage (
+	plugin_name TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value JSONB NOT NULL,
+	expires_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW(),
+	PRIMARY KEY (plugin_name, key)
+);
+
+-- Tables created lazily by PluginStorage before expiry support
+ALTER TABLE plugin_storage ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_plugin_storage_expires ON plugin_storage(expires_at) WHERE expires_at IS NOT NULL;
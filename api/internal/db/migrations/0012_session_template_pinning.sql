@@ -0,0 +1,2 @@
+-- Catalog template version a session was created from (see SessionDB.PinTemplateVersion)
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS template_version_hash VARCHAR(64);
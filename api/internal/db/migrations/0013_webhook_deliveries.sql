@@ -0,0 +1,17 @@
+-- Delivery attempts of webhook-type plugins (one row per HTTP attempt)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id SERIAL PRIMARY KEY,
+	plugin_name VARCHAR(255) NOT NULL,
+	delivery_id VARCHAR(64) NOT NULL,
+	event_type VARCHAR(255) NOT NULL,
+	url TEXT NOT NULL,
+	attempt INT NOT NULL,
+	status_code INT,
+	success BOOLEAN NOT NULL DEFAULT false,
+	error TEXT,
+	duration_ms BIGINT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_plugin_created ON webhook_deliveries(plugin_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_delivery ON webhook_deliveries(delivery_id);
@@ -0,0 +1 @@
+ALTER TABLE installed_plugins DROP COLUMN IF EXISTS active_api_version;
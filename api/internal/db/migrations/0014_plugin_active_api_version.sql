@@ -0,0 +1,2 @@
+-- API version a plugin's admins declared current (see PATCH /plugins/:id/active-version)
+ALTER TABLE installed_plugins ADD COLUMN IF NOT EXISTS active_api_version VARCHAR(20);
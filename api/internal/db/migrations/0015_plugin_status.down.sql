@@ -0,0 +1,3 @@
+ALTER TABLE installed_plugins DROP COLUMN IF EXISTS status_updated_at;
+ALTER TABLE installed_plugins DROP COLUMN IF EXISTS last_error;
+ALTER TABLE installed_plugins DROP COLUMN IF EXISTS status;
@@ -0,0 +1,4 @@
+-- Runtime status of installed plugins: loaded, failed or degraded (see plugins/health.go)
+ALTER TABLE installed_plugins ADD COLUMN IF NOT EXISTS status VARCHAR(20);
+ALTER TABLE installed_plugins ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE installed_plugins ADD COLUMN IF NOT EXISTS status_updated_at TIMESTAMP;
@@ -0,0 +1,22 @@
+-- Template categories, referenced by name from catalog_templates.category
+CREATE TABLE IF NOT EXISTS template_categories (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) UNIQUE NOT NULL,
+	display_name VARCHAR(255) NOT NULL,
+	icon_url TEXT,
+	description TEXT,
+	order_index INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_categories_order ON template_categories(order_index, name);
+
+-- Seed one category per category already used by catalog templates
+INSERT INTO template_categories (name, display_name, order_index)
+SELECT category, category, ROW_NUMBER() OVER (ORDER BY category)
+FROM (
+	SELECT DISTINCT category FROM catalog_templates
+	WHERE category IS NOT NULL AND category != ''
+) used
+ON CONFLICT (name) DO NOTHING;
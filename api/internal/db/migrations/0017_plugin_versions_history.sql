@@ -0,0 +1,13 @@
+-- Versions installed plugins were upgraded from, with the config they ran with
+CREATE TABLE IF NOT EXISTS plugin_versions_history (
+	id SERIAL PRIMARY KEY,
+	installed_plugin_id INT REFERENCES installed_plugins(id) ON DELETE CASCADE,
+	plugin_name VARCHAR(255) NOT NULL,
+	version VARCHAR(50) NOT NULL,
+	config JSONB,
+	replaced_by_version VARCHAR(50) NOT NULL,
+	upgraded_by VARCHAR(255),
+	upgraded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_plugin_versions_history_plugin ON plugin_versions_history(installed_plugin_id, upgraded_at DESC);
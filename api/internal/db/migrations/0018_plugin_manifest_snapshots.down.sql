@@ -0,0 +1,2 @@
+ALTER TABLE plugin_versions_history DROP COLUMN IF EXISTS manifest;
+ALTER TABLE installed_plugins DROP COLUMN IF EXISTS manifest;
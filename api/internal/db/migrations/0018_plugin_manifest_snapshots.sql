@@ -0,0 +1,4 @@
+-- Manifest each installation runs with, so a rollback can restore the
+-- manifest of the version it returns to after the catalog has moved on
+ALTER TABLE installed_plugins ADD COLUMN IF NOT EXISTS manifest JSONB;
+ALTER TABLE plugin_versions_history ADD COLUMN IF NOT EXISTS manifest JSONB;
@@ -0,0 +1 @@
+ALTER TABLE repositories ALTER COLUMN auth_secret TYPE VARCHAR(255);
@@ -0,0 +1,2 @@
+-- GitHub App credentials and SSH keys do not fit in 255 characters
+ALTER TABLE repositories ALTER COLUMN auth_secret TYPE TEXT;
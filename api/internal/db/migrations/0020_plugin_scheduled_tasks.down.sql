@@ -0,0 +1 @@
+DROP TABLE IF EXISTS plugin_scheduled_tasks;
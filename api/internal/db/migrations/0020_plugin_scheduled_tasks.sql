@@ -0,0 +1,11 @@
+-- Scheduled tasks registered by plugins, with the outcome of their latest run
+CREATE TABLE IF NOT EXISTS plugin_scheduled_tasks (
+	plugin_name VARCHAR(255) NOT NULL,
+	task_name VARCHAR(255) NOT NULL,
+	schedule VARCHAR(255) NOT NULL,
+	last_run_at TIMESTAMP,
+	last_duration_ms BIGINT,
+	last_error TEXT,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (plugin_name, task_name)
+);
@@ -0,0 +1,16 @@
+-- Users who installed each catalog plugin; kept after the plugin is
+-- uninstalled so past installers can still rate it
+CREATE TABLE IF NOT EXISTS plugin_install_history (
+	id SERIAL PRIMARY KEY,
+	catalog_plugin_id INT REFERENCES catalog_plugins(id) ON DELETE CASCADE,
+	plugin_name VARCHAR(255) NOT NULL,
+	user_id VARCHAR(255) REFERENCES users(id) ON DELETE CASCADE,
+	installed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_plugin_install_history_user ON plugin_install_history(catalog_plugin_id, user_id);
+
+INSERT INTO plugin_install_history (catalog_plugin_id, plugin_name, user_id, installed_at)
+SELECT catalog_plugin_id, name, installed_by, installed_at
+FROM installed_plugins
+WHERE catalog_plugin_id IS NOT NULL AND installed_by IS NOT NULL AND installed_by <> '';
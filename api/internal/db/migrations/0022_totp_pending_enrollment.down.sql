@@ -0,0 +1,3 @@
+DELETE FROM user_totp_backup_codes WHERE pending = true;
+ALTER TABLE user_totp_backup_codes DROP COLUMN IF EXISTS pending;
+ALTER TABLE user_totp_secrets DROP COLUMN IF EXISTS pending_secret_encrypted;
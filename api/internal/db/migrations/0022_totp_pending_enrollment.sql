@@ -0,0 +1,4 @@
+-- Re-running TOTP setup on a confirmed enrollment stages the new secret and
+-- backup codes here; the confirmed ones stay active until it is verified
+ALTER TABLE user_totp_secrets ADD COLUMN IF NOT EXISTS pending_secret_encrypted TEXT;
+ALTER TABLE user_totp_backup_codes ADD COLUMN IF NOT EXISTS pending BOOLEAN DEFAULT false;
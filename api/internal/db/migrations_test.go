@@ -0,0 +1,128 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMigrations(t *testing.T) []migration {
+	fsys := fstest.MapFS{
+		"m/0001_initial.sql":      {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_initial.down.sql": {Data: []byte("DROP TABLE a;")},
+		"m/0002_add_b.sql":        {Data: []byte("CREATE TABLE b (id INT);")},
+		"m/0002_add_b.down.sql":   {Data: []byte("DROP TABLE b;")},
+		"m/0010_add_c.sql":        {Data: []byte("CREATE TABLE c (id INT);")},
+		"m/0010_add_c.down.sql":   {Data: []byte("DROP TABLE c;")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	require.NoError(t, err)
+	return migrations
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(migrationFS, "migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions must be contiguous")
+		assert.Len(t, m.Checksum, 64)
+	}
+	assert.Equal(t, "initial", migrations[0].Name)
+}
+
+func TestLoadMigrations_SortsAndRequiresDown(t *testing.T) {
+	migrations := testMigrations(t)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, []int{1, 2, 10}, []int{migrations[0].Version, migrations[1].Version, migrations[2].Version})
+	assert.Equal(t, "DROP TABLE b;", migrations[1].Down)
+
+	_, err := loadMigrations(fstest.MapFS{
+		"m/0001_initial.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}, "m")
+	assert.ErrorContains(t, err, "no down file")
+
+	_, err = loadMigrations(fstest.MapFS{
+		"m/initial.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}, "m")
+	assert.ErrorContains(t, err, "invalid migration file name")
+}
+
+func TestApplyMigrations_RunsOnlyPending(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	migrations := testMigrations(t)
+	database := NewDatabaseFromDB(mockDB)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, checksum FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).AddRow(1, migrations[0].Checksum))
+
+	for _, m := range migrations[1:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(m.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").
+			WithArgs(m.Version, m.Checksum).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	require.NoError(t, database.applyMigrations(migrations))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyMigrations_FailsOnChecksumMismatch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	database := NewDatabaseFromDB(mockDB)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, checksum FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).AddRow(1, "stale"))
+
+	err = database.applyMigrations(testMigrations(t))
+	assert.ErrorContains(t, err, "checksum mismatch for applied migration 0001_initial")
+	assert.NoError(t, mock.ExpectationsWereMet(), "no migration may run after a mismatch")
+}
+
+func TestRollbackMigrations_NewestFirst(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	migrations := testMigrations(t)
+	database := NewDatabaseFromDB(mockDB)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "checksum"})
+	for _, m := range migrations {
+		rows.AddRow(m.Version, m.Checksum)
+	}
+	mock.ExpectQuery("SELECT version, checksum FROM schema_migrations").WillReturnRows(rows)
+
+	for _, m := range []migration{migrations[2], migrations[1]} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(m.Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM schema_migrations").
+			WithArgs(m.Version).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	require.NoError(t, database.rollbackMigrations(migrations, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateDown_RejectsNonPositiveSteps(t *testing.T) {
+	database := NewDatabaseFromDB(nil)
+	assert.Error(t, database.MigrateDown(0))
+}
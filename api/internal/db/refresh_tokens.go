@@ -0,0 +1,87 @@
+// Package db provides PostgreSQL database access and management for StreamSpace.
+//
+// This file implements storage for refresh tokens issued at login.
+//
+// Purpose:
+//   - Persist refresh tokens so a client can obtain a new JWT without
+//     re-entering credentials
+//   - Rotate refresh tokens: each one can be redeemed once
+//   - Revoke refresh tokens on logout
+//
+// Database Schema:
+//
+//   - refresh_tokens table: One row per issued refresh token
+//
+//   - user_id (varchar): References users(id)
+//
+//   - token_hash (varchar): SHA-256 hex digest of the refresh token
+//
+//   - expires_at (timestamp): Token is unusable after this time
+//
+//   - revoked_at (timestamp): Set when redeemed or on logout; revoked tokens are unusable
+//
+// This store never sees plaintext refresh tokens; hashing happens in the
+// auth package.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RefreshTokenDB handles database operations for refresh tokens
+type RefreshTokenDB struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenDB creates a new RefreshTokenDB instance
+func NewRefreshTokenDB(db *sql.DB) *RefreshTokenDB {
+	return &RefreshTokenDB{db: db}
+}
+
+// CreateRefreshToken stores a new refresh token hash for a user.
+func (r *RefreshTokenDB) CreateRefreshToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userID, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeRefreshToken revokes a refresh token and returns the user it was
+// issued to. Revoking and reading happen in one statement, so concurrent
+// requests cannot redeem the same token twice.
+//
+// Returns sql.ErrNoRows if the token is unknown, expired or revoked.
+func (r *RefreshTokenDB) ConsumeRefreshToken(ctx context.Context, tokenHash string) (string, error) {
+	var userID string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+	if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// RevokeRefreshToken marks a refresh token as revoked. Revoking an unknown
+// or already revoked token is not an error.
+func (r *RefreshTokenDB) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
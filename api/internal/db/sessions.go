@@ -2,11 +2,16 @@
 //
 // This file implements session management operations for multi-platform support.
 // Sessions are the source of truth in the database, updated by controller status events.
+//
+// Sessions are soft-deleted: deleting a session sets archived_at instead of
+// removing the row, so an admin can restore it. Every read in this file
+// ignores archived sessions except ListArchivedSessions.
 package db
 
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -37,8 +42,13 @@ type Session struct {
 	LastConnection     *time.Time `json:"last_connection,omitempty"`
 	LastDisconnect     *time.Time `json:"last_disconnect,omitempty"`
 	LastActivity       *time.Time `json:"last_activity,omitempty"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
 }
 
+// ErrSessionNotFound is returned when a session does not exist (or, for
+// RestoreSession, is not archived)
+var ErrSessionNotFound = errors.New("session not found")
+
 // SessionDB handles database operations for sessions.
 type SessionDB struct {
 	db *sql.DB
@@ -50,6 +60,11 @@ func NewSessionDB(db *sql.DB) *SessionDB {
 }
 
 // CreateSession creates a new session in the database.
+//
+// If the ID exists, the state, URL and pod name are updated. If the ID
+// belongs to an archived session, the row is reused for the new session:
+// it is unarchived and every column is overwritten, so the session is not
+// hidden by the archived_at IS NULL filters.
 func (s *SessionDB) CreateSession(ctx context.Context, session *Session) error {
 	if session.ID == "" {
 		session.ID = uuid.New().String()
@@ -71,7 +86,24 @@ func (s *SessionDB) CreateSession(ctx context.Context, session *Session) error {
 			state = EXCLUDED.state,
 			url = EXCLUDED.url,
 			pod_name = EXCLUDED.pod_name,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			user_id = CASE WHEN sessions.archived_at IS NULL THEN sessions.user_id ELSE EXCLUDED.user_id END,
+			team_id = CASE WHEN sessions.archived_at IS NULL THEN sessions.team_id ELSE EXCLUDED.team_id END,
+			template_name = CASE WHEN sessions.archived_at IS NULL THEN sessions.template_name ELSE EXCLUDED.template_name END,
+			app_type = CASE WHEN sessions.archived_at IS NULL THEN sessions.app_type ELSE EXCLUDED.app_type END,
+			active_connections = CASE WHEN sessions.archived_at IS NULL THEN sessions.active_connections ELSE EXCLUDED.active_connections END,
+			namespace = CASE WHEN sessions.archived_at IS NULL THEN sessions.namespace ELSE EXCLUDED.namespace END,
+			platform = CASE WHEN sessions.archived_at IS NULL THEN sessions.platform ELSE EXCLUDED.platform END,
+			memory = CASE WHEN sessions.archived_at IS NULL THEN sessions.memory ELSE EXCLUDED.memory END,
+			cpu = CASE WHEN sessions.archived_at IS NULL THEN sessions.cpu ELSE EXCLUDED.cpu END,
+			persistent_home = CASE WHEN sessions.archived_at IS NULL THEN sessions.persistent_home ELSE EXCLUDED.persistent_home END,
+			idle_timeout = CASE WHEN sessions.archived_at IS NULL THEN sessions.idle_timeout ELSE EXCLUDED.idle_timeout END,
+			max_session_duration = CASE WHEN sessions.archived_at IS NULL THEN sessions.max_session_duration ELSE EXCLUDED.max_session_duration END,
+			created_at = CASE WHEN sessions.archived_at IS NULL THEN sessions.created_at ELSE EXCLUDED.created_at END,
+			last_connection = CASE WHEN sessions.archived_at IS NULL THEN sessions.last_connection ELSE EXCLUDED.last_connection END,
+			last_disconnect = CASE WHEN sessions.archived_at IS NULL THEN sessions.last_disconnect ELSE EXCLUDED.last_disconnect END,
+			last_activity = CASE WHEN sessions.archived_at IS NULL THEN sessions.last_activity ELSE EXCLUDED.last_activity END,
+			archived_at = NULL
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -99,7 +131,7 @@ func (s *SessionDB) GetSession(ctx context.Context, sessionID string) (*Session,
 			COALESCE(idle_timeout, ''), COALESCE(max_session_duration, ''),
 			created_at, updated_at, last_connection, last_disconnect, last_activity
 		FROM sessions
-		WHERE id = $1
+		WHERE id = $1 AND archived_at IS NULL
 	`
 
 	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(
@@ -129,7 +161,7 @@ func (s *SessionDB) ListSessions(ctx context.Context) ([]*Session, error) {
 			COALESCE(idle_timeout, ''), COALESCE(max_session_duration, ''),
 			created_at, updated_at, last_connection, last_disconnect, last_activity
 		FROM sessions
-		WHERE state != 'deleted'
+		WHERE state != 'deleted' AND archived_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -147,7 +179,7 @@ func (s *SessionDB) ListSessionsByUser(ctx context.Context, userID string) ([]*S
 			COALESCE(idle_timeout, ''), COALESCE(max_session_duration, ''),
 			created_at, updated_at, last_connection, last_disconnect, last_activity
 		FROM sessions
-		WHERE user_id = $1 AND state != 'deleted'
+		WHERE user_id = $1 AND state != 'deleted' AND archived_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -164,6 +196,37 @@ func (s *SessionDB) ListSessionsByUser(ctx context.Context, userID string) ([]*S
 	return sessions, nil
 }
 
+// ListCollaboratingSessions retrieves sessions the user was invited to as an
+// active collaborator (sessions they own are not included).
+func (s *SessionDB) ListCollaboratingSessions(ctx context.Context, userID string) ([]*Session, error) {
+	query := `
+		SELECT
+			s.id, s.user_id, COALESCE(s.team_id, ''), s.template_name, s.state, COALESCE(s.app_type, 'desktop'),
+			s.active_connections, COALESCE(s.url, ''), COALESCE(s.namespace, 'streamspace'),
+			COALESCE(s.platform, 'kubernetes'), COALESCE(s.pod_name, ''),
+			COALESCE(s.memory, ''), COALESCE(s.cpu, ''), COALESCE(s.persistent_home, false),
+			COALESCE(s.idle_timeout, ''), COALESCE(s.max_session_duration, ''),
+			s.created_at, s.updated_at, s.last_connection, s.last_disconnect, s.last_activity
+		FROM sessions s
+		JOIN session_collaborators sc ON sc.session_id = s.id
+		WHERE sc.user_id = $1 AND sc.is_active = true AND sc.role IS NOT NULL
+			AND s.user_id != $1 AND s.state != 'deleted' AND s.archived_at IS NULL
+		ORDER BY s.created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collaborating sessions for user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	sessions, err := s.scanSessions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan collaborating sessions for user %s: %w", userID, err)
+	}
+	return sessions, nil
+}
+
 // ListSessionsByState retrieves all sessions with a specific state.
 func (s *SessionDB) ListSessionsByState(ctx context.Context, state string) ([]*Session, error) {
 	query := `
@@ -175,7 +238,7 @@ func (s *SessionDB) ListSessionsByState(ctx context.Context, state string) ([]*S
 			COALESCE(idle_timeout, ''), COALESCE(max_session_duration, ''),
 			created_at, updated_at, last_connection, last_disconnect, last_activity
 		FROM sessions
-		WHERE state = $1
+		WHERE state = $1 AND archived_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -280,22 +343,190 @@ func (s *SessionDB) UpdateActiveConnections(ctx context.Context, sessionID strin
 	return nil
 }
 
-// DeleteSession marks a session as deleted.
+// DeleteSession soft-deletes a session.
+//
+// It is ArchiveSession without the not-found error, so deleting an unknown
+// or already archived session is a no-op.
 func (s *SessionDB) DeleteSession(ctx context.Context, sessionID string) error {
-	query := `
+	if err := s.ArchiveSession(ctx, sessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ArchiveSession soft-deletes a session.
+//
+// In one transaction it sets archived_at on the session, its connections
+// and its resource metrics, and marks its snapshots as deleted. Returns
+// ErrSessionNotFound if the session does not exist or is already archived.
+func (s *SessionDB) ArchiveSession(ctx context.Context, sessionID string) error {
+	return s.archiveSession(ctx, sessionID, "", "")
+}
+
+// ArchiveUserSession soft-deletes a session owned by userID.
+//
+// Behaves like ArchiveSession but returns ErrSessionNotFound when the
+// session belongs to another user.
+func (s *SessionDB) ArchiveUserSession(ctx context.Context, sessionID, userID string) error {
+	return s.archiveSession(ctx, sessionID, userID, "")
+}
+
+// TerminateSession archives a session and sets its state to "terminated",
+// for sessions whose pod was killed outside the controller's stop flow.
+//
+// Returns ErrSessionNotFound if the session does not exist or is already
+// archived.
+func (s *SessionDB) TerminateSession(ctx context.Context, sessionID string) error {
+	return s.archiveSession(ctx, sessionID, "", "terminated")
+}
+
+// archiveSession archives a session and cascades to its dependent rows.
+// An empty userID skips the ownership check; a non-empty state is set on
+// the session as well.
+func (s *SessionDB) archiveSession(ctx context.Context, sessionID, userID, state string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if we don't commit
+
+	result, err := tx.ExecContext(ctx, `
 		UPDATE sessions
-		SET state = 'deleted', updated_at = $1
-		WHERE id = $2
-	`
+		SET archived_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND archived_at IS NULL AND ($2 = '' OR user_id = $2)
+	`, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to archive session %s: %w", sessionID, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
 
-	_, err := s.db.ExecContext(ctx, query, time.Now(), sessionID)
+	if state != "" {
+		if _, err := tx.ExecContext(ctx, `UPDATE sessions SET state = $1 WHERE id = $2`, state, sessionID); err != nil {
+			return fmt.Errorf("failed to set state of session %s: %w", sessionID, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE connections SET archived_at = NOW()
+		WHERE session_id = $1 AND archived_at IS NULL
+	`, sessionID); err != nil {
+		return fmt.Errorf("failed to archive connections of session %s: %w", sessionID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE session_snapshots SET status = 'deleted', updated_at = NOW()
+		WHERE session_id = $1 AND status != 'deleted'
+	`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete snapshots of session %s: %w", sessionID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE session_resource_metrics SET archived_at = NOW()
+		WHERE session_id = $1 AND archived_at IS NULL
+	`, sessionID); err != nil {
+		return fmt.Errorf("failed to archive metrics of session %s: %w", sessionID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RestoreSession clears archived_at on an archived session, its
+// connections and its resource metrics.
+//
+// Snapshots marked deleted during archival stay deleted, since their
+// storage may already have been reclaimed. Returns ErrSessionNotFound if
+// no archived session with this ID exists.
+func (s *SessionDB) RestoreSession(ctx context.Context, sessionID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to mark session %s as deleted: %w", sessionID, err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if we don't commit
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET archived_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND archived_at IS NOT NULL
+	`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to restore session %s: %w", sessionID, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE connections SET archived_at = NULL WHERE session_id = $1
+	`, sessionID); err != nil {
+		return fmt.Errorf("failed to restore connections of session %s: %w", sessionID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE session_resource_metrics SET archived_at = NULL WHERE session_id = $1
+	`, sessionID); err != nil {
+		return fmt.Errorf("failed to restore metrics of session %s: %w", sessionID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	return nil
 }
 
+// ListArchivedSessions retrieves archived sessions, most recently archived first.
+func (s *SessionDB) ListArchivedSessions(ctx context.Context) ([]*Session, error) {
+	query := `
+		SELECT
+			id, user_id, COALESCE(team_id, ''), template_name, state, COALESCE(app_type, 'desktop'),
+			active_connections, COALESCE(url, ''), COALESCE(namespace, 'streamspace'),
+			COALESCE(platform, 'kubernetes'), COALESCE(pod_name, ''),
+			COALESCE(memory, ''), COALESCE(cpu, ''), COALESCE(persistent_home, false),
+			COALESCE(idle_timeout, ''), COALESCE(max_session_duration, ''),
+			created_at, updated_at, last_connection, last_disconnect, last_activity,
+			archived_at
+		FROM sessions
+		WHERE archived_at IS NOT NULL
+		ORDER BY archived_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		session := &Session{}
+		err := rows.Scan(
+			&session.ID, &session.UserID, &session.TeamID, &session.TemplateName, &session.State, &session.AppType,
+			&session.ActiveConnections, &session.URL, &session.Namespace, &session.Platform, &session.PodName,
+			&session.Memory, &session.CPU, &session.PersistentHome, &session.IdleTimeout, &session.MaxSessionDuration,
+			&session.CreatedAt, &session.UpdatedAt, &session.LastConnection, &session.LastDisconnect, &session.LastActivity,
+			&session.ArchivedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archived session row: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archived session rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
 // HardDeleteSession permanently removes a session from the database.
+//
+// Unlike DeleteSession this cannot be undone; use it only to purge
+// sessions that have already been archived.
 func (s *SessionDB) HardDeleteSession(ctx context.Context, sessionID string) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE id = $1", sessionID)
 	if err != nil {
@@ -309,7 +540,7 @@ func (s *SessionDB) CountSessionsByUser(ctx context.Context, userID string) (int
 	var count int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'pending', 'hibernated')
+		WHERE user_id = $1 AND state IN ('running', 'pending', 'hibernated') AND archived_at IS NULL
 	`, userID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count sessions for user %s: %w", userID, err)
@@ -329,6 +560,7 @@ func (s *SessionDB) GetIdleSessions(ctx context.Context) ([]*Session, error) {
 			created_at, updated_at, last_connection, last_disconnect, last_activity
 		FROM sessions
 		WHERE state = 'running'
+			AND archived_at IS NULL
 			AND idle_timeout != ''
 			AND last_activity IS NOT NULL
 			AND last_activity < NOW() - (idle_timeout || ' seconds')::INTERVAL
@@ -378,6 +610,67 @@ func (s *SessionDB) scanSessions(rows *sql.Rows) ([]*Session, error) {
 	return sessions, nil
 }
 
+// TemplateDrift compares the catalog template version a session is pinned
+// to with the version currently in the catalog.
+type TemplateDrift struct {
+	Drifted     bool   `json:"drifted"`
+	PinnedHash  string `json:"pinnedHash"`
+	CurrentHash string `json:"currentHash"`
+}
+
+// currentTemplateHash selects the version_hash of the catalog template a
+// session was created from. Template names are only unique per repository,
+// so the most recently synced template of that name wins.
+const currentTemplateHash = `
+	SELECT ct.version_hash FROM catalog_templates ct
+	WHERE ct.name = s.template_name
+	ORDER BY ct.updated_at DESC
+	LIMIT 1
+`
+
+// PinTemplateVersion pins a session to the current version_hash of its
+// catalog template and returns the pinned hash ("" when the template is not
+// in the catalog).
+func (s *SessionDB) PinTemplateVersion(ctx context.Context, sessionID string) (string, error) {
+	var hash sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE sessions s SET template_version_hash = (`+currentTemplateHash+`), updated_at = NOW()
+		WHERE s.id = $1 AND s.archived_at IS NULL
+		RETURNING s.template_version_hash
+	`, sessionID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", ErrSessionNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to pin template version for session %s: %w", sessionID, err)
+	}
+	return hash.String, nil
+}
+
+// GetTemplateDrift reports whether a session's catalog template changed
+// since the session was pinned. Unpinned sessions, and sessions whose
+// template is no longer in the catalog, never report drift.
+func (s *SessionDB) GetTemplateDrift(ctx context.Context, sessionID string) (*TemplateDrift, error) {
+	var pinned, current sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT s.template_version_hash, (`+currentTemplateHash+`)
+		FROM sessions s
+		WHERE s.id = $1 AND s.archived_at IS NULL
+	`, sessionID).Scan(&pinned, &current)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check template drift for session %s: %w", sessionID, err)
+	}
+
+	return &TemplateDrift{
+		Drifted:     pinned.String != "" && current.String != "" && pinned.String != current.String,
+		PinnedHash:  pinned.String,
+		CurrentHash: current.String,
+	}, nil
+}
+
 // nullString returns a sql.NullString for empty strings.
 func nullString(s string) sql.NullString {
 	if s == "" {
@@ -45,6 +45,26 @@ func TestCreateSession_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateSession_UnarchivesReusedID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	// An archived row with the same ID is taken over by the new session;
+	// otherwise every query filtering on archived_at IS NULL would hide it
+	mock.ExpectExec(`ON CONFLICT \(id\) DO UPDATE SET .*` +
+		`user_id = CASE WHEN sessions.archived_at IS NULL THEN sessions.user_id ELSE EXCLUDED.user_id END, .*` +
+		`archived_at = NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = sessionDB.CreateSession(context.Background(), &Session{ID: "alice-firefox", UserID: "alice", TemplateName: "firefox"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetSession_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -160,10 +180,21 @@ func TestDeleteSession_Success(t *testing.T) {
 
 	sessionID := "session123"
 
-	// DeleteSession uses UPDATE to set state='deleted', not DELETE
+	// DeleteSession archives the session and its dependent rows, it never DELETEs
+	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE sessions").
-		WithArgs(sqlmock.AnyArg(), sessionID).
+		WithArgs(sessionID, "").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE connections SET archived_at").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE session_snapshots SET status = 'deleted'").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE session_resource_metrics SET archived_at").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
 
 	err = sessionDB.DeleteSession(ctx, sessionID)
 
@@ -179,10 +210,11 @@ func TestDeleteSession_NotFound(t *testing.T) {
 	sessionDB := NewSessionDB(db)
 	ctx := context.Background()
 
-	// DeleteSession doesn't check rows affected, it just executes
+	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE sessions").
-		WithArgs(sqlmock.AnyArg(), "nonexistent").
+		WithArgs("nonexistent", "").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
 
 	err = sessionDB.DeleteSession(ctx, "nonexistent")
 
@@ -191,6 +223,100 @@ func TestDeleteSession_NotFound(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestArchiveUserSession_NotOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions").
+		WithArgs("session123", "other-user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = sessionDB.ArchiveUserSession(context.Background(), "session123", "other-user")
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTerminateSession_SetsState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions").
+		WithArgs("session123", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sessions SET state").
+		WithArgs("terminated", "session123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE connections SET archived_at").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_snapshots SET status = 'deleted'").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_resource_metrics SET archived_at").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = sessionDB.TerminateSession(context.Background(), "session123")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreSession_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE connections SET archived_at = NULL").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_resource_metrics SET archived_at = NULL").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = sessionDB.RestoreSession(context.Background(), "session123")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreSession_NotArchived(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions").
+		WithArgs("session123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = sessionDB.RestoreSession(context.Background(), "session123")
+
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCountUserSessions_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -214,3 +340,53 @@ func TestCountUserSessions_Success(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetTemplateDrift(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT s.template_version_hash").
+		WithArgs("session123").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned", "current"}).AddRow("aaaa", "bbbb"))
+	mock.ExpectQuery("SELECT s.template_version_hash").
+		WithArgs("session456").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned", "current"}).AddRow(nil, "bbbb"))
+	mock.ExpectQuery("SELECT s.template_version_hash").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	drift, err := sessionDB.GetTemplateDrift(ctx, "session123")
+	require.NoError(t, err)
+	assert.Equal(t, &TemplateDrift{Drifted: true, PinnedHash: "aaaa", CurrentHash: "bbbb"}, drift)
+
+	// Unpinned sessions never drift
+	drift, err = sessionDB.GetTemplateDrift(ctx, "session456")
+	require.NoError(t, err)
+	assert.False(t, drift.Drifted)
+
+	_, err = sessionDB.GetTemplateDrift(ctx, "missing")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPinTemplateVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionDB := NewSessionDB(db)
+
+	mock.ExpectQuery("UPDATE sessions s SET template_version_hash").
+		WithArgs("session123").
+		WillReturnRows(sqlmock.NewRows([]string{"template_version_hash"}).AddRow("bbbb"))
+
+	hash, err := sessionDB.PinTemplateVersion(context.Background(), "session123")
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb", hash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,227 @@
+// Package db provides PostgreSQL database access and management for StreamSpace.
+//
+// This file implements storage for TOTP-based multi-factor authentication
+// of local accounts.
+//
+// Purpose:
+// - Persist each user's TOTP secret (encrypted by the caller)
+// - Track whether TOTP enrollment has been confirmed
+// - Store and consume single-use backup codes (hashed by the caller)
+// - Record failed TOTP attempts in the audit log
+//
+// Database Schema:
+//
+//   - user_totp_secrets table: One row per enrolled user
+//
+//   - user_id (varchar): Primary key, references users(id)
+//
+//   - secret_encrypted (text): AES-GCM encrypted base32 TOTP secret
+//
+//   - confirmed (boolean): True once the user verified a code
+//
+//   - pending_secret_encrypted (text): Replacement secret awaiting verification
+//
+//   - user_totp_backup_codes table: Recovery codes
+//
+//   - code_hash (varchar): SHA-256 hex digest of the backup code
+//
+//   - used (boolean): Backup codes are single-use
+//
+//   - pending (boolean): Belongs to the replacement secret awaiting verification
+//
+// This store never sees plaintext secrets or backup codes; encryption and
+// hashing happen in the auth package.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// TOTPDB handles database operations for TOTP enrollment
+type TOTPDB struct {
+	db *sql.DB
+}
+
+// NewTOTPDB creates a new TOTPDB instance
+func NewTOTPDB(db *sql.DB) *TOTPDB {
+	return &TOTPDB{db: db}
+}
+
+// GetTOTPSecret returns the encrypted TOTP secret for a user and whether
+// enrollment has been confirmed.
+//
+// Returns sql.ErrNoRows if the user has never started TOTP setup.
+func (t *TOTPDB) GetTOTPSecret(ctx context.Context, userID string) (string, bool, error) {
+	var secret string
+	var confirmed bool
+	err := t.db.QueryRowContext(ctx, `
+		SELECT secret_encrypted, confirmed
+		FROM user_totp_secrets
+		WHERE user_id = $1
+	`, userID).Scan(&secret, &confirmed)
+	if err != nil {
+		return "", false, err
+	}
+	return secret, confirmed, nil
+}
+
+// GetPendingTOTPSecret returns the encrypted TOTP secret awaiting
+// verification: the replacement secret of a confirmed enrollment, or the
+// secret of an enrollment that was never confirmed.
+//
+// Returns sql.ErrNoRows if no secret is awaiting verification.
+func (t *TOTPDB) GetPendingTOTPSecret(ctx context.Context, userID string) (string, error) {
+	var secret string
+	err := t.db.QueryRowContext(ctx, `
+		SELECT COALESCE(pending_secret_encrypted, secret_encrypted)
+		FROM user_totp_secrets
+		WHERE user_id = $1 AND (pending_secret_encrypted IS NOT NULL OR confirmed = false)
+	`, userID).Scan(&secret)
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// SaveTOTPSecret stores a new TOTP secret and backup codes for a user.
+//
+// If the user has no confirmed enrollment, they replace any previous one.
+// Otherwise they are staged as pending and the confirmed secret and backup
+// codes stay active until ConfirmTOTP, so starting setup again cannot turn
+// off the second factor. The secret and backup code hashes are written in a
+// single transaction so a user never ends up with codes from one enrollment
+// and a secret from another.
+func (t *TOTPDB) SaveTOTPSecret(ctx context.Context, userID, encryptedSecret string, backupCodeHashes []string) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var confirmed bool
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_totp_secrets (user_id, secret_encrypted, confirmed, created_at, confirmed_at)
+		VALUES ($1, $2, false, CURRENT_TIMESTAMP, NULL)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = CASE WHEN user_totp_secrets.confirmed
+		        THEN user_totp_secrets.secret_encrypted ELSE EXCLUDED.secret_encrypted END,
+		    pending_secret_encrypted = CASE WHEN user_totp_secrets.confirmed
+		        THEN EXCLUDED.secret_encrypted END,
+		    created_at = CASE WHEN user_totp_secrets.confirmed
+		        THEN user_totp_secrets.created_at ELSE CURRENT_TIMESTAMP END
+		RETURNING confirmed
+	`, userID, encryptedSecret).Scan(&confirmed)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	// Only replace the codes of the enrollment being set up
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM user_totp_backup_codes WHERE user_id = $1 AND (pending = true OR $2 = false)
+	`, userID, confirmed); err != nil {
+		return fmt.Errorf("failed to clear backup codes: %w", err)
+	}
+
+	for _, hash := range backupCodeHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_totp_backup_codes (user_id, code_hash, pending)
+			VALUES ($1, $2, $3)
+		`, userID, hash, confirmed); err != nil {
+			return fmt.Errorf("failed to save backup code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ConfirmTOTP marks a user's TOTP enrollment as confirmed. A pending
+// replacement secret and its backup codes take the place of the old ones.
+func (t *TOTPDB) ConfirmTOTP(ctx context.Context, userID string) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var replaced bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT pending_secret_encrypted IS NOT NULL
+		FROM user_totp_secrets
+		WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(&replaced)
+	if err != nil {
+		return err
+	}
+
+	if replaced {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM user_totp_backup_codes WHERE user_id = $1 AND pending = false
+		`, userID); err != nil {
+			return fmt.Errorf("failed to clear backup codes: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE user_totp_backup_codes SET pending = false WHERE user_id = $1
+		`, userID); err != nil {
+			return fmt.Errorf("failed to activate backup codes: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_totp_secrets
+		SET secret_encrypted = COALESCE(pending_secret_encrypted, secret_encrypted),
+		    pending_secret_encrypted = NULL,
+		    confirmed = true,
+		    confirmed_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+	`, userID); err != nil {
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeBackupCode marks an unused backup code as used.
+//
+// Returns true if a matching unused code was found. The UPDATE is atomic, so
+// concurrent logins cannot both redeem the same code.
+func (t *TOTPDB) ConsumeBackupCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result, err := t.db.ExecContext(ctx, `
+		UPDATE user_totp_backup_codes
+		SET used = true, used_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM user_totp_backup_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used = false AND pending = false
+			LIMIT 1
+		)
+	`, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume backup code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to consume backup code: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// LogTOTPFailure records a failed TOTP attempt in the audit log with
+// action "totp.failed".
+func (t *TOTPDB) LogTOTPFailure(ctx context.Context, userID, ipAddress string) error {
+	changes, _ := json.Marshal(map[string]interface{}{
+		"reason": "invalid TOTP or backup code after successful password check",
+	})
+
+	_, err := t.db.ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, action, resource_type, resource_id, changes, timestamp, ip_address)
+		VALUES ($1, 'totp.failed', 'user', $1, $2, CURRENT_TIMESTAMP, $3)
+	`, userID, changes, ipAddress)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
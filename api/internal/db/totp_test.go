@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveTOTPSecret_StagesReplacementOfConfirmedEnrollment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO user_totp_secrets .* RETURNING confirmed`).
+		WithArgs("user-1", "new-secret").
+		WillReturnRows(sqlmock.NewRows([]string{"confirmed"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM user_totp_backup_codes WHERE user_id = \$1 AND \(pending = true OR \$2 = false\)`).
+		WithArgs("user-1", true).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO user_totp_backup_codes`).
+		WithArgs("user-1", "hash-1", true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = NewTOTPDB(db).SaveTOTPSecret(context.Background(), "user-1", "new-secret", []string{"hash-1"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmTOTP_ActivatesPendingReplacement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pending_secret_encrypted IS NOT NULL`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"replaced"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM user_totp_backup_codes WHERE user_id = \$1 AND pending = false`).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 8))
+	mock.ExpectExec(`UPDATE user_totp_backup_codes SET pending = false`).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 8))
+	mock.ExpectExec(`UPDATE user_totp_secrets\s+SET secret_encrypted = COALESCE\(pending_secret_encrypted, secret_encrypted\)`).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = NewTOTPDB(db).ConfirmTOTP(context.Background(), "user-1")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -33,7 +33,7 @@
 //
 //   - role (varchar): User role (user, admin, superadmin)
 //
-//   - provider (varchar): Auth provider (local, saml, oidc)
+//   - provider (varchar): Auth provider (local, saml, oidc, ldap)
 //
 //   - active (boolean): Account active status
 //
@@ -108,6 +108,9 @@ import (
 // ErrUserNotFound is returned when a user is not found
 var ErrUserNotFound = fmt.Errorf("user not found")
 
+// ErrInvalidAPIKey is returned when an API key is unknown, revoked or expired
+var ErrInvalidAPIKey = fmt.Errorf("invalid API key")
+
 // UserDB handles database operations for users
 type UserDB struct {
 	db *sql.DB
@@ -470,6 +473,32 @@ func (u *UserDB) VerifyPassword(ctx context.Context, username, password string)
 	return user, nil
 }
 
+// GetUserByAPIKeyHash authenticates an API key by its SHA-256 hash (hex).
+//
+// Only active, unexpired keys match. On success the key's last_used_at and
+// use_count are updated and the owning user is returned. Unknown, revoked
+// and expired keys all return ErrInvalidAPIKey so callers cannot tell them
+// apart.
+func (u *UserDB) GetUserByAPIKeyHash(ctx context.Context, keyHash string) (*models.User, error) {
+	var keyID int
+	var userID string
+	err := u.db.QueryRowContext(ctx, `
+		UPDATE api_keys
+		SET last_used_at = CURRENT_TIMESTAMP, use_count = use_count + 1
+		WHERE key_hash = $1 AND is_active = true
+		  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		RETURNING id, user_id
+	`, keyHash).Scan(&keyID, &userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	return u.GetUser(ctx, userID)
+}
+
 // GetOrCreateSAMLUser gets or creates a user from SAML assertion
 func (u *UserDB) GetOrCreateSAMLUser(ctx context.Context, username, email, fullName, provider string) (*models.User, error) {
 	// Try to find existing user by username
@@ -704,10 +733,10 @@ func (u *UserDB) DeleteUserQuota(ctx context.Context, userID string) error {
 	return u.createDefaultQuota(ctx, userID)
 }
 
-// GetUserGroups retrieves all groups a user belongs to
+// GetUserGroups retrieves the names of all groups a user belongs to
 func (u *UserDB) GetUserGroups(ctx context.Context, userID string) ([]string, error) {
 	query := `
-		SELECT g.id
+		SELECT g.name
 		FROM groups g
 		JOIN group_memberships gm ON g.id = gm.group_id
 		WHERE gm.user_id = $1
@@ -720,16 +749,43 @@ func (u *UserDB) GetUserGroups(ctx context.Context, userID string) ([]string, er
 	}
 	defer rows.Close()
 
-	groupIDs := []string{}
+	groupNames := []string{}
 	for rows.Next() {
-		var groupID string
-		if err := rows.Scan(&groupID); err != nil {
+		var groupName string
+		if err := rows.Scan(&groupName); err != nil {
 			continue
 		}
-		groupIDs = append(groupIDs, groupID)
+		groupNames = append(groupNames, groupName)
+	}
+
+	return groupNames, nil
+}
+
+// GetUserPermissions returns the distinct permissions a user holds through
+// the roles of their group memberships (see team_role_permissions).
+func (u *UserDB) GetUserPermissions(ctx context.Context, userID string) ([]string, error) {
+	rows, err := u.db.QueryContext(ctx, `
+		SELECT DISTINCT trp.permission
+		FROM group_memberships gm
+		JOIN team_role_permissions trp ON trp.role = gm.role
+		WHERE gm.user_id = $1
+		ORDER BY trp.permission ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
 	}
 
-	return groupIDs, nil
+	return permissions, rows.Err()
 }
 
 // Helper function to join strings
@@ -763,3 +819,13 @@ func (u *UserDB) AddUserToGroup(ctx context.Context, userID, groupName string) e
 
 	return err
 }
+
+// RemoveUserFromGroup removes a user from a group by group name. Removing a
+// user who is not a member is not an error.
+func (u *UserDB) RemoveUserFromGroup(ctx context.Context, userID, groupName string) error {
+	_, err := u.db.ExecContext(ctx, `
+		DELETE FROM group_memberships
+		WHERE user_id = $1 AND group_id IN (SELECT id FROM groups WHERE name = $2)
+	`, userID, groupName)
+	return err
+}
@@ -497,3 +497,47 @@ func TestAddUserToGroup_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetUserByAPIKeyHash_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	userDB := NewUserDB(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs("keyhash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(7, "user123"))
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id").
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "full_name", "role", "provider", "active", "created_at", "updated_at", "last_login"}).
+			AddRow("user123", "ci-bot", "ci@example.com", "CI Bot", "operator", "local", true,
+				time.Now(), time.Now(), sql.NullTime{}))
+
+	user, err := userDB.GetUserByAPIKeyHash(ctx, "keyhash")
+
+	require.NoError(t, err)
+	assert.Equal(t, "user123", user.ID)
+	assert.Equal(t, "operator", user.Role)
+}
+
+func TestGetUserByAPIKeyHash_RevokedOrExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	userDB := NewUserDB(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+		WithArgs("keyhash").
+		WillReturnError(sql.ErrNoRows)
+
+	user, err := userDB.GetUserByAPIKeyHash(ctx, "keyhash")
+
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	assert.Nil(t, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -125,6 +125,7 @@ const (
 	ErrCodeDatabaseError       = "DATABASE_ERROR"
 	ErrCodeKubernetesError     = "KUBERNETES_ERROR"
 	ErrCodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	ErrCodePluginError         = "PLUGIN_ERROR"
 )
 
 // New creates a new AppError
@@ -172,7 +173,7 @@ func getStatusCodeForErrorCode(code string) int {
 		return http.StatusTooManyRequests
 	case ErrCodeServiceUnavailable:
 		return http.StatusServiceUnavailable
-	case ErrCodeInternalServer, ErrCodeDatabaseError, ErrCodeKubernetesError:
+	case ErrCodeInternalServer, ErrCodeDatabaseError, ErrCodeKubernetesError, ErrCodePluginError:
 		return http.StatusInternalServerError
 	default:
 		return http.StatusInternalServerError
@@ -266,3 +267,7 @@ func KubernetesError(err error) *AppError {
 func ServiceUnavailable(service string) *AppError {
 	return New(ErrCodeServiceUnavailable, fmt.Sprintf("%s is currently unavailable", service))
 }
+
+func PluginError(pluginName string) *AppError {
+	return New(ErrCodePluginError, fmt.Sprintf("Plugin %s failed to handle the request", pluginName))
+}
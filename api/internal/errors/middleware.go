@@ -24,7 +24,9 @@
 //
 // Implementation Details:
 // - Integrates with Gin's error handling mechanism (c.Errors)
-// - Logs errors using standard library log (consider upgrading to structured logging)
+// - Logs errors with log/slog, using the request-scoped logger when present
+//   (so records carry request_id/user_id), otherwise slog.Default()
+// - Panics are logged at ERROR level with a "stack" field
 // - Preserves error details for debugging
 // - Automatically sets HTTP status codes
 //
@@ -59,12 +61,25 @@
 package errors
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
 )
 
+// requestLogger returns the request-scoped logger set by the structured
+// logger middleware (context key "logger"), or slog.Default().
+func requestLogger(c *gin.Context) *slog.Logger {
+	if value, exists := c.Get("logger"); exists {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
 // ErrorHandler is a middleware that handles errors consistently
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -78,9 +93,12 @@ func ErrorHandler() gin.HandlerFunc {
 			if appErr, ok := err.Err.(*AppError); ok {
 				// Log the error with details
 				if appErr.StatusCode >= 500 {
-					log.Printf("[ERROR] %s - %s (Details: %s)", appErr.Code, appErr.Message, appErr.Details)
+					requestLogger(c).Error(appErr.Message,
+						slog.String("code", appErr.Code),
+						slog.String("details", appErr.Details),
+					)
 				} else {
-					log.Printf("[WARN] %s - %s", appErr.Code, appErr.Message)
+					requestLogger(c).Warn(appErr.Message, slog.String("code", appErr.Code))
 				}
 
 				// Send the error response
@@ -89,7 +107,7 @@ func ErrorHandler() gin.HandlerFunc {
 			}
 
 			// Handle generic errors
-			log.Printf("[ERROR] Unhandled error: %v", err.Err)
+			requestLogger(c).Error("unhandled error", slog.String("error", err.Err.Error()))
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
 				Error:   ErrCodeInternalServer,
 				Message: "An unexpected error occurred",
@@ -104,7 +122,12 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("[PANIC] Recovered from panic: %v", err)
+				requestLogger(c).Error("recovered from panic",
+					slog.String("panic", fmt.Sprint(err)),
+					slog.String("method", c.Request.Method),
+					slog.String("path", c.Request.URL.Path),
+					slog.String("stack", string(debug.Stack())),
+				)
 
 				c.JSON(http.StatusInternalServerError, ErrorResponse{
 					Error:   ErrCodeInternalServer,
@@ -1,7 +1,9 @@
 // Package events provides NATS event publishing and subscribing for StreamSpace.
 //
 // The subscriber handles incoming status events from platform controllers
-// and updates the API database accordingly.
+// and updates the API database accordingly. Session state transitions that
+// plugins care about (hibernated, woken) are forwarded to the plugin runtime
+// when an EventEmitter is set.
 package events
 
 import (
@@ -24,6 +26,21 @@ type Subscriber struct {
 	enabled      bool
 	controllerID string
 	subs         []*nats.Subscription
+	emitter      EventEmitter
+}
+
+// EventEmitter delivers events to plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// events package does not depend on the plugin runtime.
+type EventEmitter interface {
+	EmitEvent(eventType string, data interface{})
+}
+
+// SetEventEmitter sets where session lifecycle events are emitted for plugins.
+// Must be called before Start.
+func (s *Subscriber) SetEventEmitter(emitter EventEmitter) {
+	s.emitter = emitter
 }
 
 // NewSubscriber creates a new NATS event subscriber.
@@ -162,27 +179,56 @@ func (s *Subscriber) handleSessionStatus(data []byte) {
 	defer cancel()
 
 	// Update the session state (using Phase which is the Kubernetes phase like "Running", "Pending"),
-	// URL, and pod_name
+	// URL, and pod_name. The previous state is returned from the locked row so
+	// transitions can be detected without a separate read.
 	query := `
-		UPDATE sessions
+		UPDATE sessions s
 		SET state = $1, url = $2, pod_name = $3, updated_at = $4
-		WHERE id = $5
+		FROM (SELECT id, state FROM sessions WHERE id = $5 FOR UPDATE) prev
+		WHERE s.id = prev.id
+		RETURNING prev.state, s.user_id, s.template_name
 	`
 
 	// Convert Phase to lowercase for state field (running, hibernated, pending, failed)
 	// The UI expects lowercase state values for session lifecycle checks
 	state := strings.ToLower(event.Phase)
-	result, err := s.db.ExecContext(ctx, query, state, event.URL, event.PodName, time.Now(), event.SessionID)
+	var previousState, userID, templateName sql.NullString
+	err := s.db.QueryRowContext(ctx, query, state, event.URL, event.PodName, time.Now(), event.SessionID).
+		Scan(&previousState, &userID, &templateName)
+	if err == sql.ErrNoRows {
+		log.Printf("Session %s not found in database (may not be created yet)", event.SessionID)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to update session %s status: %v", event.SessionID, err)
 		return
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		log.Printf("Session %s not found in database (may not be created yet)", event.SessionID)
-	} else {
-		log.Printf("Updated session %s to state=%s url=%s", event.SessionID, state, event.URL)
+	log.Printf("Updated session %s to state=%s url=%s", event.SessionID, state, event.URL)
+
+	s.emitSessionTransition(&SessionStateChange{
+		SessionID:     event.SessionID,
+		UserID:        userID.String,
+		TemplateName:  templateName.String,
+		PreviousState: previousState.String,
+		State:         state,
+		Timestamp:     time.Now(),
+	})
+}
+
+// emitSessionTransition emits session.hibernated when a session enters the
+// hibernated state and session.woken when it leaves hibernation for running.
+// Repeated status events for an unchanged state emit nothing.
+func (s *Subscriber) emitSessionTransition(change *SessionStateChange) {
+	if s.emitter == nil || change.PreviousState == change.State {
+		return
+	}
+
+	switch {
+	case change.State == StatusHibernated:
+		s.emitter.EmitEvent(PluginEventSessionHibernated, change)
+	case change.State == StatusRunning && change.PreviousState == StatusHibernated:
+		s.emitter.EmitEvent(PluginEventSessionWoken, change)
 	}
 }
 
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []string
+	data   []interface{}
+}
+
+func (r *recordingEmitter) EmitEvent(eventType string, data interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, eventType)
+	r.data = append(r.data, data)
+}
+
+func runSessionStatus(t *testing.T, previousState, phase string) *recordingEmitter {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	emitter := &recordingEmitter{}
+	s := &Subscriber{db: mockDB}
+	s.SetEventEmitter(emitter)
+
+	mock.ExpectQuery("UPDATE sessions s").
+		WithArgs(sqlmock.AnyArg(), "", "", sqlmock.AnyArg(), "sess-1").
+		WillReturnRows(sqlmock.NewRows([]string{"state", "user_id", "template_name"}).
+			AddRow(previousState, "user1", "firefox"))
+
+	data, err := json.Marshal(SessionStatusEvent{SessionID: "sess-1", Phase: phase})
+	require.NoError(t, err)
+	s.handleSessionStatus(data)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	return emitter
+}
+
+func TestHandleSessionStatus_EmitsHibernated(t *testing.T) {
+	emitter := runSessionStatus(t, StatusRunning, "Hibernated")
+
+	require.Equal(t, []string{PluginEventSessionHibernated}, emitter.events)
+	change := emitter.data[0].(*SessionStateChange)
+	assert.Equal(t, "sess-1", change.SessionID)
+	assert.Equal(t, "user1", change.UserID)
+	assert.Equal(t, StatusRunning, change.PreviousState)
+	assert.Equal(t, StatusHibernated, change.State)
+}
+
+func TestHandleSessionStatus_EmitsWoken(t *testing.T) {
+	emitter := runSessionStatus(t, StatusHibernated, "Running")
+
+	assert.Equal(t, []string{PluginEventSessionWoken}, emitter.events)
+}
+
+func TestHandleSessionStatus_NoEventWithoutTransition(t *testing.T) {
+	assert.Empty(t, runSessionStatus(t, StatusRunning, "Running").events)
+	assert.Empty(t, runSessionStatus(t, StatusPending, "Running").events)
+}
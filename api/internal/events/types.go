@@ -213,3 +213,55 @@ const (
 	InstallStatusReady      = "ready"
 	InstallStatusFailed     = "failed"
 )
+
+// Plugin event types emitted on the plugin EventBus.
+//
+// Plugins subscribe to these names (ctx.Events.On(events.PluginEventSessionHibernated, ...))
+// instead of raw string literals. They are distinct from the NATS subjects
+// in subjects.go, which carry commands between the API and controllers.
+const (
+	PluginEventSessionCreated    = "session.created"
+	PluginEventSessionStarted    = "session.started"
+	PluginEventSessionStopped    = "session.stopped"
+	PluginEventSessionHibernated = "session.hibernated"
+	PluginEventSessionWoken      = "session.woken"
+	PluginEventSessionDeleted    = "session.deleted"
+	PluginEventUserCreated       = "user.created"
+	PluginEventUserUpdated       = "user.updated"
+	PluginEventUserDeleted       = "user.deleted"
+	PluginEventUserLogin         = "user.login"
+	PluginEventUserLogout        = "user.logout"
+
+	// PluginEventPlatformShutdownInitiated is emitted synchronously when the
+	// API starts a graceful shutdown, before requests are drained.
+	PluginEventPlatformShutdownInitiated = "platform.shutdown.initiated"
+
+	// PluginEventSessionForceKilled is emitted when an admin force-kills a
+	// session's pod (POST /sessions/:id/force-kill).
+	PluginEventSessionForceKilled = "session.force_killed"
+)
+
+// SessionForceKilled is the payload of session.force_killed.
+type SessionForceKilled struct {
+	SessionID string `json:"sessionId"`
+	AdminID   string `json:"adminId"`
+	Reason    string `json:"reason"`
+}
+
+// PlatformShutdownInitiated is the payload of platform.shutdown.initiated.
+type PlatformShutdownInitiated struct {
+	Signal       string    `json:"signal"`
+	DrainTimeout string    `json:"drain_timeout"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// SessionStateChange is the payload of session lifecycle plugin events
+// (session.hibernated, session.woken).
+type SessionStateChange struct {
+	SessionID     string    `json:"session_id"`
+	UserID        string    `json:"user_id"`
+	TemplateName  string    `json:"template_name"`
+	PreviousState string    `json:"previous_state"`
+	State         string    `json:"state"`
+	Timestamp     time.Time `json:"timestamp"`
+}
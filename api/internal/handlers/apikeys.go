@@ -66,9 +66,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/streamspace/streamspace/api/internal/db"
 )
 
@@ -125,11 +127,11 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	ctx := context.Background()
 
 	var req struct {
-		Name        string    `json:"name" binding:"required"`
+		Name        string    `json:"name"`
 		Description string    `json:"description"`
 		Scopes      []string  `json:"scopes"`
 		RateLimit   int       `json:"rateLimit"`
-		ExpiresIn   string    `json:"expiresIn"` // Duration string like "30d", "1y"
+		ExpiresIn   string    `json:"expiresIn"` // Duration string like "30d", "1y" or "36h"
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -161,6 +163,11 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	keyHash := hashAPIKey(apiKey)
 	keyPrefix := apiKey[:8] // Store first 8 chars for identification
 
+	// Default name so unnamed keys are still identifiable in listings
+	if req.Name == "" {
+		req.Name = "API key " + keyPrefix
+	}
+
 	// Default rate limit
 	rateLimit := req.RateLimit
 	if rateLimit == 0 {
@@ -171,10 +178,15 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	var expiresAt *time.Time
 	if req.ExpiresIn != "" {
 		duration, err := parseDuration(req.ExpiresIn)
-		if err == nil {
-			expiry := time.Now().Add(duration)
-			expiresAt = &expiry
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid expiresIn",
+				"message": err.Error(),
+			})
+			return
 		}
+		expiry := time.Now().Add(duration)
+		expiresAt = &expiry
 	}
 
 	// Insert into database
@@ -195,7 +207,7 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 		req.Name,
 		req.Description,
 		userIDStr,
-		req.Scopes,
+		pq.Array(req.Scopes),
 		rateLimit,
 		expiresAt,
 		userIDStr,
@@ -261,7 +273,7 @@ func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
 			&key.Name,
 			&key.Description,
 			&key.UserID,
-			&scopes,
+			pq.Array(&scopes),
 			&key.RateLimit,
 			&key.ExpiresAt,
 			&key.LastUsedAt,
@@ -445,44 +457,37 @@ func (h *APIKeyHandler) GetAPIKeyUsage(c *gin.Context) {
 	})
 }
 
-// parseDuration parses duration strings like "30d", "1y", "6m"
+// parseDuration parses duration strings like "30d", "2w", "6m" (30-day
+// months) and "1y", or any Go duration such as "36h". Durations must be
+// positive.
 func parseDuration(s string) (time.Duration, error) {
-	if len(s) < 2 {
-		return 0, fmt.Errorf("invalid duration format")
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"m": 30 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
 	}
 
-	unit := s[len(s)-1:]
-	value := s[:len(s)-1]
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
 
 	var duration time.Duration
-	switch unit {
-	case "d": // days
-		var days int
-		if _, err := fmt.Sscanf(value, "%d", &days); err != nil {
-			return 0, err
-		}
-		duration = time.Duration(days) * 24 * time.Hour
-	case "w": // weeks
-		var weeks int
-		if _, err := fmt.Sscanf(value, "%d", &weeks); err != nil {
-			return 0, err
-		}
-		duration = time.Duration(weeks) * 7 * 24 * time.Hour
-	case "m": // months (30 days)
-		var months int
-		if _, err := fmt.Sscanf(value, "%d", &months); err != nil {
-			return 0, err
+	if unit, ok := units[s[len(s)-1:]]; ok {
+		count, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
 		}
-		duration = time.Duration(months) * 30 * 24 * time.Hour
-	case "y": // years (365 days)
-		var years int
-		if _, err := fmt.Sscanf(value, "%d", &years); err != nil {
-			return 0, err
+		duration = time.Duration(count) * unit
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a number followed by d, w, m or y, or a Go duration like 36h", s)
 		}
-		duration = time.Duration(years) * 365 * 24 * time.Hour
-	default:
-		return 0, fmt.Errorf("invalid duration unit: %s", unit)
 	}
 
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", s)
+	}
 	return duration, nil
 }
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "6m", want: 180 * 24 * time.Hour},
+		{in: "1y", want: 365 * 24 * time.Hour},
+		{in: "36h", want: 36 * time.Hour},
+		{in: "90m30s", want: 90*time.Minute + 30*time.Second},
+		{in: "bogus", wantErr: true},
+		{in: "7dd", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "0d", wantErr: true},
+		{in: "-36h", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateAPIKey_ExpiresIn(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiresIn  string
+		wantStatus int
+		wantExpiry time.Duration
+	}{
+		{name: "days", expiresIn: "7d", wantStatus: http.StatusCreated, wantExpiry: 7 * 24 * time.Hour},
+		{name: "go duration", expiresIn: "36h", wantStatus: http.StatusCreated, wantExpiry: 36 * time.Hour},
+		{name: "never expires", expiresIn: "", wantStatus: http.StatusCreated},
+		{name: "invalid", expiresIn: "bogus", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, mock, w, c := newHandlerTest(t, http.MethodPost, "/api-keys",
+				`{"name":"ci","expiresIn":"`+tt.expiresIn+`"}`)
+			c.Set("userID", "user1")
+
+			expiresAt := &timeArg{}
+			if tt.wantStatus == http.StatusCreated {
+				mock.ExpectQuery(`INSERT INTO api_keys`).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "ci", "", "user1", sqlmock.AnyArg(), 1000,
+						expiresAt, "user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+			}
+
+			NewAPIKeyHandler(database).CreateAPIKey(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			if tt.wantExpiry == 0 {
+				assert.Nil(t, expiresAt.value)
+				return
+			}
+			require.NotNil(t, expiresAt.value)
+			assert.WithinDuration(t, time.Now().Add(tt.wantExpiry), *expiresAt.value, time.Minute)
+		})
+	}
+}
+
+// timeArg is a sqlmock argument matcher that records a nullable timestamp.
+type timeArg struct {
+	value *time.Time
+}
+
+func (a *timeArg) Match(v driver.Value) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case time.Time:
+		a.value = &v
+		return true
+	}
+	return false
+}
@@ -0,0 +1,394 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements the admin audit log API.
+//
+// AUDIT LOG FEATURES:
+// - Query security-relevant events recorded by the audit middleware
+// - Filter by user, action, resource type and time range
+// - Stream a CSV export without buffering the result set
+// - Purge records past a retention period
+//
+// API Endpoints:
+// - GET  /api/v1/admin/audit-log - Query audit events (cursor paginated)
+// - GET  /api/v1/admin/audit-log/export - Export audit events as CSV
+// - POST /api/v1/admin/audit-log/purge - Delete events older than a duration
+//
+// Security:
+// - Query and export require the admin role; purge requires superadmin
+// - Every call to these endpoints is itself written to the audit log
+//
+// Dependencies:
+// - Database: audit_log table
+//
+// Example Usage:
+//
+//	handler := NewAuditLogHandler(database)
+//	auditLog := router.Group("/api/v1/admin/audit-log")
+//	auditLog.GET("", handler.ListAuditLog)
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+const (
+	// defaultAuditLogLimit is the page size when no limit is given
+	defaultAuditLogLimit = 100
+
+	// auditLogExportFlushRows is how many CSV rows are written between flushes
+	auditLogExportFlushRows = 500
+)
+
+// AuditLogHandler handles admin queries against the audit log.
+type AuditLogHandler struct {
+	db *db.Database
+}
+
+// NewAuditLogHandler creates a new audit log handler.
+func NewAuditLogHandler(database *db.Database) *AuditLogHandler {
+	return &AuditLogHandler{db: database}
+}
+
+// AuditLogEntry is a single audit log record.
+type AuditLogEntry struct {
+	ID           int             `json:"id"`
+	UserID       string          `json:"userId"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resourceType"`
+	ResourceID   string          `json:"resourceId"`
+	Changes      json.RawMessage `json:"changes,omitempty"`
+	Timestamp    time.Time       `json:"timestamp"`
+	IPAddress    string          `json:"ipAddress"`
+}
+
+// auditLogFilter holds the parsed query filters shared by list and export.
+type auditLogFilter struct {
+	UserID       string
+	Action       string
+	ResourceType string
+	From         *time.Time
+	To           *time.Time
+}
+
+// parseAuditLogFilter reads the userId, action, resourceType, from and to
+// query parameters. from/to must be RFC 3339 timestamps.
+func parseAuditLogFilter(c *gin.Context) (auditLogFilter, error) {
+	filter := auditLogFilter{
+		UserID:       c.Query("userId"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(c, "to"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter.
+func parseTimeParam(c *gin.Context, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be an RFC 3339 timestamp", param)
+	}
+	return &t, nil
+}
+
+// where returns the WHERE clause and arguments for the filter, with
+// placeholders numbered from 1.
+func (f auditLogFilter) where() (string, []interface{}) {
+	clause := ` WHERE 1=1`
+	args := []interface{}{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		clause += fmt.Sprintf(condition, len(args))
+	}
+
+	if f.UserID != "" {
+		add(` AND user_id = $%d`, f.UserID)
+	}
+	if f.Action != "" {
+		add(` AND action = $%d`, f.Action)
+	}
+	if f.ResourceType != "" {
+		add(` AND resource_type = $%d`, f.ResourceType)
+	}
+	if f.From != nil {
+		add(` AND timestamp >= $%d`, *f.From)
+	}
+	if f.To != nil {
+		add(` AND timestamp < $%d`, *f.To)
+	}
+
+	return clause, args
+}
+
+// params returns the filter as a map for recording in the audit log.
+func (f auditLogFilter) params() map[string]interface{} {
+	params := map[string]interface{}{
+		"userId":       f.UserID,
+		"action":       f.Action,
+		"resourceType": f.ResourceType,
+	}
+	if f.From != nil {
+		params["from"] = f.From.Format(time.RFC3339)
+	}
+	if f.To != nil {
+		params["to"] = f.To.Format(time.RFC3339)
+	}
+	return params
+}
+
+const auditLogColumns = `id, user_id, action, resource_type, resource_id, changes, timestamp, ip_address`
+
+// scanAuditLogEntry scans a row selected with auditLogColumns.
+func scanAuditLogEntry(rows *sql.Rows) (AuditLogEntry, error) {
+	var entry AuditLogEntry
+	var userID, action, resourceType, resourceID, ipAddress sql.NullString
+	var changes []byte
+
+	if err := rows.Scan(&entry.ID, &userID, &action, &resourceType, &resourceID, &changes, &entry.Timestamp, &ipAddress); err != nil {
+		return entry, err
+	}
+
+	entry.UserID = userID.String
+	entry.Action = action.String
+	entry.ResourceType = resourceType.String
+	entry.ResourceID = resourceID.String
+	entry.IPAddress = ipAddress.String
+	if len(changes) > 0 {
+		entry.Changes = json.RawMessage(changes)
+	}
+	return entry, nil
+}
+
+// recordAccess writes an audit record for a call to the audit log API.
+// Failures are logged rather than returned so that a broken audit write
+// does not hide the result of a read.
+func (h *AuditLogHandler) recordAccess(ctx context.Context, c *gin.Context, action string, details map[string]interface{}) {
+	changes, _ := json.Marshal(details)
+
+	_, err := h.db.DB().ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, action, resource_type, resource_id, changes, timestamp, ip_address)
+		VALUES ($1, $2, 'audit_log', '', $3, CURRENT_TIMESTAMP, $4)
+	`, c.GetString("userID"), action, changes, c.ClientIP())
+	if err != nil {
+		log.Printf("Failed to record audit log access (%s): %v", action, err)
+	}
+}
+
+// ListAuditLog queries audit events, newest first.
+//
+// Endpoint: GET /api/v1/admin/audit-log
+//
+// Query Parameters:
+//   - userId, action, resourceType: Exact-match filters
+//   - from, to: RFC 3339 time range (from inclusive, to exclusive)
+//   - cursor, limit: Cursor pagination keyed on (timestamp, id); limit
+//     defaults to 100 (see pagination.go)
+//
+// Response: JSON with entries array and nextCursor (null on the last page)
+func (h *AuditLogHandler) ListAuditLog(c *gin.Context) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cursor, limit, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("limit") == "" {
+		limit = defaultAuditLogLimit
+	}
+
+	ctx := c.Request.Context()
+	where, args := filter.where()
+	query := `SELECT ` + auditLogColumns + ` FROM audit_log` + where
+
+	if cursor != nil {
+		query += ` AND (timestamp, id) < ($` + strconv.Itoa(len(args)+1) + `, $` + strconv.Itoa(len(args)+2) + `)`
+		args = append(args, cursor.Time, cursor.ID)
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	query += ` ORDER BY timestamp DESC, id DESC LIMIT $` + strconv.Itoa(len(args)+1)
+	args = append(args, limit+1)
+
+	rows, err := h.db.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query audit log"})
+		return
+	}
+	defer rows.Close()
+
+	entries := []AuditLogEntry{}
+	for rows.Next() {
+		entry, err := scanAuditLogEntry(rows)
+		if err != nil {
+			log.Printf("Failed to scan audit log entry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	var nextCursor *string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[len(entries)-1]
+		next := encodePageCursor(last.Timestamp, last.ID)
+		nextCursor = &next
+	}
+
+	h.recordAccess(ctx, c, "audit_log.query", filter.params())
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":    entries,
+		"nextCursor": nextCursor,
+	})
+}
+
+// ExportAuditLog streams audit events as CSV, oldest first.
+//
+// Endpoint: GET /api/v1/admin/audit-log/export
+//
+// Query Parameters:
+//   - from, to: RFC 3339 time range (from inclusive, to exclusive)
+//
+// Rows are written as they are read from the database and flushed
+// periodically, so the response uses chunked transfer encoding and the
+// result set is never held in memory.
+func (h *AuditLogHandler) ExportAuditLog(c *gin.Context) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Export supports only the time range filters
+	filter = auditLogFilter{From: filter.From, To: filter.To}
+
+	ctx := c.Request.Context()
+	where, args := filter.where()
+
+	rows, err := h.db.DB().QueryContext(ctx, `SELECT `+auditLogColumns+` FROM audit_log`+where+` ORDER BY timestamp ASC, id ASC`, args...)
+	if err != nil {
+		log.Printf("Failed to query audit log for export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export audit log"})
+		return
+	}
+	defer rows.Close()
+
+	h.recordAccess(ctx, c, "audit_log.export", filter.params())
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.csv"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "timestamp", "user_id", "action", "resource_type", "resource_id", "ip_address", "changes"})
+
+	count := 0
+	for rows.Next() {
+		entry, err := scanAuditLogEntry(rows)
+		if err != nil {
+			log.Printf("Failed to scan audit log entry: %v", err)
+			continue
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(entry.ID),
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			entry.UserID,
+			entry.Action,
+			entry.ResourceType,
+			entry.ResourceID,
+			entry.IPAddress,
+			string(entry.Changes),
+		})
+
+		count++
+		if count%auditLogExportFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	writer.Flush()
+	c.Writer.Flush()
+
+	if err := rows.Err(); err != nil {
+		// Headers are already sent; the truncated body is all we can return
+		log.Printf("Audit log export interrupted after %d rows: %v", count, err)
+	}
+}
+
+// PurgeAuditLogRequest is the request body for PurgeAuditLog.
+type PurgeAuditLogRequest struct {
+	// OlderThan is a Go duration (e.g. "2160h" for 90 days)
+	OlderThan string `json:"olderThan" binding:"required"`
+}
+
+// PurgeAuditLog deletes audit events older than the given duration.
+//
+// Endpoint: POST /api/v1/admin/audit-log/purge
+//
+// Request Body: {"olderThan": "2160h"}
+//
+// Response: JSON with the number of deleted records and the cutoff time
+func (h *AuditLogHandler) PurgeAuditLog(c *gin.Context) {
+	var req PurgeAuditLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	olderThan, err := time.ParseDuration(req.OlderThan)
+	if err != nil || olderThan <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "olderThan must be a positive duration (e.g. 2160h)"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	cutoff := time.Now().Add(-olderThan)
+
+	result, err := h.db.DB().ExecContext(ctx, `DELETE FROM audit_log WHERE timestamp < $1`, cutoff)
+	if err != nil {
+		log.Printf("Failed to purge audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge audit log"})
+		return
+	}
+	deleted, _ := result.RowsAffected()
+
+	h.recordAccess(ctx, c, "audit_log.purge", map[string]interface{}{
+		"olderThan": req.OlderThan,
+		"cutoff":    cutoff.UTC().Format(time.RFC3339),
+		"deleted":   deleted,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": deleted,
+		"cutoff":  cutoff,
+	})
+}
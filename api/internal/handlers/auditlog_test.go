@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditLogTest(t *testing.T, method, target, body string) (*AuditLogHandler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, method, target, body)
+	c.Set("userID", "admin-1")
+	return NewAuditLogHandler(database), mock, w, c
+}
+
+var auditLogRowColumns = []string{"id", "user_id", "action", "resource_type", "resource_id", "changes", "timestamp", "ip_address"}
+
+func TestListAuditLog_FiltersAndPaginates(t *testing.T) {
+	handler, mock, w, c := setupAuditLogTest(t, http.MethodGet,
+		"/admin/audit-log?userId=user-1&action=POST&from=2026-01-01T00:00:00Z&limit=1", "")
+
+	newer := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM audit_log WHERE 1=1 AND user_id = \$1 AND action = \$2 AND timestamp >= \$3 ORDER BY timestamp DESC, id DESC LIMIT \$4`).
+		WithArgs("user-1", "POST", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 2).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns).
+			AddRow(7, "user-1", "POST", "/api/v1/sessions", "sess-1", []byte(`{"status_code":201}`), newer, "10.0.0.1").
+			AddRow(5, "user-1", "POST", "/api/v1/sessions", "sess-0", nil, older, "10.0.0.1"))
+	mock.ExpectExec(`INSERT INTO audit_log`).
+		WithArgs("admin-1", "audit_log.query", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	handler.ListAuditLog(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Entries    []AuditLogEntry `json:"entries"`
+		NextCursor *string         `json:"nextCursor"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, 7, resp.Entries[0].ID)
+	require.NotNil(t, resp.NextCursor)
+	assert.Equal(t, encodePageCursor(newer, 7), *resp.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAuditLog_InvalidTimeRange(t *testing.T) {
+	handler, _, w, c := setupAuditLogTest(t, http.MethodGet, "/admin/audit-log?from=yesterday", "")
+
+	handler.ListAuditLog(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid from")
+}
+
+func TestExportAuditLog_StreamsCSV(t *testing.T) {
+	handler, mock, w, c := setupAuditLogTest(t, http.MethodGet, "/admin/audit-log/export?to=2026-04-01T00:00:00Z", "")
+
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM audit_log WHERE 1=1 AND timestamp < \$1 ORDER BY timestamp ASC, id ASC`).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns).
+			AddRow(1, "user-1", "totp.failed", "user", "user-1", []byte(`{"reason":"bad code"}`), ts, "10.0.0.1"))
+	mock.ExpectExec(`INSERT INTO audit_log`).
+		WithArgs("admin-1", "audit_log.export", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	handler.ExportAuditLog(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"id,timestamp,user_id,action,resource_type,resource_id,ip_address,changes\n"+
+			`1,2026-03-01T12:00:00Z,user-1,totp.failed,user,user-1,10.0.0.1,"{""reason"":""bad code""}"`+"\n",
+		w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeAuditLog(t *testing.T) {
+	handler, mock, w, c := setupAuditLogTest(t, http.MethodPost, "/admin/audit-log/purge", `{"olderThan":"2160h"}`)
+
+	mock.ExpectExec(`DELETE FROM audit_log WHERE timestamp < \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	mock.ExpectExec(`INSERT INTO audit_log`).
+		WithArgs("admin-1", "audit_log.purge", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	handler.PurgeAuditLog(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":42`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeAuditLog_InvalidDuration(t *testing.T) {
+	handler, _, w, c := setupAuditLogTest(t, http.MethodPost, "/admin/audit-log/purge", `{"olderThan":"-5h"}`)
+
+	handler.PurgeAuditLog(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -735,22 +735,21 @@ func (h *BatchHandler) executeBatchWake(jobID, userID string, sessionIDs []strin
 
 func (h *BatchHandler) executeBatchDelete(jobID, userID string, sessionIDs []string) {
 	ctx := context.Background()
+	sessionDB := db.NewSessionDB(h.db.DB())
 
 	successCount := 0
 	failureCount := 0
 	var errors []string
 
 	for _, sessionID := range sessionIDs {
-		result, err := h.db.DB().ExecContext(ctx, `
-			DELETE FROM sessions WHERE id = $1 AND user_id = $2
-		`, sessionID, userID)
+		err := sessionDB.ArchiveUserSession(ctx, sessionID, userID)
 
-		if err != nil {
-			failureCount++
-			errors = append(errors, fmt.Sprintf("session %s: %v", sessionID, err))
-		} else if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		if err == db.ErrSessionNotFound {
 			failureCount++
 			errors = append(errors, fmt.Sprintf("session %s: not found or not owned by user", sessionID))
+		} else if err != nil {
+			failureCount++
+			errors = append(errors, fmt.Sprintf("session %s: %v", sessionID, err))
 		} else {
 			successCount++
 		}
@@ -12,6 +12,8 @@
 // - Filter by category, tags, app type
 // - Sort by popularity, rating, recency, or install count
 // - Pagination support with customizable page size
+// - Listings cached in memory when a template cache is set; the X-Cache
+//   response header reports HIT or MISS (see internal/catalog)
 //
 // RATINGS AND REVIEWS:
 // - Add, update, and delete template ratings
@@ -38,13 +40,16 @@
 // - DELETE /api/v1/catalog/templates/:id/ratings/:ratingId - Delete rating
 // - POST   /api/v1/catalog/templates/:id/view - Record template view
 // - POST   /api/v1/catalog/templates/:id/install - Record template install
+// - GET    /api/v1/catalog/templates/:id/resource-estimate - Check cluster capacity (catalog_capacity.go)
+// - GET    /api/v1/catalog/categories - List template categories (catalog_categories.go)
 //
 // Thread Safety:
 // - All database operations are thread-safe via connection pooling
 //
 // Dependencies:
-// - Database: catalog_templates, repositories, template_ratings tables
+// - Database: catalog_templates, template_categories, repositories, template_ratings tables
 // - External Services: Repository sync for template metadata
+// - Kubernetes: Node and pod lists for resource estimates (optional)
 //
 // Example Usage:
 //
@@ -54,23 +59,40 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
+	"github.com/streamspace/streamspace/api/internal/catalog"
 	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/sync"
 )
 
 // CatalogHandler handles template catalog-related endpoints
 type CatalogHandler struct {
-	db *db.Database
+	db       *db.Database
+	cluster  ClusterCapacitySource
+	capacity *capacityCache
+
+	// templates caches ListTemplates responses; nil disables caching
+	templates *catalog.TemplateCache
+}
+
+// SetTemplateCache enables caching of template listings. The same cache
+// should be given to the sync service so completed syncs invalidate it.
+func (h *CatalogHandler) SetTemplateCache(cache *catalog.TemplateCache) {
+	h.templates = cache
 }
 
 // NewCatalogHandler creates a new catalog handler
 func NewCatalogHandler(database *db.Database) *CatalogHandler {
 	return &CatalogHandler{
-		db: database,
+		db:       database,
+		capacity: &capacityCache{},
 	}
 }
 
@@ -93,6 +115,23 @@ func (h *CatalogHandler) RegisterRoutes(router *gin.RouterGroup) {
 		// Statistics
 		catalog.POST("/templates/:id/view", h.RecordView)
 		catalog.POST("/templates/:id/install", h.RecordInstall)
+
+		// Cluster capacity check before session creation
+		catalog.GET("/templates/:id/resource-estimate", h.GetResourceEstimate)
+
+		// Version history (appended by repository sync)
+		catalog.GET("/templates/:id/versions", h.ListTemplateVersions)
+		catalog.POST("/templates/:id/rollback", h.RollbackTemplateVersion)
+
+		// Template categories (writes are admin only)
+		catalog.GET("/categories", h.ListCategories)
+		catalog.POST("/categories", h.CreateCategory)
+		catalog.PUT("/categories/:id", h.UpdateCategory)
+		catalog.DELETE("/categories/:id", h.DeleteCategory)
+
+		// Manifest validation for authors (any authenticated user)
+		catalog.POST("/templates/validate", h.ValidateTemplateManifest)
+		catalog.POST("/plugins/validate", h.ValidatePluginManifest)
 	}
 }
 
@@ -132,6 +171,26 @@ func (h *CatalogHandler) ListTemplates(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
+	var cacheKey string
+	if h.templates != nil {
+		cacheKey = catalog.TemplateQuery{
+			Search:   search,
+			Category: category,
+			Tag:      tag,
+			AppType:  appType,
+			Featured: featured,
+			Sort:     sortBy,
+			Page:     page,
+			Limit:    limit,
+		}.Key()
+		if cached, ok := h.templates.Get(cacheKey); ok {
+			c.Header("X-Cache", "HIT")
+			c.JSON(http.StatusOK, cached)
+			return
+		}
+		c.Header("X-Cache", "MISS")
+	}
+
 	// Build query
 	query := `
 		SELECT
@@ -139,9 +198,11 @@ func (h *CatalogHandler) ListTemplates(c *gin.Context) {
 			ct.category, ct.app_type, ct.icon_url, ct.tags, ct.install_count,
 			ct.is_featured, ct.version, ct.view_count, ct.avg_rating, ct.rating_count,
 			ct.created_at, ct.updated_at,
-			r.name as repository_name, r.url as repository_url
+			r.name as repository_name, r.url as repository_url,
+			COALESCE(tc.display_name, ct.category), COALESCE(tc.icon_url, '')
 		FROM catalog_templates ct
 		JOIN repositories r ON ct.repository_id = r.id
+		LEFT JOIN template_categories tc ON tc.name = ct.category
 		WHERE r.status = 'synced'
 	`
 
@@ -207,9 +268,11 @@ func (h *CatalogHandler) ListTemplates(c *gin.Context) {
 	defer rows.Close()
 
 	templates := []map[string]interface{}{}
+	repositoryIDs := []int{}
 	for rows.Next() {
 		var id, repositoryID, installCount, viewCount, ratingCount int
 		var name, displayName, description, category, appType, iconURL, version, repoName, repoURL string
+		var categoryDisplayName, categoryIcon string
 		var tags pq.StringArray
 		var isFeatured bool
 		var avgRating float64
@@ -220,29 +283,33 @@ func (h *CatalogHandler) ListTemplates(c *gin.Context) {
 			&category, &appType, &iconURL, &tags, &installCount,
 			&isFeatured, &version, &viewCount, &avgRating, &ratingCount,
 			&createdAt, &updatedAt, &repoName, &repoURL,
+			&categoryDisplayName, &categoryIcon,
 		)
 		if err != nil {
 			continue
 		}
 
+		repositoryIDs = append(repositoryIDs, repositoryID)
 		templates = append(templates, map[string]interface{}{
-			"id":           id,
-			"repositoryId": repositoryID,
-			"name":         name,
-			"displayName":  displayName,
-			"description":  description,
-			"category":     category,
-			"appType":      appType,
-			"icon":         iconURL,
-			"tags":         tags,
-			"installCount": installCount,
-			"isFeatured":   isFeatured,
-			"version":      version,
-			"viewCount":    viewCount,
-			"avgRating":    avgRating,
-			"ratingCount":  ratingCount,
-			"createdAt":    createdAt,
-			"updatedAt":    updatedAt,
+			"id":                  id,
+			"repositoryId":        repositoryID,
+			"name":                name,
+			"displayName":         displayName,
+			"description":         description,
+			"category":            category,
+			"categoryDisplayName": categoryDisplayName,
+			"categoryIcon":        categoryIcon,
+			"appType":             appType,
+			"icon":                iconURL,
+			"tags":                tags,
+			"installCount":        installCount,
+			"isFeatured":          isFeatured,
+			"version":             version,
+			"viewCount":           viewCount,
+			"avgRating":           avgRating,
+			"ratingCount":         ratingCount,
+			"createdAt":           createdAt,
+			"updatedAt":           updatedAt,
 			"repository": map[string]string{
 				"name": repoName,
 				"url":  repoURL,
@@ -288,13 +355,18 @@ func (h *CatalogHandler) ListTemplates(c *gin.Context) {
 	var total int
 	h.db.DB().QueryRowContext(c.Request.Context(), countQuery, countArgs...).Scan(&total)
 
-	c.JSON(http.StatusOK, gin.H{
-		"templates": templates,
-		"total":     total,
-		"page":      page,
-		"limit":     limit,
+	response := gin.H{
+		"templates":  templates,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
 		"totalPages": (total + limit - 1) / limit,
-	})
+	}
+	if h.templates != nil {
+		h.templates.Add(cacheKey, response, repositoryIDs)
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetTemplateDetails godoc
@@ -316,14 +388,17 @@ func (h *CatalogHandler) GetTemplateDetails(c *gin.Context) {
 			ct.category, ct.app_type, ct.icon_url, ct.manifest, ct.tags,
 			ct.install_count, ct.is_featured, ct.version, ct.view_count,
 			ct.avg_rating, ct.rating_count, ct.created_at, ct.updated_at,
-			r.name as repository_name, r.url as repository_url
+			r.name as repository_name, r.url as repository_url,
+			COALESCE(tc.display_name, ct.category), COALESCE(tc.icon_url, '')
 		FROM catalog_templates ct
 		JOIN repositories r ON ct.repository_id = r.id
+		LEFT JOIN template_categories tc ON tc.name = ct.category
 		WHERE ct.id = $1
 	`
 
 	var id, repositoryID, installCount, viewCount, ratingCount int
 	var name, displayName, description, category, appType, iconURL, manifest, version, repoName, repoURL string
+	var categoryDisplayName, categoryIcon string
 	var tags pq.StringArray
 	var isFeatured bool
 	var avgRating float64
@@ -334,6 +409,7 @@ func (h *CatalogHandler) GetTemplateDetails(c *gin.Context) {
 		&category, &appType, &iconURL, &manifest, &tags,
 		&installCount, &isFeatured, &version, &viewCount,
 		&avgRating, &ratingCount, &createdAt, &updatedAt, &repoName, &repoURL,
+		&categoryDisplayName, &categoryIcon,
 	)
 
 	if err == sql.ErrNoRows {
@@ -351,24 +427,26 @@ func (h *CatalogHandler) GetTemplateDetails(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, map[string]interface{}{
-		"id":           id,
-		"repositoryId": repositoryID,
-		"name":         name,
-		"displayName":  displayName,
-		"description":  description,
-		"category":     category,
-		"appType":      appType,
-		"icon":         iconURL,
-		"manifest":     manifest,
-		"tags":         tags,
-		"installCount": installCount,
-		"isFeatured":   isFeatured,
-		"version":      version,
-		"viewCount":    viewCount,
-		"avgRating":    avgRating,
-		"ratingCount":  ratingCount,
-		"createdAt":    createdAt,
-		"updatedAt":    updatedAt,
+		"id":                  id,
+		"repositoryId":        repositoryID,
+		"name":                name,
+		"displayName":         displayName,
+		"description":         description,
+		"category":            category,
+		"categoryDisplayName": categoryDisplayName,
+		"categoryIcon":        categoryIcon,
+		"appType":             appType,
+		"icon":                iconURL,
+		"manifest":            manifest,
+		"tags":                tags,
+		"installCount":        installCount,
+		"isFeatured":          isFeatured,
+		"version":             version,
+		"viewCount":           viewCount,
+		"avgRating":           avgRating,
+		"ratingCount":         ratingCount,
+		"createdAt":           createdAt,
+		"updatedAt":           updatedAt,
 		"repository": map[string]string{
 			"name": repoName,
 			"url":  repoURL,
@@ -625,6 +703,280 @@ func (h *CatalogHandler) RecordInstall(c *gin.Context) {
 	})
 }
 
+// ListTemplateVersions godoc
+// @Summary List template version history
+// @Description Get the versions of a catalog template recorded by repository sync, newest first
+// @Tags catalog
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/catalog/templates/{id}/versions [get]
+func (h *CatalogHandler) ListTemplateVersions(c *gin.Context) {
+	templateID := c.Param("id")
+	ctx := c.Request.Context()
+
+	var activeHash sql.NullString
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT version_hash FROM catalog_templates WHERE id = $1
+	`, templateID).Scan(&activeHash)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Template not found",
+			Message: "The requested template does not exist",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rows, err := h.db.DB().QueryContext(ctx, `
+		SELECT id, version, COALESCE(version_hash, ''), COALESCE(display_name, ''),
+		       COALESCE(description, ''), COALESCE(changelog, ''), synced_at
+		FROM catalog_template_versions
+		WHERE template_id = $1
+		ORDER BY synced_at DESC, id DESC
+	`, templateID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer rows.Close()
+
+	versions := []map[string]interface{}{}
+	for rows.Next() {
+		var id int
+		var version, hash, displayName, description, changelog string
+		var syncedAt sql.NullTime
+		if err := rows.Scan(&id, &version, &hash, &displayName, &description, &changelog, &syncedAt); err != nil {
+			continue
+		}
+		versions = append(versions, map[string]interface{}{
+			"id":          id,
+			"version":     version,
+			"versionHash": hash,
+			"displayName": displayName,
+			"description": description,
+			"changelog":   changelog,
+			"syncedAt":    syncedAt.Time,
+			"active":      activeHash.Valid && hash == activeHash.String,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templateId": templateID,
+		"versions":   versions,
+		"total":      len(versions),
+	})
+}
+
+// RollbackTemplateRequest represents a template rollback request
+type RollbackTemplateRequest struct {
+	VersionID int `json:"versionId" binding:"required"`
+}
+
+// RollbackTemplateVersion godoc
+// @Summary Roll back a template to a previous version
+// @Description Copy a historical version into the active catalog record (admin only).
+// @Description The rollback stays in effect until the repository publishes a new change for the template.
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body RollbackTemplateRequest true "Version to restore"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/catalog/templates/{id}/rollback [post]
+func (h *CatalogHandler) RollbackTemplateVersion(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Only administrators can roll back templates",
+		})
+		return
+	}
+
+	templateID := c.Param("id")
+
+	var req RollbackTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.db.DB().ExecContext(c.Request.Context(), `
+		UPDATE catalog_templates ct
+		SET manifest = v.manifest,
+		    display_name = COALESCE(v.display_name, ct.display_name),
+		    description = COALESCE(v.description, ct.description),
+		    category = COALESCE(v.category, ct.category),
+		    app_type = COALESCE(v.app_type, ct.app_type),
+		    icon_url = COALESCE(v.icon_url, ct.icon_url),
+		    tags = COALESCE(v.tags, ct.tags),
+		    version_hash = v.version_hash,
+		    updated_at = NOW()
+		FROM catalog_template_versions v
+		WHERE ct.id = $1 AND v.id = $2 AND v.template_id = ct.id
+	`, templateID, req.VersionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Version not found",
+			Message: "The requested version does not exist for this template",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Template rolled back",
+		"templateId": templateID,
+		"versionId":  req.VersionID,
+	})
+}
+
+// maxManifestBodySize caps manifest validation request bodies (1 MB)
+const maxManifestBodySize = 1 << 20
+
+// ValidateTemplateManifest godoc
+// @Summary Validate a template manifest
+// @Description Validate a raw Template YAML manifest before pushing it to a repository
+// @Tags catalog
+// @Accept application/yaml
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 415 {object} ErrorResponse
+// @Failure 422 {object} map[string]interface{}
+// @Router /catalog/templates/validate [post]
+func (h *CatalogHandler) ValidateTemplateManifest(c *gin.Context) {
+	contentType := c.ContentType()
+	if contentType != "application/yaml" && contentType != "text/yaml" &&
+		contentType != "application/x-yaml" && contentType != "text/x-yaml" {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+			Error:   "Unsupported content type",
+			Message: "Send the manifest as application/yaml or text/yaml",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxManifestBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	parser := sync.NewTemplateParser()
+	if errs := parser.TemplateManifestErrors(string(body)); len(errs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"valid":  false,
+			"errors": errs,
+		})
+		return
+	}
+
+	parsed, err := parser.ParseTemplateFromString(string(body))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"valid":  false,
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid": true,
+		"parsed": gin.H{
+			"name":        parsed.Name,
+			"displayName": parsed.DisplayName,
+			"description": parsed.Description,
+			"category":    parsed.Category,
+			"appType":     parsed.AppType,
+			"icon":        parsed.Icon,
+			"tags":        parsed.Tags,
+			"manifest":    json.RawMessage(parsed.Manifest),
+		},
+	})
+}
+
+// ValidatePluginManifest godoc
+// @Summary Validate a plugin manifest
+// @Description Validate a plugin manifest.json against the plugin schema
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /catalog/plugins/validate [post]
+func (h *CatalogHandler) ValidatePluginManifest(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxManifestBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := sync.NewPluginParser().ValidatePluginManifest(string(body)); err != nil {
+		var validationErr *sync.ManifestValidationError
+		if !errors.As(err, &validationErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"valid":  false,
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		errs := make([]string, 0, len(validationErr.Errors))
+		for _, fe := range validationErr.Errors {
+			path := fe.Path
+			if path == "" {
+				path = "/"
+			}
+			errs = append(errs, path+": "+fe.Message)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"valid":  false,
+			"errors": errs,
+		})
+		return
+	}
+
+	var manifest sync.PluginManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"valid":  false,
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":  true,
+		"parsed": manifest,
+	})
+}
+
 // updateTemplateRating updates the aggregated rating for a template
 func (h *CatalogHandler) updateTemplateRating(ctx interface{}, templateID string) {
 	h.db.DB().ExecContext(ctx.(*gin.Context).Request.Context(), `
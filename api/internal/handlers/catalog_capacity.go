@@ -0,0 +1,349 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements cluster capacity estimates for catalog templates.
+//
+// Before creating a session, users can ask whether the cluster currently has
+// room for a template's default resources (spec.defaultResources, falling
+// back to the system defaults used by session creation).
+//
+// ESTIMATION:
+// - Free capacity per node = allocatable - requests of pods bound to the node
+// - Cordoned and NotReady nodes are ignored
+// - Pending pods not yet bound to a node are placed first, largest first
+// - The template fits on a node when both its CPU and memory fit
+//
+// Pending pods are placed onto the node with the most free memory, since the
+// scheduler will place them before a new session.
+//
+// Node and pod lists are cached for 30 seconds so repeated estimates do not
+// hammer the Kubernetes API.
+//
+// API Endpoints:
+// - GET /api/v1/catalog/templates/:id/resource-estimate - Estimate whether a template can be scheduled
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// capacityCacheTTL is how long node and pod lists are reused
+	capacityCacheTTL = 30 * time.Second
+
+	// defaultEstimateCPU and defaultEstimateMemory are the session creation
+	// defaults for templates without defaultResources
+	defaultEstimateCPU    = "1000m"
+	defaultEstimateMemory = "2Gi"
+
+	// baseStartSeconds is the typical time to start a session on a node
+	// with room; each pending pod ahead of it adds pendingPodStartSeconds
+	baseStartSeconds       = 30
+	pendingPodStartSeconds = 5
+)
+
+// ClusterCapacitySource lists the nodes and pods capacity estimates are
+// computed from.
+//
+// *k8s.Client implements this interface; it is declared here so the catalog
+// handler can be tested without a cluster.
+type ClusterCapacitySource interface {
+	GetNodes(ctx context.Context) (*corev1.NodeList, error)
+	GetPods(ctx context.Context, namespace string) (*corev1.PodList, error)
+}
+
+// ResourceEstimate is the response of GET /catalog/templates/:id/resource-estimate.
+type ResourceEstimate struct {
+	CanSchedule           bool              `json:"canSchedule"`
+	NodeWithMostCapacity  string            `json:"nodeWithMostCapacity,omitempty"`
+	EstimatedStartSeconds int               `json:"estimatedStartSeconds,omitempty"`
+	Reason                string            `json:"reason,omitempty"`
+	Requested             map[string]string `json:"requested"`
+}
+
+// nodeCapacity is the free CPU (millicores) and memory (bytes) of one node.
+type nodeCapacity struct {
+	name     string
+	cpuMilli int64
+	memory   int64
+}
+
+// clusterCapacity is the free capacity of the schedulable nodes after
+// pending pods have been placed.
+type clusterCapacity struct {
+	nodes       []nodeCapacity
+	pendingPods int
+}
+
+// capacityCache holds the last clusterCapacity for capacityCacheTTL.
+type capacityCache struct {
+	mu        sync.Mutex
+	capacity  *clusterCapacity
+	fetchedAt time.Time
+}
+
+// SetClusterClient sets where resource estimates read nodes and pods from.
+// Without one, the resource estimate endpoint responds 503.
+func (h *CatalogHandler) SetClusterClient(cluster ClusterCapacitySource) {
+	h.cluster = cluster
+}
+
+// GetResourceEstimate godoc
+// @Summary Estimate whether a template can be scheduled
+// @Description Compare the template's default resources with free node capacity
+// @Tags catalog
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} ResourceEstimate
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/catalog/templates/{id}/resource-estimate [get]
+func (h *CatalogHandler) GetResourceEstimate(c *gin.Context) {
+	templateID := c.Param("id")
+
+	var manifestJSON string
+	err := h.db.DB().QueryRowContext(c.Request.Context(), `
+		SELECT manifest FROM catalog_templates WHERE id = $1
+	`, templateID).Scan(&manifestJSON)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Template not found",
+			Message: "The requested template does not exist",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	cpu, memory, err := templateDefaultResources(manifestJSON)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "Invalid template resources",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if h.cluster == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Cluster capacity unavailable",
+			Message: "No Kubernetes client is configured",
+		})
+		return
+	}
+
+	capacity, err := h.clusterCapacity(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Cluster capacity unavailable",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	estimate := capacity.estimate(cpu.MilliValue(), memory.Value())
+	estimate.Requested = map[string]string{
+		"cpu":    cpu.String(),
+		"memory": memory.String(),
+	}
+	c.JSON(http.StatusOK, estimate)
+}
+
+// templateDefaultResources returns the CPU and memory requested by sessions
+// of the template whose stored manifest is manifestJSON.
+func templateDefaultResources(manifestJSON string) (cpu, memory resource.Quantity, err error) {
+	var manifest struct {
+		Spec struct {
+			DefaultResources map[string]string
+		}
+	}
+	if manifestJSON != "" {
+		if err := json.Unmarshal([]byte(manifestJSON), &manifest); err != nil {
+			return cpu, memory, err
+		}
+	}
+
+	cpuValue, memoryValue := defaultEstimateCPU, defaultEstimateMemory
+	if v := manifest.Spec.DefaultResources["cpu"]; v != "" {
+		cpuValue = v
+	}
+	if v := manifest.Spec.DefaultResources["memory"]; v != "" {
+		memoryValue = v
+	}
+
+	if cpu, err = resource.ParseQuantity(cpuValue); err != nil {
+		return cpu, memory, err
+	}
+	if memory, err = resource.ParseQuantity(memoryValue); err != nil {
+		return cpu, memory, err
+	}
+	return cpu, memory, nil
+}
+
+// clusterCapacity returns the cached cluster capacity, refreshing it from
+// the cluster once it is older than capacityCacheTTL.
+func (h *CatalogHandler) clusterCapacity(ctx context.Context) (*clusterCapacity, error) {
+	h.capacity.mu.Lock()
+	defer h.capacity.mu.Unlock()
+
+	if h.capacity.capacity != nil && time.Since(h.capacity.fetchedAt) < capacityCacheTTL {
+		return h.capacity.capacity, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	nodes, err := h.cluster.GetNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := h.cluster.GetPods(ctx, corev1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+
+	h.capacity.capacity = computeClusterCapacity(nodes.Items, pods.Items)
+	h.capacity.fetchedAt = time.Now()
+	return h.capacity.capacity, nil
+}
+
+// computeClusterCapacity subtracts pod requests from node allocatable,
+// placing pending unbound pods onto the nodes with the most free memory.
+func computeClusterCapacity(nodes []corev1.Node, pods []corev1.Pod) *clusterCapacity {
+	capacity := &clusterCapacity{}
+	index := make(map[string]int)
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !nodeReady(&node) {
+			continue
+		}
+		index[node.Name] = len(capacity.nodes)
+		capacity.nodes = append(capacity.nodes, nodeCapacity{
+			name:     node.Name,
+			cpuMilli: node.Status.Allocatable.Cpu().MilliValue(),
+			memory:   node.Status.Allocatable.Memory().Value(),
+		})
+	}
+
+	var pending []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			pending = append(pending, pod)
+			continue
+		}
+		if i, ok := index[pod.Spec.NodeName]; ok {
+			cpu, memory := podRequests(&pod)
+			capacity.nodes[i].cpuMilli -= cpu
+			capacity.nodes[i].memory -= memory
+		}
+	}
+
+	// Largest pending pods first, each onto the node with the most free
+	// memory that fits it
+	sort.Slice(pending, func(i, j int) bool {
+		_, mi := podRequests(&pending[i])
+		_, mj := podRequests(&pending[j])
+		return mi > mj
+	})
+	for _, pod := range pending {
+		cpu, memory := podRequests(&pod)
+		if best := capacity.bestNode(cpu, memory); best >= 0 {
+			capacity.nodes[best].cpuMilli -= cpu
+			capacity.nodes[best].memory -= memory
+		}
+	}
+	capacity.pendingPods = len(pending)
+
+	return capacity
+}
+
+// bestNode returns the index of the node with the most free memory (then
+// CPU) that fits cpuMilli and memory, or -1 if none does.
+func (cc *clusterCapacity) bestNode(cpuMilli, memory int64) int {
+	best := -1
+	for i, node := range cc.nodes {
+		if node.cpuMilli < cpuMilli || node.memory < memory {
+			continue
+		}
+		if best < 0 || node.memory > cc.nodes[best].memory ||
+			(node.memory == cc.nodes[best].memory && node.cpuMilli > cc.nodes[best].cpuMilli) {
+			best = i
+		}
+	}
+	return best
+}
+
+// estimate reports whether a session requesting cpuMilli and memory can be
+// scheduled, and where.
+func (cc *clusterCapacity) estimate(cpuMilli, memory int64) ResourceEstimate {
+	if best := cc.bestNode(cpuMilli, memory); best >= 0 {
+		return ResourceEstimate{
+			CanSchedule:           true,
+			NodeWithMostCapacity:  cc.nodes[best].name,
+			EstimatedStartSeconds: baseStartSeconds + pendingPodStartSeconds*cc.pendingPods,
+		}
+	}
+
+	if len(cc.nodes) == 0 {
+		return ResourceEstimate{Reason: "No schedulable nodes available"}
+	}
+
+	cpuFits, memoryFits := false, false
+	for _, node := range cc.nodes {
+		cpuFits = cpuFits || node.cpuMilli >= cpuMilli
+		memoryFits = memoryFits || node.memory >= memory
+	}
+	switch {
+	case !cpuFits && !memoryFits:
+		return ResourceEstimate{Reason: "Insufficient CPU and memory on all nodes"}
+	case !memoryFits:
+		return ResourceEstimate{Reason: "Insufficient memory on all nodes"}
+	case !cpuFits:
+		return ResourceEstimate{Reason: "Insufficient CPU on all nodes"}
+	default:
+		return ResourceEstimate{Reason: "No single node has enough CPU and memory"}
+	}
+}
+
+// nodeReady reports whether the node's Ready condition is true.
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podRequests returns the CPU (millicores) and memory (bytes) the scheduler
+// reserves for a pod: the sum of its containers' requests, or the largest
+// init container request if that is higher.
+func podRequests(pod *corev1.Pod) (cpuMilli, memory int64) {
+	for _, container := range pod.Spec.Containers {
+		cpuMilli += container.Resources.Requests.Cpu().MilliValue()
+		memory += container.Resources.Requests.Memory().Value()
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if v := container.Resources.Requests.Cpu().MilliValue(); v > cpuMilli {
+			cpuMilli = v
+		}
+		if v := container.Resources.Requests.Memory().Value(); v > memory {
+			memory = v
+		}
+	}
+	return cpuMilli, memory
+}
@@ -0,0 +1,307 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements template category management.
+//
+// Catalog templates carry a category name from their repository manifest.
+// The template_categories table gives each name a display name, icon,
+// description and position, which template and plugin catalog listings
+// return as categoryDisplayName and categoryIcon. Categories used by a
+// template without a row are listed under their raw name.
+//
+// API Endpoints:
+// - GET    /api/v1/catalog/categories     - List categories
+// - POST   /api/v1/catalog/categories     - Create a category (admin only)
+// - PUT    /api/v1/catalog/categories/:id - Update a category (admin only)
+// - DELETE /api/v1/catalog/categories/:id - Delete an unused category (admin only)
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateCategory is a catalog template category.
+type TemplateCategory struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	DisplayName   string `json:"displayName"`
+	IconURL       string `json:"iconUrl"`
+	Description   string `json:"description"`
+	OrderIndex    int    `json:"orderIndex"`
+	TemplateCount int    `json:"templateCount"`
+}
+
+// CreateCategoryRequest is the body of POST /catalog/categories.
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	DisplayName string `json:"displayName" binding:"required,max=255"`
+	IconURL     string `json:"iconUrl"`
+	Description string `json:"description"`
+	OrderIndex  int    `json:"orderIndex"`
+}
+
+// UpdateCategoryRequest is the body of PUT /catalog/categories/:id. Omitted
+// fields are left unchanged. The name cannot be changed, since templates
+// reference categories by name.
+type UpdateCategoryRequest struct {
+	DisplayName *string `json:"displayName" binding:"omitempty,min=1,max=255"`
+	IconURL     *string `json:"iconUrl"`
+	Description *string `json:"description"`
+	OrderIndex  *int    `json:"orderIndex"`
+}
+
+// ListCategories godoc
+// @Summary List template categories
+// @Description List template categories in display order, with the number of templates in each
+// @Tags catalog
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/catalog/categories [get]
+func (h *CatalogHandler) ListCategories(c *gin.Context) {
+	rows, err := h.db.DB().QueryContext(c.Request.Context(), `
+		SELECT
+			tc.id, tc.name, tc.display_name, COALESCE(tc.icon_url, ''), COALESCE(tc.description, ''),
+			tc.order_index,
+			(SELECT COUNT(*) FROM catalog_templates ct WHERE ct.category = tc.name)
+		FROM template_categories tc
+		ORDER BY tc.order_index, tc.name
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer rows.Close()
+
+	categories := []TemplateCategory{}
+	for rows.Next() {
+		var category TemplateCategory
+		if err := rows.Scan(
+			&category.ID, &category.Name, &category.DisplayName, &category.IconURL, &category.Description,
+			&category.OrderIndex, &category.TemplateCount,
+		); err != nil {
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+		"total":      len(categories),
+	})
+}
+
+// CreateCategory godoc
+// @Summary Create a template category
+// @Description Create a template category (admin only)
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param request body CreateCategoryRequest true "Category"
+// @Success 201 {object} TemplateCategory
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/catalog/categories [post]
+func (h *CatalogHandler) CreateCategory(c *gin.Context) {
+	if !h.requireCategoryAdmin(c) {
+		return
+	}
+
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	category := TemplateCategory{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		IconURL:     req.IconURL,
+		Description: req.Description,
+		OrderIndex:  req.OrderIndex,
+	}
+	err := h.db.DB().QueryRowContext(c.Request.Context(), `
+		INSERT INTO template_categories (name, display_name, icon_url, description, order_index)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, (SELECT COUNT(*) FROM catalog_templates WHERE category = $1)
+	`, req.Name, req.DisplayName, req.IconURL, req.Description, req.OrderIndex).Scan(&category.ID, &category.TemplateCount)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Category exists",
+			Message: "A category named " + req.Name + " already exists",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.purgeTemplateCache()
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a template category
+// @Description Update a template category's display name, icon, description or position (admin only)
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param request body UpdateCategoryRequest true "Fields to change"
+// @Success 200 {object} TemplateCategory
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/catalog/categories/{id} [put]
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	if !h.requireCategoryAdmin(c) {
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var category TemplateCategory
+	err := h.db.DB().QueryRowContext(c.Request.Context(), `
+		UPDATE template_categories
+		SET display_name = COALESCE($2, display_name),
+		    icon_url = COALESCE($3, icon_url),
+		    description = COALESCE($4, description),
+		    order_index = COALESCE($5, order_index),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, name, display_name, COALESCE(icon_url, ''), COALESCE(description, ''), order_index,
+			(SELECT COUNT(*) FROM catalog_templates WHERE category = template_categories.name)
+	`, c.Param("id"), req.DisplayName, req.IconURL, req.Description, req.OrderIndex).Scan(
+		&category.ID, &category.Name, &category.DisplayName, &category.IconURL, &category.Description,
+		&category.OrderIndex, &category.TemplateCount,
+	)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Category not found",
+			Message: "The requested category does not exist",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.purgeTemplateCache()
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a template category
+// @Description Delete a template category no catalog template uses (admin only)
+// @Tags catalog
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/catalog/categories/{id} [delete]
+func (h *CatalogHandler) DeleteCategory(c *gin.Context) {
+	if !h.requireCategoryAdmin(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	categoryID := c.Param("id")
+
+	var name string
+	var templateCount int
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT tc.name, (SELECT COUNT(*) FROM catalog_templates ct WHERE ct.category = tc.name)
+		FROM template_categories tc
+		WHERE tc.id = $1
+	`, categoryID).Scan(&name, &templateCount)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Category not found",
+			Message: "The requested category does not exist",
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if templateCount > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Category in use",
+			Message: "Category " + name + " is used by catalog templates",
+		})
+		return
+	}
+
+	// Re-check in the DELETE so a template synced in between keeps its category
+	result, err := h.db.DB().ExecContext(ctx, `
+		DELETE FROM template_categories tc
+		WHERE tc.id = $1 AND NOT EXISTS (SELECT 1 FROM catalog_templates ct WHERE ct.category = tc.name)
+	`, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "Category in use",
+			Message: "Category " + name + " is used by catalog templates",
+		})
+		return
+	}
+
+	h.purgeTemplateCache()
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category deleted",
+		"name":    name,
+	})
+}
+
+// requireCategoryAdmin responds 403 and returns false unless the caller is
+// an admin.
+func (h *CatalogHandler) requireCategoryAdmin(c *gin.Context) bool {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Only administrators can manage template categories",
+		})
+		return false
+	}
+	return true
+}
+
+// purgeTemplateCache drops cached template listings, which include
+// category display names and icons.
+func (h *CatalogHandler) purgeTemplateCache() {
+	if h.templates != nil {
+		h.templates.Purge()
+	}
+}
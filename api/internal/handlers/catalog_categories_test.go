@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func categoryRequest(method, path, body, role string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userRole", role)
+	return c, w
+}
+
+func TestListCategories(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM template_categories tc").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "display_name", "icon_url", "description", "order_index", "template_count",
+		}).AddRow(1, "Browsers", "Web Browsers", "https://example.com/browsers.svg", "", 1, 4))
+
+	c, w := categoryRequest("GET", "/api/v1/catalog/categories", "", "user", nil)
+	handler.ListCategories(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"displayName":"Web Browsers"`)
+	assert.Contains(t, w.Body.String(), `"templateCount":4`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCategory_RequiresAdmin(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	c, w := categoryRequest("POST", "/api/v1/catalog/categories", `{"name":"IDEs","displayName":"IDEs"}`, "user", nil)
+	handler.CreateCategory(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCategory_Conflict(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("INSERT INTO template_categories").
+		WithArgs("IDEs", "Development", "", "", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "count"}))
+
+	c, w := categoryRequest("POST", "/api/v1/catalog/categories", `{"name":"IDEs","displayName":"Development","orderIndex":2}`, "admin", nil)
+	handler.CreateCategory(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCategory_PurgesTemplateCache(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+	handler.SetTemplateCache(catalog.NewTemplateCache(10, time.Minute))
+	handler.templates.Add("listing", gin.H{}, []int{1})
+
+	mock.ExpectQuery("UPDATE template_categories").
+		WithArgs("3", "Web Browsers", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "display_name", "icon_url", "description", "order_index", "template_count",
+		}).AddRow(3, "Browsers", "Web Browsers", "", "", 1, 4))
+
+	c, w := categoryRequest("PUT", "/api/v1/catalog/categories/3", `{"displayName":"Web Browsers"}`, "admin", gin.Params{{Key: "id", Value: "3"}})
+	handler.UpdateCategory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, handler.templates.Len())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_InUse(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT tc.name").
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).AddRow("Browsers", 4))
+
+	c, w := categoryRequest("DELETE", "/api/v1/catalog/categories/3", "", "admin", gin.Params{{Key: "id", Value: "3"}})
+	handler.DeleteCategory(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_Unused(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT tc.name").
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).AddRow("Browsers", 0))
+	mock.ExpectExec("DELETE FROM template_categories").
+		WithArgs("3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c, w := categoryRequest("DELETE", "/api/v1/catalog/categories/3", "", "admin", gin.Params{{Key: "id", Value: "3"}})
+	handler.DeleteCategory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/catalog"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func setupCatalogTest(t *testing.T) (*CatalogHandler, sqlmock.Sqlmock, func()) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	handler := NewCatalogHandler(db.NewDatabaseFromDB(mockDB))
+
+	return handler, mock, func() { mockDB.Close() }
+}
+
+func TestListTemplateVersions_MarksActive(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT version_hash FROM catalog_templates").
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"version_hash"}).AddRow("bbbb"))
+	mock.ExpectQuery("FROM catalog_template_versions").
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "version_hash", "display_name", "description", "changelog", "synced_at"}).
+			AddRow(2, "bbbb", "bbbb", "Firefox", "", "", time.Now()).
+			AddRow(1, "aaaa", "aaaa", "Firefox", "", "", time.Now().Add(-time.Hour)))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/catalog/templates/7/versions", nil)
+
+	handler.ListTemplateVersions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":2`)
+	assert.Contains(t, w.Body.String(), `"active":true`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackTemplateVersion_RequiresAdmin(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userRole", "user")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/catalog/templates/7/rollback", bytes.NewBufferString(`{"versionId":1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.RollbackTemplateVersion(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackTemplateVersion_UnknownVersion(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("UPDATE catalog_templates ct").
+		WithArgs("7", 99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userRole", "admin")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/catalog/templates/7/rollback", bytes.NewBufferString(`{"versionId":99}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.RollbackTemplateVersion(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackTemplateVersion_Success(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("UPDATE catalog_templates ct").
+		WithArgs("7", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userRole", "admin")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/catalog/templates/7/rollback", bytes.NewBufferString(`{"versionId":1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.RollbackTemplateVersion(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateTemplateManifest_ReportsAllErrors(t *testing.T) {
+	handler, _, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	body := "apiVersion: stream.space/v1alpha1\nkind: Template\nmetadata:\n  name: firefox\nspec: {}\n"
+	req := httptest.NewRequest("POST", "/catalog/templates/validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/yaml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "spec.displayName is required")
+	assert.Contains(t, w.Body.String(), "spec.baseImage is required")
+}
+
+func TestValidateTemplateManifest_Valid(t *testing.T) {
+	handler, _, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	body := "apiVersion: stream.space/v1alpha1\nkind: Template\nmetadata:\n  name: firefox\nspec:\n  displayName: Firefox\n  baseImage: lscr.io/linuxserver/firefox:latest\n"
+	req := httptest.NewRequest("POST", "/catalog/templates/validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/yaml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"valid":true`)
+	assert.Contains(t, w.Body.String(), `"appType":"desktop"`)
+}
+
+func TestValidatePluginManifest_FieldErrors(t *testing.T) {
+	handler, _, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	body := `{"name":"demo-plugin","version":"one","displayName":"Demo","type":"extension"}`
+	req := httptest.NewRequest("POST", "/catalog/plugins/validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "/version")
+}
+
+type fakeCluster struct {
+	nodes []corev1.Node
+	pods  []corev1.Pod
+	calls int
+}
+
+func (f *fakeCluster) GetNodes(ctx context.Context) (*corev1.NodeList, error) {
+	f.calls++
+	return &corev1.NodeList{Items: f.nodes}, nil
+}
+
+func (f *fakeCluster) GetPods(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: f.pods}, nil
+}
+
+func testNode(name, cpu, memory string) corev1.Node {
+	node := corev1.Node{}
+	node.Name = name
+	node.Status.Allocatable = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	return node
+}
+
+func testPod(nodeName, cpu, memory string) corev1.Pod {
+	pod := corev1.Pod{}
+	pod.Spec.NodeName = nodeName
+	pod.Status.Phase = corev1.PodRunning
+	if nodeName == "" {
+		pod.Status.Phase = corev1.PodPending
+	}
+	pod.Spec.Containers = []corev1.Container{{
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}}
+	return pod
+}
+
+func getResourceEstimate(t *testing.T, handler *CatalogHandler, mock sqlmock.Sqlmock, manifest string) (int, ResourceEstimate) {
+	mock.ExpectQuery("SELECT manifest FROM catalog_templates").
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"manifest"}).AddRow(manifest))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/catalog/templates/7/resource-estimate", nil)
+
+	handler.GetResourceEstimate(c)
+
+	var estimate ResourceEstimate
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &estimate))
+	}
+	return w.Code, estimate
+}
+
+func TestGetResourceEstimate_PicksNodeWithMostCapacity(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	cordoned := testNode("node-cordoned", "16", "64Gi")
+	cordoned.Spec.Unschedulable = true
+	cluster := &fakeCluster{
+		nodes: []corev1.Node{testNode("node-1", "4", "16Gi"), testNode("node-2", "4", "16Gi"), cordoned},
+		pods:  []corev1.Pod{testPod("node-2", "1", "8Gi")},
+	}
+	handler.SetClusterClient(cluster)
+
+	code, estimate := getResourceEstimate(t, handler, mock, `{"Spec":{"DefaultResources":{"cpu":"2","memory":"4Gi"}}}`)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, estimate.CanSchedule)
+	assert.Equal(t, "node-1", estimate.NodeWithMostCapacity)
+	assert.Equal(t, 30, estimate.EstimatedStartSeconds)
+	assert.Equal(t, map[string]string{"cpu": "2", "memory": "4Gi"}, estimate.Requested)
+
+	// Node data is cached between requests
+	getResourceEstimate(t, handler, mock, `{}`)
+	assert.Equal(t, 1, cluster.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetResourceEstimate_AccountsForPendingPods(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	handler.SetClusterClient(&fakeCluster{
+		nodes: []corev1.Node{testNode("node-1", "8", "8Gi"), testNode("node-2", "8", "8Gi")},
+		pods:  []corev1.Pod{testPod("", "1", "6Gi"), testPod("", "1", "6Gi")},
+	})
+
+	code, estimate := getResourceEstimate(t, handler, mock, `{"Spec":{"DefaultResources":{"memory":"4Gi"}}}`)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, estimate.CanSchedule)
+	assert.Equal(t, "Insufficient memory on all nodes", estimate.Reason)
+	assert.Equal(t, map[string]string{"cpu": "1", "memory": "4Gi"}, estimate.Requested)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetResourceEstimate_NoClusterClient(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+
+	code, _ := getResourceEstimate(t, handler, mock, `{}`)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListTemplates_CachesListing(t *testing.T) {
+	handler, mock, cleanup := setupCatalogTest(t)
+	defer cleanup()
+	handler.SetTemplateCache(catalog.NewTemplateCache(10, time.Minute))
+
+	now := time.Now()
+	mock.ExpectQuery("FROM catalog_templates ct").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "repository_id", "name", "display_name", "description",
+			"category", "app_type", "icon_url", "tags", "install_count",
+			"is_featured", "version", "view_count", "avg_rating", "rating_count",
+			"created_at", "updated_at", "repository_name", "repository_url",
+			"category_display_name", "category_icon",
+		}).AddRow(1, 3, "firefox", "Firefox", "", "Browsers", "desktop", "", "{web}", 0,
+			false, "1.0", 0, 0.0, 0, now, now, "official", "https://example.com/templates.git",
+			"Web Browsers", "https://example.com/browsers.svg"))
+	mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	list := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/catalog/templates?category=Browsers", nil)
+		handler.ListTemplates(c)
+		return w
+	}
+
+	first := list()
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Contains(t, first.Body.String(), `"categoryDisplayName":"Web Browsers"`)
+
+	// The second request must not reach the database
+	second := list()
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 1, handler.templates.InvalidateRepository(3))
+}
@@ -275,7 +275,7 @@ func NewCollaborationHandler(database *db.Database) *CollaborationHandler {
 func (h *CollaborationHandler) canAccessSession(userID, sessionID string) bool {
 	// Check if user owns the session
 	var owner string
-	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE id = $1", sessionID).Scan(&owner)
+	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE archived_at IS NULL AND id = $1", sessionID).Scan(&owner)
 	if err == nil && owner == userID {
 		return true
 	}
@@ -153,7 +153,7 @@ func (h *ConsoleHandler) CreateConsoleSession(c *gin.Context) {
 
 	// Verify user has access to this session
 	var sessionOwner string
-	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE id = $1", sessionID).Scan(&sessionOwner)
+	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE archived_at IS NULL AND id = $1", sessionID).Scan(&sessionOwner)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
 		return
@@ -661,7 +661,7 @@ func (h *ConsoleHandler) RenameFile(c *gin.Context) {
 func (h *ConsoleHandler) canAccessSession(userID, sessionID string) bool {
 	// Check if user owns the session
 	var owner string
-	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE id = $1", sessionID).Scan(&owner)
+	err := h.DB.DB().QueryRow("SELECT user_id FROM sessions WHERE archived_at IS NULL AND id = $1", sessionID).Scan(&owner)
 	if err == nil && owner == userID {
 		return true
 	}
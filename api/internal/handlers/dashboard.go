@@ -81,9 +81,9 @@ func (h *DashboardHandler) GetPlatformStats(c *gin.Context) {
 
 	// Get session stats
 	var totalSessions, runningSessions, hibernatedSessions int
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions`).Scan(&totalSessions)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'running'`).Scan(&runningSessions)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'hibernated'`).Scan(&hibernatedSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL`).Scan(&totalSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'running'`).Scan(&runningSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'hibernated'`).Scan(&hibernatedSessions)
 
 	// Get template count from Kubernetes
 	namespace := c.Query("namespace")
@@ -95,18 +95,18 @@ func (h *DashboardHandler) GetPlatformStats(c *gin.Context) {
 
 	// Get connection stats
 	var activeConnections int
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM connections`).Scan(&activeConnections)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM connections WHERE archived_at IS NULL`).Scan(&activeConnections)
 
 	// Get recent activity (last 24 hours)
 	var sessionsCreated24h, connectionsLast24h int
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '24 hours'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '24 hours'
 	`).Scan(&sessionsCreated24h)
 
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM connections
-		WHERE connected_at >= NOW() - INTERVAL '24 hours'
+		WHERE archived_at IS NULL AND connected_at >= NOW() - INTERVAL '24 hours'
 	`).Scan(&connectionsLast24h)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -289,6 +289,7 @@ func (h *DashboardHandler) GetTemplateUsageStats(c *gin.Context) {
 	query := `
 		SELECT template_name, COUNT(*) as session_count
 		FROM sessions
+		WHERE archived_at IS NULL
 		GROUP BY template_name
 		ORDER BY session_count DESC
 		LIMIT 20
@@ -339,7 +340,7 @@ func (h *DashboardHandler) GetActivityTimeline(c *gin.Context) {
 			DATE(created_at) as date,
 			COUNT(*) as count
 		FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '%d days'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '%d days'
 		GROUP BY DATE(created_at)
 		ORDER BY date DESC
 	`, days)
@@ -372,7 +373,7 @@ func (h *DashboardHandler) GetActivityTimeline(c *gin.Context) {
 			DATE(connected_at) as date,
 			COUNT(*) as count
 		FROM connections
-		WHERE connected_at >= NOW() - INTERVAL '%d days'
+		WHERE archived_at IS NULL AND connected_at >= NOW() - INTERVAL '%d days'
 		GROUP BY DATE(connected_at)
 		ORDER BY date DESC
 	`, days)
@@ -422,15 +423,15 @@ func (h *DashboardHandler) GetUserDashboard(c *gin.Context) {
 	// Get user's sessions
 	var totalSessions, runningSessions, hibernatedSessions int
 	h.db.DB().QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM sessions WHERE user_id = $1
+		SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND user_id = $1
 	`, userIDStr).Scan(&totalSessions)
 
 	h.db.DB().QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM sessions WHERE user_id = $1 AND state = 'running'
+		SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND user_id = $1 AND state = 'running'
 	`, userIDStr).Scan(&runningSessions)
 
 	h.db.DB().QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM sessions WHERE user_id = $1 AND state = 'hibernated'
+		SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND user_id = $1 AND state = 'hibernated'
 	`, userIDStr).Scan(&hibernatedSessions)
 
 	// Get user's quota
@@ -476,7 +477,7 @@ func (h *DashboardHandler) GetUserDashboard(c *gin.Context) {
 	var recentConnections int
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM connections
-		WHERE user_id = $1 AND connected_at >= NOW() - INTERVAL '24 hours'
+		WHERE archived_at IS NULL AND user_id = $1 AND connected_at >= NOW() - INTERVAL '24 hours'
 	`, userIDStr).Scan(&recentConnections)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -21,20 +21,26 @@
 //
 // API Endpoints:
 // - GET    /api/v1/groups - List all groups with optional filters
-// - POST   /api/v1/groups - Create new group
+// - POST   /api/v1/groups - Create new group (admin)
 // - GET    /api/v1/groups/:id - Get group by ID
-// - PATCH  /api/v1/groups/:id - Update group information
-// - DELETE /api/v1/groups/:id - Delete group
+// - PUT    /api/v1/groups/:id - Update group information (admin)
+// - PATCH  /api/v1/groups/:id - Update group information (admin)
+// - DELETE /api/v1/groups/:id - Delete group (admin)
 // - GET    /api/v1/groups/:id/members - List group members
-// - POST   /api/v1/groups/:id/members - Add user to group
-// - DELETE /api/v1/groups/:id/members/:userId - Remove user from group
-// - PATCH  /api/v1/groups/:id/members/:userId - Update member role
+// - POST   /api/v1/groups/:id/members - Add user to group (admin)
+// - DELETE /api/v1/groups/:id/members/:userId - Remove user from group (admin)
+// - PATCH  /api/v1/groups/:id/members/:userId - Update member role (admin)
 // - GET    /api/v1/groups/:id/quota - Get group quota
-// - PUT    /api/v1/groups/:id/quota - Set group quota
+// - PUT    /api/v1/groups/:id/quota - Set group quota (admin)
 //
 // Security:
+// - Endpoints that change groups or memberships require the admin role
 // - Password hashes removed from user objects in member lists
 // - User existence validated before membership operations
+// - Routes can be restricted to a group's members with auth.GroupMiddleware
+//
+// Group memberships are carried in the JWT groups claim by group name, so
+// membership changes apply to tokens issued afterwards.
 //
 // Thread Safety:
 // - All database operations are thread-safe via connection pooling
@@ -53,6 +59,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/auth"
 	"github.com/streamspace/streamspace/api/internal/db"
 	"github.com/streamspace/streamspace/api/internal/models"
 )
@@ -74,23 +81,25 @@ func NewGroupHandler(groupDB *db.GroupDB, userDB *db.UserDB) *GroupHandler {
 // RegisterRoutes registers group management routes
 func (h *GroupHandler) RegisterRoutes(router *gin.RouterGroup) {
 	groupRoutes := router.Group("/groups")
+	adminOnly := auth.RequireRole("admin")
 	{
 		// Group CRUD
 		groupRoutes.GET("", h.ListGroups)
-		groupRoutes.POST("", h.CreateGroup)
+		groupRoutes.POST("", adminOnly, h.CreateGroup)
 		groupRoutes.GET("/:id", h.GetGroup)
-		groupRoutes.PATCH("/:id", h.UpdateGroup)
-		groupRoutes.DELETE("/:id", h.DeleteGroup)
+		groupRoutes.PUT("/:id", adminOnly, h.UpdateGroup)
+		groupRoutes.PATCH("/:id", adminOnly, h.UpdateGroup)
+		groupRoutes.DELETE("/:id", adminOnly, h.DeleteGroup)
 
 		// Group members
 		groupRoutes.GET("/:id/members", h.GetGroupMembers)
-		groupRoutes.POST("/:id/members", h.AddGroupMember)
-		groupRoutes.DELETE("/:id/members/:userId", h.RemoveGroupMember)
-		groupRoutes.PATCH("/:id/members/:userId", h.UpdateMemberRole)
+		groupRoutes.POST("/:id/members", adminOnly, h.AddGroupMember)
+		groupRoutes.DELETE("/:id/members/:userId", adminOnly, h.RemoveGroupMember)
+		groupRoutes.PATCH("/:id/members/:userId", adminOnly, h.UpdateMemberRole)
 
 		// Group quotas
 		groupRoutes.GET("/:id/quota", h.GetGroupQuota)
-		groupRoutes.PUT("/:id/quota", h.SetGroupQuota)
+		groupRoutes.PUT("/:id/quota", adminOnly, h.SetGroupQuota)
 	}
 }
 
@@ -200,6 +209,7 @@ func (h *GroupHandler) GetGroup(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id} [put]
 // @Router /api/v1/groups/{id} [patch]
 func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 	groupID := c.Param("id")
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+// newHandlerTest returns a database backed by sqlmock and a gin context for a
+// request to target. The database is closed when the test finishes.
+func newHandlerTest(t *testing.T, method, target, body string) (*db.Database, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+
+	return db.NewDatabaseFromDB(mockDB), mock, w, c
+}
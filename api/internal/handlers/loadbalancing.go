@@ -491,7 +491,7 @@ func (h *LoadBalancingHandler) getSessionCountsByNode() (map[string]int, error)
 	rows, err := h.DB.DB().Query(`
 		SELECT node_name, COUNT(*) as session_count
 		FROM sessions
-		WHERE state = 'running' AND node_name IS NOT NULL
+		WHERE archived_at IS NULL AND state = 'running' AND node_name IS NOT NULL
 		GROUP BY node_name
 	`)
 	if err != nil {
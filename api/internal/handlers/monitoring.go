@@ -143,9 +143,9 @@ func (h *MonitoringHandler) PrometheusMetrics(c *gin.Context) {
 
 	// Session metrics
 	var totalSessions, runningSessions, hibernatedSessions int
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions`).Scan(&totalSessions)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'running'`).Scan(&runningSessions)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'hibernated'`).Scan(&hibernatedSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL`).Scan(&totalSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'running'`).Scan(&runningSessions)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'hibernated'`).Scan(&hibernatedSessions)
 
 	metrics = append(metrics,
 		fmt.Sprintf("# HELP streamspace_sessions_total Total number of sessions"),
@@ -167,7 +167,7 @@ func (h *MonitoringHandler) PrometheusMetrics(c *gin.Context) {
 	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&totalUsers)
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT user_id) FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '24 hours'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '24 hours'
 	`).Scan(&activeUsers)
 
 	metrics = append(metrics,
@@ -199,7 +199,7 @@ func (h *MonitoringHandler) PrometheusMetrics(c *gin.Context) {
 			COALESCE(AVG((resources->>'cpu')::float), 0),
 			COALESCE(AVG((resources->>'memory')::float), 0)
 		FROM sessions
-		WHERE state = 'running' AND resources IS NOT NULL
+		WHERE archived_at IS NULL AND state = 'running' AND resources IS NOT NULL
 	`).Scan(&avgCPU, &avgMemory)
 
 	metrics = append(metrics,
@@ -240,6 +240,7 @@ func (h *MonitoringHandler) SessionMetrics(c *gin.Context) {
 	rows, err := h.db.DB().QueryContext(ctx, `
 		SELECT state, COUNT(*) as count
 		FROM sessions
+		WHERE archived_at IS NULL
 		GROUP BY state
 	`)
 	if err != nil {
@@ -260,7 +261,7 @@ func (h *MonitoringHandler) SessionMetrics(c *gin.Context) {
 	rows, err = h.db.DB().QueryContext(ctx, `
 		SELECT template_name, COUNT(*) as count
 		FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '7 days'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '7 days'
 		GROUP BY template_name
 		ORDER BY count DESC
 		LIMIT 10
@@ -289,7 +290,7 @@ func (h *MonitoringHandler) SessionMetrics(c *gin.Context) {
 			COALESCE(AVG(EXTRACT(EPOCH FROM (terminated_at - created_at))), 0),
 			COALESCE(MAX(EXTRACT(EPOCH FROM (terminated_at - created_at))), 0)
 		FROM sessions
-		WHERE terminated_at IS NOT NULL
+		WHERE archived_at IS NULL AND terminated_at IS NOT NULL
 		AND created_at >= NOW() - INTERVAL '7 days'
 	`).Scan(&avgDuration, &maxDuration)
 
@@ -299,7 +300,7 @@ func (h *MonitoringHandler) SessionMetrics(c *gin.Context) {
 			EXTRACT(HOUR FROM created_at) as hour,
 			COUNT(*) as count
 		FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '24 hours'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '24 hours'
 		GROUP BY EXTRACT(HOUR FROM created_at)
 		ORDER BY hour
 	`)
@@ -339,7 +340,7 @@ func (h *MonitoringHandler) ResourceMetrics(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::float), 0),
 			COALESCE(SUM((resources->>'memory')::float), 0)
 		FROM sessions
-		WHERE state = 'running' AND resources IS NOT NULL
+		WHERE archived_at IS NULL AND state = 'running' AND resources IS NOT NULL
 	`).Scan(&totalCPU, &totalMemory)
 
 	// Resource usage by user
@@ -350,7 +351,7 @@ func (h *MonitoringHandler) ResourceMetrics(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::float), 0) as total_cpu,
 			COALESCE(SUM((resources->>'memory')::float), 0) as total_memory
 		FROM sessions
-		WHERE state = 'running' AND resources IS NOT NULL
+		WHERE archived_at IS NULL AND state = 'running' AND resources IS NOT NULL
 		GROUP BY user_id
 		ORDER BY total_cpu DESC
 		LIMIT 10
@@ -384,7 +385,7 @@ func (h *MonitoringHandler) ResourceMetrics(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::float), 0),
 			COALESCE(SUM((resources->>'memory')::float), 0)
 		FROM sessions
-		WHERE state = 'hibernated' AND resources IS NOT NULL
+		WHERE archived_at IS NULL AND state = 'hibernated' AND resources IS NOT NULL
 	`).Scan(&wastedSessions, &wastedCPU, &wastedMemory)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -408,9 +409,9 @@ func (h *MonitoringHandler) UserMetrics(c *gin.Context) {
 
 	// Active users by timeframe
 	var dau, wau, mau int
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE created_at >= NOW() - INTERVAL '1 day'`).Scan(&dau)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE created_at >= NOW() - INTERVAL '7 days'`).Scan(&wau)
-	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE created_at >= NOW() - INTERVAL '30 days'`).Scan(&mau)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '1 day'`).Scan(&dau)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '7 days'`).Scan(&wau)
+	h.db.DB().QueryRowContext(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '30 days'`).Scan(&mau)
 
 	// User growth
 	rows, err := h.db.DB().QueryContext(ctx, `
@@ -443,7 +444,7 @@ func (h *MonitoringHandler) UserMetrics(c *gin.Context) {
 	rows, err = h.db.DB().QueryContext(ctx, `
 		SELECT user_id, COUNT(*) as session_count
 		FROM sessions
-		WHERE created_at >= NOW() - INTERVAL '30 days'
+		WHERE archived_at IS NULL AND created_at >= NOW() - INTERVAL '30 days'
 		GROUP BY user_id
 		ORDER BY session_count DESC
 		LIMIT 10
@@ -666,7 +667,7 @@ func (h *MonitoringHandler) StorageHealth(c *gin.Context) {
 	// Sessions with persistent storage
 	var persistentSessionCount int
 	h.db.DB().QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM sessions WHERE persistent_home = true
+		SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND persistent_home = true
 	`).Scan(&persistentSessionCount)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,147 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements cursor-based (keyset) pagination helpers.
+//
+// CURSOR PAGINATION:
+//
+// Offset pagination (LIMIT/OFFSET) gets slower the deeper a client pages
+// and skips or repeats rows when data changes between requests. Keyset
+// pagination instead remembers the last row returned and asks for rows
+// strictly "after" it in the sort order:
+//
+//	WHERE (created_at, id) < ($cursor_time, $cursor_id)
+//	ORDER BY created_at DESC, id DESC
+//	LIMIT $limit
+//
+// The id tiebreaker makes the order total, so rows sharing a timestamp are
+// never skipped.
+//
+// CURSOR FORMAT:
+//
+// Cursors are opaque to clients: base64url-encoded JSON {"t": <timestamp>,
+// "id": <row id>}. Responses carry "nextCursor", which is null once the
+// last page has been returned. An absent or empty cursor returns the first
+// page.
+//
+// Query parameters:
+//   - cursor: Opaque cursor from a previous response's nextCursor
+//   - limit: Page size (default 50, max 200)
+//
+// PAGE PAGINATION:
+//
+// Where clients need numbered pages in any sort order (the plugin catalog),
+// endpoints also accept page (1-based) and pageSize and answer with the
+// number of matching rows, using LIMIT/OFFSET. Each endpoint caps its page
+// size.
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultPageLimit is the page size when no limit is given
+	defaultPageLimit = 50
+
+	// maxPageLimit caps the page size a client may request
+	maxPageLimit = 200
+)
+
+// pageCursor is the decoded position of the last row on a page.
+type pageCursor struct {
+	Time time.Time `json:"t"`
+	ID   int       `json:"id"`
+}
+
+// encodePageCursor returns the opaque cursor for the row (t, id).
+func encodePageCursor(t time.Time, id int) string {
+	data, _ := json.Marshal(pageCursor{Time: t, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor parses an opaque cursor. An empty string yields nil.
+func decodePageCursor(cursor string) (*pageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var pc pageCursor
+	if err := json.Unmarshal(data, &pc); err != nil || pc.Time.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &pc, nil
+}
+
+// parsePageParams reads the cursor and limit query parameters.
+func parsePageParams(c *gin.Context) (*pageCursor, int, error) {
+	limit := defaultPageLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			return nil, 0, fmt.Errorf("invalid limit")
+		}
+		limit = parsed
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	cursor, err := decodePageCursor(c.Query("cursor"))
+	if err != nil {
+		return nil, 0, err
+	}
+	return cursor, limit, nil
+}
+
+// wantsCursorPagination reports whether the client asked for paged results.
+//
+// Endpoints that predate pagination keep returning the full list when
+// neither cursor nor limit is present, so existing clients are unaffected.
+func wantsCursorPagination(c *gin.Context) bool {
+	_, hasCursor := c.GetQuery("cursor")
+	_, hasLimit := c.GetQuery("limit")
+	return hasCursor || hasLimit
+}
+
+// parseOffsetPageParams reads the page and pageSize query parameters.
+// pageSize defaults to defaultSize and is capped at maxSize.
+func parseOffsetPageParams(c *gin.Context, defaultSize, maxSize int) (int, int, error) {
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		parsed, err := strconv.Atoi(pageStr)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("invalid page")
+		}
+		page = parsed
+	}
+
+	pageSize := defaultSize
+	if sizeStr := c.Query("pageSize"); sizeStr != "" {
+		parsed, err := strconv.Atoi(sizeStr)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("invalid pageSize")
+		}
+		pageSize = parsed
+	}
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+	return page, pageSize, nil
+}
+
+// wantsOffsetPagination reports whether the client asked for numbered pages.
+func wantsOffsetPagination(c *gin.Context) bool {
+	_, hasPage := c.GetQuery("page")
+	_, hasPageSize := c.GetQuery("pageSize")
+	return hasPage || hasPageSize
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor_RoundTrip(t *testing.T) {
+	ts := time.Date(2025, 11, 20, 10, 30, 0, 123, time.UTC)
+
+	cursor, err := decodePageCursor(encodePageCursor(ts, 42))
+	require.NoError(t, err)
+	require.NotNil(t, cursor)
+	assert.True(t, ts.Equal(cursor.Time))
+	assert.Equal(t, 42, cursor.ID)
+
+	cursor, err = decodePageCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	_, err = decodePageCursor("not-a-cursor!")
+	assert.Error(t, err)
+}
+
+func TestListInstalledPlugins_CursorPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+
+	cursorTime := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	newer := cursorTime.Add(-time.Hour)
+	older := cursorTime.Add(-2 * time.Hour)
+
+	columns := []string{
+		"id", "catalog_plugin_id", "name", "version", "enabled",
+		"config", "installed_by", "installed_at", "updated_at",
+		"status", "last_error",
+		"display_name", "description", "plugin_type", "icon_url", "manifest", "catalog_version",
+	}
+	mock.ExpectQuery(`AND \(ip.installed_at, ip.id\) < \(\$1, \$2\) ORDER BY ip.installed_at DESC, ip.id DESC LIMIT \$3`).
+		WithArgs(cursorTime, 10, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(9, nil, "plugin-a", "1.0.0", true, []byte(`{}`), "admin", newer, newer, "loaded", nil, nil, nil, nil, nil, nil, nil).
+			AddRow(8, nil, "plugin-b", "1.0.0", true, []byte(`{}`), "admin", older, older, "failed", "plugin OnLoad failed: boom", nil, nil, nil, nil, nil, nil))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/plugins?limit=1&cursor="+encodePageCursor(cursorTime, 10), nil)
+
+	handler.ListInstalledPlugins(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Plugins    []map[string]interface{} `json:"plugins"`
+		NextCursor *string                  `json:"nextCursor"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Plugins, 1)
+	require.NotNil(t, response.NextCursor)
+
+	next, err := decodePageCursor(*response.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, 9, next.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBrowsePluginCatalog_PagePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM catalog_plugins cp\s+JOIN repositories r ON cp.repository_id = r.id\s+WHERE 1=1 AND cp.category = \$1`).
+		WithArgs("analytics").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(45))
+
+	now := time.Now()
+	columns := []string{
+		"id", "repository_id", "name", "version", "display_name",
+		"description", "category", "plugin_type", "icon_url",
+		"manifest", "tags", "install_count", "avg_rating", "rating_count",
+		"created_at", "updated_at",
+		"repo_id", "repo_name", "repo_url", "repo_type",
+		"category_display_name", "category_icon", "installed",
+	}
+	mock.ExpectQuery(`ORDER BY cp.avg_rating DESC, cp.rating_count DESC, cp.id ASC LIMIT \$2 OFFSET \$3`).
+		WithArgs("analytics", 20, 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(21, 1, "tracker", "1.0.0", "Tracker", "", "analytics", "extension", "",
+				nil, nil, 10, 4.5, 2, now, now, 1, "official", "https://plugins.example.com", "official",
+				"Analytics", "", true))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/plugins/catalog?category=analytics&sort=rating&page=2&pageSize=20", nil)
+
+	handler.BrowsePluginCatalog(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Plugins    []map[string]interface{} `json:"plugins"`
+		Total      int                      `json:"total"`
+		Page       int                      `json:"page"`
+		PageSize   int                      `json:"pageSize"`
+		TotalPages int                      `json:"totalPages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Plugins, 1)
+	assert.Equal(t, true, response.Plugins[0]["installed"])
+	assert.Equal(t, 45, response.Total)
+	assert.Equal(t, 2, response.Page)
+	assert.Equal(t, 20, response.PageSize)
+	assert.Equal(t, 3, response.TotalPages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBrowsePluginCatalog_PageParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(target string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", target, nil)
+		return c, w
+	}
+
+	c, _ := request("/plugins/catalog?pageSize=5000")
+	page, pageSize, err := parseOffsetPageParams(c, defaultCatalogPageSize, maxCatalogPageSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, page)
+	assert.Equal(t, maxCatalogPageSize, pageSize)
+
+	c, w := request("/plugins/catalog?page=0")
+	NewPluginHandler(nil, "").BrowsePluginCatalog(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid page")
+
+	c, w = request("/plugins/catalog?page=1&limit=10")
+	NewPluginHandler(nil, "").BrowsePluginCatalog(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
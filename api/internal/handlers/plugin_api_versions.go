@@ -0,0 +1,213 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements plugin API version introspection.
+//
+// Plugins can serve several versions of their HTTP API side by side under
+// /api/plugins/{name}/v{N}/ (see plugins.PluginAPI.V). Admins can declare
+// which version is current; the declaration is informational and does not
+// change routing.
+//
+// API Endpoints:
+// - GET   /api/plugins/:id/endpoints      - List a plugin's endpoints by API version and its event permissions
+// - PATCH /api/plugins/:id/active-version - Declare the current API version (admin only)
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+)
+
+// PluginEndpointRegistry lists the HTTP endpoints plugins registered.
+//
+// *plugins.APIRegistry implements this interface; it is declared here so
+// the plugin handler can be tested without a plugin runtime.
+type PluginEndpointRegistry interface {
+	GetPluginEndpoints(pluginName string) map[string][]*plugins.PluginEndpoint
+}
+
+// PluginEventPermissionSource reports the event permissions granted to
+// loaded plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// handler can be tested without a plugin runtime.
+type PluginEventPermissionSource interface {
+	EventPermissions(pluginName string) (plugins.EventPermissions, bool)
+}
+
+// PluginEndpointInfo describes one plugin endpoint in API responses.
+type PluginEndpointInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Public      bool     `json:"public,omitempty"`
+}
+
+// SetActiveVersionRequest is the body of PATCH /plugins/:id/active-version.
+type SetActiveVersionRequest struct {
+	Version string `json:"version" binding:"required"`
+}
+
+// SetAPIRegistry sets where plugin endpoints are listed from. Without one,
+// plugins are reported without endpoints.
+func (h *PluginHandler) SetAPIRegistry(registry PluginEndpointRegistry) {
+	h.apiRegistry = registry
+}
+
+// SetEventPermissionSource sets where plugins' event permissions are read
+// from. Without one, plugins are reported without event permissions.
+func (h *PluginHandler) SetEventPermissionSource(source PluginEventPermissionSource) {
+	h.eventPermissions = source
+}
+
+// ListPluginEndpoints lists a loaded plugin's HTTP endpoints grouped by API
+// version, with the version admins declared current and the events the
+// plugin may listen to and emit.
+//
+// Endpoint: GET /api/plugins/:id/endpoints
+//
+// Unversioned endpoints are listed under v1. activeVersion is v1 until an
+// admin declares another. eventPermissions is null for plugins that are
+// not loaded.
+//
+// Example Response:
+//
+//	{
+//	  "plugin": "streamspace-billing",
+//	  "activeVersion": "v1",
+//	  "versions": {"v1": [{"method": "GET", "path": "/invoices"}]},
+//	  "eventPermissions": {
+//	    "listen": ["session.*"],
+//	    "emit": ["plugin.streamspace-billing.*"]
+//	  }
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Success (versions is empty for plugins that are not loaded)
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) ListPluginEndpoints(c *gin.Context) {
+	var name string
+	var activeVersion sql.NullString
+	err := h.db.DB().QueryRow(`
+		SELECT name, active_api_version FROM installed_plugins WHERE id = $1
+	`, c.Param("id")).Scan(&name, &activeVersion)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	versions := make(map[string][]PluginEndpointInfo)
+	for version, endpoints := range h.pluginEndpoints(name) {
+		infos := make([]PluginEndpointInfo, 0, len(endpoints))
+		for _, e := range endpoints {
+			infos = append(infos, PluginEndpointInfo{
+				Method:      e.Method,
+				Path:        e.Path,
+				Description: e.Description,
+				Permissions: e.Permissions,
+				Public:      e.Public,
+			})
+		}
+		versions[version] = infos
+	}
+
+	active := plugins.DefaultAPIVersion
+	if activeVersion.Valid && activeVersion.String != "" {
+		active = activeVersion.String
+	}
+
+	var eventPermissions *plugins.EventPermissions
+	if h.eventPermissions != nil {
+		if perms, ok := h.eventPermissions.EventPermissions(name); ok {
+			eventPermissions = &perms
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plugin":           name,
+		"activeVersion":    active,
+		"versions":         versions,
+		"eventPermissions": eventPermissions,
+	})
+}
+
+// SetPluginActiveVersion declares which of a plugin's API versions is
+// current.
+//
+// Endpoint: PATCH /api/plugins/:id/active-version
+//
+// Request Body:
+//
+//	{"version": "v2"}
+//
+// The version must have the form v{N} ("2" is accepted as "v2"). For a
+// loaded plugin it must also be a version the plugin serves.
+//
+// HTTP Status Codes:
+//   - 200: Active version saved
+//   - 400: Invalid version, or a version the plugin does not serve
+//   - 403: Caller is not an admin
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) SetPluginActiveVersion(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can set a plugin's active API version"})
+		return
+	}
+
+	var req SetActiveVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+	version, err := plugins.ParseAPIVersion(req.Version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version", "details": err.Error()})
+		return
+	}
+
+	id := c.Param("id")
+	var name string
+	err = h.db.DB().QueryRow(`SELECT name FROM installed_plugins WHERE id = $1`, id).Scan(&name)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	// A loaded plugin can only declare a version it serves
+	if endpoints := h.pluginEndpoints(name); len(endpoints) > 0 {
+		if _, ok := endpoints[version]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Plugin " + name + " has no " + version + " endpoints"})
+			return
+		}
+	}
+
+	if _, err := h.db.DB().Exec(`
+		UPDATE installed_plugins SET active_api_version = $1, updated_at = NOW() WHERE id = $2
+	`, version, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set active version", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plugin": name, "activeVersion": version})
+}
+
+// pluginEndpoints returns a plugin's endpoints by version, or nil without
+// an API registry.
+func (h *PluginHandler) pluginEndpoints(name string) map[string][]*plugins.PluginEndpoint {
+	if h.apiRegistry == nil {
+		return nil
+	}
+	return h.apiRegistry.GetPluginEndpoints(name)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEndpointRegistry map[string]map[string][]*plugins.PluginEndpoint
+
+func (f fakeEndpointRegistry) GetPluginEndpoints(pluginName string) map[string][]*plugins.PluginEndpoint {
+	return f[pluginName]
+}
+
+var testEndpointRegistry = fakeEndpointRegistry{
+	"reports": {
+		"v1": {{Method: "GET", Path: "/summary"}},
+		"v2": {{Method: "GET", Path: "/summary", Permissions: []string{"reports.read"}}},
+	},
+}
+
+type fakeEventPermissionSource map[string]plugins.EventPermissions
+
+func (f fakeEventPermissionSource) EventPermissions(pluginName string) (plugins.EventPermissions, bool) {
+	perms, ok := f[pluginName]
+	return perms, ok
+}
+
+func TestListPluginEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+	handler.SetAPIRegistry(testEndpointRegistry)
+	handler.SetEventPermissionSource(fakeEventPermissionSource{
+		"reports": {Listen: []string{"session.*"}, Emit: []string{"plugin.reports.*"}},
+	})
+
+	mock.ExpectQuery(`SELECT name, active_api_version FROM installed_plugins WHERE id = \$1`).
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "active_api_version"}).AddRow("reports", nil))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/plugins/3/endpoints", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.ListPluginEndpoints(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		ActiveVersion    string                          `json:"activeVersion"`
+		Versions         map[string][]PluginEndpointInfo `json:"versions"`
+		EventPermissions *plugins.EventPermissions       `json:"eventPermissions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "v1", resp.ActiveVersion)
+	require.Len(t, resp.Versions, 2)
+	assert.Equal(t, []string{"reports.read"}, resp.Versions["v2"][0].Permissions)
+	require.NotNil(t, resp.EventPermissions)
+	assert.Equal(t, []string{"session.*"}, resp.EventPermissions.Listen)
+	assert.Equal(t, []string{"plugin.reports.*"}, resp.EventPermissions.Emit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPluginActiveVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		role       string
+		body       string
+		wantStatus int
+		wantUpdate string
+	}{
+		{name: "served version", role: "admin", body: `{"version":"2"}`, wantStatus: http.StatusOK, wantUpdate: "v2"},
+		{name: "unserved version", role: "admin", body: `{"version":"v3"}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed version", role: "admin", body: `{"version":"latest"}`, wantStatus: http.StatusBadRequest},
+		{name: "not admin", role: "user", body: `{"version":"v2"}`, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+			handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+			handler.SetAPIRegistry(testEndpointRegistry)
+
+			if tt.role == "admin" && tt.name != "malformed version" {
+				mock.ExpectQuery(`SELECT name FROM installed_plugins WHERE id = \$1`).
+					WithArgs("3").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("reports"))
+			}
+			if tt.wantUpdate != "" {
+				mock.ExpectExec(`UPDATE installed_plugins SET active_api_version = \$1`).
+					WithArgs(tt.wantUpdate, "3").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/plugins/3/active-version", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: "3"}}
+			c.Set("userRole", tt.role)
+
+			handler.SetPluginActiveVersion(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements the delivery log of webhook-type plugins.
+//
+// Webhook plugins forward platform events to an external URL (see
+// plugins.WebhookDispatcher). Every HTTP attempt, including retries, is
+// recorded in webhook_deliveries; attempts of the same event share a
+// deliveryId.
+//
+// API Endpoints:
+// - GET /api/plugins/:id/deliveries - List a plugin's recent delivery attempts
+//
+// Query Parameters:
+// - event: Only attempts for this event type
+// - success: "true" or "false" to filter by outcome
+// - limit: Maximum attempts to return (default 50, max 200)
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+// PluginWebhookDelivery is one delivery attempt of a webhook-type plugin.
+type PluginWebhookDelivery struct {
+	ID         int64     `json:"id"`
+	DeliveryID string    `json:"deliveryId"`
+	EventType  string    `json:"eventType"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ListPluginDeliveries lists recent delivery attempts of a webhook plugin,
+// newest first.
+//
+// Endpoint: GET /api/plugins/:id/deliveries
+//
+// HTTP Status Codes:
+//   - 200: Success (may return empty array)
+//   - 400: Invalid success or limit parameter
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) ListPluginDeliveries(c *gin.Context) {
+	limit := defaultDeliveriesLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxDeliveriesLimit {
+		limit = maxDeliveriesLimit
+	}
+
+	var name string
+	err := h.db.DB().QueryRow(`SELECT name FROM installed_plugins WHERE id = $1`, c.Param("id")).Scan(&name)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	conditions := []string{"plugin_name = $1"}
+	args := []interface{}{name}
+	if event := c.Query("event"); event != "" {
+		args = append(args, event)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if raw := c.Query("success"); raw != "" {
+		success, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "success must be true or false"})
+			return
+		}
+		args = append(args, success)
+		conditions = append(conditions, fmt.Sprintf("success = $%d", len(args)))
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, delivery_id, event_type, url, attempt, status_code, success, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := h.db.DB().Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []PluginWebhookDelivery{}
+	for rows.Next() {
+		var d PluginWebhookDelivery
+		var statusCode sql.NullInt64
+		var errMsg sql.NullString
+		var durationMS sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.DeliveryID, &d.EventType, &d.URL, &d.Attempt, &statusCode, &d.Success, &errMsg, &durationMS, &d.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read deliveries", "details": err.Error()})
+			return
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			d.StatusCode = &code
+		}
+		d.Error = errMsg.String
+		d.DurationMS = durationMS.Int64
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read deliveries", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plugin": name, "deliveries": deliveries})
+}
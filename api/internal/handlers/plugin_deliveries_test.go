@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPluginDeliveries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT name FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("hooks"))
+	mock.ExpectQuery(`FROM webhook_deliveries\s+WHERE plugin_name = \$1 AND success = \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3`).
+		WithArgs("hooks", false, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "delivery_id", "event_type", "url", "attempt", "status_code", "success", "error", "duration_ms", "created_at"}).
+			AddRow(2, "d-1", "session.created", "https://hooks.example.com", 2, nil, false, "timeout", 10000, now).
+			AddRow(1, "d-1", "session.created", "https://hooks.example.com", 1, 502, false, nil, 40, now))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/plugins/7/deliveries?success=false&limit=10", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	handler.ListPluginDeliveries(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Deliveries []PluginWebhookDelivery `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Deliveries, 2)
+	assert.Nil(t, resp.Deliveries[0].StatusCode)
+	assert.Equal(t, "timeout", resp.Deliveries[0].Error)
+	assert.Equal(t, 502, *resp.Deliveries[1].StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
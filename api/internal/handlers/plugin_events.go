@@ -0,0 +1,137 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements the plugin event catalog and the admin plugin event
+// metrics and replay APIs.
+//
+// EVENT CATALOG:
+// - List event types that have a registered payload schema
+// - Describe the JSON shape of each payload for plugin authors
+//
+// EVENT METRICS:
+// - Emit counts per event type
+// - Handler invocations, errors, panics and latency per event type and plugin
+// - Worker pool queue depth and dropped deliveries
+//
+// EVENT REPLAY FEATURES:
+// - Re-deliver a single recorded event by its event_log ID
+// - Re-deliver every recorded event of a type since a point in time
+// - Optionally target one plugin, delivering only to its subscriptions
+//
+// Replayed events reach handlers wrapped in a plugins.ReplayedEvent envelope
+// with replayed=true, so plugins can tell them apart from live events.
+//
+// API Endpoints:
+// - GET /api/v1/plugins/events/catalog - List event types and payload shapes
+// - GET /api/v1/admin/plugins/events/metrics - Event bus metrics
+// - POST /api/v1/admin/plugins/events/:id/replay?plugin=name - Replay one event
+// - POST /api/v1/admin/plugins/events/replay?type=session.created&since=...&plugin=name - Replay by type
+//
+// Dependencies:
+// - Plugin event bus with persistence enabled (event_log table)
+//
+// Example Usage:
+//
+//	handler := NewPluginEventsHandler(pluginRuntime.GetEventBus())
+//	admin.POST("/plugins/events/:id/replay", handler.ReplayEvent)
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+)
+
+// PluginEventsHandler handles admin operations on recorded plugin events.
+type PluginEventsHandler struct {
+	bus *plugins.EventBus
+}
+
+// NewPluginEventsHandler creates a new plugin events handler.
+func NewPluginEventsHandler(bus *plugins.EventBus) *PluginEventsHandler {
+	return &PluginEventsHandler{bus: bus}
+}
+
+// EventCatalog lists the registered plugin event types and payload shapes.
+func (h *PluginEventsHandler) EventCatalog(c *gin.Context) {
+	schemas := h.bus.EventSchemas()
+	c.JSON(http.StatusOK, gin.H{
+		"events": schemas,
+		"count":  len(schemas),
+	})
+}
+
+// EventMetrics returns the event bus metrics snapshot and worker pool state.
+func (h *PluginEventsHandler) EventMetrics(c *gin.Context) {
+	metrics := h.bus.Metrics()
+	c.JSON(http.StatusOK, gin.H{
+		"events":   metrics.Events,
+		"handlers": metrics.Handlers,
+		"pool":     h.bus.Stats(),
+	})
+}
+
+// ReplayEvent re-emits one recorded event through the event bus.
+func (h *PluginEventsHandler) ReplayEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	result, err := h.bus.ReplayEvent(c.Request.Context(), id, c.Query("plugin"))
+	if err != nil {
+		h.replayError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReplayEvents re-emits recorded events of one type through the event bus.
+//
+// The type query parameter is required; since (RFC3339) defaults to
+// replaying from the oldest recorded event.
+func (h *PluginEventsHandler) ReplayEvents(c *gin.Context) {
+	eventType := c.Query("type")
+	if eventType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type is required"})
+		return
+	}
+
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("since must be RFC3339: %v", err)})
+			return
+		}
+		since = parsed
+	}
+
+	results, err := h.bus.ReplayEvents(c.Request.Context(), eventType, since, c.Query("plugin"))
+	if err != nil {
+		h.replayError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": results,
+		"count":  len(results),
+	})
+}
+
+// replayError maps an event bus replay error to a response.
+func (h *PluginEventsHandler) replayError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, plugins.ErrEventNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	case errors.Is(err, plugins.ErrEventPersistenceDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Event persistence is not enabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay events", "message": err.Error()})
+	}
+}
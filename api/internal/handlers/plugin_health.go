@@ -0,0 +1,95 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements plugin health reporting.
+//
+// The plugin runtime records whether each plugin loaded, failed to load or
+// is degraded (most recent event handler invocations failing, or its health
+// check erroring), and re-checks loaded plugins every minute (see
+// plugins/health.go). The status is also returned by GET /api/plugins and
+// GET /api/plugins/:id.
+//
+// API Endpoints:
+// - GET /api/plugins/:id/health - Get a plugin's runtime status
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+)
+
+// PluginHealthSource reports the runtime status of plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// handler can be tested without a plugin runtime.
+type PluginHealthSource interface {
+	PluginHealth(name string) (plugins.PluginHealthReport, bool)
+}
+
+// SetHealthSource sets where live plugin health is read from. Without one,
+// GET /plugins/:id/health reports the status last stored in the database.
+func (h *PluginHandler) SetHealthSource(source PluginHealthSource) {
+	h.health = source
+}
+
+// GetPluginHealth returns the runtime status of an installed plugin.
+//
+// Endpoint: GET /api/plugins/:id/health
+//
+// The live report of the plugin runtime is returned when the runtime has
+// tried to load the plugin; otherwise the status stored in the database, or
+// "unknown" if there is none.
+//
+// Example Response:
+//
+//	{
+//	  "plugin": "slack-notifications",
+//	  "status": "degraded",
+//	  "lastError": "18 of 20 event handler invocations failed in the last 1m0s",
+//	  "loaded": true,
+//	  "healthCheck": false,
+//	  "checkedAt": "2025-01-15T10:31:00Z",
+//	  "recentInvocations": 20,
+//	  "recentErrors": 18,
+//	  "recentErrorRate": 0.9
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Success
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) GetPluginHealth(c *gin.Context) {
+	var name string
+	var status, lastError sql.NullString
+	var statusUpdatedAt sql.NullTime
+	err := h.db.DB().QueryRow(`
+		SELECT name, status, last_error, status_updated_at FROM installed_plugins WHERE id = $1
+	`, c.Param("id")).Scan(&name, &status, &lastError, &statusUpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	if h.health != nil {
+		if report, ok := h.health.PluginHealth(name); ok {
+			c.JSON(http.StatusOK, report)
+			return
+		}
+	}
+
+	report := plugins.PluginHealthReport{
+		Plugin:    name,
+		Status:    status.String,
+		LastError: lastError.String,
+		CheckedAt: statusUpdatedAt.Time,
+	}
+	if report.Status == "" {
+		report.Status = plugins.PluginStatusUnknown
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHealthSource map[string]plugins.PluginHealthReport
+
+func (f fakeHealthSource) PluginHealth(name string) (plugins.PluginHealthReport, bool) {
+	report, ok := f[name]
+	return report, ok
+}
+
+func TestGetPluginHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		dbStatus   interface{}
+		source     fakeHealthSource
+		wantStatus string
+		wantError  string
+	}{
+		{
+			name:       "live report",
+			dbStatus:   "loaded",
+			source:     fakeHealthSource{"hooks": {Plugin: "hooks", Status: plugins.PluginStatusDegraded, LastError: "health check failed: timeout", Loaded: true}},
+			wantStatus: plugins.PluginStatusDegraded,
+			wantError:  "health check failed: timeout",
+		},
+		{
+			name:       "stored status",
+			dbStatus:   "failed",
+			source:     fakeHealthSource{},
+			wantStatus: plugins.PluginStatusFailed,
+		},
+		{
+			name:       "never loaded",
+			dbStatus:   nil,
+			wantStatus: plugins.PluginStatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+			handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+			if tt.source != nil {
+				handler.SetHealthSource(tt.source)
+			}
+
+			mock.ExpectQuery(`SELECT name, status, last_error, status_updated_at FROM installed_plugins WHERE id = \$1`).
+				WithArgs("4").
+				WillReturnRows(sqlmock.NewRows([]string{"name", "status", "last_error", "status_updated_at"}).
+					AddRow("hooks", tt.dbStatus, nil, nil))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/plugins/4/health", nil)
+			c.Params = gin.Params{{Key: "id", Value: "4"}}
+
+			handler.GetPluginHealth(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var report plugins.PluginHealthReport
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, "hooks", report.Plugin)
+			assert.Equal(t, tt.wantStatus, report.Status)
+			assert.Equal(t, tt.wantError, report.LastError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements reading back and deleting the ratings and reviews of
+// catalog plugins.
+//
+// Users rate plugins with POST /api/plugins/catalog/:id/rate (see
+// RatePlugin); catalog_plugins only keeps the average and count. These
+// endpoints list the individual reviews, with a per-star breakdown, and the
+// current user's own rating so the UI can pre-fill the review form.
+//
+// Every rating change locks the plugin's catalog_plugins row and recomputes
+// avg_rating and rating_count in the same transaction, so concurrent ratings
+// cannot leave stale aggregates.
+//
+// API Endpoints:
+// - GET    /api/plugins/catalog/:id/ratings    - List a plugin's ratings
+// - GET    /api/plugins/catalog/:id/ratings/me - Get the current user's rating
+// - DELETE /api/plugins/catalog/:id/rate       - Delete the current user's rating
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+)
+
+const (
+	// defaultRatingsPageSize is the ratings page size when pageSize is not given
+	defaultRatingsPageSize = 20
+
+	// maxRatingsPageSize caps the ratings page size a client may request
+	maxRatingsPageSize = 100
+)
+
+// ratingSortOrders maps the sort query parameter of ListPluginRatings to
+// ORDER BY clauses. pr.id breaks ties so pages do not overlap.
+var ratingSortOrders = map[string]string{
+	"newest":  "pr.created_at DESC, pr.id DESC",
+	"highest": "pr.rating DESC, pr.created_at DESC, pr.id DESC",
+}
+
+// ListPluginRatings lists the ratings and reviews of a catalog plugin.
+//
+// Endpoint: GET /api/plugins/catalog/:id/ratings
+//
+// Query Parameters:
+//   - sort: newest (default) or highest
+//   - page, pageSize: Page pagination (see pagination.go). pageSize defaults
+//     to 20, at most 100.
+//
+// histogram counts the plugin's ratings per star, for all pages.
+//
+// Example Response:
+//
+//	{
+//	  "ratings": [
+//	    {
+//	      "id": 12,
+//	      "pluginId": 42,
+//	      "userId": "user-7",
+//	      "reviewerName": "Jane Doe",
+//	      "rating": 5,
+//	      "review": "Works perfectly",
+//	      "createdAt": "2025-01-10T09:00:00Z",
+//	      "updatedAt": "2025-01-10T09:00:00Z"
+//	    }
+//	  ],
+//	  "histogram": {"1": 0, "2": 1, "3": 0, "4": 3, "5": 8},
+//	  "total": 12,
+//	  "page": 1,
+//	  "pageSize": 20,
+//	  "totalPages": 1
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Success
+//   - 400: Invalid sort or page parameters
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) ListPluginRatings(c *gin.Context) {
+	ctx := c.Request.Context()
+	pluginID := c.Param("id")
+
+	sortBy := c.DefaultQuery("sort", "newest")
+	orderBy, ok := ratingSortOrders[sortBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort", "details": "sort must be newest or highest"})
+		return
+	}
+	page, pageSize, err := parseOffsetPageParams(c, defaultRatingsPageSize, maxRatingsPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters", "details": err.Error()})
+		return
+	}
+
+	var exists bool
+	if err := h.db.DB().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM catalog_plugins WHERE id = $1)
+	`, pluginID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+
+	// The histogram also gives the total across all pages
+	histogram := map[string]int{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
+	total := 0
+	histRows, err := h.db.DB().QueryContext(ctx, `
+		SELECT rating, COUNT(*) FROM plugin_ratings WHERE plugin_id = $1 GROUP BY rating
+	`, pluginID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+		return
+	}
+	defer histRows.Close()
+	for histRows.Next() {
+		var rating, count int
+		if err := histRows.Scan(&rating, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+			return
+		}
+		histogram[strconv.Itoa(rating)] = count
+		total += count
+	}
+	if err := histRows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+		return
+	}
+
+	rows, err := h.db.DB().QueryContext(ctx, `
+		SELECT pr.id, pr.plugin_id, pr.user_id, COALESCE(NULLIF(u.full_name, ''), u.username, ''),
+			pr.rating, pr.review, pr.created_at, pr.updated_at
+		FROM plugin_ratings pr
+		LEFT JOIN users u ON pr.user_id = u.id
+		WHERE pr.plugin_id = $1
+		ORDER BY `+orderBy+`
+		LIMIT $2 OFFSET $3
+	`, pluginID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	ratings := []models.PluginRating{}
+	for rows.Next() {
+		rating, err := scanPluginRating(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+			return
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ratings":    ratings,
+		"histogram":  histogram,
+		"total":      total,
+		"page":       page,
+		"pageSize":   pageSize,
+		"totalPages": (total + pageSize - 1) / pageSize,
+	})
+}
+
+// GetMyPluginRating returns the current user's rating of a catalog plugin.
+//
+// Endpoint: GET /api/plugins/catalog/:id/ratings/me
+//
+// The response has the same fields as an entry of ListPluginRatings.
+//
+// HTTP Status Codes:
+//   - 200: Success
+//   - 401: Not authenticated
+//   - 404: The user has not rated the plugin
+//   - 500: Database error
+func (h *PluginHandler) GetMyPluginRating(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	rating, err := scanPluginRating(h.db.DB().QueryRowContext(c.Request.Context(), `
+		SELECT pr.id, pr.plugin_id, pr.user_id, COALESCE(NULLIF(u.full_name, ''), u.username, ''),
+			pr.rating, pr.review, pr.created_at, pr.updated_at
+		FROM plugin_ratings pr
+		LEFT JOIN users u ON pr.user_id = u.id
+		WHERE pr.plugin_id = $1 AND pr.user_id = $2
+	`, c.Param("id"), userID))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+// DeletePluginRating deletes the current user's rating of a catalog plugin
+// and recomputes the plugin's average rating.
+//
+// Endpoint: DELETE /api/plugins/catalog/:id/rate
+//
+// HTTP Status Codes:
+//   - 200: Rating deleted
+//   - 401: Not authenticated
+//   - 404: Plugin not found, or the user has not rated it
+//   - 500: Database error
+func (h *PluginHandler) DeletePluginRating(c *gin.Context) {
+	ctx := c.Request.Context()
+	pluginID := c.Param("id")
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	tx, err := h.beginRatingChange(ctx, pluginID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating", "details": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM plugin_ratings WHERE plugin_id = $1 AND user_id = $2
+	`, pluginID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating", "details": err.Error()})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+		return
+	}
+
+	if err := commitRatingChange(ctx, tx, pluginID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rating deleted successfully"})
+}
+
+// beginRatingChange starts a transaction changing a plugin's ratings. The
+// plugin's catalog_plugins row is locked until commitRatingChange, so
+// concurrent changes recompute the aggregates one after another. Returns
+// sql.ErrNoRows if the plugin does not exist.
+func (h *PluginHandler) beginRatingChange(ctx context.Context, pluginID string) (*sql.Tx, error) {
+	tx, err := h.db.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id FROM catalog_plugins WHERE id = $1 FOR UPDATE
+	`, pluginID).Scan(&id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// commitRatingChange recomputes a plugin's avg_rating and rating_count and
+// commits the transaction started by beginRatingChange.
+func commitRatingChange(ctx context.Context, tx *sql.Tx, pluginID string) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE catalog_plugins
+		SET avg_rating = COALESCE((SELECT AVG(rating) FROM plugin_ratings WHERE plugin_id = $1), 0),
+		    rating_count = (SELECT COUNT(*) FROM plugin_ratings WHERE plugin_id = $1),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, pluginID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ratingScanner is a *sql.Row or *sql.Rows.
+type ratingScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPluginRating scans a plugin_ratings row joined with the reviewer's
+// display name.
+func scanPluginRating(row ratingScanner) (models.PluginRating, error) {
+	var rating models.PluginRating
+	var review sql.NullString
+	err := row.Scan(&rating.ID, &rating.PluginID, &rating.UserID, &rating.ReviewerName,
+		&rating.Rating, &review, &rating.CreatedAt, &rating.UpdatedAt)
+	rating.Review = review.String
+	return rating, err
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ratingColumns = []string{"id", "plugin_id", "user_id", "reviewer_name", "rating", "review", "created_at", "updated_at"}
+
+func setupPluginRatingsTest(t *testing.T, method, target, body string) (*PluginHandler, sqlmock.Sqlmock, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, method, target, body)
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+	return NewPluginHandler(database, ""), mock, w, c
+}
+
+func TestListPluginRatings(t *testing.T) {
+	handler, mock, w, c := setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings?sort=highest&page=2&pageSize=2", "")
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM catalog_plugins WHERE id = \$1\)`).
+		WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT rating, COUNT\(\*\) FROM plugin_ratings WHERE plugin_id = \$1 GROUP BY rating`).
+		WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"rating", "count"}).AddRow(5, 3).AddRow(2, 2))
+	now := time.Now()
+	mock.ExpectQuery(`ORDER BY pr.rating DESC, pr.created_at DESC, pr.id DESC\s+LIMIT \$2 OFFSET \$3`).
+		WithArgs("42", 2, 2).
+		WillReturnRows(sqlmock.NewRows(ratingColumns).
+			AddRow(7, 42, "user-7", "Jane Doe", 5, "Works perfectly", now, now).
+			AddRow(3, 42, "user-3", "bob", 2, nil, now, now))
+
+	handler.ListPluginRatings(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Ratings    []models.PluginRating `json:"ratings"`
+		Histogram  map[string]int        `json:"histogram"`
+		Total      int                   `json:"total"`
+		TotalPages int                   `json:"totalPages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Ratings, 2)
+	assert.Equal(t, "Jane Doe", resp.Ratings[0].ReviewerName)
+	assert.Equal(t, "Works perfectly", resp.Ratings[0].Review)
+	assert.Empty(t, resp.Ratings[1].Review)
+	assert.Equal(t, map[string]int{"1": 0, "2": 2, "3": 0, "4": 0, "5": 3}, resp.Histogram)
+	assert.Equal(t, 5, resp.Total)
+	assert.Equal(t, 3, resp.TotalPages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPluginRatings_Errors(t *testing.T) {
+	handler, _, w, c := setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings?sort=lowest", "")
+	handler.ListPluginRatings(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	handler, mock, w, c := setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings", "")
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	handler.ListPluginRatings(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetMyPluginRating(t *testing.T) {
+	handler, _, w, c := setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings/me", "")
+	handler.GetMyPluginRating(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	handler, mock, w, c := setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings/me", "")
+	c.Set("userID", "user-7")
+	now := time.Now()
+	mock.ExpectQuery(`WHERE pr.plugin_id = \$1 AND pr.user_id = \$2`).
+		WithArgs("42", "user-7").
+		WillReturnRows(sqlmock.NewRows(ratingColumns).AddRow(7, 42, "user-7", "Jane Doe", 4, "Solid", now, now))
+
+	handler.GetMyPluginRating(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var rating models.PluginRating
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rating))
+	assert.Equal(t, 4, rating.Rating)
+	assert.Equal(t, "Solid", rating.Review)
+
+	handler, mock, w, c = setupPluginRatingsTest(t, http.MethodGet, "/plugins/catalog/42/ratings/me", "")
+	c.Set("userID", "user-8")
+	mock.ExpectQuery(`WHERE pr.plugin_id = \$1 AND pr.user_id = \$2`).
+		WithArgs("42", "user-8").
+		WillReturnRows(sqlmock.NewRows(ratingColumns))
+	handler.GetMyPluginRating(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRatePlugin(t *testing.T) {
+	handler, mock, w, c := setupPluginRatingsTest(t, http.MethodPost, "/plugins/catalog/42/rate", `{"rating": 4, "review": "Solid"}`)
+	c.Set("userID", "user-7")
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM plugin_install_history WHERE catalog_plugin_id = \$1 AND user_id = \$2\)`).
+		WithArgs("42", "user-7").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM catalog_plugins WHERE id = \$1 FOR UPDATE`).
+		WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectExec(`INSERT INTO plugin_ratings`).
+		WithArgs("42", "user-7", 4, "Solid").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE catalog_plugins\s+SET avg_rating = COALESCE`).
+		WithArgs("42").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	handler.RatePlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRatePlugin_RequiresInstall(t *testing.T) {
+	handler, mock, w, c := setupPluginRatingsTest(t, http.MethodPost, "/plugins/catalog/42/rate", `{"rating": 1}`)
+	c.Set("userID", "user-9")
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM plugin_install_history`).
+		WithArgs("42", "user-9").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	handler.RatePlugin(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeletePluginRating(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleted    int64
+		wantStatus int
+	}{
+		{name: "deleted", deleted: 1, wantStatus: http.StatusOK},
+		{name: "not rated", deleted: 0, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mock, w, c := setupPluginRatingsTest(t, http.MethodDelete, "/plugins/catalog/42/rate", "")
+			c.Set("userID", "user-7")
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM catalog_plugins WHERE id = \$1 FOR UPDATE`).
+				WithArgs("42").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+			mock.ExpectExec(`DELETE FROM plugin_ratings WHERE plugin_id = \$1 AND user_id = \$2`).
+				WithArgs("42", "user-7").
+				WillReturnResult(sqlmock.NewResult(0, tt.deleted))
+			if tt.deleted > 0 {
+				mock.ExpectExec(`UPDATE catalog_plugins\s+SET avg_rating = COALESCE`).
+					WithArgs("42").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			handler.DeletePluginRating(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
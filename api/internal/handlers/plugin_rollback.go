@@ -0,0 +1,167 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements rolling installed plugins back to the version they
+// were upgraded from.
+//
+// Each upgrade (see plugin_upgrade.go) records the replaced version with its
+// config and manifest in plugin_versions_history. A rollback restores the
+// most recent record:
+//
+//   - The recorded config is validated against the recorded manifest's
+//     configSchema
+//   - installed_plugins gets the recorded version, config and manifest, and
+//     the record is removed, so a second rollback goes back one more version
+//   - An enabled plugin is reloaded: its event subscriptions and HTTP
+//     endpoints are removed and it is loaded again as the restored version
+//   - The rollback is recorded in the audit log
+//
+// API Endpoints:
+// - POST /api/plugins/:id/rollback - Roll an installed plugin back to its previous version
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/models"
+)
+
+// RollbackPlugin restores the version an installed plugin was last
+// upgraded from.
+//
+// Endpoint: POST /api/plugins/:id/rollback
+//
+// Path Parameters:
+//   - id: Installed plugin ID
+//
+// Example Response:
+//
+//	{
+//	  "message": "Plugin rolled back successfully",
+//	  "pluginId": 123,
+//	  "fromVersion": "1.3.0",
+//	  "toVersion": "1.2.3"
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Plugin rolled back
+//   - 400: Recorded config does not match the recorded configSchema
+//   - 403: Caller is not an admin
+//   - 404: Plugin not found, or it has no previous version
+//   - 500: Database error
+func (h *PluginHandler) RollbackPlugin(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can roll back plugins"})
+		return
+	}
+
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	var pluginID int
+	var name, currentVersion string
+	var enabled bool
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT id, name, version, enabled FROM installed_plugins WHERE id = $1
+	`, id).Scan(&pluginID, &name, &currentVersion, &enabled)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	var historyID int
+	var previous pluginVersion
+	err = h.db.DB().QueryRowContext(ctx, `
+		SELECT id, version, config, manifest
+		FROM plugin_versions_history
+		WHERE installed_plugin_id = $1
+		ORDER BY upgraded_at DESC, id DESC
+		LIMIT 1
+	`, pluginID).Scan(&historyID, &previous.Version, &previous.Config, &previous.Manifest)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "No previous version to roll back to",
+			"details": "Plugin " + name + " has not been upgraded from an earlier version",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin history", "details": err.Error()})
+		return
+	}
+
+	// The recorded config must still satisfy the recorded version's schema
+	var manifest models.PluginManifest
+	if len(previous.Manifest) > 0 {
+		json.Unmarshal(previous.Manifest, &manifest)
+	}
+	if len(previous.Config) == 0 {
+		previous.Config = json.RawMessage("{}")
+	}
+	if errs := ValidatePluginConfig(&manifest, previous.Config); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Recorded configuration is not valid for version " + previous.Version,
+			"validationErrors": errs,
+		})
+		return
+	}
+
+	if err := h.restoreVersion(ctx, c, pluginID, historyID, name, currentVersion, previous); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back plugin", "details": err.Error()})
+		return
+	}
+
+	if enabled {
+		h.runLifecycleHook(name, "Reload", func(l PluginLifecycle) error { return l.ReloadPlugin(ctx, name) })
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Plugin rolled back successfully",
+		"pluginId":    pluginID,
+		"fromVersion": currentVersion,
+		"toVersion":   previous.Version,
+	})
+}
+
+// restoreVersion moves the installation back to a recorded version, removes
+// the record and writes the audit log entry in one transaction.
+func (h *PluginHandler) restoreVersion(ctx context.Context, c *gin.Context, pluginID, historyID int, name, fromVersion string, previous pluginVersion) error {
+	tx, err := h.db.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE installed_plugins SET version = $1, config = $2, manifest = $3, updated_at = NOW() WHERE id = $4
+	`, previous.Version, previous.Config, previous.Manifest, pluginID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM plugin_versions_history WHERE id = $1
+	`, historyID); err != nil {
+		return err
+	}
+
+	changes, _ := json.Marshal(map[string]interface{}{
+		"plugin":      name,
+		"fromVersion": fromVersion,
+		"toVersion":   previous.Version,
+	})
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_log (user_id, action, resource_type, resource_id, changes, timestamp, ip_address)
+		VALUES ($1, 'plugin.rollback', 'plugin', $2, $3, CURRENT_TIMESTAMP, $4)
+	`, c.GetString("userID"), strconv.Itoa(pluginID), changes, c.ClientIP()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const rollbackManifest = `{
+	"name": "hooks",
+	"version": "1.2.3",
+	"configSchema": {
+		"type": "object",
+		"properties": {"url": {"type": "string"}},
+		"required": ["url"]
+	}
+}`
+
+func expectRollbackPlugin(mock sqlmock.Sqlmock, enabled bool) {
+	mock.ExpectQuery(`SELECT id, name, version, enabled FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "enabled"}).
+			AddRow(7, "hooks", "1.3.0", enabled))
+}
+
+func expectRollbackHistory(mock sqlmock.Sqlmock, config string) {
+	mock.ExpectQuery(`SELECT id, version, config, manifest\s+FROM plugin_versions_history`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "config", "manifest"}).
+			AddRow(3, "1.2.3", []byte(config), []byte(rollbackManifest)))
+}
+
+func TestRollbackPlugin_Success(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	c.Set("userRole", "admin")
+	c.Set("userID", "admin")
+
+	config := `{"url":"https://hooks.example.com"}`
+	expectRollbackPlugin(mock, true)
+	expectRollbackHistory(mock, config)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE installed_plugins SET version = \$1, config = \$2, manifest = \$3`).
+		WithArgs("1.2.3", []byte(config), []byte(rollbackManifest), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM plugin_versions_history WHERE id = \$1`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO audit_log .* 'plugin.rollback'`).
+		WithArgs("admin", "7", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	handler.RollbackPlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"toVersion":"1.2.3"`)
+	assert.Equal(t, []string{"reload hooks"}, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackPlugin_NoHistory(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	c.Set("userRole", "admin")
+
+	expectRollbackPlugin(mock, true)
+	mock.ExpectQuery(`FROM plugin_versions_history`).
+		WithArgs(7).
+		WillReturnError(sql.ErrNoRows)
+
+	handler.RollbackPlugin(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "No previous version to roll back to")
+	assert.Empty(t, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackPlugin_InvalidRecordedConfig(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	c.Set("userRole", "admin")
+
+	expectRollbackPlugin(mock, true)
+	expectRollbackHistory(mock, `{}`)
+
+	handler.RollbackPlugin(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "validationErrors")
+	assert.Empty(t, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackPlugin_NotFound(t *testing.T) {
+	handler, mock, _, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	c.Set("userRole", "admin")
+
+	mock.ExpectQuery(`SELECT id, name, version, enabled FROM installed_plugins`).
+		WithArgs("7").
+		WillReturnError(sql.ErrNoRows)
+
+	handler.RollbackPlugin(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Plugin not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackPlugin_RequiresAdmin(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	c.Set("userID", "user1")
+	c.Set("userRole", "user")
+
+	handler.RollbackPlugin(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
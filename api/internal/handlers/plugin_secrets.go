@@ -0,0 +1,188 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements plugin secrets management.
+//
+// Plugin credentials (API tokens, webhook signing keys) are written to the
+// Kubernetes Secret plugin-{name}-secrets instead of installed_plugins.config,
+// so they are not readable from the database and never returned by the API.
+// Running plugins read them with ctx.Secrets.Get(key).
+//
+// API Endpoints:
+// - GET /api/plugins/:id/secrets - List the names of a plugin's secrets
+// - PUT /api/plugins/:id/secrets - Set or remove secrets (admin only)
+//
+// Request Body (PUT):
+//
+//	{
+//	  "secrets": {
+//	    "slack_token": "xoxb-...",  // set
+//	    "old_token": null           // remove
+//	  }
+//	}
+//
+// Both endpoints respond with the plugin's secret key names; values are
+// never returned.
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+)
+
+// secretKeyPattern is the set of valid Kubernetes Secret data keys
+var secretKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// PluginSecretStore reads and writes the Kubernetes Secrets holding plugin
+// secrets.
+//
+// *k8s.Client implements this interface; it is declared here so the plugin
+// handler can be tested without a cluster.
+type PluginSecretStore interface {
+	GetSecretData(ctx context.Context, name string) (map[string][]byte, error)
+	UpdateSecretData(ctx context.Context, name string, labels map[string]string, set map[string][]byte, remove []string) (map[string][]byte, error)
+	DeleteSecret(ctx context.Context, name string) error
+}
+
+// SetPluginSecretsRequest is the body of PUT /plugins/:id/secrets. A nil
+// value removes the secret.
+type SetPluginSecretsRequest struct {
+	Secrets map[string]*string `json:"secrets" binding:"required"`
+}
+
+// SetSecretStore sets where plugin secrets are stored. Without one, the
+// secrets endpoints respond 503.
+func (h *PluginHandler) SetSecretStore(store PluginSecretStore) {
+	h.secrets = store
+}
+
+// ListPluginSecrets lists the names of an installed plugin's secrets.
+//
+// Endpoint: GET /api/plugins/:id/secrets
+//
+// HTTP Status Codes:
+//   - 200: Success (keys may be empty)
+//   - 404: Plugin not found
+//   - 503: No secret store configured, or Kubernetes unavailable
+func (h *PluginHandler) ListPluginSecrets(c *gin.Context) {
+	name, ok := h.pluginSecretsTarget(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.secrets.GetSecretData(c.Request.Context(), plugins.PluginSecretName(name))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Failed to read plugin secrets",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plugin": name, "keys": secretKeys(data)})
+}
+
+// SetPluginSecrets sets or removes secrets of an installed plugin.
+//
+// Endpoint: PUT /api/plugins/:id/secrets
+//
+// Secrets not mentioned in the request are left unchanged.
+//
+// HTTP Status Codes:
+//   - 200: Secrets saved
+//   - 400: Invalid request body or secret key
+//   - 403: Caller is not an admin
+//   - 404: Plugin not found
+//   - 503: No secret store configured, or Kubernetes unavailable
+func (h *PluginHandler) SetPluginSecrets(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage plugin secrets"})
+		return
+	}
+
+	var req SetPluginSecretsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+	if len(req.Secrets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No secrets provided"})
+		return
+	}
+
+	set := make(map[string][]byte)
+	var remove []string
+	for key, value := range req.Secrets {
+		if !secretKeyPattern.MatchString(key) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid secret key",
+				"details": "keys may only contain letters, digits, '-', '_' and '.': " + key,
+			})
+			return
+		}
+		if value == nil {
+			remove = append(remove, key)
+		} else {
+			set[key] = []byte(*value)
+		}
+	}
+
+	name, ok := h.pluginSecretsTarget(c)
+	if !ok {
+		return
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "streamspace",
+		"streamspace.io/plugin":        name,
+	}
+	data, err := h.secrets.UpdateSecretData(c.Request.Context(), plugins.PluginSecretName(name), labels, set, remove)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Failed to save plugin secrets",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plugin": name, "keys": secretKeys(data)})
+}
+
+// pluginSecretsTarget returns the name of the installed plugin addressed by
+// the :id parameter, writing an error response and returning false if there
+// is no secret store or no such plugin.
+func (h *PluginHandler) pluginSecretsTarget(c *gin.Context) (string, bool) {
+	if h.secrets == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Plugin secrets unavailable",
+			Message: "No Kubernetes client is configured",
+		})
+		return "", false
+	}
+
+	var name string
+	err := h.db.DB().QueryRow(`SELECT name FROM installed_plugins WHERE id = $1`, c.Param("id")).Scan(&name)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return "", false
+	}
+	return name, true
+}
+
+// secretKeys returns the sorted keys of a Secret's data.
+func secretKeys(data map[string][]byte) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePluginSecretStore struct {
+	data    map[string]map[string][]byte
+	labels  map[string]string
+	deleted []string
+}
+
+func (s *fakePluginSecretStore) GetSecretData(ctx context.Context, name string) (map[string][]byte, error) {
+	return s.data[name], nil
+}
+
+func (s *fakePluginSecretStore) UpdateSecretData(ctx context.Context, name string, labels map[string]string, set map[string][]byte, remove []string) (map[string][]byte, error) {
+	if s.data[name] == nil {
+		s.data[name] = make(map[string][]byte)
+		s.labels = labels
+	}
+	for key, value := range set {
+		s.data[name][key] = value
+	}
+	for _, key := range remove {
+		delete(s.data[name], key)
+	}
+	return s.data[name], nil
+}
+
+func (s *fakePluginSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func setupPluginSecretsTest(t *testing.T, method, body, role string) (*PluginHandler, sqlmock.Sqlmock, *fakePluginSecretStore, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, method, "/plugins/7/secrets", body)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userRole", role)
+
+	handler := NewPluginHandler(database, "")
+	store := &fakePluginSecretStore{data: map[string]map[string][]byte{
+		"plugin-slack-secrets": {"old_token": []byte("old")},
+	}}
+	handler.SetSecretStore(store)
+
+	return handler, mock, store, w, c
+}
+
+func TestSetPluginSecrets_NeverReturnsValues(t *testing.T) {
+	handler, mock, store, w, c := setupPluginSecretsTest(t, http.MethodPut,
+		`{"secrets": {"slack_token": "xoxb-secret", "old_token": null}}`, "admin")
+
+	mock.ExpectQuery(`SELECT name FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slack"))
+
+	handler.SetPluginSecrets(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "xoxb-secret")
+
+	var resp struct {
+		Keys []string `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"slack_token"}, resp.Keys)
+	assert.Equal(t, []byte("xoxb-secret"), store.data["plugin-slack-secrets"]["slack_token"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPluginSecrets_Validation(t *testing.T) {
+	handler, _, _, w, c := setupPluginSecretsTest(t, http.MethodPut, `{"secrets": {"token": "x"}}`, "user")
+	handler.SetPluginSecrets(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	handler, _, _, w, c = setupPluginSecretsTest(t, http.MethodPut, `{"secrets": {"bad key": "x"}}`, "admin")
+	handler.SetPluginSecrets(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListPluginSecrets_NoStore(t *testing.T) {
+	handler, _, _, w, c := setupPluginSecretsTest(t, http.MethodGet, "", "admin")
+	handler.SetSecretStore(nil)
+
+	handler.ListPluginSecrets(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestUninstallPlugin_DeletesSecrets(t *testing.T) {
+	handler, mock, store, w, c := setupPluginSecretsTest(t, http.MethodDelete, "", "admin")
+
+	mock.ExpectQuery(`SELECT name FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slack"))
+	mock.ExpectExec(`DELETE FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM plugin_storage WHERE plugin_name = \$1`).
+		WithArgs("slack").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	handler.UninstallPlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"plugin-slack-secrets"}, store.deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,138 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements listing the scheduled tasks of installed plugins.
+//
+// Plugins schedule periodic tasks with ctx.Scheduler (see
+// plugins/scheduler.go). The plugin runtime records each task's schedule
+// and the time, duration and error of its latest run in
+// plugin_scheduled_tasks; tasks of a loaded plugin also report their next
+// run.
+//
+// API Endpoints:
+// - GET /api/plugins/:id/tasks - List a plugin's scheduled tasks
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+)
+
+// PluginTaskSource lists the tasks loaded plugins have scheduled.
+//
+// *plugins.TaskRegistry implements this interface; it is declared here so
+// the handler can be tested without a plugin runtime.
+type PluginTaskSource interface {
+	ListTasks(pluginName string) []plugins.TaskStatus
+}
+
+// SetTaskSource sets where currently scheduled tasks are read from. Without
+// one, GET /plugins/:id/tasks only reports what is stored in the database.
+func (h *PluginHandler) SetTaskSource(source PluginTaskSource) {
+	h.tasks = source
+}
+
+// ListPluginTasks lists the scheduled tasks of an installed plugin.
+//
+// Endpoint: GET /api/plugins/:id/tasks
+//
+// Tasks recorded in the database that the plugin no longer schedules (for
+// example while it is disabled) are listed with "scheduled": false.
+//
+// Example Response:
+//
+//	{
+//	  "plugin": "streamspace-billing",
+//	  "tasks": [
+//	    {
+//	      "name": "generate-invoices",
+//	      "schedule": "0 0 1 * *",
+//	      "scheduled": true,
+//	      "running": false,
+//	      "nextRunAt": "2025-02-01T00:00:00Z",
+//	      "lastRunAt": "2025-01-01T00:00:00Z",
+//	      "lastDurationMs": 5230,
+//	      "lastError": "stripe: rate limited"
+//	    }
+//	  ]
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Success
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) ListPluginTasks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var name string
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT name FROM installed_plugins WHERE id = $1
+	`, c.Param("id")).Scan(&name)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	rows, err := h.db.DB().QueryContext(ctx, `
+		SELECT task_name, schedule, last_run_at, last_duration_ms, last_error
+		FROM plugin_scheduled_tasks
+		WHERE plugin_name = $1
+	`, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin tasks", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	tasks := make(map[string]plugins.TaskStatus)
+	for rows.Next() {
+		var task plugins.TaskStatus
+		var lastRunAt sql.NullTime
+		var lastDuration sql.NullInt64
+		var lastError sql.NullString
+		if err := rows.Scan(&task.Name, &task.Schedule, &lastRunAt, &lastDuration, &lastError); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin tasks", "details": err.Error()})
+			return
+		}
+		if lastRunAt.Valid {
+			task.LastRunAt = &lastRunAt.Time
+		}
+		task.LastDurationMs = lastDuration.Int64
+		task.LastError = lastError.String
+		tasks[task.Name] = task
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin tasks", "details": err.Error()})
+		return
+	}
+
+	// Scheduled tasks are live; their last run comes from the database
+	// until they have run since the plugin was loaded
+	if h.tasks != nil {
+		for _, live := range h.tasks.ListTasks(name) {
+			if stored, ok := tasks[live.Name]; ok && live.LastRunAt == nil {
+				live.LastRunAt = stored.LastRunAt
+				live.LastDurationMs = stored.LastDurationMs
+				live.LastError = stored.LastError
+			}
+			tasks[live.Name] = live
+		}
+	}
+
+	list := make([]plugins.TaskStatus, 0, len(tasks))
+	for _, task := range tasks {
+		list = append(list, task)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	c.JSON(http.StatusOK, gin.H{
+		"plugin": name,
+		"tasks":  list,
+	})
+}
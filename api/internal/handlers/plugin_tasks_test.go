@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTaskSource map[string][]plugins.TaskStatus
+
+func (f fakeTaskSource) ListTasks(pluginName string) []plugins.TaskStatus {
+	return f[pluginName]
+}
+
+func TestListPluginTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+	next := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	handler.SetTaskSource(fakeTaskSource{"billing": {
+		{Name: "generate-invoices", Schedule: "0 0 1 * *", Scheduled: true, NextRunAt: &next},
+	}})
+
+	lastRun := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT name FROM installed_plugins WHERE id = \$1`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("billing"))
+	mock.ExpectQuery(`SELECT task_name, schedule, last_run_at, last_duration_ms, last_error\s+FROM plugin_scheduled_tasks`).
+		WithArgs("billing").
+		WillReturnRows(sqlmock.NewRows([]string{"task_name", "schedule", "last_run_at", "last_duration_ms", "last_error"}).
+			AddRow("generate-invoices", "0 0 1 * *", lastRun, 5230, "stripe: rate limited").
+			AddRow("calculate-usage", "@hourly", nil, nil, nil))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/plugins/7/tasks", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	handler.ListPluginTasks(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Plugin string               `json:"plugin"`
+		Tasks  []plugins.TaskStatus `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "billing", resp.Plugin)
+	require.Len(t, resp.Tasks, 2)
+
+	// No longer scheduled, kept from the database
+	assert.Equal(t, "calculate-usage", resp.Tasks[0].Name)
+	assert.False(t, resp.Tasks[0].Scheduled)
+	assert.Nil(t, resp.Tasks[0].LastRunAt)
+
+	// Scheduled, with the last run recorded before the plugin was loaded
+	invoices := resp.Tasks[1]
+	assert.True(t, invoices.Scheduled)
+	assert.Equal(t, next, *invoices.NextRunAt)
+	assert.Equal(t, lastRun, *invoices.LastRunAt)
+	assert.Equal(t, int64(5230), invoices.LastDurationMs)
+	assert.Equal(t, "stripe: rate limited", invoices.LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPluginTasks_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+	mock.ExpectQuery(`SELECT name FROM installed_plugins`).
+		WithArgs("99").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/plugins/99/tasks", nil)
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.ListPluginTasks(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
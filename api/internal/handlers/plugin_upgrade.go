@@ -0,0 +1,229 @@
+// Package handlers provides HTTP handlers for the StreamSpace API.
+// This file implements upgrading installed plugins to the catalog version.
+//
+// A repository sync updates catalog_plugins in place, so an installed
+// plugin's catalog entry can advertise a newer version than the one
+// installed. GET /api/plugins reports this as updateAvailable, and the
+// upgrade endpoint moves the installation to the catalog version while
+// keeping its configuration:
+//
+//   - The catalog version must be a newer semantic version
+//   - The existing config is completed with the new manifest's
+//     defaultConfig and validated against its configSchema; an invalid
+//     config leaves the installation untouched
+//   - The replaced version, its config and its manifest are recorded in
+//     plugin_versions_history, from where POST /api/plugins/:id/rollback
+//     can restore them (see plugin_rollback.go)
+//   - The running plugin's OnUpdate hook is called and
+//     "plugin.upgraded" (PluginUpgradedEvent) is emitted
+//
+// API Endpoints:
+// - POST /api/plugins/:id/upgrade - Upgrade an installed plugin to its catalog version
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/middleware"
+	"github.com/streamspace/streamspace/api/internal/models"
+)
+
+// EventPluginUpgraded is emitted after an installed plugin is upgraded.
+const EventPluginUpgraded = "plugin.upgraded"
+
+// PluginUpgradedEvent is the payload of plugin.upgraded.
+type PluginUpgradedEvent struct {
+	PluginID    int    `json:"pluginId"`
+	Plugin      string `json:"plugin"`
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	UpgradedBy  string `json:"upgradedBy"`
+}
+
+// SetEventEmitter sets where plugin.upgraded is emitted.
+func (h *PluginHandler) SetEventEmitter(emitter EventEmitter) {
+	h.emitter = emitter
+}
+
+// UpgradePlugin upgrades an installed plugin to the version in its catalog
+// entry.
+//
+// Endpoint: POST /api/plugins/:id/upgrade
+//
+// Path Parameters:
+//   - id: Installed plugin ID
+//
+// Example Response:
+//
+//	{
+//	  "message": "Plugin upgraded successfully",
+//	  "pluginId": 123,
+//	  "fromVersion": "1.2.3",
+//	  "toVersion": "1.3.0"
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Plugin upgraded
+//   - 400: Config does not match the new configSchema, or a version is not
+//     a semantic version
+//   - 403: Caller is not an admin
+//   - 404: Plugin not found
+//   - 409: Plugin is not in the catalog, or is already at the catalog version
+//   - 500: Database error
+func (h *PluginHandler) UpgradePlugin(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can upgrade plugins"})
+		return
+	}
+
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	var pluginID int
+	var name, installedVersion string
+	var config, installedManifest []byte
+	var catalogVersion, repoURL sql.NullString
+	var manifestJSON []byte
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT ip.id, ip.name, ip.version, ip.config, ip.manifest, cp.version, cp.manifest, r.url
+		FROM installed_plugins ip
+		LEFT JOIN catalog_plugins cp ON ip.catalog_plugin_id = cp.id
+		LEFT JOIN repositories r ON cp.repository_id = r.id
+		WHERE ip.id = $1
+	`, id).Scan(&pluginID, &name, &installedVersion, &config, &installedManifest, &catalogVersion, &manifestJSON, &repoURL)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+	if !catalogVersion.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Plugin " + name + " is not in the catalog"})
+		return
+	}
+
+	installedSemver, err := semver.NewVersion(installedVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Installed version is not a semantic version", "details": err.Error()})
+		return
+	}
+	catalogSemver, err := semver.NewVersion(catalogVersion.String)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Catalog version is not a semantic version", "details": err.Error()})
+		return
+	}
+	if !catalogSemver.GreaterThan(installedSemver) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":            "Plugin is up to date",
+			"installedVersion": installedVersion,
+			"catalogVersion":   catalogVersion.String,
+		})
+		return
+	}
+
+	// Keep the existing config, checked against the new version's schema
+	var manifest models.PluginManifest
+	if len(manifestJSON) > 0 {
+		json.Unmarshal(manifestJSON, &manifest)
+	}
+	newConfig := ApplyPluginConfigDefaults(&manifest, config)
+	if errs := ValidatePluginConfig(&manifest, newConfig); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Existing configuration is not valid for version " + catalogVersion.String,
+			"validationErrors": errs,
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	from := pluginVersion{Version: installedVersion, Config: config, Manifest: installedManifest}
+	to := pluginVersion{Version: catalogVersion.String, Config: newConfig, Manifest: manifestJSON}
+	if err := h.recordUpgrade(ctx, pluginID, name, from, to, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade plugin", "details": err.Error()})
+		return
+	}
+
+	if repoURL.Valid && h.pluginDir != "" {
+		go func() {
+			if err := h.downloadPluginFromRepository(name, repoURL.String); err != nil {
+				log.Printf("[PluginHandler] Warning: Failed to download plugin files for %s@%s: %v", name, catalogVersion.String, err)
+			}
+		}()
+	}
+
+	h.runLifecycleHook(name, "OnUpdate", func(l PluginLifecycle) error {
+		return l.UpdatePlugin(ctx, name, installedVersion, catalogVersion.String)
+	})
+	if h.emitter != nil {
+		h.emitter.EmitEventWithContext(middleware.TraceContext(c), EventPluginUpgraded, &PluginUpgradedEvent{
+			PluginID:    pluginID,
+			Plugin:      name,
+			FromVersion: installedVersion,
+			ToVersion:   catalogVersion.String,
+			UpgradedBy:  userID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Plugin upgraded successfully",
+		"pluginId":    pluginID,
+		"fromVersion": installedVersion,
+		"toVersion":   catalogVersion.String,
+	})
+}
+
+// pluginVersion is a version of an installed plugin with the config and
+// manifest it runs with. A nil Manifest means the catalog's manifest.
+type pluginVersion struct {
+	Version  string
+	Config   []byte
+	Manifest []byte
+}
+
+// recordUpgrade records the replaced version in plugin_versions_history and
+// moves the installation to the new version in one transaction.
+func (h *PluginHandler) recordUpgrade(ctx context.Context, pluginID int, name string, from, to pluginVersion, userID string) error {
+	tx, err := h.db.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO plugin_versions_history (installed_plugin_id, plugin_name, version, config, manifest, replaced_by_version, upgraded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+	`, pluginID, name, from.Version, from.Config, from.Manifest, to.Version, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE installed_plugins SET version = $1, config = $2, manifest = $3, updated_at = NOW() WHERE id = $4
+	`, to.Version, to.Config, to.Manifest, pluginID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateAvailable reports whether catalogVersion is a newer semantic
+// version than installedVersion. Versions that do not parse never offer an
+// update.
+func updateAvailable(installedVersion, catalogVersion string) bool {
+	installed, err := semver.NewVersion(installedVersion)
+	if err != nil {
+		return false
+	}
+	latest, err := semver.NewVersion(catalogVersion)
+	if err != nil {
+		return false
+	}
+	return latest.GreaterThan(installed)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const upgradeManifest = `{
+	"name": "hooks",
+	"version": "1.3.0",
+	"configSchema": {
+		"type": "object",
+		"properties": {
+			"url": {"type": "string"},
+			"retries": {"type": "integer", "default": 3}
+		},
+		"required": ["url"]
+	}
+}`
+
+func setupUpgradeTest(t *testing.T, installedVersion, config string) (*PluginHandler, sqlmock.Sqlmock, *recordingEmitter, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, http.MethodPost, "/plugins/7/upgrade", "")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userID", "admin")
+	c.Set("userRole", "admin")
+
+	handler := NewPluginHandler(database, "")
+	emitter := &recordingEmitter{}
+	handler.SetEventEmitter(emitter)
+
+	mock.ExpectQuery(`SELECT ip.id, ip.name, ip.version, ip.config, ip.manifest, cp.version, cp.manifest, r.url`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "config", "installed_manifest", "catalog_version", "manifest", "url"}).
+			AddRow(7, "hooks", installedVersion, []byte(config), []byte(`{"name":"hooks","version":"1.2.3"}`), "1.3.0", []byte(upgradeManifest), nil))
+
+	return handler, mock, emitter, w, c
+}
+
+func TestUpgradePlugin_Success(t *testing.T) {
+	handler, mock, emitter, w, c := setupUpgradeTest(t, "1.2.3", `{"url":"https://hooks.example.com"}`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO plugin_versions_history`).
+		WithArgs(7, "hooks", "1.2.3", []byte(`{"url":"https://hooks.example.com"}`), []byte(`{"name":"hooks","version":"1.2.3"}`), "1.3.0", "admin").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE installed_plugins SET version = \$1, config = \$2, manifest = \$3`).
+		WithArgs("1.3.0", sqlmock.AnyArg(), []byte(upgradeManifest), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	handler.UpgradePlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"toVersion":"1.3.0"`)
+	assert.Equal(t, []string{EventPluginUpgraded}, emitter.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpgradePlugin_UpToDate(t *testing.T) {
+	handler, mock, emitter, w, c := setupUpgradeTest(t, "1.3.0", `{"url":"https://hooks.example.com"}`)
+
+	handler.UpgradePlugin(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Empty(t, emitter.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpgradePlugin_InvalidConfig(t *testing.T) {
+	handler, mock, emitter, w, c := setupUpgradeTest(t, "1.2.3", `{}`)
+
+	handler.UpgradePlugin(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "validationErrors")
+	assert.Empty(t, emitter.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpgradePlugin_RequiresAdmin(t *testing.T) {
+	database, mock, w, c := newHandlerTest(t, http.MethodPost, "/plugins/7/upgrade", "")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userID", "user1")
+	c.Set("userRole", "user")
+
+	NewPluginHandler(database, "").UpgradePlugin(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	assert.True(t, updateAvailable("1.2.3", "1.3.0"))
+	assert.True(t, updateAvailable("v1.0.0", "1.0.1"))
+	assert.False(t, updateAvailable("1.3.0", "1.3.0"))
+	assert.False(t, updateAvailable("2.0.0", "1.9.9"))
+	assert.False(t, updateAvailable("1.0.0", "latest"))
+	assert.False(t, updateAvailable("1.0.0", ""))
+}
@@ -9,6 +9,9 @@
 //	  GET    /api/plugins/catalog           - Browse available plugins
 //	  GET    /api/plugins/catalog/:id       - Get catalog plugin details
 //	  POST   /api/plugins/catalog/:id/rate  - Rate a plugin (1-5 stars)
+//	  DELETE /api/plugins/catalog/:id/rate  - Delete the current user's rating
+//	  GET    /api/plugins/catalog/:id/ratings - List a plugin's ratings and reviews
+//	  GET    /api/plugins/catalog/:id/ratings/me - Get the current user's rating
 //	  POST   /api/plugins/catalog/:id/install - Install plugin from catalog
 //
 //	Installed Plugins (CRUD):
@@ -18,6 +21,15 @@
 //	  DELETE /api/plugins/:id               - Uninstall plugin
 //	  POST   /api/plugins/:id/enable        - Enable plugin
 //	  POST   /api/plugins/:id/disable       - Disable plugin
+//	  GET    /api/plugins/:id/secrets       - List plugin secret names
+//	  PUT    /api/plugins/:id/secrets       - Set plugin secrets (admin only)
+//	  GET    /api/plugins/:id/deliveries    - List webhook plugin delivery attempts
+//	  GET    /api/plugins/:id/endpoints     - List plugin HTTP endpoints by API version
+//	  PATCH  /api/plugins/:id/active-version - Declare current API version (admin only)
+//	  GET    /api/plugins/:id/health        - Get plugin runtime status
+//	  GET    /api/plugins/:id/tasks         - List plugin scheduled tasks
+//	  POST   /api/plugins/:id/upgrade       - Upgrade plugin to its catalog version (admin only)
+//	  POST   /api/plugins/:id/rollback      - Roll plugin back to its previous version (admin only)
 //
 // Database Tables:
 //
@@ -30,11 +42,21 @@
 //	  - Plugins currently installed
 //	  - References catalog_plugins via catalog_plugin_id
 //	  - Includes enabled status and configuration
+//	  - status/last_error: runtime status (loaded, failed, degraded)
+//	  - manifest: manifest of the installed version (NULL: catalog manifest)
+//
+//	plugin_versions_history:
+//	  - Versions installed plugins were upgraded from, with their config
+//	    and manifest; the newest is restored by a rollback
 //
 //	plugin_ratings:
 //	  - User ratings for catalog plugins (1-5 stars + review)
 //	  - One rating per user per plugin (upsert on conflict)
 //
+//	plugin_install_history:
+//	  - Users who installed each catalog plugin, kept after uninstall
+//	  - Only users listed here may rate the plugin
+//
 //	plugin_stats:
 //	  - Plugin usage statistics (views, installs, last accessed)
 //	  - Updated asynchronously (non-blocking)
@@ -62,12 +84,14 @@
 //
 //	4. User enables/disables plugin:
 //	   POST /api/plugins/123/enable
-//	   (Plugin enabled in database, runtime loads it on next restart/reload)
+//	   (Plugin enabled in database, then the running plugin's OnEnable hook is called)
 package handlers
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -76,13 +100,18 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/middleware"
 	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/streamspace/streamspace/api/internal/plugins"
 )
 
 // PluginHandler handles plugin-related HTTP requests.
@@ -99,8 +128,61 @@ type PluginHandler struct {
 	db *db.Database
 	// pluginDir is the directory where plugins are installed.
 	pluginDir string
+	// lifecycle notifies running plugins of enable/disable/update; nil
+	// until SetPluginLifecycle is called.
+	lifecycle PluginLifecycle
+	// secrets stores plugin secrets as Kubernetes Secrets; nil until
+	// SetSecretStore is called (see plugin_secrets.go).
+	secrets PluginSecretStore
+	// apiRegistry lists loaded plugins' HTTP endpoints; nil until
+	// SetAPIRegistry is called (see plugin_api_versions.go).
+	apiRegistry PluginEndpointRegistry
+	// health reports live plugin status; nil until SetHealthSource is
+	// called (see plugin_health.go).
+	health PluginHealthSource
+	// emitter delivers plugin.upgraded to plugins; nil until
+	// SetEventEmitter is called (see plugin_upgrade.go).
+	emitter EventEmitter
+	// tasks lists loaded plugins' scheduled tasks; nil until SetTaskSource
+	// is called (see plugin_tasks.go).
+	tasks PluginTaskSource
+	// eventPermissions reports loaded plugins' event permissions; nil until
+	// SetEventPermissionSource is called (see plugin_api_versions.go).
+	eventPermissions PluginEventPermissionSource
+}
+
+// PluginLifecycle notifies running plugins of admin changes.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// handler can be tested without a plugin runtime.
+type PluginLifecycle interface {
+	EnablePlugin(ctx context.Context, name string) error
+	DisablePlugin(ctx context.Context, name string) error
+	UpdatePlugin(ctx context.Context, name, oldVersion, newVersion string) error
+	ReloadPlugin(ctx context.Context, name string) error
+	ApplyPluginConfig(ctx context.Context, name string, config map[string]interface{}) (plugins.ConfigReload, error)
 }
 
+// configReloadRestartRequired is reported instead of a plugins.ConfigReload
+// when a new config could not be applied to the running plugin.
+const configReloadRestartRequired = "restart_required"
+
+const (
+	// defaultCatalogPageSize is the catalog page size when only page is given
+	defaultCatalogPageSize = 20
+
+	// maxCatalogPageSize caps the catalog page size a client may request
+	maxCatalogPageSize = 100
+)
+
+// catalogCacheTTL is how long catalog responses are served from the
+// response cache. New ratings and installs show up after at most this long.
+const catalogCacheTTL = 60 * time.Second
+
+// catalogCacheParams are the query parameters the cached catalog endpoints
+// read; other parameters do not create separate cache entries.
+var catalogCacheParams = []string{"category", "type", "search", "sort", "page", "pageSize", "cursor", "limit"}
+
 // NewPluginHandler creates a new plugin handler.
 //
 // Parameters:
@@ -262,6 +344,41 @@ func (h *PluginHandler) downloadFile(url string, targetPath string) error {
 	return err
 }
 
+// SetPluginLifecycle sets where enable/disable/update notifications go.
+func (h *PluginHandler) SetPluginLifecycle(lifecycle PluginLifecycle) {
+	h.lifecycle = lifecycle
+}
+
+// runLifecycleHook calls a plugin lifecycle hook after the database change
+// has been saved. Failures are logged; the change is not rolled back.
+func (h *PluginHandler) runLifecycleHook(name, hook string, fn func(PluginLifecycle) error) {
+	if h.lifecycle == nil {
+		return
+	}
+	if err := fn(h.lifecycle); err != nil {
+		log.Printf("[PluginHandler] %s hook for plugin %s failed: %v", hook, name, err)
+	}
+}
+
+// applyConfig hands a saved config to the running plugin and reports how it
+// was applied (see plugins/config_reload.go), or configReloadRestartRequired
+// if it could not be.
+func (h *PluginHandler) applyConfig(ctx context.Context, name string, rawConfig json.RawMessage) string {
+	if h.lifecycle == nil {
+		return configReloadRestartRequired
+	}
+
+	var config map[string]interface{}
+	json.Unmarshal(rawConfig, &config)
+
+	reload, err := h.lifecycle.ApplyPluginConfig(ctx, name, config)
+	if err != nil {
+		log.Printf("[PluginHandler] Failed to apply new config to plugin %s: %v", name, err)
+		return configReloadRestartRequired
+	}
+	return string(reload)
+}
+
 // RegisterRoutes registers plugin routes to the provided router group.
 //
 // Mounts all plugin endpoints under /plugins prefix:
@@ -280,9 +397,14 @@ func (h *PluginHandler) RegisterRoutes(r *gin.RouterGroup) {
 	plugins := r.Group("/plugins")
 	{
 		// Plugin catalog
-		plugins.GET("/catalog", h.BrowsePluginCatalog)
-		plugins.GET("/catalog/:id", h.GetCatalogPlugin)
+		catalogCache := middleware.CacheResponse(catalogCacheTTL, middleware.CacheKeyWithParams(catalogCacheParams...))
+		plugins.GET("/catalog", catalogCache, h.BrowsePluginCatalog)
+		plugins.GET("/catalog/:id", catalogCache, h.GetCatalogPlugin)
+		plugins.GET("/catalog/:id/config-schema", h.GetPluginConfigSchema)
 		plugins.POST("/catalog/:id/rate", h.RatePlugin)
+		plugins.DELETE("/catalog/:id/rate", h.DeletePluginRating)
+		plugins.GET("/catalog/:id/ratings", h.ListPluginRatings)
+		plugins.GET("/catalog/:id/ratings/me", h.GetMyPluginRating)
 		plugins.POST("/catalog/:id/install", h.InstallPlugin)
 
 		// Installed plugins
@@ -292,6 +414,15 @@ func (h *PluginHandler) RegisterRoutes(r *gin.RouterGroup) {
 		plugins.DELETE("/:id", h.UninstallPlugin)
 		plugins.POST("/:id/enable", h.EnablePlugin)
 		plugins.POST("/:id/disable", h.DisablePlugin)
+		plugins.GET("/:id/secrets", h.ListPluginSecrets)
+		plugins.PUT("/:id/secrets", h.SetPluginSecrets)
+		plugins.GET("/:id/deliveries", h.ListPluginDeliveries)
+		plugins.GET("/:id/endpoints", h.ListPluginEndpoints)
+		plugins.GET("/:id/health", h.GetPluginHealth)
+		plugins.GET("/:id/tasks", h.ListPluginTasks)
+		plugins.PATCH("/:id/active-version", h.SetPluginActiveVersion)
+		plugins.POST("/:id/upgrade", h.UpgradePlugin)
+		plugins.POST("/:id/rollback", h.RollbackPlugin)
 	}
 }
 
@@ -304,14 +435,23 @@ func (h *PluginHandler) RegisterRoutes(r *gin.RouterGroup) {
 //   - type: Filter by plugin type (e.g., "builtin", "community")
 //   - search: Search in display_name, description, tags (case-insensitive)
 //   - sort: Sort order (popular, rating, newest, name) - default: popular
+//   - page, pageSize: Page pagination (see pagination.go). When either is
+//     present, one page in the requested sort order is returned and total is
+//     the number of plugins matching the filters. pageSize defaults to 20,
+//     at most 100.
+//   - cursor, limit: Cursor pagination (see pagination.go). When either is
+//     present, results are ordered newest first and the response includes
+//     nextCursor; only sort=newest may be combined with pagination.
 //
-// Response: JSON with plugins array and total count
+// Response: JSON with plugins array and total count. Each plugin has
+// installed set if a plugin of its name is installed.
 //
 // Example Requests:
 //
 //	GET /api/plugins/catalog?category=analytics&sort=rating
 //	GET /api/plugins/catalog?search=slack&sort=popular
 //	GET /api/plugins/catalog?type=builtin&sort=name
+//	GET /api/plugins/catalog?sort=rating&page=2&pageSize=20
 //
 // Example Response:
 //
@@ -328,20 +468,27 @@ func (h *PluginHandler) RegisterRoutes(r *gin.RouterGroup) {
 //	      "tags": ["analytics", "metrics"],
 //	      "install_count": 1500,
 //	      "avg_rating": 4.5,
-//	      "rating_count": 42
+//	      "rating_count": 42,
+//	      "installed": true
 //	    }
 //	  ],
 //	  "total": 1
 //	}
 //
+// With page pagination the response also has page, pageSize and
+// totalPages.
+//
 // Sorting Options:
 //   - popular: By install count desc, then rating desc
 //   - rating: By average rating desc, then rating count desc
 //   - newest: By created_at desc
 //   - name: By display_name asc
 //
+// Ties are broken by id, so pages don't overlap.
+//
 // HTTP Status Codes:
 //   - 200: Success (may return empty array if no matches)
+//   - 400: Invalid pagination parameters
 //   - 500: Database error
 func (h *PluginHandler) BrowsePluginCatalog(c *gin.Context) {
 	category := c.Query("category")
@@ -349,53 +496,116 @@ func (h *PluginHandler) BrowsePluginCatalog(c *gin.Context) {
 	search := c.Query("search")
 	sortBy := c.DefaultQuery("sort", "popular") // popular, rating, newest, name
 
-	query := `
-		SELECT
-			cp.id, cp.repository_id, cp.name, cp.version, cp.display_name,
-			cp.description, cp.category, cp.plugin_type, cp.icon_url,
-			cp.manifest, cp.tags, cp.install_count, cp.avg_rating, cp.rating_count,
-			cp.created_at, cp.updated_at,
-			r.id as repo_id, r.name as repo_name, r.url as repo_url, r.type as repo_type
-		FROM catalog_plugins cp
-		JOIN repositories r ON cp.repository_id = r.id
-		WHERE 1=1
-	`
+	paginate := wantsCursorPagination(c)
+	offsetPaginate := wantsOffsetPagination(c)
+	if paginate && offsetPaginate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use either page/pageSize or cursor/limit, not both"})
+		return
+	}
+	page, pageSize := 0, 0
+	if offsetPaginate {
+		var err error
+		if page, pageSize, err = parseOffsetPageParams(c, defaultCatalogPageSize, maxCatalogPageSize); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
+	var cursor *pageCursor
+	limit := 0
+	if paginate {
+		if c.Query("sort") != "" && sortBy != "newest" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor pagination is only supported with sort=newest"})
+			return
+		}
+		var err error
+		if cursor, limit, err = parsePageParams(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		sortBy = "newest"
+	}
+
+	// Filters, shared by the page query and the count query
+	where := ` WHERE 1=1`
 	args := []interface{}{}
 	argIndex := 1
 
 	if category != "" {
-		query += ` AND cp.category = $` + strconv.Itoa(argIndex)
+		where += ` AND cp.category = $` + strconv.Itoa(argIndex)
 		args = append(args, category)
 		argIndex++
 	}
 
 	if pluginType != "" {
-		query += ` AND cp.plugin_type = $` + strconv.Itoa(argIndex)
+		where += ` AND cp.plugin_type = $` + strconv.Itoa(argIndex)
 		args = append(args, pluginType)
 		argIndex++
 	}
 
 	if search != "" {
-		query += ` AND (cp.display_name ILIKE $` + strconv.Itoa(argIndex) +
+		where += ` AND (cp.display_name ILIKE $` + strconv.Itoa(argIndex) +
 			` OR cp.description ILIKE $` + strconv.Itoa(argIndex) +
 			` OR $` + strconv.Itoa(argIndex) + ` = ANY(cp.tags))`
 		args = append(args, "%"+search+"%")
 		argIndex++
 	}
 
+	total := 0
+	if offsetPaginate {
+		err := h.db.DB().QueryRow(`
+			SELECT COUNT(*) FROM catalog_plugins cp
+			JOIN repositories r ON cp.repository_id = r.id
+		`+where, args...).Scan(&total)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count plugins", "details": err.Error()})
+			return
+		}
+	}
+
+	query := `
+		SELECT
+			cp.id, cp.repository_id, cp.name, cp.version, cp.display_name,
+			cp.description, cp.category, cp.plugin_type, cp.icon_url,
+			cp.manifest, cp.tags, cp.install_count, cp.avg_rating, cp.rating_count,
+			cp.created_at, cp.updated_at,
+			r.id as repo_id, r.name as repo_name, r.url as repo_url, r.type as repo_type,
+			COALESCE(tc.display_name, cp.category), COALESCE(tc.icon_url, ''),
+			EXISTS (SELECT 1 FROM installed_plugins ip WHERE ip.name = cp.name)
+		FROM catalog_plugins cp
+		JOIN repositories r ON cp.repository_id = r.id
+		LEFT JOIN template_categories tc ON tc.name = cp.category
+	` + where
+
+	if cursor != nil {
+		query += ` AND (cp.created_at, cp.id) < ($` + strconv.Itoa(argIndex) + `, $` + strconv.Itoa(argIndex+1) + `)`
+		args = append(args, cursor.Time, cursor.ID)
+		argIndex += 2
+	}
+
 	// Sorting
 	switch sortBy {
 	case "popular":
-		query += ` ORDER BY cp.install_count DESC, cp.avg_rating DESC`
+		query += ` ORDER BY cp.install_count DESC, cp.avg_rating DESC, cp.id ASC`
 	case "rating":
-		query += ` ORDER BY cp.avg_rating DESC, cp.rating_count DESC`
+		query += ` ORDER BY cp.avg_rating DESC, cp.rating_count DESC, cp.id ASC`
 	case "newest":
-		query += ` ORDER BY cp.created_at DESC`
+		query += ` ORDER BY cp.created_at DESC, cp.id DESC`
 	case "name":
-		query += ` ORDER BY cp.display_name ASC`
+		query += ` ORDER BY cp.display_name ASC, cp.id ASC`
 	default:
-		query += ` ORDER BY cp.install_count DESC`
+		query += ` ORDER BY cp.install_count DESC, cp.id ASC`
+	}
+
+	if paginate {
+		// Fetch one extra row to learn whether another page exists
+		query += ` LIMIT $` + strconv.Itoa(argIndex)
+		args = append(args, limit+1)
+	}
+
+	if offsetPaginate {
+		query += ` LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
+		args = append(args, pageSize, (page-1)*pageSize)
 	}
 
 	rows, err := h.db.DB().Query(query, args...)
@@ -409,14 +619,14 @@ func (h *PluginHandler) BrowsePluginCatalog(c *gin.Context) {
 	for rows.Next() {
 		var plugin models.CatalogPlugin
 		var manifestJSON []byte
-		var tags sql.NullString
 
 		err := rows.Scan(
 			&plugin.ID, &plugin.RepositoryID, &plugin.Name, &plugin.Version,
 			&plugin.DisplayName, &plugin.Description, &plugin.Category, &plugin.PluginType,
-			&plugin.IconURL, &manifestJSON, &tags, &plugin.InstallCount,
+			&plugin.IconURL, &manifestJSON, pq.Array(&plugin.Tags), &plugin.InstallCount,
 			&plugin.AvgRating, &plugin.RatingCount, &plugin.CreatedAt, &plugin.UpdatedAt,
 			&plugin.Repository.ID, &plugin.Repository.Name, &plugin.Repository.URL, &plugin.Repository.Type,
+			&plugin.CategoryDisplayName, &plugin.CategoryIcon, &plugin.Installed,
 		)
 		if err != nil {
 			continue
@@ -427,17 +637,34 @@ func (h *PluginHandler) BrowsePluginCatalog(c *gin.Context) {
 			json.Unmarshal(manifestJSON, &plugin.Manifest)
 		}
 
-		// Parse tags
-		if tags.Valid {
-			// PostgreSQL array format: {tag1,tag2,tag3}
-			tagsStr := tags.String
-			if len(tagsStr) > 2 {
-				tagsStr = tagsStr[1 : len(tagsStr)-1] // Remove { }
-				json.Unmarshal([]byte(`["`+tagsStr+`"]`), &plugin.Tags)
-			}
+		plugins = append(plugins, plugin)
+	}
+
+	if paginate {
+		var nextCursor *string
+		if len(plugins) > limit {
+			plugins = plugins[:limit]
+			last := plugins[len(plugins)-1]
+			next := encodePageCursor(last.CreatedAt, last.ID)
+			nextCursor = &next
 		}
+		c.JSON(http.StatusOK, gin.H{
+			"plugins":    plugins,
+			"total":      len(plugins),
+			"nextCursor": nextCursor,
+		})
+		return
+	}
 
-		plugins = append(plugins, plugin)
+	if offsetPaginate {
+		c.JSON(http.StatusOK, gin.H{
+			"plugins":    plugins,
+			"total":      total,
+			"page":       page,
+			"pageSize":   pageSize,
+			"totalPages": (total + pageSize - 1) / pageSize,
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -479,6 +706,7 @@ func (h *PluginHandler) BrowsePluginCatalog(c *gin.Context) {
 //	  "install_count": 500,
 //	  "avg_rating": 4.8,
 //	  "rating_count": 20,
+//	  "installed": false,
 //	  "repository": {
 //	    "id": 1,
 //	    "name": "official",
@@ -500,7 +728,8 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 			cp.description, cp.category, cp.plugin_type, cp.icon_url,
 			cp.manifest, cp.tags, cp.install_count, cp.avg_rating, cp.rating_count,
 			cp.created_at, cp.updated_at,
-			r.id as repo_id, r.name as repo_name, r.url as repo_url, r.type as repo_type
+			r.id as repo_id, r.name as repo_name, r.url as repo_url, r.type as repo_type,
+			EXISTS (SELECT 1 FROM installed_plugins ip WHERE ip.name = cp.name)
 		FROM catalog_plugins cp
 		JOIN repositories r ON cp.repository_id = r.id
 		WHERE cp.id = $1
@@ -508,14 +737,14 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 
 	var plugin models.CatalogPlugin
 	var manifestJSON []byte
-	var tags sql.NullString
 
 	err := h.db.DB().QueryRow(query, id).Scan(
 		&plugin.ID, &plugin.RepositoryID, &plugin.Name, &plugin.Version,
 		&plugin.DisplayName, &plugin.Description, &plugin.Category, &plugin.PluginType,
-		&plugin.IconURL, &manifestJSON, &tags, &plugin.InstallCount,
+		&plugin.IconURL, &manifestJSON, pq.Array(&plugin.Tags), &plugin.InstallCount,
 		&plugin.AvgRating, &plugin.RatingCount, &plugin.CreatedAt, &plugin.UpdatedAt,
 		&plugin.Repository.ID, &plugin.Repository.Name, &plugin.Repository.URL, &plugin.Repository.Type,
+		&plugin.Installed,
 	)
 
 	if err == sql.ErrNoRows {
@@ -532,15 +761,6 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 		json.Unmarshal(manifestJSON, &plugin.Manifest)
 	}
 
-	// Parse tags
-	if tags.Valid {
-		tagsStr := tags.String
-		if len(tagsStr) > 2 {
-			tagsStr = tagsStr[1 : len(tagsStr)-1]
-			json.Unmarshal([]byte(`["`+tagsStr+`"]`), &plugin.Tags)
-		}
-	}
-
 	// Get view count and update stats
 	go func() {
 		h.db.DB().Exec(`
@@ -556,6 +776,68 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 	c.JSON(http.StatusOK, plugin)
 }
 
+// GetPluginConfigSchema returns a catalog plugin's configuration schema.
+//
+// Endpoint: GET /api/plugins/catalog/:id/config-schema
+//
+// The UI renders the install/settings form from configSchema and pre-fills
+// it with defaultConfig. Plugins without a schema return an empty object.
+//
+// Example Response:
+//
+//	{
+//	  "pluginId": 42,
+//	  "name": "slack-notifications",
+//	  "version": "1.2.3",
+//	  "configSchema": {"type": "object", "properties": {"channel": {"type": "string"}}},
+//	  "defaultConfig": {"channel": "#general"}
+//	}
+//
+// HTTP Status Codes:
+//   - 200: Success
+//   - 404: Plugin not found
+//   - 500: Database error
+func (h *PluginHandler) GetPluginConfigSchema(c *gin.Context) {
+	id := c.Param("id")
+
+	var pluginID int
+	var name, version string
+	var manifestJSON []byte
+	err := h.db.DB().QueryRow(`
+		SELECT id, name, version, manifest FROM catalog_plugins WHERE id = $1
+	`, id).Scan(&pluginID, &name, &version, &manifestJSON)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	var manifest models.PluginManifest
+	if len(manifestJSON) > 0 {
+		json.Unmarshal(manifestJSON, &manifest)
+	}
+
+	configSchema := manifest.ConfigSchema
+	if configSchema == nil {
+		configSchema = map[string]interface{}{}
+	}
+	defaultConfig := manifest.DefaultConfig
+	if defaultConfig == nil {
+		defaultConfig = map[string]interface{}{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pluginId":      pluginID,
+		"name":          name,
+		"version":       version,
+		"configSchema":  configSchema,
+		"defaultConfig": defaultConfig,
+	})
+}
+
 // RatePlugin allows a user to rate a catalog plugin.
 //
 // Endpoint: POST /api/plugins/catalog/:id/rate
@@ -571,9 +853,13 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 //	}
 //
 // Behavior:
+//   - Only users who have installed the plugin (plugin_install_history) may
+//     rate it
 //   - Upserts rating (inserts new or updates existing for this user)
-//   - Updates plugin's avg_rating and rating_count
-//   - user_id extracted from auth middleware (c.GetString("user_id"))
+//   - Updates plugin's avg_rating and rating_count in the same transaction
+//   - userID extracted from auth middleware (c.GetString("userID"))
+//   - Ratings are listed by ListPluginRatings and deleted by
+//     DeletePluginRating (see plugin_ratings.go)
 //
 // Example Request:
 //
@@ -586,10 +872,18 @@ func (h *PluginHandler) GetCatalogPlugin(c *gin.Context) {
 // HTTP Status Codes:
 //   - 200: Rating submitted successfully
 //   - 400: Invalid rating (not 1-5) or invalid request body
+//   - 401: Not authenticated
+//   - 403: The user has never installed the plugin
+//   - 404: Plugin not found
 //   - 500: Database error
 func (h *PluginHandler) RatePlugin(c *gin.Context) {
+	ctx := c.Request.Context()
 	pluginID := c.Param("id")
-	userID := c.GetString("user_id") // From auth middleware
+	userID := c.GetString("userID") // From auth middleware
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
 
 	var req models.RatePluginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -602,27 +896,44 @@ func (h *PluginHandler) RatePlugin(c *gin.Context) {
 		return
 	}
 
+	var installed bool
+	if err := h.db.DB().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM plugin_install_history WHERE catalog_plugin_id = $1 AND user_id = $2)
+	`, pluginID, userID).Scan(&installed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating", "details": err.Error()})
+		return
+	}
+	if !installed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only users who have installed the plugin can rate it"})
+		return
+	}
+
+	tx, err := h.beginRatingChange(ctx, pluginID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating", "details": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+
 	// Insert or update rating
-	_, err := h.db.DB().Exec(`
+	if _, err := tx.ExecContext(ctx, `
 		INSERT INTO plugin_ratings (plugin_id, user_id, rating, review)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (plugin_id, user_id) DO UPDATE
 		SET rating = $3, review = $4, updated_at = NOW()
-	`, pluginID, userID, req.Rating, req.Review)
-
-	if err != nil {
+	`, pluginID, userID, req.Rating, req.Review); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating", "details": err.Error()})
 		return
 	}
 
-	// Update plugin average rating
-	h.db.DB().Exec(`
-		UPDATE catalog_plugins
-		SET avg_rating = (SELECT AVG(rating) FROM plugin_ratings WHERE plugin_id = $1),
-		    rating_count = (SELECT COUNT(*) FROM plugin_ratings WHERE plugin_id = $1),
-		    updated_at = NOW()
-		WHERE id = $1
-	`, pluginID)
+	if err := commitRatingChange(ctx, tx, pluginID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating", "details": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rating submitted successfully"})
 }
@@ -642,15 +953,18 @@ func (h *PluginHandler) RatePlugin(c *gin.Context) {
 //
 // Behavior:
 //   1. Fetches plugin details from catalog_plugins
-//   2. Checks if already installed (returns 409 if yes)
-//   3. Inserts into installed_plugins with enabled=true
-//   4. Increments install count asynchronously
-//   5. Updates plugin_stats table
+//   2. Fills fields missing from config with the manifest's defaultConfig,
+//      then validates it against the configSchema (returns 400 if invalid)
+//   3. Checks if already installed (returns 409 if yes)
+//   4. Inserts into installed_plugins with enabled=true
+//   5. Increments install count asynchronously
+//   6. Updates plugin_stats table
 //
 // Side Effects:
 //   - Plugin install count incremented (async, non-blocking)
 //   - Plugin stats updated with last_installed_at timestamp
-//   - user_id saved as installed_by
+//   - userID saved as installed_by and recorded in plugin_install_history,
+//     which allows the user to rate the plugin
 //
 // Example Request:
 //
@@ -671,12 +985,13 @@ func (h *PluginHandler) RatePlugin(c *gin.Context) {
 //
 // HTTP Status Codes:
 //   - 201: Plugin installed successfully
+//   - 400: Config does not match the plugin's configSchema
 //   - 404: Catalog plugin not found
 //   - 409: Plugin already installed
 //   - 500: Database error
 func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 	catalogPluginID := c.Param("id")
-	userID := c.GetString("user_id")
+	userID := c.GetString("userID")
 
 	var req models.InstallPluginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -712,6 +1027,16 @@ func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 		json.Unmarshal(manifestJSON, &catalogPlugin.Manifest)
 	}
 
+	// Validate config against the plugin's declared schema
+	if len(req.Config) == 0 {
+		req.Config = json.RawMessage("{}")
+	}
+	req.Config = ApplyPluginConfigDefaults(&catalogPlugin.Manifest, req.Config)
+	if errs := ValidatePluginConfig(&catalogPlugin.Manifest, req.Config); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plugin configuration", "validationErrors": errs})
+		return
+	}
+
 	// Check if already installed
 	var existingID int
 	err = h.db.DB().QueryRow(`
@@ -726,16 +1051,25 @@ func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 	// Install plugin
 	var installedID int
 	err = h.db.DB().QueryRow(`
-		INSERT INTO installed_plugins (catalog_plugin_id, name, version, enabled, config, installed_by)
-		VALUES ($1, $2, $3, true, $4, $5)
+		INSERT INTO installed_plugins (catalog_plugin_id, name, version, enabled, config, installed_by, manifest)
+		VALUES ($1, $2, $3, true, $4, $5, $6)
 		RETURNING id
-	`, catalogPlugin.ID, catalogPlugin.Name, catalogPlugin.Version, req.Config, userID).Scan(&installedID)
+	`, catalogPlugin.ID, catalogPlugin.Name, catalogPlugin.Version, req.Config, userID, manifestJSON).Scan(&installedID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to install plugin", "details": err.Error()})
 		return
 	}
 
+	if userID != "" {
+		if _, err := h.db.DB().Exec(`
+			INSERT INTO plugin_install_history (catalog_plugin_id, plugin_name, user_id)
+			VALUES ($1, $2, $3)
+		`, catalogPlugin.ID, catalogPlugin.Name, userID); err != nil {
+			log.Printf("[PluginHandler] Warning: Failed to record install of %s by %s: %v", catalogPlugin.Name, userID, err)
+		}
+	}
+
 	// Download plugin files to local plugins directory
 	if repoURL.Valid && h.pluginDir != "" {
 		go func() {
@@ -777,13 +1111,16 @@ func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 //
 // Query Parameters:
 //   - enabled: Filter by enabled status ("true" for enabled only)
+//   - cursor, limit: Cursor pagination over (installed_at, id), newest
+//     first (see pagination.go). Without either, all plugins are returned.
 //
-// Response: JSON with plugins array and total count
+// Response: JSON with plugins array and total count (plus nextCursor when paginated)
 //
 // Example Requests:
 //
 //	GET /api/plugins              // All installed plugins
 //	GET /api/plugins?enabled=true // Only enabled plugins
+//	GET /api/plugins?limit=20     // First page of 20
 //
 // Example Response:
 //
@@ -801,7 +1138,9 @@ func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 //	      "display_name": "Slack Notifications",
 //	      "description": "...",
 //	      "plugin_type": "community",
-//	      "icon_url": "..."
+//	      "icon_url": "...",
+//	      "catalogVersion": "1.3.0",
+//	      "updateAvailable": true
 //	    }
 //	  ],
 //	  "total": 1
@@ -813,22 +1152,48 @@ func (h *PluginHandler) InstallPlugin(c *gin.Context) {
 func (h *PluginHandler) ListInstalledPlugins(c *gin.Context) {
 	enabledOnly := c.Query("enabled") == "true"
 
+	paginate := wantsCursorPagination(c)
+	var cursor *pageCursor
+	limit := 0
+	if paginate {
+		var err error
+		if cursor, limit, err = parsePageParams(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	query := `
 		SELECT
 			ip.id, ip.catalog_plugin_id, ip.name, ip.version, ip.enabled,
 			ip.config, ip.installed_by, ip.installed_at, ip.updated_at,
-			cp.display_name, cp.description, cp.plugin_type, cp.icon_url, cp.manifest
+			ip.status, ip.last_error,
+			cp.display_name, cp.description, cp.plugin_type, cp.icon_url, cp.manifest, cp.version
 		FROM installed_plugins ip
 		LEFT JOIN catalog_plugins cp ON ip.catalog_plugin_id = cp.id
 	`
 
+	query += ` WHERE 1=1`
+	args := []interface{}{}
+
 	if enabledOnly {
-		query += ` WHERE ip.enabled = true`
+		query += ` AND ip.enabled = true`
+	}
+
+	if cursor != nil {
+		args = append(args, cursor.Time, cursor.ID)
+		query += ` AND (ip.installed_at, ip.id) < ($1, $2)`
 	}
 
-	query += ` ORDER BY ip.installed_at DESC`
+	query += ` ORDER BY ip.installed_at DESC, ip.id DESC`
 
-	rows, err := h.db.DB().Query(query)
+	if paginate {
+		// Fetch one extra row to learn whether another page exists
+		args = append(args, limit+1)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := h.db.DB().Query(query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugins", "details": err.Error()})
 		return
@@ -839,13 +1204,15 @@ func (h *PluginHandler) ListInstalledPlugins(c *gin.Context) {
 	for rows.Next() {
 		var plugin models.InstalledPlugin
 		var catalogPluginID sql.NullInt64
-		var displayName, description, pluginType, iconURL sql.NullString
+		var displayName, description, pluginType, iconURL, catalogVersion sql.NullString
+		var status, lastError sql.NullString
 		var manifestJSON []byte
 
 		err := rows.Scan(
 			&plugin.ID, &catalogPluginID, &plugin.Name, &plugin.Version, &plugin.Enabled,
 			&plugin.Config, &plugin.InstalledBy, &plugin.InstalledAt, &plugin.UpdatedAt,
-			&displayName, &description, &pluginType, &iconURL, &manifestJSON,
+			&status, &lastError,
+			&displayName, &description, &pluginType, &iconURL, &manifestJSON, &catalogVersion,
 		)
 		if err != nil {
 			continue
@@ -868,6 +1235,10 @@ func (h *PluginHandler) ListInstalledPlugins(c *gin.Context) {
 		if iconURL.Valid {
 			plugin.IconURL = iconURL.String
 		}
+		plugin.Status = status.String
+		plugin.LastError = lastError.String
+		plugin.CatalogVersion = catalogVersion.String
+		plugin.UpdateAvailable = updateAvailable(plugin.Version, catalogVersion.String)
 
 		if len(manifestJSON) > 0 {
 			var manifest models.PluginManifest
@@ -879,6 +1250,22 @@ func (h *PluginHandler) ListInstalledPlugins(c *gin.Context) {
 		plugins = append(plugins, plugin)
 	}
 
+	if paginate {
+		var nextCursor *string
+		if len(plugins) > limit {
+			plugins = plugins[:limit]
+			last := plugins[len(plugins)-1]
+			next := encodePageCursor(last.InstalledAt, last.ID)
+			nextCursor = &next
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"plugins":    plugins,
+			"total":      len(plugins),
+			"nextCursor": nextCursor,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"plugins": plugins,
 		"total":   len(plugins),
@@ -909,7 +1296,8 @@ func (h *PluginHandler) GetInstalledPlugin(c *gin.Context) {
 		SELECT
 			ip.id, ip.catalog_plugin_id, ip.name, ip.version, ip.enabled,
 			ip.config, ip.installed_by, ip.installed_at, ip.updated_at,
-			cp.display_name, cp.description, cp.plugin_type, cp.icon_url, cp.manifest
+			ip.status, ip.last_error,
+			cp.display_name, cp.description, cp.plugin_type, cp.icon_url, cp.manifest, cp.version
 		FROM installed_plugins ip
 		LEFT JOIN catalog_plugins cp ON ip.catalog_plugin_id = cp.id
 		WHERE ip.id = $1
@@ -917,13 +1305,15 @@ func (h *PluginHandler) GetInstalledPlugin(c *gin.Context) {
 
 	var plugin models.InstalledPlugin
 	var catalogPluginID sql.NullInt64
-	var displayName, description, pluginType, iconURL sql.NullString
+	var displayName, description, pluginType, iconURL, catalogVersion sql.NullString
+	var status, lastError sql.NullString
 	var manifestJSON []byte
 
 	err := h.db.DB().QueryRow(query, id).Scan(
 		&plugin.ID, &catalogPluginID, &plugin.Name, &plugin.Version, &plugin.Enabled,
 		&plugin.Config, &plugin.InstalledBy, &plugin.InstalledAt, &plugin.UpdatedAt,
-		&displayName, &description, &pluginType, &iconURL, &manifestJSON,
+		&status, &lastError,
+		&displayName, &description, &pluginType, &iconURL, &manifestJSON, &catalogVersion,
 	)
 
 	if err == sql.ErrNoRows {
@@ -952,6 +1342,10 @@ func (h *PluginHandler) GetInstalledPlugin(c *gin.Context) {
 	if iconURL.Valid {
 		plugin.IconURL = iconURL.String
 	}
+	plugin.Status = status.String
+	plugin.LastError = lastError.String
+	plugin.CatalogVersion = catalogVersion.String
+	plugin.UpdateAvailable = updateAvailable(plugin.Version, catalogVersion.String)
 
 	if len(manifestJSON) > 0 {
 		var manifest models.PluginManifest
@@ -974,12 +1368,20 @@ func (h *PluginHandler) GetInstalledPlugin(c *gin.Context) {
 //
 //	{
 //	  "enabled": true,                // Enable/disable plugin
-//	  "config": {"api_key": "new..."}  // Update configuration
+//	  "config": {"api_key": "new..."}, // Update configuration
+//	  "version": "1.3.0"               // Record an upgraded version
 //	}
 //
 // Behavior:
 //   - Only provided fields are updated
+//   - config is completed with the manifest's defaultConfig, then validated
+//     against its configSchema
 //   - updated_at timestamp automatically set
+//   - After saving, the running plugin's OnEnable/OnDisable hook is called
+//     if enabled was provided, then OnUpdate with the old and new versions
+//   - A new config is applied to the running plugin without a restart: via
+//     its OnConfigChange hook, or by disabling and re-enabling it. The
+//     response's configReload and restartRequired report the outcome
 //
 // Example Request:
 //
@@ -988,9 +1390,17 @@ func (h *PluginHandler) GetInstalledPlugin(c *gin.Context) {
 //	  "config": {"webhook_url": "https://new-url.com"}
 //	}
 //
+// Example Response:
+//
+//	{
+//	  "message": "Plugin updated successfully",
+//	  "configReload": "live",     // live, cycled, deferred or restart_required
+//	  "restartRequired": false
+//	}
+//
 // HTTP Status Codes:
 //   - 200: Plugin updated successfully
-//   - 400: Invalid request body
+//   - 400: Invalid request body or config does not match configSchema
 //   - 404: Plugin not found
 //   - 500: Database error
 func (h *PluginHandler) UpdateInstalledPlugin(c *gin.Context) {
@@ -1002,6 +1412,38 @@ func (h *PluginHandler) UpdateInstalledPlugin(c *gin.Context) {
 		return
 	}
 
+	// Load the current installation (the hooks need its name and version)
+	var name, oldVersion string
+	var manifestJSON []byte
+	err := h.db.DB().QueryRow(`
+		SELECT ip.name, ip.version, COALESCE(ip.manifest, cp.manifest)
+		FROM installed_plugins ip
+		LEFT JOIN catalog_plugins cp ON ip.catalog_plugin_id = cp.id
+		WHERE ip.id = $1
+	`, id).Scan(&name, &oldVersion, &manifestJSON)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plugin", "details": err.Error()})
+		return
+	}
+
+	if req.Config != nil {
+		// Re-validate against the manifest of the currently installed plugin
+		var manifest models.PluginManifest
+		if len(manifestJSON) > 0 {
+			json.Unmarshal(manifestJSON, &manifest)
+		}
+
+		req.Config = ApplyPluginConfigDefaults(&manifest, req.Config)
+		if errs := ValidatePluginConfig(&manifest, req.Config); len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plugin configuration", "validationErrors": errs})
+			return
+		}
+	}
+
 	query := `UPDATE installed_plugins SET `
 	args := []interface{}{}
 	argIndex := 1
@@ -1018,6 +1460,14 @@ func (h *PluginHandler) UpdateInstalledPlugin(c *gin.Context) {
 		argIndex++
 	}
 
+	newVersion := oldVersion
+	if req.Version != "" {
+		query += `version = $` + strconv.Itoa(argIndex) + `, `
+		args = append(args, req.Version)
+		argIndex++
+		newVersion = req.Version
+	}
+
 	query += `updated_at = NOW() WHERE id = $` + strconv.Itoa(argIndex)
 	args = append(args, id)
 
@@ -1033,7 +1483,21 @@ func (h *PluginHandler) UpdateInstalledPlugin(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Plugin updated successfully"})
+	ctx := c.Request.Context()
+	if req.Enabled != nil && *req.Enabled {
+		h.runLifecycleHook(name, "OnEnable", func(l PluginLifecycle) error { return l.EnablePlugin(ctx, name) })
+	} else if req.Enabled != nil {
+		h.runLifecycleHook(name, "OnDisable", func(l PluginLifecycle) error { return l.DisablePlugin(ctx, name) })
+	}
+	response := gin.H{"message": "Plugin updated successfully"}
+	if req.Config != nil {
+		reload := h.applyConfig(ctx, name, req.Config)
+		response["configReload"] = reload
+		response["restartRequired"] = reload == configReloadRestartRequired
+	}
+	h.runLifecycleHook(name, "OnUpdate", func(l PluginLifecycle) error { return l.UpdatePlugin(ctx, name, oldVersion, newVersion) })
+
+	c.JSON(http.StatusOK, response)
 }
 
 // UninstallPlugin removes a plugin from the system.
@@ -1045,9 +1509,11 @@ func (h *PluginHandler) UpdateInstalledPlugin(c *gin.Context) {
 //
 // Behavior:
 //   - Deletes plugin from installed_plugins table
+//   - Deletes the plugin's plugin_storage keys
+//   - Deletes the plugin's Kubernetes Secret (plugin-{name}-secrets)
 //   - Plugin runtime should unload the plugin
 //
-// WARNING: This does not clean up plugin data tables or configuration.
+// WARNING: This does not clean up plugin data tables (PluginDatabase).
 // Plugin should implement cleanup in OnUnload hook.
 //
 // Example Request:
@@ -1086,6 +1552,18 @@ func (h *PluginHandler) UninstallPlugin(c *gin.Context) {
 		return
 	}
 
+	// Drop the plugin's key-value storage (see plugins.PluginStorage)
+	if _, err := h.db.DB().Exec(`DELETE FROM plugin_storage WHERE plugin_name = $1`, pluginName); err != nil {
+		log.Printf("[PluginHandler] Warning: Failed to clear plugin storage for %s: %v", pluginName, err)
+	}
+
+	// Delete the plugin's secrets (see plugin_secrets.go)
+	if h.secrets != nil {
+		if err := h.secrets.DeleteSecret(c.Request.Context(), plugins.PluginSecretName(pluginName)); err != nil {
+			log.Printf("[PluginHandler] Warning: Failed to delete plugin secrets for %s: %v", pluginName, err)
+		}
+	}
+
 	// Remove plugin files from plugins directory
 	if h.pluginDir != "" && pluginName != "" {
 		pluginPath := filepath.Join(h.pluginDir, pluginName)
@@ -1108,7 +1586,8 @@ func (h *PluginHandler) UninstallPlugin(c *gin.Context) {
 //
 // Behavior:
 //   - Sets enabled=true in database
-//   - Plugin runtime should load the plugin on next startup/reload
+//   - Then calls the plugin's OnEnable hook, loading it first if needed
+//     (hook failures are logged, the plugin stays enabled)
 //
 // Example Request:
 //
@@ -1121,22 +1600,25 @@ func (h *PluginHandler) UninstallPlugin(c *gin.Context) {
 func (h *PluginHandler) EnablePlugin(c *gin.Context) {
 	id := c.Param("id")
 
-	result, err := h.db.DB().Exec(`
+	var name string
+	err := h.db.DB().QueryRow(`
 		UPDATE installed_plugins
 		SET enabled = true, updated_at = NOW()
 		WHERE id = $1
-	`, id)
+		RETURNING name
+	`, id).Scan(&name)
 
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable plugin", "details": err.Error()})
 		return
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
-		return
-	}
+	ctx := c.Request.Context()
+	h.runLifecycleHook(name, "OnEnable", func(l PluginLifecycle) error { return l.EnablePlugin(ctx, name) })
 
 	c.JSON(http.StatusOK, gin.H{"message": "Plugin enabled successfully"})
 }
@@ -1150,7 +1632,9 @@ func (h *PluginHandler) EnablePlugin(c *gin.Context) {
 //
 // Behavior:
 //   - Sets enabled=false in database
-//   - Plugin runtime should unload the plugin on next reload
+//   - Then calls the running plugin's OnDisable hook and stops delivering
+//     platform events to it (hook failures are logged, the plugin stays
+//     disabled)
 //
 // Example Request:
 //
@@ -1163,22 +1647,141 @@ func (h *PluginHandler) EnablePlugin(c *gin.Context) {
 func (h *PluginHandler) DisablePlugin(c *gin.Context) {
 	id := c.Param("id")
 
-	result, err := h.db.DB().Exec(`
+	var name string
+	err := h.db.DB().QueryRow(`
 		UPDATE installed_plugins
 		SET enabled = false, updated_at = NOW()
 		WHERE id = $1
-	`, id)
+		RETURNING name
+	`, id).Scan(&name)
 
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable plugin", "details": err.Error()})
 		return
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Plugin not found"})
-		return
-	}
+	ctx := c.Request.Context()
+	h.runLifecycleHook(name, "OnDisable", func(l PluginLifecycle) error { return l.DisablePlugin(ctx, name) })
 
 	c.JSON(http.StatusOK, gin.H{"message": "Plugin disabled successfully"})
 }
+
+// pluginConfigSchemaURL is the resource name the config schema is compiled
+// under; relative "$ref"s within the schema resolve against it.
+const pluginConfigSchemaURL = "plugin-config.schema.json"
+
+// ValidatePluginConfig validates config against manifest.ConfigSchema.
+//
+// Returns one message per failing value in the form "<json pointer>: <reason>"
+// (sorted by path), or nil if the config is valid or the manifest declares
+// no schema. Local "$ref"s (e.g. "#/definitions/channel") are resolved
+// within the schema; remote references are rejected so a catalog manifest
+// cannot make the API fetch arbitrary URLs.
+//
+// Example:
+//
+//	if errs := ValidatePluginConfig(&plugin.Manifest, req.Config); len(errs) > 0 {
+//	    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plugin configuration", "validationErrors": errs})
+//	    return
+//	}
+func ValidatePluginConfig(manifest *models.PluginManifest, config json.RawMessage) []string {
+	if manifest == nil || len(manifest.ConfigSchema) == 0 {
+		return nil
+	}
+
+	schemaJSON, err := json.Marshal(manifest.ConfigSchema)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid configSchema: %v", err)}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("remote schema references are not allowed: %s", url)
+	}
+	if err := compiler.AddResource(pluginConfigSchemaURL, bytes.NewReader(schemaJSON)); err != nil {
+		return []string{fmt.Sprintf("invalid configSchema: %v", err)}
+	}
+	schema, err := compiler.Compile(pluginConfigSchemaURL)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid configSchema: %v", err)}
+	}
+
+	if len(config) == 0 {
+		config = json.RawMessage("{}")
+	}
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return []string{fmt.Sprintf("config is not valid JSON: %v", err)}
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	var errs []string
+	collectConfigErrors(ve, &errs)
+	sort.Strings(errs)
+	return errs
+}
+
+// ApplyPluginConfigDefaults returns config with every top-level field of
+// manifest.DefaultConfig that config does not set.
+//
+// Fields present in config, even as null, are kept. config is returned
+// unchanged when the manifest has no defaults or config is not a JSON
+// object (ValidatePluginConfig reports the latter).
+func ApplyPluginConfigDefaults(manifest *models.PluginManifest, config json.RawMessage) json.RawMessage {
+	if manifest == nil || len(manifest.DefaultConfig) == 0 {
+		return config
+	}
+
+	fields := map[string]json.RawMessage{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &fields); err != nil || fields == nil {
+			return config
+		}
+	}
+
+	for key, value := range manifest.DefaultConfig {
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		fields[key] = encoded
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return config
+	}
+	return merged
+}
+
+// collectConfigErrors flattens a validation error tree into its leaf messages.
+func collectConfigErrors(ve *jsonschema.ValidationError, out *[]string) {
+	if len(ve.Causes) == 0 {
+		path := ve.InstanceLocation
+		if path == "" {
+			path = "/"
+		}
+		*out = append(*out, fmt.Sprintf("%s: %s", path, ve.Message))
+		return
+	}
+	for _, cause := range ve.Causes {
+		collectConfigErrors(cause, out)
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/models"
+	"github.com/streamspace/streamspace/api/internal/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func configSchemaManifest(t *testing.T, schema string) *models.PluginManifest {
+	t.Helper()
+	manifest := &models.PluginManifest{}
+	require.NoError(t, json.Unmarshal([]byte(schema), &manifest.ConfigSchema))
+	return manifest
+}
+
+func TestValidatePluginConfig_NoSchema(t *testing.T) {
+	assert.Nil(t, ValidatePluginConfig(&models.PluginManifest{}, json.RawMessage(`{"anything": 1}`)))
+	assert.Nil(t, ValidatePluginConfig(nil, nil))
+}
+
+func TestValidatePluginConfig_Valid(t *testing.T) {
+	manifest := configSchemaManifest(t, `{
+		"type": "object",
+		"required": ["webhook_url"],
+		"properties": {
+			"webhook_url": {"type": "string"},
+			"retries": {"type": "integer", "minimum": 0}
+		}
+	}`)
+
+	errs := ValidatePluginConfig(manifest, json.RawMessage(`{"webhook_url": "https://hooks.example.com", "retries": 3}`))
+	assert.Empty(t, errs)
+}
+
+func TestValidatePluginConfig_ReportsErrors(t *testing.T) {
+	manifest := configSchemaManifest(t, `{
+		"type": "object",
+		"required": ["webhook_url"],
+		"properties": {
+			"webhook_url": {"type": "string"},
+			"retries": {"type": "integer", "minimum": 0}
+		}
+	}`)
+
+	errs := ValidatePluginConfig(manifest, json.RawMessage(`{"retries": -1}`))
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0], "/: missing properties")
+	assert.Contains(t, errs[1], "/retries:")
+}
+
+func TestValidatePluginConfig_LocalRef(t *testing.T) {
+	manifest := configSchemaManifest(t, `{
+		"type": "object",
+		"definitions": {
+			"channel": {"type": "string", "pattern": "^#"}
+		},
+		"properties": {
+			"channel": {"$ref": "#/definitions/channel"}
+		}
+	}`)
+
+	assert.Empty(t, ValidatePluginConfig(manifest, json.RawMessage(`{"channel": "#general"}`)))
+
+	errs := ValidatePluginConfig(manifest, json.RawMessage(`{"channel": "general"}`))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "/channel:")
+}
+
+func TestValidatePluginConfig_RemoteRefRejected(t *testing.T) {
+	manifest := configSchemaManifest(t, `{
+		"properties": {
+			"channel": {"$ref": "https://example.com/schemas/channel.json"}
+		}
+	}`)
+
+	errs := ValidatePluginConfig(manifest, json.RawMessage(`{"channel": "#general"}`))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "invalid configSchema")
+}
+
+func TestApplyPluginConfigDefaults(t *testing.T) {
+	manifest := &models.PluginManifest{DefaultConfig: map[string]interface{}{
+		"channel": "#general",
+		"retries": 3,
+	}}
+
+	merged := ApplyPluginConfigDefaults(manifest, json.RawMessage(`{"channel": "#ops", "webhook_url": null}`))
+	assert.JSONEq(t, `{"channel": "#ops", "retries": 3, "webhook_url": null}`, string(merged))
+
+	assert.JSONEq(t, `{"channel": "#general", "retries": 3}`, string(ApplyPluginConfigDefaults(manifest, nil)))
+
+	// Non-objects are left for validation to reject
+	assert.Equal(t, `[1]`, string(ApplyPluginConfigDefaults(manifest, json.RawMessage(`[1]`))))
+	assert.Equal(t, `{"a":1}`, string(ApplyPluginConfigDefaults(&models.PluginManifest{}, json.RawMessage(`{"a":1}`))))
+}
+
+func TestGetPluginConfigSchema(t *testing.T) {
+	handler, mock, _, w, c := setupPluginLifecycleTest(t, "GET", "")
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	mock.ExpectQuery("SELECT id, name, version, manifest FROM catalog_plugins").
+		WithArgs("42").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "manifest"}).
+			AddRow(42, "slack", "1.2.3", []byte(`{"configSchema": {"type": "object"}, "defaultConfig": {"channel": "#general"}}`)))
+
+	handler.GetPluginConfigSchema(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"pluginId": 42,
+		"name": "slack",
+		"version": "1.2.3",
+		"configSchema": {"type": "object"},
+		"defaultConfig": {"channel": "#general"}
+	}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPluginConfigSchema_NotFound(t *testing.T) {
+	handler, mock, _, w, c := setupPluginLifecycleTest(t, "GET", "")
+
+	mock.ExpectQuery("SELECT id, name, version, manifest FROM catalog_plugins").
+		WillReturnError(sql.ErrNoRows)
+
+	handler.GetPluginConfigSchema(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type recordingLifecycle struct {
+	calls []string
+	err   error
+}
+
+func (l *recordingLifecycle) EnablePlugin(ctx context.Context, name string) error {
+	l.calls = append(l.calls, "enable "+name)
+	return l.err
+}
+
+func (l *recordingLifecycle) DisablePlugin(ctx context.Context, name string) error {
+	l.calls = append(l.calls, "disable "+name)
+	return l.err
+}
+
+func (l *recordingLifecycle) UpdatePlugin(ctx context.Context, name, oldVersion, newVersion string) error {
+	l.calls = append(l.calls, "update "+name+" "+oldVersion+"->"+newVersion)
+	return l.err
+}
+
+func (l *recordingLifecycle) ReloadPlugin(ctx context.Context, name string) error {
+	l.calls = append(l.calls, "reload "+name)
+	return l.err
+}
+
+func (l *recordingLifecycle) ApplyPluginConfig(ctx context.Context, name string, config map[string]interface{}) (plugins.ConfigReload, error) {
+	l.calls = append(l.calls, fmt.Sprintf("config %s %v", name, config))
+	return plugins.ConfigReloadLive, l.err
+}
+
+func setupPluginLifecycleTest(t *testing.T, method, body string) (*PluginHandler, sqlmock.Sqlmock, *recordingLifecycle, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, method, "/plugins/7", body)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	handler := NewPluginHandler(database, "")
+	lifecycle := &recordingLifecycle{}
+	handler.SetPluginLifecycle(lifecycle)
+
+	return handler, mock, lifecycle, w, c
+}
+
+func TestEnablePlugin_CallsHookAfterSaving(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+	lifecycle.err = errors.New("hook failed")
+
+	mock.ExpectQuery(`UPDATE installed_plugins\s+SET enabled = true`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slack"))
+
+	handler.EnablePlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code, "hook failures do not fail the request")
+	assert.Equal(t, []string{"enable slack"}, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisablePlugin_NotFound(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPost, "")
+
+	mock.ExpectQuery(`UPDATE installed_plugins\s+SET enabled = false`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	handler.DisablePlugin(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInstalledPlugin_CallsHooks(t *testing.T) {
+	handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPatch, `{"enabled":false,"version":"2.0.0"}`)
+
+	mock.ExpectQuery(`SELECT ip.name, ip.version, COALESCE\(ip.manifest, cp.manifest\)`).
+		WithArgs("7").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "version", "manifest"}).AddRow("slack", "1.4.0", nil))
+	mock.ExpectExec(`UPDATE installed_plugins SET enabled = \$1, version = \$2, updated_at = NOW\(\) WHERE id = \$3`).
+		WithArgs(false, "2.0.0", "7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	handler.UpdateInstalledPlugin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"disable slack", "update slack 1.4.0->2.0.0"}, lifecycle.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInstalledPlugin_AppliesConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		hookErr         error
+		wantReload      string
+		wantRestartFlag bool
+	}{
+		{name: "applied live", wantReload: "live"},
+		{name: "hook failed", hookErr: errors.New("bad webhook"), wantReload: "restart_required", wantRestartFlag: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mock, lifecycle, w, c := setupPluginLifecycleTest(t, http.MethodPatch, `{"config":{"url":"https://new.example.com"}}`)
+			lifecycle.err = tt.hookErr
+
+			mock.ExpectQuery(`SELECT ip.name, ip.version, COALESCE\(ip.manifest, cp.manifest\)`).
+				WithArgs("7").
+				WillReturnRows(sqlmock.NewRows([]string{"name", "version", "manifest"}).AddRow("hooks", "1.0.0", nil))
+			mock.ExpectExec(`UPDATE installed_plugins SET config = \$1, updated_at = NOW\(\) WHERE id = \$2`).
+				WithArgs(sqlmock.AnyArg(), "7").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			handler.UpdateInstalledPlugin(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantReload, resp["configReload"])
+			assert.Equal(t, tt.wantRestartFlag, resp["restartRequired"])
+			assert.Equal(t, []string{"config hooks map[url:https://new.example.com]", "update hooks 1.0.0->1.0.0"}, lifecycle.calls)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// tagsArray is wantTags as PostgreSQL returns a TEXT[] column.
+const tagsArray = `{ci/cd,"\"quoted\"","machine learning","a,b"}`
+
+var wantTags = []string{"ci/cd", `"quoted"`, "machine learning", "a,b"}
+
+func TestCatalogPlugin_ParsesTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	row := []driver.Value{
+		5, 1, "ci-tools", "1.0.0", "CI Tools", "", "ci", "extension", "",
+		nil, tagsArray, 0, 0.0, 0, now, now, 1, "official", "https://plugins.example.com", "official",
+	}
+	columns := []string{
+		"id", "repository_id", "name", "version", "display_name",
+		"description", "category", "plugin_type", "icon_url",
+		"manifest", "tags", "install_count", "avg_rating", "rating_count",
+		"created_at", "updated_at",
+		"repo_id", "repo_name", "repo_url", "repo_type",
+	}
+
+	t.Run("browse", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+		handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+
+		mock.ExpectQuery(`FROM catalog_plugins cp`).
+			WillReturnRows(sqlmock.NewRows(append(columns, "category_display_name", "category_icon", "installed")).
+				AddRow(append(row, "CI", "", false)...))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/plugins/catalog", nil)
+
+		handler.BrowsePluginCatalog(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Plugins []models.CatalogPlugin `json:"plugins"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Plugins, 1)
+		assert.Equal(t, wantTags, resp.Plugins[0].Tags)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+		handler := NewPluginHandler(db.NewDatabaseFromDB(mockDB), "")
+
+		mock.ExpectQuery(`FROM catalog_plugins cp`).
+			WithArgs("5").
+			WillReturnRows(sqlmock.NewRows(append(columns, "installed")).AddRow(append(row, true)...))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/plugins/catalog/5", nil)
+		c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+		handler.GetCatalogPlugin(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var plugin models.CatalogPlugin
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plugin))
+		assert.Equal(t, wantTags, plugin.Tags)
+	})
+}
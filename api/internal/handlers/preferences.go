@@ -544,7 +544,7 @@ func (h *PreferencesHandler) GetRecentSessions(c *gin.Context) {
 	rows, err := h.db.DB().QueryContext(ctx, `
 		SELECT id, template_name, state, created_at
 		FROM sessions
-		WHERE user_id = $1
+		WHERE archived_at IS NULL AND user_id = $1
 		ORDER BY created_at DESC
 		LIMIT 10
 	`, userIDStr)
@@ -277,7 +277,7 @@ func (h *QuotasHandler) GetUserUsage(c *gin.Context) {
 	var activeSessions int
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting', 'pending')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting', 'pending')
 	`, userID).Scan(&activeSessions)
 
 	// Sum allocated resources
@@ -287,7 +287,7 @@ func (h *QuotasHandler) GetUserUsage(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::int), 0),
 			COALESCE(SUM((resources->>'memory')::int), 0)
 		FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting')
 		AND resources IS NOT NULL
 	`, userID).Scan(&totalCPU, &totalMemory)
 
@@ -349,7 +349,7 @@ func (h *QuotasHandler) GetUserQuotaStatus(c *gin.Context) {
 	var activeSessions int
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting', 'pending')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting', 'pending')
 	`, userID).Scan(&activeSessions)
 
 	var totalCPU, totalMemory int
@@ -358,7 +358,7 @@ func (h *QuotasHandler) GetUserQuotaStatus(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::int), 0),
 			COALESCE(SUM((resources->>'memory')::int), 0)
 		FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting')
 		AND resources IS NOT NULL
 	`, userID).Scan(&totalCPU, &totalMemory)
 
@@ -605,7 +605,7 @@ func (h *QuotasHandler) GetTeamUsage(c *gin.Context) {
 	var activeSessions int
 	query := fmt.Sprintf(`
 		SELECT COUNT(*) FROM sessions
-		WHERE user_id IN (%s) AND state IN ('running', 'starting', 'pending')
+		WHERE archived_at IS NULL AND user_id IN (%s) AND state IN ('running', 'starting', 'pending')
 	`, placeholders)
 	h.db.DB().QueryRowContext(ctx, query, args...).Scan(&activeSessions)
 
@@ -616,7 +616,7 @@ func (h *QuotasHandler) GetTeamUsage(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::int), 0),
 			COALESCE(SUM((resources->>'memory')::int), 0)
 		FROM sessions
-		WHERE user_id IN (%s) AND state IN ('running', 'starting')
+		WHERE archived_at IS NULL AND user_id IN (%s) AND state IN ('running', 'starting')
 		AND resources IS NOT NULL
 	`, placeholders)
 	h.db.DB().QueryRowContext(ctx, query, args...).Scan(&totalCPU, &totalMemory)
@@ -709,7 +709,7 @@ func (h *QuotasHandler) GetTeamQuotaStatus(c *gin.Context) {
 
 		query := fmt.Sprintf(`
 			SELECT COUNT(*) FROM sessions
-			WHERE user_id IN (%s) AND state IN ('running', 'starting', 'pending')
+			WHERE archived_at IS NULL AND user_id IN (%s) AND state IN ('running', 'starting', 'pending')
 		`, placeholders)
 		h.db.DB().QueryRowContext(ctx, query, args...).Scan(&activeSessions)
 
@@ -718,7 +718,7 @@ func (h *QuotasHandler) GetTeamQuotaStatus(c *gin.Context) {
 				COALESCE(SUM((resources->>'cpu')::int), 0),
 				COALESCE(SUM((resources->>'memory')::int), 0)
 			FROM sessions
-			WHERE user_id IN (%s) AND state IN ('running', 'starting')
+			WHERE archived_at IS NULL AND user_id IN (%s) AND state IN ('running', 'starting')
 			AND resources IS NOT NULL
 		`, placeholders)
 		h.db.DB().QueryRowContext(ctx, query, args...).Scan(&totalCPU, &totalMemory)
@@ -967,7 +967,7 @@ func (h *QuotasHandler) CheckQuota(c *gin.Context) {
 	var activeSessions int
 	h.db.DB().QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting', 'pending')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting', 'pending')
 	`, req.UserID).Scan(&activeSessions)
 
 	var totalCPU, totalMemory int
@@ -976,7 +976,7 @@ func (h *QuotasHandler) CheckQuota(c *gin.Context) {
 			COALESCE(SUM((resources->>'cpu')::int), 0),
 			COALESCE(SUM((resources->>'memory')::int), 0)
 		FROM sessions
-		WHERE user_id = $1 AND state IN ('running', 'starting')
+		WHERE archived_at IS NULL AND user_id = $1 AND state IN ('running', 'starting')
 		AND resources IS NOT NULL
 	`, req.UserID).Scan(&totalCPU, &totalMemory)
 
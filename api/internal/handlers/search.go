@@ -354,7 +354,7 @@ func (h *SearchHandler) SearchSessions(c *gin.Context) {
 	sqlQuery := `
 		SELECT id, template_name, state, created_at, last_connection
 		FROM sessions
-		WHERE user_id = $1
+		WHERE archived_at IS NULL AND user_id = $1
 	`
 	args := []interface{}{userIDStr}
 	argIndex := 2
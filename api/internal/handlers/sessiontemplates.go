@@ -85,11 +85,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/streamspace/streamspace/api/internal/db"
 	"github.com/streamspace/streamspace/api/internal/events"
 	"github.com/streamspace/streamspace/api/internal/k8s"
@@ -652,7 +652,7 @@ func (h *SessionTemplatesHandler) CreateTemplateFromSession(c *gin.Context) {
 	// Get session details
 	var templateName string
 	err := h.db.DB().QueryRowContext(ctx, `
-		SELECT template_name FROM sessions WHERE id = $1 AND user_id = $2
+		SELECT template_name FROM sessions WHERE archived_at IS NULL AND id = $1 AND user_id = $2
 	`, sessionID, userIDStr).Scan(&templateName)
 
 	if err != nil {
@@ -1227,12 +1227,11 @@ func (h *SessionTemplatesHandler) ListTemplateVersions(c *gin.Context) {
 	for rows.Next() {
 		var version TemplateSnapshot
 		var templateDataJSON []byte
-		var tagsArray sql.NullString
 
 		err := rows.Scan(
 			&version.ID, &version.TemplateID, &version.VersionNumber,
 			&templateDataJSON, &version.Description, &version.CreatedBy,
-			&version.CreatedAt, &tagsArray,
+			&version.CreatedAt, pq.Array(&version.Tags),
 		)
 
 		if err != nil {
@@ -1246,18 +1245,6 @@ func (h *SessionTemplatesHandler) ListTemplateVersions(c *gin.Context) {
 			continue
 		}
 
-		// Parse tags array
-		if tagsArray.Valid && tagsArray.String != "" {
-			// PostgreSQL array format: {tag1,tag2,tag3}
-			tagsStr := tagsArray.String
-			if len(tagsStr) > 2 && tagsStr[0] == '{' && tagsStr[len(tagsStr)-1] == '}' {
-				tagsStr = tagsStr[1 : len(tagsStr)-1]
-				if tagsStr != "" {
-					version.Tags = splitPostgresArray(tagsStr)
-				}
-			}
-		}
-
 		versions = append(versions, version)
 	}
 
@@ -1356,12 +1343,6 @@ func (h *SessionTemplatesHandler) CreateTemplateVersion(c *gin.Context) {
 		return
 	}
 
-	// Convert tags to PostgreSQL array format
-	tagsArray := "{}"
-	if len(req.Tags) > 0 {
-		tagsArray = "{" + joinPostgresArray(req.Tags) + "}"
-	}
-
 	// Insert new version
 	var versionID int
 	err = h.db.DB().QueryRowContext(ctx, `
@@ -1369,7 +1350,7 @@ func (h *SessionTemplatesHandler) CreateTemplateVersion(c *gin.Context) {
 		(template_id, version_number, template_data, description, created_by, tags)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, templateID, nextVersion, string(templateDataBytes), req.Description, userIDStr, tagsArray).Scan(&versionID)
+	`, templateID, nextVersion, string(templateDataBytes), req.Description, userIDStr, pq.Array(req.Tags)).Scan(&versionID)
 
 	if err != nil {
 		log.Printf("[ERROR] Failed to create template version: %v", err)
@@ -1570,12 +1551,6 @@ func (h *SessionTemplatesHandler) createVersionSnapshot(ctx context.Context, tem
 		return 0, fmt.Errorf("failed to get next version number: %w", err)
 	}
 
-	// Convert tags to PostgreSQL array format
-	tagsArray := "{}"
-	if len(tags) > 0 {
-		tagsArray = "{" + joinPostgresArray(tags) + "}"
-	}
-
 	// Insert new version
 	var versionID int
 	err = h.db.DB().QueryRowContext(ctx, `
@@ -1583,7 +1558,7 @@ func (h *SessionTemplatesHandler) createVersionSnapshot(ctx context.Context, tem
 		(template_id, version_number, template_data, description, created_by, tags)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
-	`, templateID, nextVersion, string(templateDataJSON), description, userID, tagsArray).Scan(&versionID)
+	`, templateID, nextVersion, string(templateDataJSON), description, userID, pq.Array(tags)).Scan(&versionID)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create version: %w", err)
@@ -1591,51 +1566,3 @@ func (h *SessionTemplatesHandler) createVersionSnapshot(ctx context.Context, tem
 
 	return versionID, nil
 }
-
-// splitPostgresArray splits a PostgreSQL array string into a Go slice
-func splitPostgresArray(s string) []string {
-	if s == "" {
-		return []string{}
-	}
-	// Simple split by comma (assumes no commas in values)
-	parts := []string{}
-	for _, part := range splitByComma(s) {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			parts = append(parts, trimmed)
-		}
-	}
-	return parts
-}
-
-// joinPostgresArray joins a Go slice into a PostgreSQL array string
-func joinPostgresArray(tags []string) string {
-	quoted := make([]string, len(tags))
-	for i, tag := range tags {
-		// Escape quotes in tag
-		escaped := strings.ReplaceAll(tag, `"`, `\"`)
-		quoted[i] = `"` + escaped + `"`
-	}
-	return strings.Join(quoted, ",")
-}
-
-// splitByComma splits a string by comma
-func splitByComma(s string) []string {
-	result := []string{}
-	current := ""
-	inQuotes := false
-	for _, ch := range s {
-		if ch == '"' {
-			inQuotes = !inQuotes
-		} else if ch == ',' && !inQuotes {
-			result = append(result, current)
-			current = ""
-		} else {
-			current += string(ch)
-		}
-	}
-	if current != "" {
-		result = append(result, current)
-	}
-	return result
-}
@@ -47,9 +47,10 @@
 // - GET    /api/v1/sessions/:id/invitations - List invitations
 // - DELETE /api/v1/invitations/:token - Revoke invitation
 // - POST   /api/v1/invitations/:token/accept - Accept invitation
+// - POST   /api/v1/sessions/:id/collaborators - Invite a collaborator (viewer/editor)
 // - GET    /api/v1/sessions/:id/collaborators - List active collaborators
 // - POST   /api/v1/sessions/:id/collaborators/:userId/activity - Update activity
-// - DELETE /api/v1/sessions/:id/collaborators/:userId - Remove collaborator
+// - DELETE /api/v1/sessions/:id/collaborators/:userId - Remove collaborator (owner only)
 // - GET    /api/v1/shared-sessions - List sessions shared with user
 //
 // Security:
@@ -75,6 +76,7 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -82,11 +84,56 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/middleware"
 )
 
+// Collaborator roles for invited collaborators
+const (
+	CollaboratorRoleViewer = "viewer"
+	CollaboratorRoleEditor = "editor"
+
+	// sessionAccessOwner is returned by verifySessionAccess for the owner
+	sessionAccessOwner = "owner"
+)
+
+// Collaborator events emitted for plugins
+const (
+	EventSessionCollaboratorAdded   = "session.collaborator.added"
+	EventSessionCollaboratorRemoved = "session.collaborator.removed"
+)
+
+// CollaboratorAddedEvent is the payload of session.collaborator.added.
+type CollaboratorAddedEvent struct {
+	SessionID string `json:"sessionId"`
+	UserID    string `json:"userId"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invitedBy"`
+}
+
+// CollaboratorRemovedEvent is the payload of session.collaborator.removed.
+type CollaboratorRemovedEvent struct {
+	SessionID string `json:"sessionId"`
+	UserID    string `json:"userId"`
+	RemovedBy string `json:"removedBy"`
+}
+
+// errSessionAccessDenied is returned when a user neither owns nor
+// collaborates on a session.
+var errSessionAccessDenied = errors.New("user does not have access to this session")
+
+// EventEmitter delivers events to plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// handlers package does not depend on the plugin runtime. ctx carries the
+// request's trace (see middleware.TraceContext) to the plugin handlers.
+type EventEmitter interface {
+	EmitEventWithContext(ctx context.Context, eventType string, data interface{})
+}
+
 // SharingHandler handles session sharing and collaboration
 type SharingHandler struct {
-	db *db.Database
+	db      *db.Database
+	emitter EventEmitter
 }
 
 // NewSharingHandler creates a new sharing handler
@@ -96,6 +143,46 @@ func NewSharingHandler(database *db.Database) *SharingHandler {
 	}
 }
 
+// SetEventEmitter sets where collaborator events are emitted for plugins.
+func (h *SharingHandler) SetEventEmitter(emitter EventEmitter) {
+	h.emitter = emitter
+}
+
+// emit delivers an event caused by the request in c to plugins when an
+// emitter is configured.
+func (h *SharingHandler) emit(c *gin.Context, eventType string, data interface{}) {
+	if h.emitter != nil {
+		h.emitter.EmitEventWithContext(middleware.TraceContext(c), eventType, data)
+	}
+}
+
+// verifySessionAccess reports how userID may access a session: "owner" for
+// the session owner, or the collaborator role ("viewer"/"editor") for an
+// invited collaborator. Returns sql.ErrNoRows if the session does not exist
+// and errSessionAccessDenied if the user has no access.
+func (h *SharingHandler) verifySessionAccess(ctx context.Context, sessionID, userID string) (string, error) {
+	var ownerID string
+	var role sql.NullString
+	err := h.db.DB().QueryRowContext(ctx, `
+		SELECT s.user_id, sc.role
+		FROM sessions s
+		LEFT JOIN session_collaborators sc
+			ON sc.session_id = s.id AND sc.user_id = $2 AND sc.is_active = true
+		WHERE s.archived_at IS NULL AND s.id = $1
+	`, sessionID, userID).Scan(&ownerID, &role)
+	if err != nil {
+		return "", err
+	}
+
+	if ownerID == userID {
+		return sessionAccessOwner, nil
+	}
+	if role.Valid && role.String != "" {
+		return role.String, nil
+	}
+	return "", errSessionAccessDenied
+}
+
 // RegisterRoutes registers the sharing routes
 func (h *SharingHandler) RegisterRoutes(router *gin.RouterGroup) {
 	router.POST("/sessions/:id/share", h.CreateShare)
@@ -108,6 +195,7 @@ func (h *SharingHandler) RegisterRoutes(router *gin.RouterGroup) {
 	router.DELETE("/invitations/:token", h.RevokeInvitation)
 	router.POST("/invitations/:token/accept", h.AcceptInvitation)
 
+	router.POST("/sessions/:id/collaborators", h.AddCollaborator)
 	router.GET("/sessions/:id/collaborators", h.ListCollaborators)
 	router.POST("/sessions/:id/collaborators/:userId/activity", h.UpdateCollaboratorActivity)
 	router.DELETE("/sessions/:id/collaborators/:userId", h.RemoveCollaborator)
@@ -139,7 +227,7 @@ func (h *SharingHandler) CreateShare(c *gin.Context) {
 
 	// Get session owner
 	var ownerUserId string
-	err := h.db.DB().QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE id = $1`, sessionID).Scan(&ownerUserId)
+	err := h.db.DB().QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE archived_at IS NULL AND id = $1`, sessionID).Scan(&ownerUserId)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
@@ -345,7 +433,7 @@ func (h *SharingHandler) CreateInvitation(c *gin.Context) {
 
 	// Get session owner
 	var createdBy string
-	err := h.db.DB().QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE id = $1`, sessionID).Scan(&createdBy)
+	err := h.db.DB().QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE archived_at IS NULL AND id = $1`, sessionID).Scan(&createdBy)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
@@ -520,15 +608,123 @@ func (h *SharingHandler) AcceptInvitation(c *gin.Context) {
 	})
 }
 
+// AddCollaborator invites a user to collaborate on a session (owner only).
+//
+// Request Body: {"userId": "...", "role": "viewer|editor"}
+//
+// Viewers may watch the session; editors may also interact with it. Adding
+// an existing collaborator updates their role.
+func (h *SharingHandler) AddCollaborator(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+	currentUserID := c.GetString("userID")
+
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Map the role onto the existing share permission levels
+	permissionLevel := ""
+	switch req.Role {
+	case CollaboratorRoleViewer:
+		permissionLevel = "view"
+	case CollaboratorRoleEditor:
+		permissionLevel = "collaborate"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be: viewer or editor"})
+		return
+	}
+
+	if !h.requireSessionOwner(c, sessionID, currentUserID, "Only the session owner can add collaborators") {
+		return
+	}
+
+	if req.UserID == currentUserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The session owner cannot be added as a collaborator"})
+		return
+	}
+
+	var userExists bool
+	err := h.db.DB().QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, req.UserID).Scan(&userExists)
+	if err != nil || !userExists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
+
+	now := time.Now()
+	_, err = h.db.DB().ExecContext(ctx, `
+		INSERT INTO session_collaborators (id, session_id, user_id, permission_level, role, invited_by, invited_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+		ON CONFLICT (session_id, user_id)
+		DO UPDATE SET permission_level = $4, role = $5, invited_by = $6, invited_at = $7, is_active = true
+	`, uuid.New().String(), sessionID, req.UserID, permissionLevel, req.Role, currentUserID, now)
+	if err != nil {
+		log.Printf("Failed to add collaborator %s to session %s: %v", req.UserID, sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add collaborator"})
+		return
+	}
+
+	h.emit(c, EventSessionCollaboratorAdded, &CollaboratorAddedEvent{
+		SessionID: sessionID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+		InvitedBy: currentUserID,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"sessionId": sessionID,
+		"userId":    req.UserID,
+		"role":      req.Role,
+		"invitedBy": currentUserID,
+		"invitedAt": now,
+	})
+}
+
+// requireSessionOwner writes an error response and returns false unless
+// userID owns the session.
+func (h *SharingHandler) requireSessionOwner(c *gin.Context, sessionID, userID, forbiddenMessage string) bool {
+	access, err := h.verifySessionAccess(c.Request.Context(), sessionID, userID)
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return false
+	case err != nil && err != errSessionAccessDenied:
+		log.Printf("Failed to verify access to session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session access"})
+		return false
+	case access != sessionAccessOwner:
+		c.JSON(http.StatusForbidden, gin.H{"error": forbiddenMessage})
+		return false
+	}
+	return true
+}
+
 // ListCollaborators lists active collaborators for a session
 func (h *SharingHandler) ListCollaborators(c *gin.Context) {
 	ctx := context.Background()
 	sessionID := c.Param("id")
 
+	// Owners and collaborators may see who else is in the session
+	if c.GetString("userRole") != "admin" {
+		if _, err := h.verifySessionAccess(ctx, sessionID, c.GetString("userID")); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			} else {
+				c.JSON(http.StatusForbidden, gin.H{"error": "User does not have access to this session"})
+			}
+			return
+		}
+	}
+
 	rows, err := h.db.DB().QueryContext(ctx, `
 		SELECT
 			sc.id, sc.session_id, sc.user_id, sc.permission_level,
-			sc.joined_at, sc.last_activity, sc.is_active,
+			sc.joined_at, sc.last_activity, sc.is_active, COALESCE(sc.role, ''),
 			u.username, u.full_name
 		FROM session_collaborators sc
 		JOIN users u ON sc.user_id = u.id
@@ -544,11 +740,11 @@ func (h *SharingHandler) ListCollaborators(c *gin.Context) {
 
 	collaborators := []map[string]interface{}{}
 	for rows.Next() {
-		var id, sessionId, userId, permissionLevel, username, fullName string
+		var id, sessionId, userId, permissionLevel, role, username, fullName string
 		var joinedAt, lastActivity time.Time
 		var isActive bool
 
-		if err := rows.Scan(&id, &sessionId, &userId, &permissionLevel, &joinedAt, &lastActivity, &isActive, &username, &fullName); err != nil {
+		if err := rows.Scan(&id, &sessionId, &userId, &permissionLevel, &joinedAt, &lastActivity, &isActive, &role, &username, &fullName); err != nil {
 			continue
 		}
 
@@ -560,6 +756,7 @@ func (h *SharingHandler) ListCollaborators(c *gin.Context) {
 			"joinedAt":        joinedAt,
 			"lastActivity":    lastActivity,
 			"isActive":        isActive,
+			"role":            role,
 			"user": map[string]interface{}{
 				"username": username,
 				"fullName": fullName,
@@ -589,14 +786,19 @@ func (h *SharingHandler) UpdateCollaboratorActivity(c *gin.Context) {
 	`, sessionID, userID).Scan(&permissionLevel)
 
 	if err != nil {
-		// Check if user is the owner
-		var ownerID string
-		err = h.db.DB().QueryRowContext(ctx, `SELECT user_id FROM sessions WHERE id = $1`, sessionID).Scan(&ownerID)
-		if err != nil || ownerID != userID {
+		// Fall back to ownership or an invited collaborator role
+		access, accessErr := h.verifySessionAccess(ctx, sessionID, userID)
+		switch {
+		case accessErr != nil:
 			c.JSON(http.StatusForbidden, gin.H{"error": "User does not have access to this session"})
 			return
+		case access == sessionAccessOwner:
+			permissionLevel = "control"
+		case access == CollaboratorRoleEditor:
+			permissionLevel = "collaborate"
+		default:
+			permissionLevel = "view"
 		}
-		permissionLevel = "control"
 	}
 
 	// Upsert collaborator
@@ -616,16 +818,20 @@ func (h *SharingHandler) UpdateCollaboratorActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-// RemoveCollaborator removes a collaborator from a session
+// RemoveCollaborator removes a collaborator from a session (owner only)
 func (h *SharingHandler) RemoveCollaborator(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	sessionID := c.Param("id")
 	userID := c.Param("userId")
 
-	_, err := h.db.DB().ExecContext(ctx, `
+	if !h.requireSessionOwner(c, sessionID, c.GetString("userID"), "Only the session owner can remove collaborators") {
+		return
+	}
+
+	result, err := h.db.DB().ExecContext(ctx, `
 		UPDATE session_collaborators
 		SET is_active = false
-		WHERE session_id = $1 AND user_id = $2
+		WHERE session_id = $1 AND user_id = $2 AND is_active = true
 	`, sessionID, userID)
 
 	if err != nil {
@@ -633,6 +839,14 @@ func (h *SharingHandler) RemoveCollaborator(c *gin.Context) {
 		return
 	}
 
+	if rows, _ := result.RowsAffected(); rows > 0 {
+		h.emit(c, EventSessionCollaboratorRemoved, &CollaboratorRemovedEvent{
+			SessionID: sessionID,
+			UserID:    userID,
+			RemovedBy: c.GetString("userID"),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed successfully"})
 }
 
@@ -657,6 +871,7 @@ func (h *SharingHandler) ListSharedSessions(c *gin.Context) {
 		JOIN users u ON s.user_id = u.id
 		WHERE ss.shared_with_user_id = $1
 			AND ss.revoked_at IS NULL
+			AND s.archived_at IS NULL
 			AND (ss.expires_at IS NULL OR ss.expires_at > $2)
 		ORDER BY ss.created_at DESC
 	`, userID, time.Now())
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/middleware"
+	"github.com/streamspace/streamspace/api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingEmitter struct {
+	events []string
+	traces []tracing.TraceContext
+}
+
+func (e *recordingEmitter) EmitEventWithContext(ctx context.Context, eventType string, data interface{}) {
+	e.events = append(e.events, eventType)
+	trace, _ := tracing.FromContext(ctx)
+	e.traces = append(e.traces, trace)
+}
+
+func setupSharingTest(t *testing.T, method, target, body, userID string) (*SharingHandler, sqlmock.Sqlmock, *recordingEmitter, *httptest.ResponseRecorder, *gin.Context) {
+	database, mock, w, c := newHandlerTest(t, method, target, body)
+	c.Params = gin.Params{{Key: "id", Value: "sess-1"}}
+	c.Set("userID", userID)
+
+	handler := NewSharingHandler(database)
+	emitter := &recordingEmitter{}
+	handler.SetEventEmitter(emitter)
+
+	return handler, mock, emitter, w, c
+}
+
+func expectSessionAccess(mock sqlmock.Sqlmock, userID, ownerID string, role interface{}) {
+	mock.ExpectQuery(`SELECT s.user_id, sc.role`).
+		WithArgs("sess-1", userID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow(ownerID, role))
+}
+
+func TestAddCollaborator_Success(t *testing.T) {
+	handler, mock, emitter, w, c := setupSharingTest(t, http.MethodPost, "/sessions/sess-1/collaborators",
+		`{"userId":"user-2","role":"editor"}`, "owner-1")
+
+	expectSessionAccess(mock, "owner-1", "owner-1", nil)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE id = \$1\)`).
+		WithArgs("user-2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO session_collaborators`).
+		WithArgs(sqlmock.AnyArg(), "sess-1", "user-2", "collaborate", "editor", "owner-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	c.Set(middleware.RequestIDKey, "req-42")
+
+	handler.AddCollaborator(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []string{EventSessionCollaboratorAdded}, emitter.events)
+	assert.Equal(t, []tracing.TraceContext{{RequestID: "req-42", UserID: "owner-1"}}, emitter.traces)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddCollaborator_InvalidRole(t *testing.T) {
+	handler, _, _, w, c := setupSharingTest(t, http.MethodPost, "/sessions/sess-1/collaborators",
+		`{"userId":"user-2","role":"admin"}`, "owner-1")
+
+	handler.AddCollaborator(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAddCollaborator_CollaboratorCannotInvite(t *testing.T) {
+	handler, mock, emitter, w, c := setupSharingTest(t, http.MethodPost, "/sessions/sess-1/collaborators",
+		`{"userId":"user-3","role":"viewer"}`, "user-2")
+
+	expectSessionAccess(mock, "user-2", "owner-1", "editor")
+
+	handler.AddCollaborator(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, emitter.events)
+}
+
+func TestRemoveCollaborator_OwnerOnly(t *testing.T) {
+	handler, mock, emitter, w, c := setupSharingTest(t, http.MethodDelete, "/sessions/sess-1/collaborators/user-2", "", "user-3")
+	c.Params = append(c.Params, gin.Param{Key: "userId", Value: "user-2"})
+
+	expectSessionAccess(mock, "user-3", "owner-1", nil)
+
+	handler.RemoveCollaborator(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, emitter.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveCollaborator_Success(t *testing.T) {
+	handler, mock, emitter, w, c := setupSharingTest(t, http.MethodDelete, "/sessions/sess-1/collaborators/user-2", "", "owner-1")
+	c.Params = append(c.Params, gin.Param{Key: "userId", Value: "user-2"})
+
+	expectSessionAccess(mock, "owner-1", "owner-1", nil)
+	mock.ExpectExec(`UPDATE session_collaborators`).
+		WithArgs("sess-1", "user-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	handler.RemoveCollaborator(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{EventSessionCollaboratorRemoved}, emitter.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -305,7 +305,7 @@ func (h *TeamHandler) ListTeamSessions(c *gin.Context) {
 		SELECT id, user_id, template_name, state, active_connections,
 		       url, created_at, updated_at
 		FROM sessions
-		WHERE team_id = $1
+		WHERE archived_at IS NULL AND team_id = $1
 		ORDER BY created_at DESC
 	`, teamID)
 	if err != nil {
@@ -492,7 +492,7 @@ func (h *UserHandler) SetUserQuota(c *gin.Context) {
 func (h *UserHandler) GetUserGroups(c *gin.Context) {
 	userID := c.Param("id")
 
-	groupIDs, err := h.userDB.GetUserGroups(c.Request.Context(), userID)
+	groupNames, err := h.userDB.GetUserGroups(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Failed to get user groups",
@@ -503,8 +503,8 @@ func (h *UserHandler) GetUserGroups(c *gin.Context) {
 
 	// Fetch full group details
 	groups := []interface{}{}
-	for _, groupID := range groupIDs {
-		group, err := h.groupDB.GetGroup(c.Request.Context(), groupID)
+	for _, groupName := range groupNames {
+		group, err := h.groupDB.GetGroupByName(c.Request.Context(), groupName)
 		if err == nil {
 			groups = append(groups, group)
 		}
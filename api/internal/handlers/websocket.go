@@ -719,9 +719,9 @@ func (h *WebSocketHandler) sendPeriodicMetrics(session *WebSocketSession) {
 		case <-ticker.C:
 			// Get current metrics
 			var totalSessions, runningSessions, hibernatedSessions int
-			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions`).Scan(&totalSessions)
-			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'running'`).Scan(&runningSessions)
-			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE state = 'hibernated'`).Scan(&hibernatedSessions)
+			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL`).Scan(&totalSessions)
+			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'running'`).Scan(&runningSessions)
+			h.db.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE archived_at IS NULL AND state = 'hibernated'`).Scan(&hibernatedSessions)
 
 			message := &BroadcastMessage{
 				Type:  "metrics",
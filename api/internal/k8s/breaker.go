@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sony/gobreaker"
+	apperrors "github.com/streamspace/streamspace/api/internal/errors"
+)
+
+const (
+	// circuitBreakerFailureThreshold is the number of consecutive failed
+	// Kubernetes API requests that opens the circuit breaker.
+	circuitBreakerFailureThreshold = 5
+
+	// defaultCircuitBreakerTimeout is how long the breaker stays open before
+	// letting a trial request through. Override with K8S_CIRCUIT_BREAKER_TIMEOUT.
+	defaultCircuitBreakerTimeout = 30 * time.Second
+
+	// EventCircuitOpened is emitted when the Kubernetes circuit breaker opens.
+	EventCircuitOpened = "platform.k8s.circuit_opened"
+)
+
+// CircuitOpenedEvent is the payload of EventCircuitOpened.
+type CircuitOpenedEvent struct {
+	// Failures is the number of consecutive failures that opened the breaker.
+	Failures int `json:"failures"`
+
+	// Timeout is how long the breaker stays open, e.g. "30s".
+	Timeout string `json:"timeout"`
+}
+
+// ErrKubernetesError is returned for Kubernetes API calls rejected by an open
+// circuit breaker, without contacting the API server.
+var ErrKubernetesError = apperrors.New(apperrors.ErrCodeKubernetesError, "Kubernetes API unavailable (circuit breaker open)")
+
+// errServerError marks a 5xx response as a breaker failure. The response
+// itself is still returned to client-go so it can decode the Status.
+var errServerError = errors.New("kubernetes API server error")
+
+// EventEmitter delivers platform events to plugins.
+//
+// *plugins.RuntimeV2 implements this interface; it is declared here so the
+// k8s package does not depend on the plugin runtime.
+type EventEmitter interface {
+	EmitEvent(eventType string, data interface{})
+}
+
+// SetEventEmitter sets where circuit breaker events are emitted for plugins.
+// Must be called before the client is shared between goroutines.
+func (c *Client) SetEventEmitter(emitter EventEmitter) {
+	c.emitter = emitter
+}
+
+// CircuitBreakerState returns the breaker state ("closed", "open" or
+// "half-open") and the current count of consecutive failures.
+func (c *Client) CircuitBreakerState() (string, uint32) {
+	if c.breaker == nil {
+		return gobreaker.StateClosed.String(), 0
+	}
+	return c.breaker.State().String(), c.breaker.Counts().ConsecutiveFailures
+}
+
+// circuitBreakerTimeout reads K8S_CIRCUIT_BREAKER_TIMEOUT (a Go duration).
+func circuitBreakerTimeout() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("K8S_CIRCUIT_BREAKER_TIMEOUT")); err == nil && v > 0 {
+		return v
+	}
+	return defaultCircuitBreakerTimeout
+}
+
+// newCircuitBreaker creates the breaker guarding Kubernetes API requests.
+//
+// After circuitBreakerFailureThreshold consecutive failures the breaker opens
+// and requests fail fast with ErrKubernetesError. Once timeout elapses it
+// turns half-open and lets a single request through to probe the API server.
+func (c *Client) newCircuitBreaker(timeout time.Duration) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "kubernetes",
+		MaxRequests: 1,
+		Timeout:     timeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= circuitBreakerFailureThreshold
+		},
+		IsSuccessful: func(err error) bool {
+			// A caller giving up is not evidence that the API server is down
+			return err == nil || errors.Is(err, context.Canceled)
+		},
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("Kubernetes circuit breaker: %s -> %s", from, to)
+			// Called with the breaker locked: must not call back into it
+			if to == gobreaker.StateOpen && c.emitter != nil {
+				c.emitter.EmitEvent(EventCircuitOpened, &CircuitOpenedEvent{
+					Failures: circuitBreakerFailureThreshold,
+					Timeout:  timeout.String(),
+				})
+			}
+		},
+	})
+}
+
+// breakerTransport routes every Kubernetes API request through the circuit
+// breaker. Transport errors and 5xx responses count as failures; other
+// responses (including 404 and 409) mean the API server is reachable.
+type breakerTransport struct {
+	breaker *gobreaker.CircuitBreaker
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	result, err := t.breaker.Execute(func() (interface{}, error) {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return resp, errServerError
+		}
+		return resp, nil
+	})
+
+	switch {
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		return nil, ErrKubernetesError
+	case errors.Is(err, errServerError):
+		return result.(*http.Response), nil
+	case err != nil:
+		return nil, err
+	}
+	return result.(*http.Response), nil
+}
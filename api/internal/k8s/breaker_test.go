@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEmitter struct {
+	events []string
+}
+
+func (e *recordingEmitter) EmitEvent(eventType string, data interface{}) {
+	e.events = append(e.events, eventType)
+}
+
+func TestBreakerTransport_OpensAfterConsecutiveFailures(t *testing.T) {
+	var hits int32
+	status := int32(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	emitter := &recordingEmitter{}
+	client := &Client{emitter: emitter}
+	client.breaker = client.newCircuitBreaker(50 * time.Millisecond)
+	httpClient := &http.Client{Transport: &breakerTransport{breaker: client.breaker, next: http.DefaultTransport}}
+
+	for i := 0; i < circuitBreakerFailureThreshold; i++ {
+		resp, err := httpClient.Get(server.URL)
+		require.NoError(t, err, "5xx responses are passed through to the caller")
+		resp.Body.Close()
+	}
+
+	state, failures := client.CircuitBreakerState()
+	assert.Equal(t, "open", state)
+	assert.Equal(t, uint32(0), failures, "counts reset when the breaker opens")
+	assert.Equal(t, []string{EventCircuitOpened}, emitter.events)
+
+	// Open: fail fast without contacting the API server
+	_, err := httpClient.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrKubernetesError))
+	assert.Equal(t, int32(circuitBreakerFailureThreshold), atomic.LoadInt32(&hits))
+
+	// After the timeout a single trial request closes the breaker again
+	atomic.StoreInt32(&status, http.StatusNotFound)
+	time.Sleep(60 * time.Millisecond)
+	state, _ = client.CircuitBreakerState()
+	assert.Equal(t, "half-open", state)
+
+	resp, err := httpClient.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	state, _ = client.CircuitBreakerState()
+	assert.Equal(t, "closed", state)
+}
+
+func TestCircuitBreakerState_NoBreaker(t *testing.T) {
+	state, failures := (&Client{}).CircuitBreakerState()
+	assert.Equal(t, "closed", state)
+	assert.Equal(t, uint32(0), failures)
+}
+
+func TestCircuitBreakerTimeout(t *testing.T) {
+	t.Setenv("K8S_CIRCUIT_BREAKER_TIMEOUT", "")
+	assert.Equal(t, defaultCircuitBreakerTimeout, circuitBreakerTimeout())
+
+	t.Setenv("K8S_CIRCUIT_BREAKER_TIMEOUT", "2m")
+	assert.Equal(t, 2*time.Minute, circuitBreakerTimeout())
+}
@@ -87,11 +87,14 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/sony/gobreaker"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -101,6 +104,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // Session represents a StreamSpace Session CRD
@@ -206,6 +211,8 @@ type Client struct {
 	dynamicClient dynamic.Interface
 	config        *rest.Config
 	namespace     string
+	breaker       *gobreaker.CircuitBreaker // Guards all API server requests
+	emitter       EventEmitter              // Receives circuit breaker events
 }
 
 var (
@@ -235,6 +242,14 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
 
+	// Route every request through the circuit breaker so an unreachable API
+	// server fails fast instead of stalling each call until it times out
+	client := &Client{config: config}
+	client.breaker = client.newCircuitBreaker(circuitBreakerTimeout())
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &breakerTransport{breaker: client.breaker, next: rt}
+	})
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
@@ -250,12 +265,10 @@ func NewClient() (*Client, error) {
 		namespace = "streamspace"
 	}
 
-	return &Client{
-		clientset:     clientset,
-		dynamicClient: dynamicClient,
-		config:        config,
-		namespace:     namespace,
-	}, nil
+	client.clientset = clientset
+	client.dynamicClient = dynamicClient
+	client.namespace = namespace
+	return client, nil
 }
 
 // getConfig returns Kubernetes config (in-cluster or kubeconfig)
@@ -1034,6 +1047,68 @@ func (c *Client) GetPods(ctx context.Context, namespace string) (*corev1.PodList
 	return pods, nil
 }
 
+// ForceDeleteSessionPods deletes a session's pods with a zero grace period,
+// terminating them immediately. Pods are found by the "session" label;
+// podName, if set, is deleted as well in case the label is missing.
+//
+// Returns the names of the pods deleted. Pods that are already gone are
+// skipped.
+func (c *Client) ForceDeleteSessionPods(ctx context.Context, namespace, sessionID, podName string) ([]string, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("session=%s", sessionID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of session %s: %w", sessionID, err)
+	}
+
+	names := make([]string, 0, len(pods.Items)+1)
+	labelled := false
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+		labelled = labelled || pod.Name == podName
+	}
+	if podName != "" && !labelled {
+		names = append(names, podName)
+	}
+
+	zero := int64(0)
+	deleted := make([]string, 0, len(names))
+	for _, name := range names {
+		err := c.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{GracePeriodSeconds: &zero})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to force delete pod %s: %w", name, err)
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted, nil
+}
+
+// GetPodMetrics returns current CPU/memory usage for pods in a namespace.
+//
+// Usage comes from the Kubernetes metrics API (metrics.k8s.io/v1beta1),
+// which requires metrics-server to be installed in the cluster.
+func (c *Client) GetPodMetrics(ctx context.Context, namespace string) (*metricsv1beta1.PodMetricsList, error) {
+	metricsClient, err := metricsclientset.NewForConfig(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %w", err)
+	}
+
+	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+
+	return podMetrics, nil
+}
+
+// GetNamespace returns the default namespace for StreamSpace resources
+func (c *Client) GetNamespace() string {
+	return c.namespace
+}
+
 // GetServices returns services in a namespace
 func (c *Client) GetServices(ctx context.Context, namespace string) (*corev1.ServiceList, error) {
 	services, err := c.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
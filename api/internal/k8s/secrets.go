@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// ============================================================================
+// Secret Operations
+// ============================================================================
+
+// GetSecretData returns the data of a Secret in the StreamSpace namespace,
+// or nil if the Secret does not exist.
+func (c *Client) GetSecretData(ctx context.Context, name string) (map[string][]byte, error) {
+	secret, err := c.clientset.CoreV1().Secrets(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+
+	return secret.Data, nil
+}
+
+// UpdateSecretData sets and removes keys of a Secret in the StreamSpace
+// namespace, creating it with labels if it does not exist. Keys not
+// mentioned are left unchanged. It returns the Secret's resulting data.
+func (c *Client) UpdateSecretData(ctx context.Context, name string, labels map[string]string, set map[string][]byte, remove []string) (map[string][]byte, error) {
+	secrets := c.clientset.CoreV1().Secrets(c.namespace)
+
+	var data map[string][]byte
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: c.namespace,
+					Labels:    labels,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: set,
+			}
+			created, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+			if err != nil {
+				return err
+			}
+			data = created.Data
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte, len(set))
+		}
+		for key, value := range set {
+			secret.Data[key] = value
+		}
+		for _, key := range remove {
+			delete(secret.Data, key)
+		}
+
+		updated, err := secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		data = updated.Data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update secret %s: %w", name, err)
+	}
+
+	return data, nil
+}
+
+// DeleteSecret deletes a Secret in the StreamSpace namespace. Deleting a
+// Secret that does not exist is not an error.
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	err := c.clientset.CoreV1().Secrets(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s: %w", name, err)
+	}
+
+	return nil
+}
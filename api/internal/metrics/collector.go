@@ -0,0 +1,216 @@
+// Package metrics collects per-session resource usage for StreamSpace.
+//
+// The ResourceCollector periodically samples CPU and memory usage of session
+// pods from the Kubernetes metrics API and stores one row per session per
+// sample in the session_resource_metrics table. The API serves these rows as
+// time series (GET /api/v1/sessions/:id/metrics), aggregated to the
+// requested resolution.
+//
+// Architecture:
+//   - GetPods lists pods in the StreamSpace namespace; session pods are
+//     identified by the "session" label set by the controller
+//   - GetPodMetrics reads usage from metrics.k8s.io/v1beta1 (metrics-server)
+//   - Container usage is summed per pod, then per session
+//   - Rows are upserted on (session_id, collected_at) so a retried sample
+//     never creates duplicates
+//   - Samples older than the retention period are deleted each cycle
+//
+// Configuration:
+//   - SESSION_METRICS_INTERVAL: sampling interval (default 30s)
+//   - SESSION_METRICS_RETENTION: how long samples are kept (default 168h)
+//
+// If metrics-server is not installed, each cycle logs a warning and stores
+// nothing; the rest of the API is unaffected.
+//
+// Example usage:
+//
+//	collector := metrics.NewResourceCollector(database, k8sClient, k8sClient.GetNamespace())
+//	go collector.Start()
+//	defer collector.Stop()
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/streamspace/streamspace/api/internal/db"
+	corev1 "k8s.io/api/core/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+const (
+	// DefaultCollectInterval is how often usage is sampled
+	DefaultCollectInterval = 30 * time.Second
+
+	// DefaultRetention is how long samples are kept
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// sessionLabel is the pod label holding the session name
+	sessionLabel = "session"
+
+	// collectTimeout bounds one collection cycle
+	collectTimeout = 20 * time.Second
+)
+
+// PodSource provides session pods and their resource usage.
+//
+// *k8s.Client implements this interface.
+type PodSource interface {
+	GetPods(ctx context.Context, namespace string) (*corev1.PodList, error)
+	GetPodMetrics(ctx context.Context, namespace string) (*metricsv1beta1.PodMetricsList, error)
+}
+
+// SessionUsage is the resource usage of one session at one point in time.
+type SessionUsage struct {
+	SessionID     string
+	CPUMillicores int64
+	MemoryBytes   int64
+}
+
+// ResourceCollector samples session resource usage in the background.
+type ResourceCollector struct {
+	db        *db.Database
+	source    PodSource
+	namespace string
+	interval  time.Duration
+	retention time.Duration
+	stopCh    chan struct{}
+}
+
+// NewResourceCollector creates a collector for session pods in namespace.
+//
+// Interval and retention are read from SESSION_METRICS_INTERVAL and
+// SESSION_METRICS_RETENTION (Go durations); invalid or unset values use
+// the defaults.
+func NewResourceCollector(database *db.Database, source PodSource, namespace string) *ResourceCollector {
+	return &ResourceCollector{
+		db:        database,
+		source:    source,
+		namespace: namespace,
+		interval:  durationFromEnv("SESSION_METRICS_INTERVAL", DefaultCollectInterval),
+		retention: durationFromEnv("SESSION_METRICS_RETENTION", DefaultRetention),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start runs the collection loop until Stop is called.
+func (rc *ResourceCollector) Start() {
+	log.Printf("Session resource collector started (interval: %s)", rc.interval)
+
+	ticker := time.NewTicker(rc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+			if err := rc.Collect(ctx); err != nil {
+				log.Printf("Session resource collection failed: %v", err)
+			}
+			cancel()
+		case <-rc.stopCh:
+			log.Println("Session resource collector stopped")
+			return
+		}
+	}
+}
+
+// Stop stops the collection loop
+func (rc *ResourceCollector) Stop() {
+	close(rc.stopCh)
+}
+
+// Collect takes one sample of every running session and stores it.
+func (rc *ResourceCollector) Collect(ctx context.Context) error {
+	pods, err := rc.source.GetPods(ctx, rc.namespace)
+	if err != nil {
+		return err
+	}
+
+	podMetrics, err := rc.source.GetPodMetrics(ctx, rc.namespace)
+	if err != nil {
+		return err
+	}
+
+	usage := aggregateSessionUsage(pods, podMetrics)
+	collectedAt := time.Now().UTC().Truncate(time.Second)
+
+	for _, u := range usage {
+		if _, err := rc.db.DB().ExecContext(ctx, `
+			INSERT INTO session_resource_metrics (session_id, collected_at, cpu_millicores, memory_bytes)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (session_id, collected_at)
+			DO UPDATE SET cpu_millicores = EXCLUDED.cpu_millicores, memory_bytes = EXCLUDED.memory_bytes
+		`, u.SessionID, collectedAt, u.CPUMillicores, u.MemoryBytes); err != nil {
+			return fmt.Errorf("failed to store metrics for session %s: %w", u.SessionID, err)
+		}
+	}
+
+	if _, err := rc.db.DB().ExecContext(ctx, `
+		DELETE FROM session_resource_metrics WHERE collected_at < $1
+	`, collectedAt.Add(-rc.retention)); err != nil {
+		return fmt.Errorf("failed to prune session metrics: %w", err)
+	}
+
+	return nil
+}
+
+// aggregateSessionUsage sums container usage of running session pods per session.
+//
+// Pods without a session label (e.g. the API or controller itself) and pods
+// that are not running are ignored.
+func aggregateSessionUsage(pods *corev1.PodList, podMetrics *metricsv1beta1.PodMetricsList) []SessionUsage {
+	podSession := make(map[string]string)
+	for _, pod := range pods.Items {
+		sessionID := pod.Labels[sessionLabel]
+		if sessionID == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podSession[pod.Name] = sessionID
+	}
+
+	totals := make(map[string]*SessionUsage)
+	var order []string
+	for _, pm := range podMetrics.Items {
+		sessionID, ok := podSession[pm.Name]
+		if !ok {
+			continue
+		}
+
+		u, exists := totals[sessionID]
+		if !exists {
+			u = &SessionUsage{SessionID: sessionID}
+			totals[sessionID] = u
+			order = append(order, sessionID)
+		}
+
+		for _, container := range pm.Containers {
+			if cpu, ok := container.Usage[corev1.ResourceCPU]; ok {
+				u.CPUMillicores += cpu.MilliValue()
+			}
+			if mem, ok := container.Usage[corev1.ResourceMemory]; ok {
+				u.MemoryBytes += mem.Value()
+			}
+		}
+	}
+
+	result := make([]SessionUsage, 0, len(order))
+	for _, sessionID := range order {
+		result = append(result, *totals[sessionID])
+	}
+	return result
+}
+
+// durationFromEnv parses a Go duration from key, falling back to def.
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid %s %q, using default %s", key, value, def)
+	}
+	return def
+}
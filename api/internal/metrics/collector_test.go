@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+type fakePodSource struct {
+	pods       *corev1.PodList
+	metrics    *metricsv1beta1.PodMetricsList
+	metricsErr error
+}
+
+func (f *fakePodSource) GetPods(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	return f.pods, nil
+}
+
+func (f *fakePodSource) GetPodMetrics(ctx context.Context, namespace string) (*metricsv1beta1.PodMetricsList, error) {
+	return f.metrics, f.metricsErr
+}
+
+func sessionPod(name, session string, phase corev1.PodPhase) corev1.Pod {
+	labels := map[string]string{"app": "streamspace-session"}
+	if session != "" {
+		labels["session"] = session
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func podUsage(name string, containers ...corev1.ResourceList) metricsv1beta1.PodMetrics {
+	pm := metricsv1beta1.PodMetrics{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, usage := range containers {
+		pm.Containers = append(pm.Containers, metricsv1beta1.ContainerMetrics{Usage: usage})
+	}
+	return pm
+}
+
+func usage(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func testSource() *fakePodSource {
+	return &fakePodSource{
+		pods: &corev1.PodList{Items: []corev1.Pod{
+			sessionPod("user1-firefox-abc", "user1-firefox", corev1.PodRunning),
+			sessionPod("user2-vscode-def", "user2-vscode", corev1.PodPending),
+			sessionPod("streamspace-api-xyz", "", corev1.PodRunning),
+		}},
+		metrics: &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			podUsage("user1-firefox-abc", usage("250m", "512Mi"), usage("50m", "64Mi")),
+			podUsage("user2-vscode-def", usage("100m", "128Mi")),
+			podUsage("streamspace-api-xyz", usage("1", "1Gi")),
+		}},
+	}
+}
+
+func TestAggregateSessionUsage(t *testing.T) {
+	source := testSource()
+
+	result := aggregateSessionUsage(source.pods, source.metrics)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "user1-firefox", result[0].SessionID)
+	assert.Equal(t, int64(300), result[0].CPUMillicores)
+	assert.Equal(t, int64(576*1024*1024), result[0].MemoryBytes)
+}
+
+func TestCollect_UpsertsAndPrunes(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	rc := NewResourceCollector(db.NewDatabaseFromDB(mockDB), testSource(), "streamspace")
+
+	mock.ExpectExec("INSERT INTO session_resource_metrics").
+		WithArgs("user1-firefox", sqlmock.AnyArg(), int64(300), int64(576*1024*1024)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM session_resource_metrics").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, rc.Collect(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCollect_MetricsAPIUnavailable(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	source := testSource()
+	source.metricsErr = errors.New("the server could not find the requested resource")
+	rc := NewResourceCollector(db.NewDatabaseFromDB(mockDB), source, "streamspace")
+
+	assert.Error(t, rc.Collect(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,258 @@
+// Package middleware provides HTTP middleware for the StreamSpace API.
+// This file implements in-process response caching for read endpoints.
+//
+// Purpose:
+// Some GET endpoints (the plugin catalog) run expensive queries for data that
+// rarely changes. CacheResponse keeps their successful responses in memory
+// for a fixed TTL, so repeated requests skip the handler entirely.
+//
+// Behavior:
+//   - Only GET requests with a 200 response are cached, keyed by the output
+//     of a key function (see CacheKeyWithParams)
+//   - A cached response is served with X-Cache: HIT; a response produced by
+//     the handler gets X-Cache: MISS
+//   - Both carry Cache-Control: max-age=N, N being the seconds left until
+//     the entry expires
+//   - Expired entries are never served. At most maxCachedResponses entries
+//     are kept; when full, expired entries are dropped first, then those
+//     closest to expiry. Admins can drop entries early by key prefix
+//     through CacheInvalidateHandler
+//
+// Unlike cache.CacheMiddleware this cache is per replica and needs no Redis,
+// so replicas may serve different versions of a response for up to the TTL.
+//
+// Usage:
+//
+//	catalog.GET("", middleware.CacheResponse(time.Minute, middleware.CacheKeyWithParams("category", "sort")), h.BrowseCatalog)
+//	admin.POST("/cache/invalidate", middleware.CacheInvalidateHandler())
+package middleware
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedResponse is a response stored by CacheResponse.
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// maxCachedResponses bounds the entries of responseCache, so clients
+// varying query parameters cannot grow it without limit.
+const maxCachedResponses = 1000
+
+// responseCache holds the cached responses of every CacheResponse
+// middleware, keyed by their key functions' output.
+var responseCache = struct {
+	sync.Mutex
+	entries map[string]*cachedResponse
+}{entries: map[string]*cachedResponse{}}
+
+// loadCachedResponse returns the unexpired entry for key, if any.
+func loadCachedResponse(key string) (*cachedResponse, bool) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	cached, ok := responseCache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(cached.expiresAt) {
+		delete(responseCache.entries, key)
+		return nil, false
+	}
+	return cached, true
+}
+
+// storeCachedResponse stores entry under key, evicting entries if the
+// cache is full.
+func storeCachedResponse(key string, entry *cachedResponse) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	if _, exists := responseCache.entries[key]; !exists && len(responseCache.entries) >= maxCachedResponses {
+		now := time.Now()
+		for k, cached := range responseCache.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(responseCache.entries, k)
+			}
+		}
+		for len(responseCache.entries) >= maxCachedResponses {
+			var oldest string
+			for k, cached := range responseCache.entries {
+				if oldest == "" || cached.expiresAt.Before(responseCache.entries[oldest].expiresAt) {
+					oldest = k
+				}
+			}
+			delete(responseCache.entries, oldest)
+		}
+	}
+	responseCache.entries[key] = entry
+}
+
+// cacheCaptureWriter copies the response body while writing it, and adds
+// the cache headers to successful responses.
+type cacheCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+	ttl  time.Duration
+}
+
+func (w *cacheCaptureWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		w.Header().Set("X-Cache", "MISS")
+		w.Header().Set("Cache-Control", maxAge(w.ttl))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// CacheResponse caches successful GET responses for ttl.
+//
+// keyFunc returns the cache key of a request; requests for which it returns
+// "" are not cached. Keys must include everything the response depends on,
+// such as query parameters.
+func CacheResponse(ttl time.Duration, keyFunc func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if cached, ok := loadCachedResponse(key); ok {
+			c.Header("X-Cache", "HIT")
+			c.Header("Cache-Control", maxAge(time.Until(cached.expiresAt)))
+			c.Data(http.StatusOK, cached.contentType, cached.body)
+			c.Abort()
+			return
+		}
+
+		writer := &cacheCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, ttl: ttl}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK {
+			return
+		}
+		storeCachedResponse(key, &cachedResponse{
+			contentType: writer.Header().Get("Content-Type"),
+			body:        writer.body.Bytes(),
+			expiresAt:   time.Now().Add(ttl),
+		})
+	}
+}
+
+// maxAge formats a Cache-Control max-age directive, rounding up to whole
+// seconds.
+func maxAge(d time.Duration) string {
+	return "max-age=" + strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// CacheKeyWithQuery keys a request by its path and all query parameters.
+//
+// Query parameters are sorted, so ?a=1&b=2 and ?b=2&a=1 share an entry.
+// Keys start with the request path, e.g.
+// "/api/v1/plugins/catalog?category=analytics&sort=rating".
+func CacheKeyWithQuery(c *gin.Context) string {
+	return cacheKey(c.Request.URL.Path, c.Request.URL.Query())
+}
+
+// CacheKeyWithParams returns a key function like CacheKeyWithQuery that
+// only includes the given query parameters. Other parameters do not affect
+// the response, so requests that only differ in them share an entry.
+func CacheKeyWithParams(params ...string) func(*gin.Context) string {
+	return func(c *gin.Context) string {
+		query := c.Request.URL.Query()
+		kept := url.Values{}
+		for _, param := range params {
+			if values, ok := query[param]; ok {
+				kept[param] = values
+			}
+		}
+		return cacheKey(c.Request.URL.Path, kept)
+	}
+}
+
+// cacheKey joins a path and its encoded, sorted query parameters.
+func cacheKey(path string, query url.Values) string {
+	if encoded := query.Encode(); encoded != "" {
+		return path + "?" + encoded
+	}
+	return path
+}
+
+// InvalidateCache removes the cached responses whose key starts with
+// prefix, returning how many were removed. An empty prefix removes all.
+func InvalidateCache(prefix string) int {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	removed := 0
+	for key := range responseCache.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(responseCache.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// CacheInvalidateHandler removes cached responses by key prefix.
+//
+// Endpoint: POST /api/v1/admin/cache/invalidate
+//
+// Request Body:
+//
+//	{"prefix": "/api/v1/plugins/catalog"}
+//
+// Example Response:
+//
+//	{"prefix": "/api/v1/plugins/catalog", "invalidated": 12}
+//
+// HTTP Status Codes:
+//   - 200: Matching entries removed
+//   - 400: Missing prefix
+func CacheInvalidateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Prefix string `json:"prefix" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request",
+				"message": "prefix is required",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"prefix":      req.Prefix,
+			"invalidated": InvalidateCache(req.Prefix),
+		})
+	}
+}
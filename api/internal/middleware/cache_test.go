@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCachedRouter serves /catalog through CacheResponse and counts handler
+// calls. Responses are 500 while failing is set.
+func newCachedRouter(t *testing.T, ttl time.Duration) (*gin.Engine, *int, *bool) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { InvalidateCache("") })
+
+	calls := 0
+	failing := false
+	router := gin.New()
+	router.GET("/catalog", CacheResponse(ttl, CacheKeyWithQuery), func(c *gin.Context) {
+		calls++
+		if failing {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db down"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"call": calls, "sort": c.Query("sort")})
+	})
+	router.POST("/cache/invalidate", CacheInvalidateHandler())
+	return router, &calls, &failing
+}
+
+func getCached(router *gin.Engine, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestCacheResponse_ServesHits(t *testing.T) {
+	router, calls, _ := newCachedRouter(t, time.Minute)
+
+	w := getCached(router, "/catalog?sort=name&category=analytics")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, "max-age=60", w.Header().Get("Cache-Control"))
+
+	w = getCached(router, "/catalog?category=analytics&sort=name")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"call":1,"sort":"name"}`, w.Body.String())
+
+	// Different query parameters are cached separately
+	w = getCached(router, "/catalog?sort=rating")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCacheResponse_ExpiresAfterTTL(t *testing.T) {
+	router, calls, _ := newCachedRouter(t, 20*time.Millisecond)
+
+	getCached(router, "/catalog")
+	time.Sleep(40 * time.Millisecond)
+
+	w := getCached(router, "/catalog")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCacheResponse_SkipsErrors(t *testing.T) {
+	router, calls, failing := newCachedRouter(t, time.Minute)
+
+	*failing = true
+	w := getCached(router, "/catalog")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+
+	*failing = false
+	w = getCached(router, "/catalog")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestCacheInvalidateHandler(t *testing.T) {
+	router, calls, _ := newCachedRouter(t, time.Minute)
+
+	getCached(router, "/catalog?sort=name")
+	getCached(router, "/catalog?sort=rating")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cache/invalidate", strings.NewReader(`{"prefix":"/catalog"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"prefix":"/catalog","invalidated":2}`, w.Body.String())
+
+	w = getCached(router, "/catalog?sort=name")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 3, *calls)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cache/invalidate", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 1, InvalidateCache("/catalog"))
+}
+
+func TestCacheKeyWithParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keyFunc := CacheKeyWithParams("sort", "cursor")
+
+	key := func(target string) string {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return keyFunc(c)
+	}
+
+	assert.Equal(t, "/catalog?sort=name", key("/catalog?sort=name&x=random"))
+	assert.Equal(t, "/catalog", key("/catalog?x=random"))
+	assert.Equal(t, "/catalog?cursor=", key("/catalog?cursor="), "present but empty parameters are kept")
+}
+
+func TestCacheResponse_BoundsEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { InvalidateCache("") })
+
+	router := gin.New()
+	router.GET("/catalog", CacheResponse(time.Minute, CacheKeyWithQuery), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"x": c.Query("x")})
+	})
+
+	for i := 0; i < maxCachedResponses+50; i++ {
+		getCached(router, "/catalog?x="+strconv.Itoa(i))
+	}
+
+	responseCache.Lock()
+	entries := len(responseCache.entries)
+	_, newest := responseCache.entries["/catalog?x="+strconv.Itoa(maxCachedResponses+49)]
+	responseCache.Unlock()
+	assert.Equal(t, maxCachedResponses, entries)
+	assert.True(t, newest)
+}
@@ -27,4 +27,11 @@ const (
 
 	// CleanupThreshold is the age threshold for removing old entries
 	CleanupThreshold = 10 * time.Minute
+
+	// APIRateLimitRequests is the maximum number of API requests per user
+	// (or per IP when unauthenticated) within APIRateLimitWindow
+	APIRateLimitRequests = 1000
+
+	// APIRateLimitWindow is the time window for API rate limiting
+	APIRateLimitWindow = 1 * time.Minute
 )
@@ -26,24 +26,39 @@
 // - So attacker cannot get the token to put in the custom header
 //
 // Implementation Details:
-// - Token: 32 random bytes, base64-encoded (256 bits of entropy)
+// - Token: nonce.expiry.signature, where signature is
+//   HMAC-SHA256(secret, userID + sessionID + nonce + expiry)
+// - Nonce: 32 random bytes, base64-encoded (256 bits of entropy)
+// - Binding: tokens only verify for the user and JWT session they were issued to
+// - Expiry: same as the user's JWT (taken from the "claims" exp claim)
+// - Storage: none - tokens are verified by recomputing the HMAC, so there is
+//   no in-memory map, no cleanup goroutine, and tokens survive API restarts
+//   and work across replicas that share the JWT secret
 // - Comparison: Constant-time (prevents timing attacks)
-// - Storage: In-memory map with automatic cleanup (24-hour expiry)
 // - Exempt: GET, HEAD, OPTIONS requests (safe methods, no state change)
+// - Exempt: API key requests (authMethod "apikey"); the key is sent in the
+//   Authorization header, which browsers never attach automatically
 //
 // Usage:
-//   router.Use(middleware.CSRFProtection())
+//   // After the auth middleware, using the JWT signing secret
+//   protected.Use(authMiddleware)
+//   protected.Use(middleware.CSRFProtection(jwtSecret))
 package middleware
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // CSRF Constants define CSRF protection configuration.
@@ -57,27 +72,11 @@ const (
 	// CSRFCookieName is the name of the CSRF cookie
 	CSRFCookieName = "csrf_token"
 
-	// CSRFTokenExpiry is how long CSRF tokens are valid
+	// CSRFTokenExpiry is how long CSRF tokens are valid when the request
+	// carries no JWT expiry (tokens normally expire with the user's JWT)
 	CSRFTokenExpiry = 24 * time.Hour
 )
 
-// CSRFStore stores CSRF tokens with expiration
-type CSRFStore struct {
-	tokens map[string]time.Time
-	mu     sync.RWMutex
-}
-
-var (
-	globalCSRFStore = &CSRFStore{
-		tokens: make(map[string]time.Time),
-	}
-	csrfCleanupOnce sync.Once
-
-	// tokenGenerationMu protects against race conditions when multiple
-	// GET requests arrive simultaneously and try to generate new tokens
-	tokenGenerationMu sync.Mutex
-)
-
 // generateCSRFToken generates a cryptographically secure random CSRF token.
 //
 // The token is used in the double-submit cookie pattern to prevent CSRF attacks.
@@ -148,114 +147,60 @@ func generateCSRFToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// addToken adds a token to the store with expiration
-func (cs *CSRFStore) addToken(token string) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
-	cs.tokens[token] = time.Now().Add(CSRFTokenExpiry)
-}
-
-// validateToken checks if a token is valid and not expired
-func (cs *CSRFStore) validateToken(token string) bool {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
-	
-	expiry, exists := cs.tokens[token]
-	if !exists {
-		return false
-	}
-	
-	// Check if expired
-	if time.Now().After(expiry) {
-		return false
+// csrfTokenExpiry returns when a CSRF token issued for this request expires.
+//
+// Tokens share the lifetime of the user's JWT so that logging in again (and
+// only that) is needed to obtain a fresh one. The auth middleware stores the
+// parsed claims under "claims"; any claims type exposing the standard exp
+// claim works. Requests without an exp claim fall back to CSRFTokenExpiry.
+func csrfTokenExpiry(c *gin.Context) time.Time {
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(interface {
+			GetExpirationTime() (*jwt.NumericDate, error)
+		}); ok {
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				return exp.Time
+			}
+		}
 	}
-	
-	return true
-}
-
-// removeToken removes a token from the store
-func (cs *CSRFStore) removeToken(token string) {
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
-	delete(cs.tokens, token)
+	return time.Now().Add(CSRFTokenExpiry)
 }
 
-// cleanup removes expired tokens from the store periodically.
-//
-// This background goroutine prevents the token store from growing unbounded
-// by removing expired tokens every hour.
-//
-// CLEANUP STRATEGY:
-//
-// - Runs every 1 hour
-// - Scans all tokens in store
-// - Deletes tokens where expiry < now
-// - Holds write lock only during deletion (not entire hour)
-//
-// MEMORY MANAGEMENT:
-//
-// Without cleanup, tokens would accumulate indefinitely:
-// - 1000 req/sec = 86,400,000 tokens/day
-// - Each token ~100 bytes (string + time.Time)
-// - Total: ~8.6 GB/day memory leak
-//
-// With hourly cleanup:
-// - Max tokens = requests in 24 hours (token expiry)
-// - Cleanup removes tokens > 24 hours old
-// - Steady-state memory usage
-//
-// CONCURRENCY SAFETY:
-//
-// - Uses mu.Lock() for write access
-// - Safe to run concurrently with addToken/validateToken
-// - Other goroutines block during cleanup but only briefly
-//
-// WHY HOURLY:
-//
-// - Balance between memory usage and CPU overhead
-// - More frequent = less memory, more CPU
-// - Less frequent = more memory, less CPU
-// - 1 hour is reasonable middle ground
-//
-// PRODUCTION ALTERNATIVES:
-//
-// For high-traffic production:
-// 1. Use Redis for token storage (automatic expiry)
-// 2. Use LRU cache with size limit
-// 3. Use database with TTL index
-// 4. Partition tokens by expiry time (faster cleanup)
-//
-// GOROUTINE LIFECYCLE:
+// signCSRFToken computes the HMAC binding a nonce and expiry to a user session.
 //
-// This goroutine runs forever (until process exits).
-// Started once via sync.Once in CSRFProtection middleware.
-// No graceful shutdown implemented (acceptable for background task).
-func (cs *CSRFStore) cleanup() {
-	// Create ticker that fires every hour
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	// Infinite loop: cleanup on every tick
-	for range ticker.C {
-		// STEP 1: Acquire write lock
-		// Blocks all token operations during cleanup
-		cs.mu.Lock()
+// Fields are joined with "|"; the nonce is base64url and the expiry is
+// numeric, so neither can contain the separator.
+func signCSRFToken(secret []byte, userID, sessionID, nonce string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(userID + "|" + sessionID + "|" + nonce + "|" + strconv.FormatInt(expiresAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
 
-		// STEP 2: Get current time for comparison
-		now := time.Now()
+// issueCSRFToken creates a new signed token: nonce.expiry.signature
+func issueCSRFToken(secret []byte, userID, sessionID string, expiresAt time.Time) (string, error) {
+	nonce, err := generateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	exp := expiresAt.Unix()
+	return nonce + "." + strconv.FormatInt(exp, 10) + "." + signCSRFToken(secret, userID, sessionID, nonce, exp), nil
+}
 
-		// STEP 3: Scan all tokens
-		// Delete those that have expired
-		for token, expiry := range cs.tokens {
-			if now.After(expiry) {
-				delete(cs.tokens, token)
-			}
-		}
+// verifyCSRFToken checks that token was issued by this server for the given
+// user session and has not expired. No server-side state is consulted.
+func verifyCSRFToken(secret []byte, token, userID, sessionID string, now time.Time) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || userID == "" {
+		return false
+	}
 
-		// STEP 4: Release lock
-		// Allow other operations to proceed
-		cs.mu.Unlock()
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= exp {
+		return false
 	}
+
+	expected := signCSRFToken(secret, userID, sessionID, parts[0], exp)
+	return subtle.ConstantTimeCompare([]byte(parts[2]), []byte(expected)) == 1
 }
 
 // CSRFProtection returns a Gin middleware that protects against Cross-Site Request
@@ -294,9 +239,10 @@ func (cs *CSRFStore) cleanup() {
 //
 // Safe Request (GET, HEAD, OPTIONS):
 //   1. Client: GET /api/sessions
-//   2. Server: Generates CSRF token (e.g., "abc123...")
+//   2. Server: Reuses the csrf_token cookie if it still verifies for this
+//      user session, otherwise signs a new token (e.g., "abc123...")
 //   3. Server: Sets X-CSRF-Token header to "abc123..."
-//   4. Server: Sets csrf_token cookie to "abc123..."
+//   4. Server: Sets csrf_token cookie to "abc123..." (expires with the JWT)
 //   5. Client: Stores token from header in memory/localStorage
 //   6. Response returned
 //
@@ -307,7 +253,8 @@ func (cs *CSRFStore) cleanup() {
 //   4. Server: Reads token from header → "abc123..."
 //   5. Server: Reads token from cookie → "abc123..."
 //   6. Server: Compares using constant-time comparison
-//   7. Server: Validates token exists and not expired
+//   7. Server: Recomputes the HMAC for the current user and JWT session and
+//      checks the embedded expiry
 //   8. If all checks pass: Request processed
 //   9. If any check fails: 403 Forbidden
 //
@@ -328,10 +275,10 @@ func (cs *CSRFStore) cleanup() {
 //    - Timing attack: measure comparison time to guess token byte-by-byte
 //    - Constant-time: comparison always takes same time regardless of input
 //
-// 2. Token Expiration:
-//    - Tokens expire after 24 hours
-//    - Limits window for token theft
-//    - Forces periodic token refresh
+// 2. Session Binding and Expiration:
+//    - Tokens are signed for one user and one JWT session ID
+//    - A token stolen from one session is useless in any other
+//    - Tokens expire together with the JWT they were issued under
 //
 // 3. Cryptographically Secure Random:
 //    - Uses crypto/rand (not math/rand)
@@ -349,7 +296,8 @@ func (cs *CSRFStore) cleanup() {
 // USAGE:
 //
 //   router := gin.Default()
-//   router.Use(middleware.CSRFProtection())
+//   router.Use(authMiddleware) // sets userID, sessionID and claims
+//   router.Use(middleware.CSRFProtection(jwtSecret))
 //
 //   // All routes now protected:
 //   router.GET("/api/sessions", handler)  // Generates token
@@ -406,9 +354,9 @@ func (cs *CSRFStore) cleanup() {
 //    - Attacker can read token from headers
 //    - Mitigation: Prevent XSS (input validation, CSP)
 //
-// 3. Token Storage:
-//    - Tokens stored in memory (lost on restart)
-//    - Mitigation: Use Redis for persistent storage
+// 3. Secret Rotation:
+//    - Tokens are signed with the JWT secret
+//    - Rotating JWT_SECRET invalidates all CSRF tokens (and all JWTs)
 //
 // COMMON ERRORS:
 //
@@ -421,98 +369,81 @@ func (cs *CSRFStore) cleanup() {
 //   - Solution: Ensure X-CSRF-Token header is set correctly
 //
 // "CSRF token invalid":
-//   - Token expired (>24 hours old)
-//   - Server restarted (tokens lost)
+//   - Token expired (its JWT expired)
+//   - Token was issued for a different user or login session
 //   - Solution: Refresh token by making GET request
-func CSRFProtection() gin.HandlerFunc {
-	// INITIALIZATION: Start cleanup goroutine once
-	//
-	// sync.Once ensures cleanup goroutine is started exactly once,
-	// even if CSRFProtection is called multiple times.
-	//
-	// WHY ONCE: Prevents multiple cleanup goroutines from running
-	// and competing for the same lock.
-	csrfCleanupOnce.Do(func() {
-		go globalCSRFStore.cleanup()
-	})
+func CSRFProtection(secret string) gin.HandlerFunc {
+	key := []byte(secret)
 
 	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		sessionID := c.GetString("sessionID")
+
+		// API key clients are not browsers and carry no ambient credentials
+		if c.GetString("authMethod") == "apikey" {
+			c.Next()
+			return
+		}
+
 		// BRANCH 1: SAFE METHODS (GET, HEAD, OPTIONS)
 		//
-		// These methods should not modify state, so we generate and send
-		// a new CSRF token for use in subsequent state-changing requests.
+		// These methods should not modify state, so we issue a CSRF token
+		// for use in subsequent state-changing requests.
 		//
 		// WHY EXEMPT: Safe methods are idempotent and read-only by HTTP specification.
 		// They should not have side effects, so CSRF is not a risk.
 		if c.Request.Method == "GET" || c.Request.Method == "HEAD" || c.Request.Method == "OPTIONS" {
-			// Use mutex to prevent race conditions with parallel GET requests
-			// Without this, multiple simultaneous GETs could each generate a new token,
-			// causing the cookie and JavaScript to have different tokens
-			tokenGenerationMu.Lock()
+			// No authenticated user: nothing to bind a token to
+			if userID == "" {
+				c.Next()
+				return
+			}
 
-			// Check if client already has a valid token
-			// Reuse existing token to prevent token churn that causes mismatches
+			// Reuse existing token while it still verifies for this user session
+			// to prevent token churn that causes mismatches
 			existingToken, err := c.Cookie(CSRFCookieName)
-			if err == nil && existingToken != "" && globalCSRFStore.validateToken(existingToken) {
-				// Existing token is still valid, send it back in header
-				tokenGenerationMu.Unlock()
+			if err == nil && verifyCSRFToken(key, existingToken, userID, sessionID, time.Now()) {
 				c.Header(CSRFTokenHeader, existingToken)
 				c.Next()
 				return
 			}
 
-			// STEP 1: Generate new CSRF token
-			// Uses crypto/rand for cryptographic security
-			token, err := generateCSRFToken()
+			// STEP 1: Sign a new token bound to this user and JWT session
+			expiresAt := csrfTokenExpiry(c)
+			token, err := issueCSRFToken(key, userID, sessionID, expiresAt)
 			if err != nil {
 				// CRITICAL ERROR: Cannot generate secure random
-				// This indicates serious system issues (no entropy)
 				// Do NOT proceed without CSRF protection
-				tokenGenerationMu.Unlock()
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"error": "Failed to generate CSRF token",
 				})
 				return
 			}
 
-			// STEP 2: Store token in server-side store
-			// Required for validation in step 4 of state-changing requests
-			globalCSRFStore.addToken(token)
-
-			// STEP 3: Send token in response header
+			// STEP 2: Send token in response header
 			// JavaScript clients read this header and store token
 			c.Header(CSRFTokenHeader, token)
 
-			// STEP 4: Send token in cookie
-			// Browser automatically sends this cookie on subsequent requests
+			// STEP 3: Send token in cookie, expiring with the JWT
 			//
-			// Cookie parameters:
-			// - Name: "csrf_token"
-			// - Value: token (e.g., "abc123...")
-			// - MaxAge: 86400 seconds (24 hours)
-			// - Path: "/" (available to all endpoints)
-			// - Domain: "" (current domain only)
 			// - Secure: true in production (HTTPS-only), false in debug mode
 			// - HttpOnly: true (not accessible to JavaScript - prevents XSS)
-
-			// Determine if we should use secure cookies
-			// In debug/development mode, allow HTTP for local testing
+			maxAge := int(time.Until(expiresAt).Seconds())
+			if maxAge < 1 {
+				maxAge = 1
+			}
 			secureCookie := gin.Mode() != gin.DebugMode
 
 			c.SetCookie(
 				CSRFCookieName,
 				token,
-				int(CSRFTokenExpiry.Seconds()),
+				maxAge,
 				"/",
 				"",
 				secureCookie, // Secure: HTTPS-only in production, HTTP allowed in debug
 				true,         // HttpOnly: JavaScript cannot access (XSS protection)
 			)
 
-			// Release lock after setting cookie so subsequent parallel requests use this token
-			tokenGenerationMu.Unlock()
-
-			// Continue to next handler
 			c.Next()
 			return
 		}
@@ -531,9 +462,6 @@ func CSRFProtection() gin.HandlerFunc {
 		// Browser sends this automatically (even for cross-site requests)
 		cookieToken, err := c.Cookie(CSRFCookieName)
 		if err != nil {
-			// Cookie not found: User never made a GET request to get token
-			// OR: Cookie expired
-			// OR: Browser blocked cookies
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "CSRF token missing",
 				"message": "CSRF cookie not found",
@@ -544,28 +472,9 @@ func CSRFProtection() gin.HandlerFunc {
 		// STEP 3: Compare tokens using constant-time comparison
 		//
 		// SECURITY: MUST use subtle.ConstantTimeCompare, NOT ==
-		//
-		// WHY CONSTANT-TIME:
-		// Regular comparison (==) returns immediately on first mismatch:
-		//   "abc123" == "xyz123"
-		//        ^-- Mismatch at position 0, returns in ~1ns
-		//   "abc123" == "abc999"
-		//           ^-- Mismatch at position 3, returns in ~3ns
-		//
-		// Attacker can measure response time to guess token byte-by-byte:
-		//   Try "a??????" → 1ns → correct first byte
-		//   Try "b??????" → 0ns → incorrect first byte
-		//   Try "ab?????" → 2ns → correct second byte
-		//   ... repeat to recover entire token
-		//
-		// Constant-time comparison always takes same time:
-		//   "abc123" == "xyz123" → always 6ns
-		//   "abc123" == "abc999" → always 6ns
-		//
-		// Returns 1 if equal, 0 if not equal
+		// Regular comparison returns on the first mismatching byte, which
+		// lets an attacker recover a token byte-by-byte from response times.
 		if subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) != 1 {
-			// Tokens don't match: CSRF attack detected
-			// OR: Client bug (not sending header correctly)
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "CSRF token mismatch",
 				"message": "CSRF tokens do not match",
@@ -573,12 +482,10 @@ func CSRFProtection() gin.HandlerFunc {
 			return
 		}
 
-		// STEP 4: Validate token exists in store and is not expired
-		// Even if tokens match, verify token was issued by this server
-		// and has not expired (>24 hours old)
-		if !globalCSRFStore.validateToken(cookieToken) {
-			// Token expired or never existed
-			// User needs to refresh token by making GET request
+		// STEP 4: Verify the signature for this user session and the expiry.
+		// Even if tokens match, the token must have been signed by this
+		// server for the authenticated user's current JWT session.
+		if !verifyCSRFToken(key, cookieToken, userID, sessionID, time.Now()) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error":   "CSRF token invalid",
 				"message": "CSRF token has expired or is invalid",
@@ -587,7 +494,6 @@ func CSRFProtection() gin.HandlerFunc {
 		}
 
 		// All checks passed: Request is legitimate
-		// Continue to next handler
 		c.Next()
 	}
 }
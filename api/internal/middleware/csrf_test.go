@@ -3,74 +3,124 @@
 // cross-site request forgery attacks while allowing legitimate requests.
 //
 // Tests validate:
-// - Tokens can be added and validated successfully
-// - Invalid tokens are rejected
-// - Expired tokens are correctly identified and rejected
-// - Cleanup removes expired tokens to prevent memory leaks
-// - Double-submit cookie pattern works correctly
+// - Signed tokens verify for the user session they were issued to
+// - Tokens for another user or JWT session are rejected
+// - Expired and tampered tokens are rejected
+// - Double-submit cookie pattern works correctly end to end
 package middleware
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
-func TestCSRFStore_AddAndValidateToken(t *testing.T) {
-	store := &CSRFStore{
-		tokens: make(map[string]time.Time),
+var testCSRFSecret = []byte("test-jwt-secret-at-least-32-characters")
+
+func TestVerifyCSRFToken_BoundToUserSession(t *testing.T) {
+	token, err := issueCSRFToken(testCSRFSecret, "user1", "session1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	if !verifyCSRFToken(testCSRFSecret, token, "user1", "session1", time.Now()) {
+		t.Error("Token should verify for the issuing user session")
 	}
 
-	token := "test-token-12345"
-	
-	// Add token
-	store.addToken(token)
+	if verifyCSRFToken(testCSRFSecret, token, "user2", "session1", time.Now()) {
+		t.Error("Token should not verify for another user")
+	}
 
-	// Should be valid
-	if !store.validateToken(token) {
-		t.Error("Token should be valid after adding")
+	if verifyCSRFToken(testCSRFSecret, token, "user1", "session2", time.Now()) {
+		t.Error("Token should not verify for another JWT session")
 	}
 
-	// Invalid token should not validate
-	if store.validateToken("invalid-token") {
-		t.Error("Invalid token should not validate")
+	if verifyCSRFToken([]byte("different-secret"), token, "user1", "session1", time.Now()) {
+		t.Error("Token should not verify with a different secret")
 	}
 }
 
-func TestCSRFStore_TokenExpiry(t *testing.T) {
-	store := &CSRFStore{
-		tokens: make(map[string]time.Time),
+func TestVerifyCSRFToken_Expiry(t *testing.T) {
+	token, err := issueCSRFToken(testCSRFSecret, "user1", "session1", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
 	}
 
-	token := "test-token"
-	
-	// Add token with past expiry (simulate expired token)
-	store.tokens[token] = time.Now().Add(-1 * time.Hour)
-
-	// Should not be valid (expired)
-	if store.validateToken(token) {
-		t.Error("Expired token should not validate")
+	if verifyCSRFToken(testCSRFSecret, token, "user1", "session1", time.Now().Add(2*time.Minute)) {
+		t.Error("Expired token should not verify")
 	}
 }
 
-func TestCSRFStore_RemoveToken(t *testing.T) {
-	store := &CSRFStore{
-		tokens: make(map[string]time.Time),
+func TestVerifyCSRFToken_Tampered(t *testing.T) {
+	token, err := issueCSRFToken(testCSRFSecret, "user1", "session1", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	// Extending the expiry invalidates the signature
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + "99999999999" + "." + parts[2]
+	if verifyCSRFToken(testCSRFSecret, forged, "user1", "session1", time.Now()) {
+		t.Error("Token with modified expiry should not verify")
 	}
 
-	token := "test-token"
-	store.addToken(token)
+	for _, bad := range []string{"", "abc", "a.b", "a.notanumber.c"} {
+		if verifyCSRFToken(testCSRFSecret, bad, "user1", "session1", time.Now()) {
+			t.Errorf("Malformed token %q should not verify", bad)
+		}
+	}
+}
 
-	// Verify exists
-	if !store.validateToken(token) {
-		t.Error("Token should exist before removal")
+func TestCSRFProtection_DoubleSubmit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "user1")
+		c.Set("sessionID", "session1")
+		c.Next()
+	})
+	router.Use(CSRFProtection(string(testCSRFSecret)))
+	router.GET("/resource", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/resource", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// GET issues a token in header and cookie
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/resource", nil))
+	token := w.Header().Get(CSRFTokenHeader)
+	if token == "" {
+		t.Fatal("Expected CSRF token header on GET")
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatal("Expected csrf_token cookie on GET")
+	}
+	if value, _ := url.QueryUnescape(cookies[0].Value); value != token {
+		t.Fatal("Expected csrf_token cookie matching the header token")
 	}
 
-	// Remove
-	store.removeToken(token)
+	// POST with matching header and cookie succeeds
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/resource", nil)
+	req.AddCookie(cookies[0])
+	req.Header.Set(CSRFTokenHeader, token)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 with valid token, got %d", w.Code)
+	}
 
-	// Should no longer validate
-	if store.validateToken(token) {
-		t.Error("Token should not validate after removal")
+	// POST without header is rejected
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/resource", nil)
+	req.AddCookie(cookies[0])
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without header token, got %d", w.Code)
 	}
 }
 
@@ -0,0 +1,139 @@
+// Package middleware provides HTTP middleware for the StreamSpace API.
+// This file implements request and background work draining for graceful
+// shutdown.
+//
+// Purpose:
+// http.Server.Shutdown stops accepting connections and waits for in-flight
+// requests, but requests arriving on already open keep-alive connections
+// would still start new work, and background goroutines (repository sync,
+// metrics collection) are not covered at all. A Drainer closes both gaps:
+//
+//   - Once StartDraining is called, new requests get 503 Service Unavailable
+//     with Retry-After and Connection: close, so load balancers and clients
+//     move to another replica
+//   - Background goroutines started with Go are tracked by name, and Wait
+//     reports which ones are still running when the drain timeout expires
+//
+// Thread Safety:
+// Safe for concurrent use.
+//
+// Usage:
+//
+//	drainer := middleware.NewDrainer()
+//	router.Use(drainer.Middleware())
+//	drainer.Go("repository-sync", func() { syncService.StartScheduledSync(ctx, interval) })
+//
+//	// On SIGTERM
+//	drainer.StartDraining()
+//	cancelBackgroundWork()
+//	srv.Shutdown(ctx)
+//	if running := drainer.Wait(ctx); len(running) > 0 {
+//	    log.Fatalf("Goroutines still running: %v", running)
+//	}
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// drainRetryAfter is the Retry-After sent to requests rejected while draining
+const drainRetryAfter = 5 * time.Second
+
+// Drainer rejects new requests and tracks background goroutines during
+// graceful shutdown.
+type Drainer struct {
+	draining atomic.Bool
+
+	mu      sync.Mutex
+	running map[string]int
+	wg      sync.WaitGroup
+}
+
+// NewDrainer creates a Drainer that accepts requests until StartDraining.
+func NewDrainer() *Drainer {
+	return &Drainer{running: make(map[string]int)}
+}
+
+// Middleware returns 503 for every request once draining has started.
+func (d *Drainer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !d.Draining() {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(drainRetryAfter.Seconds())))
+		c.Header("Connection", "close")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Service unavailable",
+			"message": "Server is shutting down",
+		})
+	}
+}
+
+// StartDraining makes the middleware reject new requests.
+func (d *Drainer) StartDraining() {
+	d.draining.Store(true)
+}
+
+// Draining reports whether StartDraining was called.
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Go runs fn in a goroutine tracked under name until it returns. Callers
+// are responsible for making fn return on shutdown (cancel its context or
+// call its Stop method) before Wait.
+func (d *Drainer) Go(name string, fn func()) {
+	d.mu.Lock()
+	d.running[name]++
+	d.mu.Unlock()
+	d.wg.Add(1)
+
+	go func() {
+		defer func() {
+			d.mu.Lock()
+			if d.running[name]--; d.running[name] == 0 {
+				delete(d.running, name)
+			}
+			d.mu.Unlock()
+			d.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+// Wait waits for the goroutines started with Go to return or for ctx to be
+// done. It returns the sorted names of the goroutines still running, or nil
+// if all of them finished.
+func (d *Drainer) Wait(ctx context.Context) []string {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	running := make([]string, 0, len(d.running))
+	for name := range d.running {
+		running = append(running, name)
+	}
+	sort.Strings(running)
+	return running
+}
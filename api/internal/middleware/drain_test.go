@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainer_RejectsRequestsWhileDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	drainer := NewDrainer()
+
+	router := gin.New()
+	router.Use(drainer.Middleware())
+	router.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	drainer.StartDraining()
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+}
+
+func TestDrainer_WaitReportsRunningGoroutines(t *testing.T) {
+	drainer := NewDrainer()
+
+	stop := make(chan struct{})
+	drainer.Go("finishes", func() {})
+	drainer.Go("stuck", func() { <-stop })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, []string{"stuck"}, drainer.Wait(ctx))
+
+	close(stop)
+	assert.Nil(t, drainer.Wait(context.Background()))
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveIPFilter performs a GET to path from remoteIP through a router that
+// applies filter to every route.
+func serveIPFilter(filter gin.HandlerFunc, path, remoteIP string) *httptest.ResponseRecorder {
+	return serveIPFilterForwarded(filter, path, remoteIP, "")
+}
+
+// serveIPFilterForwarded is serveIPFilter with an X-Forwarded-For header.
+// Like main.go, the router trusts no proxies.
+func serveIPFilterForwarded(filter gin.HandlerFunc, path, remoteIP, forwardedFor string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.Use(filter)
+	router.GET("/*path", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = remoteIP + ":40000"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIPAllowList(t *testing.T) {
+	filter := IPAllowList([]string{"10.0.0.0/8", "203.0.113.7", "not-a-cidr"})
+
+	assert.Equal(t, http.StatusOK, serveIPFilter(filter, "/", "10.1.2.3").Code)
+	assert.Equal(t, http.StatusOK, serveIPFilter(filter, "/", "203.0.113.7").Code)
+
+	w := serveIPFilter(filter, "/", "203.0.113.8")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestIPBlockList(t *testing.T) {
+	filter := IPBlockList([]string{"198.51.100.0/24"})
+
+	w := serveIPFilter(filter, "/", "198.51.100.20")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, serveIPFilter(filter, "/", "10.1.2.3").Code)
+}
+
+func TestIPFilters_OpenWhenUnconfigured(t *testing.T) {
+	assert.Equal(t, http.StatusOK, serveIPFilter(IPAllowList(nil), "/", "203.0.113.8").Code)
+	assert.Equal(t, http.StatusOK, serveIPFilter(IPBlockList(nil), "/", "203.0.113.8").Code)
+}
+
+func TestIPAllowList_FailsClosedWhenNothingParses(t *testing.T) {
+	filter := IPAllowList([]string{"not-a-cidr", "10.0.0.0/33"})
+
+	assert.Equal(t, http.StatusForbidden, serveIPFilter(filter, "/", "10.1.2.3").Code)
+	assert.Equal(t, http.StatusForbidden, serveIPFilter(filter, "/", "127.0.0.1").Code)
+}
+
+func TestIPFilters_IgnoreSpoofedForwardedFor(t *testing.T) {
+	allow := IPAllowList([]string{"10.0.0.0/8"})
+	assert.Equal(t, http.StatusForbidden, serveIPFilterForwarded(allow, "/", "203.0.113.9", "10.1.2.3").Code)
+
+	block := IPBlockList([]string{"198.51.100.0/24"})
+	assert.Equal(t, http.StatusForbidden, serveIPFilterForwarded(block, "/", "198.51.100.20", "10.1.2.3").Code)
+}
+
+func TestOnPathPrefix(t *testing.T) {
+	filter := OnPathPrefix("/api/v1/admin/", IPAllowList([]string{"10.0.0.0/8"}))
+
+	assert.Equal(t, http.StatusForbidden, serveIPFilter(filter, "/api/v1/admin/nodes", "203.0.113.8").Code)
+	assert.Equal(t, http.StatusOK, serveIPFilter(filter, "/api/v1/admin/nodes", "10.0.0.5").Code)
+	assert.Equal(t, http.StatusOK, serveIPFilter(filter, "/api/v1/sessions", "203.0.113.8").Code)
+}
+
+func TestCIDRsFromEnv(t *testing.T) {
+	t.Setenv("ADMIN_ALLOW_CIDRS", " 10.0.0.0/8, ,192.168.1.1 ")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, CIDRsFromEnv("ADMIN_ALLOW_CIDRS"))
+
+	t.Setenv("ADMIN_ALLOW_CIDRS", "")
+	assert.Empty(t, CIDRsFromEnv("ADMIN_ALLOW_CIDRS"))
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, ParseLogLevel("DEBUG"))
+	assert.Equal(t, slog.LevelWarn, ParseLogLevel("warning"))
+	assert.Equal(t, slog.LevelError, ParseLogLevel("error"))
+	assert.Equal(t, slog.LevelInfo, ParseLogLevel(""))
+	assert.Equal(t, slog.LevelInfo, ParseLogLevel("verbose"))
+}
+
+func TestStructuredLogger_EmitsCorrelatedJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	config := DefaultStructuredLoggerConfig()
+	config.Logger = NewSlogLogger(&buf, slog.LevelInfo)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(StructuredLoggerWithConfigFunc(config))
+	router.GET("/sessions/:id", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Set("sessionID", "jwt-session-1")
+		GetLogger(c).Info("handler log")
+		c.Status(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest("GET", "/sessions/abc", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var handlerRecord, requestRecord map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerRecord))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &requestRecord))
+
+	assert.Equal(t, "handler log", handlerRecord["msg"])
+	assert.Equal(t, "req-123", handlerRecord["request_id"])
+	assert.Equal(t, "user-1", handlerRecord["user_id"])
+	assert.Equal(t, "jwt-session-1", handlerRecord["session_id"])
+
+	assert.Equal(t, "WARN", requestRecord["level"])
+	assert.Equal(t, "req-123", requestRecord["request_id"])
+	assert.Equal(t, "user-1", requestRecord["user_id"])
+	assert.Equal(t, float64(http.StatusNotFound), requestRecord["status"])
+	assert.Equal(t, "/sessions/abc", requestRecord["path"])
+}
@@ -0,0 +1,192 @@
+// Package middleware provides HTTP middleware for the StreamSpace API.
+// This file implements Prometheus metrics collection and the /metrics scrape endpoint.
+//
+// Purpose:
+// The existing GET /api/v1/metrics endpoint returns connection statistics as
+// JSON for the admin UI, which Prometheus cannot scrape. This middleware
+// records per-request metrics with the official client library and exposes
+// them in the Prometheus text format.
+//
+// Metrics:
+//   - http_requests_total (counter): labels method, path, status
+//   - http_request_duration_seconds (histogram): labels method, path, status
+//   - streamspace_active_sessions (gauge): sessions in running state
+//   - streamspace_active_websocket_connections (gauge): connected WebSocket clients
+//   - streamspace_snapshot_size_bytes_total (gauge): total size of stored snapshots
+//
+// Cardinality:
+// The path label uses the matched route template (e.g. /api/v1/sessions/:id)
+// rather than the raw URL, so session IDs and other parameters do not create
+// a new time series per request. Requests that match no route are recorded
+// with path "unmatched".
+//
+// Access Control:
+// The scrape endpoint is not behind JWT auth (Prometheus cannot log in).
+// Instead it only answers requests from loopback addresses or from the
+// CIDRs listed in METRICS_ALLOWED_CIDRS (comma-separated).
+//
+// Usage:
+//
+//	router.Use(middleware.PrometheusMetrics())
+//	middleware.RegisterStreamSpaceGauges(activeSessions, wsConnections, snapshotBytes)
+//	router.GET("/metrics", middleware.PrometheusHandler(middleware.MetricsAllowedCIDRsFromEnv()))
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests processed, by method, route and status code.",
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds, by method, route and status code.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	registerHTTPMetricsOnce sync.Once
+	registerGaugesOnce      sync.Once
+)
+
+// PrometheusMetrics returns middleware that records request count and latency.
+func PrometheusMetrics() gin.HandlerFunc {
+	registerHTTPMetricsOnce.Do(func() {
+		prometheus.MustRegister(httpRequestsTotal, httpRequestDuration)
+	})
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequestsTotal.WithLabelValues(c.Request.Method, path, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, path, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterStreamSpaceGauges registers the StreamSpace application gauges.
+//
+// Each gauge is computed on scrape by calling the provided function, so the
+// values are always current and nothing needs to be updated in the
+// background. Nil functions are skipped. Only the first call has any effect.
+func RegisterStreamSpaceGauges(activeSessions, websocketConnections, snapshotSizeBytes func() float64) {
+	registerGaugesOnce.Do(func() {
+		gauges := []struct {
+			name string
+			help string
+			fn   func() float64
+		}{
+			{"streamspace_active_sessions", "Number of sessions currently in the running state.", activeSessions},
+			{"streamspace_active_websocket_connections", "Number of connected WebSocket clients.", websocketConnections},
+			{"streamspace_snapshot_size_bytes_total", "Total size in bytes of all stored session snapshots.", snapshotSizeBytes},
+		}
+
+		for _, g := range gauges {
+			if g.fn == nil {
+				continue
+			}
+			prometheus.MustRegister(prometheus.NewGaugeFunc(
+				prometheus.GaugeOpts{Name: g.name, Help: g.help},
+				g.fn,
+			))
+		}
+	})
+}
+
+// MetricsAllowedCIDRsFromEnv parses METRICS_ALLOWED_CIDRS (comma-separated).
+//
+// Invalid entries are logged and ignored. Loopback addresses are always
+// allowed regardless of this list.
+func MetricsAllowedCIDRsFromEnv() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("METRICS_ALLOWED_CIDRS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("[Metrics] Ignoring invalid CIDR in METRICS_ALLOWED_CIDRS: %q", entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// PrometheusHandler serves the Prometheus scrape endpoint.
+//
+// Requests are only answered when the client IP is a loopback address or
+// falls within one of allowedCIDRs; all others receive 403 Forbidden.
+func PrometheusHandler(allowedCIDRs []*net.IPNet) gin.HandlerFunc {
+	handler := promhttp.Handler()
+
+	restrict := MetricsClientsOnly(allowedCIDRs)
+
+	return func(c *gin.Context) {
+		if restrict(c); c.IsAborted() {
+			return
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// MetricsClientsOnly restricts a route to the same clients as the scrape
+// endpoint: loopback addresses and allowedCIDRs. Other clients get 403.
+//
+// The client IP comes from c.ClientIP(), so X-Forwarded-For is only honored
+// from the router's trusted proxies (TRUSTED_PROXIES in main.go).
+func MetricsClientsOnly(allowedCIDRs []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !metricsClientAllowed(c.ClientIP(), allowedCIDRs) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Metrics endpoint is not accessible from this address",
+			})
+			return
+		}
+	}
+}
+
+// metricsClientAllowed reports whether ip may scrape metrics.
+func metricsClientAllowed(ip string, allowedCIDRs []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	if parsed.IsLoopback() {
+		return true
+	}
+	for _, network := range allowedCIDRs {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
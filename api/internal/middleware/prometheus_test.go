@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsClientAllowed(t *testing.T) {
+	_, podNet, _ := net.ParseCIDR("10.244.0.0/16")
+	allowed := []*net.IPNet{podNet}
+
+	assert.True(t, metricsClientAllowed("127.0.0.1", nil))
+	assert.True(t, metricsClientAllowed("::1", nil))
+	assert.True(t, metricsClientAllowed("10.244.3.7", allowed))
+	assert.False(t, metricsClientAllowed("10.245.0.1", allowed))
+	assert.False(t, metricsClientAllowed("203.0.113.5", nil))
+	assert.False(t, metricsClientAllowed("not-an-ip", allowed))
+}
+
+func TestPrometheusMetrics_RecordsRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(PrometheusMetrics())
+	router.GET("/sessions/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/metrics", PrometheusHandler(nil))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/sessions/abc-123", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.RemoteAddr = "127.0.0.1:9090"
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",path="/sessions/:id",status="200"}`)
+	assert.Contains(t, body, "http_request_duration_seconds_bucket")
+	assert.NotContains(t, body, "abc-123")
+}
+
+func TestPrometheusHandler_RejectsRemoteClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/metrics", PrometheusHandler(nil))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.RemoteAddr = "203.0.113.5:40000"
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMetricsClientsOnly_IgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantStatus     int
+	}{
+		{name: "untrusted peer", trustedProxies: nil, wantStatus: http.StatusForbidden},
+		{name: "trusted proxy", trustedProxies: []string{"203.0.113.9"}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			assert.NoError(t, router.SetTrustedProxies(tt.trustedProxies))
+			router.GET("/metrics/db", MetricsClientsOnly(nil), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/metrics/db", nil)
+			req.RemoteAddr = "203.0.113.9:40000"
+			req.Header.Set("X-Forwarded-For", "127.0.0.1")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
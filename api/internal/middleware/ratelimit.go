@@ -25,11 +25,18 @@
 //   if !limiter.CheckLimit("user:123:mfa", 5, 1*time.Minute) {
 //     return errors.New("rate limit exceeded")
 //   }
+//
+//   // As middleware on authenticated routes (keys on userID, falls back to IP)
+//   protected.Use(middleware.GetRateLimiter().UserMiddleware())
 package middleware
 
 import (
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 // RateLimiter implements a simple in-memory sliding window rate limiter.
@@ -44,19 +51,22 @@ import (
 //
 // Memory Management:
 // - Automatic cleanup runs every 5 minutes
-// - Removes entries older than 10 minutes
+// - Evicts keys not accessed for 10 minutes (tracked in lastAccess)
+// - Trims expired attempts of active keys without resetting them
 // - Prevents memory leaks from abandoned rate limits
 //
 // For production use with multiple API servers, replace with Redis-backed
 // implementation for distributed rate limiting.
 type RateLimiter struct {
-	attempts map[string][]time.Time
-	mu       sync.RWMutex
+	attempts   map[string][]time.Time
+	lastAccess map[string]time.Time
+	mu         sync.RWMutex
 }
 
 var (
 	globalRateLimiter = &RateLimiter{
-		attempts: make(map[string][]time.Time),
+		attempts:   make(map[string][]time.Time),
+		lastAccess: make(map[string]time.Time),
 	}
 	cleanupOnce sync.Once
 )
@@ -254,6 +264,7 @@ func (rl *RateLimiter) CheckLimit(key string, maxAttempts int, window time.Durat
 	defer rl.mu.Unlock()
 
 	now := time.Now()
+	rl.touch(key, now)
 
 	// Get existing attempts for this key
 	attempts, exists := rl.attempts[key]
@@ -285,9 +296,76 @@ func (rl *RateLimiter) CheckLimit(key string, maxAttempts int, window time.Durat
 
 // ResetLimit clears all attempts for a given key
 func (rl *RateLimiter) ResetLimit(key string) {
+	rl.Reset(key)
+}
+
+// Reset clears all attempts and the last-access time for a key.
+//
+// Intended for admin use, e.g. unblocking a user after a false positive.
+// Per-user middleware keys have the form "user:<userID>" (see UserKey);
+// unauthenticated requests are keyed by client IP.
+func (rl *RateLimiter) Reset(key string) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 	delete(rl.attempts, key)
+	delete(rl.lastAccess, key)
+}
+
+// UserKey returns the rate limit key used by UserMiddleware for a user.
+func UserKey(userID string) string {
+	return "user:" + userID
+}
+
+// UserMiddleware rate limits requests per authenticated user.
+//
+// Requests are keyed on the userID set by the auth middleware, so users
+// sharing one IP (NAT, corporate proxies) no longer share a limit. When no
+// user is authenticated the client IP is used instead. The limit is
+// APIRateLimitRequests per APIRateLimitWindow.
+//
+// Must be registered after the auth middleware.
+//
+// Response headers:
+//   - X-RateLimit-Limit: Maximum requests per window
+//   - X-RateLimit-Remaining: Requests left in the current window
+//   - Retry-After: Seconds to wait (only on 429)
+func (rl *RateLimiter) UserMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		if userID, exists := c.Get("userID"); exists {
+			if id, ok := userID.(string); ok && id != "" {
+				key = UserKey(id)
+			}
+		}
+
+		allowed := rl.CheckLimit(key, APIRateLimitRequests, APIRateLimitWindow)
+		remaining := APIRateLimitRequests - rl.GetAttempts(key, APIRateLimitWindow)
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(APIRateLimitRequests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(APIRateLimitWindow.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Rate limit exceeded",
+				"message": "Too many requests, please try again later",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// touch records the last access time of key. Caller must hold rl.mu.
+func (rl *RateLimiter) touch(key string, now time.Time) {
+	if rl.lastAccess == nil {
+		rl.lastAccess = make(map[string]time.Time)
+	}
+	rl.lastAccess[key] = now
 }
 
 // GetAttempts returns the number of attempts within the window for a key
@@ -317,25 +395,39 @@ func (rl *RateLimiter) cleanup() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		rl.mu.Lock()
-		now := time.Now()
+		rl.evictStale(time.Now())
+	}
+}
 
-		for key, attempts := range rl.attempts {
-			// Remove entries older than cleanup threshold
-			validAttempts := []time.Time{}
-			for _, t := range attempts {
-				if now.Sub(t) < CleanupThreshold {
-					validAttempts = append(validAttempts, t)
-				}
-			}
+// evictStale removes keys not accessed within CleanupThreshold and trims
+// expired attempts of the remaining keys.
+//
+// Keys are evicted on last access rather than on their newest attempt, so
+// a user who is actively being rate limited is never reset by cleanup.
+func (rl *RateLimiter) evictStale(now time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	for key, attempts := range rl.attempts {
+		if lastAccess, ok := rl.lastAccess[key]; !ok || now.Sub(lastAccess) >= CleanupThreshold {
+			delete(rl.attempts, key)
+			delete(rl.lastAccess, key)
+			continue
+		}
 
-			if len(validAttempts) == 0 {
-				delete(rl.attempts, key)
-			} else {
-				rl.attempts[key] = validAttempts
+		// Remove entries older than cleanup threshold
+		validAttempts := []time.Time{}
+		for _, t := range attempts {
+			if now.Sub(t) < CleanupThreshold {
+				validAttempts = append(validAttempts, t)
 			}
 		}
+		rl.attempts[key] = validAttempts
+	}
 
-		rl.mu.Unlock()
+	for key, lastAccess := range rl.lastAccess {
+		if now.Sub(lastAccess) >= CleanupThreshold {
+			delete(rl.lastAccess, key)
+		}
 	}
 }
@@ -11,8 +11,12 @@
 package middleware
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestRateLimiter_CheckLimit(t *testing.T) {
@@ -100,3 +104,114 @@ func TestRateLimiter_WindowExpiry(t *testing.T) {
 		t.Error("Should succeed after window expiry")
 	}
 }
+
+func TestRateLimiter_EvictStaleKeepsActiveKeys(t *testing.T) {
+	rl := &RateLimiter{
+		attempts:   make(map[string][]time.Time),
+		lastAccess: make(map[string]time.Time),
+	}
+
+	now := time.Now()
+	rl.attempts["user:idle"] = []time.Time{now.Add(-15 * time.Minute)}
+	rl.lastAccess["user:idle"] = now.Add(-15 * time.Minute)
+
+	// Active user whose oldest attempt is old but who accessed recently
+	rl.attempts["user:active"] = []time.Time{now.Add(-11 * time.Minute), now.Add(-time.Minute)}
+	rl.lastAccess["user:active"] = now.Add(-time.Minute)
+
+	rl.evictStale(now)
+
+	if _, exists := rl.attempts["user:idle"]; exists {
+		t.Error("Idle key should have been evicted")
+	}
+	if _, exists := rl.lastAccess["user:idle"]; exists {
+		t.Error("Idle key last access should have been evicted")
+	}
+	if got := len(rl.attempts["user:active"]); got != 1 {
+		t.Errorf("Expected active key to keep 1 recent attempt, got %d", got)
+	}
+}
+
+func TestRateLimiter_Reset(t *testing.T) {
+	rl := &RateLimiter{
+		attempts: make(map[string][]time.Time),
+	}
+
+	key := UserKey("123")
+	rl.CheckLimit(key, 5, time.Minute)
+	rl.Reset(key)
+
+	if count := rl.GetAttempts(key, time.Minute); count != 0 {
+		t.Errorf("Expected 0 attempts after Reset, got %d", count)
+	}
+	if _, exists := rl.lastAccess[key]; exists {
+		t.Error("Reset should clear last access")
+	}
+}
+
+func TestRateLimiter_UserMiddlewareKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rl := &RateLimiter{
+		attempts: make(map[string][]time.Time),
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	router.Use(rl.UserMiddleware())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Test-User", "alice")
+	req.RemoteAddr = "10.0.0.1:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if count := rl.GetAttempts(UserKey("alice"), APIRateLimitWindow); count != 1 {
+		t.Errorf("Expected 1 attempt for user key, got %d", count)
+	}
+	if count := rl.GetAttempts("10.0.0.1", APIRateLimitWindow); count != 1 {
+		t.Errorf("Expected 1 attempt for IP key, got %d", count)
+	}
+	if w.Header().Get("X-RateLimit-Limit") == "" {
+		t.Error("Expected X-RateLimit-Limit header")
+	}
+}
+
+func TestRateLimiter_UserMiddlewareBlocks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rl := &RateLimiter{
+		attempts: make(map[string][]time.Time),
+	}
+	key := UserKey("bob")
+	for i := 0; i < APIRateLimitRequests; i++ {
+		rl.CheckLimit(key, APIRateLimitRequests, APIRateLimitWindow)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "bob")
+		c.Next()
+	})
+	router.Use(rl.UserMiddleware())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
@@ -48,11 +48,17 @@
 //
 //   // Client can send existing request ID for distributed tracing
 //   // curl -H "X-Request-ID: my-trace-id" https://api.streamspace.io/sessions
+//
+//   // Carry the request ID (and user ID) into goroutines and plugin events
+//   runtime.EmitEventWithContext(middleware.TraceContext(c), "session.created", session)
 package middleware
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/streamspace/streamspace/api/internal/tracing"
 )
 
 const (
@@ -78,6 +84,10 @@ func RequestID() gin.HandlerFunc {
 		// Store in context for use by handlers
 		c.Set(RequestIDKey, requestID)
 
+		// Also trace the request's context.Context, so calls made with
+		// c.Request.Context() carry the ID
+		c.Request = c.Request.WithContext(tracing.WithTrace(c.Request.Context(), tracing.TraceContext{RequestID: requestID}))
+
 		// Set response header so client can reference this request
 		c.Header(RequestIDHeader, requestID)
 
@@ -94,3 +104,16 @@ func GetRequestID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// TraceContext returns the request's context carrying a tracing.TraceContext
+// with the request ID and the authenticated user ID.
+//
+// The context is detached from the request's cancellation, so it can be
+// handed to goroutines and asynchronous event handlers that outlive the
+// response.
+func TraceContext(c *gin.Context) context.Context {
+	return tracing.WithTrace(tracing.Detach(c.Request.Context()), tracing.TraceContext{
+		RequestID: GetRequestID(c),
+		UserID:    c.GetString("userID"),
+	})
+}
@@ -7,7 +7,7 @@
 // alerting, debugging, and observability in production environments.
 //
 // Implementation Details:
-// - Structured format: Key-value pairs instead of unstructured text
+// - Structured format: One JSON record per request via log/slog (see logger.go)
 // - Request correlation: Includes request ID for distributed tracing
 // - User tracking: Logs authenticated user information when available
 // - Performance metrics: Captures request duration in milliseconds
@@ -28,6 +28,8 @@
 // - username: Authenticated username (if authenticated)
 // - errors: Concatenated error messages (if any errors occurred)
 //
+// - session_id: Authenticated session ID (if authenticated)
+//
 // Log Levels:
 // - INFO: Successful requests (2xx status codes)
 // - WARN: Client errors (4xx status codes)
@@ -47,6 +49,7 @@
 //   router.Use(middleware.StructuredLoggerWithConfigFunc(config))
 //
 // Configuration:
+//   Logger: nil                      // *slog.Logger to use (default: slog.Default())
 //   SkipPaths: []string{}           // Paths to skip (e.g., ["/metrics", "/health"])
 //   SkipHealthCheck: true            // Skip /health and /api/v1/health endpoints
 //   LogQuery: true                   // Log query parameters
@@ -54,7 +57,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -63,63 +66,17 @@ import (
 // StructuredLogger provides structured logging for all requests
 // Logs include request ID, method, path, status, duration, and client IP
 func StructuredLogger() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Start timer
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
-
-		// Process request
-		c.Next()
-
-		// Calculate request duration
-		duration := time.Since(start)
-
-		// Get request ID (if RequestID middleware is used)
-		requestID := GetRequestID(c)
-
-		// Get status code
-		status := c.Writer.Status()
-
-		// Build log entry with structured fields
-		logEntry := map[string]interface{}{
-			"request_id": requestID,
-			"method":     c.Request.Method,
-			"path":       path,
-			"query":      raw,
-			"status":     status,
-			"duration":   duration.String(),
-			"duration_ms": duration.Milliseconds(),
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
-		}
-
-		// Add user info if authenticated
-		if userID, exists := c.Get("userID"); exists {
-			logEntry["user_id"] = userID
-		}
-		if username, exists := c.Get("username"); exists {
-			logEntry["username"] = username
-		}
-
-		// Add error if present
-		if len(c.Errors) > 0 {
-			logEntry["errors"] = c.Errors.String()
-		}
-
-		// Determine log level based on status code
-		if status >= 500 {
-			log.Printf("ERROR %v", logEntry)
-		} else if status >= 400 {
-			log.Printf("WARN %v", logEntry)
-		} else {
-			log.Printf("INFO %v", logEntry)
-		}
-	}
+	config := DefaultStructuredLoggerConfig()
+	config.SkipHealthCheck = false
+	return StructuredLoggerWithConfigFunc(config)
 }
 
 // StructuredLoggerWithConfig allows customization of structured logging
 type StructuredLoggerConfig struct {
+	// Logger is the base logger for request records and the request-scoped
+	// logger exposed via GetLogger. Defaults to slog.Default().
+	Logger *slog.Logger
+
 	// SkipPaths is a list of paths to skip logging (e.g., health checks)
 	SkipPaths []string
 
@@ -156,6 +113,18 @@ func StructuredLoggerWithConfigFunc(config StructuredLoggerConfig) gin.HandlerFu
 	}
 
 	return func(c *gin.Context) {
+		base := config.Logger
+		if base == nil {
+			base = slog.Default()
+		}
+
+		// Expose a request-scoped logger to handlers (see GetLogger)
+		requestLogger := base
+		if requestID := GetRequestID(c); requestID != "" {
+			requestLogger = base.With(slog.String("request_id", requestID))
+		}
+		c.Set(LoggerKey, requestLogger)
+
 		// Skip logging for certain paths
 		path := c.Request.URL.Path
 		if skipMap[path] {
@@ -173,53 +142,47 @@ func StructuredLoggerWithConfigFunc(config StructuredLoggerConfig) gin.HandlerFu
 		// Calculate request duration
 		duration := time.Since(start)
 
-		// Get request ID
-		requestID := GetRequestID(c)
-
 		// Get status code
 		status := c.Writer.Status()
 
-		// Build log entry
-		logEntry := map[string]interface{}{
-			"request_id": requestID,
-			"method":     c.Request.Method,
-			"path":       path,
-			"status":     status,
-			"duration":   duration.String(),
-			"duration_ms": duration.Milliseconds(),
-			"client_ip":  c.ClientIP(),
+		// Build log record attributes
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.String("duration", duration.String()),
+			slog.Int64("duration_ms", duration.Milliseconds()),
+			slog.String("client_ip", c.ClientIP()),
 		}
 
 		// Conditionally add query
 		if config.LogQuery && raw != "" {
-			logEntry["query"] = raw
+			attrs = append(attrs, slog.String("query", raw))
 		}
 
 		// Conditionally add user agent
 		if config.LogUserAgent {
-			logEntry["user_agent"] = c.Request.UserAgent()
+			attrs = append(attrs, slog.String("user_agent", c.Request.UserAgent()))
 		}
 
-		// Add user info if authenticated
-		if userID, exists := c.Get("userID"); exists {
-			logEntry["user_id"] = userID
-		}
-		if username, exists := c.Get("username"); exists {
-			logEntry["username"] = username
+		// Add username if authenticated (user_id and session_id come from GetLogger)
+		if username := c.GetString("username"); username != "" {
+			attrs = append(attrs, slog.String("username", username))
 		}
 
 		// Add error if present
 		if len(c.Errors) > 0 {
-			logEntry["errors"] = c.Errors.String()
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
 		}
 
-		// Log based on status code
+		// Log level based on status code
+		level := slog.LevelInfo
 		if status >= 500 {
-			log.Printf("ERROR %v", logEntry)
+			level = slog.LevelError
 		} else if status >= 400 {
-			log.Printf("WARN %v", logEntry)
-		} else {
-			log.Printf("INFO %v", logEntry)
+			level = slog.LevelWarn
 		}
+
+		GetLogger(c).LogAttrs(c.Request.Context(), level, "http request", attrs...)
 	}
 }
@@ -283,7 +283,7 @@ func (t *TeamRBAC) CanAccessSession(ctx context.Context, userID, sessionID strin
 	var sessionUserID string
 	var teamID sql.NullString
 	err := t.database.QueryRowContext(ctx, `
-		SELECT user_id, team_id FROM sessions WHERE id = $1
+		SELECT user_id, team_id FROM sessions WHERE archived_at IS NULL AND id = $1
 	`, sessionID).Scan(&sessionUserID, &teamID)
 
 	if err == sql.ErrNoRows {
@@ -142,6 +142,11 @@ type CatalogPlugin struct {
 	// Examples: "Analytics", "Security", "Integrations", "UI Enhancements"
 	Category string `json:"category"`
 
+	// CategoryDisplayName and CategoryIcon come from the template_categories
+	// row named Category; without one, CategoryDisplayName is Category.
+	CategoryDisplayName string `json:"categoryDisplayName"`
+	CategoryIcon        string `json:"categoryIcon"`
+
 	// PluginType indicates the plugin's architecture.
 	// Valid values:
 	//   - "extension": General-purpose extension (most common)
@@ -169,6 +174,9 @@ type CatalogPlugin struct {
 	// RatingCount is the number of ratings submitted.
 	RatingCount int `json:"ratingCount"`
 
+	// Installed is true when a plugin of this name is installed.
+	Installed bool `json:"installed"`
+
 	// Repository contains the source repository information.
 	// Embedded via JOIN query for convenience.
 	Repository Repository `json:"repository"`
@@ -241,6 +249,13 @@ type InstalledPlugin struct {
 	// UpdatedAt is when configuration or version was last changed.
 	UpdatedAt time.Time `json:"updatedAt"`
 
+	// Status is the plugin's runtime status: loaded, failed or degraded.
+	// Empty until the runtime first tries to load the plugin.
+	Status string `json:"status,omitempty"`
+
+	// LastError explains a failed or degraded status.
+	LastError string `json:"lastError,omitempty"`
+
 	// The following fields are populated from the catalog via JOIN.
 	// They provide convenience for API responses without extra queries.
 
@@ -258,6 +273,13 @@ type InstalledPlugin struct {
 
 	// Manifest contains the full plugin metadata.
 	Manifest *PluginManifest `json:"manifest,omitempty"`
+
+	// CatalogVersion is the version currently in the plugin's catalog entry.
+	CatalogVersion string `json:"catalogVersion,omitempty"`
+
+	// UpdateAvailable reports whether CatalogVersion is newer than Version
+	// (see POST /api/plugins/:id/upgrade).
+	UpdateAvailable bool `json:"updateAvailable"`
 }
 
 // PluginManifest contains complete metadata and configuration schema for a plugin.
@@ -388,6 +410,9 @@ type PluginRating struct {
 	Review    string    `json:"review,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// ReviewerName is the reviewer's full name, or username if unset.
+	ReviewerName string `json:"reviewerName"`
 }
 
 // PluginStats represents usage statistics for a plugin
@@ -429,6 +454,7 @@ type InstallPluginRequest struct {
 type UpdatePluginRequest struct {
 	Enabled *bool           `json:"enabled,omitempty"`
 	Config  json.RawMessage `json:"config,omitempty"`
+	Version string          `json:"version,omitempty"`
 }
 
 // RatePluginRequest represents a request to rate a plugin
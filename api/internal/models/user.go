@@ -95,6 +95,7 @@ type User struct {
 	//   - "local": Username + password authentication
 	//   - "saml": SAML 2.0 SSO (Authentik, Keycloak, Okta, etc.)
 	//   - "oidc": OIDC OAuth2 (Google, GitHub, Azure AD, etc.)
+	//   - "ldap": LDAP directory (Active Directory, OpenLDAP)
 	//
 	// Default: "local"
 	Provider string `json:"provider" db:"provider"`
@@ -134,7 +135,7 @@ type User struct {
 	// Nil if no quota has been explicitly set (platform defaults apply).
 	Quota *UserQuota `json:"quota,omitempty"`
 
-	// Groups is a list of group IDs this user belongs to.
+	// Groups is a list of the names of the groups this user belongs to.
 	// Populated from the group_memberships table.
 	// Used for team-based resource quotas and access control.
 	Groups []string `json:"groups,omitempty"`
@@ -416,7 +417,7 @@ type CreateUserRequest struct {
 	FullName string `json:"fullName" binding:"required"`
 	Password string `json:"password"` // Required for local auth, validated in handler
 	Role     string `json:"role"`     // user, admin, operator
-	Provider string `json:"provider"` // local, saml, oidc
+	Provider string `json:"provider"` // local, saml, oidc, ldap
 }
 
 // UpdateUserRequest represents a request to update an existing user.
@@ -502,7 +503,11 @@ type SetQuotaRequest struct {
 }
 
 // LoginRequest represents a user login request.
+//
+// TOTPCode is required once the user has confirmed TOTP enrollment. It may
+// be either the current 6-digit code or one of the user's backup codes.
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	TOTPCode string `json:"totpCode,omitempty"`
 }
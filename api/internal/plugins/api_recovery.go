@@ -0,0 +1,248 @@
+// Package plugins - api_recovery.go
+//
+// This file implements panic recovery for plugin HTTP endpoints.
+//
+// Every handler chain mounted by APIRegistry.AttachToRouter starts with a
+// recovery middleware bound to the owning plugin. When a plugin handler (or
+// one of its middleware) panics, the middleware:
+//
+//   - Logs the panic and stack trace with the plugin name
+//   - Increments streamspace_plugin_endpoint_panics_total{plugin}
+//   - Responds 500 with a PLUGIN_ERROR body naming the plugin
+//
+// # Crash Policy
+//
+// A plugin that panics CrashPolicy.MaxPanics times within CrashPolicy.Window
+// (default: 5 panics in 1 minute) has its endpoints disabled: they answer
+// 503 PLUGIN_DISABLED without reaching the plugin, and a "plugin.crashed"
+// event (PluginCrashedEvent) is emitted on the registry's event bus.
+//
+// Endpoints are enabled again when the plugin is re-enabled
+// (RuntimeV2.EnablePlugin) or reloaded (UnregisterAll clears the state).
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	apperrors "github.com/streamspace/streamspace/api/internal/errors"
+)
+
+// EventPluginCrashed is emitted when a plugin's endpoints are disabled after
+// repeated panics.
+const EventPluginCrashed = "plugin.crashed"
+
+const (
+	// defaultMaxPanics is the number of panics that disables a plugin's endpoints
+	defaultMaxPanics = 5
+
+	// defaultPanicWindow is the window in which defaultMaxPanics are counted
+	defaultPanicWindow = time.Minute
+)
+
+var (
+	pluginEndpointPanicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_endpoint_panics_total",
+			Help: "Total number of plugin HTTP handler panics, by plugin.",
+		},
+		[]string{"plugin"},
+	)
+
+	registerRecoveryMetricsOnce sync.Once
+)
+
+// CrashPolicy configures when a panicking plugin's endpoints are disabled.
+type CrashPolicy struct {
+	// MaxPanics is the number of panics within Window that disables the
+	// plugin's endpoints.
+	// Default: 5
+	MaxPanics int
+
+	// Window is the sliding window panics are counted in.
+	// Default: 1 minute
+	Window time.Duration
+}
+
+// PluginCrashedEvent is the payload of plugin.crashed.
+type PluginCrashedEvent struct {
+	Plugin string `json:"plugin"`
+
+	// Panics is the number of panics within the window that disabled the
+	// plugin's endpoints.
+	Panics int `json:"panics"`
+
+	// Window is the crash policy window, e.g. "1m0s".
+	Window string `json:"window"`
+
+	// LastPanic is the value the final handler panicked with.
+	LastPanic string `json:"lastPanic"`
+}
+
+// pluginCrashes tracks recent panics of one plugin's endpoints.
+type pluginCrashes struct {
+	total    uint64
+	recent   []time.Time
+	disabled bool
+}
+
+// SetEventBus sets the bus plugin.crashed events are emitted on. Without
+// one, crashed plugins are only logged.
+func (r *APIRegistry) SetEventBus(bus *EventBus) {
+	r.crashMu.Lock()
+	r.events = bus
+	r.crashMu.Unlock()
+}
+
+// SetCrashPolicy replaces the crash policy. Zero fields take their defaults.
+func (r *APIRegistry) SetCrashPolicy(policy CrashPolicy) {
+	if policy.MaxPanics <= 0 {
+		policy.MaxPanics = defaultMaxPanics
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaultPanicWindow
+	}
+
+	r.crashMu.Lock()
+	r.crashPolicy = policy
+	r.crashMu.Unlock()
+}
+
+// PanicCount returns the number of panics recorded for a plugin's endpoints
+// since it was last loaded.
+func (r *APIRegistry) PanicCount(pluginName string) uint64 {
+	r.crashMu.Lock()
+	defer r.crashMu.Unlock()
+
+	if crashes := r.crashes[pluginName]; crashes != nil {
+		return crashes.total
+	}
+	return 0
+}
+
+// EndpointsDisabled reports whether a plugin's endpoints were disabled by
+// the crash policy.
+func (r *APIRegistry) EndpointsDisabled(pluginName string) bool {
+	r.crashMu.Lock()
+	defer r.crashMu.Unlock()
+
+	crashes := r.crashes[pluginName]
+	return crashes != nil && crashes.disabled
+}
+
+// EnableEndpoints re-enables a plugin's endpoints and clears its recent
+// panics.
+func (r *APIRegistry) EnableEndpoints(pluginName string) {
+	r.crashMu.Lock()
+	crashes := r.crashes[pluginName]
+	wasDisabled := crashes != nil && crashes.disabled
+	if crashes != nil {
+		crashes.disabled = false
+		crashes.recent = nil
+	}
+	r.crashMu.Unlock()
+
+	if wasDisabled {
+		log.Printf("[API Registry] Re-enabled endpoints for plugin: %s", pluginName)
+	}
+}
+
+// resetCrashes forgets everything recorded for a plugin's endpoints.
+func (r *APIRegistry) resetCrashes(pluginName string) {
+	r.crashMu.Lock()
+	delete(r.crashes, pluginName)
+	r.crashMu.Unlock()
+}
+
+// recoverPlugin returns the middleware that heads every handler chain of
+// pluginName's endpoints.
+func (r *APIRegistry) recoverPlugin(pluginName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.EndpointsDisabled(pluginName) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "PLUGIN_DISABLED",
+				"code":    "PLUGIN_DISABLED",
+				"message": fmt.Sprintf("Plugin %s endpoints are disabled after repeated failures", pluginName),
+				"plugin":  pluginName,
+			})
+			return
+		}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			log.Printf("[API Registry] Plugin %s panicked handling %s %s: %v\n%s",
+				pluginName, c.Request.Method, c.Request.URL.Path, recovered, debug.Stack())
+			r.recordPanic(pluginName, recovered)
+
+			appErr := apperrors.PluginError(pluginName)
+			c.AbortWithStatusJSON(appErr.StatusCode, gin.H{
+				"error":   appErr.Code,
+				"code":    appErr.Code,
+				"message": appErr.Message,
+				"plugin":  pluginName,
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// recordPanic counts a panic and applies the crash policy.
+func (r *APIRegistry) recordPanic(pluginName string, recovered interface{}) {
+	registerRecoveryMetricsOnce.Do(func() {
+		prometheus.MustRegister(pluginEndpointPanicsTotal)
+	})
+	pluginEndpointPanicsTotal.WithLabelValues(pluginName).Inc()
+
+	now := time.Now()
+
+	r.crashMu.Lock()
+	policy := r.crashPolicy
+	crashes := r.crashes[pluginName]
+	if crashes == nil {
+		crashes = &pluginCrashes{}
+		r.crashes[pluginName] = crashes
+	}
+	crashes.total++
+
+	// Keep only the panics inside the window
+	recent := crashes.recent[:0]
+	for _, at := range crashes.recent {
+		if now.Sub(at) < policy.Window {
+			recent = append(recent, at)
+		}
+	}
+	crashes.recent = append(recent, now)
+
+	crashed := !crashes.disabled && len(crashes.recent) >= policy.MaxPanics
+	if crashed {
+		crashes.disabled = true
+	}
+	panics := len(crashes.recent)
+	bus := r.events
+	r.crashMu.Unlock()
+
+	if !crashed {
+		return
+	}
+
+	log.Printf("[API Registry] Disabled endpoints for plugin %s after %d panics in %s", pluginName, panics, policy.Window)
+	if bus != nil {
+		bus.Emit(EventPluginCrashed, PluginCrashedEvent{
+			Plugin:    pluginName,
+			Panics:    panics,
+			Window:    policy.Window.String(),
+			LastPanic: fmt.Sprint(recovered),
+		})
+	}
+}
@@ -49,6 +49,23 @@
 //
 //	// Results in: POST /api/plugins/slack/send
 //
+// Endpoint Versioning:
+//
+// Plugins that evolve their HTTP API can serve several contracts side by
+// side by registering versioned endpoints. Versions have the form v{N}; the
+// version is inserted between the plugin namespace and the relative path:
+//
+//	v2 := api.V("v2") // or api.V("2")
+//	v2.POST("/send", sendV2Handler)
+//	// Results in: POST /api/plugins/slack/v2/send
+//
+//	// Equivalent, per endpoint:
+//	api.RegisterEndpoint(EndpointOptions{Method: "POST", Path: "/send", Version: "v2", Handler: sendV2Handler})
+//
+// Unversioned registrations keep their /api/plugins/{name}/{path} form, so
+// existing clients are unaffected, and count as v1 (DefaultAPIVersion) when
+// endpoints are grouped by version.
+//
 // Thread Safety:
 //
 // The registry uses sync.RWMutex for thread-safe concurrent access:
@@ -69,13 +86,35 @@
 //
 // Permission Model:
 //
-// Endpoints can declare required permissions for documentation/UI purposes.
-// Actual enforcement happens in middleware, not the registry:
+// Endpoints can declare required permissions. When they do, the registry
+// prepends a permission check to the handler chain at AttachToRouter time:
 //
 //	api.RegisterEndpoint(EndpointOptions{
 //	    Permissions: []string{"plugin.slack.send", "sessions.read"},
 //	})
 //
+// The check reads the identity set by the auth middleware (auth.Middleware
+// and auth.OptionalAuth):
+//   - No "userID" in the context: 401 Unauthorized
+//   - "userRole" == "admin": always allowed
+//   - Otherwise every declared permission must be present in
+//     "userPermissions" ([]string, "*" grants all): 403 Forbidden if not
+//
+// "userPermissions" holds the permissions granted to the roles of the user's
+// group memberships in team_role_permissions, so admins grant a plugin
+// permission by adding it to a team role.
+//
+// Endpoints that must be reachable without authentication set Public: true,
+// which skips the check (Permissions are then documentation only). The
+// middleware passed to SetAuthMiddleware runs before the check on every
+// other endpoint, so non-public endpoints are never served anonymously.
+//
+// Panic Recovery:
+//
+// Every handler chain starts with a recovery middleware: a panicking plugin
+// handler gets a 500 PLUGIN_ERROR response naming the plugin, and a plugin
+// that keeps panicking has its endpoints disabled (see api_recovery.go).
+//
 // Cleanup on Unload:
 //
 // When a plugin is unloaded:
@@ -91,7 +130,6 @@
 //
 // Future Enhancements:
 //   - Dynamic route reloading without restart
-//   - Endpoint versioning (/api/plugins/slack/v1/send)
 //   - Rate limiting per plugin
 //   - Request/response logging and metrics
 //   - OpenAPI/Swagger spec generation from registered endpoints
@@ -101,11 +139,33 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultAPIVersion is the version unversioned plugin endpoints belong to.
+const DefaultAPIVersion = "v1"
+
+// apiVersionPattern matches plugin API versions: v1, v2, ...
+var apiVersionPattern = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// ParseAPIVersion normalizes a plugin API version ("2" becomes "v2") and
+// returns an error unless it has the form v{N}.
+func ParseAPIVersion(version string) (string, error) {
+	if version != "" && version[0] >= '0' && version[0] <= '9' {
+		version = "v" + version
+	}
+	if !apiVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid API version %q: must have the form v{N}, e.g. v2", version)
+	}
+	return version, nil
+}
+
 // APIRegistry manages plugin API endpoint registrations.
 //
 // The registry provides centralized management of all plugin-contributed API
@@ -121,9 +181,9 @@ import (
 // Registry Structure:
 //
 //	endpoints: map[string]*PluginEndpoint
-//	  Key format: "{pluginName}:{method}:{path}"
-//	  Example: "slack:POST:/api/plugins/slack/send"
-//	  Value: Full endpoint metadata
+//	  Key format: "{METHOD}:{path}" (path parameters normalized, see routeKey)
+//	  Example: "POST:/api/plugins/slack/send"
+//	  Value: Full endpoint metadata, including the owning plugin
 //
 // Concurrency Model:
 //
@@ -133,14 +193,34 @@ import (
 //	Registration is serialized to prevent conflicts
 type APIRegistry struct {
 	// endpoints stores all registered plugin API endpoints.
-	// Map key format: "{pluginName}:{method}:{path}"
+	// Map key format: "{METHOD}:{path}" (see routeKey), so a route can
+	// only have one owner.
 	// Thread-safe access via mu.
 	endpoints map[string]*PluginEndpoint
 
+	// auth authenticates requests to non-public endpoints before their
+	// permission check (see SetAuthMiddleware). Protected by mu.
+	auth []gin.HandlerFunc
+
 	// mu protects concurrent access to the endpoints map.
 	// Read operations (GetEndpoints, AttachToRouter) use RLock.
 	// Write operations (Register, Unregister) use Lock.
 	mu sync.RWMutex
+
+	// crashes tracks endpoint panics by plugin name; crashPolicy decides
+	// when a plugin's endpoints are disabled and events receives
+	// plugin.crashed (see api_recovery.go). Protected by crashMu.
+	crashes     map[string]*pluginCrashes
+	crashPolicy CrashPolicy
+	events      *EventBus
+	crashMu     sync.Mutex
+
+	// requestLimits holds each plugin's HTTP handler limit and onTimeout
+	// is told about handlers exceeding it (see timeouts.go). Protected by
+	// timeoutMu.
+	requestLimits map[string]time.Duration
+	onTimeout     func(pluginName, kind string)
+	timeoutMu     sync.Mutex
 }
 
 // PluginEndpoint represents a registered plugin API endpoint.
@@ -174,10 +254,14 @@ type PluginEndpoint struct {
 	Method string
 
 	// Path is the full URL path including namespace prefix.
-	// Format: /api/plugins/{pluginName}/{relative-path}
-	// Example: /api/plugins/slack/send
+	// Format: /api/plugins/{pluginName}/[{version}/]{relative-path}
+	// Example: /api/plugins/slack/send, /api/plugins/slack/v2/send
 	Path string
 
+	// Version is the API version segment of the path (e.g. "v2").
+	// Empty for unversioned endpoints.
+	Version string
+
 	// Handler is the Gin handler function that processes requests.
 	// Receives gin.Context with request data, writes response.
 	Handler gin.HandlerFunc
@@ -188,10 +272,14 @@ type PluginEndpoint struct {
 	Middleware []gin.HandlerFunc
 
 	// Permissions lists required permissions for this endpoint.
-	// Used for documentation and UI permission checks.
-	// Actual enforcement must happen in middleware.
+	// Unless Public is set, they are enforced by a middleware the registry
+	// prepends to the handler chain (see requirePermissions).
 	Permissions []string
 
+	// Public marks an endpoint as reachable without authentication.
+	// Declared Permissions are not enforced for public endpoints.
+	Public bool
+
 	// Description provides human-readable documentation.
 	// Used in API documentation and admin UI.
 	Description string
@@ -207,7 +295,9 @@ type PluginEndpoint struct {
 //	runtime.apiRegistry = registry
 func NewAPIRegistry() *APIRegistry {
 	return &APIRegistry{
-		endpoints: make(map[string]*PluginEndpoint),
+		endpoints:   make(map[string]*PluginEndpoint),
+		crashes:     make(map[string]*pluginCrashes),
+		crashPolicy: CrashPolicy{MaxPanics: defaultMaxPanics, Window: defaultPanicWindow},
 	}
 }
 
@@ -221,17 +311,27 @@ func NewAPIRegistry() *APIRegistry {
 //   - endpoint: Endpoint metadata (method, path, handler, etc.)
 //
 // Returns:
-//   - error: Conflict error if endpoint already registered, nil on success
+//   - error: Validation error if the path is outside the plugin's namespace
+//     or malformed, conflict error if the route is already registered, nil
+//     on success
 //
 // Thread Safety:
 //
 //	This method acquires an exclusive write lock. It's safe to call
 //	concurrently from multiple plugins during startup.
 //
+// Path Validation:
+//
+//	The path must start with /api/plugins/{pluginName}/ and may not
+//	contain empty, "." or ".." segments, so a plugin cannot register
+//	routes in another plugin's namespace or escape its own.
+//
 // Conflict Detection:
 //
-//	Endpoints are uniquely identified by (pluginName, method, path).
-//	Attempting to register a duplicate returns an error.
+//	Endpoints are uniquely identified by (method, path) across all
+//	plugins, with path parameter names ignored (/items/:id and
+//	/items/:name are the same route to the router). Attempting to
+//	register a taken route returns an error naming its owner.
 //
 // Example:
 //
@@ -241,14 +341,22 @@ func NewAPIRegistry() *APIRegistry {
 //	    Handler: sendHandler,
 //	})
 func (r *APIRegistry) Register(pluginName string, endpoint *PluginEndpoint) error {
+	if err := validateEndpointPath(pluginName, endpoint.Path); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	key := fmt.Sprintf("%s:%s:%s", pluginName, endpoint.Method, endpoint.Path)
+	key := routeKey(endpoint.Method, endpoint.Path)
 
 	// Check if already registered (prevents duplicate routes)
-	if _, exists := r.endpoints[key]; exists {
-		return fmt.Errorf("endpoint %s %s already registered by plugin %s", endpoint.Method, endpoint.Path, pluginName)
+	if existing, exists := r.endpoints[key]; exists {
+		if existing.PluginName == pluginName {
+			return fmt.Errorf("endpoint %s %s already registered by plugin %s", endpoint.Method, endpoint.Path, pluginName)
+		}
+		return fmt.Errorf("endpoint %s %s conflicts with %s %s registered by plugin %s",
+			endpoint.Method, endpoint.Path, existing.Method, existing.Path, existing.PluginName)
 	}
 
 	endpoint.PluginName = pluginName
@@ -262,6 +370,7 @@ func (r *APIRegistry) Register(pluginName string, endpoint *PluginEndpoint) erro
 //
 // This method removes a single endpoint by its method and path. The endpoint
 // will no longer be available after the next router rebuild (typically on restart).
+// Endpoints owned by another plugin are left alone.
 //
 // Parameters:
 //   - pluginName: Name of the plugin that owns the endpoint
@@ -284,7 +393,10 @@ func (r *APIRegistry) Unregister(pluginName string, method string, path string)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	key := fmt.Sprintf("%s:%s:%s", pluginName, method, path)
+	key := routeKey(method, path)
+	if endpoint, exists := r.endpoints[key]; !exists || endpoint.PluginName != pluginName {
+		return
+	}
 	delete(r.endpoints, key)
 
 	log.Printf("[API Registry] Unregistered endpoint: %s %s (plugin: %s)", method, path, pluginName)
@@ -308,6 +420,9 @@ func (r *APIRegistry) Unregister(pluginName string, method string, path string)
 //	  1. Collect keys to delete
 //	  2. Delete collected keys
 //
+//	The plugin's recorded panics are cleared as well, so a reloaded
+//	plugin starts with its endpoints enabled.
+//
 // Example:
 //
 //	// During plugin unload
@@ -327,6 +442,7 @@ func (r *APIRegistry) UnregisterAll(pluginName string) {
 	for _, key := range toDelete {
 		delete(r.endpoints, key)
 	}
+	r.resetCrashes(pluginName)
 
 	log.Printf("[API Registry] Unregistered all endpoints for plugin: %s", pluginName)
 }
@@ -367,16 +483,20 @@ func (r *APIRegistry) GetEndpoints() []*PluginEndpoint {
 	return endpoints
 }
 
-// GetPluginEndpoints returns endpoints for a specific plugin.
+// GetPluginEndpoints returns endpoints for a specific plugin, grouped by
+// API version.
 //
 // Filters the endpoint registry to return only endpoints owned by the
-// specified plugin. Useful for plugin-specific introspection.
+// specified plugin. Useful for plugin-specific introspection. Unversioned
+// endpoints (Version "") are grouped under DefaultAPIVersion. Within a
+// version, endpoints are sorted by path, then method.
 //
 // Parameters:
 //   - pluginName: Name of the plugin to query
 //
 // Returns:
-//   - []*PluginEndpoint: Endpoints registered by that plugin
+//   - map[string][]*PluginEndpoint: Endpoints registered by that plugin,
+//     keyed by version (e.g. "v1", "v2")
 //
 // Thread Safety:
 //
@@ -389,20 +509,34 @@ func (r *APIRegistry) GetEndpoints() []*PluginEndpoint {
 //
 // Example:
 //
-//	slackEndpoints := registry.GetPluginEndpoints("slack")
-//	fmt.Printf("Slack plugin has %d endpoints\n", len(slackEndpoints))
-func (r *APIRegistry) GetPluginEndpoints(pluginName string) []*PluginEndpoint {
+//	byVersion := registry.GetPluginEndpoints("slack")
+//	fmt.Printf("Slack plugin has %d v2 endpoints\n", len(byVersion["v2"]))
+func (r *APIRegistry) GetPluginEndpoints(pluginName string) map[string][]*PluginEndpoint {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	endpoints := make([]*PluginEndpoint, 0)
+	byVersion := make(map[string][]*PluginEndpoint)
 	for _, endpoint := range r.endpoints {
-		if endpoint.PluginName == pluginName {
-			endpoints = append(endpoints, endpoint)
+		if endpoint.PluginName != pluginName {
+			continue
+		}
+		version := endpoint.Version
+		if version == "" {
+			version = DefaultAPIVersion
 		}
+		byVersion[version] = append(byVersion[version], endpoint)
 	}
 
-	return endpoints
+	for _, endpoints := range byVersion {
+		sort.Slice(endpoints, func(i, j int) bool {
+			if endpoints[i].Path != endpoints[j].Path {
+				return endpoints[i].Path < endpoints[j].Path
+			}
+			return endpoints[i].Method < endpoints[j].Method
+		})
+	}
+
+	return byVersion
 }
 
 // AttachToRouter attaches all registered endpoints to a Gin router.
@@ -417,7 +551,7 @@ func (r *APIRegistry) GetPluginEndpoints(pluginName string) []*PluginEndpoint {
 // Behavior:
 //
 //	For each registered endpoint:
-//	  1. Build middleware chain (endpoint.Middleware + endpoint.Handler)
+//	  1. Build middleware chain (recovery + auth + permission check + endpoint.Middleware + endpoint.Handler)
 //	  2. Register with router: router.Handle(method, path, handlers...)
 //	  3. Log the attachment
 //
@@ -428,8 +562,12 @@ func (r *APIRegistry) GetPluginEndpoints(pluginName string) []*PluginEndpoint {
 //
 // Middleware Chain:
 //
-//	The handler chain is built as: [middleware1, middleware2, ..., handler]
-//	Middleware executes in array order before the handler.
+//	The handler chain is built as: [recovery, auth..., permissions, middleware1, ..., handler]
+//	The auth middleware (see SetAuthMiddleware) is only present when the
+//	endpoint is not Public, and the permission check only when Permissions
+//	are also declared. Middleware executes in array order before
+//	the handler. The recovery middleware turns panics anywhere in the chain
+//	into a PLUGIN_ERROR response (see api_recovery.go).
 //
 // Example:
 //
@@ -447,18 +585,114 @@ func (r *APIRegistry) AttachToRouter(router *gin.RouterGroup) {
 	defer r.mu.RUnlock()
 
 	for _, endpoint := range r.endpoints {
-		// Create the full handler chain: [middleware..., handler]
-		handlers := make([]gin.HandlerFunc, 0, len(endpoint.Middleware)+1)
-		handlers = append(handlers, endpoint.Middleware...)
-		handlers = append(handlers, endpoint.Handler)
-
 		// Register with router
-		router.Handle(endpoint.Method, endpoint.Path, handlers...)
+		router.Handle(endpoint.Method, endpoint.Path, r.handlerChain(endpoint)...)
 
 		log.Printf("[API Registry] Attached endpoint: %s %s", endpoint.Method, endpoint.Path)
 	}
 }
 
+// SetAuthMiddleware sets the middleware that authenticates requests to
+// non-public endpoints, such as auth.Middleware. It must set the identity
+// the permission check reads ("userID", "userRole", "userPermissions") and
+// abort unauthenticated requests. Call it before AttachToRouter.
+func (r *APIRegistry) SetAuthMiddleware(handlers ...gin.HandlerFunc) {
+	r.mu.Lock()
+	r.auth = handlers
+	r.mu.Unlock()
+}
+
+// validateEndpointPath checks that path lies inside pluginName's namespace
+// and has no empty, "." or ".." segments.
+func validateEndpointPath(pluginName, path string) error {
+	prefix := "/api/plugins/" + pluginName + "/"
+	if pluginName == "" || strings.Contains(pluginName, "/") {
+		return fmt.Errorf("invalid plugin name %q", pluginName)
+	}
+	if !strings.HasPrefix(path, prefix) || len(path) == len(prefix) {
+		return fmt.Errorf("endpoint path %q must be under %s", path, prefix)
+	}
+
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		switch segment {
+		case "":
+			return fmt.Errorf("endpoint path %q contains an empty segment", path)
+		case ".", "..":
+			return fmt.Errorf("endpoint path %q contains a %q segment", path, segment)
+		}
+	}
+	return nil
+}
+
+// routeKey identifies the route method and path map to in the router.
+// Parameter names are dropped, since /items/:id and /items/:name are the
+// same route.
+func routeKey(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = ":"
+		} else if strings.HasPrefix(segment, "*") {
+			segments[i] = "*"
+		}
+	}
+	return strings.ToUpper(method) + ":" + strings.Join(segments, "/")
+}
+
+// handlerChain builds [recovery, auth..., permission check, middleware...,
+// handler] for the endpoint, with the handler bounded by the plugin's
+// maxRequestDuration. The caller holds r.mu.
+func (r *APIRegistry) handlerChain(e *PluginEndpoint) []gin.HandlerFunc {
+	handlers := make([]gin.HandlerFunc, 0, len(r.auth)+len(e.Middleware)+3)
+	handlers = append(handlers, r.recoverPlugin(e.PluginName))
+	if !e.Public {
+		handlers = append(handlers, r.auth...)
+		if len(e.Permissions) > 0 {
+			handlers = append(handlers, requirePermissions(e.Permissions))
+		}
+	}
+	handlers = append(handlers, e.Middleware...)
+	handlers = append(handlers, r.timeoutPlugin(e.PluginName, e.Handler))
+	return handlers
+}
+
+// requirePermissions rejects requests whose user lacks any of permissions.
+//
+// Admins pass unconditionally; other users need every permission in the
+// "userPermissions" context value ("*" grants everything).
+func requirePermissions(permissions []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("userID") == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+
+		if c.GetString("userRole") == "admin" {
+			c.Next()
+			return
+		}
+
+		granted := make(map[string]bool)
+		for _, p := range c.GetStringSlice("userPermissions") {
+			granted[p] = true
+		}
+
+		for _, required := range permissions {
+			if !granted[required] && !granted["*"] {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":      "Insufficient permissions",
+					"permission": required,
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
 // PluginAPI provides API registration interface for plugins.
 //
 // This is the plugin-facing API that abstracts the underlying APIRegistry.
@@ -487,6 +721,10 @@ type PluginAPI struct {
 	// pluginName is the name of the plugin this API instance serves.
 	// Used to automatically namespace all endpoints.
 	pluginName string
+
+	// version is the default API version for endpoints registered through
+	// this instance (set by V). Empty means unversioned.
+	version string
 }
 
 // NewPluginAPI creates a new plugin API instance.
@@ -513,6 +751,32 @@ func NewPluginAPI(registry *APIRegistry, pluginName string) *PluginAPI {
 	}
 }
 
+// V returns a PluginAPI that registers endpoints under version ("v2" or
+// just "2").
+//
+// The returned instance shares the registry and plugin namespace; every
+// endpoint registered through it (and every Unregister) uses the
+// /api/plugins/{name}/v{N}/ prefix. An EndpointOptions.Version set
+// explicitly takes precedence. An invalid version is reported when an
+// endpoint is registered.
+//
+// Example:
+//
+//	v1 := api.V("v1")
+//	v2 := api.V("v2")
+//	v1.POST("/send", sendV1Handler) // POST /api/plugins/slack/v1/send
+//	v2.POST("/send", sendV2Handler) // POST /api/plugins/slack/v2/send
+func (pa *PluginAPI) V(version string) *PluginAPI {
+	if parsed, err := ParseAPIVersion(version); err == nil {
+		version = parsed
+	}
+	return &PluginAPI{
+		registry:   pa.registry,
+		pluginName: pa.pluginName,
+		version:    version,
+	}
+}
+
 // EndpointOptions contains options for registering an endpoint.
 //
 // This struct provides a flexible API for endpoint registration with
@@ -521,16 +785,21 @@ func NewPluginAPI(registry *APIRegistry, pluginName string) *PluginAPI {
 // Fields:
 //   - Method: HTTP method (GET, POST, PUT, PATCH, DELETE)
 //   - Path: Relative path (will be prefixed with /api/plugins/{name})
+//   - Version: Optional API version of the form v{N} (e.g. "v2"), inserted
+//     after the plugin name. Defaults to the PluginAPI's version, if any
 //   - Handler: Gin handler function
 //   - Middleware: Optional middleware chain
-//   - Permissions: Permission strings for documentation
+//   - Permissions: Permissions the caller must hold (enforced unless Public)
+//   - Public: Skip authentication/permission enforcement for this endpoint
 //   - Description: Human-readable endpoint description
 type EndpointOptions struct {
 	Method      string
 	Path        string
+	Version     string
 	Handler     gin.HandlerFunc
 	Middleware  []gin.HandlerFunc
 	Permissions []string
+	Public      bool
 	Description string
 }
 
@@ -551,6 +820,7 @@ type EndpointOptions struct {
 //	The path is automatically prefixed with /api/plugins/{pluginName}/.
 //	Plugin provides: "/send"
 //	Results in: "/api/plugins/slack/send"
+//	With Version "v1": "/api/plugins/slack/v1/send"
 //
 // Example:
 //
@@ -563,20 +833,29 @@ type EndpointOptions struct {
 //	    Description: "Send a Slack message",
 //	})
 func (pa *PluginAPI) RegisterEndpoint(opts EndpointOptions) error {
-	// Ensure path starts with / (normalize input)
-	if len(opts.Path) == 0 || opts.Path[0] != '/' {
-		opts.Path = "/" + opts.Path
+	version := opts.Version
+	if version == "" {
+		version = pa.version
+	}
+	if version != "" {
+		parsed, err := ParseAPIVersion(version)
+		if err != nil {
+			return err
+		}
+		version = parsed
 	}
 
 	// Apply plugin namespace prefix automatically
-	fullPath := fmt.Sprintf("/api/plugins/%s%s", pa.pluginName, opts.Path)
+	fullPath := pa.fullPath(version, opts.Path)
 
 	endpoint := &PluginEndpoint{
 		Method:      opts.Method,
 		Path:        fullPath,
+		Version:     version,
 		Handler:     opts.Handler,
 		Middleware:  opts.Middleware,
 		Permissions: opts.Permissions,
+		Public:      opts.Public,
 		Description: opts.Description,
 	}
 
@@ -713,6 +992,7 @@ func (pa *PluginAPI) DELETE(path string, handler gin.HandlerFunc, permissions ..
 //
 // Removes a previously registered endpoint by method and path. The path
 // should be the relative path used during registration, not the full path.
+// On an instance returned by V the version prefix is applied as well.
 //
 // Parameters:
 //   - method: HTTP method (GET, POST, etc.)
@@ -726,6 +1006,18 @@ func (pa *PluginAPI) DELETE(path string, handler gin.HandlerFunc, permissions ..
 //	// Later, unregister
 //	api.Unregister("POST", "/send")
 func (pa *PluginAPI) Unregister(method string, path string) {
-	fullPath := fmt.Sprintf("/api/plugins/%s%s", pa.pluginName, path)
-	pa.registry.Unregister(pa.pluginName, method, fullPath)
+	pa.registry.Unregister(pa.pluginName, method, pa.fullPath(pa.version, path))
+}
+
+// fullPath builds /api/plugins/{pluginName}[/{version}]{path}.
+func (pa *PluginAPI) fullPath(version, path string) string {
+	// Ensure path starts with / (normalize input)
+	if len(path) == 0 || path[0] != '/' {
+		path = "/" + path
+	}
+
+	if version == "" {
+		return fmt.Sprintf("/api/plugins/%s%s", pa.pluginName, path)
+	}
+	return fmt.Sprintf("/api/plugins/%s/%s%s", pa.pluginName, version, path)
 }
@@ -0,0 +1,308 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/streamspace/streamspace/api/internal/auth"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopHandler(c *gin.Context) {}
+
+func TestPluginAPI_UnversionedPath(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+
+	require.NoError(t, api.POST("/send", noopHandler))
+
+	endpoints := registry.GetPluginEndpoints("slack")[DefaultAPIVersion]
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "/api/plugins/slack/send", endpoints[0].Path)
+	assert.Equal(t, "", endpoints[0].Version)
+}
+
+func TestPluginAPI_VersionedEndpointsCoexist(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+
+	require.NoError(t, api.GET("/status", noopHandler))
+	require.NoError(t, api.V("v1").POST("/send", noopHandler))
+	require.NoError(t, api.V("2").POST("/send", noopHandler))
+	require.NoError(t, api.RegisterEndpoint(EndpointOptions{
+		Method:  http.MethodGet,
+		Path:    "status",
+		Version: "v2",
+		Handler: noopHandler,
+	}))
+
+	byVersion := registry.GetPluginEndpoints("slack")
+	require.Len(t, byVersion, 2)
+
+	// Unversioned endpoints keep their path and count as v1
+	v1 := byVersion["v1"]
+	require.Len(t, v1, 2)
+	assert.Equal(t, "/api/plugins/slack/status", v1[0].Path)
+	assert.Equal(t, "/api/plugins/slack/v1/send", v1[1].Path)
+	assert.Equal(t, "v1", v1[1].Version)
+
+	v2 := byVersion["v2"]
+	require.Len(t, v2, 2)
+	assert.Equal(t, "/api/plugins/slack/v2/send", v2[0].Path)
+	assert.Equal(t, "/api/plugins/slack/v2/status", v2[1].Path)
+	assert.Equal(t, "v2", v2[1].Version)
+}
+
+func TestPluginAPI_VersionedUnregister(t *testing.T) {
+	registry := NewAPIRegistry()
+	v1 := NewPluginAPI(registry, "slack").V("v1")
+
+	require.NoError(t, v1.POST("/send", noopHandler))
+	v1.Unregister(http.MethodPost, "/send")
+
+	assert.Empty(t, registry.GetPluginEndpoints("slack"))
+}
+
+func TestPluginAPI_InvalidVersion(t *testing.T) {
+	api := NewPluginAPI(NewAPIRegistry(), "slack")
+
+	for _, version := range []string{"v1/beta", "beta", "v0", "V2"} {
+		assert.Error(t, api.V(version).POST("/send", noopHandler), version)
+	}
+
+	version, err := ParseAPIVersion("3")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", version)
+}
+
+// serveEndpoint attaches the registry to a router whose fake auth middleware
+// sets the given identity, then performs a request to path.
+func serveEndpoint(registry *APIRegistry, method, path string, identity map[string]interface{}) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("")
+	group.Use(func(c *gin.Context) {
+		for key, value := range identity {
+			c.Set(key, value)
+		}
+		c.Next()
+	})
+	registry.AttachToRouter(group)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func okHandler(c *gin.Context) { c.Status(http.StatusOK) }
+
+func TestPluginAPI_EnforcesDeclaredPermissions(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.POST("/send", okHandler, "plugin.slack.send"))
+
+	path := "/api/plugins/slack/send"
+
+	assert.Equal(t, http.StatusUnauthorized, serveEndpoint(registry, http.MethodPost, path, nil))
+	assert.Equal(t, http.StatusForbidden, serveEndpoint(registry, http.MethodPost, path, map[string]interface{}{
+		"userID": "user-1", "userRole": "user",
+	}))
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodPost, path, map[string]interface{}{
+		"userID": "user-1", "userRole": "user", "userPermissions": []string{"plugin.slack.send"},
+	}))
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodPost, path, map[string]interface{}{
+		"userID": "admin-1", "userRole": "admin",
+	}))
+}
+
+func TestPluginAPI_PermissionsFromAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	// Quota and group lookups while loading the user are not mocked; the
+	// middleware ignores their errors
+	mock.MatchExpectationsInOrder(false)
+
+	registry := NewAPIRegistry()
+	registry.SetAuthMiddleware(auth.Middleware(nil, db.NewUserDB(mockDB)))
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.POST("/send", okHandler, "plugin.slack.send"))
+	require.NoError(t, api.GET("/status", okHandler))
+	require.NoError(t, api.RegisterEndpoint(EndpointOptions{
+		Method: http.MethodPost, Path: "/events", Handler: okHandler, Public: true,
+	}))
+
+	router := gin.New()
+	registry.AttachToRouter(router.Group(""))
+
+	expectAPIKey := func(key, userID string, permissions ...string) {
+		hash := sha256.Sum256([]byte(key))
+		mock.ExpectQuery("UPDATE api_keys SET last_used_at").
+			WithArgs(hex.EncodeToString(hash[:])).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, userID))
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE id").
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "full_name", "role", "provider", "active", "created_at", "updated_at", "last_login"}).
+				AddRow(userID, userID, userID+"@example.com", "", "user", "local", true, time.Now(), time.Now(), nil))
+		rows := sqlmock.NewRows([]string{"permission"})
+		for _, permission := range permissions {
+			rows.AddRow(permission)
+		}
+		mock.ExpectQuery("SELECT DISTINCT trp.permission").WithArgs(userID).WillReturnRows(rows)
+	}
+	serve := func(method, path, key string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "ApiKey "+key)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/plugins/slack/send", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/plugins/slack/status", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/plugins/slack/events", ""))
+
+	expectAPIKey("sk_sender", "user-1", "plugin.slack.send", "team.sessions.view")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/plugins/slack/send", "sk_sender"))
+
+	expectAPIKey("sk_viewer", "user-2", "team.sessions.view")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/api/plugins/slack/send", "sk_viewer"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginAPI_PublicEndpointSkipsPermissions(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.RegisterEndpoint(EndpointOptions{
+		Method:      http.MethodPost,
+		Path:        "/events",
+		Handler:     okHandler,
+		Permissions: []string{"plugin.slack.events"},
+		Public:      true,
+	}))
+
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodPost, "/api/plugins/slack/events", nil))
+}
+
+func TestPluginAPI_NoPermissionsUnchanged(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.GET("/status", okHandler))
+
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodGet, "/api/plugins/slack/status", nil))
+}
+
+func TestPluginAPI_RecoversHandlerPanic(t *testing.T) {
+	registry := NewAPIRegistry()
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.POST("/send", func(c *gin.Context) { panic("nil map") }))
+	require.NoError(t, NewPluginAPI(registry, "billing").GET("/status", okHandler))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registry.AttachToRouter(router.Group(""))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/plugins/slack/send", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "PLUGIN_ERROR", body["code"])
+	assert.Equal(t, "slack", body["plugin"])
+	assert.Equal(t, uint64(1), registry.PanicCount("slack"))
+
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodGet, "/api/plugins/billing/status", nil))
+	assert.Zero(t, registry.PanicCount("billing"))
+}
+
+func TestPluginAPI_DisablesEndpointsAfterRepeatedPanics(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	crashed := make(chan PluginCrashedEvent, 1)
+	bus.Subscribe(EventPluginCrashed, "watcher", func(data interface{}) error {
+		crashed <- data.(PluginCrashedEvent)
+		return nil
+	})
+
+	registry := NewAPIRegistry()
+	registry.SetEventBus(bus)
+	registry.SetCrashPolicy(CrashPolicy{MaxPanics: 2, Window: time.Minute})
+	api := NewPluginAPI(registry, "slack")
+	require.NoError(t, api.POST("/send", func(c *gin.Context) { panic("boom") }))
+	require.NoError(t, api.GET("/status", okHandler))
+
+	path := "/api/plugins/slack/send"
+	assert.Equal(t, http.StatusInternalServerError, serveEndpoint(registry, http.MethodPost, path, nil))
+	assert.False(t, registry.EndpointsDisabled("slack"))
+	assert.Equal(t, http.StatusInternalServerError, serveEndpoint(registry, http.MethodPost, path, nil))
+	assert.True(t, registry.EndpointsDisabled("slack"))
+
+	select {
+	case event := <-crashed:
+		assert.Equal(t, PluginCrashedEvent{Plugin: "slack", Panics: 2, Window: "1m0s", LastPanic: "boom"}, event)
+	case <-time.After(time.Second):
+		t.Fatal("plugin.crashed was not emitted")
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveEndpoint(registry, http.MethodGet, "/api/plugins/slack/status", nil))
+
+	registry.EnableEndpoints("slack")
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodGet, "/api/plugins/slack/status", nil))
+}
+
+func TestRegister_RejectsSpoofedNamespace(t *testing.T) {
+	registry := NewAPIRegistry()
+
+	for _, path := range []string{
+		"/api/plugins/billing/invoices", // another plugin's namespace
+		"/api/plugins/slackbot/send",    // shares the name as a prefix only
+		"/api/plugins/slack",            // the namespace itself
+		"/api/plugins/slack/",           // empty relative path
+		"/api/sessions",                 // outside /api/plugins
+		"/api/plugins/slack/../billing/invoices",
+		"/api/plugins/slack/./send",
+		"/api/plugins/slack//send",
+	} {
+		err := registry.Register("slack", &PluginEndpoint{Method: http.MethodGet, Path: path, Handler: noopHandler})
+		assert.Error(t, err, path)
+	}
+	assert.Empty(t, registry.GetEndpoints())
+
+	// Relative paths cannot climb out of the namespace through PluginAPI either
+	assert.Error(t, NewPluginAPI(registry, "slack").GET("/../billing/invoices", noopHandler))
+}
+
+func TestRegister_DetectsConflictsAcrossPlugins(t *testing.T) {
+	registry := NewAPIRegistry()
+	require.NoError(t, NewPluginAPI(registry, "slack").GET("/items/:id", noopHandler))
+
+	err := registry.Register("slack", &PluginEndpoint{Method: "get", Path: "/api/plugins/slack/items/:name", Handler: noopHandler})
+	assert.ErrorContains(t, err, "already registered by plugin slack")
+
+	// A route owned by another plugin can be neither taken over nor removed
+	registry.endpoints[routeKey(http.MethodPost, "/api/plugins/slack/send")] = &PluginEndpoint{
+		PluginName: "legacy", Method: http.MethodPost, Path: "/api/plugins/slack/send", Handler: noopHandler,
+	}
+	err = registry.Register("slack", &PluginEndpoint{Method: http.MethodPost, Path: "/api/plugins/slack/send", Handler: noopHandler})
+	assert.ErrorContains(t, err, "registered by plugin legacy")
+
+	registry.Unregister("slack", http.MethodPost, "/api/plugins/slack/send")
+	assert.Len(t, registry.GetPluginEndpoints("legacy")[DefaultAPIVersion], 1)
+
+	// Different methods on the same path do not conflict
+	assert.NoError(t, NewPluginAPI(registry, "slack").POST("/items/:id", noopHandler))
+}
@@ -38,6 +38,8 @@
 //     - OnUnload: Plugin cleanup
 //     - OnEnable: Plugin enabled
 //     - OnDisable: Plugin disabled
+//     - OnUpdate: Plugin version or configuration updated
+//     - OnConfigChange: Optional, not provided by BasePlugin (see config_reload.go)
 //
 //  2. Session Hooks:
 //     - OnSessionCreated, OnSessionStarted, OnSessionStopped
@@ -121,6 +123,12 @@ func (p *BasePlugin) OnDisable(ctx *PluginContext) error {
 	return nil
 }
 
+// OnUpdate is called when the installed plugin is updated.
+// Default: no-op. Override to migrate data between versions.
+func (p *BasePlugin) OnUpdate(ctx *PluginContext, oldVersion, newVersion string) error {
+	return nil
+}
+
 // Session Event Hooks - Default no-op implementations
 
 // OnSessionCreated is called when a new session is created.
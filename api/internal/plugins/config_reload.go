@@ -0,0 +1,78 @@
+// Package plugins - config_reload.go
+//
+// This file implements applying a new configuration to a loaded plugin
+// without restarting the API.
+//
+// After PATCH /api/plugins/:id saves a new config, ApplyPluginConfig
+// replaces the loaded plugin's Config (and its PluginContext's) and:
+//   - calls OnConfigChange on plugins implementing ConfigChangeHandler,
+//     which apply the new values in place ("live")
+//   - otherwise cycles an enabled plugin through OnDisable and OnEnable, in
+//     which it re-reads ctx.Config ("cycled")
+//   - does nothing more for plugins that are not loaded or are disabled;
+//     they read the config when next loaded or enabled ("deferred")
+//
+// If a hook fails the new config stays in place, but the plugin may keep
+// running with the old values until it is reloaded.
+package plugins
+
+import "context"
+
+// ConfigChangeHandler is implemented by plugins that can apply a new
+// configuration while running. It is optional; plugins without it are
+// disabled and re-enabled to pick up a new configuration.
+type ConfigChangeHandler interface {
+	OnConfigChange(ctx *PluginContext, newConfig map[string]interface{}) error
+}
+
+// ConfigReload describes how a new configuration reached a plugin.
+type ConfigReload string
+
+const (
+	// ConfigReloadLive means the plugin applied it in OnConfigChange.
+	ConfigReloadLive ConfigReload = "live"
+
+	// ConfigReloadCycled means the plugin was disabled and re-enabled.
+	ConfigReloadCycled ConfigReload = "cycled"
+
+	// ConfigReloadDeferred means the plugin is not running and reads the
+	// configuration when it is next loaded or enabled.
+	ConfigReloadDeferred ConfigReload = "deferred"
+)
+
+// ApplyPluginConfig hands a new configuration to a loaded plugin.
+//
+// Returns how the configuration was applied, and the error of the hook
+// that applied it, if any.
+//
+// Thread Safety: Thread-safe via internal locking.
+func (r *RuntimeV2) ApplyPluginConfig(ctx context.Context, name string, config map[string]interface{}) (ConfigReload, error) {
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	plugin := r.setPluginState(name, func(p *LoadedPlugin) {
+		p.Config = config
+		if p.Instance != nil && p.Instance.Context != nil {
+			p.Instance.Context.Config = config
+		}
+	})
+	if plugin == nil || !plugin.Enabled {
+		return ConfigReloadDeferred, nil
+	}
+
+	if handler, ok := plugin.Handler.(ConfigChangeHandler); ok {
+		return ConfigReloadLive, callLifecycleHook(name, "OnConfigChange", func() error {
+			return handler.OnConfigChange(plugin.Instance.Context, config)
+		})
+	}
+
+	if err := callLifecycleHook(name, "OnDisable", func() error {
+		return plugin.Handler.OnDisable(plugin.Instance.Context)
+	}); err != nil {
+		return ConfigReloadCycled, err
+	}
+	return ConfigReloadCycled, callLifecycleHook(name, "OnEnable", func() error {
+		return plugin.Handler.OnEnable(plugin.Instance.Context)
+	})
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cyclePlugin records lifecycle hooks and the config it saw in OnEnable.
+type cyclePlugin struct {
+	BasePlugin
+	calls   []string
+	enabled map[string]interface{}
+}
+
+func (p *cyclePlugin) OnDisable(ctx *PluginContext) error {
+	p.calls = append(p.calls, "disable")
+	return nil
+}
+
+func (p *cyclePlugin) OnEnable(ctx *PluginContext) error {
+	p.calls = append(p.calls, "enable")
+	p.enabled = ctx.Config
+	return nil
+}
+
+// liveConfigPlugin applies new configs in OnConfigChange.
+type liveConfigPlugin struct {
+	cyclePlugin
+	applied map[string]interface{}
+}
+
+func (p *liveConfigPlugin) OnConfigChange(ctx *PluginContext, newConfig map[string]interface{}) error {
+	p.applied = newConfig
+	return nil
+}
+
+func runtimeWithPlugin(name string, handler PluginHandler, enabled bool) *RuntimeV2 {
+	return &RuntimeV2{plugins: map[string]*LoadedPlugin{
+		name: {
+			Name:     name,
+			Enabled:  enabled,
+			Handler:  handler,
+			Instance: &PluginInstance{Context: &PluginContext{PluginName: name}},
+		},
+	}}
+}
+
+func TestApplyPluginConfig_Live(t *testing.T) {
+	handler := &liveConfigPlugin{}
+	runtime := runtimeWithPlugin("hooks", handler, true)
+	config := map[string]interface{}{"url": "https://new.example.com"}
+
+	reload, err := runtime.ApplyPluginConfig(context.Background(), "hooks", config)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigReloadLive, reload)
+	assert.Equal(t, config, handler.applied)
+	assert.Empty(t, handler.calls, "plugins with OnConfigChange are not cycled")
+	assert.Equal(t, config, runtime.plugins["hooks"].Instance.Context.Config)
+}
+
+func TestApplyPluginConfig_CyclesPluginsWithoutHook(t *testing.T) {
+	handler := &cyclePlugin{}
+	runtime := runtimeWithPlugin("hooks", handler, true)
+	config := map[string]interface{}{"url": "https://new.example.com"}
+
+	reload, err := runtime.ApplyPluginConfig(context.Background(), "hooks", config)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigReloadCycled, reload)
+	assert.Equal(t, []string{"disable", "enable"}, handler.calls)
+	assert.Equal(t, config, handler.enabled, "OnEnable sees the new config")
+}
+
+func TestApplyPluginConfig_Deferred(t *testing.T) {
+	handler := &cyclePlugin{}
+	runtime := runtimeWithPlugin("hooks", handler, false)
+
+	reload, err := runtime.ApplyPluginConfig(context.Background(), "hooks", map[string]interface{}{"url": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, ConfigReloadDeferred, reload)
+	assert.Empty(t, handler.calls)
+	assert.Equal(t, map[string]interface{}{"url": "x"}, runtime.plugins["hooks"].Config)
+
+	reload, err = runtime.ApplyPluginConfig(context.Background(), "not-loaded", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigReloadDeferred, reload)
+}
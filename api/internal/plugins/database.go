@@ -110,6 +110,7 @@
 //	    plugin_name TEXT NOT NULL,
 //	    key TEXT NOT NULL,
 //	    value JSONB NOT NULL,
+//	    expires_at TIMESTAMP,          -- NULL: never expires
 //	    created_at TIMESTAMP DEFAULT NOW(),
 //	    updated_at TIMESTAMP DEFAULT NOW(),
 //	    PRIMARY KEY (plugin_name, key)
@@ -159,9 +160,9 @@
 //     - Workaround: Use PluginDatabase for complex queries
 //     - PluginStorage designed for simple get/set only
 //
-//  5. **No quota enforcement**: Plugin can consume unlimited storage
-//     - Future: Per-plugin storage quotas
-//     - Workaround: Monitor disk usage, set limits externally
+//  5. **PluginStorage quota is per plugin, not per key**: Set fails with
+//     ErrStorageQuotaExceeded once the plugin's values would exceed its quota
+//     (default 10 MiB, see SetQuota). PluginDatabase tables are not limited.
 //
 // # Security Considerations
 //
@@ -187,11 +188,22 @@ package plugins
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/streamspace/streamspace/api/internal/db"
 )
 
+// defaultStorageQuotaBytes is the total size of the JSON values a plugin may
+// keep in plugin_storage.
+const defaultStorageQuotaBytes = 10 << 20
+
+// ErrStorageQuotaExceeded is returned by PluginStorage.Set when the value
+// would take the plugin over its storage quota.
+var ErrStorageQuotaExceeded = errors.New("plugin storage quota exceeded")
+
 // PluginDatabase provides full SQL database access for plugins.
 //
 // This struct wraps the platform's database connection, providing plugins with
@@ -738,14 +750,18 @@ func (pd *PluginDatabase) CreateTable(tableName string, schema string) error {
 // **API Design** (like Redis/localStorage):
 //   - Get(key) → value
 //   - Set(key, value) → store/update
+//   - SetWithTTL(key, value, ttl) → store/update, expiring after ttl
 //   - Delete(key) → remove
 //   - Keys(prefix) → list keys
-//   - Clear() → delete all plugin's data
+//   - List(prefix) → keys and values
+//   - Clear() → delete all plugin's data (also done on uninstall)
 //
 // **Storage Format**:
 //   - Table: plugin_storage (shared across all plugins)
 //   - Namespace: plugin_name column filters data
 //   - Value type: JSONB (flexible, queryable)
+//   - Expired keys: Invisible to Get/Keys/List, removed on read
+//   - Quota: Total value size per plugin (default 10 MiB)
 //
 // **When to Use**:
 //   - Cache: Store API responses, computed values
@@ -769,6 +785,12 @@ func (pd *PluginDatabase) CreateTable(tableName string, schema string) error {
 type PluginStorage struct {
 	db         *db.Database
 	pluginName string
+
+	// quotaBytes bounds the total size of the plugin's stored values
+	quotaBytes int64
+
+	// now returns the current time; replaced in tests
+	now func() time.Time
 }
 
 // NewPluginStorage creates a new plugin storage instance.
@@ -811,7 +833,18 @@ func NewPluginStorage(database *db.Database, pluginName string) *PluginStorage {
 	return &PluginStorage{
 		db:         database,
 		pluginName: pluginName,
+		quotaBytes: defaultStorageQuotaBytes,
+		now:        time.Now,
+	}
+}
+
+// SetQuota sets the total size in bytes of the JSON values the plugin may
+// store. A quota of zero or less restores the default (10 MiB).
+func (ps *PluginStorage) SetQuota(bytes int64) {
+	if bytes <= 0 {
+		bytes = defaultStorageQuotaBytes
 	}
+	ps.quotaBytes = bytes
 }
 
 // initStorage ensures the plugin_storage table exists.
@@ -831,6 +864,7 @@ func NewPluginStorage(database *db.Database, pluginName string) *PluginStorage {
 //	    plugin_name TEXT NOT NULL,     -- Plugin namespace
 //	    key TEXT NOT NULL,              -- Storage key
 //	    value JSONB NOT NULL,           -- Any JSON value
+//	    expires_at TIMESTAMP,           -- NULL: never expires
 //	    created_at TIMESTAMP DEFAULT NOW(),
 //	    updated_at TIMESTAMP DEFAULT NOW(),
 //	    PRIMARY KEY (plugin_name, key) -- Unique per plugin
@@ -853,6 +887,7 @@ func (ps *PluginStorage) initStorage() error {
 			plugin_name TEXT NOT NULL,
 			key TEXT NOT NULL,
 			value JSONB NOT NULL,
+			expires_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT NOW(),
 			updated_at TIMESTAMP DEFAULT NOW(),
 			PRIMARY KEY (plugin_name, key)
@@ -887,9 +922,11 @@ func (ps *PluginStorage) initStorage() error {
 //
 // **Return Values**:
 //   - Key exists: Returns value (interface{}), nil error
-//   - Key not found: Returns nil value, nil error
+//   - Key not found or expired: Returns nil value, nil error
 //   - Database error: Returns nil value, error
 //
+// An expired key found by Get is deleted.
+//
 // **Why nil instead of sql.ErrNoRows?**
 //   - Line 131: if err == sql.ErrNoRows { return nil, nil }
 //   - Makes "key not found" a normal case, not an error
@@ -924,11 +961,12 @@ func (ps *PluginStorage) initStorage() error {
 func (ps *PluginStorage) Get(key string) (interface{}, error) {
 	ps.initStorage() // Ensure table exists
 
-	var value interface{}
+	var raw []byte
+	var expiresAt sql.NullTime
 	err := ps.db.DB().QueryRow(`
-		SELECT value FROM plugin_storage
+		SELECT value, expires_at FROM plugin_storage
 		WHERE plugin_name = $1 AND key = $2
-	`, ps.pluginName, key).Scan(&value)
+	`, ps.pluginName, key).Scan(&raw, &expiresAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -937,6 +975,14 @@ func (ps *PluginStorage) Get(key string) (interface{}, error) {
 		return nil, fmt.Errorf("failed to get key %s for plugin %s: %w", key, ps.pluginName, err)
 	}
 
+	if expiresAt.Valid && !expiresAt.Time.After(ps.now()) {
+		return nil, ps.Delete(key)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode key %s for plugin %s: %w", key, ps.pluginName, err)
+	}
 	return value, nil
 }
 
@@ -990,8 +1036,14 @@ func (ps *PluginStorage) Get(key string) (interface{}, error) {
 //   - json.Marshal() used internally
 //   - Error if value can't be serialized (channels, functions, etc.)
 //
+// **Quota**:
+//   - The plugin's unexpired values, with this one replacing any previous
+//     value of key, must fit in its quota (see SetQuota)
+//   - Otherwise Set returns ErrStorageQuotaExceeded and stores nothing
+//
 // **Error Cases**:
 //   - json.Marshal fails: Non-serializable value
+//   - Quota exceeded: ErrStorageQuotaExceeded
 //   - INSERT fails: Database error (unlikely)
 //   - UPDATE fails: Database error (unlikely)
 //
@@ -1006,14 +1058,55 @@ func (ps *PluginStorage) Get(key string) (interface{}, error) {
 //
 // Returns error if serialization or database operation fails, nil on success.
 func (ps *PluginStorage) Set(key string, value interface{}) error {
+	return ps.set(key, value, nil)
+}
+
+// SetWithTTL stores a value like Set that expires after ttl.
+//
+// Once expired, the key is no longer returned by Get, Keys or List and no
+// longer counts against the plugin's quota. Setting the key again replaces
+// its expiry; Set makes it permanent.
+//
+// Example:
+//
+//	// Cache an API response for five minutes
+//	storage.SetWithTTL("cache_users", users, 5*time.Minute)
+func (ps *PluginStorage) SetWithTTL(key string, value interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("ttl for key %s must be positive", key)
+	}
+	expiresAt := ps.now().Add(ttl)
+	return ps.set(key, value, &expiresAt)
+}
+
+// set stores value under key after checking the quota. A nil expiresAt
+// never expires.
+func (ps *PluginStorage) set(key string, value interface{}, expiresAt *time.Time) error {
 	ps.initStorage() // Ensure table exists
 
-	_, err := ps.db.DB().Exec(`
-		INSERT INTO plugin_storage (plugin_name, key, value, updated_at)
-		VALUES ($1, $2, $3, NOW())
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode key %s for plugin %s: %w", key, ps.pluginName, err)
+	}
+
+	// Size of the plugin's other live values; key's own value is replaced
+	var used int64
+	if err := ps.db.DB().QueryRow(`
+		SELECT COALESCE(SUM(octet_length(value::text)), 0) FROM plugin_storage
+		WHERE plugin_name = $1 AND key <> $2 AND (expires_at IS NULL OR expires_at > $3)
+	`, ps.pluginName, key, ps.now()).Scan(&used); err != nil {
+		return fmt.Errorf("failed to check storage quota for plugin %s: %w", ps.pluginName, err)
+	}
+	if used+int64(len(encoded)) > ps.quotaBytes {
+		return fmt.Errorf("%w: plugin %s would use %d of %d bytes", ErrStorageQuotaExceeded, ps.pluginName, used+int64(len(encoded)), ps.quotaBytes)
+	}
+
+	_, err = ps.db.DB().Exec(`
+		INSERT INTO plugin_storage (plugin_name, key, value, expires_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
 		ON CONFLICT (plugin_name, key)
-		DO UPDATE SET value = $3, updated_at = NOW()
-	`, ps.pluginName, key, value)
+		DO UPDATE SET value = $3, expires_at = $4, updated_at = NOW()
+	`, ps.pluginName, key, string(encoded), expiresAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to set key %s for plugin %s: %w", key, ps.pluginName, err)
@@ -1164,11 +1257,11 @@ func (ps *PluginStorage) Keys(prefix string) ([]string, error) {
 	var args []interface{}
 
 	if prefix == "" {
-		query = `SELECT key FROM plugin_storage WHERE plugin_name = $1 ORDER BY key`
-		args = []interface{}{ps.pluginName}
+		query = `SELECT key FROM plugin_storage WHERE plugin_name = $1 AND (expires_at IS NULL OR expires_at > $2) ORDER BY key`
+		args = []interface{}{ps.pluginName, ps.now()}
 	} else {
-		query = `SELECT key FROM plugin_storage WHERE plugin_name = $1 AND key LIKE $2 ORDER BY key`
-		args = []interface{}{ps.pluginName, prefix + "%"}
+		query = `SELECT key FROM plugin_storage WHERE plugin_name = $1 AND (expires_at IS NULL OR expires_at > $2) AND key LIKE $3 ORDER BY key`
+		args = []interface{}{ps.pluginName, ps.now(), prefix + "%"}
 	}
 
 	rows, err := ps.db.DB().Query(query, args...)
@@ -1189,6 +1282,48 @@ func (ps *PluginStorage) Keys(prefix string) ([]string, error) {
 	return keys, nil
 }
 
+// List returns the unexpired keys starting with prefix together with their
+// values, in one query.
+//
+// Like Keys, an empty prefix lists every key of the plugin. Values are
+// decoded as in Get.
+//
+// Example:
+//
+//	cached, err := storage.List("cache_")
+//	for key, value := range cached {
+//	    // ...
+//	}
+func (ps *PluginStorage) List(prefix string) (map[string]interface{}, error) {
+	ps.initStorage() // Ensure table exists
+
+	rows, err := ps.db.DB().Query(`
+		SELECT key, value FROM plugin_storage
+		WHERE plugin_name = $1 AND (expires_at IS NULL OR expires_at > $2) AND key LIKE $3
+		ORDER BY key
+	`, ps.pluginName, ps.now(), prefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys for plugin %s: %w", ps.pluginName, err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]interface{})
+	for rows.Next() {
+		var key string
+		var raw []byte
+		if err := rows.Scan(&key, &raw); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode key %s for plugin %s: %w", key, ps.pluginName, err)
+		}
+		values[key] = value
+	}
+
+	return values, rows.Err()
+}
+
 // Clear removes all storage for the plugin.
 //
 // This method deletes all rows in plugin_storage belonging to this plugin,
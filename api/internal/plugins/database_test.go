@@ -0,0 +1,128 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage returns a PluginStorage on a sqlmock database whose clock
+// is fixed at now. The lazy CREATE TABLE is expected once per call.
+func newTestStorage(t *testing.T, now time.Time) (*PluginStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	storage := NewPluginStorage(db.NewDatabaseFromDB(mockDB), "kv-plugin")
+	storage.now = func() time.Time { return now }
+	return storage, mock
+}
+
+func TestPluginStorage_SetEnforcesQuota(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+	storage.SetQuota(100)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(octet_length\\(value::text\\)\\), 0\\) FROM plugin_storage").
+		WithArgs("kv-plugin", "big", now).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(90))
+
+	err := storage.Set("big", "more than ten bytes")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrStorageQuotaExceeded))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginStorage_SetWithinQuota(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+	storage.SetQuota(100)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs("kv-plugin", "config", now).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(50))
+	mock.ExpectExec("INSERT INTO plugin_storage").
+		WithArgs("kv-plugin", "config", `{"enabled":true}`, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, storage.Set("config", map[string]bool{"enabled": true}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginStorage_SetWithTTL(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COALESCE").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectExec("INSERT INTO plugin_storage").
+		WithArgs("kv-plugin", "cache_users", `["alice"]`, now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, storage.SetWithTTL("cache_users", []string{"alice"}, time.Minute))
+	assert.Error(t, storage.SetWithTTL("cache_users", nil, 0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginStorage_GetExpiredKey(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT value, expires_at FROM plugin_storage").
+		WithArgs("kv-plugin", "cache_users").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "expires_at"}).AddRow([]byte(`["alice"]`), now.Add(-time.Second)))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM plugin_storage").
+		WithArgs("kv-plugin", "cache_users").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	value, err := storage.Get("cache_users")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginStorage_GetLiveKey(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT value, expires_at FROM plugin_storage").
+		WithArgs("kv-plugin", "count").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "expires_at"}).AddRow([]byte(`42`), now.Add(time.Hour)))
+
+	value, err := storage.Get("count")
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPluginStorage_List(t *testing.T) {
+	now := time.Now()
+	storage, mock := newTestStorage(t, now)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS plugin_storage").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT key, value FROM plugin_storage").
+		WithArgs("kv-plugin", now, "cache_%").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("cache_a", []byte(`"x"`)).
+			AddRow("cache_b", []byte(`{"n":1}`)))
+
+	values, err := storage.List("cache_")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"cache_a": "x",
+		"cache_b": map[string]interface{}{"n": 1.0},
+	}, values)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,191 @@
+// Package plugins - event_audit.go
+//
+// This file implements the subscription audit log for the event bus.
+//
+// Security teams need to know which plugins listen to which events, in
+// particular sensitive ones such as "user.login". When an EventBus is
+// created with NewEventBusWithAudit, every subscription made through
+// SubscribeAudited and every unsubscription is recorded in the
+// plugin_event_subscriptions table.
+//
+// # Audit Table
+//
+//	plugin_event_subscriptions
+//	    id          SERIAL
+//	    plugin_name VARCHAR(255)
+//	    event_type  VARCHAR(255)
+//	    action      VARCHAR(20)   -- "subscribe" or "unsubscribe"
+//	    created_at  TIMESTAMP
+//
+// # Performance
+//
+// Audit records are written by a single background goroutine fed by a
+// buffered channel. Subscribe/Unsubscribe only enqueue a record after
+// releasing the bus lock, and Emit never touches the audit path, so event
+// delivery latency is unchanged. If the buffer is full (database down or
+// very slow), records are dropped and a warning is logged rather than
+// blocking plugin loading.
+//
+// # Querying
+//
+//	entries, total, err := bus.GetEventAuditLog("streamspace-slack", from, to, 50, 0)
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+const (
+	// auditBufferSize is the number of audit records that may be queued
+	// before new records are dropped
+	auditBufferSize = 256
+
+	// auditWriteTimeout bounds a single audit INSERT
+	auditWriteTimeout = 5 * time.Second
+
+	// Subscription audit actions
+	AuditActionSubscribe   = "subscribe"
+	AuditActionUnsubscribe = "unsubscribe"
+)
+
+// EventSubscriptionAudit is one recorded subscription change.
+type EventSubscriptionAudit struct {
+	ID         int64     `json:"id"`
+	PluginName string    `json:"pluginName"`
+	EventType  string    `json:"eventType"`
+	Action     string    `json:"action"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// eventAuditor persists subscription audit records in the background.
+type eventAuditor struct {
+	db      *db.Database
+	records chan EventSubscriptionAudit
+}
+
+// NewEventBusWithAudit enables subscription auditing on bus.
+//
+// Subscriptions made through SubscribeAudited and all unsubscriptions are
+// recorded in plugin_event_subscriptions using database. The same bus is
+// returned for convenience. Calling it twice on one bus has no effect.
+//
+// Example:
+//
+//	bus := NewEventBusWithAudit(NewEventBus(EventBusConfig{}), database)
+func NewEventBusWithAudit(bus *EventBus, database *db.Database) *EventBus {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.audit != nil || database == nil {
+		return bus
+	}
+
+	auditor := &eventAuditor{
+		db:      database,
+		records: make(chan EventSubscriptionAudit, auditBufferSize),
+	}
+	go auditor.run()
+	bus.audit = auditor
+
+	return bus
+}
+
+// SubscribeAudited registers handler like Subscribe and records the
+// subscription in the audit log.
+//
+// On a bus without auditing enabled it behaves exactly like Subscribe.
+func (bus *EventBus) SubscribeAudited(eventType string, pluginName string, handler EventHandler) {
+	bus.Subscribe(eventType, pluginName, handler)
+	bus.recordAudit(pluginName, eventType, AuditActionSubscribe)
+}
+
+// GetEventAuditLog returns the subscription history of a plugin between
+// from and to, newest first.
+//
+// An empty pluginName returns the history of all plugins. limit and offset
+// page through the results; total is the number of matching records.
+func (bus *EventBus) GetEventAuditLog(pluginName string, from, to time.Time, limit, offset int) ([]EventSubscriptionAudit, int, error) {
+	bus.mu.RLock()
+	auditor := bus.audit
+	bus.mu.RUnlock()
+
+	if auditor == nil {
+		return nil, 0, fmt.Errorf("event subscription auditing is not enabled")
+	}
+
+	ctx := context.Background()
+
+	var total int
+	if err := auditor.db.DB().QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM plugin_event_subscriptions
+		WHERE ($1 = '' OR plugin_name = $1) AND created_at >= $2 AND created_at < $3
+	`, pluginName, from, to).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit records: %w", err)
+	}
+
+	rows, err := auditor.db.DB().QueryContext(ctx, `
+		SELECT id, plugin_name, event_type, action, created_at
+		FROM plugin_event_subscriptions
+		WHERE ($1 = '' OR plugin_name = $1) AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5
+	`, pluginName, from, to, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit records: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []EventSubscriptionAudit{}
+	for rows.Next() {
+		var e EventSubscriptionAudit
+		if err := rows.Scan(&e.ID, &e.PluginName, &e.EventType, &e.Action, &e.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// recordAudit queues an audit record if auditing is enabled.
+//
+// Must be called without holding bus.mu.
+func (bus *EventBus) recordAudit(pluginName, eventType, action string) {
+	bus.mu.RLock()
+	auditor := bus.audit
+	bus.mu.RUnlock()
+
+	if auditor == nil {
+		return
+	}
+
+	select {
+	case auditor.records <- EventSubscriptionAudit{
+		PluginName: pluginName,
+		EventType:  eventType,
+		Action:     action,
+		CreatedAt:  time.Now(),
+	}:
+	default:
+		log.Printf("[EventBus] Audit buffer full, dropping %s record for plugin %s on %s", action, pluginName, eventType)
+	}
+}
+
+// run writes queued audit records until the process exits.
+func (a *eventAuditor) run() {
+	for record := range a.records {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		if _, err := a.db.DB().ExecContext(ctx, `
+			INSERT INTO plugin_event_subscriptions (plugin_name, event_type, action, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, record.PluginName, record.EventType, record.Action, record.CreatedAt); err != nil {
+			log.Printf("[EventBus] Failed to record %s audit for plugin %s: %v", record.Action, record.PluginName, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,80 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeAudited_RecordsSubscribeAndUnsubscribe(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectExec("INSERT INTO plugin_event_subscriptions").
+		WithArgs("audit-plugin", "user.login", AuditActionSubscribe, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO plugin_event_subscriptions").
+		WithArgs("audit-plugin", "user.login", AuditActionUnsubscribe, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	bus := NewEventBusWithAudit(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+	bus.SubscribeAudited("user.login", "audit-plugin", func(data interface{}) error { return nil })
+	bus.UnsubscribeAll("audit-plugin")
+
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSubscribeAudited_WithoutAudit(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	called := make(chan struct{}, 1)
+	bus.SubscribeAudited("session.created", "plain-plugin", func(data interface{}) error {
+		called <- struct{}{}
+		return nil
+	})
+	bus.Emit("session.created", nil)
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+
+	_, _, err := bus.GetEventAuditLog("plain-plugin", time.Time{}, time.Now(), 10, 0)
+	assert.Error(t, err)
+}
+
+func TestGetEventAuditLog(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	bus := NewEventBusWithAudit(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	at := from.Add(time.Hour)
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs("audit-plugin", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT id, plugin_name, event_type, action, created_at").
+		WithArgs("audit-plugin", from, to, 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "plugin_name", "event_type", "action", "created_at"}).
+			AddRow(3, "audit-plugin", "user.login", AuditActionUnsubscribe, at).
+			AddRow(2, "audit-plugin", "user.login", AuditActionSubscribe, at))
+
+	entries, total, err := bus.GetEventAuditLog("audit-plugin", from, to, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, AuditActionUnsubscribe, entries[0].Action)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -39,34 +39,41 @@
 //   - Returns errors from all handlers
 //   - Use when event ordering matters or errors must be handled
 //
+// **Ordered option**:
+//   - SubscribeOrdered() processes a subscription's events one at a time,
+//     in emission order, without blocking Emit (see event_ordered.go)
+//
 // # Subscription Management
 //
-// Subscribers are tracked using a compound key: "eventType:pluginName"
+// Subscribers are tracked by event type, then by plugin name:
 //   - Allows multiple handlers per event (different plugins)
 //   - Enables efficient cleanup when plugin unloads (UnsubscribeAll)
-//   - Prevents key collisions between plugins
+//   - Events are delivered on an exact event type match only, so
+//     "session.created" subscribers never see "session.created_extra"
 //
 // Example subscriber registry:
 //
-//	subscribers = map[string][]EventHandler{
-//	    "session.created:analytics": [handler1, handler2],
-//	    "session.created:billing":   [handler3],
-//	    "user.login:audit":          [handler4],
+//	subscribers = map[string]map[string][]EventHandler{
+//	    "session.created": {"analytics": [handler1, handler2], "billing": [handler3]},
+//	    "user.login":      {"audit": [handler4]},
 //	}
 //
+// Logs and errors still name a subscription "eventType:pluginName".
+//
 // # Concurrency Model
 //
 // The event bus is designed for high-concurrency environments:
 //
 //   - **RWMutex**: Protects subscriber registry
 //   - **Concurrent reads**: Multiple Emit() calls can read subscribers simultaneously
-//   - **Goroutine per handler**: Each handler runs in isolation
+//   - **Worker pool**: Emit queues deliveries for a fixed pool of workers
+//     (EventBusConfig), so bursts cannot spawn unbounded goroutines
 //   - **Panic recovery**: Handler panics don't crash the event bus
 //
 // Performance characteristics:
-//   - Emit latency: <1ms (just spawns goroutines)
+//   - Emit latency: <1ms (just enqueues deliveries)
 //   - EmitSync latency: Depends on slowest handler
-//   - Memory overhead: ~2 KB per goroutine
+//   - Memory overhead: bounded by workers and queue size
 //
 // # Error Handling
 //
@@ -79,6 +86,16 @@
 // Example: If 5 plugins subscribe to "session.created" and 2 of them panic,
 // the other 3 still process the event successfully.
 //
+// Emit counts and handler invocations, durations, errors and panics are
+// recorded per event type and plugin (see event_metrics.go).
+//
+// # Request Tracing
+//
+// EmitWithContext and EmitSyncCtx hand their context to every handler, so a
+// tracing.TraceContext (request ID and user ID) set by the emitting request
+// reaches context-aware handlers (SubscribeCtx, PluginEvents.OnCtx, On[T]).
+// Handler failures are logged with the trace.
+//
 // # Event Namespacing
 //
 // Platform events vs. plugin events:
@@ -94,7 +111,8 @@
 //
 //   - **Lazy handler collection**: Handlers collected under read lock
 //   - **Lock-free execution**: Handlers run after lock is released
-//   - **No buffering**: Events processed immediately (no queue)
+//   - **Bounded queue**: When full, deliveries are dropped (counted in
+//     Stats) or Emit blocks, depending on EventBusConfig.Overflow
 //
 // Benchmark data (1000 events/sec, 10 subscribers per event):
 //   - CPU usage: ~5% (mostly handler execution, not event bus overhead)
@@ -103,22 +121,25 @@
 //
 // # Known Limitations
 //
-//  1. **No event persistence**: Events lost if no subscribers (not a queue)
-//  2. **No replay**: Can't re-deliver events after they're emitted
-//  3. **No filtering**: All subscribers receive all events of that type
-//  4. **No ordering across types**: session.created may process before user.created
+//  1. **Not a queue**: Events are lost if no subscribers; persistence
+//     (event_replay.go) only records them for replay while debugging
+//  2. **No filtering**: All subscribers receive all events of that type
+//  3. **No ordering across types**: session.created may process before user.created
 //
 // Future enhancements:
 //   - Event filtering (e.g., only sessions for user X)
-//   - Event persistence for audit log
-//   - Replay capability for debugging
 //   - Priority-based delivery
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/streamspace/streamspace/api/internal/tracing"
 )
 
 // EventBus manages event distribution to plugins using a pub/sub pattern.
@@ -135,7 +156,7 @@ import (
 //
 // Typical usage:
 //
-//	bus := NewEventBus()
+//	bus := NewEventBus(EventBusConfig{})
 //
 //	// Plugin subscribes to events
 //	bus.Subscribe("session.created", "my-plugin", func(data interface{}) error {
@@ -149,8 +170,39 @@ import (
 //
 // Concurrency: All methods are thread-safe and safe for concurrent use.
 type EventBus struct {
-	subscribers map[string][]EventHandler
+	// subscribers holds handlers by event type, then by plugin name
+	subscribers map[string]map[string][]ContextEventHandler
 	mu          sync.RWMutex
+
+	// ordered holds SubscribeOrdered subscriptions, which bypass the worker
+	// pool and deliver through a per-plugin FIFO queue (see event_ordered.go)
+	ordered       map[string]map[string][]*orderedSubscription
+	orderedQueues map[string]*orderedQueue
+
+	// queue feeds Emit deliveries to the worker pool
+	queue    chan eventDelivery
+	overflow OverflowPolicy
+	workers  int
+	dropped  atomic.Uint64
+
+	// audit records subscription changes; nil unless enabled with
+	// NewEventBusWithAudit
+	audit *eventAuditor
+
+	// store records emitted events for replay; nil unless enabled with
+	// NewEventBusWithPersistence
+	store *eventStore
+
+	// schemas holds the declared payload types by event type (see
+	// event_schema.go)
+	schemas map[string]*EventSchema
+
+	// metrics records emit counts and handler outcomes (see event_metrics.go)
+	metrics *eventMetrics
+
+	// sandbox bounds handler invocations; nil unless set with SetSandbox
+	// (see event_sandbox.go)
+	sandbox *PluginSandbox
 }
 
 // EventHandler is a function that handles an event.
@@ -175,15 +227,149 @@ type EventBus struct {
 //   - Avoid blocking operations without timeouts
 type EventHandler func(data interface{}) error
 
+// ContextEventHandler is an EventHandler that also receives a context.
+//
+// The context is cancelled when an EmitSyncCtx caller stops waiting, so
+// well-behaved handlers can abort long-running work early. Handlers invoked
+// through EmitWithContext receive the emitter's context values (such as its
+// tracing.TraceContext) without its cancellation; handlers invoked through
+// Emit or EmitSync receive a background context.
+type ContextEventHandler func(ctx context.Context, data interface{}) error
+
+// OverflowPolicy selects what Emit does when the delivery queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards the delivery and counts it in Stats().Dropped.
+	OverflowDrop OverflowPolicy = iota
+
+	// OverflowBlock makes Emit wait for queue space (backpressure).
+	// Handlers that call Emit themselves can deadlock the pool under this
+	// policy if every worker blocks on a full queue.
+	OverflowBlock
+)
+
+const (
+	// defaultEventWorkers is the worker pool size when none is configured
+	defaultEventWorkers = 16
+
+	// defaultEventQueueSize is the delivery queue length when none is configured
+	defaultEventQueueSize = 1024
+)
+
+// EventBusConfig configures the worker pool used by Emit.
+type EventBusConfig struct {
+	// Workers is the number of goroutines delivering asynchronous events.
+	// Default: 16
+	Workers int
+
+	// QueueSize bounds the number of pending handler deliveries.
+	// Default: 1024
+	QueueSize int
+
+	// Overflow selects drop or backpressure when the queue is full.
+	// Default: OverflowDrop
+	Overflow OverflowPolicy
+}
+
+// EventBusStats reports the state of the Emit worker pool.
+type EventBusStats struct {
+	Workers       int    `json:"workers"`
+	QueueDepth    int    `json:"queueDepth"`
+	QueueCapacity int    `json:"queueCapacity"`
+	Dropped       uint64 `json:"dropped"`
+}
+
+// subscription is one handler together with the plugin that registered it
+// and its "eventType:pluginName" key, used in logs and errors.
+type subscription struct {
+	key     string
+	plugin  string
+	handler ContextEventHandler
+
+	// ordered marks a handler that waits for an ordered queue; the queue
+	// records the invocation's metrics itself
+	ordered bool
+}
+
+// eventDelivery is one handler invocation queued by Emit.
+type eventDelivery struct {
+	ctx       context.Context
+	eventType string
+	key       string
+	plugin    string
+	data      interface{}
+	handler   ContextEventHandler
+}
+
 // NewEventBus creates a new event bus for plugin event distribution.
 //
-// Returns an initialized EventBus with an empty subscriber registry.
-// The event bus is ready to use immediately - no additional setup required.
+// Returns an initialized EventBus with an empty subscriber registry and
+// starts its worker pool. Zero config fields take their defaults, so
+// NewEventBus(EventBusConfig{}) is a sensible starting point.
 //
 // Thread safety: The returned event bus is safe for concurrent use.
-func NewEventBus() *EventBus {
-	return &EventBus{
-		subscribers: make(map[string][]EventHandler),
+func NewEventBus(config EventBusConfig) *EventBus {
+	if config.Workers <= 0 {
+		config.Workers = defaultEventWorkers
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultEventQueueSize
+	}
+
+	bus := &EventBus{
+		subscribers:   make(map[string]map[string][]ContextEventHandler),
+		ordered:       make(map[string]map[string][]*orderedSubscription),
+		orderedQueues: make(map[string]*orderedQueue),
+		queue:         make(chan eventDelivery, config.QueueSize),
+		overflow:      config.Overflow,
+		workers:       config.Workers,
+		schemas:       make(map[string]*EventSchema),
+		metrics:       newEventMetrics(),
+	}
+	for i := 0; i < config.Workers; i++ {
+		go bus.worker()
+	}
+	return bus
+}
+
+// worker delivers queued events for the lifetime of the bus.
+func (bus *EventBus) worker() {
+	for delivery := range bus.queue {
+		bus.deliver(delivery)
+	}
+}
+
+// deliver runs one handler, logging errors and recovering panics.
+func (bus *EventBus) deliver(d eventDelivery) {
+	if err := bus.invoke(d.ctx, d.eventType, d.plugin, d.handler, d.data); err != nil {
+		log.Printf("[EventBus] %sHandler %s failed on event %s: %v", tracing.LogPrefix(d.ctx), d.key, d.eventType, err)
+	}
+}
+
+// invoke runs one of plugin's handlers through the sandbox, if any, and
+// records the invocation's metrics.
+func (bus *EventBus) invoke(ctx context.Context, eventType, plugin string, handler ContextEventHandler, data interface{}) error {
+	bus.mu.RLock()
+	sandbox := bus.sandbox
+	bus.mu.RUnlock()
+
+	if sandbox != nil {
+		sandboxed := handler
+		handler = func(ctx context.Context, data interface{}) error {
+			return sandbox.run(ctx, eventType, plugin, sandboxed, data)
+		}
+	}
+	return bus.metrics.invoke(ctx, eventType, plugin, handler, data)
+}
+
+// Stats returns the current queue depth and dropped delivery count.
+func (bus *EventBus) Stats() EventBusStats {
+	return EventBusStats{
+		Workers:       bus.workers,
+		QueueDepth:    len(bus.queue),
+		QueueCapacity: cap(bus.queue),
+		Dropped:       bus.dropped.Load(),
 	}
 }
 
@@ -198,8 +384,8 @@ func NewEventBus() *EventBus {
 //   - pluginName: The plugin registering the handler (for tracking/cleanup)
 //   - handler: The function to call when the event is emitted
 //
-// Subscription key:
-//   - Internally uses compound key "eventType:pluginName"
+// Subscription registry:
+//   - Handlers are stored by event type, then by plugin name
 //   - Allows multiple plugins to subscribe to same event
 //   - Enables efficient cleanup via UnsubscribeAll(pluginName)
 //
@@ -221,11 +407,24 @@ func NewEventBus() *EventBus {
 //	    return nil
 //	})
 func (bus *EventBus) Subscribe(eventType string, pluginName string, handler EventHandler) {
+	bus.SubscribeCtx(eventType, pluginName, func(_ context.Context, data interface{}) error {
+		return handler(data)
+	})
+}
+
+// SubscribeCtx registers a context-aware event handler for a specific event
+// type. It behaves like Subscribe, but the handler receives the context passed
+// to EmitSyncCtx.
+func (bus *EventBus) SubscribeCtx(eventType string, pluginName string, handler ContextEventHandler) {
 	bus.mu.Lock()
 	defer bus.mu.Unlock()
 
-	key := eventType + ":" + pluginName
-	bus.subscribers[key] = append(bus.subscribers[key], handler)
+	plugins := bus.subscribers[eventType]
+	if plugins == nil {
+		plugins = make(map[string][]ContextEventHandler)
+		bus.subscribers[eventType] = plugins
+	}
+	plugins[pluginName] = append(plugins[pluginName], handler)
 
 	log.Printf("[EventBus] Plugin %s subscribed to %s", pluginName, eventType)
 }
@@ -233,65 +432,97 @@ func (bus *EventBus) Subscribe(eventType string, pluginName string, handler Even
 // Unsubscribe removes a handler
 func (bus *EventBus) Unsubscribe(eventType string, pluginName string) {
 	bus.mu.Lock()
-	defer bus.mu.Unlock()
+	bus.removeHandlers(eventType, pluginName)
+	idle := bus.removeOrdered(eventType, pluginName)
+	bus.mu.Unlock()
 
-	key := eventType + ":" + pluginName
-	delete(bus.subscribers, key)
+	if idle != nil {
+		idle.close()
+	}
 
 	log.Printf("[EventBus] Plugin %s unsubscribed from %s", pluginName, eventType)
+	bus.recordAudit(pluginName, eventType, AuditActionUnsubscribe)
 }
 
 // UnsubscribeAll removes all handlers for a plugin
 func (bus *EventBus) UnsubscribeAll(pluginName string) {
 	bus.mu.Lock()
-	defer bus.mu.Unlock()
 
-	toDelete := []string{}
-	for key := range bus.subscribers {
-		// Keys are in format "eventType:pluginName"
-		for i := len(key) - 1; i >= 0; i-- {
-			if key[i] == ':' {
-				if key[i+1:] == pluginName {
-					toDelete = append(toDelete, key)
-				}
-				break
-			}
+	eventTypes := []string{}
+	for eventType, plugins := range bus.subscribers {
+		if _, ok := plugins[pluginName]; ok {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	for eventType, plugins := range bus.ordered {
+		if _, ok := plugins[pluginName]; !ok {
+			continue
+		}
+		if _, ok := bus.subscribers[eventType][pluginName]; !ok {
+			eventTypes = append(eventTypes, eventType)
 		}
 	}
 
-	for _, key := range toDelete {
-		delete(bus.subscribers, key)
+	var idle *orderedQueue
+	for _, eventType := range eventTypes {
+		bus.removeHandlers(eventType, pluginName)
+		if queue := bus.removeOrdered(eventType, pluginName); queue != nil {
+			idle = queue
+		}
+	}
+	bus.mu.Unlock()
+
+	if idle != nil {
+		idle.close()
 	}
 
 	log.Printf("[EventBus] Unsubscribed plugin %s from all events", pluginName)
+	for _, eventType := range eventTypes {
+		bus.recordAudit(pluginName, eventType, AuditActionUnsubscribe)
+	}
+}
+
+// removeHandlers deletes pluginName's handlers for eventType. Must be called
+// with bus.mu held for writing.
+func (bus *EventBus) removeHandlers(eventType, pluginName string) {
+	plugins := bus.subscribers[eventType]
+	delete(plugins, pluginName)
+	if len(plugins) == 0 {
+		delete(bus.subscribers, eventType)
+	}
 }
 
 // Emit publishes an event to all subscribers asynchronously.
 //
-// This is the primary method for delivering events to plugins. It immediately
-// spawns goroutines for all matching event handlers and returns without waiting
-// for them to complete (fire-and-forget pattern).
+// This is the primary method for delivering events to plugins. It queues one
+// delivery per matching handler for the worker pool and returns without
+// waiting for them to complete (fire-and-forget pattern).
 //
 // Event matching:
-//   - Finds all subscriber keys that start with the eventType
-//   - Example: "session.created" matches "session.created:analytics", "session.created:billing"
-//   - Each matching handler is invoked in a separate goroutine
+//   - Finds every handler subscribed to exactly eventType, for every plugin
+//   - Example: "session.created" reaches the analytics and billing handlers
+//     for "session.created", but not those for "session.created_extra"
+//   - Each matching handler is queued as a separate delivery
 //
 // Execution model:
 //   - **Asynchronous**: Returns immediately, doesn't wait for handlers
-//   - **Parallel**: All handlers run concurrently in separate goroutines
-//   - **Non-blocking**: Slow handlers don't delay event emission
+//   - **Parallel**: Handlers run concurrently on the bus's worker pool
+//   - **Bounded**: When the queue is full the delivery is dropped and counted,
+//     or Emit blocks until there is room (EventBusConfig.Overflow)
 //   - **Isolated**: Handler errors/panics don't affect other handlers
 //
 // Error handling:
 //   - Handler errors are logged to console (not returned to caller)
 //   - Handler panics are recovered and logged with stack trace
 //   - No errors bubble up to caller (fire-and-forget semantics)
+//   - A payload that does not match the event's registered schema is logged
+//     and the event is not delivered (see RegisterEventSchema)
 //
 // Performance:
-//   - Emit latency: <1ms (just spawns goroutines)
+//   - Emit latency: <1ms (just enqueues deliveries)
 //   - No waiting for handler completion
-//   - Memory overhead: ~2 KB per goroutine (handler stack)
+//   - Goroutines and memory bounded by the worker pool and queue size
 //
 // Use cases:
 //   - Notifying plugins about platform events (session.*, user.*)
@@ -320,40 +551,54 @@ func (bus *EventBus) UnsubscribeAll(pluginName string) {
 //   - Lock released before executing handlers (no blocking)
 //
 // See also:
+//   - EmitWithContext(): Asynchronous delivery that carries a request trace
 //   - EmitSync(): Synchronous version that waits for all handlers
 //   - Subscribe(): Register event handlers
 func (bus *EventBus) Emit(eventType string, data interface{}) {
-	bus.mu.RLock()
-	handlers := make([]EventHandler, 0)
+	bus.EmitWithContext(context.Background(), eventType, data)
+}
 
-	// Collect all handlers for this event type
-	for key, subs := range bus.subscribers {
-		// Check if key starts with eventType
-		if len(key) >= len(eventType) && key[:len(eventType)] == eventType {
-			handlers = append(handlers, subs...)
-		}
+// EmitWithContext publishes an event asynchronously, like Emit, handing ctx
+// to every handler.
+//
+// Handlers run after the caller has moved on, so they receive ctx's values
+// (e.g. the tracing.TraceContext of the emitting request) but not its
+// cancellation or deadline.
+//
+// Example:
+//
+//	bus.EmitWithContext(middleware.TraceContext(c), "session.created", session)
+func (bus *EventBus) EmitWithContext(ctx context.Context, eventType string, data interface{}) {
+	if err := bus.validatePayload(eventType, data); err != nil {
+		log.Printf("[EventBus] %sRejected event %s: %v", tracing.LogPrefix(ctx), eventType, err)
+		return
 	}
-	bus.mu.RUnlock()
+	bus.persist(eventType, data)
+	bus.metrics.recordEmit(eventType)
+	ctx = withEventMeta(tracing.Detach(ctx), eventType, time.Now())
 
-	// Call all handlers concurrently
-	var wg sync.WaitGroup
-	for _, handler := range handlers {
-		wg.Add(1)
-		go func(h EventHandler) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("[EventBus] Handler panicked on event %s: %v", eventType, r)
-				}
-			}()
+	// Queue one delivery per handler for the worker pool
+	for _, sub := range bus.subscriptionsFor(eventType, "") {
+		delivery := eventDelivery{ctx: ctx, eventType: eventType, key: sub.key, plugin: sub.plugin, data: data, handler: sub.handler}
 
-			if err := h(data); err != nil {
-				log.Printf("[EventBus] Handler error on event %s: %v", eventType, err)
-			}
-		}(handler)
+		if bus.overflow == OverflowBlock {
+			bus.queue <- delivery
+			continue
+		}
+
+		select {
+		case bus.queue <- delivery:
+		default:
+			dropped := bus.dropped.Add(1)
+			log.Printf("[EventBus] Queue full, dropped delivery of event %s (total dropped: %d)", eventType, dropped)
+		}
 	}
 
-	// Don't wait for all handlers to complete (async)
+	// Ordered subscriptions are queued here, in the emitter's goroutine, so
+	// their FIFO order matches the order of Emit calls
+	for _, o := range bus.orderedFor(eventType, "") {
+		o.queue.enqueue(orderedItem{ctx: ctx, eventType: eventType, data: data, key: o.key, plugin: o.plugin, handler: o.handler})
+	}
 }
 
 // EmitSync publishes an event and waits for all handlers to complete synchronously.
@@ -420,62 +665,165 @@ func (bus *EventBus) Emit(eventType string, data interface{}) {
 //   - Emit(): Asynchronous version (recommended for most use cases)
 //   - Subscribe(): Register event handlers
 func (bus *EventBus) EmitSync(eventType string, data interface{}) []error {
+	return bus.EmitSyncCtx(context.Background(), eventType, data)
+}
+
+// EmitSyncCtx publishes an event and waits for all handlers to complete or
+// for ctx to be done, whichever comes first.
+//
+// Handlers run in parallel exactly as with EmitSync and receive ctx, so they
+// can stop early once it is cancelled. If ctx is done before every handler
+// has returned, EmitSyncCtx returns immediately with a timeout error (wrapping
+// ctx.Err()) for each handler that had not finished; those handlers keep
+// running in the background and their results are discarded.
+//
+// A payload that does not match the event's registered schema is not
+// delivered; its validation error is the only error returned.
+//
+// Use this instead of EmitSync on request paths to bound the latency a slow
+// plugin can add:
+//
+//	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+//	defer cancel()
+//	errs := bus.EmitSyncCtx(ctx, "session.deleted", session)
+func (bus *EventBus) EmitSyncCtx(ctx context.Context, eventType string, data interface{}) []error {
+	if err := bus.validatePayload(eventType, data); err != nil {
+		return []error{err}
+	}
+	bus.metrics.recordEmit(eventType)
+	ctx = withEventMeta(ctx, eventType, time.Now())
+	return bus.runSync(ctx, eventType, bus.syncSubscriptions(ctx, eventType, "", data), data)
+}
+
+// subscriptionKey names a subscription in logs and errors.
+func subscriptionKey(eventType, pluginName string) string {
+	return eventType + ":" + pluginName
+}
+
+// subscriptionsFor collects the handlers subscribed to exactly eventType,
+// by every plugin or, with a non-empty pluginName, by that plugin only.
+func (bus *EventBus) subscriptionsFor(eventType, pluginName string) []subscription {
 	bus.mu.RLock()
-	handlers := make([]EventHandler, 0)
+	defer bus.mu.RUnlock()
 
-	for key, subs := range bus.subscribers {
-		if len(key) >= len(eventType) && key[:len(eventType)] == eventType {
-			handlers = append(handlers, subs...)
+	subs := make([]subscription, 0)
+	for plugin, handlers := range bus.subscribers[eventType] {
+		if pluginName != "" && plugin != pluginName {
+			continue
+		}
+		key := subscriptionKey(eventType, plugin)
+		for _, handler := range handlers {
+			subs = append(subs, subscription{key: key, plugin: plugin, handler: handler})
 		}
 	}
-	bus.mu.RUnlock()
+	return subs
+}
+
+// runSync runs subs in parallel with data and waits for them to finish or
+// for ctx to be done, returning their errors (see EmitSyncCtx).
+func (bus *EventBus) runSync(ctx context.Context, eventType string, subs []subscription, data interface{}) []error {
+	type result struct {
+		index int
+		err   error
+	}
 
-	// Call all handlers and collect errors
+	// Buffered so handlers finishing after a timeout never block
+	results := make(chan result, len(subs))
+	for i, sub := range subs {
+		go func(i int, sub subscription) {
+			if sub.ordered {
+				results <- result{index: i, err: sub.handler(ctx, data)}
+				return
+			}
+			results <- result{index: i, err: bus.invoke(ctx, eventType, sub.plugin, sub.handler, data)}
+		}(i, sub)
+	}
+
+	// Collect errors until every handler returns or ctx is done
 	errors := make([]error, 0)
-	var mu sync.Mutex
-
-	var wg sync.WaitGroup
-	for _, handler := range handlers {
-		wg.Add(1)
-		go func(h EventHandler) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					mu.Lock()
-					errors = append(errors, fmt.Errorf("handler panicked: %v", r))
-					mu.Unlock()
+	finished := make([]bool, len(subs))
+	for remaining := len(subs); remaining > 0; remaining-- {
+		select {
+		case res := <-results:
+			finished[res.index] = true
+			if res.err != nil {
+				errors = append(errors, res.err)
+			}
+		case <-ctx.Done():
+			for i, sub := range subs {
+				if !finished[i] {
+					errors = append(errors, fmt.Errorf("handler %s did not finish: %w", sub.key, ctx.Err()))
 				}
-			}()
-
-			if err := h(data); err != nil {
-				mu.Lock()
-				errors = append(errors, err)
-				mu.Unlock()
 			}
-		}(handler)
+			return errors
+		}
 	}
 
-	wg.Wait()
 	return errors
 }
 
-// PluginEvents provides event API for plugins
+// PluginEvents provides event API for plugins.
+//
+// Subscriptions and emitted events are checked against the plugin's event
+// permissions (see event_permissions.go).
 type PluginEvents struct {
-	bus        *EventBus
-	pluginName string
+	bus         *EventBus
+	pluginName  string
+	permissions EventPermissions
 }
 
-// NewPluginEvents creates a new plugin events instance
+// NewPluginEvents creates a new plugin events instance with the default
+// permissions of plugins that declare none.
 func NewPluginEvents(bus *EventBus, pluginName string) *PluginEvents {
+	return NewPluginEventsWithPermissions(bus, pluginName, NewEventPermissions(pluginName, nil))
+}
+
+// NewPluginEventsWithPermissions creates a plugin events instance limited
+// to permissions.
+func NewPluginEventsWithPermissions(bus *EventBus, pluginName string, permissions EventPermissions) *PluginEvents {
 	return &PluginEvents{
-		bus:        bus,
-		pluginName: pluginName,
+		bus:         bus,
+		pluginName:  pluginName,
+		permissions: permissions,
+	}
+}
+
+// On registers an event handler (recorded in the audit log when enabled).
+// Returns ErrEventPermissionDenied without events.listen.{eventType}.
+func (pe *PluginEvents) On(eventType string, handler func(data interface{}) error) error {
+	if err := pe.permissions.checkListen(pe.pluginName, eventType); err != nil {
+		return err
 	}
+	pe.bus.SubscribeAudited(eventType, pe.pluginName, handler)
+	return nil
 }
 
-// On registers an event handler
-func (pe *PluginEvents) On(eventType string, handler func(data interface{}) error) {
-	pe.bus.Subscribe(eventType, pe.pluginName, handler)
+// OnCtx registers a context-aware event handler (recorded in the audit log
+// when enabled). The handler receives the EmitSyncCtx caller's context.
+// Returns ErrEventPermissionDenied without events.listen.{eventType}.
+func (pe *PluginEvents) OnCtx(eventType string, handler ContextEventHandler) error {
+	if err := pe.permissions.checkListen(pe.pluginName, eventType); err != nil {
+		return err
+	}
+	pe.bus.SubscribeCtx(eventType, pe.pluginName, handler)
+	pe.bus.recordAudit(pe.pluginName, eventType, AuditActionSubscribe)
+	return nil
+}
+
+// On[T] (event_schema.go) registers a handler that receives the payload
+// decoded into T; it is a function because Go methods cannot have type
+// parameters.
+
+// OnOrdered registers an event handler that processes events one at a time
+// in emission order (recorded in the audit log when enabled).
+// Returns ErrEventPermissionDenied without events.listen.{eventType}.
+func (pe *PluginEvents) OnOrdered(eventType string, handler func(data interface{}) error) error {
+	if err := pe.permissions.checkListen(pe.pluginName, eventType); err != nil {
+		return err
+	}
+	pe.bus.SubscribeOrdered(eventType, pe.pluginName, handler)
+	pe.bus.recordAudit(pe.pluginName, eventType, AuditActionSubscribe)
+	return nil
 }
 
 // Off removes an event handler
@@ -483,8 +831,14 @@ func (pe *PluginEvents) Off(eventType string) {
 	pe.bus.Unsubscribe(eventType, pe.pluginName)
 }
 
-// Emit emits an event (plugins can emit custom events)
-func (pe *PluginEvents) Emit(eventType string, data interface{}) {
-	// Prefix with plugin name to namespace custom events
-	pe.bus.Emit("plugin."+pe.pluginName+"."+eventType, data)
+// Emit emits an event (plugins can emit custom events). Events are
+// namespaced as plugin.{pluginName}.{eventType}; returns
+// ErrEventPermissionDenied without a matching events.emit permission.
+func (pe *PluginEvents) Emit(eventType string, data interface{}) error {
+	namespaced := pluginEventPrefix + pe.pluginName + "." + eventType
+	if !pe.permissions.CanEmit(namespaced) {
+		return fmt.Errorf("%w: plugin %s needs %s%s", ErrEventPermissionDenied, pe.pluginName, eventEmitPermission, namespaced)
+	}
+	pe.bus.Emit(namespaced, data)
+	return nil
 }
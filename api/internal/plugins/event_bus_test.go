@@ -0,0 +1,196 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streamspace/streamspace/api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitSync_CollectsErrors(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.Subscribe("session.deleted", "ok-plugin", func(data interface{}) error { return nil })
+	bus.Subscribe("session.deleted", "failing-plugin", func(data interface{}) error { return errors.New("boom") })
+	bus.Subscribe("session.deleted", "panicking-plugin", func(data interface{}) error { panic("oops") })
+
+	errs := bus.EmitSync("session.deleted", nil)
+	assert.Len(t, errs, 2)
+}
+
+func TestEmitSyncCtx_ReturnsOnDeadline(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	cancelled := make(chan struct{})
+	bus.SubscribeCtx("session.deleted", "slow-plugin", func(ctx context.Context, data interface{}) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	bus.Subscribe("session.deleted", "fast-plugin", func(data interface{}) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	errs := bus.EmitSyncCtx(ctx, "session.deleted", nil)
+
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], context.DeadlineExceeded))
+	assert.Contains(t, errs[0].Error(), "slow-plugin")
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("slow handler did not observe cancellation")
+	}
+}
+
+func TestEmitSyncCtx_NoHandlers(t *testing.T) {
+	errs := NewEventBus(EventBusConfig{}).EmitSyncCtx(context.Background(), "session.created", nil)
+	assert.Empty(t, errs)
+}
+
+func TestEmit_DropsWhenQueueFull(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{Workers: 1, QueueSize: 1})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.Subscribe("session.created", "slow-plugin", func(data interface{}) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	// First delivery occupies the only worker, second fills the queue
+	bus.Emit("session.created", nil)
+	<-started
+	bus.Emit("session.created", nil)
+	bus.Emit("session.created", nil)
+
+	stats := bus.Stats()
+	assert.Equal(t, 1, stats.Workers)
+	assert.Equal(t, 1, stats.QueueDepth)
+	assert.Equal(t, 1, stats.QueueCapacity)
+	assert.Equal(t, uint64(1), stats.Dropped)
+
+	close(release)
+}
+
+func TestEmit_BlockPolicyDeliversEverything(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{Workers: 2, QueueSize: 1, Overflow: OverflowBlock})
+
+	var delivered sync.WaitGroup
+	delivered.Add(20)
+	bus.Subscribe("session.created", "counter-plugin", func(data interface{}) error {
+		delivered.Done()
+		return nil
+	})
+
+	for i := 0; i < 20; i++ {
+		bus.Emit("session.created", i)
+	}
+
+	delivered.Wait()
+	assert.Equal(t, uint64(0), bus.Stats().Dropped)
+}
+
+func TestEmit_DeliversExactEventTypeOnly(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	var mu sync.Mutex
+	received := map[string][]string{}
+	record := func(plugin string) EventHandler {
+		return func(data interface{}) error {
+			mu.Lock()
+			received[plugin] = append(received[plugin], data.(string))
+			mu.Unlock()
+			return nil
+		}
+	}
+	bus.Subscribe("session.created", "exact", record("exact"))
+	bus.Subscribe("session.created_extra", "extra", record("extra"))
+	bus.SubscribeOrdered("session.created", "ordered", record("ordered"))
+
+	assert.Empty(t, bus.EmitSync("session.created_extra", "extra-event"))
+	assert.Empty(t, bus.EmitSync("session.created", "created-event"))
+	assert.Empty(t, bus.EmitSync("session.created:exact", "compound-event"))
+	assert.Empty(t, bus.EmitSync("session", "short-event"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string][]string{
+		"exact":   {"created-event"},
+		"extra":   {"extra-event"},
+		"ordered": {"created-event"},
+	}, received)
+}
+
+func TestUnsubscribeAll_LeavesSimilarNamesAlone(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	for _, plugin := range []string{"foo", "foo:bar", "barfoo"} {
+		plugin := plugin
+		bus.Subscribe("session.created", plugin, func(data interface{}) error {
+			mu.Lock()
+			calls[plugin]++
+			mu.Unlock()
+			return nil
+		})
+	}
+	bus.SubscribeOrdered("session.deleted", "foo", func(data interface{}) error {
+		calls["foo-ordered"]++
+		return nil
+	})
+
+	bus.UnsubscribeAll("foo")
+
+	assert.Empty(t, bus.EmitSync("session.created", nil))
+	assert.Empty(t, bus.EmitSync("session.deleted", nil))
+	assert.Equal(t, map[string]int{"foo:bar": 1, "barfoo": 1}, calls)
+}
+
+func TestEmitWithContext_PropagatesTrace(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	traces := make(chan tracing.TraceContext, 2)
+	bus.SubscribeCtx("session.created", "audit", func(ctx context.Context, data interface{}) error {
+		trace, _ := tracing.FromContext(ctx)
+		traces <- trace
+		return ctx.Err()
+	})
+	bus.SubscribeOrdered("session.created", "billing", func(data interface{}) error {
+		return nil
+	})
+
+	// The request is over (its context cancelled) before handlers run
+	ctx, cancel := context.WithCancel(tracing.WithTrace(context.Background(), tracing.TraceContext{RequestID: "req-1", UserID: "user-1"}))
+	cancel()
+	bus.EmitWithContext(ctx, "session.created", nil)
+
+	select {
+	case trace := <-traces:
+		assert.Equal(t, tracing.TraceContext{RequestID: "req-1", UserID: "user-1"}, trace)
+	case <-time.After(time.Second):
+		t.Fatal("handler was not invoked")
+	}
+
+	require.Eventually(t, func() bool {
+		for _, h := range bus.Metrics().Handlers {
+			if h.Plugin == "audit" && h.Invocations == 1 {
+				return h.Errors == 0
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond, "handler must not see the emitter's cancellation")
+}
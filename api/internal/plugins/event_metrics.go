@@ -0,0 +1,251 @@
+// Package plugins - event_metrics.go
+//
+// This file implements event bus instrumentation.
+//
+// Every event bus records, per event type, how many events were emitted and,
+// per event type and plugin, how many handler invocations ran, how long they
+// took, and how many returned an error or panicked.
+//
+// bus.Metrics() returns an in-memory snapshot for the bus, served as JSON by
+// GET /api/v1/admin/plugins/events/metrics for the admin UI. The same data
+// is recorded in the default Prometheus registry, scraped via GET /metrics:
+//
+//   - streamspace_plugin_events_emitted_total (counter): label event_type
+//   - streamspace_plugin_event_handler_invocations_total (counter): labels event_type, plugin
+//   - streamspace_plugin_event_handler_errors_total (counter): labels event_type, plugin
+//   - streamspace_plugin_event_handler_panics_total (counter): labels event_type, plugin
+//   - streamspace_plugin_event_handler_duration_seconds (histogram): labels event_type, plugin
+//
+// Panics are counted as errors as well, so the error rate covers every
+// failed invocation.
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	eventsEmittedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_events_emitted_total",
+			Help: "Total number of events emitted on the plugin event bus, by event type.",
+		},
+		[]string{"event_type"},
+	)
+
+	eventHandlerInvocationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_event_handler_invocations_total",
+			Help: "Total number of plugin event handler invocations, by event type and plugin.",
+		},
+		[]string{"event_type", "plugin"},
+	)
+
+	eventHandlerErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_event_handler_errors_total",
+			Help: "Total number of plugin event handler invocations that failed, by event type and plugin.",
+		},
+		[]string{"event_type", "plugin"},
+	)
+
+	eventHandlerPanicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_event_handler_panics_total",
+			Help: "Total number of plugin event handler invocations that panicked, by event type and plugin.",
+		},
+		[]string{"event_type", "plugin"},
+	)
+
+	eventHandlerDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "streamspace_plugin_event_handler_duration_seconds",
+			Help:    "Plugin event handler duration in seconds, by event type and plugin.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"event_type", "plugin"},
+	)
+
+	registerEventMetricsOnce sync.Once
+)
+
+// EventBusMetrics is a snapshot of event bus activity since the bus was
+// created.
+type EventBusMetrics struct {
+	Events   []EventTypeMetrics `json:"events"`
+	Handlers []HandlerMetrics   `json:"handlers"`
+}
+
+// EventTypeMetrics counts the events emitted of one type.
+type EventTypeMetrics struct {
+	EventType string `json:"eventType"`
+	Emitted   uint64 `json:"emitted"`
+}
+
+// HandlerMetrics describes one plugin's handlers for one event type.
+type HandlerMetrics struct {
+	EventType   string `json:"eventType"`
+	Plugin      string `json:"plugin"`
+	Invocations uint64 `json:"invocations"`
+	Errors      uint64 `json:"errors"`
+	Panics      uint64 `json:"panics"`
+
+	// ErrorRate is Errors / Invocations (0 when there were no invocations).
+	ErrorRate float64 `json:"errorRate"`
+
+	AvgDurationMs float64 `json:"avgDurationMs"`
+	MaxDurationMs float64 `json:"maxDurationMs"`
+}
+
+// handlerKey identifies one plugin's handlers for one event type.
+type handlerKey struct {
+	eventType string
+	plugin    string
+}
+
+// handlerStats accumulates invocations for one handlerKey.
+type handlerStats struct {
+	invocations   uint64
+	errors        uint64
+	panics        uint64
+	totalDuration time.Duration
+	maxDuration   time.Duration
+}
+
+// eventMetrics records the activity of one event bus.
+type eventMetrics struct {
+	mu       sync.Mutex
+	emitted  map[string]uint64
+	handlers map[handlerKey]*handlerStats
+}
+
+// newEventMetrics creates an empty recorder and registers the Prometheus
+// collectors on first use.
+func newEventMetrics() *eventMetrics {
+	registerEventMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			eventsEmittedTotal,
+			eventHandlerInvocationsTotal,
+			eventHandlerErrorsTotal,
+			eventHandlerPanicsTotal,
+			eventHandlerDuration,
+		)
+	})
+
+	return &eventMetrics{
+		emitted:  make(map[string]uint64),
+		handlers: make(map[handlerKey]*handlerStats),
+	}
+}
+
+// Metrics returns a snapshot of the bus's emit and handler metrics, sorted
+// by event type and plugin.
+func (bus *EventBus) Metrics() EventBusMetrics {
+	m := bus.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := EventBusMetrics{
+		Events:   make([]EventTypeMetrics, 0, len(m.emitted)),
+		Handlers: make([]HandlerMetrics, 0, len(m.handlers)),
+	}
+	for eventType, emitted := range m.emitted {
+		snapshot.Events = append(snapshot.Events, EventTypeMetrics{EventType: eventType, Emitted: emitted})
+	}
+	for key, stats := range m.handlers {
+		h := HandlerMetrics{
+			EventType:     key.eventType,
+			Plugin:        key.plugin,
+			Invocations:   stats.invocations,
+			Errors:        stats.errors,
+			Panics:        stats.panics,
+			MaxDurationMs: durationMs(stats.maxDuration),
+		}
+		if stats.invocations > 0 {
+			h.ErrorRate = float64(stats.errors) / float64(stats.invocations)
+			h.AvgDurationMs = durationMs(stats.totalDuration) / float64(stats.invocations)
+		}
+		snapshot.Handlers = append(snapshot.Handlers, h)
+	}
+
+	sort.Slice(snapshot.Events, func(i, j int) bool {
+		return snapshot.Events[i].EventType < snapshot.Events[j].EventType
+	})
+	sort.Slice(snapshot.Handlers, func(i, j int) bool {
+		a, b := snapshot.Handlers[i], snapshot.Handlers[j]
+		if a.EventType != b.EventType {
+			return a.EventType < b.EventType
+		}
+		return a.Plugin < b.Plugin
+	})
+	return snapshot
+}
+
+// recordEmit counts one emitted event.
+func (m *eventMetrics) recordEmit(eventType string) {
+	m.mu.Lock()
+	m.emitted[eventType]++
+	m.mu.Unlock()
+
+	eventsEmittedTotal.WithLabelValues(eventType).Inc()
+}
+
+// invoke runs one of plugin's handlers for an event, converting a panic into
+// an error, and records the invocation.
+func (m *eventMetrics) invoke(ctx context.Context, eventType, plugin string, handler ContextEventHandler, data interface{}) (err error) {
+	start := time.Now()
+	panicked := false
+
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+		m.recordInvocation(eventType, plugin, time.Since(start), err, panicked)
+	}()
+
+	return handler(ctx, data)
+}
+
+// recordInvocation records one handler invocation.
+func (m *eventMetrics) recordInvocation(eventType, plugin string, duration time.Duration, err error, panicked bool) {
+	m.mu.Lock()
+	key := handlerKey{eventType: eventType, plugin: plugin}
+	stats := m.handlers[key]
+	if stats == nil {
+		stats = &handlerStats{}
+		m.handlers[key] = stats
+	}
+	stats.invocations++
+	stats.totalDuration += duration
+	if duration > stats.maxDuration {
+		stats.maxDuration = duration
+	}
+	if err != nil {
+		stats.errors++
+	}
+	if panicked {
+		stats.panics++
+	}
+	m.mu.Unlock()
+
+	eventHandlerInvocationsTotal.WithLabelValues(eventType, plugin).Inc()
+	eventHandlerDuration.WithLabelValues(eventType, plugin).Observe(duration.Seconds())
+	if err != nil {
+		eventHandlerErrorsTotal.WithLabelValues(eventType, plugin).Inc()
+	}
+	if panicked {
+		eventHandlerPanicsTotal.WithLabelValues(eventType, plugin).Inc()
+	}
+}
+
+// durationMs converts d to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_RecordsHandlerOutcomes(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.Subscribe("metrics.test", "ok-plugin", func(data interface{}) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	bus.Subscribe("metrics.test", "bad-plugin", func(data interface{}) error {
+		if data.(int) == 0 {
+			panic("boom")
+		}
+		return errors.New("failed")
+	})
+
+	panicsBefore := testutil.ToFloat64(eventHandlerPanicsTotal.WithLabelValues("metrics.test", "bad-plugin"))
+
+	bus.EmitSync("metrics.test", 0)
+	bus.EmitSync("metrics.test", 1)
+
+	metrics := bus.Metrics()
+	require.Equal(t, []EventTypeMetrics{{EventType: "metrics.test", Emitted: 2}}, metrics.Events)
+	require.Len(t, metrics.Handlers, 2)
+
+	bad := metrics.Handlers[0]
+	assert.Equal(t, "bad-plugin", bad.Plugin)
+	assert.Equal(t, uint64(2), bad.Invocations)
+	assert.Equal(t, uint64(2), bad.Errors)
+	assert.Equal(t, uint64(1), bad.Panics)
+	assert.Equal(t, 1.0, bad.ErrorRate)
+
+	ok := metrics.Handlers[1]
+	assert.Equal(t, "ok-plugin", ok.Plugin)
+	assert.Equal(t, uint64(2), ok.Invocations)
+	assert.Zero(t, ok.Errors)
+	assert.GreaterOrEqual(t, ok.MaxDurationMs, 2.0)
+	assert.GreaterOrEqual(t, ok.AvgDurationMs, 2.0)
+
+	assert.Equal(t, panicsBefore+1, testutil.ToFloat64(eventHandlerPanicsTotal.WithLabelValues("metrics.test", "bad-plugin")))
+}
+
+func TestMetrics_AsyncAndOrderedDeliveries(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	done := make(chan struct{}, 2)
+	bus.Subscribe("metrics.async", "pool", func(data interface{}) error {
+		done <- struct{}{}
+		return nil
+	})
+	bus.SubscribeOrdered("metrics.async", "ordered", func(data interface{}) error {
+		done <- struct{}{}
+		return nil
+	})
+
+	bus.Emit("metrics.async", nil)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("handlers were not invoked")
+		}
+	}
+
+	// Invocations are recorded after the handler returns
+	require.Eventually(t, func() bool {
+		return len(bus.Metrics().Handlers) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	for _, h := range bus.Metrics().Handlers {
+		assert.Equal(t, uint64(1), h.Invocations, h.Plugin)
+	}
+}
+
+func TestMetrics_OrderedSyncCountedOnce(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.SubscribeOrdered("metrics.sync", "billing", func(data interface{}) error {
+		return nil
+	})
+
+	require.Empty(t, bus.EmitSync("metrics.sync", nil))
+
+	metrics := bus.Metrics()
+	require.Len(t, metrics.Handlers, 1)
+	assert.Equal(t, uint64(1), metrics.Handlers[0].Invocations)
+}
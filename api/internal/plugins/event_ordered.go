@@ -0,0 +1,220 @@
+// Package plugins - event_ordered.go
+//
+// This file implements ordered (sequential) event delivery.
+//
+// Handlers registered with Subscribe run on the shared worker pool, so two
+// events can be processed concurrently and in either order, even by the same
+// subscriber. Plugins such as billing need session.created for a session to
+// be processed before the matching session.deleted.
+//
+// SubscribeOrdered routes a subscription through a worker goroutine fed by
+// an unbounded FIFO queue. All ordered subscriptions of one plugin share that
+// queue, so a plugin's ordered handlers see events in the order they were
+// emitted even across event types:
+//
+//   - Emit appends to the queue in the emitter's goroutine and returns
+//     immediately, so events are processed in the order Emit was called
+//   - The worker runs one handler invocation at a time
+//   - EmitSync/EmitSyncCtx queue the same way and wait for the result
+//
+// # Panics and Errors
+//
+// A handler that panics or returns an error for one event is logged and the
+// worker moves on to the next queued event (skip and continue). A failing
+// event is never retried, so it cannot stall the queue.
+//
+// # Ordering Scope
+//
+// Ordering holds per plugin. Events emitted concurrently from different
+// goroutines are processed in the order they reached Emit; there is no
+// ordering between different plugins, or between a plugin's ordered and
+// regular (Subscribe) handlers.
+package plugins
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/streamspace/streamspace/api/internal/tracing"
+)
+
+// orderedItem is one queued invocation of an ordered subscription.
+type orderedItem struct {
+	ctx       context.Context
+	eventType string
+	data      interface{}
+	key       string
+	plugin    string
+	handler   ContextEventHandler
+
+	// done receives the handler result when a caller is waiting (EmitSync)
+	done chan error
+}
+
+// orderedQueue runs a plugin's ordered handlers strictly in FIFO order.
+type orderedQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []orderedItem
+	closed  bool
+
+	// invoke runs and records one handler invocation (EventBus.invoke)
+	invoke func(ctx context.Context, eventType, plugin string, handler ContextEventHandler, data interface{}) error
+}
+
+// orderedSubscription is one SubscribeOrdered registration.
+type orderedSubscription struct {
+	key     string
+	plugin  string
+	handler ContextEventHandler
+	queue   *orderedQueue
+}
+
+// SubscribeOrdered registers a handler that receives events of eventType one
+// at a time, in the order they were emitted, relative to every other
+// ordered handler of pluginName.
+//
+// Emit never blocks on an ordered subscription: events are appended to an
+// in-memory queue that a dedicated goroutine drains. Once a plugin has no
+// ordered subscriptions left, the goroutine exits after processing the
+// events already queued.
+//
+// Example:
+//
+//	// session.created for a session always runs before session.deleted
+//	bus.SubscribeOrdered("session.created", "billing", startMetering)
+//	bus.SubscribeOrdered("session.deleted", "billing", stopMetering)
+func (bus *EventBus) SubscribeOrdered(eventType string, pluginName string, handler EventHandler) {
+	bus.mu.Lock()
+	queue := bus.orderedQueues[pluginName]
+	if queue == nil {
+		queue = &orderedQueue{invoke: bus.invoke}
+		queue.cond = sync.NewCond(&queue.mu)
+		go queue.run()
+		bus.orderedQueues[pluginName] = queue
+	}
+	plugins := bus.ordered[eventType]
+	if plugins == nil {
+		plugins = make(map[string][]*orderedSubscription)
+		bus.ordered[eventType] = plugins
+	}
+	plugins[pluginName] = append(plugins[pluginName], &orderedSubscription{
+		key:    subscriptionKey(eventType, pluginName),
+		plugin: pluginName,
+		handler: func(_ context.Context, data interface{}) error {
+			return handler(data)
+		},
+		queue: queue,
+	})
+	bus.mu.Unlock()
+
+	log.Printf("[EventBus] Plugin %s subscribed (ordered) to %s", pluginName, eventType)
+}
+
+// removeOrdered deletes pluginName's ordered subscriptions to eventType and
+// returns the plugin's queue if it no longer has any subscriptions.
+//
+// Must be called with bus.mu held for writing; the caller closes the
+// returned queue after releasing the lock.
+func (bus *EventBus) removeOrdered(eventType, pluginName string) *orderedQueue {
+	plugins := bus.ordered[eventType]
+	if _, ok := plugins[pluginName]; !ok {
+		return nil
+	}
+	delete(plugins, pluginName)
+	if len(plugins) == 0 {
+		delete(bus.ordered, eventType)
+	}
+
+	for _, other := range bus.ordered {
+		if _, ok := other[pluginName]; ok {
+			return nil
+		}
+	}
+
+	queue := bus.orderedQueues[pluginName]
+	delete(bus.orderedQueues, pluginName)
+	return queue
+}
+
+// orderedFor collects the ordered subscriptions receiving eventType (see
+// subscriptionsFor for the matching rules).
+func (bus *EventBus) orderedFor(eventType, pluginName string) []*orderedSubscription {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	var subs []*orderedSubscription
+	for plugin, ordered := range bus.ordered[eventType] {
+		if pluginName == "" || plugin == pluginName {
+			subs = append(subs, ordered...)
+		}
+	}
+	return subs
+}
+
+// syncSubscriptions returns the subscriptions a synchronous delivery of data
+// must wait for. Ordered subscriptions are queued immediately, so they keep
+// their FIFO position, and contribute a handler that waits for the result.
+func (bus *EventBus) syncSubscriptions(ctx context.Context, eventType, pluginName string, data interface{}) []subscription {
+	subs := bus.subscriptionsFor(eventType, pluginName)
+	for _, o := range bus.orderedFor(eventType, pluginName) {
+		done := make(chan error, 1)
+		if !o.queue.enqueue(orderedItem{ctx: ctx, eventType: eventType, data: data, key: o.key, plugin: o.plugin, handler: o.handler, done: done}) {
+			continue
+		}
+		subs = append(subs, subscription{key: o.key, plugin: o.plugin, ordered: true, handler: func(context.Context, interface{}) error {
+			return <-done
+		}})
+	}
+	return subs
+}
+
+// enqueue appends an item to the queue. It returns false if the queue has
+// been closed.
+func (q *orderedQueue) enqueue(item orderedItem) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+	q.items = append(q.items, item)
+	q.cond.Signal()
+	return true
+}
+
+// close stops the worker once the queued items have been processed.
+func (q *orderedQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Signal()
+	q.mu.Unlock()
+}
+
+// run processes queued items one at a time until the queue is closed and
+// empty.
+func (q *orderedQueue) run() {
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		item := q.items[0]
+		q.items[0] = orderedItem{}
+		q.items = q.items[1:]
+		q.mu.Unlock()
+
+		err := q.invoke(item.ctx, item.eventType, item.plugin, item.handler, item.data)
+		if err != nil {
+			log.Printf("[EventBus] %sOrdered handler %s failed, skipping event: %v", tracing.LogPrefix(item.ctx), item.key, err)
+		}
+		if item.done != nil {
+			item.done <- err
+		}
+	}
+}
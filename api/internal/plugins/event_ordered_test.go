@@ -0,0 +1,130 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeOrdered_PreservesEmitOrder(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{Workers: 8})
+
+	var mu sync.Mutex
+	var got []int
+	done := make(chan struct{})
+	bus.SubscribeOrdered("session.created", "billing", func(data interface{}) error {
+		// Vary handler latency so unordered delivery would interleave
+		if data.(int)%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		got = append(got, data.(int))
+		if len(got) == 100 {
+			close(done)
+		}
+		mu.Unlock()
+		return nil
+	})
+
+	for i := 0; i < 100; i++ {
+		bus.Emit("session.created", i)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ordered handler did not process every event")
+	}
+
+	for i, v := range got {
+		require.Equal(t, i, v, "events processed out of order")
+	}
+}
+
+func TestSubscribeOrdered_ConcurrentEmitters(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	var mu sync.Mutex
+	created := map[string]bool{}
+	var violations []string
+	var processed sync.WaitGroup
+
+	const sessions = 50
+	processed.Add(2 * sessions)
+	handler := func(data interface{}) error {
+		defer processed.Done()
+		event := data.([2]string)
+		mu.Lock()
+		defer mu.Unlock()
+		switch event[0] {
+		case "created":
+			created[event[1]] = true
+		case "deleted":
+			if !created[event[1]] {
+				violations = append(violations, event[1])
+			}
+		}
+		return nil
+	}
+	bus.SubscribeOrdered("session.created", "billing", handler)
+	bus.SubscribeOrdered("session.deleted", "billing", handler)
+
+	var emitters sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		emitters.Add(1)
+		go func(id string) {
+			defer emitters.Done()
+			bus.Emit("session.created", [2]string{"created", id})
+			bus.Emit("session.deleted", [2]string{"deleted", id})
+		}(fmt.Sprintf("sess-%d", i))
+	}
+	emitters.Wait()
+	processed.Wait()
+
+	assert.Empty(t, violations, "session.deleted processed before session.created")
+}
+
+func TestSubscribeOrdered_PanicSkipsEvent(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+
+	seen := make(chan int, 3)
+	bus.SubscribeOrdered("session.created", "flaky", func(data interface{}) error {
+		if data.(int) == 1 {
+			panic("boom")
+		}
+		seen <- data.(int)
+		return nil
+	})
+
+	bus.Emit("session.created", 0)
+	bus.Emit("session.created", 1)
+	bus.Emit("session.created", 2)
+
+	for _, want := range []int{0, 2} {
+		select {
+		case got := <-seen:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not delivered after a panic", want)
+		}
+	}
+}
+
+func TestSubscribeOrdered_EmitSyncWaits(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.SubscribeOrdered("session.deleted", "billing", func(data interface{}) error {
+		return errors.New("invoice not found")
+	})
+
+	errs := bus.EmitSync("session.deleted", nil)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "invoice not found")
+
+	bus.UnsubscribeAll("billing")
+	assert.Empty(t, bus.EmitSync("session.deleted", nil))
+}
@@ -0,0 +1,120 @@
+// Package plugins - event_permissions.go
+//
+// This file implements the permissions that control which events a plugin
+// may subscribe to and emit through ctx.Events.
+//
+// Plugins declare event permissions in their manifest next to their other
+// permissions:
+//
+//	"permissions": ["database", "events.listen.session.*", "events.emit.plugin.billing.*"]
+//
+// Event permissions have two forms:
+//   - events.listen.{pattern}: subscribe to events matching pattern
+//   - events.emit.{pattern}: emit events matching pattern. Plugins emit under
+//     their own namespace (ctx.Events.Emit("invoice.paid", ...) emits
+//     plugin.billing.invoice.paid), so emit patterns start with
+//     plugin.{pluginName}.
+//
+// A pattern is an event type, or a prefix ending in ".*" matching every
+// event type below it, or "*". Other plugins' events (plugin.{name}.*) are
+// private: "*" does not match them, only patterns starting with "plugin."
+// do.
+//
+// Manifests that predate event permissions declare none. Such plugins are
+// granted the defaults they relied on: listening to all platform events and
+// their own, and emitting their own events. They cannot listen to other
+// plugins' events.
+//
+// The runtime computes a plugin's permissions when loading it and keeps
+// them with the loaded plugin (see RuntimeV2.EventPermissions).
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// eventListenPermission prefixes manifest permissions to subscribe to events
+	eventListenPermission = "events.listen."
+
+	// eventEmitPermission prefixes manifest permissions to emit events
+	eventEmitPermission = "events.emit."
+
+	// pluginEventPrefix namespaces the events plugins emit
+	pluginEventPrefix = "plugin."
+)
+
+// ErrEventPermissionDenied is returned when a plugin subscribes to or emits
+// an event it has no permission for.
+var ErrEventPermissionDenied = errors.New("event permission denied")
+
+// EventPermissions are the event patterns a plugin may listen to and emit.
+type EventPermissions struct {
+	Listen []string `json:"listen"`
+	Emit   []string `json:"emit"`
+
+	// Default is true when the manifest declared no event permissions and
+	// the defaults for such plugins were granted.
+	Default bool `json:"default,omitempty"`
+}
+
+// NewEventPermissions returns the event permissions granted by a plugin's
+// manifest permissions.
+func NewEventPermissions(pluginName string, manifestPermissions []string) EventPermissions {
+	perms := EventPermissions{Listen: []string{}, Emit: []string{}}
+	for _, p := range manifestPermissions {
+		switch {
+		case strings.HasPrefix(p, eventListenPermission):
+			perms.Listen = append(perms.Listen, strings.TrimPrefix(p, eventListenPermission))
+		case strings.HasPrefix(p, eventEmitPermission):
+			perms.Emit = append(perms.Emit, strings.TrimPrefix(p, eventEmitPermission))
+		}
+	}
+
+	if len(perms.Listen) == 0 && len(perms.Emit) == 0 {
+		own := pluginEventPrefix + pluginName + ".*"
+		perms.Listen = []string{"*", own}
+		perms.Emit = []string{own}
+		perms.Default = true
+	}
+	return perms
+}
+
+// CanListen reports whether the plugin may subscribe to eventType.
+func (p EventPermissions) CanListen(eventType string) bool {
+	return matchEventPatterns(p.Listen, eventType)
+}
+
+// CanEmit reports whether the plugin may emit eventType (the full,
+// namespaced event type).
+func (p EventPermissions) CanEmit(eventType string) bool {
+	return matchEventPatterns(p.Emit, eventType)
+}
+
+// checkListen returns ErrEventPermissionDenied if the plugin may not
+// subscribe to eventType.
+func (p EventPermissions) checkListen(pluginName, eventType string) error {
+	if p.CanListen(eventType) {
+		return nil
+	}
+	return fmt.Errorf("%w: plugin %s needs %s%s", ErrEventPermissionDenied, pluginName, eventListenPermission, eventType)
+}
+
+// matchEventPatterns reports whether any pattern matches eventType.
+func matchEventPatterns(patterns []string, eventType string) bool {
+	private := strings.HasPrefix(eventType, pluginEventPrefix)
+	for _, pattern := range patterns {
+		if private && !strings.HasPrefix(pattern, pluginEventPrefix) {
+			continue
+		}
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEventPermissions_FromManifest(t *testing.T) {
+	perms := NewEventPermissions("reports", []string{
+		"database",
+		"events.listen.session.*",
+		"events.listen.plugin.billing.invoice.paid",
+		"events.emit.plugin.reports.*",
+	})
+
+	assert.False(t, perms.Default)
+	assert.True(t, perms.CanListen("session.created"))
+	assert.True(t, perms.CanListen("plugin.billing.invoice.paid"))
+	assert.False(t, perms.CanListen("user.created"))
+	assert.False(t, perms.CanListen("plugin.billing.invoice.voided"))
+	assert.False(t, perms.CanListen("sessionx.created"))
+
+	assert.True(t, perms.CanEmit("plugin.reports.generated"))
+	assert.False(t, perms.CanEmit("plugin.billing.invoice.paid"))
+}
+
+func TestNewEventPermissions_Default(t *testing.T) {
+	perms := NewEventPermissions("reports", []string{"database"})
+
+	assert.True(t, perms.Default)
+	assert.True(t, perms.CanListen("session.created"))
+	assert.True(t, perms.CanListen("plugin.reports.generated"))
+	assert.False(t, perms.CanListen("plugin.billing.invoice.paid"), "other plugins' events need an explicit permission")
+	assert.True(t, perms.CanEmit("plugin.reports.generated"))
+}
+
+func TestPluginEvents_EnforcesPermissions(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	billing := NewPluginEventsWithPermissions(bus, "billing",
+		NewEventPermissions("billing", []string{"events.emit.plugin.billing.invoice.*"}))
+	reports := NewPluginEventsWithPermissions(bus, "reports",
+		NewEventPermissions("reports", []string{"events.listen.plugin.billing.*"}))
+
+	err := reports.On("session.created", func(data interface{}) error { return nil })
+	assert.True(t, errors.Is(err, ErrEventPermissionDenied))
+	assert.Contains(t, err.Error(), "events.listen.session.created")
+
+	received := make(chan interface{}, 1)
+	require.NoError(t, reports.On("plugin.billing.invoice.paid", func(data interface{}) error {
+		received <- data
+		return nil
+	}))
+
+	err = billing.Emit("refund.issued", nil)
+	assert.True(t, errors.Is(err, ErrEventPermissionDenied))
+
+	require.NoError(t, billing.Emit("invoice.paid", "inv-1"))
+	select {
+	case data := <-received:
+		assert.Equal(t, "inv-1", data)
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
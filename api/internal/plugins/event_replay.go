@@ -0,0 +1,281 @@
+// Package plugins - event_replay.go
+//
+// This file implements event persistence and replay for the event bus.
+//
+// Developing a plugin against real sessions is slow: every test of a
+// handler means creating and deleting a session. When an EventBus is
+// created with NewEventBusWithPersistence, every event passed to Emit is
+// recorded in the event_log table, and ReplayEvent/ReplayEvents re-deliver
+// recorded events to the current subscribers, optionally to a single plugin.
+//
+// # Replay Envelope
+//
+// Replayed events are not delivered with their original Go type. Handlers
+// receive a *ReplayedEvent whose Data holds the JSON payload that was
+// recorded, so plugins can tell replays apart from live events:
+//
+//	ctx.Events.Subscribe("session.created", func(data interface{}) error {
+//	    if replay, ok := data.(*plugins.ReplayedEvent); ok {
+//	        log.Printf("replay of event %d: %s", replay.ID, replay.Data)
+//	        return nil
+//	    }
+//	    ...
+//	})
+//
+// Replays are delivered synchronously (like EmitSyncCtx) so handler errors
+// can be reported back to the developer, and they are not recorded again.
+//
+// # Performance
+//
+// Like the subscription audit, records are written by a single background
+// goroutine fed by a buffered channel. Emit only marshals the payload and
+// enqueues it; if the buffer is full the record is dropped with a warning.
+package plugins
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+const (
+	// eventStoreBufferSize is the number of event records that may be queued
+	// before new records are dropped
+	eventStoreBufferSize = 1024
+
+	// eventStoreWriteTimeout bounds a single event_log INSERT
+	eventStoreWriteTimeout = 5 * time.Second
+
+	// maxReplayEvents caps how many events one ReplayEvents call re-delivers
+	maxReplayEvents = 1000
+)
+
+var (
+	// ErrEventNotFound is returned by ReplayEvent for an unknown event ID.
+	ErrEventNotFound = errors.New("event not found")
+
+	// ErrEventPersistenceDisabled is returned when replaying on a bus created
+	// without NewEventBusWithPersistence.
+	ErrEventPersistenceDisabled = errors.New("event persistence is not enabled")
+)
+
+// StoredEvent is one event recorded in event_log.
+type StoredEvent struct {
+	ID          int64           `json:"id"`
+	EventID     string          `json:"eventId"`
+	EventType   string          `json:"eventType"`
+	Payload     json.RawMessage `json:"payload"`
+	PublishedAt time.Time       `json:"publishedAt"`
+}
+
+// ReplayedEvent is the envelope handlers receive for a replayed event.
+type ReplayedEvent struct {
+	// Replayed is always true; it lets handlers that decode the envelope
+	// generically distinguish replays from live events.
+	Replayed bool `json:"replayed"`
+
+	// ID is the event_log ID of the original event.
+	ID int64 `json:"id"`
+
+	EventType   string          `json:"eventType"`
+	PublishedAt time.Time       `json:"publishedAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// ReplayResult reports the delivery of one replayed event.
+type ReplayResult struct {
+	ID         int64    `json:"id"`
+	EventType  string   `json:"eventType"`
+	Deliveries int      `json:"deliveries"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// eventStore persists emitted events in the background.
+type eventStore struct {
+	db      *db.Database
+	records chan StoredEvent
+}
+
+// NewEventBusWithPersistence enables event persistence on bus.
+//
+// Every event passed to Emit is recorded in event_log using database, which
+// makes it available to ReplayEvent and ReplayEvents. The same bus is
+// returned for convenience. Calling it twice on one bus has no effect.
+//
+// Example:
+//
+//	bus := NewEventBusWithPersistence(NewEventBus(EventBusConfig{}), database)
+func NewEventBusWithPersistence(bus *EventBus, database *db.Database) *EventBus {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.store != nil || database == nil {
+		return bus
+	}
+
+	store := &eventStore{
+		db:      database,
+		records: make(chan StoredEvent, eventStoreBufferSize),
+	}
+	go store.run()
+	bus.store = store
+
+	return bus
+}
+
+// ReplayEvent re-delivers the recorded event with the given ID.
+//
+// If pluginName is non-empty, only that plugin's subscriptions receive the
+// event. Handlers receive a *ReplayedEvent and the call returns once they
+// have finished or ctx is done.
+func (bus *EventBus) ReplayEvent(ctx context.Context, id int64, pluginName string) (*ReplayResult, error) {
+	store, err := bus.eventStore()
+	if err != nil {
+		return nil, err
+	}
+
+	var event StoredEvent
+	err = store.db.DB().QueryRowContext(ctx, `
+		SELECT id, event_id, subject, payload, published_at
+		FROM event_log
+		WHERE id = $1
+	`, id).Scan(&event.ID, &event.EventID, &event.EventType, &event.Payload, &event.PublishedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event %d: %w", id, err)
+	}
+
+	result := bus.replay(ctx, event, pluginName)
+	return &result, nil
+}
+
+// ReplayEvents re-delivers recorded events of eventType published at or
+// after since, oldest first, up to maxReplayEvents.
+//
+// If pluginName is non-empty, only that plugin's subscriptions receive the
+// events. Each event is delivered synchronously before the next is sent.
+func (bus *EventBus) ReplayEvents(ctx context.Context, eventType string, since time.Time, pluginName string) ([]ReplayResult, error) {
+	store, err := bus.eventStore()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := store.db.DB().QueryContext(ctx, `
+		SELECT id, event_id, subject, payload, published_at
+		FROM event_log
+		WHERE subject = $1 AND published_at >= $2
+		ORDER BY published_at ASC, id ASC
+		LIMIT $3
+	`, eventType, since, maxReplayEvents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	// Load everything first so handlers never run while rows are open
+	events := []StoredEvent{}
+	for rows.Next() {
+		var event StoredEvent
+		if err := rows.Scan(&event.ID, &event.EventID, &event.EventType, &event.Payload, &event.PublishedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	results := make([]ReplayResult, 0, len(events))
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, bus.replay(ctx, event, pluginName))
+	}
+	return results, nil
+}
+
+// replay delivers event wrapped in a ReplayedEvent envelope.
+func (bus *EventBus) replay(ctx context.Context, event StoredEvent, pluginName string) ReplayResult {
+	envelope := &ReplayedEvent{
+		Replayed:    true,
+		ID:          event.ID,
+		EventType:   event.EventType,
+		PublishedAt: event.PublishedAt,
+		Data:        event.Payload,
+	}
+	subs := bus.syncSubscriptions(ctx, event.EventType, pluginName, envelope)
+
+	result := ReplayResult{ID: event.ID, EventType: event.EventType, Deliveries: len(subs)}
+	for _, err := range bus.runSync(ctx, event.EventType, subs, envelope) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	log.Printf("[EventBus] Replayed event %d (%s) to %d handlers", event.ID, event.EventType, len(subs))
+	return result
+}
+
+// eventStore returns the bus's store, or ErrEventPersistenceDisabled.
+func (bus *EventBus) eventStore() (*eventStore, error) {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	if bus.store == nil {
+		return nil, ErrEventPersistenceDisabled
+	}
+	return bus.store, nil
+}
+
+// persist queues an event record if persistence is enabled.
+//
+// Must be called without holding bus.mu.
+func (bus *EventBus) persist(eventType string, data interface{}) {
+	bus.mu.RLock()
+	store := bus.store
+	bus.mu.RUnlock()
+
+	if store == nil {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[EventBus] Not persisting event %s: payload is not JSON-encodable: %v", eventType, err)
+		return
+	}
+
+	select {
+	case store.records <- StoredEvent{
+		EventID:     uuid.New().String(),
+		EventType:   eventType,
+		Payload:     payload,
+		PublishedAt: time.Now(),
+	}:
+	default:
+		log.Printf("[EventBus] Event store buffer full, dropping record of event %s", eventType)
+	}
+}
+
+// run writes queued event records until the process exits.
+func (s *eventStore) run() {
+	for record := range s.records {
+		ctx, cancel := context.WithTimeout(context.Background(), eventStoreWriteTimeout)
+		if _, err := s.db.DB().ExecContext(ctx, `
+			INSERT INTO event_log (event_id, subject, payload, published_at)
+			VALUES ($1, $2, $3, $4)
+		`, record.EventID, record.EventType, []byte(record.Payload), record.PublishedAt); err != nil {
+			log.Printf("[EventBus] Failed to persist event %s: %v", record.EventType, err)
+		}
+		cancel()
+	}
+}
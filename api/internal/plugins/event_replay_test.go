@@ -0,0 +1,117 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var eventLogColumns = []string{"id", "event_id", "subject", "payload", "published_at"}
+
+func TestEmit_PersistsEvent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectExec("INSERT INTO event_log").
+		WithArgs(sqlmock.AnyArg(), "session.created", []byte(`{"id":"sess-1"}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	bus := NewEventBusWithPersistence(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+	bus.Emit("session.created", map[string]string{"id": "sess-1"})
+
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReplayEvent_TargetsPlugin(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	publishedAt := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT id, event_id, subject, payload, published_at FROM event_log").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(eventLogColumns).
+			AddRow(int64(7), "evt-7", "session.created", []byte(`{"id":"sess-1"}`), publishedAt))
+
+	bus := NewEventBusWithPersistence(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+
+	var received *ReplayedEvent
+	bus.Subscribe("session.created", "target-plugin", func(data interface{}) error {
+		received = data.(*ReplayedEvent)
+		return nil
+	})
+	bus.Subscribe("session.created", "other-plugin", func(data interface{}) error {
+		t.Error("replay was delivered to a plugin that was not targeted")
+		return nil
+	})
+
+	result, err := bus.ReplayEvent(context.Background(), 7, "target-plugin")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Deliveries)
+	assert.Empty(t, result.Errors)
+
+	require.NotNil(t, received)
+	assert.True(t, received.Replayed)
+	assert.Equal(t, int64(7), received.ID)
+	assert.JSONEq(t, `{"id":"sess-1"}`, string(received.Data))
+
+	encoded, err := json.Marshal(received)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"replayed":true`)
+}
+
+func TestReplayEvent_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT id, event_id, subject, payload, published_at FROM event_log").
+		WithArgs(int64(99)).
+		WillReturnRows(sqlmock.NewRows(eventLogColumns))
+
+	bus := NewEventBusWithPersistence(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+
+	_, err = bus.ReplayEvent(context.Background(), 99, "")
+	assert.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestReplayEvents_ByType(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	since := time.Now().Add(-24 * time.Hour)
+	mock.ExpectQuery("SELECT id, event_id, subject, payload, published_at FROM event_log").
+		WithArgs("session.created", since, maxReplayEvents).
+		WillReturnRows(sqlmock.NewRows(eventLogColumns).
+			AddRow(int64(1), "evt-1", "session.created", []byte(`{}`), since).
+			AddRow(int64(2), "evt-2", "session.created", []byte(`{}`), since.Add(time.Minute)))
+
+	bus := NewEventBusWithPersistence(NewEventBus(EventBusConfig{}), db.NewDatabaseFromDB(mockDB))
+
+	var ids []int64
+	bus.Subscribe("session.created", "debug-plugin", func(data interface{}) error {
+		ids = append(ids, data.(*ReplayedEvent).ID)
+		return nil
+	})
+
+	results, err := bus.ReplayEvents(context.Background(), "session.created", since, "")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplay_WithoutPersistence(t *testing.T) {
+	_, err := NewEventBus(EventBusConfig{}).ReplayEvent(context.Background(), 1, "")
+	assert.ErrorIs(t, err, ErrEventPersistenceDisabled)
+}
@@ -0,0 +1,394 @@
+// Package plugins - event_sandbox.go
+//
+// This file implements the plugin sandbox, which bounds how long a plugin's
+// event handlers may run.
+//
+// Every handler invocation on a bus with a PluginSandbox (see
+// EventBus.SetSandbox) receives a context with a deadline of the plugin's
+// maxHandlerDuration. A handler still running at the deadline has its
+// context cancelled and the invocation fails with ErrHandlerTimeout; the
+// handler's goroutine cannot be stopped, but the worker or ordered queue
+// that called it moves on.
+//
+// # Configuration
+//
+// The limit is read from the plugin's installed_plugins.config when it is
+// loaded, as a Go duration string or a number of seconds:
+//
+//	{"maxHandlerDuration": "10s"}
+//
+// Plugins without one get defaultMaxHandlerDuration (10 seconds).
+//
+// Timeouts also count towards marking the plugin degraded (see
+// timeouts.go).
+//
+// # Violations
+//
+// Each timeout is a violation: it increments
+// streamspace_plugin_violations_total{plugin,type} and is recorded in the
+// plugin_violations table. A plugin with maxViolations (3) violations within
+// violationWindow (1 hour) is suspended: its handlers are no longer invoked,
+// the sandbox's disable function is called (RuntimeV2 disables the plugin
+// and persists enabled = false), and a "plugin.violated.timeout" event
+// (PluginViolationEvent) is emitted. Re-enabling the plugin lifts the
+// suspension.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/tracing"
+)
+
+// EventPluginViolatedTimeout is emitted when a plugin is disabled after its
+// event handlers repeatedly exceeded their maxHandlerDuration.
+const EventPluginViolatedTimeout = "plugin.violated.timeout"
+
+const (
+	// ViolationTimeout is the violation type of a handler that exceeded its
+	// maxHandlerDuration
+	ViolationTimeout = "timeout"
+
+	// defaultMaxHandlerDuration is the handler limit of plugins that do not
+	// configure maxHandlerDuration
+	defaultMaxHandlerDuration = 10 * time.Second
+
+	// maxViolations is the number of violations within violationWindow that
+	// disables a plugin
+	maxViolations = 3
+
+	// violationWindow is the window in which maxViolations are counted
+	violationWindow = time.Hour
+
+	// violationWriteTimeout bounds a single plugin_violations INSERT
+	violationWriteTimeout = 5 * time.Second
+)
+
+// ErrHandlerTimeout is returned for a handler invocation that exceeded its
+// plugin's maxHandlerDuration.
+var ErrHandlerTimeout = errors.New("plugin event handler exceeded its maximum duration")
+
+var (
+	pluginViolationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_violations_total",
+			Help: "Total number of plugin sandbox violations, by plugin and violation type.",
+		},
+		[]string{"plugin", "type"},
+	)
+
+	registerSandboxMetricsOnce sync.Once
+)
+
+// PluginViolationEvent is the payload of plugin.violated.timeout.
+type PluginViolationEvent struct {
+	Plugin string `json:"plugin"`
+	Type   string `json:"type"`
+
+	// EventType is the event whose handler caused the final violation.
+	EventType string `json:"eventType"`
+
+	// Limit is the plugin's maxHandlerDuration, e.g. "30s".
+	Limit string `json:"limit"`
+
+	// Violations is the number of violations within Window.
+	Violations int    `json:"violations"`
+	Window     string `json:"window"`
+}
+
+// PluginSandbox enforces per-plugin limits on event handler invocations.
+type PluginSandbox struct {
+	db *db.Database
+
+	mu         sync.Mutex
+	limits     map[string]time.Duration
+	violations map[string]*pluginViolations
+	events     *EventBus
+	disable    func(ctx context.Context, pluginName string) error
+	onTimeout  func(pluginName, kind string)
+}
+
+// pluginViolations tracks recent violations of one plugin.
+type pluginViolations struct {
+	total     uint64
+	recent    []time.Time
+	suspended bool
+}
+
+// NewPluginSandbox creates a sandbox that records violations in database's
+// plugin_violations table. database may be nil, in which case violations
+// are only logged and counted.
+func NewPluginSandbox(database *db.Database) *PluginSandbox {
+	registerSandboxMetricsOnce.Do(func() {
+		prometheus.MustRegister(pluginViolationsTotal)
+	})
+
+	return &PluginSandbox{
+		db:         database,
+		limits:     make(map[string]time.Duration),
+		violations: make(map[string]*pluginViolations),
+	}
+}
+
+// SetSandbox makes the bus invoke every handler through sandbox, and makes
+// sandbox emit plugin.violated.timeout on the bus.
+func (bus *EventBus) SetSandbox(sandbox *PluginSandbox) {
+	bus.mu.Lock()
+	bus.sandbox = sandbox
+	bus.mu.Unlock()
+
+	sandbox.mu.Lock()
+	sandbox.events = bus
+	sandbox.mu.Unlock()
+}
+
+// SetDisableFunc sets the function called when a plugin exceeds its
+// violation budget. Without one, the plugin's handlers are only suspended.
+func (s *PluginSandbox) SetDisableFunc(disable func(ctx context.Context, pluginName string) error) {
+	s.mu.Lock()
+	s.disable = disable
+	s.mu.Unlock()
+}
+
+// SetTimeoutFunc sets the function called when a plugin event handler
+// times out.
+func (s *PluginSandbox) SetTimeoutFunc(onTimeout func(pluginName, kind string)) {
+	s.mu.Lock()
+	s.onTimeout = onTimeout
+	s.mu.Unlock()
+}
+
+// Configure sets a plugin's limits from its installed_plugins.config.
+//
+// An invalid maxHandlerDuration is logged and the default is used.
+func (s *PluginSandbox) Configure(pluginName string, config map[string]interface{}) {
+	limit := defaultMaxHandlerDuration
+	if raw, ok := config["maxHandlerDuration"]; ok {
+		parsed, err := parseHandlerDuration(raw)
+		if err != nil {
+			log.Printf("[Plugin Sandbox] Invalid maxHandlerDuration for %s, using %s: %v", pluginName, defaultMaxHandlerDuration, err)
+		} else {
+			limit = parsed
+		}
+	}
+	s.SetMaxHandlerDuration(pluginName, limit)
+}
+
+// SetMaxHandlerDuration sets how long each of a plugin's handler
+// invocations may run.
+func (s *PluginSandbox) SetMaxHandlerDuration(pluginName string, limit time.Duration) {
+	s.mu.Lock()
+	s.limits[pluginName] = limit
+	s.mu.Unlock()
+}
+
+// MaxHandlerDuration returns a plugin's handler limit.
+func (s *PluginSandbox) MaxHandlerDuration(pluginName string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit, ok := s.limits[pluginName]; ok {
+		return limit
+	}
+	return defaultMaxHandlerDuration
+}
+
+// ViolationCount returns the number of violations recorded for a plugin
+// since it was loaded.
+func (s *PluginSandbox) ViolationCount(pluginName string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v := s.violations[pluginName]; v != nil {
+		return v.total
+	}
+	return 0
+}
+
+// Suspended reports whether a plugin's handlers are suspended after
+// exceeding its violation budget.
+func (s *PluginSandbox) Suspended(pluginName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.violations[pluginName]
+	return v != nil && v.suspended
+}
+
+// Resume lifts a plugin's suspension and clears its recent violations.
+func (s *PluginSandbox) Resume(pluginName string) {
+	s.mu.Lock()
+	if v := s.violations[pluginName]; v != nil {
+		v.suspended = false
+		v.recent = nil
+	}
+	s.mu.Unlock()
+}
+
+// Forget removes everything the sandbox knows about a plugin.
+func (s *PluginSandbox) Forget(pluginName string) {
+	s.mu.Lock()
+	delete(s.limits, pluginName)
+	delete(s.violations, pluginName)
+	s.mu.Unlock()
+}
+
+// run invokes handler under pluginName's limits.
+//
+// A panic in the handler is re-raised on the calling goroutine, so callers
+// recover and count it as if the handler had been called directly.
+func (s *PluginSandbox) run(ctx context.Context, eventType, pluginName string, handler ContextEventHandler, data interface{}) error {
+	if s.Suspended(pluginName) {
+		return nil
+	}
+
+	limit := s.MaxHandlerDuration(pluginName)
+	if limit <= 0 {
+		return handler(ctx, data)
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	type outcome struct {
+		err       error
+		recovered interface{}
+	}
+	// Buffered so a handler finishing after the deadline never blocks
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{recovered: r}
+			}
+		}()
+		done <- outcome{err: handler(handlerCtx, data)}
+	}()
+
+	select {
+	case out := <-done:
+		if out.recovered != nil {
+			panic(out.recovered)
+		}
+		return out.err
+	case <-handlerCtx.Done():
+		// The caller giving up (EmitSyncCtx) is not the plugin's fault
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.recordViolation(ctx, pluginName, eventType, limit)
+		return fmt.Errorf("%w (%s)", ErrHandlerTimeout, limit)
+	}
+}
+
+// recordViolation counts a timeout and disables the plugin once it
+// exceeds its violation budget.
+func (s *PluginSandbox) recordViolation(ctx context.Context, pluginName, eventType string, limit time.Duration) {
+	pluginViolationsTotal.WithLabelValues(pluginName, ViolationTimeout).Inc()
+	log.Printf("[Plugin Sandbox] %sPlugin %s handler for %s exceeded %s", tracing.LogPrefix(ctx), pluginName, eventType, limit)
+
+	now := time.Now()
+
+	s.mu.Lock()
+	v := s.violations[pluginName]
+	if v == nil {
+		v = &pluginViolations{}
+		s.violations[pluginName] = v
+	}
+	v.total++
+
+	// Keep only the violations inside the window
+	recent := v.recent[:0]
+	for _, at := range v.recent {
+		if now.Sub(at) < violationWindow {
+			recent = append(recent, at)
+		}
+	}
+	v.recent = append(recent, now)
+
+	violated := !v.suspended && len(v.recent) >= maxViolations
+	if violated {
+		v.suspended = true
+	}
+	count := len(v.recent)
+	bus := s.events
+	disable := s.disable
+	onTimeout := s.onTimeout
+	s.mu.Unlock()
+
+	s.persistViolation(pluginName, eventType, limit, now)
+	if onTimeout != nil {
+		onTimeout(pluginName, TimeoutKindEvent)
+	}
+
+	if !violated {
+		return
+	}
+
+	log.Printf("[Plugin Sandbox] Disabling plugin %s after %d timeouts in %s", pluginName, count, violationWindow)
+	if disable != nil {
+		if err := disable(tracing.Detach(ctx), pluginName); err != nil {
+			log.Printf("[Plugin Sandbox] Failed to disable plugin %s: %v", pluginName, err)
+		}
+	}
+	if bus != nil {
+		bus.EmitWithContext(ctx, EventPluginViolatedTimeout, PluginViolationEvent{
+			Plugin:     pluginName,
+			Type:       ViolationTimeout,
+			EventType:  eventType,
+			Limit:      limit.String(),
+			Violations: count,
+			Window:     violationWindow.String(),
+		})
+	}
+}
+
+// persistViolation records a violation in the plugin_violations table in
+// the background.
+func (s *PluginSandbox) persistViolation(pluginName, eventType string, limit time.Duration, at time.Time) {
+	if s.db == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), violationWriteTimeout)
+		defer cancel()
+
+		if _, err := s.db.DB().ExecContext(ctx, `
+			INSERT INTO plugin_violations (plugin_name, violation_type, event_type, limit_ms, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, pluginName, ViolationTimeout, eventType, limit.Milliseconds(), at); err != nil {
+			log.Printf("[Plugin Sandbox] Failed to record violation for plugin %s: %v", pluginName, err)
+		}
+	}()
+}
+
+// parseHandlerDuration parses a maxHandlerDuration config value: a Go
+// duration string or a number of seconds.
+func parseHandlerDuration(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, err
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("duration must be positive, got %s", v)
+		}
+		return d, nil
+	case float64:
+		if v <= 0 {
+			return 0, fmt.Errorf("duration must be positive, got %v", v)
+		}
+		return time.Duration(v * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("expected a duration string or number of seconds, got %T", raw)
+	}
+}
@@ -0,0 +1,148 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox_TimesOutSlowHandler(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	sandbox := NewPluginSandbox(nil)
+	bus.SetSandbox(sandbox)
+	sandbox.SetMaxHandlerDuration("slow-plugin", 20*time.Millisecond)
+
+	cancelled := make(chan struct{})
+	bus.SubscribeCtx("sandbox.test", "slow-plugin", func(ctx context.Context, data interface{}) error {
+		<-ctx.Done()
+		close(cancelled)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	errs := bus.EmitSync("sandbox.test", nil)
+	require.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrHandlerTimeout))
+	assert.Equal(t, uint64(1), sandbox.ViolationCount("slow-plugin"))
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+}
+
+func TestSandbox_FastHandlerAndPanicsUnaffected(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	sandbox := NewPluginSandbox(nil)
+	bus.SetSandbox(sandbox)
+
+	bus.Subscribe("sandbox.fast", "fast-plugin", func(data interface{}) error {
+		return errors.New("failed")
+	})
+	bus.Subscribe("sandbox.fast", "panicking-plugin", func(data interface{}) error {
+		panic("boom")
+	})
+
+	errs := bus.EmitSync("sandbox.fast", nil)
+	require.Len(t, errs, 2)
+	assert.Zero(t, sandbox.ViolationCount("fast-plugin"))
+
+	for _, h := range bus.Metrics().Handlers {
+		if h.Plugin == "panicking-plugin" {
+			assert.Equal(t, uint64(1), h.Panics)
+		}
+	}
+}
+
+func TestSandbox_DisablesPluginAfterRepeatedViolations(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	sandbox := NewPluginSandbox(nil)
+	bus.SetSandbox(sandbox)
+	sandbox.SetMaxHandlerDuration("slow-plugin", 5*time.Millisecond)
+
+	var mu sync.Mutex
+	var disabled []string
+	sandbox.SetDisableFunc(func(ctx context.Context, pluginName string) error {
+		mu.Lock()
+		disabled = append(disabled, pluginName)
+		mu.Unlock()
+		return nil
+	})
+
+	violated := make(chan PluginViolationEvent, 1)
+	bus.Subscribe(EventPluginViolatedTimeout, "watcher", func(data interface{}) error {
+		violated <- data.(PluginViolationEvent)
+		return nil
+	})
+
+	var calls atomic.Int32
+	bus.Subscribe("sandbox.slow", "slow-plugin", func(data interface{}) error {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+
+	for i := 0; i < maxViolations; i++ {
+		bus.EmitSync("sandbox.slow", nil)
+	}
+
+	select {
+	case event := <-violated:
+		assert.Equal(t, "slow-plugin", event.Plugin)
+		assert.Equal(t, ViolationTimeout, event.Type)
+		assert.Equal(t, "sandbox.slow", event.EventType)
+		assert.Equal(t, maxViolations, event.Violations)
+	case <-time.After(time.Second):
+		t.Fatal("plugin.violated.timeout was not emitted")
+	}
+
+	mu.Lock()
+	assert.Equal(t, []string{"slow-plugin"}, disabled)
+	mu.Unlock()
+	assert.True(t, sandbox.Suspended("slow-plugin"))
+
+	// Suspended handlers are skipped until the plugin is resumed
+	assert.Empty(t, bus.EmitSync("sandbox.slow", nil))
+	assert.Equal(t, int32(maxViolations), calls.Load())
+
+	sandbox.Resume("slow-plugin")
+	assert.False(t, sandbox.Suspended("slow-plugin"))
+}
+
+func TestSandbox_CallerTimeoutIsNotAViolation(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	sandbox := NewPluginSandbox(nil)
+	bus.SetSandbox(sandbox)
+
+	bus.SubscribeCtx("sandbox.caller", "plugin", func(ctx context.Context, data interface{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	bus.EmitSyncCtx(ctx, "sandbox.caller", nil)
+
+	assert.Zero(t, sandbox.ViolationCount("plugin"))
+}
+
+func TestSandbox_Configure(t *testing.T) {
+	sandbox := NewPluginSandbox(nil)
+
+	sandbox.Configure("string", map[string]interface{}{"maxHandlerDuration": "10s"})
+	sandbox.Configure("seconds", map[string]interface{}{"maxHandlerDuration": 2.5})
+	sandbox.Configure("invalid", map[string]interface{}{"maxHandlerDuration": "soon"})
+	sandbox.Configure("unset", nil)
+
+	assert.Equal(t, 10*time.Second, sandbox.MaxHandlerDuration("string"))
+	assert.Equal(t, 2500*time.Millisecond, sandbox.MaxHandlerDuration("seconds"))
+	assert.Equal(t, defaultMaxHandlerDuration, sandbox.MaxHandlerDuration("invalid"))
+	assert.Equal(t, defaultMaxHandlerDuration, sandbox.MaxHandlerDuration("unset"))
+}
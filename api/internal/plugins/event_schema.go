@@ -0,0 +1,297 @@
+// Package plugins - event_schema.go
+//
+// This file implements typed event payloads and the event schema catalog.
+//
+// Handlers registered with Subscribe/On receive data as interface{} and
+// must type-assert it; a wrong assertion panics, the bus recovers, and the
+// event is lost for that plugin with only a log line to show for it.
+//
+// The platform declares the payload type of each event it emits with
+// RegisterEventSchema. For a registered event:
+//
+//   - Emit/EmitSync reject payloads of any other type (logged, not delivered)
+//   - Plugins can subscribe with On[T], which decodes the payload into T and
+//     reports a mismatch as a handler error instead of a panic
+//   - The schema is listed by EventSchemas, which backs the
+//     GET /api/v1/plugins/events/catalog endpoint for plugin authors
+//
+// Example:
+//
+//	bus.RegisterEventSchema("session.hibernated", "A session entered hibernation", events.SessionStateChange{})
+//
+//	plugins.On(ctx.Events, "session.hibernated", func(event *plugins.Event, change events.SessionStateChange) error {
+//	    log.Printf("%s at %s: %s", event.Type, event.Timestamp, change.SessionID)
+//	    return nil
+//	})
+//
+// Events without a registered schema are delivered exactly as before.
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSchemaDepth bounds how deeply payload shapes are described, so
+// recursive types cannot loop forever.
+const maxSchemaDepth = 6
+
+// ErrPayloadMismatch is returned when an event payload does not match the
+// type registered for the event.
+var ErrPayloadMismatch = errors.New("event payload does not match its registered type")
+
+// Event is the envelope typed handlers receive alongside the decoded payload.
+type Event struct {
+	// Type is the emitted event type, e.g. "session.hibernated".
+	Type string `json:"type"`
+
+	// Timestamp is when the event was emitted (or originally published,
+	// for replayed events).
+	Timestamp time.Time `json:"timestamp"`
+
+	// Payload is the JSON encoding of the event data.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EventSchema declares the payload of one event type.
+type EventSchema struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+
+	// PayloadType is the Go type of the payload, e.g. "events.SessionStateChange".
+	PayloadType string `json:"payloadType"`
+
+	// Payload describes the JSON shape of the payload: objects map field
+	// names to their shape and scalars are "string", "number", "boolean",
+	// "timestamp" or "any".
+	Payload interface{} `json:"payload"`
+
+	payloadType reflect.Type
+}
+
+// eventMetaKey is the context key under which deliveries carry eventMeta.
+type eventMetaKey struct{}
+
+// eventMeta identifies the emission a delivery belongs to.
+type eventMeta struct {
+	eventType string
+	emittedAt time.Time
+}
+
+// withEventMeta attaches the event type and emission time to ctx.
+func withEventMeta(ctx context.Context, eventType string, emittedAt time.Time) context.Context {
+	return context.WithValue(ctx, eventMetaKey{}, eventMeta{eventType: eventType, emittedAt: emittedAt})
+}
+
+// RegisterEventSchema declares the payload type of eventType.
+//
+// payload is a zero value of the payload type (a pointer to it is
+// equivalent); Emit accepts the type, a pointer to it, or JSON that decodes
+// into it. Registering an event again replaces its schema.
+func (bus *EventBus) RegisterEventSchema(eventType, description string, payload interface{}) {
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	schema := &EventSchema{
+		Type:        eventType,
+		Description: description,
+		PayloadType: "any",
+		Payload:     "any",
+		payloadType: t,
+	}
+	if t != nil {
+		schema.PayloadType = t.String()
+		schema.Payload = describeType(t, 0)
+	}
+
+	bus.mu.Lock()
+	bus.schemas[eventType] = schema
+	bus.mu.Unlock()
+}
+
+// EventSchemas returns the registered event schemas sorted by event type.
+func (bus *EventBus) EventSchemas() []EventSchema {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	schemas := make([]EventSchema, 0, len(bus.schemas))
+	for _, schema := range bus.schemas {
+		schemas = append(schemas, *schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Type < schemas[j].Type
+	})
+	return schemas
+}
+
+// validatePayload checks data against the schema registered for eventType.
+// Events without a schema, or with an untyped one, always pass.
+func (bus *EventBus) validatePayload(eventType string, data interface{}) error {
+	bus.mu.RLock()
+	schema := bus.schemas[eventType]
+	bus.mu.RUnlock()
+
+	if schema == nil || schema.payloadType == nil {
+		return nil
+	}
+
+	want := schema.payloadType
+	switch v := data.(type) {
+	case json.RawMessage:
+		return decodeInto(eventType, v, want)
+	case []byte:
+		return decodeInto(eventType, v, want)
+	}
+
+	got := reflect.TypeOf(data)
+	if got == want || (got != nil && got.Kind() == reflect.Ptr && got.Elem() == want) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s expects %s, got %T", ErrPayloadMismatch, eventType, want, data)
+}
+
+// decodeInto checks that raw decodes into a value of type t.
+func decodeInto(eventType string, raw []byte, t reflect.Type) error {
+	if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+		return fmt.Errorf("%w: %s expects %s: %v", ErrPayloadMismatch, eventType, t, err)
+	}
+	return nil
+}
+
+// On registers a typed event handler for a plugin (recorded in the audit
+// log when enabled).
+//
+// The payload is passed through when it already has type T (or *T);
+// otherwise its JSON encoding is decoded into T. If decoding fails the
+// handler is not called and the mismatch is reported as a handler error.
+// Replayed events are unwrapped, so handlers see the original payload.
+// Returns ErrEventPermissionDenied without events.listen.{eventType}.
+func On[T any](pe *PluginEvents, eventType string, handler func(event *Event, payload T) error) error {
+	return pe.OnCtx(eventType, func(ctx context.Context, data interface{}) error {
+		event, payload, err := decodeEvent[T](ctx, eventType, data)
+		if err != nil {
+			return err
+		}
+		return handler(event, payload)
+	})
+}
+
+// decodeEvent builds the envelope for one delivery and decodes its payload
+// into T.
+func decodeEvent[T any](ctx context.Context, eventType string, data interface{}) (*Event, T, error) {
+	var payload T
+	event := &Event{Type: eventType, Timestamp: time.Now()}
+	if meta, ok := ctx.Value(eventMetaKey{}).(eventMeta); ok {
+		event.Type = meta.eventType
+		event.Timestamp = meta.emittedAt
+	}
+
+	typed := false
+	switch v := data.(type) {
+	case T:
+		payload, typed = v, true
+	case *T:
+		if v != nil {
+			payload, typed = *v, true
+		}
+	case *ReplayedEvent:
+		event.Type = v.EventType
+		event.Timestamp = v.PublishedAt
+		event.Payload = v.Data
+	case json.RawMessage:
+		event.Payload = v
+	}
+
+	if typed || event.Payload == nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, payload, fmt.Errorf("event %s: payload is not JSON-encodable: %w", event.Type, err)
+		}
+		event.Payload = raw
+	}
+	if typed {
+		return event, payload, nil
+	}
+
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, payload, fmt.Errorf("%w: %s payload does not decode into %T: %v", ErrPayloadMismatch, event.Type, payload, err)
+	}
+	return event, payload, nil
+}
+
+// describeType returns the JSON shape of t for the event catalog.
+func describeType(t reflect.Type, depth int) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if depth > maxSchemaDepth {
+		return "any"
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return "timestamp"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return []interface{}{describeType(t.Elem(), depth+1)}
+	case reflect.Map:
+		return map[string]interface{}{"*": describeType(t.Elem(), depth+1)}
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		describeFields(t, depth, fields)
+		return fields
+	default:
+		return "any"
+	}
+}
+
+// describeFields adds the JSON fields of struct type t to fields, flattening
+// embedded structs the way encoding/json does.
+func describeFields(t reflect.Type, depth int, fields map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				describeFields(embedded, depth, fields)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = describeType(field.Type, depth+1)
+	}
+}
@@ -0,0 +1,111 @@
+package plugins
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSessionEvent struct {
+	SessionID string    `json:"session_id"`
+	Tags      []string  `json:"tags,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	internal  string
+}
+
+func TestRegisterEventSchema_Catalog(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.RegisterEventSchema("session.woken", "A session woke up", &testSessionEvent{})
+	bus.RegisterEventSchema("custom.any", "Anything goes", nil)
+
+	schemas := bus.EventSchemas()
+	require.Len(t, schemas, 2)
+	assert.Equal(t, "custom.any", schemas[0].Type)
+	assert.Equal(t, "any", schemas[0].Payload)
+
+	assert.Equal(t, "session.woken", schemas[1].Type)
+	assert.Equal(t, "plugins.testSessionEvent", schemas[1].PayloadType)
+	assert.Equal(t, map[string]interface{}{
+		"session_id": "string",
+		"tags":       []interface{}{"string"},
+		"timestamp":  "timestamp",
+	}, schemas[1].Payload)
+}
+
+func TestEmit_RejectsMismatchedPayload(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	bus.RegisterEventSchema("session.woken", "", testSessionEvent{})
+
+	calls := 0
+	bus.Subscribe("session.woken", "analytics", func(data interface{}) error {
+		calls++
+		return nil
+	})
+
+	errs := bus.EmitSync("session.woken", map[string]string{"session_id": "sess-1"})
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrPayloadMismatch)
+
+	assert.Empty(t, bus.EmitSync("session.woken", &testSessionEvent{SessionID: "sess-1"}))
+	assert.Empty(t, bus.EmitSync("session.woken", json.RawMessage(`{"session_id":"sess-1"}`)))
+	assert.NotEmpty(t, bus.EmitSync("session.woken", json.RawMessage(`{"session_id":42}`)))
+	assert.Equal(t, 2, calls)
+}
+
+func TestOn_DecodesTypedPayload(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	events := NewPluginEvents(bus, "analytics")
+
+	var got []testSessionEvent
+	var envelopes []*Event
+	On(events, "session.woken", func(event *Event, payload testSessionEvent) error {
+		got = append(got, payload)
+		envelopes = append(envelopes, event)
+		return nil
+	})
+
+	before := time.Now()
+	require.Empty(t, bus.EmitSync("session.woken", &testSessionEvent{SessionID: "typed"}))
+	require.Empty(t, bus.EmitSync("session.woken", map[string]interface{}{"session_id": "from-map"}))
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "typed", got[0].SessionID)
+	assert.Equal(t, "from-map", got[1].SessionID)
+	assert.Equal(t, "session.woken", envelopes[0].Type)
+	assert.False(t, envelopes[0].Timestamp.Before(before))
+	assert.JSONEq(t, `{"session_id":"from-map"}`, string(envelopes[1].Payload))
+}
+
+func TestOn_MismatchIsHandlerError(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	events := NewPluginEvents(bus, "analytics")
+
+	called := false
+	On(events, "session.woken", func(event *Event, payload testSessionEvent) error {
+		called = true
+		return nil
+	})
+
+	errs := bus.EmitSync("session.woken", "not a session")
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrPayloadMismatch)
+	assert.False(t, called)
+}
+
+func TestOn_UnwrapsReplayedEvent(t *testing.T) {
+	published := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	event, payload, err := decodeEvent[testSessionEvent](t.Context(), "session.woken", &ReplayedEvent{
+		Replayed:    true,
+		ID:          7,
+		EventType:   "session.woken",
+		PublishedAt: published,
+		Data:        json.RawMessage(`{"session_id":"replayed"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "replayed", payload.SessionID)
+	assert.Equal(t, published, event.Timestamp)
+}
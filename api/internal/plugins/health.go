@@ -0,0 +1,378 @@
+// Package plugins - health.go
+//
+// This file implements plugin health reporting.
+//
+// The runtime records a status for every plugin it loads, stored in
+// installed_plugins.status with the error behind it in last_error:
+//
+//   - loaded: the plugin loaded and is healthy
+//   - failed: loading failed (handler not found, or OnLoad returned an error)
+//   - degraded: the plugin is loaded, but most of its recent event handler
+//     invocations failed, its handlers repeatedly timed out (see
+//     timeouts.go), or its health check returned an error
+//
+// Every PluginHealthInterval the runtime looks at each loaded plugin's
+// handler invocations since the previous check (see event_metrics.go) and
+// runs its health check, if it registered one:
+//
+//	ctx.Health.Register(func(ctx context.Context) error {
+//	    return client.Ping(ctx)
+//	})
+//
+// A degraded plugin returns to loaded once a check finds it healthy again.
+// Statuses are written to the database only when they change, and a
+// "plugin.unhealthy" event is emitted when a loaded plugin becomes degraded.
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+// Plugin statuses.
+const (
+	PluginStatusLoaded   = "loaded"
+	PluginStatusDegraded = "degraded"
+	PluginStatusFailed   = "failed"
+
+	// PluginStatusUnknown is reported for plugins the runtime has not tried
+	// to load.
+	PluginStatusUnknown = "unknown"
+)
+
+const (
+	// PluginHealthInterval is how often loaded plugins are checked
+	PluginHealthInterval = time.Minute
+
+	// healthCheckTimeout bounds one plugin's health check
+	healthCheckTimeout = 10 * time.Second
+
+	// healthWriteTimeout bounds a single installed_plugins status UPDATE
+	healthWriteTimeout = 5 * time.Second
+
+	// degradedErrorRate is the share of failed handler invocations since
+	// the previous check at which a plugin is degraded
+	degradedErrorRate = 0.5
+
+	// degradedMinInvocations is the fewest invocations since the previous
+	// check for the error rate to count
+	degradedMinInvocations = 5
+)
+
+// HealthCheckFunc reports whether a plugin is healthy. A non-nil error marks
+// the plugin degraded.
+type HealthCheckFunc func(ctx context.Context) error
+
+// PluginHealth lets a plugin register a health check.
+type PluginHealth struct {
+	mu    sync.Mutex
+	check HealthCheckFunc
+}
+
+// NewPluginHealth creates a plugin's health accessor with no check
+// registered.
+func NewPluginHealth() *PluginHealth {
+	return &PluginHealth{}
+}
+
+// Register sets the plugin's health check, replacing any previous one.
+func (ph *PluginHealth) Register(check HealthCheckFunc) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	ph.check = check
+}
+
+// registered reports whether the plugin registered a health check.
+func (ph *PluginHealth) registered() bool {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	return ph.check != nil
+}
+
+// run calls the plugin's health check, converting a panic into an error.
+// It returns nil if no check is registered.
+func (ph *PluginHealth) run(ctx context.Context) (err error) {
+	ph.mu.Lock()
+	check := ph.check
+	ph.mu.Unlock()
+	if check == nil {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("health check panicked: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return check(ctx)
+}
+
+// PluginHealthReport is the runtime status of one plugin.
+type PluginHealthReport struct {
+	Plugin    string `json:"plugin"`
+	Status    string `json:"status"`
+	LastError string `json:"lastError,omitempty"`
+
+	// Loaded reports whether the plugin is currently loaded.
+	Loaded bool `json:"loaded"`
+
+	// HealthCheck reports whether the plugin registered a health check.
+	HealthCheck bool `json:"healthCheck"`
+
+	// CheckedAt is when the status was last determined.
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Handler invocations between the last two checks.
+	RecentInvocations uint64  `json:"recentInvocations"`
+	RecentErrors      uint64  `json:"recentErrors"`
+	RecentErrorRate   float64 `json:"recentErrorRate"`
+
+	// Timeouts counts handler timeouts since the plugin was loaded;
+	// RecentTimeouts those within the timeout window.
+	Timeouts       uint64 `json:"timeouts"`
+	RecentTimeouts int    `json:"recentTimeouts"`
+}
+
+// handlerTotals counts one plugin's handler invocations across event types.
+type handlerTotals struct {
+	invocations uint64
+	errors      uint64
+}
+
+// healthMonitor tracks plugin statuses and persists them.
+type healthMonitor struct {
+	db  *db.Database
+	bus *EventBus
+
+	mu      sync.Mutex
+	reports map[string]*PluginHealthReport
+
+	// totals holds each plugin's handler totals at its previous check
+	totals map[string]handlerTotals
+
+	// timeouts tracks each plugin's handler timeouts since it was loaded
+	timeouts map[string]*timeoutRecord
+}
+
+// newHealthMonitor creates a monitor that reads handler metrics from bus and
+// writes statuses to database's installed_plugins table. database may be
+// nil, in which case statuses are only kept in memory.
+func newHealthMonitor(database *db.Database, bus *EventBus) *healthMonitor {
+	return &healthMonitor{
+		db:       database,
+		bus:      bus,
+		reports:  make(map[string]*PluginHealthReport),
+		totals:   make(map[string]handlerTotals),
+		timeouts: make(map[string]*timeoutRecord),
+	}
+}
+
+// handlerTotals sums each plugin's handler invocations across event types.
+func (m *healthMonitor) handlerTotals() map[string]handlerTotals {
+	totals := make(map[string]handlerTotals)
+	for _, h := range m.bus.Metrics().Handlers {
+		t := totals[h.Plugin]
+		t.invocations += h.Invocations
+		t.errors += h.Errors
+		totals[h.Plugin] = t
+	}
+	return totals
+}
+
+// loaded records the outcome of loading a plugin. loadErr is nil if the
+// plugin loaded.
+func (m *healthMonitor) loaded(name string, loadErr error) {
+	status := PluginStatusLoaded
+	if loadErr != nil {
+		status = PluginStatusFailed
+	}
+
+	// Errors from before this load do not count against it
+	baseline := m.handlerTotals()[name]
+
+	m.mu.Lock()
+	m.totals[name] = baseline
+	delete(m.timeouts, name)
+	m.reports[name] = &PluginHealthReport{Plugin: name, Loaded: loadErr == nil}
+	m.mu.Unlock()
+
+	m.setStatus(name, status, loadErr, nil)
+}
+
+// unloaded marks a plugin as no longer loaded, keeping its last status.
+func (m *healthMonitor) unloaded(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if report, ok := m.reports[name]; ok {
+		report.Loaded = false
+	}
+}
+
+// check determines the status of a loaded plugin from its recent handler
+// invocations and its health check.
+func (m *healthMonitor) check(ctx context.Context, name string, health *PluginHealth, totals map[string]handlerTotals) {
+	current := totals[name]
+
+	m.mu.Lock()
+	previous := m.totals[name]
+	m.totals[name] = current
+	timeouts := m.recentTimeouts(name, time.Now())
+	m.mu.Unlock()
+
+	// A plugin reloaded after totals were read has a newer baseline
+	var recent handlerTotals
+	if current.invocations >= previous.invocations && current.errors >= previous.errors {
+		recent = handlerTotals{
+			invocations: current.invocations - previous.invocations,
+			errors:      current.errors - previous.errors,
+		}
+	}
+
+	status := PluginStatusLoaded
+	var reason error
+	if err := health.run(ctx); err != nil {
+		status = PluginStatusDegraded
+		reason = fmt.Errorf("health check failed: %w", err)
+	} else if timeouts >= unhealthyTimeouts {
+		status = PluginStatusDegraded
+		reason = timeoutReason(timeouts)
+	} else if recent.invocations >= degradedMinInvocations &&
+		float64(recent.errors)/float64(recent.invocations) >= degradedErrorRate {
+		status = PluginStatusDegraded
+		reason = fmt.Errorf("%d of %d event handler invocations failed in the last %s",
+			recent.errors, recent.invocations, PluginHealthInterval)
+	}
+
+	m.setStatus(name, status, reason, func(report *PluginHealthReport) {
+		report.HealthCheck = health.registered()
+		report.RecentInvocations = recent.invocations
+		report.RecentErrors = recent.errors
+		report.RecentErrorRate = 0
+		if recent.invocations > 0 {
+			report.RecentErrorRate = float64(recent.errors) / float64(recent.invocations)
+		}
+	})
+}
+
+// setStatus updates a plugin's report and persists its status if it
+// changed.
+func (m *healthMonitor) setStatus(name, status string, reason error, update func(report *PluginHealthReport)) {
+	lastError := ""
+	if reason != nil {
+		lastError = reason.Error()
+	}
+
+	m.mu.Lock()
+	report, ok := m.reports[name]
+	if !ok {
+		report = &PluginHealthReport{Plugin: name, Loaded: true}
+		m.reports[name] = report
+	}
+	changed := report.Status != status || report.LastError != lastError
+	unhealthy := status == PluginStatusDegraded && report.Status != PluginStatusDegraded && report.Loaded
+	if status != report.Status && report.Status != "" {
+		log.Printf("[Plugin Health] Plugin %s is now %s (was %s): %s", name, status, report.Status, lastError)
+	}
+	report.Status = status
+	report.LastError = lastError
+	report.CheckedAt = time.Now()
+	if record := m.timeouts[name]; record != nil {
+		report.Timeouts = record.total
+		report.RecentTimeouts = len(record.recent)
+	} else {
+		report.Timeouts, report.RecentTimeouts = 0, 0
+	}
+	if update != nil {
+		update(report)
+	}
+	m.mu.Unlock()
+
+	if changed {
+		m.persist(name, status, lastError)
+	}
+	if unhealthy {
+		m.bus.Emit(EventPluginUnhealthy, PluginUnhealthyEvent{
+			Plugin: name,
+			Status: status,
+			Reason: lastError,
+		})
+	}
+}
+
+// persist writes a plugin's status to installed_plugins.
+func (m *healthMonitor) persist(name, status, lastError string) {
+	if m.db == nil {
+		return
+	}
+
+	var errValue interface{}
+	if lastError != "" {
+		errValue = lastError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthWriteTimeout)
+	defer cancel()
+
+	if _, err := m.db.DB().ExecContext(ctx, `
+		UPDATE installed_plugins SET status = $1, last_error = $2, status_updated_at = NOW() WHERE name = $3
+	`, status, errValue, name); err != nil {
+		log.Printf("[Plugin Health] Failed to record status of plugin %s: %v", name, err)
+	}
+}
+
+// report returns a copy of a plugin's report.
+func (m *healthMonitor) report(name string) (PluginHealthReport, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report, ok := m.reports[name]
+	if !ok {
+		return PluginHealthReport{}, false
+	}
+	return *report, true
+}
+
+// CheckPluginHealth checks every loaded plugin now. The runtime calls it
+// every PluginHealthInterval after Start.
+func (r *RuntimeV2) CheckPluginHealth(ctx context.Context) {
+	r.pluginsMux.RLock()
+	checks := make(map[string]*PluginHealth, len(r.plugins))
+	for name, plugin := range r.plugins {
+		checks[name] = plugin.Instance.Context.Health
+	}
+	r.pluginsMux.RUnlock()
+
+	totals := r.health.handlerTotals()
+	for name, health := range checks {
+		r.health.check(ctx, name, health, totals)
+	}
+}
+
+// PluginHealth returns the status of a plugin the runtime has tried to
+// load.
+func (r *RuntimeV2) PluginHealth(name string) (PluginHealthReport, bool) {
+	return r.health.report(name)
+}
+
+// runHealthChecks calls CheckPluginHealth every PluginHealthInterval until
+// ctx is cancelled.
+func (r *RuntimeV2) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(PluginHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.CheckPluginHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthMonitor_LoadOutcome(t *testing.T) {
+	monitor := newHealthMonitor(nil, NewEventBus(EventBusConfig{}))
+
+	monitor.loaded("broken", errors.New("plugin OnLoad failed: no token"))
+	report, ok := monitor.report("broken")
+	require.True(t, ok)
+	assert.Equal(t, PluginStatusFailed, report.Status)
+	assert.Equal(t, "plugin OnLoad failed: no token", report.LastError)
+	assert.False(t, report.Loaded)
+
+	monitor.loaded("broken", nil)
+	report, _ = monitor.report("broken")
+	assert.Equal(t, PluginStatusLoaded, report.Status)
+	assert.Empty(t, report.LastError)
+	assert.True(t, report.Loaded)
+
+	_, ok = monitor.report("never-loaded")
+	assert.False(t, ok)
+}
+
+func TestHealthMonitor_DegradedOnRecentHandlerErrors(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	failing := true
+	bus.Subscribe("health.test", "flaky", func(data interface{}) error {
+		if failing {
+			return errors.New("upstream unavailable")
+		}
+		return nil
+	})
+
+	// Errors before the plugin loaded do not count
+	for i := 0; i < degradedMinInvocations; i++ {
+		bus.EmitSync("health.test", i)
+	}
+	monitor := newHealthMonitor(nil, bus)
+	monitor.loaded("flaky", nil)
+	health := NewPluginHealth()
+
+	monitor.check(context.Background(), "flaky", health, monitor.handlerTotals())
+	report, _ := monitor.report("flaky")
+	assert.Equal(t, PluginStatusLoaded, report.Status)
+
+	for i := 0; i < degradedMinInvocations; i++ {
+		bus.EmitSync("health.test", i)
+	}
+	monitor.check(context.Background(), "flaky", health, monitor.handlerTotals())
+	report, _ = monitor.report("flaky")
+	assert.Equal(t, PluginStatusDegraded, report.Status)
+	assert.Contains(t, report.LastError, "5 of 5 event handler invocations failed")
+	assert.Equal(t, 1.0, report.RecentErrorRate)
+
+	// Recovers once handlers succeed again
+	failing = false
+	for i := 0; i < degradedMinInvocations; i++ {
+		bus.EmitSync("health.test", i)
+	}
+	monitor.check(context.Background(), "flaky", health, monitor.handlerTotals())
+	report, _ = monitor.report("flaky")
+	assert.Equal(t, PluginStatusLoaded, report.Status)
+	assert.Empty(t, report.LastError)
+}
+
+func TestHealthMonitor_HealthCheck(t *testing.T) {
+	monitor := newHealthMonitor(nil, NewEventBus(EventBusConfig{}))
+	monitor.loaded("pinger", nil)
+
+	health := NewPluginHealth()
+	health.Register(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	monitor.check(context.Background(), "pinger", health, monitor.handlerTotals())
+
+	report, _ := monitor.report("pinger")
+	assert.Equal(t, PluginStatusDegraded, report.Status)
+	assert.Equal(t, "health check failed: connection refused", report.LastError)
+	assert.True(t, report.HealthCheck)
+
+	health.Register(func(ctx context.Context) error { panic("boom") })
+	monitor.check(context.Background(), "pinger", health, monitor.handlerTotals())
+	report, _ = monitor.report("pinger")
+	assert.Equal(t, PluginStatusDegraded, report.Status)
+	assert.Contains(t, report.LastError, "panicked")
+}
@@ -181,6 +181,10 @@ type PluginMarketplace struct {
 	cacheTTL         time.Duration
 	lastSync         time.Time
 	availablePlugins map[string]*MarketplacePlugin
+
+	// secrets holds plugin secrets deleted on uninstall; nil until
+	// SetSecretStore is called
+	secrets SecretStore
 }
 
 // MarketplacePlugin represents a plugin available in the marketplace.
@@ -269,6 +273,11 @@ func NewPluginMarketplace(database *db.Database, repositoryURL, pluginDir string
 	}
 }
 
+// SetSecretStore sets where uninstalled plugins' secrets are deleted from.
+func (m *PluginMarketplace) SetSecretStore(store SecretStore) {
+	m.secrets = store
+}
+
 // SyncCatalog syncs the plugin catalog from the remote repository.
 //
 // This method fetches the latest catalog.json from the configured repository
@@ -579,7 +588,8 @@ func (m *PluginMarketplace) InstallPlugin(ctx context.Context, name string, conf
 //
 // **Plugin Lifecycle State After Uninstall**:
 //   - Runtime: Plugin remains loaded in memory until restart
-//   - Database: installed_plugins row deleted
+//   - Database: installed_plugins row and plugin_storage keys deleted
+//   - Kubernetes: plugin-{name}-secrets Secret deleted
 //   - Filesystem: /plugins/{name}/ directory removed
 //   - Catalog: Plugin still visible in marketplace (can reinstall)
 //
@@ -607,6 +617,18 @@ func (m *PluginMarketplace) UninstallPlugin(ctx context.Context, name string) er
 		return fmt.Errorf("failed to remove from database: %w", err)
 	}
 
+	// Drop the plugin's key-value storage
+	if err := NewPluginStorage(m.db, name).Clear(); err != nil {
+		log.Printf("[Plugin Marketplace] Warning: Failed to clear plugin storage: %v", err)
+	}
+
+	// Delete the plugin's secrets
+	if m.secrets != nil {
+		if err := m.secrets.DeleteSecret(ctx, PluginSecretName(name)); err != nil {
+			log.Printf("[Plugin Marketplace] Warning: Failed to delete plugin secrets: %v", err)
+		}
+	}
+
 	// Remove plugin files
 	pluginPath := filepath.Join(m.pluginDir, name)
 	if err := os.RemoveAll(pluginPath); err != nil {
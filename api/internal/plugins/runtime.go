@@ -240,6 +240,9 @@ type Runtime struct {
 	// Uses robfig/cron/v3 for flexible scheduling with standard cron syntax.
 	scheduler *cron.Cron
 
+	// tasks tracks which plugin owns each job on scheduler.
+	tasks *TaskRegistry
+
 	// apiRegistry tracks REST API routes registered by plugins.
 	// Plugin routes are prefixed with /api/plugins/{name}/ for namespacing.
 	apiRegistry *APIRegistry
@@ -316,6 +319,10 @@ type LoadedPlugin struct {
 	// IsBuiltin indicates whether the plugin is bundled with StreamSpace.
 	// Builtin plugins cannot be uninstalled and may have elevated permissions.
 	IsBuiltin bool
+
+	// EventPermissions are the events the plugin may listen to and emit,
+	// granted by the manifest's events.* permissions (see event_permissions.go).
+	EventPermissions EventPermissions
 }
 
 // PluginHandler is the interface that all plugins must implement.
@@ -337,14 +344,22 @@ type LoadedPlugin struct {
 //   - Flush buffered data, save state
 //   - Errors are logged but unload continues (best-effort cleanup)
 //
-// **OnEnable(ctx)**: Called when plugin is enabled (future use)
+// **OnEnable(ctx)**: Called when an admin enables the plugin
 //   - Resume event processing
 //   - Start background workers
 //
-// **OnDisable(ctx)**: Called when plugin is disabled (future use)
+// **OnDisable(ctx)**: Called when an admin disables the plugin
 //   - Pause event processing
 //   - Stop background workers
 //
+// **OnUpdate(ctx, oldVersion, newVersion)**: Called when an admin updates the
+// installed plugin (version or configuration)
+//   - Migrate plugin data from oldVersion's format
+//   - Re-read configuration
+//
+// Enable, disable and update hooks run after the change is saved; errors are
+// logged but do not undo it.
+//
 // # Event Hooks
 //
 // Event hooks are optional - plugins can implement only the events they need.
@@ -413,6 +428,7 @@ type PluginHandler interface {
 	OnUnload(ctx *PluginContext) error
 	OnEnable(ctx *PluginContext) error
 	OnDisable(ctx *PluginContext) error
+	OnUpdate(ctx *PluginContext, oldVersion, newVersion string) error
 
 	// Event handlers (optional)
 	OnSessionCreated(ctx *PluginContext, session interface{}) error
@@ -501,6 +517,15 @@ type PluginInstance struct {
 //   - JSON serialization of values
 //   - Backed by database (persistent across restarts)
 //
+// **Secrets**: Read-only access to the plugin's credentials
+//   - Stored in the Kubernetes Secret plugin-{name}-secrets
+//   - Written by admins via PUT /api/plugins/:id/secrets
+//   - Never stored in the plugin's config
+//
+// **Health**: Optional health check polled by the runtime
+//   - Registered with ctx.Health.Register(func(ctx) error)
+//   - A failing check marks the plugin degraded (see health.go)
+//
 // **Logger**: Structured logging with plugin prefix
 //   - Automatic log level filtering (debug, info, warn, error)
 //   - Contextual fields for correlation
@@ -566,6 +591,8 @@ type PluginContext struct {
 	API       *PluginAPI
 	UI        *PluginUI
 	Storage   *PluginStorage
+	Secrets   *PluginSecrets
+	Health    *PluginHealth
 	Logger    *PluginLogger
 	Scheduler *PluginScheduler
 
@@ -575,11 +602,13 @@ type PluginContext struct {
 
 // NewRuntime creates a new plugin runtime
 func NewRuntime(database *db.Database) *Runtime {
+	scheduler := cron.New()
 	return &Runtime{
 		db:          database,
 		plugins:     make(map[string]*LoadedPlugin),
-		eventBus:    NewEventBus(),
-		scheduler:   cron.New(),
+		eventBus:    NewEventBus(EventBusConfig{}),
+		scheduler:   scheduler,
+		tasks:       NewTaskRegistry(scheduler, database),
 		apiRegistry: NewAPIRegistry(),
 		uiRegistry:  NewUIRegistry(),
 		discovery:   NewPluginDiscovery(),
@@ -806,12 +835,15 @@ func (r *Runtime) LoadPlugin(ctx context.Context, name, version string, config m
 
 	// Initialize plugin components
 	pluginCtx.Database = NewPluginDatabase(r.db, name)
-	pluginCtx.Events = NewPluginEvents(r.eventBus, name)
+	eventPermissions := NewEventPermissions(name, manifest.Permissions)
+	pluginCtx.Events = NewPluginEventsWithPermissions(r.eventBus, name, eventPermissions)
 	pluginCtx.API = NewPluginAPI(r.apiRegistry, name)
 	pluginCtx.UI = NewPluginUI(r.uiRegistry, name)
 	pluginCtx.Storage = NewPluginStorage(r.db, name)
+	pluginCtx.Secrets = NewPluginSecrets(nil, name)
+	pluginCtx.Health = NewPluginHealth()
 	pluginCtx.Logger = NewPluginLogger(name)
-	pluginCtx.Scheduler = NewPluginScheduler(r.scheduler, name)
+	pluginCtx.Scheduler = NewPluginScheduler(r.tasks, name)
 
 	// Create plugin instance
 	instance := &PluginInstance{
@@ -838,6 +870,8 @@ func (r *Runtime) LoadPlugin(ctx context.Context, name, version string, config m
 		Handler:  handler,
 		Instance: instance,
 		LoadedAt: time.Now(),
+
+		EventPermissions: eventPermissions,
 	}
 
 	// Call OnLoad hook
@@ -875,7 +909,7 @@ func (r *Runtime) unloadPluginLocked(ctx context.Context, name string) error {
 	}
 
 	// Cleanup plugin resources
-	plugin.Instance.Scheduler.RemoveAll()
+	r.tasks.UnregisterAll(name)
 	r.apiRegistry.UnregisterAll(name)
 	r.uiRegistry.UnregisterAll(name)
 	r.eventBus.UnsubscribeAll(name)
@@ -154,6 +154,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -161,7 +162,9 @@ import (
 
 	"github.com/robfig/cron/v3"
 	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/streamspace/streamspace/api/internal/events"
 	"github.com/streamspace/streamspace/api/internal/models"
+	reposync "github.com/streamspace/streamspace/api/internal/sync"
 )
 
 // RuntimeV2 manages the lifecycle and execution of plugins with automatic discovery.
@@ -201,6 +204,10 @@ type RuntimeV2 struct {
 	// Plugins can schedule jobs via ctx.Scheduler.Schedule(spec, func).
 	scheduler *cron.Cron
 
+	// tasks tracks which plugin owns each job on scheduler and the outcome
+	// of their last runs (see task_registry.go).
+	tasks *TaskRegistry
+
 	// apiRegistry is the centralized HTTP API endpoint registry.
 	// Plugins register endpoints via ctx.API.RegisterEndpoint(opts).
 	apiRegistry *APIRegistry
@@ -209,6 +216,24 @@ type RuntimeV2 struct {
 	// Plugins register UI components via ctx.UI.RegisterWidget/Page/etc.
 	uiRegistry *UIRegistry
 
+	// sandbox bounds how long plugin event handlers may run and disables
+	// plugins that repeatedly exceed their limit (see event_sandbox.go).
+	sandbox *PluginSandbox
+
+	// secrets backs each plugin's ctx.Secrets (see secrets.go); nil until
+	// SetSecretStore is called.
+	secrets SecretStore
+
+	// webhooks delivers the events of webhook-type plugins, which have no
+	// Go handler of their own (see webhook_dispatcher.go).
+	webhooks *WebhookDispatcher
+
+	// health records plugin statuses and polls loaded plugins every
+	// PluginHealthInterval after Start (see health.go).
+	health     *healthMonitor
+	stopHealth context.CancelFunc
+	healthWG   sync.WaitGroup
+
 	// autoStart controls whether plugins are auto-loaded on Start().
 	// If true: Loads all enabled plugins from database on startup.
 	// If false: Plugins must be loaded manually via LoadPlugin API.
@@ -236,16 +261,32 @@ type RuntimeV2 struct {
 //
 // Thread Safety: Constructor is not thread-safe. Do not call concurrently.
 func NewRuntimeV2(database *db.Database, pluginDirs ...string) *RuntimeV2 {
-	return &RuntimeV2{
+	eventBus := NewEventBusWithPersistence(NewEventBusWithAudit(NewEventBus(EventBusConfig{}), database), database)
+	apiRegistry := NewAPIRegistry()
+	apiRegistry.SetEventBus(eventBus)
+	sandbox := NewPluginSandbox(database)
+	eventBus.SetSandbox(sandbox)
+
+	scheduler := cron.New()
+
+	runtime := &RuntimeV2{
 		db:          database,
 		discovery:   NewPluginDiscovery(pluginDirs...),
 		plugins:     make(map[string]*LoadedPlugin),
-		eventBus:    NewEventBus(),
-		scheduler:   cron.New(),
-		apiRegistry: NewAPIRegistry(),
+		eventBus:    eventBus,
+		scheduler:   scheduler,
+		tasks:       NewTaskRegistry(scheduler, database),
+		apiRegistry: apiRegistry,
 		uiRegistry:  NewUIRegistry(),
+		sandbox:     sandbox,
+		webhooks:    NewWebhookDispatcher(database),
+		health:      newHealthMonitor(database, eventBus),
 		autoStart:   true,
 	}
+	sandbox.SetDisableFunc(runtime.disableViolatingPlugin)
+	sandbox.SetTimeoutFunc(runtime.health.recordTimeout)
+	apiRegistry.SetTimeoutFunc(runtime.health.recordTimeout)
+	return runtime
 }
 
 // SetAutoStart enables/disables automatic plugin loading on Start().
@@ -278,6 +319,14 @@ func (r *RuntimeV2) SetAutoStart(enabled bool) {
 	r.autoStart = enabled
 }
 
+// SetSecretStore sets where plugins read their secrets from. Without one,
+// ctx.Secrets.Get fails with ErrSecretsUnavailable.
+//
+// Thread Safety: Not thread-safe. Call before Start().
+func (r *RuntimeV2) SetSecretStore(store SecretStore) {
+	r.secrets = store
+}
+
 // RegisterBuiltinPlugin registers a built-in plugin for automatic discovery.
 //
 // Built-in plugins are compiled into the API binary and don't require
@@ -318,8 +367,9 @@ func (r *RuntimeV2) RegisterBuiltinPlugin(name string, factory PluginFactory) {
 //
 // Startup sequence:
 //  1. Start the cron scheduler (for plugin scheduled jobs)
-//  2. Discover all available plugins (filesystem + built-in)
-//  3. If auto-start is enabled: Load all enabled plugins from database
+//  2. Start polling plugin health every PluginHealthInterval
+//  3. Discover all available plugins (filesystem + built-in)
+//  4. If auto-start is enabled: Load all enabled plugins from database
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -354,6 +404,15 @@ func (r *RuntimeV2) Start(ctx context.Context) error {
 	// Start scheduler
 	r.scheduler.Start()
 
+	// Start polling plugin health
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	r.stopHealth = stopHealth
+	r.healthWG.Add(1)
+	go func() {
+		defer r.healthWG.Done()
+		r.runHealthChecks(healthCtx)
+	}()
+
 	// Discover all available plugins
 	availablePlugins, err := r.discovery.DiscoverAll()
 	if err != nil {
@@ -386,23 +445,31 @@ func (r *RuntimeV2) Start(ctx context.Context) error {
 //   - For each plugin, also loads manifest from catalog_plugins table
 //   - Handles missing catalog gracefully (plugin loads without manifest)
 //
+// Load Order:
+//   - Manifest dependencies are resolved with sync.ResolveDependencies so
+//     every plugin's OnLoad runs after the OnLoad of its dependencies
+//   - Plugins with a missing or version-incompatible dependency are not
+//     loaded (nor is anything that depends on them)
+//   - A dependency cycle prevents all enabled plugins from loading
+//
 // Parameters:
 //   - ctx: Context for query cancellation
 //
 // Returns:
 //   - Number of successfully loaded plugins
-//   - Error only on critical database failures
+//   - Error on critical database failures or circular dependencies
 //
 // Error Handling:
 //   - Individual plugin loading errors are logged but don't fail the method
 //   - Config parsing errors result in empty config (plugin still loads)
 //   - Missing manifest is logged as warning (plugin still loads)
+//   - Blocked dependencies are logged as warnings
 //
 // Thread Safety: Not thread-safe. Called by Start() which manages locking.
 func (r *RuntimeV2) loadEnabledPlugins(ctx context.Context) (int, error) {
 	// Query enabled plugins from database
 	rows, err := r.db.DB().QueryContext(ctx, `
-		SELECT id, name, version, enabled, config, catalog_plugin_id
+		SELECT id, name, version, enabled, config, catalog_plugin_id, manifest
 		FROM installed_plugins
 		WHERE enabled = true
 		ORDER BY name
@@ -416,11 +483,18 @@ func (r *RuntimeV2) loadEnabledPlugins(ctx context.Context) (int, error) {
 	}
 	defer rows.Close()
 
-	loadedCount := 0
+	type pendingPlugin struct {
+		plugin    models.InstalledPlugin
+		config    map[string]interface{}
+		catalogID sql.NullInt64
+		manifest  []byte
+	}
+
+	pending := make(map[string]*pendingPlugin)
 	for rows.Next() {
 		var plugin models.InstalledPlugin
 		var catalogID sql.NullInt64
-		var configJSON []byte
+		var configJSON, manifestJSON []byte
 
 		err := rows.Scan(
 			&plugin.ID,
@@ -429,6 +503,7 @@ func (r *RuntimeV2) loadEnabledPlugins(ctx context.Context) (int, error) {
 			&plugin.Enabled,
 			&configJSON,
 			&catalogID,
+			&manifestJSON,
 		)
 		if err != nil {
 			log.Printf("[Plugin Runtime] Error scanning plugin: %v", err)
@@ -444,21 +519,43 @@ func (r *RuntimeV2) loadEnabledPlugins(ctx context.Context) (int, error) {
 			}
 		}
 
-		// Load manifest from catalog if available
-		var manifest models.PluginManifest
-		if catalogID.Valid {
-			err = r.db.DB().QueryRowContext(ctx, `
-				SELECT manifest FROM catalog_plugins WHERE id = $1
-			`, catalogID.Int64).Scan(&manifest)
-			if err != nil {
-				log.Printf("[Plugin Runtime] Warning: Could not load manifest for %s: %v", plugin.Name, err)
-				// Continue without manifest
-			}
+		pending[plugin.Name] = &pendingPlugin{plugin: plugin, config: config, catalogID: catalogID, manifest: manifestJSON}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read installed plugins: %w", err)
+	}
+	rows.Close()
+
+	// Load manifests from catalog if available
+	manifests := make(map[string]models.PluginManifest, len(pending))
+	parsed := make([]*reposync.ParsedPlugin, 0, len(pending))
+	for name, p := range pending {
+		manifest := r.installedManifest(ctx, name, p.manifest, p.catalogID)
+		manifests[name] = manifest
+		parsed = append(parsed, &reposync.ParsedPlugin{
+			Name:         name,
+			Version:      p.plugin.Version,
+			Dependencies: manifest.Dependencies,
+		})
+	}
+
+	// Order plugins so dependencies are loaded first
+	ordered, err := reposync.ResolveDependencies(parsed)
+	if err != nil {
+		var depErr *reposync.DependencyError
+		if !errors.As(err, &depErr) {
+			return 0, err
 		}
+		log.Printf("[Plugin Runtime] Warning: %v", depErr)
+	}
+
+	loadedCount := 0
+	for _, resolved := range ordered {
+		p := pending[resolved.Name]
 
 		// Load the plugin
-		if err := r.LoadPluginWithConfig(ctx, plugin.Name, plugin.Version, config, manifest); err != nil {
-			log.Printf("[Plugin Runtime] Error loading plugin %s: %v", plugin.Name, err)
+		if err := r.LoadPluginWithConfig(ctx, p.plugin.Name, p.plugin.Version, p.config, manifests[resolved.Name]); err != nil {
+			log.Printf("[Plugin Runtime] Error loading plugin %s: %v", p.plugin.Name, err)
 			continue
 		}
 
@@ -487,10 +584,10 @@ func (r *RuntimeV2) LoadPluginByName(ctx context.Context, name string) error {
 	// Query plugin from database
 	var plugin models.InstalledPlugin
 	var catalogID sql.NullInt64
-	var configJSON []byte
+	var configJSON, manifestJSON []byte
 
 	err := r.db.DB().QueryRowContext(ctx, `
-		SELECT id, name, version, enabled, config, catalog_plugin_id
+		SELECT id, name, version, enabled, config, catalog_plugin_id, manifest
 		FROM installed_plugins
 		WHERE name = $1
 	`, name).Scan(
@@ -500,6 +597,7 @@ func (r *RuntimeV2) LoadPluginByName(ctx context.Context, name string) error {
 		&plugin.Enabled,
 		&configJSON,
 		&catalogID,
+		&manifestJSON,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -517,20 +615,40 @@ func (r *RuntimeV2) LoadPluginByName(ctx context.Context, name string) error {
 		}
 	}
 
-	// Load manifest from catalog if available
+	manifest := r.installedManifest(ctx, plugin.Name, manifestJSON, catalogID)
+
+	// Load the plugin
+	return r.LoadPluginWithConfig(ctx, plugin.Name, plugin.Version, config, manifest)
+}
+
+// installedManifest returns the manifest an installed plugin runs with: the
+// snapshot taken when it was installed, upgraded or rolled back, or else
+// its catalog entry's manifest.
+//
+// A missing or unreadable manifest is logged and an empty one returned;
+// the plugin still loads.
+func (r *RuntimeV2) installedManifest(ctx context.Context, name string, snapshot []byte, catalogID sql.NullInt64) models.PluginManifest {
 	var manifest models.PluginManifest
+	if len(snapshot) > 0 {
+		err := json.Unmarshal(snapshot, &manifest)
+		if err == nil {
+			return manifest
+		}
+		log.Printf("[Plugin Runtime] Warning: Could not parse installed manifest for %s: %v", name, err)
+		manifest = models.PluginManifest{}
+	}
+
+	// Load manifest from catalog if available
 	if catalogID.Valid {
-		err = r.db.DB().QueryRowContext(ctx, `
+		err := r.db.DB().QueryRowContext(ctx, `
 			SELECT manifest FROM catalog_plugins WHERE id = $1
 		`, catalogID.Int64).Scan(&manifest)
 		if err != nil {
-			log.Printf("[Plugin Runtime] Warning: Could not load manifest for %s: %v", plugin.Name, err)
+			log.Printf("[Plugin Runtime] Warning: Could not load manifest for %s: %v", name, err)
 			// Continue without manifest
 		}
 	}
-
-	// Load the plugin
-	return r.LoadPluginWithConfig(ctx, plugin.Name, plugin.Version, config, manifest)
+	return manifest
 }
 
 // ReloadPlugin unloads and reloads a plugin with updated configuration.
@@ -557,6 +675,113 @@ func (r *RuntimeV2) ReloadPlugin(ctx context.Context, name string) error {
 	return r.LoadPluginByName(ctx, name)
 }
 
+// EnablePlugin notifies a plugin that an admin enabled it.
+//
+// A loaded plugin is marked enabled (it receives platform events again) and
+// its OnEnable hook is called. A plugin that is not loaded yet is loaded from
+// the database first, which calls OnLoad before OnEnable.
+//
+// Returns the hook's error; the plugin stays enabled either way.
+//
+// Thread Safety: Thread-safe via internal locking.
+func (r *RuntimeV2) EnablePlugin(ctx context.Context, name string) error {
+	r.pluginsMux.RLock()
+	_, loaded := r.plugins[name]
+	r.pluginsMux.RUnlock()
+
+	if !loaded {
+		if err := r.LoadPluginByName(ctx, name); err != nil {
+			return fmt.Errorf("failed to load plugin %s: %w", name, err)
+		}
+	}
+
+	plugin := r.setPluginState(name, func(p *LoadedPlugin) { p.Enabled = true })
+	if plugin == nil {
+		return fmt.Errorf("plugin %s is not loaded", name)
+	}
+	r.apiRegistry.EnableEndpoints(name)
+	r.sandbox.Resume(name)
+	return callLifecycleHook(name, "OnEnable", func() error {
+		return plugin.Handler.OnEnable(plugin.Instance.Context)
+	})
+}
+
+// DisablePlugin notifies a loaded plugin that an admin disabled it.
+//
+// The plugin stays loaded but stops receiving platform events, and its
+// OnDisable hook is called. Disabling a plugin that is not loaded is a no-op.
+//
+// Thread Safety: Thread-safe via internal locking.
+func (r *RuntimeV2) DisablePlugin(ctx context.Context, name string) error {
+	plugin := r.setPluginState(name, func(p *LoadedPlugin) { p.Enabled = false })
+	if plugin == nil {
+		return nil
+	}
+	return callLifecycleHook(name, "OnDisable", func() error {
+		return plugin.Handler.OnDisable(plugin.Instance.Context)
+	})
+}
+
+// disableViolatingPlugin disables a plugin the sandbox caught exceeding its
+// handler limits, and persists the change so it stays disabled after a
+// restart.
+func (r *RuntimeV2) disableViolatingPlugin(ctx context.Context, name string) error {
+	if r.db != nil {
+		if _, err := r.db.DB().ExecContext(ctx, `
+			UPDATE installed_plugins SET enabled = false, updated_at = CURRENT_TIMESTAMP WHERE name = $1
+		`, name); err != nil {
+			return fmt.Errorf("failed to persist disabled state: %w", err)
+		}
+	}
+	return r.DisablePlugin(ctx, name)
+}
+
+// UpdatePlugin notifies a loaded plugin that its installation was updated.
+//
+// The plugin's OnUpdate hook receives the previous and new version strings
+// (equal when only the configuration changed) so it can migrate its data.
+// Updating a plugin that is not loaded is a no-op.
+//
+// Thread Safety: Thread-safe via internal locking.
+func (r *RuntimeV2) UpdatePlugin(ctx context.Context, name, oldVersion, newVersion string) error {
+	plugin := r.setPluginState(name, func(p *LoadedPlugin) { p.Version = newVersion })
+	if plugin == nil {
+		return nil
+	}
+	return callLifecycleHook(name, "OnUpdate", func() error {
+		return plugin.Handler.OnUpdate(plugin.Instance.Context, oldVersion, newVersion)
+	})
+}
+
+// setPluginState applies update to a loaded plugin under the write lock and
+// returns it, or nil if the plugin is not loaded.
+func (r *RuntimeV2) setPluginState(name string, update func(p *LoadedPlugin)) *LoadedPlugin {
+	r.pluginsMux.Lock()
+	defer r.pluginsMux.Unlock()
+
+	plugin, exists := r.plugins[name]
+	if !exists {
+		return nil
+	}
+	update(plugin)
+	return plugin
+}
+
+// callLifecycleHook runs a plugin lifecycle hook, converting a panic into an
+// error so a faulty plugin cannot crash the API.
+func callLifecycleHook(name, hook string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin %s panicked in %s: %v", name, hook, r)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		return fmt.Errorf("plugin %s %s failed: %w", name, hook, err)
+	}
+	return nil
+}
+
 // LoadPluginWithConfig loads and initializes a plugin with specific configuration.
 //
 // This is the core plugin loading method that:
@@ -585,6 +810,7 @@ func (r *RuntimeV2) ReloadPlugin(ctx context.Context, name string) error {
 //   - API: HTTP endpoint registration (/api/plugins/{name}/*)
 //   - UI: Component registration (widgets, pages, menus)
 //   - Storage: Key-value storage
+//   - Secrets: Read access to the plugin's Kubernetes Secret
 //   - Logger: Structured JSON logging
 //   - Scheduler: Cron job scheduling
 //
@@ -615,10 +841,19 @@ func (r *RuntimeV2) LoadPluginWithConfig(ctx context.Context, name, version stri
 
 	log.Printf("[Plugin Runtime] Loading plugin: %s@%s", name, version)
 
-	// Load plugin handler via discovery
-	handler, err := r.discovery.LoadPlugin(name)
-	if err != nil {
-		return fmt.Errorf("failed to load plugin handler: %w", err)
+	// Load plugin handler via discovery; webhook plugins are served by the
+	// webhook dispatcher
+	var handler PluginHandler
+	if manifest.Type == PluginTypeWebhook {
+		handler = r.webhooks.Plugin()
+	} else {
+		var err error
+		handler, err = r.discovery.LoadPlugin(name)
+		if err != nil {
+			err = fmt.Errorf("failed to load plugin handler: %w", err)
+			r.health.loaded(name, err)
+			return err
+		}
 	}
 
 	// Create plugin context
@@ -631,12 +866,17 @@ func (r *RuntimeV2) LoadPluginWithConfig(ctx context.Context, name, version stri
 
 	// Initialize plugin components
 	pluginCtx.Database = NewPluginDatabase(r.db, name)
-	pluginCtx.Events = NewPluginEvents(r.eventBus, name)
+	eventPermissions := NewEventPermissions(name, manifest.Permissions)
+	pluginCtx.Events = NewPluginEventsWithPermissions(r.eventBus, name, eventPermissions)
 	pluginCtx.API = NewPluginAPI(r.apiRegistry, name)
 	pluginCtx.UI = NewPluginUI(r.uiRegistry, name)
 	pluginCtx.Storage = NewPluginStorage(r.db, name)
+	pluginCtx.Secrets = NewPluginSecrets(r.secrets, name)
+	pluginCtx.Health = NewPluginHealth()
 	pluginCtx.Logger = NewPluginLogger(name)
-	pluginCtx.Scheduler = NewPluginScheduler(r.scheduler, name)
+	pluginCtx.Scheduler = NewPluginScheduler(r.tasks, name)
+	r.sandbox.Configure(name, config)
+	r.apiRegistry.ConfigureTimeouts(name, config)
 
 	// Create plugin instance
 	instance := &PluginInstance{
@@ -657,15 +897,20 @@ func (r *RuntimeV2) LoadPluginWithConfig(ctx context.Context, name, version stri
 		Instance:  instance,
 		LoadedAt:  time.Now(),
 		IsBuiltin: r.discovery.IsBuiltin(name),
+
+		EventPermissions: eventPermissions,
 	}
 
 	// Call OnLoad hook
 	if err := handler.OnLoad(pluginCtx); err != nil {
-		return fmt.Errorf("plugin OnLoad failed: %w", err)
+		err = fmt.Errorf("plugin OnLoad failed: %w", err)
+		r.health.loaded(name, err)
+		return err
 	}
 
 	// Register plugin
 	r.plugins[name] = loaded
+	r.health.loaded(name, nil)
 
 	log.Printf("[Plugin Runtime] Plugin loaded successfully: %s@%s (builtin: %v)", name, version, loaded.IsBuiltin)
 	return nil
@@ -680,6 +925,9 @@ func (r *RuntimeV2) LoadPluginWithConfig(ctx context.Context, name, version stri
 //  4. Unregister all UI components
 //  5. Remove all event subscriptions
 //  6. Stop the cron scheduler (waits for running jobs)
+//  7. Wait for in-flight webhook deliveries (pending retries are dropped)
+//
+// Health checks are stopped before any plugin is unloaded.
 //
 // Parameters:
 //   - ctx: Context for cancellation (currently not used, reserved for future)
@@ -709,6 +957,12 @@ func (r *RuntimeV2) LoadPluginWithConfig(ctx context.Context, name, version stri
 func (r *RuntimeV2) Stop(ctx context.Context) error {
 	log.Println("[Plugin Runtime] Stopping...")
 
+	// Stop health checks first: they take the read lock
+	if r.stopHealth != nil {
+		r.stopHealth()
+		r.healthWG.Wait()
+	}
+
 	r.pluginsMux.Lock()
 	defer r.pluginsMux.Unlock()
 
@@ -723,6 +977,11 @@ func (r *RuntimeV2) Stop(ctx context.Context) error {
 	schedCtx := r.scheduler.Stop()
 	<-schedCtx.Done()
 
+	// Let in-flight webhook deliveries finish
+	if err := r.webhooks.Stop(ctx); err != nil {
+		log.Printf("[Plugin Runtime] Webhook deliveries still running at shutdown: %v", err)
+	}
+
 	log.Println("[Plugin Runtime] Stopped successfully")
 	return nil
 }
@@ -783,10 +1042,12 @@ func (r *RuntimeV2) unloadPluginLocked(ctx context.Context, name string) error {
 	}
 
 	// Cleanup plugin resources
-	plugin.Instance.Scheduler.RemoveAll()
+	r.tasks.UnregisterAll(name)
 	r.apiRegistry.UnregisterAll(name)
 	r.uiRegistry.UnregisterAll(name)
 	r.eventBus.UnsubscribeAll(name)
+	r.sandbox.Forget(name)
+	r.health.unloaded(name)
 
 	// Remove from registry
 	delete(r.plugins, name)
@@ -853,11 +1114,20 @@ func (r *RuntimeV2) unloadPluginLocked(ctx context.Context, name string) error {
 //
 // Thread Safety: Thread-safe via read lock (allows concurrent event emission).
 func (r *RuntimeV2) EmitEvent(eventType string, data interface{}) {
+	r.EmitEventWithContext(context.Background(), eventType, data)
+}
+
+// EmitEventWithContext is EmitEvent for events caused by a request: event
+// bus handlers receive ctx's values, including its tracing.TraceContext
+// (see EventBus.EmitWithContext).
+//
+// Thread Safety: Thread-safe via read lock (allows concurrent event emission).
+func (r *RuntimeV2) EmitEventWithContext(ctx context.Context, eventType string, data interface{}) {
 	r.pluginsMux.RLock()
 	defer r.pluginsMux.RUnlock()
 
 	// Emit to event bus
-	r.eventBus.Emit(eventType, data)
+	r.eventBus.EmitWithContext(ctx, eventType, data)
 
 	// Call appropriate lifecycle hooks
 	for name, plugin := range r.plugins {
@@ -875,27 +1145,27 @@ func (r *RuntimeV2) EmitEvent(eventType string, data interface{}) {
 
 			var err error
 			switch eventType {
-			case "session.created":
+			case events.PluginEventSessionCreated:
 				err = p.Handler.OnSessionCreated(p.Instance.Context, data)
-			case "session.started":
+			case events.PluginEventSessionStarted:
 				err = p.Handler.OnSessionStarted(p.Instance.Context, data)
-			case "session.stopped":
+			case events.PluginEventSessionStopped:
 				err = p.Handler.OnSessionStopped(p.Instance.Context, data)
-			case "session.hibernated":
+			case events.PluginEventSessionHibernated:
 				err = p.Handler.OnSessionHibernated(p.Instance.Context, data)
-			case "session.woken":
+			case events.PluginEventSessionWoken:
 				err = p.Handler.OnSessionWoken(p.Instance.Context, data)
-			case "session.deleted":
+			case events.PluginEventSessionDeleted:
 				err = p.Handler.OnSessionDeleted(p.Instance.Context, data)
-			case "user.created":
+			case events.PluginEventUserCreated:
 				err = p.Handler.OnUserCreated(p.Instance.Context, data)
-			case "user.updated":
+			case events.PluginEventUserUpdated:
 				err = p.Handler.OnUserUpdated(p.Instance.Context, data)
-			case "user.deleted":
+			case events.PluginEventUserDeleted:
 				err = p.Handler.OnUserDeleted(p.Instance.Context, data)
-			case "user.login":
+			case events.PluginEventUserLogin:
 				err = p.Handler.OnUserLogin(p.Instance.Context, data)
-			case "user.logout":
+			case events.PluginEventUserLogout:
 				err = p.Handler.OnUserLogout(p.Instance.Context, data)
 			}
 
@@ -945,6 +1215,19 @@ func (r *RuntimeV2) GetPlugin(name string) (*LoadedPlugin, error) {
 	return plugin, nil
 }
 
+// EventPermissions returns the event permissions granted to a loaded
+// plugin. ok is false if the plugin is not loaded.
+func (r *RuntimeV2) EventPermissions(name string) (perms EventPermissions, ok bool) {
+	r.pluginsMux.RLock()
+	defer r.pluginsMux.RUnlock()
+
+	plugin, exists := r.plugins[name]
+	if !exists {
+		return EventPermissions{}, false
+	}
+	return plugin.EventPermissions, true
+}
+
 // ListPlugins returns all currently loaded plugins.
 //
 // Returns a slice of LoadedPlugin structs, one for each loaded plugin.
@@ -1071,6 +1354,16 @@ func (r *RuntimeV2) GetAPIRegistry() *APIRegistry {
 	return r.apiRegistry
 }
 
+// GetTaskRegistry returns the registry of plugin scheduled tasks.
+//
+// Primary Use Case: GET /api/plugins/:id/tasks lists a plugin's tasks with
+// their last run and last error.
+//
+// Thread Safety: TaskRegistry has internal locking.
+func (r *RuntimeV2) GetTaskRegistry() *TaskRegistry {
+	return r.tasks
+}
+
 // GetUIRegistry returns the UI registry for direct access.
 //
 // This allows external code to:
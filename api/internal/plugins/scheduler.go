@@ -26,16 +26,16 @@
 //   - Simple API: scheduler.Schedule("daily-report", "@daily", func)
 //   - Cron library handles timing (accurate, efficient)
 //   - Automatic error recovery (panics logged, job continues)
-//   - RemoveAll() on plugin unload (cleanup guaranteed)
-//   - ListJobs() for debugging
+//   - Jobs removed and cancelled when the plugin unloads (cleanup guaranteed)
+//   - ListJobs() for debugging, Tasks() for last run and last error
 //
 // # Architecture: Per-Plugin Scheduler
 //
 //	┌─────────────────────────────────────────────────────────┐
-//	│  Global Cron Instance (shared across all plugins)      │
-//	│  - Single background goroutine                          │
-//	│  - Manages all scheduled jobs                           │
-//	│  - Runs jobs at specified times                         │
+//	│  TaskRegistry (shared across all plugins)               │
+//	│  - Single cron instance and background goroutine        │
+//	│  - Tracks which plugin owns each job                    │
+//	│  - Records last run and last error of each job          │
 //	└──────────────────────┬──────────────────────────────────┘
 //	                       │
 //	         ┌─────────────┼─────────────┐
@@ -52,7 +52,7 @@
 //
 // **Why one scheduler per plugin?**
 //   - Namespace isolation: Each plugin manages own jobs
-//   - Easy cleanup: RemoveAll() removes only plugin's jobs
+//   - Easy cleanup: the runtime removes only the unloaded plugin's jobs
 //   - Prevents naming conflicts: Plugin A "sync" vs. Plugin B "sync"
 //   - Simplifies plugin code (don't need to prefix job names)
 //
@@ -115,8 +115,8 @@
 //     - Problem: Multiple API replicas all run same jobs (duplicate work)
 //     - Future: Add distributed locking (Redis, PostgreSQL advisory locks)
 //
-//  2. **No job history**: Can't see when job last ran or if it failed
-//     - Future: Store job run history in database
+//  2. **Latest run only**: The last run time and error of each job are kept
+//     (see task_registry.go), not a history of runs
 //
 //  3. **No job dependencies**: Can't chain jobs (run B after A completes)
 //     - Workaround: Use event bus to trigger dependent jobs
@@ -125,85 +125,71 @@
 //     - Future: Support per-job timezone configuration
 //
 // See also:
+//   - api/internal/plugins/task_registry.go: Central task registry
 //   - api/internal/plugins/runtime.go: Plugin lifecycle management
 //   - github.com/robfig/cron: Underlying cron library
 package plugins
 
 import (
+	"context"
 	"fmt"
-	"log"
-
-	"github.com/robfig/cron/v3"
 )
 
 // PluginScheduler provides cron-based scheduling for plugins.
 //
-// Each plugin receives its own scheduler instance, which wraps a shared global
-// cron instance but maintains separate job namespace and lifecycle management.
+// Each plugin receives its own scheduler instance, a view of the shared
+// TaskRegistry limited to the plugin's own job namespace.
 //
 // **Fields**:
-//   - cron: Shared global cron instance (one per platform)
-//   - pluginName: Plugin identifier (for logging and namespacing)
-//   - jobIDs: Map of job name to cron entry ID (for removal)
-//
-// **Why map job names to entry IDs?**
-//   - Cron library identifies jobs by EntryID (sequential integer)
-//   - Plugins use human-readable names ("daily-cleanup", "sync-users")
-//   - Map allows Remove("daily-cleanup") without remembering EntryID
-//   - Prevents duplicate job names within same plugin
+//   - tasks: Shared task registry (one per platform)
+//   - pluginName: Plugin identifier (owner of the jobs it schedules)
 //
 // **Lifecycle**:
 //   - Created: When plugin is loaded (NewPluginScheduler)
 //   - Used: Plugin calls Schedule(), Remove(), etc.
-//   - Cleanup: RemoveAll() called on plugin unload
+//   - Cleanup: The runtime calls TaskRegistry.UnregisterAll on plugin unload
 //
-// **Thread Safety**: Not thread-safe internally (map access), but underlying
-// cron.Cron is thread-safe, so concurrent Schedule() calls are safe.
+// **Thread Safety**: Thread-safe; the registry has internal locking.
 type PluginScheduler struct {
-	cron       *cron.Cron
+	tasks      *TaskRegistry
 	pluginName string
-	jobIDs     map[string]cron.EntryID // jobName -> entryID
 }
 
 // NewPluginScheduler creates a new plugin scheduler instance.
 //
 // This constructor is called by the runtime when loading a plugin, providing
-// the plugin with its own scheduler that wraps the shared global cron instance.
+// the plugin with its own scheduler that wraps the shared task registry.
 //
-// **Why pass cron instance instead of creating new one?**
+// **Why pass the registry instead of creating a cron instance?**
 //   - Single background goroutine for all plugins (efficient)
 //   - Shared ticker reduces CPU wakeups (battery-friendly)
 //   - Centralized lifecycle management (one cron.Start/Stop)
 //   - Alternative: Per-plugin cron = N goroutines + N tickers (wasteful)
 //
 // **Parameter Validation**:
-//   - cronInstance: Must not be nil (panics if nil, caller error)
-//   - pluginName: Used for logging, empty string allowed but not recommended
-//
-// **Initialization**:
-//   - Empty jobIDs map (no jobs scheduled yet)
-//   - Plugin must call Schedule() to add jobs
+//   - tasks: Must not be nil (panics if nil, caller error)
+//   - pluginName: Owner of the scheduled jobs, must be the plugin's name
 //
 // **Example Usage** (in runtime):
 //
 //	globalCron := cron.New()
 //	globalCron.Start()
+//	tasks := NewTaskRegistry(globalCron, database)
 //
 //	for _, plugin := range plugins {
-//	    scheduler := NewPluginScheduler(globalCron, plugin.Name)
+//	    scheduler := NewPluginScheduler(tasks, plugin.Name)
 //	    plugin.OnLoad(scheduler, ...) // Plugin receives scheduler
 //	}
 //
 // Parameters:
-//   - cronInstance: Shared global cron instance
-//   - pluginName: Plugin identifier for logging
+//   - tasks: Shared task registry
+//   - pluginName: Plugin identifier
 //
 // Returns initialized scheduler ready to schedule jobs.
-func NewPluginScheduler(cronInstance *cron.Cron, pluginName string) *PluginScheduler {
+func NewPluginScheduler(tasks *TaskRegistry, pluginName string) *PluginScheduler {
 	return &PluginScheduler{
-		cron:       cronInstance,
+		tasks:      tasks,
 		pluginName: pluginName,
-		jobIDs:     make(map[string]cron.EntryID),
 	}
 }
 
@@ -221,9 +207,10 @@ func NewPluginScheduler(cronInstance *cron.Cron, pluginName string) *PluginSched
 //   - "@daily"        → Every day at midnight (shortcut)
 //
 // **Job Wrapping** (automatic):
-//   - Panic recovery: Panics logged, job continues on next schedule
-//   - Logging: Logs when job starts (helps debugging)
-//   - Plugin context: Logs include plugin name
+//   - Panic recovery: Panics recorded as the job's last error, job continues
+//     on next schedule
+//   - Last run time and duration recorded (see Tasks)
+//   - A run is skipped while the previous one is still running
 //
 // **Duplicate Job Names** (overwrite behavior):
 //   - If job "sync" already exists: Remove old, add new
@@ -236,10 +223,14 @@ func NewPluginScheduler(cronInstance *cron.Cron, pluginName string) *PluginSched
 //   - Enables dynamic reconfiguration
 //   - Alternative: Return error on duplicate (forces manual Remove)
 //
+// The expression is validated before the old job is removed, so an invalid
+// reschedule keeps the existing job.
+//
 // **Job Function Signature**:
 //   - Must be `func()` (no parameters, no return value)
 //   - Runs in separate goroutine (don't block)
 //   - Can access plugin state via closures
+//   - Jobs that can fail or run long should use ScheduleTask instead
 //
 // **Example Usage** (in plugin):
 //
@@ -259,7 +250,7 @@ func NewPluginScheduler(cronInstance *cron.Cron, pluginName string) *PluginSched
 //
 // **Error Cases**:
 //   - Invalid cron expression: Returns parse error from cron library
-//   - Example: "invalid" → "failed to parse cron expression"
+//   - Example: "invalid" → "invalid schedule \"invalid\" for task ..."
 //   - Job added successfully: Returns nil
 //
 // **Performance**:
@@ -274,34 +265,27 @@ func NewPluginScheduler(cronInstance *cron.Cron, pluginName string) *PluginSched
 //
 // Returns nil on success, error if cron expression is invalid.
 func (ps *PluginScheduler) Schedule(jobName string, cronExpr string, job func()) error {
-	// Remove existing job if any
-	if existingID, exists := ps.jobIDs[jobName]; exists {
-		ps.cron.Remove(existingID)
-		delete(ps.jobIDs, jobName)
-	}
-
-	// Wrap job with plugin context and error handling
-	wrappedJob := func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("[Plugin:%s] Scheduled job %s panicked: %v", ps.pluginName, jobName, r)
-			}
-		}()
-
-		log.Printf("[Plugin:%s] Running scheduled job: %s", ps.pluginName, jobName)
+	return ps.tasks.Schedule(ps.pluginName, jobName, cronExpr, func(context.Context) error {
 		job()
-	}
-
-	// Add job to cron
-	entryID, err := ps.cron.AddFunc(cronExpr, wrappedJob)
-	if err != nil {
-		return fmt.Errorf("failed to schedule job %s for plugin %s: %w", jobName, ps.pluginName, err)
-	}
-
-	ps.jobIDs[jobName] = entryID
-	log.Printf("[Plugin:%s] Scheduled job %s with expression: %s", ps.pluginName, jobName, cronExpr)
+		return nil
+	})
+}
 
-	return nil
+// ScheduleTask schedules a job that reports failure and can be cancelled.
+//
+// Like Schedule, but the returned error is recorded as the job's last error
+// (see Tasks), and ctx is cancelled when the job is removed or the plugin
+// unloads, so long-running jobs should stop when it is done.
+//
+// **Example Usage** (in plugin):
+//
+//	ctx.Scheduler.ScheduleTask("monthly-report", "0 6 1 * *", func(ctx context.Context) error {
+//	    return p.generateReport(ctx)
+//	})
+//
+// Returns nil on success, error if cron expression is invalid.
+func (ps *PluginScheduler) ScheduleTask(jobName string, cronExpr string, task func(ctx context.Context) error) error {
+	return ps.tasks.Schedule(ps.pluginName, jobName, cronExpr, task)
 }
 
 // Remove removes a scheduled job by name.
@@ -310,10 +294,9 @@ func (ps *PluginScheduler) Schedule(jobName string, cronExpr string, job func())
 // scheduler. If the job doesn't exist, this is a no-op (safe to call).
 //
 // **Removal Process**:
-//  1. Look up job name in jobIDs map
-//  2. If exists: Call cron.Remove(entryID)
-//  3. Delete from jobIDs map
-//  4. Log removal
+//  1. Look up the plugin's job in the task registry
+//  2. If exists: Remove it from cron and cancel its context
+//  3. Log removal
 //
 // **Why no error return?**
 //   - Removing non-existent job is safe (idempotent)
@@ -339,64 +322,40 @@ func (ps *PluginScheduler) Schedule(jobName string, cronExpr string, job func())
 //	}
 //
 // **Thread Safety**:
-//   - cron.Remove() is thread-safe
-//   - Map access not protected (assumes sequential calls from plugin)
-//   - Safe to call while job is running (job completes, won't reschedule)
+//   - Safe for concurrent use
+//   - Safe to call while job is running (its context is cancelled, it
+//     won't reschedule)
 //
 // Parameters:
 //   - jobName: Name of job to remove
 //
 // No return value (idempotent, always succeeds).
 func (ps *PluginScheduler) Remove(jobName string) {
-	if entryID, exists := ps.jobIDs[jobName]; exists {
-		ps.cron.Remove(entryID)
-		delete(ps.jobIDs, jobName)
-		log.Printf("[Plugin:%s] Removed scheduled job: %s", ps.pluginName, jobName)
-	}
+	ps.tasks.Unregister(ps.pluginName, jobName)
 }
 
 // RemoveAll removes all scheduled jobs for this plugin.
 //
-// This method is called during plugin unload to ensure clean shutdown,
-// preventing orphaned jobs from running after plugin is stopped.
+// The runtime removes a plugin's jobs itself after OnUnload (through
+// TaskRegistry.UnregisterAll), so plugins don't need to call this on
+// unload. It is useful to stop all jobs while staying loaded, e.g. in
+// OnDisable.
 //
 // **Cleanup Process**:
-//  1. Iterate through all job IDs in jobIDs map
-//  2. Call cron.Remove(entryID) for each
-//  3. Clear jobIDs map (reset to empty)
-//  4. Log each removal
-//
-// **Why clear the map?**
-//   - Prevents memory leaks (stale entry IDs)
-//   - Allows plugin to be reloaded cleanly
-//   - Makes scheduler reusable (though typically not reused)
+//  1. Remove each of the plugin's jobs from cron
+//  2. Cancel the context of jobs that are running
+//  3. Log each removal
 //
-// **When Called**:
-//   - Plugin unload: runtime.UnloadPlugin() calls plugin.OnUnload()
-//   - Plugin disable: Admin disables plugin in UI
-//   - Platform shutdown: Cleanup all plugins
+// **Example** (in plugin OnDisable):
 //
-// **Example** (in plugin OnUnload):
-//
-//	func (p *MyPlugin) OnUnload() error {
-//	    // Stop all scheduled jobs
-//	    p.scheduler.RemoveAll()
-//
-//	    // Clean up other resources
-//	    p.db.Close()
+//	func (p *MyPlugin) OnDisable(ctx *PluginContext) error {
+//	    ctx.Scheduler.RemoveAll()
 //	    return nil
 //	}
 //
-// **What if RemoveAll not called?**
-//   - Jobs continue running (access unloaded plugin state)
-//   - Panics likely (plugin resources released)
-//   - Memory leak (plugin can't be garbage collected)
-//   - Critical: Always call RemoveAll in OnUnload
-//
 // **Thread Safety**:
 //   - Safe to call while jobs are running
-//   - Running jobs complete, won't reschedule
-//   - cron.Remove() thread-safe
+//   - Running jobs are cancelled, won't reschedule
 //
 // **Performance**:
 //   - Time: O(n) where n = number of plugin's jobs
@@ -405,11 +364,7 @@ func (ps *PluginScheduler) Remove(jobName string) {
 //
 // No parameters or return value.
 func (ps *PluginScheduler) RemoveAll() {
-	for jobName, entryID := range ps.jobIDs {
-		ps.cron.Remove(entryID)
-		log.Printf("[Plugin:%s] Removed scheduled job: %s", ps.pluginName, jobName)
-	}
-	ps.jobIDs = make(map[string]cron.EntryID)
+	ps.tasks.UnregisterAll(ps.pluginName)
 }
 
 // ListJobs returns all scheduled job names for this plugin.
@@ -420,7 +375,7 @@ func (ps *PluginScheduler) RemoveAll() {
 // **Return Value**:
 //   - Slice of job names (e.g., ["sync", "cleanup", "report"])
 //   - Empty slice if no jobs scheduled
-//   - Order: Undefined (map iteration order)
+//   - Order: Sorted by name
 //
 // **Use Cases**:
 //   - Debugging: Log all scheduled jobs on plugin load
@@ -447,26 +402,29 @@ func (ps *PluginScheduler) RemoveAll() {
 //	    "count": 3
 //	}
 //
-// **Why not return more details?**
-//   - Cron library doesn't expose schedule or next run time easily
-//   - Would require additional tracking (complexity)
-//   - Job names sufficient for most debugging
-//   - Future: Could add GetJobDetails(name) for schedule, next run, etc.
+// For schedule, next run, last run and last error use Tasks.
 //
 // **Performance**:
 //   - Time: O(n) where n = number of jobs
 //   - Memory: Allocates new slice (copy of keys)
 //   - Typical: <1µs for 10 jobs
 //
-// Returns slice of job names (order undefined).
+// Returns slice of job names (sorted).
 func (ps *PluginScheduler) ListJobs() []string {
-	jobs := make([]string, 0, len(ps.jobIDs))
-	for jobName := range ps.jobIDs {
-		jobs = append(jobs, jobName)
+	tasks := ps.tasks.ListTasks(ps.pluginName)
+	jobs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		jobs = append(jobs, task.Name)
 	}
 	return jobs
 }
 
+// Tasks returns the plugin's scheduled jobs with their schedule, next run
+// and the outcome of their last run, sorted by name.
+func (ps *PluginScheduler) Tasks() []TaskStatus {
+	return ps.tasks.ListTasks(ps.pluginName)
+}
+
 // IsScheduled checks if a job is currently scheduled.
 //
 // This method provides a simple way to check job existence without
@@ -511,8 +469,7 @@ func (ps *PluginScheduler) ListJobs() []string {
 //
 // Returns true if job is scheduled, false otherwise.
 func (ps *PluginScheduler) IsScheduled(jobName string) bool {
-	_, exists := ps.jobIDs[jobName]
-	return exists
+	return ps.tasks.IsScheduled(ps.pluginName, jobName)
 }
 
 // ScheduleInterval schedules a job to run at a fixed interval.
@@ -568,7 +525,7 @@ func (ps *PluginScheduler) IsScheduled(jobName string) bool {
 //   - Prevents abuse (scheduling job every second)
 //   - Alternative: Use goroutine + time.Ticker for sub-minute tasks
 //
-// **Thread Safety**: Same as Schedule() (wraps cron.AddFunc)
+// **Thread Safety**: Same as Schedule() (wraps it)
 //
 // Parameters:
 //   - jobName: Human-readable job identifier
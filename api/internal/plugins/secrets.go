@@ -0,0 +1,112 @@
+// Package plugins - secrets.go
+//
+// This file implements plugin secrets: credentials such as API tokens that
+// must not live in installed_plugins.config, where anyone with database
+// access can read them and every GET of the plugin returns them.
+//
+// Each plugin's secrets are kept in one Kubernetes Secret in the StreamSpace
+// namespace, named plugin-{name}-secrets (see PluginSecretName). Admins
+// write them with PUT /api/plugins/:id/secrets; the API only ever returns
+// their key names. Plugins read them through their context:
+//
+//	token, err := ctx.Secrets.Get("slack_token")
+//
+// Values are read from the Secret on every Get, so a rotated secret takes
+// effect without reloading the plugin. Uninstalling the plugin deletes the
+// Secret.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// secretReadTimeout bounds a single read of a plugin's Secret
+const secretReadTimeout = 10 * time.Second
+
+var (
+	// ErrSecretNotFound is returned by PluginSecrets.Get for a key the
+	// plugin's Secret does not contain.
+	ErrSecretNotFound = errors.New("plugin secret not found")
+
+	// ErrSecretsUnavailable is returned by PluginSecrets when the runtime
+	// has no SecretStore (for example, outside a Kubernetes cluster).
+	ErrSecretsUnavailable = errors.New("plugin secrets are not available")
+)
+
+// SecretStore reads and deletes the Kubernetes Secrets that hold plugin
+// secrets. *k8s.Client implements this interface.
+type SecretStore interface {
+	GetSecretData(ctx context.Context, name string) (map[string][]byte, error)
+	DeleteSecret(ctx context.Context, name string) error
+}
+
+// PluginSecretName returns the name of the Kubernetes Secret holding a
+// plugin's secrets.
+func PluginSecretName(pluginName string) string {
+	return fmt.Sprintf("plugin-%s-secrets", pluginName)
+}
+
+// PluginSecrets gives a plugin read access to its own secrets.
+type PluginSecrets struct {
+	store      SecretStore
+	pluginName string
+}
+
+// NewPluginSecrets creates the secrets accessor of a plugin. store may be
+// nil, in which case every read fails with ErrSecretsUnavailable.
+func NewPluginSecrets(store SecretStore, pluginName string) *PluginSecrets {
+	return &PluginSecrets{
+		store:      store,
+		pluginName: pluginName,
+	}
+}
+
+// Get returns the value of one of the plugin's secrets, or
+// ErrSecretNotFound if it is not set.
+func (ps *PluginSecrets) Get(key string) (string, error) {
+	data, err := ps.read()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, key)
+	}
+	return string(value), nil
+}
+
+// Keys returns the sorted names of the plugin's secrets.
+func (ps *PluginSecrets) Keys() ([]string, error) {
+	data, err := ps.read()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// read fetches the plugin's Secret.
+func (ps *PluginSecrets) read() (map[string][]byte, error) {
+	if ps.store == nil {
+		return nil, ErrSecretsUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretReadTimeout)
+	defer cancel()
+
+	data, err := ps.store.GetSecretData(ctx, PluginSecretName(ps.pluginName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets of plugin %s: %w", ps.pluginName, err)
+	}
+	return data, nil
+}
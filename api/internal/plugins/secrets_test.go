@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretStore struct {
+	secrets map[string]map[string][]byte
+	deleted []string
+}
+
+func (s *fakeSecretStore) GetSecretData(ctx context.Context, name string) (map[string][]byte, error) {
+	return s.secrets[name], nil
+}
+
+func (s *fakeSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	s.deleted = append(s.deleted, name)
+	delete(s.secrets, name)
+	return nil
+}
+
+func TestPluginSecrets_Get(t *testing.T) {
+	store := &fakeSecretStore{secrets: map[string]map[string][]byte{
+		"plugin-slack-secrets": {"token": []byte("xoxb-1"), "signing_key": []byte("abc")},
+	}}
+	secrets := NewPluginSecrets(store, "slack")
+
+	token, err := secrets.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-1", token)
+
+	_, err = secrets.Get("missing")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	keys, err := secrets.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"signing_key", "token"}, keys)
+
+	// Another plugin cannot see slack's secrets
+	_, err = NewPluginSecrets(store, "other").Get("token")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestPluginSecrets_NoStore(t *testing.T) {
+	_, err := NewPluginSecrets(nil, "slack").Get("token")
+	assert.True(t, errors.Is(err, ErrSecretsUnavailable))
+}
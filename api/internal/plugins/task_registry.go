@@ -0,0 +1,257 @@
+// Package plugins - task_registry.go
+//
+// This file implements the central registry of plugin scheduled tasks.
+//
+// Every plugin's ctx.Scheduler (see scheduler.go) adds its tasks here, on the
+// runtime's single cron instance. The registry records which plugin owns each
+// task, so unloading a plugin (UnregisterAll) removes all of its tasks and
+// cancels the context of any that are still running; plugins no longer need
+// to clean up in OnUnload, and a task can never outlive its plugin.
+//
+// After every run the registry records when the task ran, how long it took
+// and the error it returned (a panic counts as an error), in memory and in
+// the plugin_scheduled_tasks table, so GET /api/plugins/:id/tasks can show
+// them across restarts.
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+// taskWriteTimeout bounds recording a task run in the database
+const taskWriteTimeout = 5 * time.Second
+
+// TaskStatus describes a plugin's scheduled task and its most recent run.
+type TaskStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Scheduled      bool       `json:"scheduled"`
+	Running        bool       `json:"running"`
+	NextRunAt      *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+}
+
+// scheduledTask is a task added to the cron instance.
+type scheduledTask struct {
+	entryID cron.EntryID
+	ctx     context.Context
+	cancel  context.CancelFunc
+	status  TaskStatus
+}
+
+// TaskRegistry runs the scheduled tasks of all plugins.
+//
+// Thread Safety: Safe for concurrent use.
+type TaskRegistry struct {
+	cron *cron.Cron
+	db   *db.Database
+
+	mu    sync.Mutex
+	tasks map[string]map[string]*scheduledTask // pluginName -> taskName -> task
+}
+
+// NewTaskRegistry creates a registry adding tasks to cronInstance. Task runs
+// are recorded in database when it is not nil.
+func NewTaskRegistry(cronInstance *cron.Cron, database *db.Database) *TaskRegistry {
+	return &TaskRegistry{
+		cron:  cronInstance,
+		db:    database,
+		tasks: make(map[string]map[string]*scheduledTask),
+	}
+}
+
+// Schedule adds a task running task at the times matching cronExpr.
+//
+// cronExpr is a standard 5-field cron expression or a descriptor such as
+// @hourly or @every 10m. A task of the plugin with the same name is
+// replaced; if cronExpr is invalid the existing task is kept.
+//
+// The context passed to task is cancelled when the task is removed or its
+// plugin unloaded.
+func (r *TaskRegistry) Schedule(pluginName, taskName, cronExpr string, task func(ctx context.Context) error) error {
+	schedule, err := cron.ParseStandard(cronExpr)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for task %s of plugin %s: %w", cronExpr, taskName, pluginName, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduled := &scheduledTask{
+		ctx:    ctx,
+		cancel: cancel,
+		status: TaskStatus{Name: taskName, Schedule: cronExpr, Scheduled: true},
+	}
+
+	r.mu.Lock()
+	r.removeLocked(pluginName, taskName)
+	if r.tasks[pluginName] == nil {
+		r.tasks[pluginName] = make(map[string]*scheduledTask)
+	}
+	r.tasks[pluginName][taskName] = scheduled
+	scheduled.entryID = r.cron.Schedule(schedule, cron.FuncJob(func() {
+		r.run(pluginName, scheduled, task)
+	}))
+	r.mu.Unlock()
+
+	r.persistSchedule(pluginName, taskName, cronExpr)
+	log.Printf("[Plugin:%s] Scheduled task %s with expression: %s", pluginName, taskName, cronExpr)
+	return nil
+}
+
+// Unregister removes a plugin's task, cancelling it if it is running.
+func (r *TaskRegistry) Unregister(pluginName, taskName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.removeLocked(pluginName, taskName) {
+		log.Printf("[Plugin:%s] Removed scheduled task: %s", pluginName, taskName)
+	}
+}
+
+// UnregisterAll removes all tasks of a plugin, cancelling those that are
+// running. The runtime calls it when the plugin is unloaded.
+func (r *TaskRegistry) UnregisterAll(pluginName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for taskName := range r.tasks[pluginName] {
+		r.removeLocked(pluginName, taskName)
+		log.Printf("[Plugin:%s] Removed scheduled task: %s", pluginName, taskName)
+	}
+	delete(r.tasks, pluginName)
+}
+
+// removeLocked removes a task from the cron instance and the registry.
+//
+// Thread Safety: Caller must hold r.mu.
+func (r *TaskRegistry) removeLocked(pluginName, taskName string) bool {
+	task, ok := r.tasks[pluginName][taskName]
+	if !ok {
+		return false
+	}
+	r.cron.Remove(task.entryID)
+	task.cancel()
+	delete(r.tasks[pluginName], taskName)
+	return true
+}
+
+// IsScheduled reports whether a plugin has a task with the given name.
+func (r *TaskRegistry) IsScheduled(pluginName, taskName string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tasks[pluginName][taskName]
+	return ok
+}
+
+// ListTasks returns the scheduled tasks of a plugin, sorted by name.
+func (r *TaskRegistry) ListTasks(pluginName string) []TaskStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(r.tasks[pluginName]))
+	for _, task := range r.tasks[pluginName] {
+		status := task.status
+		if next := r.cron.Entry(task.entryID).Next; !next.IsZero() {
+			status.NextRunAt = &next
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// run runs a task and records the outcome. A run is skipped while the
+// previous one is still going, and once the task has been removed.
+func (r *TaskRegistry) run(pluginName string, scheduled *scheduledTask, task func(ctx context.Context) error) {
+	r.mu.Lock()
+	if scheduled.ctx.Err() != nil || scheduled.status.Running {
+		r.mu.Unlock()
+		return
+	}
+	scheduled.status.Running = true
+	taskName := scheduled.status.Name
+	r.mu.Unlock()
+
+	started := time.Now()
+	err := runTask(scheduled.ctx, task)
+	duration := time.Since(started)
+	if err != nil {
+		log.Printf("[Plugin:%s] Scheduled task %s failed: %v", pluginName, taskName, err)
+	}
+
+	r.mu.Lock()
+	scheduled.status.Running = false
+	scheduled.status.LastRunAt = &started
+	scheduled.status.LastDurationMs = duration.Milliseconds()
+	scheduled.status.LastError = ""
+	if err != nil {
+		scheduled.status.LastError = err.Error()
+	}
+	status := scheduled.status
+	r.mu.Unlock()
+
+	r.persistRun(pluginName, status)
+}
+
+// runTask calls task, turning a panic into an error.
+func runTask(ctx context.Context, task func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return task(ctx)
+}
+
+// persistSchedule records a task's schedule, keeping its last run.
+func (r *TaskRegistry) persistSchedule(pluginName, taskName, cronExpr string) {
+	if r.db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taskWriteTimeout)
+	defer cancel()
+
+	if _, err := r.db.DB().ExecContext(ctx, `
+		INSERT INTO plugin_scheduled_tasks (plugin_name, task_name, schedule, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (plugin_name, task_name) DO UPDATE SET schedule = EXCLUDED.schedule, updated_at = NOW()
+	`, pluginName, taskName, cronExpr); err != nil {
+		log.Printf("[Plugin:%s] Failed to record task %s: %v", pluginName, taskName, err)
+	}
+}
+
+// persistRun records the outcome of a task's latest run.
+func (r *TaskRegistry) persistRun(pluginName string, status TaskStatus) {
+	if r.db == nil {
+		return
+	}
+
+	var lastError interface{}
+	if status.LastError != "" {
+		lastError = status.LastError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taskWriteTimeout)
+	defer cancel()
+
+	if _, err := r.db.DB().ExecContext(ctx, `
+		INSERT INTO plugin_scheduled_tasks (plugin_name, task_name, schedule, last_run_at, last_duration_ms, last_error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (plugin_name, task_name) DO UPDATE SET
+			schedule = EXCLUDED.schedule,
+			last_run_at = EXCLUDED.last_run_at,
+			last_duration_ms = EXCLUDED.last_duration_ms,
+			last_error = EXCLUDED.last_error,
+			updated_at = NOW()
+	`, pluginName, status.Name, status.Schedule, *status.LastRunAt, status.LastDurationMs, lastError); err != nil {
+		log.Printf("[Plugin:%s] Failed to record run of task %s: %v", pluginName, status.Name, err)
+	}
+}
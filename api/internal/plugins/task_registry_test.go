@@ -0,0 +1,88 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scheduledJob returns the cron job of a scheduled task, to run it the way
+// cron would.
+func scheduledJob(t *testing.T, registry *TaskRegistry, pluginName, taskName string) cron.Job {
+	t.Helper()
+	registry.mu.Lock()
+	task, ok := registry.tasks[pluginName][taskName]
+	registry.mu.Unlock()
+	require.True(t, ok, "task %s of %s is not scheduled", taskName, pluginName)
+	return registry.cron.Entry(task.entryID).Job
+}
+
+func TestTaskRegistry_RecordsLastRun(t *testing.T) {
+	registry := NewTaskRegistry(cron.New(), nil)
+	scheduler := NewPluginScheduler(registry, "billing")
+
+	fail := true
+	require.NoError(t, scheduler.ScheduleTask("invoices", "0 0 1 * *", func(context.Context) error {
+		if fail {
+			return errors.New("stripe: rate limited")
+		}
+		return nil
+	}))
+	require.NoError(t, scheduler.Schedule("usage", "@hourly", func() { panic("nil map") }))
+
+	scheduledJob(t, registry, "billing", "invoices").Run()
+	scheduledJob(t, registry, "billing", "usage").Run()
+
+	tasks := scheduler.Tasks()
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "invoices", tasks[0].Name)
+	assert.Equal(t, "0 0 1 * *", tasks[0].Schedule)
+	assert.NotNil(t, tasks[0].LastRunAt)
+	assert.Equal(t, "stripe: rate limited", tasks[0].LastError)
+	assert.Equal(t, "panic: nil map", tasks[1].LastError)
+
+	fail = false
+	scheduledJob(t, registry, "billing", "invoices").Run()
+	assert.Empty(t, scheduler.Tasks()[0].LastError)
+}
+
+func TestTaskRegistry_InvalidScheduleKeepsTask(t *testing.T) {
+	registry := NewTaskRegistry(cron.New(), nil)
+	scheduler := NewPluginScheduler(registry, "billing")
+
+	require.NoError(t, scheduler.Schedule("usage", "@hourly", func() {}))
+	assert.ErrorContains(t, scheduler.Schedule("usage", "every hour", func() {}), `invalid schedule "every hour"`)
+
+	assert.True(t, scheduler.IsScheduled("usage"))
+	assert.Equal(t, "@hourly", scheduler.Tasks()[0].Schedule)
+}
+
+func TestTaskRegistry_UnregisterAllCancelsOwnedTasks(t *testing.T) {
+	registry := NewTaskRegistry(cron.New(), nil)
+	billing := NewPluginScheduler(registry, "billing")
+	reports := NewPluginScheduler(registry, "reports")
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	require.NoError(t, billing.ScheduleTask("invoices", "@daily", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}))
+	require.NoError(t, reports.Schedule("weekly", "@weekly", func() {}))
+
+	go scheduledJob(t, registry, "billing", "invoices").Run()
+	<-started
+
+	registry.UnregisterAll("billing")
+	<-cancelled
+
+	assert.Empty(t, billing.ListJobs())
+	assert.Equal(t, []string{"weekly"}, reports.ListJobs())
+	assert.Len(t, registry.cron.Entries(), 1)
+}
@@ -0,0 +1,389 @@
+// Package plugins - timeouts.go
+//
+// This file implements execution timeouts for plugin HTTP handlers and
+// tracks plugin timeouts for health reporting.
+//
+// Every plugin endpoint handler runs with a request context carrying a
+// deadline of the plugin's maxRequestDuration. A handler still running at
+// the deadline gets a cancelled context, the client receives 504
+// PLUGIN_TIMEOUT, and anything the handler writes afterwards is discarded.
+// As with event handlers (see event_sandbox.go), the handler's goroutine
+// cannot be stopped, but the request goroutine is released.
+//
+// # Configuration
+//
+// Both limits are read from the plugin's installed_plugins.config when it
+// is loaded, as Go duration strings or numbers of seconds:
+//
+//	{"maxRequestDuration": "60s", "maxHandlerDuration": "5s"}
+//
+// Plugins without them get defaultMaxRequestDuration (30 seconds) for HTTP
+// handlers and defaultMaxHandlerDuration (10 seconds) for event handlers.
+//
+// # Health
+//
+// Every HTTP or event handler timeout increments
+// streamspace_plugin_timeouts_total{plugin,kind}. A plugin with
+// unhealthyTimeouts (3) timeouts within timeoutWindow (10 minutes) is
+// marked degraded (see health.go) and a "plugin.unhealthy" event
+// (PluginUnhealthyEvent) is emitted. It returns to loaded at the first
+// health check after its timeouts have aged out of the window.
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EventPluginUnhealthy is emitted when a loaded plugin becomes degraded.
+const EventPluginUnhealthy = "plugin.unhealthy"
+
+// Timeout kinds.
+const (
+	TimeoutKindHTTP  = "http"
+	TimeoutKindEvent = "event"
+)
+
+const (
+	// defaultMaxRequestDuration is the HTTP handler limit of plugins that
+	// do not configure maxRequestDuration
+	defaultMaxRequestDuration = 30 * time.Second
+
+	// unhealthyTimeouts is the number of timeouts within timeoutWindow
+	// that marks a plugin degraded
+	unhealthyTimeouts = 3
+
+	// timeoutWindow is the window in which unhealthyTimeouts are counted
+	timeoutWindow = 10 * time.Minute
+)
+
+var (
+	pluginTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "streamspace_plugin_timeouts_total",
+			Help: "Total number of plugin handler timeouts, by plugin and kind (http or event).",
+		},
+		[]string{"plugin", "kind"},
+	)
+
+	registerTimeoutMetricsOnce sync.Once
+)
+
+// PluginUnhealthyEvent is the payload of plugin.unhealthy.
+type PluginUnhealthyEvent struct {
+	Plugin string `json:"plugin"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ConfigureTimeouts sets a plugin's HTTP handler limit from its
+// installed_plugins.config.
+//
+// An invalid maxRequestDuration is logged and the default is used.
+func (r *APIRegistry) ConfigureTimeouts(pluginName string, config map[string]interface{}) {
+	limit := defaultMaxRequestDuration
+	if raw, ok := config["maxRequestDuration"]; ok {
+		parsed, err := parseHandlerDuration(raw)
+		if err != nil {
+			log.Printf("[API Registry] Invalid maxRequestDuration for %s, using %s: %v", pluginName, defaultMaxRequestDuration, err)
+		} else {
+			limit = parsed
+		}
+	}
+	r.SetMaxRequestDuration(pluginName, limit)
+}
+
+// SetMaxRequestDuration sets how long each of a plugin's HTTP handler
+// invocations may run.
+func (r *APIRegistry) SetMaxRequestDuration(pluginName string, limit time.Duration) {
+	r.timeoutMu.Lock()
+	if r.requestLimits == nil {
+		r.requestLimits = make(map[string]time.Duration)
+	}
+	r.requestLimits[pluginName] = limit
+	r.timeoutMu.Unlock()
+}
+
+// MaxRequestDuration returns a plugin's HTTP handler limit.
+func (r *APIRegistry) MaxRequestDuration(pluginName string) time.Duration {
+	r.timeoutMu.Lock()
+	defer r.timeoutMu.Unlock()
+
+	if limit, ok := r.requestLimits[pluginName]; ok {
+		return limit
+	}
+	return defaultMaxRequestDuration
+}
+
+// SetTimeoutFunc sets the function called when a plugin HTTP handler
+// times out.
+func (r *APIRegistry) SetTimeoutFunc(onTimeout func(pluginName, kind string)) {
+	r.timeoutMu.Lock()
+	r.onTimeout = onTimeout
+	r.timeoutMu.Unlock()
+}
+
+// timeoutPlugin wraps a plugin endpoint's handler in pluginName's HTTP
+// handler limit.
+//
+// The handler runs on a copy of the request's context, so a handler
+// outliving the request never touches a gin.Context that has been reused.
+// A panic in the handler is re-raised on the request goroutine, where
+// recoverPlugin handles it.
+func (r *APIRegistry) timeoutPlugin(pluginName string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := r.MaxRequestDuration(pluginName)
+		if limit <= 0 {
+			handler(c)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), limit)
+		defer cancel()
+
+		writer := newTimeoutWriter(ctx, c.Writer)
+		hc := c.Copy()
+		hc.Request = c.Request.WithContext(ctx)
+		hc.Writer = writer
+
+		// Buffered so a handler finishing after the deadline never blocks
+		done := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				done <- recover()
+			}()
+			handler(hc)
+		}()
+
+		select {
+		case recovered := <-done:
+			if recovered != nil {
+				panic(recovered)
+			}
+			writer.finish()
+		case <-ctx.Done():
+			// The client going away is not the plugin's fault
+			if c.Request.Context().Err() != nil {
+				writer.timeout()
+				c.Abort()
+				return
+			}
+
+			log.Printf("[API Registry] Plugin %s handler for %s %s exceeded %s",
+				pluginName, c.Request.Method, c.Request.URL.Path, limit)
+			r.recordTimeout(pluginName)
+
+			if writer.timeout() {
+				c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+					"error":   "PLUGIN_TIMEOUT",
+					"code":    "PLUGIN_TIMEOUT",
+					"message": fmt.Sprintf("Plugin %s did not respond within %s", pluginName, limit),
+					"plugin":  pluginName,
+				})
+				return
+			}
+			c.Abort()
+		}
+	}
+}
+
+// recordTimeout reports an HTTP handler timeout.
+func (r *APIRegistry) recordTimeout(pluginName string) {
+	r.timeoutMu.Lock()
+	onTimeout := r.onTimeout
+	r.timeoutMu.Unlock()
+
+	if onTimeout != nil {
+		onTimeout(pluginName, TimeoutKindHTTP)
+	}
+}
+
+// timeoutWriter passes a plugin handler's writes through to the response
+// until the handler times out, and discards them afterwards.
+//
+// Like http.TimeoutHandler, the handler sets headers on a map of its own,
+// which is copied to the response when the handler first writes (gin's
+// WriteHeader only records the status) or when it finishes in time. A handler outliving the request therefore never touches
+// the response's headers while the request goroutine writes its 504.
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	// ctx is the handler's context; writes after its deadline are
+	// discarded even before the request goroutine calls timeout
+	ctx context.Context
+
+	// header is the handler's view of the response headers. It is only
+	// used by the handler's goroutine, or by the request goroutine once
+	// the handler has finished.
+	header http.Header
+
+	mu            sync.Mutex
+	timedOut      bool
+	headerWritten bool
+}
+
+// newTimeoutWriter wraps w for a handler running with ctx, starting from a
+// copy of w's headers.
+func newTimeoutWriter(ctx context.Context, w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, ctx: ctx, header: w.Header().Clone()}
+}
+
+// expired reports whether writes are discarded. Callers hold w.mu.
+func (w *timeoutWriter) expired() bool {
+	return w.timedOut || w.ctx.Err() != nil
+}
+
+// timeout stops passing writes through and reports whether the response
+// is still unwritten, so the caller can send its own.
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	return !w.ResponseWriter.Written()
+}
+
+// finish copies the headers of a handler that returned in time without
+// writing, so the rest of the chain sees them.
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.expired() {
+		w.copyHeader()
+	}
+}
+
+// copyHeader replaces the response's headers with the handler's, once.
+// Callers hold w.mu and have checked w.expired.
+func (w *timeoutWriter) copyHeader() {
+	if w.headerWritten {
+		return
+	}
+	w.headerWritten = true
+
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		if _, ok := w.header[key]; !ok {
+			delete(dst, key)
+		}
+	}
+	for key, values := range w.header {
+		dst[key] = append([]string(nil), values...)
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.expired() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.expired() {
+		w.copyHeader()
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.copyHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.copyHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.expired() {
+		w.copyHeader()
+		w.ResponseWriter.Flush()
+	}
+}
+
+// timeoutRecord tracks recent timeouts of one plugin.
+type timeoutRecord struct {
+	total  uint64
+	recent []time.Time
+}
+
+// recordTimeout counts a handler timeout of a plugin and marks the plugin
+// degraded once it reaches unhealthyTimeouts within timeoutWindow.
+func (m *healthMonitor) recordTimeout(name, kind string) {
+	registerTimeoutMetricsOnce.Do(func() {
+		prometheus.MustRegister(pluginTimeoutsTotal)
+	})
+	pluginTimeoutsTotal.WithLabelValues(name, kind).Inc()
+
+	now := time.Now()
+
+	m.mu.Lock()
+	record := m.timeouts[name]
+	if record == nil {
+		record = &timeoutRecord{}
+		m.timeouts[name] = record
+	}
+	record.total++
+	record.recent = recentTimeouts(record.recent, now)
+	record.recent = append(record.recent, now)
+	recent := len(record.recent)
+	m.mu.Unlock()
+
+	if recent >= unhealthyTimeouts {
+		m.setStatus(name, PluginStatusDegraded, timeoutReason(recent), nil)
+	}
+}
+
+// recentTimeouts returns the timeouts in record within timeoutWindow of
+// now. Callers hold m.mu.
+func (m *healthMonitor) recentTimeouts(name string, now time.Time) int {
+	record := m.timeouts[name]
+	if record == nil {
+		return 0
+	}
+	record.recent = recentTimeouts(record.recent, now)
+	return len(record.recent)
+}
+
+// recentTimeouts filters times to those within timeoutWindow of now.
+func recentTimeouts(times []time.Time, now time.Time) []time.Time {
+	recent := times[:0]
+	for _, at := range times {
+		if now.Sub(at) < timeoutWindow {
+			recent = append(recent, at)
+		}
+	}
+	return recent
+}
+
+// timeoutReason describes why a plugin with recent timeouts is degraded.
+func timeoutReason(recent int) error {
+	return fmt.Errorf("%d handler timeouts in the last %s", recent, timeoutWindow)
+}
@@ -0,0 +1,163 @@
+package plugins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginAPI_HandlerTimeout(t *testing.T) {
+	registry := NewAPIRegistry()
+	registry.SetMaxRequestDuration("slow", 20*time.Millisecond)
+
+	timeouts := make(chan string, 1)
+	registry.SetTimeoutFunc(func(pluginName, kind string) {
+		timeouts <- pluginName + "/" + kind
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	api := NewPluginAPI(registry, "slow")
+	require.NoError(t, api.GET("/hang", func(c *gin.Context) {
+		<-release
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	}))
+	require.NoError(t, api.GET("/ctx", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	}))
+	require.NoError(t, api.GET("/fast", okHandler))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registry.AttachToRouter(router.Group(""))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/hang", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "PLUGIN_TIMEOUT")
+	assert.Equal(t, "slow/"+TimeoutKindHTTP, <-timeouts)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/ctx", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NotContains(t, w.Body.String(), "late")
+	<-timeouts
+
+	assert.Equal(t, http.StatusOK, serveEndpoint(registry, http.MethodGet, "/api/plugins/slow/fast", nil))
+}
+
+// Run with -race: a handler setting headers after the deadline must not
+// touch the headers of the 504 response.
+func TestPluginAPI_HandlerTimeoutHeaders(t *testing.T) {
+	registry := NewAPIRegistry()
+	registry.SetMaxRequestDuration("slow", 20*time.Millisecond)
+
+	finished := make(chan struct{})
+	api := NewPluginAPI(registry, "slow")
+	require.NoError(t, api.GET("/late", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		for i := 0; i < 100; i++ {
+			c.Header("X-Late", strconv.Itoa(i))
+		}
+		c.JSON(http.StatusOK, gin.H{"late": true})
+		close(finished)
+	}))
+	require.NoError(t, api.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Plugin", "fast")
+		c.String(http.StatusOK, "ok")
+	}))
+	require.NoError(t, api.GET("/empty", func(c *gin.Context) {
+		c.Header("X-Plugin", "empty")
+		c.Status(http.StatusNoContent)
+	}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registry.AttachToRouter(router.Group(""))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/late", nil))
+	<-finished
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("X-Late"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fast", w.Header().Get("X-Plugin"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/empty", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "empty", w.Header().Get("X-Plugin"))
+}
+
+func TestAPIRegistry_ConfigureTimeouts(t *testing.T) {
+	registry := NewAPIRegistry()
+
+	registry.ConfigureTimeouts("default", map[string]interface{}{})
+	assert.Equal(t, defaultMaxRequestDuration, registry.MaxRequestDuration("default"))
+
+	registry.ConfigureTimeouts("string", map[string]interface{}{"maxRequestDuration": "1m"})
+	assert.Equal(t, time.Minute, registry.MaxRequestDuration("string"))
+
+	registry.ConfigureTimeouts("seconds", map[string]interface{}{"maxRequestDuration": float64(5)})
+	assert.Equal(t, 5*time.Second, registry.MaxRequestDuration("seconds"))
+
+	registry.ConfigureTimeouts("invalid", map[string]interface{}{"maxRequestDuration": "-1s"})
+	assert.Equal(t, defaultMaxRequestDuration, registry.MaxRequestDuration("invalid"))
+}
+
+func TestHealthMonitor_DegradedOnRepeatedTimeouts(t *testing.T) {
+	bus := NewEventBus(EventBusConfig{})
+	unhealthy := make(chan PluginUnhealthyEvent, 1)
+	bus.Subscribe(EventPluginUnhealthy, "watcher", func(data interface{}) error {
+		unhealthy <- data.(PluginUnhealthyEvent)
+		return nil
+	})
+
+	monitor := newHealthMonitor(nil, bus)
+	monitor.loaded("slow", nil)
+
+	for i := 0; i < unhealthyTimeouts-1; i++ {
+		monitor.recordTimeout("slow", TimeoutKindHTTP)
+	}
+	report, _ := monitor.report("slow")
+	assert.Equal(t, PluginStatusLoaded, report.Status)
+
+	monitor.recordTimeout("slow", TimeoutKindEvent)
+	report, _ = monitor.report("slow")
+	assert.Equal(t, PluginStatusDegraded, report.Status)
+	assert.Equal(t, uint64(unhealthyTimeouts), report.Timeouts)
+	assert.Equal(t, unhealthyTimeouts, report.RecentTimeouts)
+
+	select {
+	case event := <-unhealthy:
+		assert.Equal(t, "slow", event.Plugin)
+		assert.Equal(t, PluginStatusDegraded, event.Status)
+		assert.Contains(t, event.Reason, "3 handler timeouts")
+	case <-time.After(time.Second):
+		t.Fatal("plugin.unhealthy was not emitted")
+	}
+
+	// Stays degraded while the timeouts are recent
+	monitor.check(t.Context(), "slow", NewPluginHealth(), monitor.handlerTotals())
+	report, _ = monitor.report("slow")
+	assert.Equal(t, PluginStatusDegraded, report.Status)
+
+	// A reload starts over
+	monitor.loaded("slow", nil)
+	monitor.check(t.Context(), "slow", NewPluginHealth(), monitor.handlerTotals())
+	report, _ = monitor.report("slow")
+	assert.Equal(t, PluginStatusLoaded, report.Status)
+	assert.Zero(t, report.Timeouts)
+}
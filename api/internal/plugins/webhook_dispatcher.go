@@ -0,0 +1,347 @@
+// Package plugins - webhook_dispatcher.go
+//
+// This file implements webhook-type plugins: plugins without Go code that
+// forward platform events to an external URL.
+//
+// A plugin whose manifest has "type": "webhook" declares its target in its
+// installed_plugins.config:
+//
+//	{
+//	  "url": "https://hooks.example.com/streamspace",
+//	  "secret": "whsec_...",
+//	  "events": ["session.created", "session.deleted"]
+//	}
+//
+// When secret is not set in the config, the plugin's "secret" secret is
+// used instead (see secrets.go), which keeps it out of the database.
+//
+// # Delivery
+//
+// For every subscribed event the dispatcher POSTs a JSON payload:
+//
+//	{"id": "<delivery id>", "event": "session.created", "plugin": "...", "timestamp": "...", "data": {...}}
+//
+// with the headers X-StreamSpace-Event, X-StreamSpace-Delivery and
+// X-StreamSpace-Signature (hex HMAC-SHA256 of the body, keyed with the
+// secret). A 2xx response is a success. Network errors, timeouts and 5xx
+// responses are retried up to webhookMaxAttempts times with exponential
+// backoff starting at webhookInitialBackoff; other responses are not
+// retried. Deliveries run in the background, so a slow endpoint never holds
+// up the event bus.
+//
+// Every attempt is recorded in the webhook_deliveries table, which admins
+// can query with GET /api/plugins/:id/deliveries.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/streamspace/streamspace/api/internal/db"
+)
+
+// PluginTypeWebhook is the manifest type of plugins delivered by the
+// WebhookDispatcher.
+const PluginTypeWebhook = "webhook"
+
+const (
+	// webhookMaxAttempts is the number of attempts per delivery
+	webhookMaxAttempts = 5
+
+	// webhookInitialBackoff is the wait before the first retry; it doubles
+	// after each failed attempt
+	webhookInitialBackoff = time.Second
+
+	// webhookAttemptTimeout bounds a single POST
+	webhookAttemptTimeout = 10 * time.Second
+
+	// webhookRecordTimeout bounds a single webhook_deliveries INSERT
+	webhookRecordTimeout = 5 * time.Second
+)
+
+// ErrDispatcherStopped is returned for events dispatched after Stop.
+var ErrDispatcherStopped = errors.New("webhook dispatcher is stopped")
+
+// webhookConfig is the target of a webhook-type plugin.
+type webhookConfig struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+// webhookPayload is the body POSTed for each event.
+type webhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	Plugin    string      `json:"plugin"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDispatcher delivers events to the URLs of webhook-type plugins.
+type WebhookDispatcher struct {
+	db     *db.Database
+	client *http.Client
+
+	maxAttempts    int
+	initialBackoff time.Duration
+
+	// ctx is cancelled by Stop to abandon pending retries
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a dispatcher that records delivery attempts
+// in database's webhook_deliveries table. database may be nil, in which
+// case attempts are only logged.
+func NewWebhookDispatcher(database *db.Database) *WebhookDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		db: database,
+		client: &http.Client{
+			Timeout: webhookAttemptTimeout,
+			// Do not follow redirects to addresses the admin did not configure
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts:    webhookMaxAttempts,
+		initialBackoff: webhookInitialBackoff,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Plugin returns the handler of a webhook-type plugin, which subscribes to
+// the events listed in its config and dispatches them through d.
+func (d *WebhookDispatcher) Plugin() PluginHandler {
+	return &webhookPlugin{dispatcher: d}
+}
+
+// Stop abandons pending retries and waits for in-flight attempts to finish
+// or for ctx to be done.
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch delivers one event to a plugin's webhook in the background.
+func (d *WebhookDispatcher) dispatch(pluginName string, target *webhookConfig, eventType string, data interface{}) error {
+	if d.ctx.Err() != nil {
+		return ErrDispatcherStopped
+	}
+
+	payload := webhookPayload{
+		ID:        uuid.New().String(),
+		Event:     eventType,
+		Plugin:    pluginName,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(pluginName, target, payload, body)
+	}()
+	return nil
+}
+
+// deliver POSTs body until it succeeds, fails permanently or runs out of
+// attempts.
+func (d *WebhookDispatcher) deliver(pluginName string, target *webhookConfig, payload webhookPayload, body []byte) {
+	backoff := d.initialBackoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		start := time.Now()
+		status, err := d.post(target, payload, body)
+		duration := time.Since(start)
+
+		success := err == nil && status >= 200 && status < 300
+		d.record(pluginName, target.URL, payload, attempt, status, success, err, duration)
+		if success {
+			return
+		}
+
+		retryable := err != nil || status >= 500
+		if !retryable || attempt == d.maxAttempts {
+			log.Printf("[Webhook Dispatcher] Delivery %s of %s for plugin %s failed after %d attempt(s) (status %d): %v",
+				payload.ID, payload.Event, pluginName, attempt, status, err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-d.ctx.Done():
+			log.Printf("[Webhook Dispatcher] Abandoning delivery %s for plugin %s on shutdown", payload.ID, pluginName)
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt and returns the response status.
+func (d *WebhookDispatcher) post(target *webhookConfig, payload webhookPayload, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "StreamSpace-Webhook/1.0")
+	req.Header.Set("X-StreamSpace-Event", payload.Event)
+	req.Header.Set("X-StreamSpace-Delivery", payload.ID)
+	req.Header.Set("X-StreamSpace-Signature", signWebhookPayload(body, target.Secret))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded amount so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// record stores one delivery attempt in webhook_deliveries.
+func (d *WebhookDispatcher) record(pluginName, targetURL string, payload webhookPayload, attempt, status int, success bool, deliveryErr error, duration time.Duration) {
+	if d.db == nil {
+		return
+	}
+
+	var statusCode interface{}
+	if status != 0 {
+		statusCode = status
+	}
+	var errMsg interface{}
+	if deliveryErr != nil {
+		errMsg = deliveryErr.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookRecordTimeout)
+	defer cancel()
+
+	if _, err := d.db.DB().ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (plugin_name, delivery_id, event_type, url, attempt, status_code, success, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, pluginName, payload.ID, payload.Event, targetURL, attempt, statusCode, success, errMsg, duration.Milliseconds()); err != nil {
+		log.Printf("[Webhook Dispatcher] Failed to record delivery %s for plugin %s: %v", payload.ID, pluginName, err)
+	}
+}
+
+// signWebhookPayload returns the hex HMAC-SHA256 of body keyed with secret.
+func signWebhookPayload(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseWebhookConfig reads a webhook-type plugin's target from its config.
+func parseWebhookConfig(config map[string]interface{}) (*webhookConfig, error) {
+	target := &webhookConfig{}
+
+	rawURL, _ := config["url"].(string)
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("config.url must be an http(s) URL, got %q", rawURL)
+	}
+	target.URL = rawURL
+
+	target.Secret, _ = config["secret"].(string)
+
+	rawEvents, _ := config["events"].([]interface{})
+	for _, raw := range rawEvents {
+		eventType, ok := raw.(string)
+		if !ok || eventType == "" {
+			return nil, fmt.Errorf("config.events must be a list of event types")
+		}
+		target.Events = append(target.Events, eventType)
+	}
+	if len(target.Events) == 0 {
+		return nil, fmt.Errorf("config.events must list at least one event type")
+	}
+
+	return target, nil
+}
+
+// webhookPlugin is the PluginHandler of a webhook-type plugin.
+type webhookPlugin struct {
+	BasePlugin
+	dispatcher *WebhookDispatcher
+	enabled    atomic.Bool
+}
+
+// OnLoad subscribes to the plugin's configured events.
+func (p *webhookPlugin) OnLoad(ctx *PluginContext) error {
+	target, err := parseWebhookConfig(ctx.Config)
+	if err != nil {
+		return err
+	}
+	if target.Secret == "" {
+		secret, err := ctx.Secrets.Get("secret")
+		if err != nil {
+			return fmt.Errorf("webhook plugin needs config.secret or a \"secret\" plugin secret: %w", err)
+		}
+		target.Secret = secret
+	}
+
+	for _, eventType := range target.Events {
+		eventType := eventType
+		err := ctx.Events.OnCtx(eventType, func(_ context.Context, data interface{}) error {
+			if !p.enabled.Load() {
+				return nil
+			}
+			return p.dispatcher.dispatch(ctx.PluginName, target, eventType, data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	p.enabled.Store(true)
+	log.Printf("[Webhook Dispatcher] Plugin %s delivering %v to %s", ctx.PluginName, target.Events, target.URL)
+	return nil
+}
+
+// OnEnable resumes deliveries.
+func (p *webhookPlugin) OnEnable(ctx *PluginContext) error {
+	p.enabled.Store(true)
+	return nil
+}
+
+// OnDisable stops deliveries; retries already in progress continue.
+func (p *webhookPlugin) OnDisable(ctx *PluginContext) error {
+	p.enabled.Store(false)
+	return nil
+}
@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRequest is a request received by a test webhook endpoint.
+type webhookRequest struct {
+	body      []byte
+	signature string
+	delivery  string
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []webhookRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		status := statuses[len(received)%len(statuses)]
+		received = append(received, webhookRequest{
+			body:      body,
+			signature: r.Header.Get("X-StreamSpace-Signature"),
+			delivery:  r.Header.Get("X-StreamSpace-Delivery"),
+		})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), received...)
+	}
+}
+
+func TestWebhookDispatcher_RetriesServerErrors(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusBadGateway, http.StatusOK)
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs("hooks", sqlmock.AnyArg(), "session.created", server.URL, 1, http.StatusBadGateway, false, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs("hooks", sqlmock.AnyArg(), "session.created", server.URL, 2, http.StatusOK, true, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	dispatcher := NewWebhookDispatcher(db.NewDatabaseFromDB(mockDB))
+	dispatcher.initialBackoff = time.Millisecond
+
+	target := &webhookConfig{URL: server.URL, Secret: "s3cret", Events: []string{"session.created"}}
+	require.NoError(t, dispatcher.dispatch("hooks", target, "session.created", map[string]string{"id": "sess-1"}))
+	require.Eventually(t, func() bool { return len(received()) == 2 }, time.Second, time.Millisecond)
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	requests := received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, requests[0].delivery, requests[1].delivery, "retries keep the delivery id")
+	assert.Equal(t, signWebhookPayload(requests[1].body, "s3cret"), requests[1].signature)
+
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(requests[1].body, &payload))
+	assert.Equal(t, "session.created", payload.Event)
+	assert.Equal(t, "hooks", payload.Plugin)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookDispatcher_DoesNotRetryClientErrors(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusBadRequest)
+
+	dispatcher := NewWebhookDispatcher(nil)
+	dispatcher.initialBackoff = time.Millisecond
+
+	target := &webhookConfig{URL: server.URL, Secret: "s3cret", Events: []string{"user.created"}}
+	require.NoError(t, dispatcher.dispatch("hooks", target, "user.created", nil))
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	assert.Len(t, received(), 1)
+	assert.ErrorIs(t, dispatcher.dispatch("hooks", target, "user.created", nil), ErrDispatcherStopped)
+}
+
+func TestWebhookPlugin_SubscribesConfiguredEvents(t *testing.T) {
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	bus := NewEventBus(EventBusConfig{})
+	dispatcher := NewWebhookDispatcher(nil)
+	handler := dispatcher.Plugin()
+
+	ctx := &PluginContext{
+		PluginName: "hooks",
+		Config: map[string]interface{}{
+			"url":    server.URL,
+			"events": []interface{}{"session.created"},
+		},
+		Events: NewPluginEvents(bus, "hooks"),
+		Secrets: NewPluginSecrets(&fakeSecretStore{secrets: map[string]map[string][]byte{
+			"plugin-hooks-secrets": {"secret": []byte("from-k8s")},
+		}}, "hooks"),
+	}
+	require.NoError(t, handler.OnLoad(ctx))
+
+	bus.EmitSync("session.created", nil)
+	bus.EmitSync("session.deleted", nil)
+	require.NoError(t, handler.OnDisable(ctx))
+	bus.EmitSync("session.created", nil)
+	require.Eventually(t, func() bool { return len(received()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	requests := received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, signWebhookPayload(requests[0].body, "from-k8s"), requests[0].signature)
+}
+
+func TestParseWebhookConfig(t *testing.T) {
+	_, err := parseWebhookConfig(map[string]interface{}{"url": "ftp://example.com", "events": []interface{}{"a"}})
+	assert.Error(t, err)
+
+	_, err = parseWebhookConfig(map[string]interface{}{"url": "https://example.com"})
+	assert.Error(t, err)
+
+	target, err := parseWebhookConfig(map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"secret": "x",
+		"events": []interface{}{"session.created", "user.login"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session.created", "user.login"}, target.Events)
+}
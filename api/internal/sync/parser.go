@@ -2,12 +2,15 @@ package sync
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
 )
 
@@ -395,41 +398,57 @@ func (p *TemplateParser) ParseTemplateFromString(yamlContent string) (*ParsedTem
 	return template, nil
 }
 
-// ValidateTemplateManifest validates a template manifest structure
+// ValidateTemplateManifest validates a template manifest structure.
+//
+// All problems are reported together, joined with "; ". Use
+// TemplateManifestErrors to get them as a list.
 func (p *TemplateParser) ValidateTemplateManifest(yamlContent string) error {
+	if errs := p.TemplateManifestErrors(yamlContent); len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// TemplateManifestErrors returns every validation problem in a template
+// manifest, or nil when it is valid. Messages name the offending field
+// (e.g. "spec.baseImage is required") so template authors can fix them all
+// in one pass.
+func (p *TemplateParser) TemplateManifestErrors(yamlContent string) []string {
 	var manifest TemplateManifest
 	if err := yaml.Unmarshal([]byte(yamlContent), &manifest); err != nil {
-		return fmt.Errorf("invalid YAML: %w", err)
+		return []string{fmt.Sprintf("invalid YAML: %v", err)}
 	}
 
+	var errs []string
+
 	// Check required fields
 	if manifest.Kind != "Template" {
-		return fmt.Errorf("kind must be 'Template', got '%s'", manifest.Kind)
+		errs = append(errs, fmt.Sprintf("kind must be 'Template', got '%s'", manifest.Kind))
 	}
 
 	// Support both old and new API versions
 	if manifest.APIVersion != "stream.space/v1alpha1" && manifest.APIVersion != "stream.streamspace.io/v1alpha1" {
-		return fmt.Errorf("apiVersion must be 'stream.space/v1alpha1', got '%s'", manifest.APIVersion)
+		errs = append(errs, fmt.Sprintf("apiVersion must be 'stream.space/v1alpha1', got '%s'", manifest.APIVersion))
 	}
 
 	if manifest.Metadata.Name == "" {
-		return fmt.Errorf("metadata.name is required")
+		errs = append(errs, "metadata.name is required")
 	}
 
 	if manifest.Spec.DisplayName == "" {
-		return fmt.Errorf("spec.displayName is required")
+		errs = append(errs, "spec.displayName is required")
 	}
 
 	if manifest.Spec.BaseImage == "" {
-		return fmt.Errorf("spec.baseImage is required")
+		errs = append(errs, "spec.baseImage is required")
 	}
 
 	// Validate app type if specified
 	if manifest.Spec.AppType != "" && manifest.Spec.AppType != "desktop" && manifest.Spec.AppType != "webapp" {
-		return fmt.Errorf("spec.appType must be 'desktop' or 'webapp', got '%s'", manifest.Spec.AppType)
+		errs = append(errs, fmt.Sprintf("spec.appType must be 'desktop' or 'webapp', got '%s'", manifest.Spec.AppType))
 	}
 
-	return nil
+	return errs
 }
 
 // ========== Plugin Parsing ==========
@@ -536,6 +555,10 @@ type ParsedPlugin struct {
 	// Tags are keywords for search and filtering.
 	// Example: ["analytics", "reporting", "metrics"]
 	Tags []string
+
+	// Dependencies maps required plugin names to semver constraints.
+	// Example: {"streamspace-auth": ">=1.0.0"}
+	Dependencies map[string]string
 }
 
 // PluginManifest represents the complete JSON structure of a plugin manifest.
@@ -693,10 +716,11 @@ func (p *PluginParser) ParseRepository(repoPath string) ([]*ParsedPlugin, error)
 //
 // Parsing steps:
 //  1. Read file from disk
-//  2. Unmarshal JSON into PluginManifest struct
-//  3. Validate required fields (name, version, displayName, type)
-//  4. Validate plugin type is one of: extension, webhook, api, ui, theme
-//  5. Convert manifest to JSON for database storage
+//  2. Validate against the embedded plugin.schema.json (required fields,
+//     field types, semver version, type enum, homepage/repository URLs,
+//     name/description lengths)
+//  3. Unmarshal JSON into PluginManifest struct
+//  4. Convert manifest to JSON for database storage
 //
 // Required fields:
 //   - name: Unique plugin identifier
@@ -716,7 +740,8 @@ func (p *PluginParser) ParseRepository(repoPath string) ([]*ParsedPlugin, error)
 //
 // Returns:
 //   - ParsedPlugin with extracted metadata
-//   - Error if file cannot be read, parsed, or validated
+//   - Error if file cannot be read, parsed, or validated; schema violations
+//     are reported as *ManifestValidationError with one entry per field
 //
 // Example:
 //
@@ -733,41 +758,17 @@ func (p *PluginParser) ParsePluginFile(filePath string) (*ParsedPlugin, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	// Validate against plugin.schema.json before decoding
+	if err := validatePluginManifestSchema(data); err != nil {
+		return nil, err
+	}
+
 	// Parse JSON
 	var manifest PluginManifest
 	if err := json.Unmarshal(data, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	// Validate required fields
-	if manifest.Name == "" {
-		return nil, fmt.Errorf("plugin name is required")
-	}
-
-	if manifest.Version == "" {
-		return nil, fmt.Errorf("version is required")
-	}
-
-	if manifest.DisplayName == "" {
-		return nil, fmt.Errorf("displayName is required")
-	}
-
-	if manifest.Type == "" {
-		return nil, fmt.Errorf("type is required")
-	}
-
-	// Validate plugin type
-	validTypes := map[string]bool{
-		"extension": true,
-		"webhook":   true,
-		"api":       true,
-		"ui":        true,
-		"theme":     true,
-	}
-	if !validTypes[manifest.Type] {
-		return nil, fmt.Errorf("invalid plugin type: %s (must be extension, webhook, api, ui, or theme)", manifest.Type)
-	}
-
 	// Convert full manifest to JSON for storage
 	manifestJSON, err := json.Marshal(manifest)
 	if err != nil {
@@ -775,15 +776,16 @@ func (p *PluginParser) ParsePluginFile(filePath string) (*ParsedPlugin, error) {
 	}
 
 	plugin := &ParsedPlugin{
-		Name:        manifest.Name,
-		Version:     manifest.Version,
-		DisplayName: manifest.DisplayName,
-		Description: manifest.Description,
-		Category:    manifest.Category,
-		PluginType:  manifest.Type,
-		Icon:        manifest.Icon,
-		Manifest:    string(manifestJSON),
-		Tags:        manifest.Tags,
+		Name:         manifest.Name,
+		Version:      manifest.Version,
+		DisplayName:  manifest.DisplayName,
+		Description:  manifest.Description,
+		Category:     manifest.Category,
+		PluginType:   manifest.Type,
+		Icon:         manifest.Icon,
+		Manifest:     string(manifestJSON),
+		Tags:         manifest.Tags,
+		Dependencies: manifest.Dependencies,
 	}
 
 	if plugin.Tags == nil {
@@ -793,41 +795,197 @@ func (p *PluginParser) ParsePluginFile(filePath string) (*ParsedPlugin, error) {
 	return plugin, nil
 }
 
-// ValidatePluginManifest validates a plugin manifest structure
+// ValidatePluginManifest validates a plugin manifest against plugin.schema.json.
+//
+// Returns a *ManifestValidationError listing every failing field path when
+// the manifest does not match the schema.
 func (p *PluginParser) ValidatePluginManifest(jsonContent string) error {
-	var manifest PluginManifest
-	if err := json.Unmarshal([]byte(jsonContent), &manifest); err != nil {
-		return fmt.Errorf("invalid JSON: %w", err)
+	return validatePluginManifestSchema([]byte(jsonContent))
+}
+
+// DependencyError reports plugins that cannot be loaded because of their
+// declared dependencies.
+//
+// Blocked maps each affected plugin to the reason, e.g. a missing
+// dependency, a version that does not satisfy the constraint, or a
+// dependency that is itself blocked.
+type DependencyError struct {
+	Blocked map[string]string
+}
+
+// Error implements the error interface.
+func (e *DependencyError) Error() string {
+	names := make([]string, 0, len(e.Blocked))
+	for name := range e.Blocked {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// Check required fields
-	if manifest.Name == "" {
-		return fmt.Errorf("name is required")
+	reasons := make([]string, 0, len(names))
+	for _, name := range names {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", name, e.Blocked[name]))
 	}
+	return "unresolved plugin dependencies: " + strings.Join(reasons, "; ")
+}
 
-	if manifest.Version == "" {
-		return fmt.Errorf("version is required")
+// ResolveDependencies orders plugins so every plugin comes after the
+// plugins it depends on.
+//
+// Ordering uses Kahn's algorithm on the dependency graph; plugins with no
+// ordering constraint between them keep alphabetical order so load order is
+// deterministic. Constraints are matched with Masterminds/semver (">=1.0.0",
+// "^2.0.0", "~1.2", ...).
+//
+// Error handling:
+//   - Circular dependencies return (nil, error) naming the cycle,
+//     e.g. "circular plugin dependency: a -> b -> a"
+//   - Missing dependencies, unsatisfied constraints and invalid constraints
+//     block the dependent plugin (and anything depending on it). The
+//     remaining plugins are still returned in order together with a
+//     *DependencyError describing the blocked ones
+//
+// Example:
+//
+//	ordered, err := ResolveDependencies(plugins)
+//	var depErr *DependencyError
+//	if errors.As(err, &depErr) {
+//	    log.Printf("Skipping plugins: %v", depErr)
+//	} else if err != nil {
+//	    return err // cycle
+//	}
+func ResolveDependencies(plugins []*ParsedPlugin) ([]*ParsedPlugin, error) {
+	byName := make(map[string]*ParsedPlugin, len(plugins))
+	for _, plugin := range plugins {
+		byName[plugin.Name] = plugin
+	}
+
+	// Check every declared dependency exists and satisfies its constraint
+	blocked := make(map[string]string)
+	for name, plugin := range byName {
+		depNames := make([]string, 0, len(plugin.Dependencies))
+		for depName := range plugin.Dependencies {
+			depNames = append(depNames, depName)
+		}
+		sort.Strings(depNames)
+
+		for _, depName := range depNames {
+			if reason := checkDependency(byName[depName], depName, plugin.Dependencies[depName]); reason != "" {
+				blocked[name] = reason
+				break
+			}
+		}
 	}
 
-	if manifest.DisplayName == "" {
-		return fmt.Errorf("displayName is required")
+	// Kahn's algorithm: in-degree is the number of dependencies not yet
+	// ordered, dependents lists the edges dependency -> dependent
+	inDegree := make(map[string]int, len(byName))
+	dependents := make(map[string][]string)
+	for name, plugin := range byName {
+		inDegree[name] += 0
+		for depName := range plugin.Dependencies {
+			if _, ok := byName[depName]; !ok {
+				continue
+			}
+			inDegree[name]++
+			dependents[depName] = append(dependents[depName], name)
+		}
 	}
 
-	if manifest.Type == "" {
-		return fmt.Errorf("type is required")
+	var queue []string
+	for name, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, name)
+		}
 	}
+	sort.Strings(queue)
 
-	// Validate plugin type
-	validTypes := map[string]bool{
-		"extension": true,
-		"webhook":   true,
-		"api":       true,
-		"ui":        true,
-		"theme":     true,
+	ordered := make([]*ParsedPlugin, 0, len(byName))
+	processed := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		processed++
+
+		_, isBlocked := blocked[name]
+		if !isBlocked {
+			ordered = append(ordered, byName[name])
+		}
+
+		next := dependents[name]
+		sort.Strings(next)
+		for _, dependent := range next {
+			// A blocked dependency blocks everything that depends on it
+			if _, already := blocked[dependent]; isBlocked && !already {
+				blocked[dependent] = fmt.Sprintf("dependency %s cannot be loaded", name)
+			}
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
 	}
-	if !validTypes[manifest.Type] {
-		return fmt.Errorf("invalid type: %s (must be extension, webhook, api, ui, or theme)", manifest.Type)
+
+	// Anything never dequeued is part of (or behind) a cycle
+	if processed < len(byName) {
+		return nil, fmt.Errorf("circular plugin dependency: %s", strings.Join(findDependencyCycle(byName, inDegree), " -> "))
 	}
 
-	return nil
+	if len(blocked) > 0 {
+		return ordered, &DependencyError{Blocked: blocked}
+	}
+	return ordered, nil
+}
+
+// checkDependency returns why dep does not satisfy constraint, or "" if it does.
+func checkDependency(dep *ParsedPlugin, depName, constraint string) string {
+	if dep == nil {
+		return fmt.Sprintf("missing dependency %s", depName)
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Sprintf("invalid version constraint %q for %s", constraint, depName)
+	}
+
+	version, err := semver.NewVersion(dep.Version)
+	if err != nil {
+		return fmt.Sprintf("dependency %s has invalid version %q", depName, dep.Version)
+	}
+
+	if !c.Check(version) {
+		return fmt.Sprintf("dependency %s %s does not satisfy %s", depName, dep.Version, constraint)
+	}
+	return ""
+}
+
+// findDependencyCycle returns one dependency cycle among the plugins that
+// Kahn's algorithm could not order (inDegree > 0), as a closed path.
+func findDependencyCycle(byName map[string]*ParsedPlugin, inDegree map[string]int) []string {
+	var start string
+	for name, degree := range inDegree {
+		if degree > 0 && (start == "" || name < start) {
+			start = name
+		}
+	}
+
+	// Follow unresolved dependency edges until a plugin repeats; every node
+	// left over has at least one such edge, so this always terminates
+	seen := make(map[string]int)
+	var path []string
+	for current := start; ; {
+		if index, ok := seen[current]; ok {
+			return append(path[index:], current)
+		}
+		seen[current] = len(path)
+		path = append(path, current)
+
+		deps := make([]string, 0, len(byName[current].Dependencies))
+		for depName := range byName[current].Dependencies {
+			if inDegree[depName] > 0 {
+				deps = append(deps, depName)
+			}
+		}
+		sort.Strings(deps)
+		current = deps[0]
+	}
 }
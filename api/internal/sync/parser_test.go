@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pluginNames(plugins []*ParsedPlugin) []string {
+	names := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestResolveDependencies_Order(t *testing.T) {
+	plugins := []*ParsedPlugin{
+		{Name: "alerts", Version: "1.0.0", Dependencies: map[string]string{"notifications": "^2.0.0"}},
+		{Name: "notifications", Version: "2.3.1", Dependencies: map[string]string{"core": ">=1.0.0"}},
+		{Name: "core", Version: "1.4.0"},
+		{Name: "branding", Version: "0.1.0"},
+	}
+
+	ordered, err := ResolveDependencies(plugins)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"branding", "core", "notifications", "alerts"}, pluginNames(ordered))
+}
+
+func TestResolveDependencies_Cycle(t *testing.T) {
+	plugins := []*ParsedPlugin{
+		{Name: "a", Version: "1.0.0", Dependencies: map[string]string{"b": "*"}},
+		{Name: "b", Version: "1.0.0", Dependencies: map[string]string{"a": "*"}},
+		{Name: "c", Version: "1.0.0"},
+	}
+
+	ordered, err := ResolveDependencies(plugins)
+	require.Error(t, err)
+	assert.Nil(t, ordered)
+	assert.Contains(t, err.Error(), "circular plugin dependency")
+	assert.Contains(t, err.Error(), "a -> b -> a")
+}
+
+func TestResolveDependencies_MissingBlocksDependents(t *testing.T) {
+	plugins := []*ParsedPlugin{
+		{Name: "reports", Version: "1.0.0", Dependencies: map[string]string{"billing": ">=1.0.0"}},
+		{Name: "billing", Version: "1.0.0", Dependencies: map[string]string{"payments": ">=1.0.0"}},
+		{Name: "core", Version: "1.0.0"},
+	}
+
+	ordered, err := ResolveDependencies(plugins)
+	var depErr *DependencyError
+	require.True(t, errors.As(err, &depErr))
+	assert.Equal(t, []string{"core"}, pluginNames(ordered))
+	assert.Contains(t, depErr.Blocked, "billing")
+	assert.Contains(t, depErr.Blocked, "reports")
+}
+
+func TestResolveDependencies_UnsatisfiedVersion(t *testing.T) {
+	plugins := []*ParsedPlugin{
+		{Name: "alerts", Version: "1.0.0", Dependencies: map[string]string{"notifications": "^3.0.0"}},
+		{Name: "notifications", Version: "2.3.1"},
+	}
+
+	ordered, err := ResolveDependencies(plugins)
+	var depErr *DependencyError
+	require.True(t, errors.As(err, &depErr))
+	assert.Equal(t, []string{"notifications"}, pluginNames(ordered))
+	assert.Contains(t, depErr.Blocked["alerts"], "notifications")
+}
@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// pluginSchemaFS embeds the JSON Schema for plugin manifest.json files.
+//
+//go:embed plugin.schema.json
+var pluginSchemaFS embed.FS
+
+const pluginSchemaFile = "plugin.schema.json"
+
+var (
+	pluginSchema     *jsonschema.Schema
+	pluginSchemaErr  error
+	pluginSchemaOnce gosync.Once
+)
+
+// ManifestFieldError describes one schema violation in a plugin manifest.
+type ManifestFieldError struct {
+	// Path is the JSON pointer of the offending value (e.g. "/version").
+	// An empty path refers to the manifest root (e.g. a missing required field).
+	Path string `json:"path"`
+
+	// Message explains what is wrong with the value.
+	Message string `json:"message"`
+}
+
+// ManifestValidationError is returned when a plugin manifest does not match
+// plugin.schema.json. It lists every failing field so repository maintainers
+// can fix all problems in one pass.
+type ManifestValidationError struct {
+	Errors []ManifestFieldError `json:"errors"`
+}
+
+// Error formats all field errors on one line.
+func (e *ManifestValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		path := fe.Path
+		if path == "" {
+			path = "/"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", path, fe.Message))
+	}
+	return "plugin manifest validation failed: " + strings.Join(parts, "; ")
+}
+
+// compiledPluginSchema compiles the embedded schema once.
+func compiledPluginSchema() (*jsonschema.Schema, error) {
+	pluginSchemaOnce.Do(func() {
+		data, err := pluginSchemaFS.ReadFile(pluginSchemaFile)
+		if err != nil {
+			pluginSchemaErr = fmt.Errorf("failed to read plugin schema: %w", err)
+			return
+		}
+
+		compiler := jsonschema.NewCompiler()
+		compiler.AssertFormat = true
+		if err := compiler.AddResource(pluginSchemaFile, bytes.NewReader(data)); err != nil {
+			pluginSchemaErr = fmt.Errorf("failed to load plugin schema: %w", err)
+			return
+		}
+
+		pluginSchema, pluginSchemaErr = compiler.Compile(pluginSchemaFile)
+	})
+	return pluginSchema, pluginSchemaErr
+}
+
+// validatePluginManifestSchema validates raw manifest JSON against plugin.schema.json.
+//
+// Returns a *ManifestValidationError when the manifest violates the schema,
+// or a plain error when the JSON cannot be decoded at all.
+func validatePluginManifestSchema(data []byte) error {
+	schema, err := compiledPluginSchema()
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	result := &ManifestValidationError{}
+	collectManifestErrors(ve, &result.Errors)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Path < result.Errors[j].Path
+	})
+	return result
+}
+
+// collectManifestErrors flattens the validation error tree into its leaves.
+func collectManifestErrors(ve *jsonschema.ValidationError, out *[]ManifestFieldError) {
+	if len(ve.Causes) == 0 {
+		*out = append(*out, ManifestFieldError{Path: ve.InstanceLocation, Message: ve.Message})
+		return
+	}
+	for _, cause := range ve.Causes {
+		collectManifestErrors(cause, out)
+	}
+}
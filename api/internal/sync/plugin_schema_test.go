@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePluginManifest_Valid(t *testing.T) {
+	parser := NewPluginParser()
+
+	err := parser.ValidatePluginManifest(`{
+		"name": "streamspace-analytics",
+		"version": "1.2.0-beta.1",
+		"displayName": "Analytics",
+		"description": "Usage analytics and reporting for sessions",
+		"type": "extension",
+		"homepage": "https://streamspace.io/plugins/analytics",
+		"tags": ["analytics"]
+	}`)
+
+	assert.NoError(t, err)
+}
+
+func TestValidatePluginManifest_ReportsEveryField(t *testing.T) {
+	parser := NewPluginParser()
+
+	err := parser.ValidatePluginManifest(`{
+		"name": "x",
+		"version": "1.0",
+		"type": "widget",
+		"homepage": "not a url",
+		"tags": "analytics"
+	}`)
+
+	var verr *ManifestValidationError
+	require.True(t, errors.As(err, &verr), "expected ManifestValidationError, got %v", err)
+
+	paths := map[string]bool{}
+	for _, fe := range verr.Errors {
+		paths[fe.Path] = true
+	}
+
+	assert.True(t, paths[""], "missing displayName should be reported at the root")
+	assert.True(t, paths["/name"])
+	assert.True(t, paths["/version"])
+	assert.True(t, paths["/type"])
+	assert.True(t, paths["/homepage"])
+	assert.True(t, paths["/tags"])
+}
+
+func TestValidatePluginManifest_InvalidJSON(t *testing.T) {
+	err := NewPluginParser().ValidatePluginManifest(`{"name":`)
+
+	var verr *ManifestValidationError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &verr))
+}
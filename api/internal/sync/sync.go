@@ -31,11 +31,15 @@ package sync
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/lib/pq"
@@ -88,6 +92,23 @@ type SyncService struct {
 
 	// pluginParser parses Plugin JSON manifests from repositories.
 	pluginParser *PluginParser
+
+	// catalogCache is told about every completed sync so it can drop stale
+	// template listings. Optional, set via SetCatalogCache.
+	catalogCache CatalogCache
+}
+
+// CatalogCache caches catalog template listings.
+//
+// *catalog.TemplateCache implements this interface.
+type CatalogCache interface {
+	InvalidateRepository(repoID int) int
+}
+
+// SetCatalogCache sets the cache invalidated when a repository sync
+// completes.
+func (s *SyncService) SetCatalogCache(cache CatalogCache) {
+	s.catalogCache = cache
 }
 
 // NewSyncService creates a new sync service instance.
@@ -167,9 +188,17 @@ func NewSyncService(database *db.Database) (*SyncService, error) {
 //   - Parse errors: Log warnings, continue with valid resources
 //   - Database errors: Roll back transaction, return error
 //
+// Progress:
+//   - If progress is non-nil, a SyncProgress is sent for each stage
+//     (cloning, parsing, saving, then done or error) and progress is closed
+//     when SyncRepository returns
+//   - Sends never block: give progress enough buffer for every stage
+//     (syncProgressStages); updates that do not fit are dropped
+//
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
 //   - repoID: Database ID of the repository to sync
+//   - progress: Optional channel receiving stage updates (may be nil)
 //
 // Returns an error if:
 //   - Repository not found in database
@@ -178,13 +207,25 @@ func NewSyncService(database *db.Database) (*SyncService, error) {
 //
 // Example:
 //
-//	err := syncService.SyncRepository(ctx, 1)
+//	err := syncService.SyncRepository(ctx, 1, nil)
 //	if err != nil {
 //	    log.Printf("Sync failed: %v", err)
 //	}
-func (s *SyncService) SyncRepository(ctx context.Context, repoID int) error {
+func (s *SyncService) SyncRepository(ctx context.Context, repoID int, progress chan<- SyncProgress) (err error) {
 	log.Printf("Starting sync for repository %d", repoID)
 
+	if progress != nil {
+		start := time.Now()
+		defer func() {
+			if err != nil {
+				sendProgress(progress, SyncProgress{Stage: SyncStageError, Message: err.Error()})
+			} else {
+				sendProgress(progress, SyncProgress{Stage: SyncStageDone, Elapsed: time.Since(start).Round(100 * time.Millisecond).String()})
+			}
+			close(progress)
+		}()
+	}
+
 	// Get repository details
 	repo, err := s.getRepository(ctx, repoID)
 	if err != nil {
@@ -197,42 +238,18 @@ func (s *SyncService) SyncRepository(ctx context.Context, repoID int) error {
 	}
 
 	// Clone or update repository
-	repoPath := filepath.Join(s.workDir, fmt.Sprintf("repo-%d", repoID))
-
-	var cloneErr error
-	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
-		// Clone repository
-		log.Printf("Cloning repository %s to %s", repo.URL, repoPath)
-		cloneErr = s.gitClient.Clone(ctx, repo.URL, repoPath, repo.Branch, repo.AuthConfig)
-	} else {
-		// Pull latest changes
-		log.Printf("Pulling latest changes for repository %s", repo.URL)
-		cloneErr = s.gitClient.Pull(ctx, repoPath, repo.Branch, repo.AuthConfig)
-	}
-
+	sendProgress(progress, SyncProgress{Stage: SyncStageCloning})
+	repoPath, cloneErr := s.fetchRepository(ctx, repo)
 	if cloneErr != nil {
 		errMsg := fmt.Sprintf("Git operation failed: %v", cloneErr)
 		s.updateRepositoryStatus(ctx, repoID, "failed", errMsg)
 		return fmt.Errorf("git operation failed: %w", cloneErr)
 	}
 
-	// Parse templates from repository
-	templates, err := s.parser.ParseRepository(repoPath)
-	if err != nil {
-		log.Printf("Template parsing warning: %v", err)
-		templates = []*ParsedTemplate{} // Continue even if no templates found
-	}
-
-	log.Printf("Found %d templates in repository %d", len(templates), repoID)
-
-	// Parse plugins from repository
-	plugins, err := s.pluginParser.ParseRepository(repoPath)
-	if err != nil {
-		log.Printf("Plugin parsing warning: %v", err)
-		plugins = []*ParsedPlugin{} // Continue even if no plugins found
-	}
-
-	log.Printf("Found %d plugins in repository %d", len(plugins), repoID)
+	// Parse templates and plugins from repository
+	templates, plugins := s.parseRepository(repoPath, repoID)
+	sendProgress(progress, SyncProgress{Stage: SyncStageParsing, TemplatesFound: len(templates)})
+	sendProgress(progress, SyncProgress{Stage: SyncStageSaving})
 
 	// Update catalog with templates
 	if len(templates) > 0 {
@@ -267,10 +284,248 @@ func (s *SyncService) SyncRepository(ctx context.Context, repoID int) error {
 		log.Printf("Failed to update repository sync time: %v", err)
 	}
 
+	// Drop cached listings that may show the repository's old templates
+	if s.catalogCache != nil {
+		if removed := s.catalogCache.InvalidateRepository(repoID); removed > 0 {
+			log.Printf("Invalidated %d cached catalog listings for repository %d", removed, repoID)
+		}
+	}
+
 	log.Printf("Successfully synced repository %d with %d templates and %d plugins", repoID, len(templates), len(plugins))
 	return nil
 }
 
+// Sync progress stages reported by SyncRepository.
+const (
+	SyncStageCloning = "cloning"
+	SyncStageParsing = "parsing"
+	SyncStageSaving  = "saving"
+	SyncStageDone    = "done"
+	SyncStageError   = "error"
+)
+
+// syncProgressStages is the most updates one SyncRepository call sends.
+const syncProgressStages = 4
+
+// SyncProgress is one stage update from SyncRepository.
+type SyncProgress struct {
+	Stage string `json:"stage"`
+
+	// TemplatesFound is set for the parsing stage
+	TemplatesFound int `json:"templatesFound,omitempty"`
+
+	// Elapsed is the total sync duration, set for the done stage
+	Elapsed string `json:"elapsed,omitempty"`
+
+	// Message describes the failure, set for the error stage
+	Message string `json:"message,omitempty"`
+}
+
+// NewSyncProgress returns a channel buffered for every update of one sync.
+func NewSyncProgress() chan SyncProgress {
+	return make(chan SyncProgress, syncProgressStages)
+}
+
+// sendProgress delivers an update without blocking the sync.
+func sendProgress(progress chan<- SyncProgress, update SyncProgress) {
+	if progress == nil {
+		return
+	}
+	select {
+	case progress <- update:
+	default:
+		log.Printf("Dropping sync progress update %q: channel full", update.Stage)
+	}
+}
+
+// SyncDiff describes what a sync would change in the catalog.
+//
+// Entries are resource names, sorted alphabetically. Modified means the
+// manifest differs from what the catalog currently holds.
+type SyncDiff struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+
+	// Plugins is the same comparison for catalog_plugins
+	Plugins *SyncDiff `json:"plugins,omitempty"`
+}
+
+// SyncRepositoryDryRun previews a sync without writing to the database.
+//
+// The repository is cloned or pulled and parsed exactly as SyncRepository
+// does, then compared against the current catalog:
+//   - Templates are compared by the hash of their last synced version, the
+//     same check updateCatalog uses to skip unchanged templates
+//   - Plugins are compared by manifest
+//
+// Nothing in the database is modified: catalog tables, last_sync, status
+// and template_count are all left untouched. The working copy on disk is
+// updated, which the next real sync would do anyway.
+//
+// Example:
+//
+//	diff, err := syncService.SyncRepositoryDryRun(ctx, 1)
+//	if err == nil && len(diff.Removed) > 0 {
+//	    log.Printf("Sync would remove: %v", diff.Removed)
+//	}
+func (s *SyncService) SyncRepositoryDryRun(ctx context.Context, repoID int) (*SyncDiff, error) {
+	repo, err := s.getRepository(ctx, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	repoPath, err := s.fetchRepository(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("git operation failed: %w", err)
+	}
+
+	templates, plugins := s.parseRepository(repoPath, repoID)
+
+	// Current templates with the hash of their latest synced version
+	currentTemplates, err := s.queryNameValues(ctx, `
+		SELECT ct.name, COALESCE((
+			SELECT v.version_hash FROM catalog_template_versions v
+			WHERE v.template_id = ct.id AND v.version_hash IS NOT NULL
+			ORDER BY v.synced_at DESC, v.id DESC
+			LIMIT 1
+		), '')
+		FROM catalog_templates ct
+		WHERE ct.repository_id = $1
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing templates: %w", err)
+	}
+
+	currentPlugins, err := s.queryNameValues(ctx, `
+		SELECT name, COALESCE(manifest::text, '') FROM catalog_plugins WHERE repository_id = $1
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing plugins: %w", err)
+	}
+
+	incomingTemplates := make(map[string]string, len(templates))
+	for _, template := range templates {
+		incomingTemplates[template.Name] = manifestHash(template.Manifest)
+	}
+
+	// Plugin manifests are stored as JSONB, which reformats them, so both
+	// sides are normalized before comparing
+	for name, manifest := range currentPlugins {
+		currentPlugins[name] = normalizeJSON(manifest)
+	}
+	incomingPlugins := make(map[string]string, len(plugins))
+	for _, plugin := range plugins {
+		incomingPlugins[plugin.Name] = normalizeJSON(plugin.Manifest)
+	}
+
+	diff := diffCatalog(currentTemplates, incomingTemplates)
+	diff.Plugins = diffCatalog(currentPlugins, incomingPlugins)
+
+	log.Printf("Dry-run sync for repository %d: %d added, %d removed, %d modified templates",
+		repoID, len(diff.Added), len(diff.Removed), len(diff.Modified))
+	return diff, nil
+}
+
+// fetchRepository clones the repository on first use and pulls it afterwards.
+//
+// Returns the path of the working copy.
+func (s *SyncService) fetchRepository(ctx context.Context, repo *Repository) (string, error) {
+	repoPath := filepath.Join(s.workDir, fmt.Sprintf("repo-%d", repo.ID))
+
+	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+		// Clone repository
+		log.Printf("Cloning repository %s to %s", repo.URL, repoPath)
+		return repoPath, s.gitClient.Clone(ctx, repo.URL, repoPath, repo.Branch, repo.AuthConfig)
+	}
+
+	// Pull latest changes
+	log.Printf("Pulling latest changes for repository %s", repo.URL)
+	return repoPath, s.gitClient.Pull(ctx, repoPath, repo.Branch, repo.AuthConfig)
+}
+
+// parseRepository parses templates and plugins from a working copy.
+//
+// Parse errors are logged and treated as "nothing found" so a repository
+// with only templates (or only plugins) still syncs.
+func (s *SyncService) parseRepository(repoPath string, repoID int) ([]*ParsedTemplate, []*ParsedPlugin) {
+	templates, err := s.parser.ParseRepository(repoPath)
+	if err != nil {
+		log.Printf("Template parsing warning: %v", err)
+		templates = []*ParsedTemplate{} // Continue even if no templates found
+	}
+
+	log.Printf("Found %d templates in repository %d", len(templates), repoID)
+
+	plugins, err := s.pluginParser.ParseRepository(repoPath)
+	if err != nil {
+		log.Printf("Plugin parsing warning: %v", err)
+		plugins = []*ParsedPlugin{} // Continue even if no plugins found
+	}
+
+	log.Printf("Found %d plugins in repository %d", len(plugins), repoID)
+
+	return templates, plugins
+}
+
+// queryNameValues runs a two-column (name, value) query into a map.
+func (s *SyncService) queryNameValues(ctx context.Context, query string, args ...interface{}) (map[string]string, error) {
+	rows, err := s.db.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		result[name] = value
+	}
+	return result, rows.Err()
+}
+
+// normalizeJSON re-encodes a JSON document with sorted keys and no
+// whitespace. Invalid JSON is returned unchanged.
+func normalizeJSON(raw string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return raw
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return string(normalized)
+}
+
+// diffCatalog compares current and incoming name → content maps.
+func diffCatalog(current, incoming map[string]string) *SyncDiff {
+	diff := &SyncDiff{Added: []string{}, Removed: []string{}, Modified: []string{}}
+
+	for name, value := range incoming {
+		existing, found := current[name]
+		switch {
+		case !found:
+			diff.Added = append(diff.Added, name)
+		case existing != value:
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	for name := range current {
+		if _, found := incoming[name]; !found {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff
+}
+
 // SyncAllRepositories synchronizes all enabled repositories.
 //
 // This method:
@@ -331,7 +586,7 @@ func (s *SyncService) SyncAllRepositories(ctx context.Context) error {
 	failCount := 0
 
 	for _, repoID := range repoIDs {
-		if err := s.SyncRepository(ctx, repoID); err != nil {
+		if err := s.SyncRepository(ctx, repoID, nil); err != nil {
 			log.Printf("Failed to sync repository %d: %v", repoID, err)
 			failCount++
 		} else {
@@ -379,7 +634,22 @@ func (s *SyncService) updateRepositoryStatus(ctx context.Context, repoID int, st
 	return err
 }
 
-// updateCatalog updates the catalog_templates table with parsed templates
+// updateCatalog reconciles catalog_templates with the parsed repository templates.
+//
+// Every template manifest is hashed (SHA-256 of the manifest JSON) and
+// compared against the hash of the most recently synced version:
+//   - Unchanged templates are skipped entirely (idempotent, fast re-syncs)
+//   - Changed templates are updated in place and a new row is appended to
+//     catalog_template_versions
+//   - New templates are inserted along with their first version row
+//   - Templates no longer present in the repository are removed
+//
+// Comparing against the last synced version (rather than the active record)
+// means an admin rollback is preserved until the repository publishes a
+// new change for that template.
+//
+// Template IDs are stable across syncs, so ratings, stats and version
+// history attached to a template survive repository updates.
 func (s *SyncService) updateCatalog(ctx context.Context, repoID int, templates []*ParsedTemplate) error {
 	// Start transaction
 	tx, err := s.db.DB().BeginTx(ctx, nil)
@@ -388,12 +658,38 @@ func (s *SyncService) updateCatalog(ctx context.Context, repoID int, templates [
 	}
 	defer tx.Rollback()
 
-	// Delete existing templates for this repository
-	_, err = tx.ExecContext(ctx, `
-		DELETE FROM catalog_templates WHERE repository_id = $1
+	// Load existing templates and the hash of their latest synced version
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ct.id, ct.name, COALESCE((
+			SELECT v.version_hash FROM catalog_template_versions v
+			WHERE v.template_id = ct.id AND v.version_hash IS NOT NULL
+			ORDER BY v.synced_at DESC, v.id DESC
+			LIMIT 1
+		), '')
+		FROM catalog_templates ct
+		WHERE ct.repository_id = $1
 	`, repoID)
 	if err != nil {
-		return fmt.Errorf("failed to delete old templates: %w", err)
+		return fmt.Errorf("failed to load existing templates: %w", err)
+	}
+
+	type existingTemplate struct {
+		id   int
+		hash string
+	}
+	existing := make(map[string]existingTemplate)
+	for rows.Next() {
+		var name string
+		var et existingTemplate
+		if err := rows.Scan(&et.id, &name, &et.hash); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan existing template: %w", err)
+		}
+		existing[name] = et
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load existing templates: %w", err)
 	}
 
 	// Deduplicate templates by name (keep the last occurrence)
@@ -402,22 +698,68 @@ func (s *SyncService) updateCatalog(ctx context.Context, repoID int, templates [
 		templateMap[template.Name] = template
 	}
 
-	// Insert deduplicated templates
+	// Remove templates that are no longer in the repository
+	for name, et := range existing {
+		if _, ok := templateMap[name]; ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM catalog_templates WHERE id = $1`, et.id); err != nil {
+			return fmt.Errorf("failed to delete removed template %s: %w", name, err)
+		}
+	}
+
+	now := time.Now()
+	inserted, updated, unchanged := 0, 0, 0
+
 	for _, template := range templateMap {
-		// Convert manifest to JSON string for storage
-		manifestJSON := template.Manifest
+		hash := manifestHash(template.Manifest)
 
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO catalog_templates (
-				repository_id, name, display_name, description, category,
-				app_type, icon_url, manifest, tags, created_at, updated_at
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		`, repoID, template.Name, template.DisplayName, template.Description,
-			template.Category, template.AppType, template.Icon, manifestJSON,
-			pq.Array(template.Tags), time.Now(), time.Now())
+		et, found := existing[template.Name]
+		if found && et.hash == hash {
+			unchanged++
+			continue
+		}
+
+		templateID := et.id
+		if found {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE catalog_templates
+				SET display_name = $1, description = $2, category = $3, app_type = $4,
+				    icon_url = $5, manifest = $6, tags = $7, version_hash = $8, updated_at = $9
+				WHERE id = $10
+			`, template.DisplayName, template.Description, template.Category, template.AppType,
+				template.Icon, template.Manifest, pq.Array(template.Tags), hash, now, templateID)
+			if err != nil {
+				return fmt.Errorf("failed to update template %s: %w", template.Name, err)
+			}
+			updated++
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO catalog_templates (
+					repository_id, name, display_name, description, category,
+					app_type, icon_url, manifest, tags, version_hash, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				RETURNING id
+			`, repoID, template.Name, template.DisplayName, template.Description,
+				template.Category, template.AppType, template.Icon, template.Manifest,
+				pq.Array(template.Tags), hash, now, now).Scan(&templateID)
+			if err != nil {
+				return fmt.Errorf("failed to insert template %s: %w", template.Name, err)
+			}
+			inserted++
+		}
 
+		// Append to version history (version is the short content hash)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO catalog_template_versions (
+				template_id, version, version_hash, manifest, display_name, description,
+				category, app_type, icon_url, tags, synced_at, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+			ON CONFLICT (template_id, version) DO UPDATE SET synced_at = EXCLUDED.synced_at
+		`, templateID, hash[:12], hash, template.Manifest, template.DisplayName, template.Description,
+			template.Category, template.AppType, template.Icon, pq.Array(template.Tags), now)
 		if err != nil {
-			return fmt.Errorf("failed to insert template %s: %w", template.Name, err)
+			return fmt.Errorf("failed to record version for template %s: %w", template.Name, err)
 		}
 	}
 
@@ -426,10 +768,17 @@ func (s *SyncService) updateCatalog(ctx context.Context, repoID int, templates [
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Updated catalog with %d templates for repository %d", len(templates), repoID)
+	log.Printf("Updated catalog for repository %d: %d new, %d updated, %d unchanged templates",
+		repoID, inserted, updated, unchanged)
 	return nil
 }
 
+// manifestHash returns the hex-encoded SHA-256 of a template manifest.
+func manifestHash(manifest string) string {
+	sum := sha256.Sum256([]byte(manifest))
+	return hex.EncodeToString(sum[:])
+}
+
 // updatePluginCatalog updates the plugin catalog with parsed plugins
 func (s *SyncService) updatePluginCatalog(ctx context.Context, repoID int, plugins []*ParsedPlugin) error {
 	// Start transaction
@@ -511,6 +860,6 @@ type Repository struct {
 
 // AuthConfig represents authentication configuration for Git
 type AuthConfig struct {
-	Type   string // none, ssh, token, basic
+	Type   string // none, ssh, token, basic, github_app
 	Secret string // Secret value (SSH key, token, or password)
 }
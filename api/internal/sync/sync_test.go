@@ -0,0 +1,99 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/streamspace/streamspace/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffCatalog(t *testing.T) {
+	current := map[string]string{
+		"firefox": "hash-a",
+		"vscode":  "hash-b",
+		"gimp":    "hash-c",
+	}
+	incoming := map[string]string{
+		"firefox":  "hash-a",
+		"vscode":   "hash-b2",
+		"blender":  "hash-d",
+		"inkscape": "hash-e",
+	}
+
+	diff := diffCatalog(current, incoming)
+
+	assert.Equal(t, []string{"blender", "inkscape"}, diff.Added)
+	assert.Equal(t, []string{"gimp"}, diff.Removed)
+	assert.Equal(t, []string{"vscode"}, diff.Modified)
+}
+
+func TestDiffCatalog_Empty(t *testing.T) {
+	diff := diffCatalog(map[string]string{}, map[string]string{})
+
+	assert.NotNil(t, diff.Added)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.Empty(t, diff.Modified)
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	// JSONB reorders keys and drops whitespace; both forms must compare equal
+	assert.Equal(t,
+		normalizeJSON(`{"name": "slack", "version": "1.0.0"}`),
+		normalizeJSON(`{"version":"1.0.0","name":"slack"}`))
+	assert.Equal(t, "not json", normalizeJSON("not json"))
+}
+
+func TestSyncRepository_ReportsErrorProgress(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery("SELECT id, name, url, branch, auth_type, auth_secret").
+		WithArgs(42).
+		WillReturnError(sql.ErrNoRows)
+
+	s := &SyncService{db: db.NewDatabaseFromDB(mockDB)}
+	progress := NewSyncProgress()
+
+	err = s.SyncRepository(context.Background(), 42, progress)
+	require.Error(t, err)
+
+	var updates []SyncProgress
+	for update := range progress {
+		updates = append(updates, update)
+	}
+	require.Len(t, updates, 1)
+	assert.Equal(t, SyncStageError, updates[0].Stage)
+	assert.Contains(t, updates[0].Message, "failed to get repository")
+}
+
+func TestUpdatePluginCatalog_StoresTagsAsArray(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	plugin := &ParsedPlugin{
+		Name:    "ci-tools",
+		Version: "1.0.0",
+		Tags:    []string{"ci/cd", `"quoted"`, "machine learning"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM catalog_plugins WHERE repository_id = \\$1").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO catalog_plugins").
+		WithArgs(3, "ci-tools", "1.0.0", "", "", "", "", "", "",
+			`{"ci/cd","\"quoted\"","machine learning"}`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	s := &SyncService{db: db.NewDatabaseFromDB(mockDB)}
+	require.NoError(t, s.updatePluginCatalog(context.Background(), 3, []*ParsedPlugin{plugin}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
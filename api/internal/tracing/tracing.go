@@ -0,0 +1,85 @@
+// Package tracing carries request correlation data across goroutines.
+//
+// The RequestID middleware identifies every HTTP request, but the Gin
+// context it writes to is gone once work moves to another goroutine (plugin
+// event handlers, background jobs). A TraceContext stored in a
+// context.Context travels with that work instead:
+//
+//	// In a handler (see middleware.TraceContext)
+//	ctx := middleware.TraceContext(c)
+//	runtime.EmitEventWithContext(ctx, "session.created", session)
+//
+//	// In a plugin event handler
+//	func(ctx context.Context, data interface{}) error {
+//	    log.Printf("%sprocessing session", tracing.LogPrefix(ctx))
+//	    ...
+//	}
+//
+// Work that outlives the request should use Detach, so it keeps the trace
+// without being cancelled when the response is written.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TraceContext identifies the request that caused a piece of work.
+type TraceContext struct {
+	// RequestID is the X-Request-ID of the originating HTTP request.
+	RequestID string `json:"requestId,omitempty"`
+
+	// UserID is the authenticated user who made the request, if any.
+	UserID string `json:"userId,omitempty"`
+}
+
+// traceKey is the context key under which a TraceContext is stored.
+type traceKey struct{}
+
+// WithTrace returns a copy of ctx carrying trace.
+func WithTrace(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// FromContext returns the TraceContext carried by ctx.
+func FromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	trace, ok := ctx.Value(traceKey{}).(TraceContext)
+	return trace, ok
+}
+
+// Detach returns a context with ctx's values, including its TraceContext,
+// that is never cancelled. Use it to hand request-scoped data to goroutines
+// that outlive the request.
+func Detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(ctx)
+}
+
+// String formats the trace as "request_id=... user_id=...", omitting empty
+// fields.
+func (t TraceContext) String() string {
+	parts := make([]string, 0, 2)
+	if t.RequestID != "" {
+		parts = append(parts, "request_id="+t.RequestID)
+	}
+	if t.UserID != "" {
+		parts = append(parts, "user_id="+t.UserID)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogPrefix returns "[request_id=... user_id=...] " for log lines about work
+// traced by ctx, or "" when ctx carries no trace.
+func LogPrefix(ctx context.Context) string {
+	trace, ok := FromContext(ctx)
+	if !ok || trace.String() == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s] ", trace)
+}
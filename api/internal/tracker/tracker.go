@@ -232,7 +232,7 @@ func (ct *ConnectionTracker) loadActiveConnections() error {
 	rows, err := ct.db.DB().QueryContext(ctx, `
 		SELECT id, session_id, user_id, client_ip, user_agent, connected_at, last_heartbeat
 		FROM connections
-		WHERE last_heartbeat > NOW() - INTERVAL '5 minutes'
+		WHERE archived_at IS NULL AND last_heartbeat > NOW() - INTERVAL '5 minutes'
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to query connections: %w", err)
@@ -84,6 +84,7 @@ type Manager struct {
 	db          *db.Database
 	k8sClient   *k8s.Client
 	notifier    *Notifier
+	heartbeat   *HeartbeatManager
 }
 
 // NewManager creates a new WebSocket manager
@@ -93,7 +94,10 @@ func NewManager(database *db.Database, k8sClient *k8s.Client) *Manager {
 		metricsHub:  NewHub(),
 		db:          database,
 		k8sClient:   k8sClient,
+		heartbeat:   NewHeartbeatManager(),
 	}
+	m.sessionsHub.heartbeat = m.heartbeat
+	m.metricsHub.heartbeat = m.heartbeat
 	// Initialize notifier with reference to manager
 	m.notifier = NewNotifier(m)
 	return m
@@ -107,6 +111,18 @@ func (m *Manager) Start() {
 	go m.broadcastMetrics()
 }
 
+// ClientCount returns the total number of connected WebSocket clients
+// across all hubs.
+func (m *Manager) ClientCount() int {
+	return m.sessionsHub.ClientCount() + m.metricsHub.ClientCount()
+}
+
+// SetTokenValidator sets how the tokens of reconnect messages are
+// validated (see heartbeat.go).
+func (m *Manager) SetTokenValidator(validate TokenValidator) {
+	m.heartbeat.SetTokenValidator(validate)
+}
+
 // GetNotifier returns the notifier for event-driven notifications
 func (m *Manager) GetNotifier() *Notifier {
 	return m.notifier
@@ -116,7 +132,10 @@ func (m *Manager) GetNotifier() *Notifier {
 // Supports subscribing to user-specific or session-specific events via query params:
 // - ?user_id=<userID> - Subscribe to all events for a specific user
 // - ?session_id=<sessionID> - Subscribe to events for a specific session
-func (m *Manager) HandleSessionsWebSocket(conn *websocket.Conn, userID, sessionID string) {
+//
+// identity is the authenticated user that opened the connection; userID is the
+// user whose events are subscribed to and may differ for admins/operators.
+func (m *Manager) HandleSessionsWebSocket(conn *websocket.Conn, identity Identity, userID, sessionID string) {
 	clientID := uuid.New().String()
 
 	// Subscribe to user or session events if specified
@@ -130,7 +149,7 @@ func (m *Manager) HandleSessionsWebSocket(conn *websocket.Conn, userID, sessionI
 	// Cleanup subscription on disconnect
 	defer m.notifier.UnsubscribeClient(clientID)
 
-	m.sessionsHub.ServeClient(conn, clientID)
+	m.sessionsHub.ServeClient(conn, clientID, identity)
 }
 
 // CloseAll closes all WebSocket connections and subscriptions
@@ -166,9 +185,9 @@ func (m *Manager) CloseAll() {
 }
 
 // HandleMetricsWebSocket handles WebSocket connections for metrics updates
-func (m *Manager) HandleMetricsWebSocket(conn *websocket.Conn) {
+func (m *Manager) HandleMetricsWebSocket(conn *websocket.Conn, identity Identity) {
 	clientID := uuid.New().String()
-	m.metricsHub.ServeClient(conn, clientID)
+	m.metricsHub.ServeClient(conn, clientID, identity)
 }
 
 // HandleLogsWebSocket handles WebSocket connections for pod logs streaming
@@ -236,7 +255,7 @@ func (m *Manager) broadcastSessionUpdates() {
 			// Get active connections count from database
 			var activeConns int
 			if err := m.db.DB().QueryRowContext(ctx, `
-				SELECT active_connections FROM sessions WHERE id = $1
+				SELECT active_connections FROM sessions WHERE archived_at IS NULL AND id = $1
 			`, session.Name).Scan(&activeConns); err != nil {
 				// If query fails, default to 0
 				activeConns = 0
@@ -324,6 +343,7 @@ func (m *Manager) broadcastMetrics() {
 				COUNT(*) FILTER (WHERE state = 'hibernated') as hibernated,
 				COUNT(*) as total
 			FROM sessions
+			WHERE archived_at IS NULL
 		`).Scan(&runningCount, &hibernatedCount, &totalCount)
 
 		if err != nil {
@@ -335,7 +355,7 @@ func (m *Manager) broadcastMetrics() {
 		var activeConnections int
 		err = m.db.DB().QueryRowContext(ctx, `
 			SELECT COUNT(*) FROM connections
-			WHERE last_heartbeat > NOW() - INTERVAL '2 minutes'
+			WHERE archived_at IS NULL AND last_heartbeat > NOW() - INTERVAL '2 minutes'
 		`).Scan(&activeConnections)
 
 		if err != nil {
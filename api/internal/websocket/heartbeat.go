@@ -0,0 +1,181 @@
+// Package websocket provides real-time WebSocket communication for StreamSpace.
+//
+// This file implements connection heartbeats and in-band re-authentication.
+//
+// Heartbeat:
+//   - Every client is sent a ping frame every 30 seconds
+//   - A client that does not answer with a pong within 10 seconds is gone:
+//     its connection is closed with code 1000 and
+//     streamspace_websocket_timeout_total is incremented
+//   - Browsers answer pings without any application code
+//
+// Reconnect:
+//
+// After a network interruption, a client whose connection survived can
+// re-authenticate it (for example with a JWT refreshed while it was
+// offline) instead of opening a new connection:
+//
+//	{"type":"reconnect","token":"<jwt>"}
+//
+// The token must be valid and belong to the user that opened the
+// connection. The server answers {"type":"reconnected","userId":"..."} and
+// keeps the connection's subscriptions; otherwise it closes the connection
+// with code 4001, the code upgrade requests with an invalid token are
+// rejected with.
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultHeartbeatInterval is how often clients are pinged
+	defaultHeartbeatInterval = 30 * time.Second
+
+	// defaultPongTimeout is how long a client has to answer a ping
+	defaultPongTimeout = 10 * time.Second
+
+	// writeWait is how long a single write may take
+	writeWait = 10 * time.Second
+
+	// closeUnauthorized is the close code sent when re-authentication fails
+	closeUnauthorized = 4001
+)
+
+// Client message types.
+const (
+	MessageTypeReconnect   = "reconnect"
+	MessageTypeReconnected = "reconnected"
+)
+
+var (
+	websocketTimeoutsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "streamspace_websocket_timeout_total",
+			Help: "Total number of WebSocket connections closed because a heartbeat ping went unanswered.",
+		},
+	)
+
+	registerHeartbeatMetricsOnce sync.Once
+)
+
+// TokenValidator validates the JWT of a reconnect message and returns the
+// user it belongs to.
+type TokenValidator func(token string) (Identity, error)
+
+// HeartbeatManager pings the clients of a hub, closes the connections of
+// clients that stop answering, and re-authenticates clients that send a
+// reconnect message.
+type HeartbeatManager struct {
+	interval    time.Duration
+	pongTimeout time.Duration
+	validate    TokenValidator
+}
+
+// NewHeartbeatManager creates a heartbeat manager with the default
+// interval and pong timeout.
+func NewHeartbeatManager() *HeartbeatManager {
+	return &HeartbeatManager{
+		interval:    defaultHeartbeatInterval,
+		pongTimeout: defaultPongTimeout,
+	}
+}
+
+// SetTokenValidator sets how reconnect tokens are validated. Without one,
+// reconnect messages are rejected.
+func (hb *HeartbeatManager) SetTokenValidator(validate TokenValidator) {
+	hb.validate = validate
+}
+
+// readTimeout is how long a connection may go without receiving anything.
+// It only catches connections whose pings fail to be sent; the pong
+// timeout normally closes a silent connection first.
+func (hb *HeartbeatManager) readTimeout() time.Duration {
+	return 2 * hb.interval
+}
+
+// timeout closes the connection of a client that did not answer a ping.
+func (hb *HeartbeatManager) timeout(c *Client) {
+	registerHeartbeatMetricsOnce.Do(func() {
+		prometheus.MustRegister(websocketTimeoutsTotal)
+	})
+	websocketTimeoutsTotal.Inc()
+
+	log.Printf("WebSocket client %s did not answer ping within %s, closing", c.id, hb.pongTimeout)
+	c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "heartbeat timeout"),
+		time.Now().Add(writeWait))
+}
+
+// clientMessage is a message sent by the browser.
+type clientMessage struct {
+	Type  string `json:"type"`
+	Token string `json:"token,omitempty"`
+}
+
+// reconnect re-authenticates a client with a reconnect message's token.
+// It returns an error if the connection must be closed.
+func (hb *HeartbeatManager) reconnect(c *Client, token string) error {
+	if hb.validate == nil {
+		return fmt.Errorf("reconnect is not supported")
+	}
+
+	identity, err := hb.validate(token)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	if identity.UserID != c.Identity().UserID {
+		return fmt.Errorf("token belongs to user %s, connection to user %s", identity.UserID, c.Identity().UserID)
+	}
+
+	c.setIdentity(identity)
+	c.alive()
+
+	reply, _ := json.Marshal(map[string]interface{}{
+		"type":   MessageTypeReconnected,
+		"userId": identity.UserID,
+	})
+	select {
+	case c.replies <- reply:
+	default:
+		// The client is sending reconnects faster than they are answered
+	}
+	return nil
+}
+
+// handleMessage handles a message sent by the browser. It returns an error
+// if the connection must be closed.
+func (c *Client) handleMessage(message []byte) error {
+	var msg clientMessage
+	if err := json.Unmarshal(message, &msg); err == nil && msg.Type == MessageTypeReconnect {
+		return c.hub.heartbeat.reconnect(c, msg.Token)
+	}
+
+	// For now, we just log other received messages
+	// In the future, we could handle client->server messages
+	log.Printf("Received message from client %s (user %s): %s", c.id, c.Identity().UserID, message)
+	return nil
+}
+
+// alive records that the client answered, clearing an outstanding ping.
+func (c *Client) alive() {
+	select {
+	case c.pong <- struct{}{}:
+	default:
+	}
+}
+
+// closeUnauthorized closes the connection after a failed re-authentication.
+func (c *Client) closeUnauthorized(reason error) {
+	log.Printf("WebSocket client %s failed to reconnect: %v", c.id, reason)
+	c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(closeUnauthorized, "unauthorized"),
+		time.Now().Add(writeWait))
+}
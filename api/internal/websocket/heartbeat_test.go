@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialHub serves hub's clients as user-1 and dials it.
+func dialHub(t *testing.T, hub *Hub) *websocket.Conn {
+	t.Helper()
+	go hub.Run()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.ServeClient(conn, "client-1", Identity{UserID: "user-1", UserRole: "user"})
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newTestHub() *Hub {
+	hub := NewHub()
+	hub.heartbeat.interval = 50 * time.Millisecond
+	hub.heartbeat.pongTimeout = 20 * time.Millisecond
+	hub.heartbeat.SetTokenValidator(func(token string) (Identity, error) {
+		switch token {
+		case "user-1-token":
+			return Identity{UserID: "user-1", UserRole: "admin"}, nil
+		case "user-2-token":
+			return Identity{UserID: "user-2", UserRole: "user"}, nil
+		}
+		return Identity{}, errors.New("invalid token")
+	})
+	return hub
+}
+
+func TestHeartbeat_MissingPongClosesConnection(t *testing.T) {
+	before := testutil.ToFloat64(websocketTimeoutsTotal)
+	conn := dialHub(t, newTestHub())
+
+	// Never answer pings
+	conn.SetPingHandler(func(string) error { return nil })
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "unexpected error: %v", err)
+	assert.Equal(t, before+1, testutil.ToFloat64(websocketTimeoutsTotal))
+}
+
+func TestHeartbeat_AnsweredPingsKeepConnectionOpen(t *testing.T) {
+	conn := dialHub(t, newTestHub())
+
+	// The default ping handler answers with a pong
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+
+	_, _, err := conn.ReadMessage()
+	var netErr interface{ Timeout() bool }
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout(), "unexpected error: %v", err)
+}
+
+func TestHeartbeat_Reconnect(t *testing.T) {
+	conn := dialHub(t, newTestHub())
+
+	require.NoError(t, conn.WriteJSON(clientMessage{Type: MessageTypeReconnect, Token: "user-1-token"}))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var reply map[string]interface{}
+	require.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, MessageTypeReconnected, reply["type"])
+	assert.Equal(t, "user-1", reply["userId"])
+}
+
+func TestHeartbeat_ReconnectRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "invalid token", token: "not-a-jwt"},
+		{name: "other user", token: "user-2-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialHub(t, newTestHub())
+
+			require.NoError(t, conn.WriteJSON(clientMessage{Type: MessageTypeReconnect, Token: tt.token}))
+
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			_, _, err := conn.ReadMessage()
+			assert.True(t, websocket.IsCloseError(err, closeUnauthorized), "unexpected error: %v", err)
+		})
+	}
+}
@@ -92,6 +92,9 @@ type Hub struct {
 	// mu protects concurrent access to clients map.
 	// Used when checking client count or iterating clients.
 	mu sync.RWMutex
+
+	// heartbeat pings the hub's clients and handles reconnect messages.
+	heartbeat *HeartbeatManager
 }
 
 // Client represents an individual WebSocket connection.
@@ -139,6 +142,40 @@ type Client struct {
 	// id uniquely identifies this client.
 	// Format: "{userID}-{sessionID}" or UUID
 	id string
+
+	// identity is the authenticated user behind the connection.
+	// Used for per-message authorization of client->server messages.
+	// Replaced by a reconnect message, so guarded by identityMu.
+	identity   Identity
+	identityMu sync.RWMutex
+
+	// pong signals writePump that the client answered a ping.
+	// Buffer size: 1
+	pong chan struct{}
+
+	// replies is the channel of responses to client->server messages.
+	// Unlike send, it is never closed, so readPump can always write to it.
+	replies chan []byte
+}
+
+// Identity is the authenticated user that opened a WebSocket connection.
+type Identity struct {
+	UserID   string
+	UserRole string
+}
+
+// Identity returns the authenticated user behind the connection.
+func (c *Client) Identity() Identity {
+	c.identityMu.RLock()
+	defer c.identityMu.RUnlock()
+	return c.identity
+}
+
+// setIdentity replaces the authenticated user behind the connection.
+func (c *Client) setIdentity(identity Identity) {
+	c.identityMu.Lock()
+	c.identity = identity
+	c.identityMu.Unlock()
 }
 
 // NewHub creates a new WebSocket hub
@@ -148,6 +185,7 @@ func NewHub() *Hub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
+		heartbeat:  NewHeartbeatManager(),
 	}
 }
 
@@ -213,17 +251,21 @@ func (h *Hub) ClientCount() int {
 
 // writePump pumps messages from the hub to the websocket connection
 func (c *Client) writePump() {
-	ticker := time.NewTicker(30 * time.Second) // Send ping every 30 seconds
+	heartbeat := c.hub.heartbeat
+	ticker := time.NewTicker(heartbeat.interval)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
 	}()
 
+	// pongDeadline fires when an outstanding ping goes unanswered
+	var pongDeadline <-chan time.Time
+
 	for {
 		select {
 		case message, ok := <-c.send:
 			// Set write deadline to prevent hanging on slow connections
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// Hub closed the channel
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -247,12 +289,31 @@ func (c *Client) writePump() {
 				return
 			}
 
+		case reply := <-c.replies:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+				return
+			}
+
 		case <-ticker.C:
-			// Send ping to keep connection alive
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			// Send ping to detect stale connections, discarding any
+			// answer to an earlier ping
+			select {
+			case <-c.pong:
+			default:
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+			pongDeadline = time.After(heartbeat.pongTimeout)
+
+		case <-c.pong:
+			pongDeadline = nil
+
+		case <-pongDeadline:
+			heartbeat.timeout(c)
+			return
 		}
 	}
 }
@@ -265,9 +326,11 @@ func (c *Client) readPump() {
 	}()
 
 	// Set read deadline and pong handler to keep connection alive
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	readTimeout := c.hub.heartbeat.readTimeout()
+	c.conn.SetReadDeadline(time.Now().Add(readTimeout))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		c.alive()
 		return nil
 	})
 
@@ -281,21 +344,25 @@ func (c *Client) readPump() {
 		}
 
 		// Reset read deadline on any message
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.conn.SetReadDeadline(time.Now().Add(readTimeout))
 
-		// For now, we just log received messages
-		// In the future, we could handle client->server messages
-		log.Printf("Received message from client %s: %s", c.id, message)
+		if err := c.handleMessage(message); err != nil {
+			c.closeUnauthorized(err)
+			break
+		}
 	}
 }
 
 // ServeClient handles a new WebSocket connection
-func (h *Hub) ServeClient(conn *websocket.Conn, clientID string) {
+func (h *Hub) ServeClient(conn *websocket.Conn, clientID string, identity Identity) {
 	client := &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan []byte, 256),
-		id:   clientID,
+		hub:      h,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		id:       clientID,
+		identity: identity,
+		pong:     make(chan struct{}, 1),
+		replies:  make(chan []byte, 4),
 	}
 
 	client.hub.register <- client